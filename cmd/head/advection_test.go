@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"io"
+	"log/slog"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Флаги командной строки, заданные пользователем, на время теста: runX
+// смотрят на них через flag.Visit
+func setFlags(t *testing.T, values map[string]string) {
+	t.Helper()
+	prev := flag.CommandLine
+	fs := flag.NewFlagSet("head", flag.ContinueOnError)
+	for name, v := range values {
+		fs.String(name, "", "")
+		if err := fs.Set(name, v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	flag.CommandLine = fs
+	t.Cleanup(func() { flag.CommandLine = prev })
+}
+
+func TestMain(m *testing.M) {
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	os.Exit(m.Run())
+}
+
+// Проверка флагов -velocity: неизвестная схема, -theta вне [0, 1],
+// бесконечная скорость, неизвестные столбцы, слишком грубая сетка и флаги
+// других режимов дают код 1
+func TestRunAdvectionRejects(t *testing.T) {
+	good := advection{method: "CN", velocity: 1, dx: 0.1, dt: 0.01, tmax: 0.1, columns: "all"}
+	tests := []struct {
+		name  string
+		flags map[string]string
+		edit  func(a *advection)
+	}{
+		{"unknown method", map[string]string{"method": "RK4"}, func(a *advection) { a.method = "RK4" }},
+		{"theta above 1", map[string]string{"method": "THETA", "theta": "1.5"}, func(a *advection) { a.method, a.theta = "THETA", 1.5 }},
+		{"infinite velocity", nil, func(a *advection) { a.velocity = math.Inf(1) }},
+		{"NaN velocity", nil, func(a *advection) { a.velocity = math.NaN() }},
+		{"unknown columns", nil, func(a *advection) { a.columns = "exact" }},
+		{"one interval", nil, func(a *advection) { a.dx = 0.8 }},
+		{"with lambda", map[string]string{"lambda": "5"}, func(*advection) {}},
+		{"with nx", map[string]string{"nx": "20"}, func(*advection) {}},
+		{"with storage", map[string]string{"storage": "final"}, func(*advection) {}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			setFlags(t, tc.flags)
+			a := good
+			a.out = filepath.Join(t.TempDir(), "out.csv")
+			tc.edit(&a)
+			if code := runAdvection(a); code != 1 {
+				t.Errorf("exit code %d, want 1", code)
+			}
+		})
+	}
+}
+
+// Расчёт с -velocity пишет все слои в CSV: у каждой схемы заголовок и
+// nt+1 слоёв по nx+1 строк
+func TestRunAdvection(t *testing.T) {
+	for _, method := range []string{"FTCS", "BTCS", "CN"} {
+		t.Run(method, func(t *testing.T) {
+			setFlags(t, map[string]string{"method": method, "velocity": "-1.5"})
+			out := filepath.Join(t.TempDir(), "out.csv")
+			a := advection{method: method, velocity: -1.5, dx: 0.05, dt: 0.001, tmax: 0.05, out: out, columns: "all"}
+			if code := runAdvection(a); code != 0 {
+				t.Fatalf("exit code %d", code)
+			}
+			f, err := os.Open(out)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			var lines []string
+			sc := bufio.NewScanner(f)
+			for sc.Scan() {
+				lines = append(lines, sc.Text())
+			}
+			if want := 1 + 51*21; len(lines) != want {
+				t.Fatalf("%d lines, want %d", len(lines), want)
+			}
+			if !strings.HasPrefix(lines[0], "x,t,") {
+				t.Errorf("header %q", lines[0])
+			}
+		})
+	}
+}
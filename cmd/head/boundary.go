@@ -0,0 +1,227 @@
+package main
+
+import (
+	"log/slog"
+	"math"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"heat-solver/internal/io"
+	"heat-solver/internal/mathutils"
+	"heat-solver/internal/solver"
+)
+
+// Параметры расчёта с краевыми условиями -bc-left и -bc-right
+type boundaryRun struct {
+	method      string
+	theta       float64
+	left, right mathutils.Boundary
+	dx, dt      float64
+	tmax        float64
+	out         string
+	columns     string
+	// Что делать с несовместимостью начала и краевых условий
+	// (compatWarn, compatSmooth или compatRannacher), допуск и число
+	// правимых узлов у края для compatSmooth
+	compat      string
+	compatTol   float64
+	compatNodes int
+}
+
+// Значения -compat
+const (
+	compatWarn      = "warn"
+	compatSmooth    = "smooth"
+	compatRannacher = "rannacher"
+)
+
+// Флаги проверки совместимости; без -bc-left и -bc-right они тоже
+// включают runBoundary
+var flagsCompat = []string{"compat", "compat-tol", "compat-nodes"}
+
+// Краевые условия из -bc-left и -bc-right; ошибка разбора — в лог
+func parseBoundaries(left, right string) (mathutils.Boundary, mathutils.Boundary, bool) {
+	l, err := mathutils.ParseBoundary(left)
+	if err != nil {
+		slog.Error("Invalid -bc-left", "error", err)
+		return l, l, false
+	}
+	r, err := mathutils.ParseBoundary(right)
+	if err != nil {
+		slog.Error("Invalid -bc-right", "error", err)
+		return l, r, false
+	}
+	return l, r, true
+}
+
+// Значения на краях для режимов, где края — только постоянные условия
+// Дирихле (-method STEADY, -layers)
+func dirichletValues(mode string, left, right mathutils.Boundary) (float64, float64, bool) {
+	for _, b := range []mathutils.Boundary{left, right} {
+		if !b.IsDirichlet() || b.TimeDependent() {
+			slog.Error(mode+" takes constant dirichlet boundary values only", "bc_left", left.String(), "bc_right", right.String())
+			return 0, 0, false
+		}
+	}
+	return left.Value(0), right.Value(0), true
+}
+
+// Точное решение расчёта с краевыми условиями, если оно известно: мода
+// mathutils.BoundaryMode при однородных условиях Дирихле и Неймана и ряд
+// остывающей пластины mathutils.RobinSlab при теплоизоляции слева и
+// условии Робина справа; иначе nil
+func boundaryExact(left, right mathutils.Boundary) mathutils.Reference {
+	switch {
+	case right.Kind == mathutils.BCRobin && left.IsNeumann() && left.IsHomogeneous():
+		return mathutils.NewRobinSlab(right.Biot(1), 1, right.Ambient)
+	case left.Kind == mathutils.BCRobin || right.Kind == mathutils.BCRobin:
+		return nil
+	case left.IsHomogeneous() && right.IsHomogeneous():
+		return mathutils.BoundaryMode{Alpha: 1, LeftNeumann: left.IsNeumann(), RightNeumann: right.IsNeumann()}
+	}
+	return nil
+}
+
+// Расчёт на отрезке с условиями Дирихле, Неймана или Робина на концах
+// (solver.StreamBoundaryFrom): θ-схема -method FTCS, BTCS, CN (по
+// умолчанию) или THETA, условия Неймана и Робина — через фиктивный узел
+// со вторым порядком по x. Начало — первая мода для выбранных видов
+// условий (sin(πx), cos(πx/2), sin(πx/2) или cos(πx)), а с условием Робина
+// — u = 1. Перед счётом начало сверяется с условиями в момент 0
+// (checkCompat). Если точное решение известно (boundaryExact), в конце
+// считаются нормы ошибки. Слои пишутся в CSV по мере счёта. Код выхода 1
+// при ошибке
+func runBoundary(c boundaryRun) int {
+	rejected := slices.DeleteFunc(slices.Clone(flags1D), func(name string) bool {
+		return name == "theta" || slices.Contains(flagsCompat, name)
+	})
+	if !rejectFlags("-bc-left and -bc-right", rejected, flagsRadial...) {
+		return 1
+	}
+	theta, ok := methodTheta("boundary condition", c.method, c.theta)
+	if !ok {
+		return 1
+	}
+	compat := strings.ToLower(c.compat)
+	switch compat {
+	case compatWarn, compatSmooth:
+	case compatRannacher:
+		if theta != 0.5 {
+			slog.Error("-compat rannacher needs -method CN", "theta", theta)
+			return 1
+		}
+	default:
+		slog.Error("Unknown -compat", "compat", c.compat, "available", []string{compatWarn, compatSmooth, compatRannacher})
+		return 1
+	}
+	if compat != compatSmooth && !rejectFlags("-compat "+compat, nil, "compat-nodes") {
+		return 1
+	}
+	if !(c.compatTol >= 0) || math.IsInf(c.compatTol, 0) {
+		slog.Error("Compatibility tolerance must be non-negative and finite", "compat_tol", c.compatTol)
+		return 1
+	}
+	reference := boundaryExact(c.left, c.right)
+	var exact mathutils.Reference
+	switch strings.ToLower(c.columns) {
+	case io.ColumnsAll:
+		if reference != nil {
+			exact = reference
+		} else {
+			slog.Info("No exact solution for these boundary conditions; the CSV has x,t,u_numeric only")
+		}
+	case io.ColumnsNumeric:
+	default:
+		slog.Error("Unknown CSV columns", "columns", c.columns, "available", []string{io.ColumnsAll, io.ColumnsNumeric})
+		return 1
+	}
+	nx := int(math.Round(1 / c.dx))
+	if nx < 2 {
+		slog.Error("Grid needs at least 2 intervals", "nx", nx)
+		return 1
+	}
+	dx := 1 / float64(nx)
+	nt := int(math.Round(c.tmax / c.dt))
+
+	slog.Info("Simulation parameters", "theta", theta, "bc_left", c.left.String(), "bc_right", c.right.String(), "dt", c.dt, "tmax", c.tmax, "outfile", c.out)
+	slog.Info("Grid configuration", "nx", nx, "nt", nt, "dx", dx)
+	for _, b := range []mathutils.Boundary{c.left, c.right} {
+		if b.Kind == mathutils.BCRobin {
+			bi := b.Biot(1)
+			slog.Info("Robin boundary", "bc", b.String(), "biot", bi, "lambda1", mathutils.BiotRoots(bi, 1)[0])
+		}
+	}
+
+	u0, startup, ok := checkCompat(c, compat, nx, dx)
+	if !ok {
+		return 1
+	}
+	if !slices.Equal(u0, solver.BoundaryProfile(nx, dx, c.left, c.right)) {
+		// Точное решение начинается с несглаженного профиля
+		reference, exact = nil, nil
+	}
+
+	f, err := os.Create(c.out)
+	if err != nil {
+		slog.Error("Failed to create output file", "file", c.out, "error", err)
+		return 1
+	}
+	defer f.Close()
+	w := io.NewCSVLevelWriter(f, dx, c.dt, exact)
+
+	start := time.Now()
+	last, err := solver.StreamBoundaryFrom(u0, nt, dx, c.dt, 1, theta, startup, c.left, c.right, w.WriteLevel)
+	if err == nil {
+		err = w.Flush()
+	}
+	if err == nil {
+		err = f.Close()
+	}
+	if err != nil {
+		slog.Error("Computation failed", "error", err)
+		return 1
+	}
+	slog.Info("Computation and CSV output completed", "runtime_sec", time.Since(start).Seconds())
+
+	t := float64(nt) * c.dt
+	if reference != nil {
+		l2, linf := mathutils.LevelErrors(last, dx, t, reference)
+		slog.Info("Error norms", "t", t, "l2", l2, "linf", linf)
+	}
+	slog.Info("Results successfully saved", "file", c.out)
+	return 0
+}
+
+// Проверка совместимости начала с краевыми условиями
+// (solver.CheckCompatibility): о каждой несовместимости больше
+// -compat-tol — предупреждение с её величиной. -compat smooth правит
+// -compat-nodes узлов у краёв (solver.SmoothStart), -compat rannacher
+// начинает CN с полушагов BTCS (solver.RannacherSteps), -compat warn
+// только предупреждает. Начальный профиль и число шагов старта; false —
+// ошибка уже в логе
+func checkCompat(c boundaryRun, compat string, nx int, dx float64) ([]float64, int, bool) {
+	u0 := solver.BoundaryProfile(nx, dx, c.left, c.right)
+	found := solver.CheckCompatibility(u0, dx, c.left, c.right, c.compatTol)
+	for _, m := range found {
+		slog.Warn("Initial condition does not match the boundary condition", "side", m.Side, "bc", m.Kind, "initial", m.Initial, "required", m.Required, "mismatch", m.Size, "tol", c.compatTol)
+	}
+	if len(found) == 0 {
+		return u0, 0, true
+	}
+	switch compat {
+	case compatSmooth:
+		smoothed, err := solver.SmoothStart(u0, dx, c.left, c.right, c.compatNodes)
+		if err != nil {
+			slog.Error("Invalid -compat-nodes", "error", err)
+			return nil, 0, false
+		}
+		slog.Info("Initial condition smoothed at the ends", "nodes", c.compatNodes)
+		return smoothed, 0, true
+	case compatRannacher:
+		return u0, solver.RannacherSteps, true
+	}
+	slog.Info("Continuing with the mismatch; -compat smooth or -compat rannacher (with CN) damps the start-up transient")
+	return u0, 0, true
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"flag"
+	"log/slog"
+	"math"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"heat-solver/internal/config"
+	"heat-solver/internal/io"
+	"heat-solver/internal/mathutils"
+	"heat-solver/internal/solver"
+)
+
+const equationCattaneo = "cattaneo"
+
+// Параметры расчёта уравнения Каттанео (-equation cattaneo)
+type cattaneoRun struct {
+	method  string
+	tau     float64
+	ic      string
+	dx, dt  float64
+	tmax    float64
+	out     string
+	columns string
+}
+
+// Расчёт τ·u_tt + u_t = u_xx на [0, 1] с нулём на краях и u_t(0) = 0
+// трёхслойной неявной схемой solver.StreamCattaneoFrom. Начальный слой —
+// sin(πx) (-ic sine, точное решение mathutils.CattaneoMode) или
+// ступенька -ic tophat, у которой видна конечная скорость √(1/τ): пока
+// правый фронт не дошёл до края, он сравнивается с ¾ + t/√τ. Слои пишутся
+// в CSV по мере счёта. Код выхода 1 при ошибке
+func runCattaneo(c cattaneoRun) int {
+	rejected := slices.DeleteFunc(slices.Clone(flags1D), func(name string) bool { return name == "ic" })
+	if !rejectFlags("-equation cattaneo", rejected, append([]string{"dim", "geometry", "rho", "length", "m", "order", "memory"}, flagsRadial...)...) {
+		return 1
+	}
+	methodSet := false
+	flag.Visit(func(f *flag.Flag) { methodSet = methodSet || f.Name == "method" })
+	if methodSet && !strings.EqualFold(c.method, "BTCS") {
+		slog.Error("The Cattaneo equation takes its three-level implicit scheme, -method BTCS, only", "method", c.method)
+		return 1
+	}
+	if !(c.tau >= 0) || math.IsInf(c.tau, 0) {
+		slog.Error("Relaxation time must be non-negative and finite", "tau", c.tau)
+		return 1
+	}
+	mode := mathutils.CattaneoMode{Alpha: 1, Tau: c.tau}
+	tophat := strings.EqualFold(c.ic, config.ICTopHat)
+	if !tophat && !strings.EqualFold(c.ic, config.ICSine) {
+		slog.Error("Unknown initial condition", "ic", c.ic, "available", []string{config.ICSine, config.ICTopHat})
+		return 1
+	}
+	var exact mathutils.Reference
+	switch strings.ToLower(c.columns) {
+	case io.ColumnsAll:
+		if tophat {
+			slog.Info("No exact solution for -ic tophat; the CSV has x,t,u_numeric only")
+		} else {
+			exact = mode
+		}
+	case io.ColumnsNumeric:
+	default:
+		slog.Error("Unknown CSV columns", "columns", c.columns, "available", []string{io.ColumnsAll, io.ColumnsNumeric})
+		return 1
+	}
+	nx := int(math.Round(1 / c.dx))
+	if nx < 2 {
+		slog.Error("Grid needs at least 2 intervals", "nx", nx)
+		return 1
+	}
+	dx := 1 / float64(nx)
+	nt := int(math.Round(c.tmax / c.dt))
+
+	slog.Info("Simulation parameters", "equation", "Cattaneo τu_tt + u_t = u_xx", "tau", c.tau, "speed", mode.Speed(), "ic", strings.ToLower(c.ic), "dt", c.dt, "tmax", c.tmax, "outfile", c.out)
+	slog.Info("Grid configuration", "nx", nx, "nt", nt, "dx", dx)
+	if c.tau > 0 && c.dt > c.tau/10 {
+		slog.Warn("Time step is not small against tau; the first-order scheme smears the wave fronts", "dt", c.dt, "tau", c.tau)
+	}
+
+	f, err := os.Create(c.out)
+	if err != nil {
+		slog.Error("Failed to create output file", "file", c.out, "error", err)
+		return 1
+	}
+	defer f.Close()
+	w := io.NewCSVLevelWriter(f, dx, c.dt, exact)
+
+	u0 := solver.SineProfile(nx, dx)
+	if tophat {
+		u0 = solver.PulseProfile(nx, dx, 0.25, 0.75)
+	}
+	start := time.Now()
+	last, err := solver.StreamCattaneoFrom(u0, nt, dx, c.dt, 1, c.tau, w.WriteLevel)
+	if err == nil {
+		err = w.Flush()
+	}
+	if err == nil {
+		err = f.Close()
+	}
+	if err != nil {
+		slog.Error("Computation failed", "error", err)
+		return 1
+	}
+	slog.Info("Computation and CSV output completed", "runtime_sec", time.Since(start).Seconds())
+
+	t := float64(nt) * c.dt
+	exactFront := 0.75 + mode.Speed()*t
+	switch {
+	case !tophat:
+		l2, linf := mathutils.LevelErrors(last, dx, t, mode)
+		slog.Info("Error norms", "t", t, "l2", l2, "linf", linf)
+		slog.Info("Mode amplitude", "cattaneo", mode.Amplitude(t), "heat_equation", math.Exp(-math.Pi*math.Pi*t))
+	case c.tau == 0:
+		slog.Info("No wave front with tau = 0: heat spreads at once, as in the heat equation")
+	case exactFront < 1:
+		front := solver.CattaneoFront(last, dx, solver.CattaneoJump(1, c.tau, t)/2)
+		slog.Info("Wave front", "t", t, "x", front, "exact", exactFront, "difference_cells", math.Abs(front-exactFront)/dx)
+	default:
+		slog.Info("The wave front has reached the end; lower -tmax to compare it with ¾ + t/√τ", "t", t)
+	}
+	slog.Info("Results successfully saved", "file", c.out)
+	return 0
+}
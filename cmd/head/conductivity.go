@@ -0,0 +1,125 @@
+package main
+
+import (
+	"flag"
+	"log/slog"
+	"math"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"heat-solver/internal/io"
+	"heat-solver/internal/mathutils"
+	"heat-solver/internal/solver"
+)
+
+// Параметры расчёта с проводимостью k(u) = k0·(1 + β·u)
+type conductivityRun struct {
+	method    string
+	theta     float64
+	k0, beta  float64
+	picardTol float64
+	dx, dt    float64
+	tmax      float64
+	out       string
+	columns   string
+}
+
+// Флаги расчёта с k(u): любой из них, заданный явно, включает его
+var flagsConductivity = []string{"k0", "beta", "picard-tol"}
+
+// Явно задан хотя бы один из флагов names
+func anyFlagSet(names []string) bool {
+	set := false
+	flag.Visit(func(f *flag.Flag) { set = set || slices.Contains(names, f.Name) })
+	return set
+}
+
+// Расчёт u_t = (k(u)·u_x)_x из sin(πx) с нулём на краях: неявный шаг
+// BTCS, CN или THETA с итерациями Пикара на каждом шаге. Слои пишутся в
+// CSV по мере счёта. Точное решение есть только при β = 0
+// (exp(−k0·π²t)·sin(πx)); тогда в конце — нормы ошибки. Код выхода 1 при
+// ошибке
+func runConductivity(c conductivityRun) int {
+	rejected := slices.DeleteFunc(slices.Clone(flags1D), func(name string) bool {
+		return name == "theta" || slices.Contains(flagsConductivity, name)
+	})
+	if !rejectFlags("-k0, -beta and -picard-tol", rejected, flagsRadial...) {
+		return 1
+	}
+	theta, ok := methodTheta("k(u)", c.method, c.theta)
+	if !ok {
+		return 1
+	}
+	if theta == 0 {
+		slog.Error("Picard iteration needs an implicit step: -method BTCS, CN or THETA with -theta > 0")
+		return 1
+	}
+	if !(c.k0 > 0) || math.IsInf(c.k0, 0) {
+		slog.Error("Conductivity k0 must be positive and finite", "k0", c.k0)
+		return 1
+	}
+	if math.IsNaN(c.beta) || math.IsInf(c.beta, 0) {
+		slog.Error("Beta must be finite", "beta", c.beta)
+		return 1
+	}
+	if !(c.picardTol > 0) {
+		slog.Error("Picard tolerance must be positive", "picard_tol", c.picardTol)
+		return 1
+	}
+	k := solver.Conductivity{K0: c.k0, Beta: c.beta}
+	var exact mathutils.Reference
+	switch strings.ToLower(c.columns) {
+	case io.ColumnsAll:
+		if c.beta == 0 {
+			exact = mathutils.Analytical{Alpha: c.k0}
+		} else {
+			slog.Info("No exact solution for beta ≠ 0; the CSV has x,t,u_numeric only")
+		}
+	case io.ColumnsNumeric:
+	default:
+		slog.Error("Unknown CSV columns", "columns", c.columns, "available", []string{io.ColumnsAll, io.ColumnsNumeric})
+		return 1
+	}
+	nx := int(math.Round(1 / c.dx))
+	if nx < 2 {
+		slog.Error("Grid needs at least 2 intervals", "nx", nx)
+		return 1
+	}
+	dx := 1 / float64(nx)
+	nt := int(math.Round(c.tmax / c.dt))
+
+	slog.Info("Simulation parameters", "equation", "k(u) = k0(1 + βu)", "k0", c.k0, "beta", c.beta, "theta", theta, "picard_tol", c.picardTol, "dt", c.dt, "tmax", c.tmax, "outfile", c.out)
+	slog.Info("Grid configuration", "nx", nx, "nt", nt, "dx", dx)
+
+	f, err := os.Create(c.out)
+	if err != nil {
+		slog.Error("Failed to create output file", "file", c.out, "error", err)
+		return 1
+	}
+	defer f.Close()
+	w := io.NewCSVLevelWriter(f, dx, c.dt, exact)
+
+	start := time.Now()
+	last, err := solver.StreamConductivityFrom(solver.SineProfile(nx, dx), nt, dx, c.dt, k, theta, c.picardTol, w.WriteLevel)
+	if err == nil {
+		err = w.Flush()
+	}
+	if err == nil {
+		err = f.Close()
+	}
+	if err != nil {
+		slog.Error("Computation failed", "error", err)
+		return 1
+	}
+	slog.Info("Computation and CSV output completed", "runtime_sec", time.Since(start).Seconds())
+
+	if c.beta == 0 {
+		t := float64(nt) * c.dt
+		l2, linf := mathutils.LevelErrors(last, dx, t, mathutils.Analytical{Alpha: c.k0})
+		slog.Info("Error norms", "t", t, "l2", l2, "linf", linf)
+	}
+	slog.Info("Results successfully saved", "file", c.out)
+	return 0
+}
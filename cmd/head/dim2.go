@@ -0,0 +1,142 @@
+package main
+
+import (
+	"flag"
+	"log/slog"
+	"math"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"heat-solver/internal/io"
+	"heat-solver/internal/mathutils"
+	"heat-solver/internal/solver"
+)
+
+// Параметры расчёта на единичном квадрате (-dim 2)
+type square struct {
+	method  string
+	nx, ny  int
+	dx, dt  float64
+	tmax    float64
+	out     string
+	columns string
+	every   int // шаг по слоям между снимками в CSV
+
+	mask, maskFile string // отверстия или файл маски, см. runMasked2D
+	maskValue      float64
+}
+
+// Флаги одномерного расчёта, которые с -dim 2 и 3 ничего бы не делали
+var flags1D = []string{
+	"theta", "lumped", "rtol", "atol", "tridiagonal", "verify-residual",
+	"storage", "checkpoint-every", "checkpoint-mem", "gif", "gif-stride", "frames-dir",
+	"heat-csv", "modes-csv", "modes", "modes-stride", "modes-norm",
+	"threshold", "check-symmetry", "pipeline", "adapt", "tol", "ic", "rannacher",
+	"spatial-order", "stencil", "velocity", "lambda", "k0", "beta", "picard-tol",
+	"source", "laser-power", "laser-speed", "laser-width", "laser-start",
+	"layers", "layers-file", "alpha2", "hcoef", "ic2", "network", "space-richardson",
+	"compat", "compat-tol", "compat-nodes",
+}
+
+// Явно заданные флаги из names и more; если такие есть, ошибка в лог
+// ("not supported with " + mode) и false
+func rejectFlags(mode string, names []string, more ...string) bool {
+	var unsupported []string
+	flag.Visit(func(f *flag.Flag) {
+		if slices.Contains(names, f.Name) || slices.Contains(more, f.Name) {
+			unsupported = append(unsupported, "-"+f.Name)
+		}
+	})
+	if len(unsupported) > 0 {
+		slog.Error("Flags not supported with "+mode, "flags", unsupported)
+		return false
+	}
+	return true
+}
+
+// Передаёт в emit начальный слой, каждый every-й и последний слой nt;
+// every = 0 — только начальный и последний
+func snapshots(every, nt int, emit solver.EmitFunc) solver.EmitFunc {
+	return func(n int, row []float64) error {
+		if n == 0 || n == nt || (every > 0 && n%every == 0) {
+			return emit(n, row)
+		}
+		return nil
+	}
+}
+
+// Расчёт на квадрате с нулевыми краями из sin(πx)·sin(πy): слои пишутся в
+// CSV по мере счёта (x,y,t,u_numeric,u_exact,error), в конце — нормы
+// ошибки последнего слоя, как в 1D. С маской — runMasked2D. Код выхода 1
+// при ошибке
+func run2D(s square) int {
+	if !rejectFlags("-dim 2", flags1D, "nz", "geometry", "grid", "stretch-factor") {
+		return 1
+	}
+	stream, ok := solver.Lookup2D(s.method)
+	if !ok {
+		slog.Error("Unknown 2D method", "method", s.method, "available", solver.Methods2D())
+		return 1
+	}
+	var exact mathutils.Reference2D
+	switch strings.ToLower(s.columns) {
+	case io.ColumnsAll:
+		exact = mathutils.Analytical2D{Alpha: 1}
+	case io.ColumnsNumeric:
+	default:
+		slog.Error("Unknown CSV columns", "columns", s.columns, "available", []string{io.ColumnsAll, io.ColumnsNumeric})
+		return 1
+	}
+	if s.nx <= 0 {
+		s.nx = int(math.Round(1 / s.dx))
+	}
+	if s.ny <= 0 {
+		s.ny = s.nx
+	}
+	if s.every < 0 {
+		slog.Error("Snapshot stride must not be negative", "snapshot_every", s.every)
+		return 1
+	}
+	if s.nx < 2 || s.ny < 2 {
+		slog.Error("2D grid needs at least 2 intervals per side", "nx", s.nx, "ny", s.ny)
+		return 1
+	}
+	if anyFlagSet(flagsMask) {
+		return runMasked2D(s)
+	}
+	dx, dy := 1/float64(s.nx), 1/float64(s.ny)
+	nt := int(math.Round(s.tmax / s.dt))
+
+	slog.Info("Simulation parameters", "dim", 2, "method", strings.ToUpper(s.method), "dt", s.dt, "tmax", s.tmax, "outfile", s.out)
+	slog.Info("Grid configuration", "nx", s.nx, "ny", s.ny, "nt", nt, "dx", dx, "dy", dy)
+
+	f, err := os.Create(s.out)
+	if err != nil {
+		slog.Error("Failed to create output file", "file", s.out, "error", err)
+		return 1
+	}
+	defer f.Close()
+	w := io.NewCSV2DLevelWriter(f, s.nx, s.ny, dx, dy, s.dt, exact)
+
+	start := time.Now()
+	u0 := solver.SineProfile2D(s.nx, s.ny, dx, dy)
+	last, err := stream(u0, s.nx, s.ny, nt, dx, dy, s.dt, 1, snapshots(s.every, nt, w.WriteLevel))
+	if err == nil {
+		err = w.Flush()
+	}
+	if err == nil {
+		err = f.Close()
+	}
+	if err != nil {
+		slog.Error("Computation failed", "error", err)
+		return 1
+	}
+	slog.Info("Computation and CSV output completed", "runtime_sec", time.Since(start).Seconds())
+
+	l2, linf := mathutils.LevelErrors2D(last, s.nx, s.ny, dx, dy, float64(nt)*s.dt, mathutils.Analytical2D{Alpha: 1})
+	slog.Info("Error norms", "t", float64(nt)*s.dt, "l2", l2, "linf", linf)
+	slog.Info("Results successfully saved", "file", s.out)
+	return 0
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"flag"
+	"log/slog"
+	"math"
+	"os"
+	"strings"
+	"time"
+
+	"heat-solver/internal/io"
+	"heat-solver/internal/solver"
+)
+
+const (
+	equationHeat   = "heat"
+	equationFisher = "fisher"
+	equationPorous = "pme"
+)
+
+// Параметры расчёта Фишера–КПП (-equation fisher)
+type fisherRun struct {
+	method string
+	rho    float64
+	length float64
+	dx, dt float64
+	tmax   float64
+	out    string
+}
+
+// Флаги, которые есть только у -equation fisher, pme, cattaneo и fractional
+var flagsEquation = []string{"rho", "length", "m", "tau", "order", "memory"}
+
+// Расчёт u_t = u_xx + ρu(1 − u) на [0, L] из ступеньки (1 на [0, L/10],
+// 0 правее) с u(0) = 1 и u(L) = 0 расщеплением Стрэнга с CN. Точного
+// решения нет, поэтому CSV — только x,t,u_numeric; в конце — положение
+// фронта u = ½ и его скорость за вторую половину расчёта против
+// 2√ρ. Код выхода 1 при ошибке
+func runFisher(c fisherRun) int {
+	if !rejectFlags("-equation fisher", flags1D, append([]string{"dim", "geometry", "columns", "m", "tau", "order", "memory"}, flagsRadial...)...) {
+		return 1
+	}
+	methodSet := false
+	flag.Visit(func(f *flag.Flag) { methodSet = methodSet || f.Name == "method" })
+	if methodSet && !strings.EqualFold(c.method, "CN") && !strings.EqualFold(c.method, "crank-nicolson") {
+		slog.Error("Fisher–KPP takes the CN diffusion step only", "method", c.method)
+		return 1
+	}
+	if !(c.rho > 0) || math.IsInf(c.rho, 0) {
+		slog.Error("Growth rate must be positive and finite", "rho", c.rho)
+		return 1
+	}
+	if !(c.length > 0) || math.IsInf(c.length, 0) {
+		slog.Error("Domain length must be positive and finite", "length", c.length)
+		return 1
+	}
+	nx := int(math.Round(c.length / c.dx))
+	if nx < 2 {
+		slog.Error("Grid needs at least 2 intervals", "nx", nx)
+		return 1
+	}
+	dx := c.length / float64(nx)
+	nt := int(math.Round(c.tmax / c.dt))
+
+	slog.Info("Simulation parameters", "equation", "Fisher–KPP", "rho", c.rho, "length", c.length, "dt", c.dt, "tmax", c.tmax, "outfile", c.out)
+	slog.Info("Grid configuration", "nx", nx, "nt", nt, "dx", dx)
+
+	f, err := os.Create(c.out)
+	if err != nil {
+		slog.Error("Failed to create output file", "file", c.out, "error", err)
+		return 1
+	}
+	defer f.Close()
+	w := io.NewCSVLevelWriter(f, dx, c.dt, nil)
+
+	half := nt / 2
+	front := math.NaN()
+	emit := func(n int, row []float64) error {
+		if n == half {
+			front = solver.FisherFront(row, dx)
+		}
+		return w.WriteLevel(n, row)
+	}
+	start := time.Now()
+	last, err := solver.StreamFisherFrom(solver.FisherStep(nx, dx, c.length/10), nt, dx, c.dt, 1, c.rho, emit)
+	if err == nil {
+		err = w.Flush()
+	}
+	if err == nil {
+		err = f.Close()
+	}
+	if err != nil {
+		slog.Error("Computation failed", "error", err)
+		return 1
+	}
+	slog.Info("Computation and CSV output completed", "runtime_sec", time.Since(start).Seconds())
+
+	t := float64(nt) * c.dt
+	end := solver.FisherFront(last, dx)
+	speed := (end - front) / (float64(nt-half) * c.dt)
+	slog.Info("Front position", "t", t, "x", end, "speed", speed, "minimum_speed", solver.FisherSpeed(1, c.rho))
+	if end > 0.9*c.length {
+		slog.Warn("Front is close to the right end; lengthen the domain", "x", end, "length", c.length)
+	}
+	slog.Info("Results successfully saved", "file", c.out)
+	return 0
+}
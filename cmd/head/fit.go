@@ -0,0 +1,162 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+
+	"heat-solver/internal/config"
+	"heat-solver/internal/solver"
+)
+
+// Подкоманда fit: подбор α в u_t = α·u_xx по измерениям датчиков из CSV
+// (solver.FitAlpha), в лог — оценка, интервал и невязка; с -out — CSV
+// измерений с расчётом в тех же точках
+func runFit(args []string) int {
+	fs := flag.NewFlagSet("fit", flag.ContinueOnError)
+	data := fs.String("data", "", "CSV of probe samples with columns t, x (or x_probe) and u (or u_measured, u_obs); other columns are ignored")
+	method := fs.String("method", "CN", "Numerical method of the forward solves")
+	dx := fs.Float64("dx", 0.02, "Spatial step of the forward solves")
+	dt := fs.Float64("dt", 0.001, "Time step of the forward solves, in the scaled time α·t")
+	ic := fs.String("ic", config.ICSine, "Initial condition of the forward solves: sine or tophat")
+	alphaMin := fs.Float64("alpha-min", 0.1, "Lower bound of the α search")
+	alphaMax := fs.Float64("alpha-max", 10, "Upper bound of the α search; the forward solve runs to alpha-max times the last sample time")
+	out := fs.String("out", "", "Also write t,x,u_measured,u_model,residual at the fitted α to this CSV file")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *data == "" {
+		slog.Error("fit needs -data with the probe samples")
+		return 2
+	}
+
+	p := config.Params{Method: *method, Dx: *dx, Dt: *dt, Tmax: *dt, IC: strings.ToLower(*ic)}
+	if err := p.Validate(); err != nil {
+		slog.Error("Invalid parameters", "error", err)
+		return 1
+	}
+	samples, err := readProbeSamples(*data)
+	if err != nil {
+		slog.Error("Invalid probe data", "file", *data, "error", err)
+		return 1
+	}
+	slog.Info("Probe data", "file", *data, "samples", len(samples))
+	fit, err := solver.FitAlpha(context.Background(), p, samples, *alphaMin, *alphaMax, nil)
+	if err != nil {
+		slog.Error("Fit failed", "error", err)
+		return 1
+	}
+	slog.Info("Fit result", "method", *method, "alpha", fit.Alpha, "ci95_low", fit.Low, "ci95_high", fit.High, "rms_residual", fit.RMS)
+	if fit.Alpha <= *alphaMin*(1+1e-3) || fit.Alpha >= *alphaMax*(1-1e-3) {
+		slog.Warn("The estimate is at the end of the search range, widen -alpha-min or -alpha-max", "alpha_min", *alphaMin, "alpha_max", *alphaMax)
+	}
+	if *out == "" {
+		return 0
+	}
+	if err := writeFitCSV(*out, samples, fit.Model); err != nil {
+		slog.Error("Error writing fit table", "file", *out, "error", err)
+		return 1
+	}
+	slog.Info("Table saved", "file", *out)
+	return 0
+}
+
+// Измерения из CSV с заголовком; строки с # пропускаются
+func readProbeSamples(file string) ([]solver.ProbeSample, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	cr := csv.NewReader(f)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+	cr.Comment = '#'
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("cannot read CSV header: %w", err)
+	}
+	aliases := map[string]string{"x_probe": "x", "u_measured": "u", "u_obs": "u"}
+	cols := map[string]int{}
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if alias, ok := aliases[name]; ok {
+			name = alias
+		}
+		if _, dup := cols[name]; !dup {
+			cols[name] = i
+		}
+	}
+	for _, name := range []string{"t", "x", "u"} {
+		if _, ok := cols[name]; !ok {
+			return nil, fmt.Errorf("CSV header has no column %s, want t,x,u or t,x_probe,u_measured", name)
+		}
+	}
+
+	var samples []solver.ProbeSample
+	for {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			return samples, nil
+		}
+		line, _ := cr.FieldPos(0)
+		if err != nil {
+			var perr *csv.ParseError
+			if errors.As(err, &perr) {
+				line = perr.Line
+			}
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		var s solver.ProbeSample
+		for _, c := range []struct {
+			name string
+			dst  *float64
+		}{{"t", &s.T}, {"x", &s.X}, {"u", &s.U}} {
+			i := cols[c.name]
+			if i >= len(rec) {
+				return nil, fmt.Errorf("line %d: missing column %s", line, c.name)
+			}
+			if *c.dst, err = strconv.ParseFloat(strings.TrimSpace(rec[i]), 64); err != nil {
+				return nil, fmt.Errorf("line %d: column %s: %q is not a number", line, c.name, rec[i])
+			}
+		}
+		samples = append(samples, s)
+	}
+}
+
+// Измерения и расчёт при подобранном α: t,x,u_measured,u_model,residual
+func writeFitCSV(filename string, samples []solver.ProbeSample, model []float64) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	fmt.Fprintln(w, "t,x,u_measured,u_model,residual")
+	var buf []byte
+	for i, s := range samples {
+		buf = buf[:0]
+		for _, v := range []float64{s.T, s.X, s.U, model[i]} {
+			buf = strconv.AppendFloat(buf, v, 'g', -1, 64)
+			buf = append(buf, ',')
+		}
+		buf = strconv.AppendFloat(buf, s.U-model[i], 'g', -1, 64)
+		buf = append(buf, '\n')
+		if _, err := w.Write(buf); err != nil {
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"flag"
+	"log/slog"
+	"math"
+	"os"
+	"strings"
+	"time"
+
+	"heat-solver/internal/io"
+	"heat-solver/internal/mathutils"
+	"heat-solver/internal/solver"
+)
+
+const equationFractional = "fractional"
+
+// Параметры расчёта дробного по времени уравнения (-equation fractional)
+type fractionalRun struct {
+	method  string
+	order   float64
+	memory  int
+	dx, dt  float64
+	tmax    float64
+	out     string
+	columns string
+}
+
+// Расчёт ∂^β u/∂t^β = u_xx (производная Капуто) на [0, 1] с нулём на краях
+// из sin(πx) схемой L1 solver.StreamFractionalFrom; порядок β задаёт
+// -order, а -beta остаётся коэффициентом k(u) и здесь отвергается. Точное
+// решение —
+// mathutils.FractionalMode, в конце — нормы ошибки и амплитуда
+// E_β(−π²t^β) против exp(−π²t) уравнения теплопроводности. Слои пишутся в
+// CSV по мере счёта. Код выхода 1 при ошибке
+func runFractional(c fractionalRun) int {
+	if !rejectFlags("-equation fractional", flags1D, append([]string{"dim", "geometry", "rho", "length", "m", "tau"}, flagsRadial...)...) {
+		return 1
+	}
+	methodSet := false
+	flag.Visit(func(f *flag.Flag) {
+		methodSet = methodSet || f.Name == "method"
+	})
+	if methodSet && !strings.EqualFold(c.method, "BTCS") {
+		slog.Error("The fractional equation takes its implicit L1 scheme, -method BTCS, only", "method", c.method)
+		return 1
+	}
+	if !(c.order > 0 && c.order <= 1) {
+		slog.Error("Fractional order must be in (0, 1]", "order", c.order)
+		return 1
+	}
+	if c.memory < 0 {
+		slog.Error("Memory must be non-negative", "memory", c.memory)
+		return 1
+	}
+	mode := mathutils.FractionalMode{Alpha: 1, Beta: c.order}
+	var exact mathutils.Reference
+	switch strings.ToLower(c.columns) {
+	case io.ColumnsAll:
+		exact = mode
+	case io.ColumnsNumeric:
+	default:
+		slog.Error("Unknown CSV columns", "columns", c.columns, "available", []string{io.ColumnsAll, io.ColumnsNumeric})
+		return 1
+	}
+	nx := int(math.Round(1 / c.dx))
+	if nx < 2 {
+		slog.Error("Grid needs at least 2 intervals", "nx", nx)
+		return 1
+	}
+	dx := 1 / float64(nx)
+	nt := int(math.Round(c.tmax / c.dt))
+
+	slog.Info("Simulation parameters", "equation", "Caputo ∂^β u/∂t^β = u_xx", "order", c.order, "memory", c.memory, "dt", c.dt, "tmax", c.tmax, "outfile", c.out)
+	slog.Info("Grid configuration", "nx", nx, "nt", nt, "dx", dx)
+
+	f, err := os.Create(c.out)
+	if err != nil {
+		slog.Error("Failed to create output file", "file", c.out, "error", err)
+		return 1
+	}
+	defer f.Close()
+	w := io.NewCSVLevelWriter(f, dx, c.dt, exact)
+
+	start := time.Now()
+	last, err := solver.StreamFractionalFrom(solver.SineProfile(nx, dx), nt, dx, c.dt, 1, c.order, c.memory, w.WriteLevel)
+	if err == nil {
+		err = w.Flush()
+	}
+	if err == nil {
+		err = f.Close()
+	}
+	if err != nil {
+		slog.Error("Computation failed", "error", err)
+		return 1
+	}
+	slog.Info("Computation and CSV output completed", "runtime_sec", time.Since(start).Seconds())
+
+	t := float64(nt) * c.dt
+	l2, linf := mathutils.LevelErrors(last, dx, t, mode)
+	slog.Info("Error norms", "t", t, "l2", l2, "linf", linf)
+	slog.Info("Mode amplitude", "mittag_leffler", mode.Amplitude(t), "heat_equation", math.Exp(-math.Pi*math.Pi*t))
+	slog.Info("Results successfully saved", "file", c.out)
+	return 0
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+// Порядок -order проверяется на (0, 1], а -beta (коэффициент k(u)) с
+// -equation fractional отвергается, а не читается как порядок
+func TestRunFractionalRejects(t *testing.T) {
+	good := fractionalRun{order: 0.5, dx: 0.1, dt: 0.01, tmax: 0.05, columns: "all"}
+	tests := []struct {
+		name  string
+		flags map[string]string
+		edit  func(c *fractionalRun)
+	}{
+		{"zero order", map[string]string{"order": "0"}, func(c *fractionalRun) { c.order = 0 }},
+		{"negative order", map[string]string{"order": "-0.5"}, func(c *fractionalRun) { c.order = -0.5 }},
+		{"order above 1", map[string]string{"order": "1.5"}, func(c *fractionalRun) { c.order = 1.5 }},
+		{"NaN order", map[string]string{"order": "NaN"}, func(c *fractionalRun) { c.order = math.NaN() }},
+		{"beta", map[string]string{"beta": "0.5"}, func(*fractionalRun) {}},
+		{"beta zero", map[string]string{"beta": "0"}, func(*fractionalRun) {}},
+		{"method CN", map[string]string{"method": "CN"}, func(c *fractionalRun) { c.method = "CN" }},
+		{"negative memory", map[string]string{"memory": "-1"}, func(c *fractionalRun) { c.memory = -1 }},
+		{"with tau", map[string]string{"tau": "0.1"}, func(*fractionalRun) {}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			setFlags(t, tc.flags)
+			c := good
+			c.out = filepath.Join(t.TempDir(), "out.csv")
+			tc.edit(&c)
+			if code := runFractional(c); code != 1 {
+				t.Errorf("exit code %d, want 1", code)
+			}
+		})
+	}
+}
+
+// Допустимые порядки, включая β = 1, и -method BTCS дают CSV
+func TestRunFractional(t *testing.T) {
+	for _, order := range []float64{0.3, 1} {
+		setFlags(t, map[string]string{"method": "BTCS", "order": strconv.FormatFloat(order, 'g', -1, 64)})
+		out := filepath.Join(t.TempDir(), "out.csv")
+		c := fractionalRun{method: "BTCS", order: order, memory: 2, dx: 0.1, dt: 0.01, tmax: 0.05, out: out, columns: "all"}
+		if code := runFractional(c); code != 0 {
+			t.Fatalf("order %g: exit code %d", order, code)
+		}
+		if fi, err := os.Stat(out); err != nil || fi.Size() == 0 {
+			t.Errorf("order %g: output %v", order, err)
+		}
+	}
+}
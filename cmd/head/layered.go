@@ -0,0 +1,176 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log/slog"
+	"math"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"heat-solver/internal/io"
+	"heat-solver/internal/mathutils"
+	"heat-solver/internal/solver"
+)
+
+// Параметры расчёта составной стенки (-layers или -layers-file)
+type layeredRun struct {
+	method      string
+	theta       float64
+	layers      string
+	layersFile  string
+	left, right float64
+	dx, dt      float64
+	tmax        float64
+	out         string
+	columns     string
+}
+
+// Флаги составной стенки: любой из них включает её расчёт
+var flagsLayers = []string{"layers", "layers-file"}
+
+// Края стенки по умолчанию (если -bc-left или -bc-right не заданы):
+// нагрев слева, u(0) = 1, u(L) = 0
+const (
+	layeredLeft  = 1.0
+	layeredRight = 0.0
+)
+
+// Файл слоёв -layers-file: {"layers": [{"thickness": 0.3, "conductivity": 1,
+// "capacity": 1}, …]}; capacity можно опустить
+type layersFile struct {
+	Layers mathutils.Slab `json:"layers"`
+}
+
+// Слои из -layers или -layers-file (задать можно только одно из них)
+func loadLayers(spec, file string) (mathutils.Slab, error) {
+	switch {
+	case spec != "" && file != "":
+		return nil, fmt.Errorf("-layers and -layers-file are mutually exclusive")
+	case file == "":
+		return mathutils.ParseLayers(spec)
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var lf layersFile
+	if err := json.Unmarshal(data, &lf); err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	if err := lf.Layers.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	return lf.Layers, nil
+}
+
+// Расчёт c(x)·u_t = (k(x)·u_x)_x на составной стенке [0, L] из нуля с
+// u(0) = -bc-left и u(L) = -bc-right (по умолчанию 1 и 0): θ-схема
+// solver.StreamLayeredFrom, -method BTCS, CN (по умолчанию), FTCS или THETA.
+// Слои пишутся в CSV по мере счёта со столбцом номера слоя узла. В конце —
+// температуры на границах слоёв и перепады на слоях против стационарного
+// расчёта по последовательным сопротивлениям. Код выхода 1 при ошибке
+func runLayered(c layeredRun) int {
+	rejected := slices.DeleteFunc(slices.Clone(flags1D), func(name string) bool {
+		return name == "theta" || slices.Contains(flagsLayers, name)
+	})
+	if !rejectFlags("-layers", rejected, flagsRadial...) {
+		return 1
+	}
+	slab, err := loadLayers(c.layers, c.layersFile)
+	if err != nil {
+		slog.Error("Invalid layers", "error", err)
+		return 1
+	}
+	theta, ok := methodTheta("layered slab", c.method, c.theta)
+	if !ok {
+		return 1
+	}
+	switch strings.ToLower(c.columns) {
+	case io.ColumnsAll:
+		slog.Info("No exact solution for the transient slab; the CSV has x,layer,t,u_numeric only")
+	case io.ColumnsNumeric:
+	default:
+		slog.Error("Unknown CSV columns", "columns", c.columns, "available", []string{io.ColumnsAll, io.ColumnsNumeric})
+		return 1
+	}
+	leftSet, rightSet := false, false
+	flag.Visit(func(f *flag.Flag) {
+		leftSet = leftSet || f.Name == "bc-left"
+		rightSet = rightSet || f.Name == "bc-right"
+	})
+	if !leftSet {
+		c.left = layeredLeft
+	}
+	if !rightSet {
+		c.right = layeredRight
+	}
+	for _, v := range []float64{c.left, c.right} {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			slog.Error("Boundary values must be finite", "bc_left", c.left, "bc_right", c.right)
+			return 1
+		}
+	}
+	length := slab.Length()
+	nx := int(math.Round(length / c.dx))
+	if nx < 2 {
+		slog.Error("Grid needs at least 2 intervals", "nx", nx)
+		return 1
+	}
+	dx := length / float64(nx)
+	nt := int(math.Round(c.tmax / c.dt))
+
+	slog.Info("Simulation parameters", "equation", "layered slab", "layers", len(slab), "length", length, "bc_left", c.left, "bc_right", c.right, "theta", theta, "dt", c.dt, "tmax", c.tmax, "outfile", c.out)
+	slog.Info("Grid configuration", "nx", nx, "nt", nt, "dx", dx)
+	for _, x := range slab.Interfaces() {
+		if s := x / dx; math.Abs(s-math.Round(s)) > 1e-9 {
+			slog.Info("Some layer interfaces fall inside cells; their temperatures come from the flux between the nodes", "interface", x, "dx", dx)
+			break
+		}
+	}
+
+	f, err := os.Create(c.out)
+	if err != nil {
+		slog.Error("Failed to create output file", "file", c.out, "error", err)
+		return 1
+	}
+	defer f.Close()
+	layers := make([]int, nx+1)
+	for i := range layers {
+		layers[i] = slab.LayerAt(float64(i) * dx)
+	}
+	w := io.NewCSVLayersLevelWriter(f, dx, c.dt, layers)
+
+	start := time.Now()
+	last, err := solver.StreamLayeredFrom(solver.LayeredStart(nx, c.left, c.right), nt, dx, c.dt, theta, slab, w.WriteLevel)
+	if err == nil {
+		err = w.Flush()
+	}
+	if err == nil {
+		err = f.Close()
+	}
+	if err != nil {
+		slog.Error("Computation failed", "error", err)
+		return 1
+	}
+	slog.Info("Computation and CSV output completed", "runtime_sec", time.Since(start).Seconds())
+
+	temps := solver.InterfaceTemperatures(last, dx, slab)
+	drops, series := solver.LayerDrops(last, dx, slab), slab.SeriesDrops(c.left, c.right)
+	var worst float64
+	for j, l := range slab {
+		slog.Info("Layer temperature drop", "layer", j, "thickness", l.Thickness, "conductivity", l.Conductivity,
+			"u_left", temps[j], "u_right", temps[j+1], "drop", drops[j], "series_drop", series[j])
+		worst = math.Max(worst, math.Abs(drops[j]-series[j]))
+	}
+	t := float64(nt) * c.dt
+	slog.Info("Drops against series thermal resistance", "t", t, "max_difference", worst)
+	if worst > 1e-6*math.Max(math.Abs(c.left-c.right), 1) {
+		slog.Info("The slab has not reached the steady state yet; raise -tmax for the series drops", "t", t)
+	}
+	slog.Info("Results successfully saved", "file", c.out)
+	return 0
+}
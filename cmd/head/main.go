@@ -1,22 +1,101 @@
 package main
 
 import (
+	"bufio"
+	"context"
 	"flag"
+	"fmt"
+	goio "io"
 	"log/slog"
+	"math"
 	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
 	"heat-solver/internal/config"
 	"heat-solver/internal/io"
+	"heat-solver/internal/mathutils"
+	"heat-solver/internal/render"
 	"heat-solver/internal/solver"
 )
 
 func main() {
-	method := flag.String("method", "FTCS", "Numerical method: FTCS, BTCS, or CN")
+	method := flag.String("method", "FTCS", "Numerical method: FTCS, BTCS, BTCS-RICHARDSON (BTCS-RE, second order by extrapolation), CN, BDF2, RK4, RK45 (adaptive, see -rtol), EXPONENTIAL (exact in time), SPECTRAL, FEM (see -lumped), FV (finite volumes, see -theta), DUFORT-FRANKEL (DF), SAULYEV (ADE, explicit and unconditionally stable), HOPSCOTCH (odd–even hopscotch, no tridiagonal solve), RKC (Runge–Kutta–Chebyshev super-steps, explicit with the stage count chosen from r), theta (see -theta), or STEADY (the steady state −u″ = f without time stepping, see -source)")
+	theta := flag.Float64("theta", 0.5, "Weight of the implicit part for -method theta, FEM and FV, from 0 (FTCS) through 0.5 (CN) to 1 (BTCS)")
+	lumped := flag.Bool("lumped", false, "Lumped (diagonal) mass matrix for -method FEM instead of the consistent one")
+	rtol := flag.Float64("rtol", solver.DefaultRTol, "Relative error tolerance per step for -method RK45")
+	atol := flag.Float64("atol", solver.DefaultATol, "Absolute error tolerance per step for -method RK45")
+	adapt := flag.Bool("adapt", false, "Choose the BTCS or CN time step by step doubling to meet -tol; -dt is then the output interval and the largest step")
+	tol := flag.Float64("tol", config.DefaultTol, "Local error tolerance per step (max norm) for -adapt")
+	spatialOrder := flag.Int("spatial-order", 2, "Order of accuracy in x: 2 (three-point differences) or 4 (see -stencil)")
+	stencil := flag.String("stencil", config.StencilCompact, "Fourth-order stencil for -spatial-order 4: compact ((1 + dx²/12 δ²) u_t = δ²u/dx², still tridiagonal; -method BTCS and CN) or wide (five points (−1, 16, −30, 16, −1)/12dx²; -method FTCS with r ≤ 3/8, BTCS and CN with pentadiagonal solves)")
+	ic := flag.String("ic", config.ICSine, "Initial condition: sine (sin(πx)) or tophat (1 on (1/4, 3/4), 0 outside)")
+	rannacher := flag.Bool("rannacher", false, "Rannacher startup for -method CN: the first two steps are four BTCS half-steps, which damp the oscillations of discontinuous initial data (default: on with -ic tophat)")
+	source := flag.String("source", "", "Volumetric source f(x, t) of u_t = u_xx + f for -method FTCS, BTCS and CN: a preset (sine = sin(πx), gaussian = exp(−100(x − ½)²), manufactured, whose exact solution from -ic sine is cos t·sin(πx)) or an expression in x and t with + - * / ^, pi, e and sin, cos, tan, exp, log, sqrt, abs, sinh, cosh, tanh, e.g. \"sin(pi*x)\", which from any start settles to sin(πx)/π²; with -method STEADY f of −u″ = f, a number or an expression in x only")
+	laserPower := flag.Float64("laser-power", mathutils.DefaultLaser.Power, "Peak power density P of -source laser, f = P·exp(−(x − x₀ − vt)²/2σ²)")
+	laserSpeed := flag.Float64("laser-speed", mathutils.DefaultLaser.Speed, "Scanning speed v of -source laser (0: a fixed beam); the beam keeps its shape after its centre leaves [0, 1], and only its tail heats the rod")
+	laserWidth := flag.Float64("laser-width", mathutils.DefaultLaser.Width, "Beam width σ > 0 of -source laser")
+	laserStart := flag.Float64("laser-start", mathutils.DefaultLaser.Start, "Beam centre x₀ of -source laser at t = 0")
+	bcLeft := flag.String("bc-left", "", "Boundary condition at x = 0 as kind:g: dirichlet:g (u = g) or neumann:g (∂u/∂x = g, neumann:0 insulates), g a number or an expression in t; a plain number is dirichlet. robin:h=10,uamb=0 (optional k=1) is the convective −k·∂u/∂n = h·(u − uamb) with the outward normal n. Time stepping with -bc-left or -bc-right runs -method FTCS, BTCS, CN (default) or THETA with ghost nodes at the Neumann and Robin ends from the first mode of the chosen boundaries (cos(πx/2) for neumann:0 on the left), or from u = 1 with a Robin end; -method STEADY and -layers take constant dirichlet values only (default 1 for -layers)")
+	bcRight := flag.String("bc-right", "", "Boundary condition at x = 1 (x = L for -layers) as kind:g, see -bc-left")
+	compat := flag.String("compat", compatWarn, "With -bc-left or -bc-right in time stepping: what to do when the initial condition does not match a boundary condition at t = 0 by more than -compat-tol: warn (log the mismatch and go on), smooth (correct the -compat-nodes nodes next to that end) or rannacher (start -method CN with BTCS half-steps)")
+	compatTol := flag.Float64("compat-tol", 1e-3, "Largest accepted mismatch of -compat: |u0 − g(0)| at a dirichlet end, dx·|∂u0/∂n − required| at a neumann or robin end")
+	compatNodes := flag.Int("compat-nodes", 4, "Nodes next to each mismatched end that -compat smooth corrects, from 2 to nx/2")
+	layers := flag.String("layers", "", "Composite slab of layers with their own thickness, conductivity k and heat capacity c as thickness:alpha (c = 1) or thickness:k:c, comma-separated, e.g. \"0.3:1.0,0.4:0.1,0.3:1.0\"; runs c·u_t = (k·u_x)_x on [0, L] from zero with -bc-left and -bc-right, -method FTCS, BTCS, CN (default) or THETA, writes a layer column to the CSV and compares the final drops across the layers with series thermal resistance")
+	layersFile := flag.String("layers-file", "", "JSON file with the layers of -layers: {\"layers\": [{\"thickness\": 0.3, \"conductivity\": 1, \"capacity\": 1}, ...]}")
+	networkFile := flag.String("network", "", "JSON topology of a network of rods joined at nodes, e.g. a T-junction: {\"nodes\": [{\"name\": \"J\"}, {\"name\": \"A\", \"temperature\": 1}, ...], \"segments\": [{\"name\": \"left\", \"from\": \"A\", \"to\": \"J\", \"length\": 0.5, \"nx\": 10, \"alpha\": 1}, ...]}; nodes without a temperature share one value with zero net flux (Kirchhoff). Runs from zero with -method FTCS, BTCS, CN (default) or THETA and writes one CSV per segment (-out with the _segment suffix) and the node temperatures (_nodes)")
+	alpha2 := flag.Float64("alpha2", 0.1, "Diffusivity α₂ > 0 of the second field v of the two-temperature model u_t = u_xx − h(u − v), v_t = α₂v_xx + h(u − v); setting -alpha2, -hcoef or -ic2 runs this model with -method FTCS, BTCS, CN (default) or THETA and writes u and v to the CSV")
+	hcoef := flag.Float64("hcoef", 1, "Exchange coefficient h ≥ 0 of the two-temperature model; large h locks u and v together, as one field with α = (1 + α₂)/2")
+	ic2 := flag.String("ic2", icZero, "Initial condition of v in the two-temperature model: zero, sine or tophat (u starts from -ic)")
+	lambda := flag.Float64("lambda", 0, "Linear reaction coefficient λ ≥ 0 of u_t = u_xx − λu for -method FTCS, BTCS and CN; the reference becomes exp(−(π² + λ)t)·sin(πx), and the CSV starts with a '# lambda=…' line")
+	equation := flag.String("equation", equationHeat, "Equation: heat (u_t = u_xx), fisher (Fisher–KPP u_t = u_xx + ρu(1 − u) on [0, -length] from a step, Strang splitting with CN, see -rho), pme (porous medium u_t = (u^m)_xx on [0, -length] from a Barenblatt profile, BTCS with Picard iterations, see -m), cattaneo (hyperbolic τu_tt + u_t = u_xx on [0, 1] with waves of speed 1/√τ, a three-level implicit scheme, see -tau) or fractional (Caputo time-fractional ∂^β u/∂t^β = u_xx on [0, 1] from sin(πx), the L1 scheme, see -order and -memory)")
+	order := flag.Float64("order", 0.5, "Order 0 < β ≤ 1 of the Caputo time derivative of -equation fractional; 1 gives BTCS exactly")
+	memory := flag.Int("memory", 0, "History levels that -equation fractional sums exactly; older levels go in blocks of this length, so the cost per step drops from O(n) to O(memory + n/memory), best near √nt. 0 keeps the full O(nt²) history")
+	tau := flag.Float64("tau", 0.05, "Relaxation time τ ≥ 0 of -equation cattaneo; 0 gives BTCS of the heat equation exactly")
+	rho := flag.Float64("rho", 1.0, "Growth rate ρ > 0 of -equation fisher; the front settles to the speed 2√ρ")
+	length := flag.Float64("length", 50, "Domain length L of -equation fisher (u(0) = 1, u(L) = 0, the step at L/10) and pme (zero at both ends, the Barenblatt support of half-width L/10 centred at L/2)")
+	pmeM := flag.Float64("m", 2, "Exponent m > 1 of -equation pme, u_t = (u^m)_xx")
+	velocity := flag.Float64("velocity", 0, "Advection velocity v of u_t + v·u_x = u_xx for -dim 1 on the uniform grid; nonzero values compare against exp(-π²t)·sin(π(x − vt)) and take -method FTCS (first-order upwind), BTCS, CN or THETA (central differences)")
+	k0 := flag.Float64("k0", 1, "Conductivity k0 of u_t = (k(u)·u_x)_x with k(u) = k0·(1 + βu); setting -k0, -beta or -picard-tol runs this equation with -method BTCS, CN or THETA and Picard iterations in every step")
+	beta := flag.Float64("beta", 0, "Temperature coefficient β of k(u) = k0·(1 + βu); 0 with -k0 1 reproduces BTCS exactly")
+	picardTol := flag.Float64("picard-tol", solver.DefaultPicardTol, "Picard iterations stop when the max-norm update is at most this value")
 	dx := flag.Float64("dx", 0.1, "Spatial step size")
+	dim := flag.Int("dim", 1, "Space dimension: 1 (the segment [0, 1]), 2 (the unit square, -method FTCS, ADI or LOD) or 3 (the unit cube, Douglas-Gunn ADI)")
+	nx2 := flag.Int("nx", 0, "Intervals along x for -dim 2 and 3 (0: 1/dx)")
+	ny2 := flag.Int("ny", 0, "Intervals along y for -dim 2 and 3 (0: the same as along x)")
+	nz3 := flag.Int("nz", 0, "Intervals along z for -dim 3 (0: the same as along x)")
+	spaceRichardson := flag.Bool("space-richardson", false, "Also solve on a grid with dx/2 at the same dt and write (4·u_{dx/2} − u_dx)/3 on the dx nodes as the u_richardson column: Richardson extrapolation to fourth order in space for second-order schemes (-dim 1 only)")
+	maskSpec := flag.String("mask", "", "Holes cut from the -dim 2 square: x0:x1:y0:y1 rectangles separated by commas, or L for an L-shaped plate (the hole 0.5:1:0.5:1); masked nodes are a Dirichlet boundary at -mask-value, -method FTCS, ADI or LOD")
+	maskFile := flag.String("mask-file", "", "Text grid of the -dim 2 mask, one line per row of nodes from y = 1 down to y = 0: '#' or '0' masked, '.' or '1' active (commas and spaces ignored); it sets -nx and -ny")
+	maskValue := flag.Float64("mask-value", 0, "Temperature of the masked nodes for -mask and -mask-file")
+	snapshotEvery := flag.Int("snapshot-every", 1, "Time levels between CSV snapshots for -dim 2 and 3 (0: the initial and final level only; the final level is always written)")
+	geometry := flag.String("geometry", geometryCartesian, "Geometry for -dim 1: cartesian (the segment [0, 1]), cylindrical (the radius r in [0, 1] of an axisymmetric cylinder, u_t = u_rr + u_r/r) or spherical (of a sphere, u_t = u_rr + 2u_r/r); the radial ones take -method FTCS, BTCS, CN or THETA")
+	grid := flag.String("grid", gridUniform, "Spatial grid for -dim 1 -geometry cartesian: uniform or stretch (nodes clustered at both ends by a tanh map, see -stretch-factor; -method FTCS, BTCS, CN or THETA)")
+	stretchFactor := flag.Float64("stretch-factor", 2.0, "Clustering strength β of -grid stretch, x = ½(1 + tanh(β(2ξ−1))/tanh β); 0 gives a uniform grid, 2 makes the edge step about 0.15 of the mean")
 	dt := flag.Float64("dt", 0.001, "Time step size")
 	tmax := flag.Float64("tmax", 1.0, "Maximum simulation time")
 	outfile := flag.String("out", "results.csv", "Output CSV file")
+	gifFile := flag.String("gif", "", "Also write an animated GIF of the profile to this file")
+	gifStride := flag.Int("gif-stride", 10, "Time levels per GIF frame")
+	framesDir := flag.String("frames-dir", "", "Also write each animation frame (every -gif-stride levels) as a PNG file to this directory")
+	threads := flag.Int("threads", 0, "Threads for FTCS, the parallel tridiagonal solver on large grids and animation frame encoding (0: GOMAXPROCS)")
+	tridiag := flag.String("tridiagonal", solver.TridiagThomas, "Tridiagonal solver for BTCS and CN: thomas or parallel")
+	verifyResidual := flag.String("verify-residual", solver.ResidualOff, "Check the residual of every BTCS and CN step: off, warn (log) or strict (stop the solve)")
+	storage := flag.String("storage", config.StorageFull, "Time levels to keep: full, final-only (initial and final level only) or checkpoint (every K-th level, the rest recomputed for output)")
+	checkpointEvery := flag.Int("checkpoint-every", 0, "Time steps between checkpoints with -storage checkpoint (0: from -checkpoint-mem)")
+	checkpointMem := flag.Int64("checkpoint-mem", solver.DefaultCheckpointMemory>>20, "Memory budget for checkpoints in MiB with -storage checkpoint")
+	columns := flag.String("columns", io.ColumnsAll, "CSV columns: all (with u_exact and error) or numeric (x,t,u_numeric only)")
+	heatCSV := flag.String("heat-csv", "", "Also write the heat content Q(t) = ∫u dx of every level with the exact one ((2/π)exp(-π²t) for -ic sine) to this CSV file")
+	modesCSV := flag.String("modes-csv", "", "Also write the coefficients of the first -modes sine modes of every -modes-stride-th level as t,a_1,...,a_M to this CSV file")
+	modeCount := flag.Int("modes", 10, "Sine modes in -modes-csv (at most nx-1)")
+	modesStride := flag.Int("modes-stride", 1, "Time levels between rows of -modes-csv")
+	modesNorm := flag.String("modes-norm", mathutils.ModeAmplitude, "Normalization of -modes-csv: amplitude (u = Σ a_k sin(kπx)), orthonormal (basis √2 sin(kπx)) or relative (a_k(t)/a_k(0))")
+	threshold := flag.String("threshold", "", "Report when max_x u first drops below each of these values (comma-separated, e.g. 0.5,0.1)")
+	checkSymmetry := flag.Bool("check-symmetry", false, "Check that every level stays symmetric about x = 1/2 and fail the run if the asymmetry exceeds round-off (1e-12 of max|u0|)")
+	pipeline := flag.Bool("pipeline", false, "Write CSV levels while solving instead of after the solve (full storage, no GIF)")
 
 	flag.Parse()
 
@@ -24,13 +103,268 @@ func main() {
 		Level: slog.LevelInfo,
 	}))
 	slog.SetDefault(logger)
+	// θ-схема регистрируется по требованию: при -method theta или явном
+	// -theta (тогда THETA видна и подкомандам, например
+	// work-precision -methods CN,THETA)
+	thetaSet := strings.EqualFold(*method, "theta")
+	flag.Visit(func(f *flag.Flag) { thetaSet = thetaSet || f.Name == "theta" })
+	if thetaSet {
+		m, err := solver.ThetaMethod(*theta)
+		if err != nil {
+			slog.Error("Invalid -theta", "error", err)
+			os.Exit(1)
+		}
+		solver.Register(m)
+	}
+	// Так же МКЭ: при -method FEM или явном -lumped, с θ из -theta
+	femSet := strings.EqualFold(*method, "fem")
+	flag.Visit(func(f *flag.Flag) { femSet = femSet || f.Name == "lumped" })
+	if femSet {
+		m, err := solver.FEMMethod(*theta, *lumped)
+		if err != nil {
+			slog.Error("Invalid -theta", "error", err)
+			os.Exit(1)
+		}
+		solver.Register(m)
+	}
+	// И конечные объёмы: при -method FV
+	if strings.EqualFold(*method, "fv") {
+		m, err := solver.FVMethod(*theta)
+		if err != nil {
+			slog.Error("Invalid -theta", "error", err)
+			os.Exit(1)
+		}
+		solver.Register(m)
+	}
+	// Допуски RK45 действуют и в подкомандах (verify)
+	if err := solver.SetRK45Tolerance(*rtol, *atol); err != nil {
+		slog.Error("Invalid RK45 tolerances", "error", err)
+		os.Exit(1)
+	}
+	// Подкоманды идут после флагов расчёта: heat stability -method CN -r 5
+	switch flag.Arg(0) {
+	case "stability":
+		os.Exit(runStability(flag.Args()[1:]))
+	case "error-split":
+		os.Exit(runErrorSplit(flag.Args()[1:]))
+	case "suggest":
+		os.Exit(runSuggest(flag.Args()[1:]))
+	case "work-precision":
+		os.Exit(runWorkPrecision(flag.Args()[1:]))
+	case "verify":
+		os.Exit(runVerify(flag.Args()[1:]))
+	case "fit":
+		os.Exit(runFit(flag.Args()[1:]))
+	}
+	solver.SetThreads(*threads)
+	if err := solver.SetTridiagonal(*tridiag); err != nil {
+		slog.Error("Invalid tridiagonal solver", "error", err)
+		os.Exit(1)
+	}
+	if err := solver.SetResidualCheck(*verifyResidual); err != nil {
+		slog.Error("Invalid residual check", "error", err)
+		os.Exit(1)
+	}
+	solver.SetSymmetryCheck(*checkSymmetry)
+	if *dim != 2 && !rejectFlags(fmt.Sprintf("-dim %d", *dim), flagsMask) {
+		os.Exit(1)
+	}
+	switch strings.ToLower(*equation) {
+	case equationHeat:
+		if !rejectFlags("-equation heat", flagsEquation) {
+			os.Exit(1)
+		}
+	case equationFisher:
+		os.Exit(runFisher(fisherRun{method: *method, rho: *rho, length: *length, dx: *dx, dt: *dt, tmax: *tmax, out: *outfile}))
+	case equationPorous:
+		os.Exit(runPorous(porousRun{method: *method, m: *pmeM, length: *length, picardTol: *picardTol, dx: *dx, dt: *dt, tmax: *tmax, out: *outfile, columns: *columns}))
+	case equationCattaneo:
+		os.Exit(runCattaneo(cattaneoRun{method: *method, tau: *tau, ic: *ic, dx: *dx, dt: *dt, tmax: *tmax, out: *outfile, columns: *columns}))
+	case equationFractional:
+		os.Exit(runFractional(fractionalRun{method: *method, order: *order, memory: *memory, dx: *dx, dt: *dt, tmax: *tmax, out: *outfile, columns: *columns}))
+	default:
+		slog.Error("Unknown equation", "equation", *equation, "available", []string{equationHeat, equationFisher, equationPorous, equationCattaneo, equationFractional})
+		os.Exit(1)
+	}
+	left, right, ok := parseBoundaries(*bcLeft, *bcRight)
+	if !ok {
+		os.Exit(1)
+	}
+	if strings.EqualFold(*method, methodSteady) {
+		u0, u1, ok := dirichletValues("-method STEADY", left, right)
+		if !ok {
+			os.Exit(1)
+		}
+		os.Exit(runSteady(steady{dx: *dx, source: *source, left: u0, right: u1, out: *outfile, columns: *columns}))
+	}
+	uniform1D := *dim == 1 && strings.EqualFold(*geometry, geometryCartesian) && strings.EqualFold(*grid, gridUniform)
+	if !uniform1D && !rejectFlags("time stepping outside -dim 1 on the uniform grid", flagsSteady, flagsCompat...) {
+		os.Exit(1)
+	}
+	switch *dim {
+	case 1:
+		switch strings.ToLower(*geometry) {
+		case geometryCartesian:
+			switch strings.ToLower(*grid) {
+			case gridUniform:
+				if !rejectFlags("-grid uniform", nil, "stretch-factor") {
+					os.Exit(1)
+				}
+				if anyFlagSet(flagsLayers) {
+					u0, u1, ok := dirichletValues("-layers", left, right)
+					if !ok {
+						os.Exit(1)
+					}
+					os.Exit(runLayered(layeredRun{method: *method, theta: *theta, layers: *layers, layersFile: *layersFile, left: u0, right: u1, dx: *dx, dt: *dt, tmax: *tmax, out: *outfile, columns: *columns}))
+				}
+				if anyFlagSet(flagsSteady) || anyFlagSet(flagsCompat) {
+					os.Exit(runBoundary(boundaryRun{method: *method, theta: *theta, left: left, right: right, dx: *dx, dt: *dt, tmax: *tmax, out: *outfile, columns: *columns, compat: *compat, compatTol: *compatTol, compatNodes: *compatNodes}))
+				}
+				if *networkFile != "" {
+					os.Exit(runNetwork(networkRun{method: *method, theta: *theta, file: *networkFile, dt: *dt, tmax: *tmax, out: *outfile, columns: *columns}))
+				}
+				if anyFlagSet(flagsTwoTemperature) {
+					os.Exit(runTwoTemperature(twoTemperatureRun{method: *method, theta: *theta, alpha2: *alpha2, h: *hcoef, ic: *ic, ic2: *ic2, dx: *dx, dt: *dt, tmax: *tmax, out: *outfile, columns: *columns}))
+				}
+				if anyFlagSet(flagsConductivity) {
+					os.Exit(runConductivity(conductivityRun{method: *method, theta: *theta, k0: *k0, beta: *beta, picardTol: *picardTol, dx: *dx, dt: *dt, tmax: *tmax, out: *outfile, columns: *columns}))
+				}
+				if *velocity != 0 {
+					os.Exit(runAdvection(advection{method: *method, theta: *theta, velocity: *velocity, dx: *dx, dt: *dt, tmax: *tmax, out: *outfile, columns: *columns}))
+				}
+				if *spaceRichardson {
+					os.Exit(runSpaceRichardson(spaceRichardsonRun{method: *method, ic: *ic, dx: *dx, dt: *dt, tmax: *tmax, out: *outfile, columns: *columns}))
+				}
+			case gridStretch:
+				os.Exit(runStretched(stretched{method: *method, theta: *theta, factor: *stretchFactor, dx: *dx, dt: *dt, tmax: *tmax, out: *outfile, columns: *columns}))
+			default:
+				slog.Error("Unknown grid", "grid", *grid, "available", []string{gridUniform, gridStretch})
+				os.Exit(1)
+			}
+		case geometryCylindrical, geometrySpherical:
+			os.Exit(runRadial(radialRun{geometry: strings.ToLower(*geometry), method: *method, theta: *theta, dr: *dx, dt: *dt, tmax: *tmax, out: *outfile, columns: *columns}))
+		default:
+			slog.Error("Unknown geometry", "geometry", *geometry, "available", []string{geometryCartesian, geometryCylindrical, geometrySpherical})
+			os.Exit(1)
+		}
+	case 2:
+		os.Exit(run2D(square{method: *method, nx: *nx2, ny: *ny2, dx: *dx, dt: *dt, tmax: *tmax, out: *outfile, columns: *columns, every: *snapshotEvery, mask: *maskSpec, maskFile: *maskFile, maskValue: *maskValue}))
+	case 3:
+		os.Exit(run3D(box{method: *method, nx: *nx2, ny: *ny2, nz: *nz3, dx: *dx, dt: *dt, tmax: *tmax, out: *outfile, columns: *columns, every: *snapshotEvery}))
+	default:
+		slog.Error("Unsupported dimension", "dim", *dim, "available", []int{1, 2, 3})
+		os.Exit(1)
+	}
 
 	params := config.Params{
-		Method:  *method,
-		Dx:      *dx,
-		Dt:      *dt,
-		Tmax:    *tmax,
-		Outfile: *outfile,
+		Method:       *method,
+		Dx:           *dx,
+		Dt:           *dt,
+		Tmax:         *tmax,
+		Outfile:      *outfile,
+		Storage:      strings.ToLower(*storage),
+		Adapt:        *adapt,
+		Tol:          *tol,
+		IC:           strings.ToLower(*ic),
+		SpatialOrder: *spatialOrder,
+		Lambda:       *lambda,
+		Source:       *source,
+	}
+	if math.IsNaN(*lambda) || math.IsInf(*lambda, 0) || *lambda < 0 {
+		slog.Error("Invalid -lambda", "lambda", *lambda)
+		os.Exit(1)
+	}
+	// Метаданные CSV: строки "# ..." перед заголовком
+	var csvMeta []string
+	if params.Lambda != 0 {
+		csvMeta = append(csvMeta, fmt.Sprintf("lambda=%g", params.Lambda))
+	}
+	if params.Source != "" {
+		csvMeta = append(csvMeta, "source="+params.Source)
+	}
+	if params.LaserSource() {
+		params.Laser = mathutils.Laser{Power: *laserPower, Speed: *laserSpeed, Width: *laserWidth, Start: *laserStart}
+		csvMeta = append(csvMeta, fmt.Sprintf("laser_power=%g laser_speed=%g laser_width=%g laser_start=%g",
+			params.Laser.Power, params.Laser.Speed, params.Laser.Width, params.Laser.Start))
+	} else if !rejectFlags("-source other than laser", flagsLaser) {
+		os.Exit(1)
+	}
+	if _, err := params.ParseSource(); err != nil {
+		slog.Error("Invalid -source", "error", err)
+		os.Exit(1)
+	}
+	if params.LaserSource() {
+		logLaser(params.Laser, params.Tmax)
+	}
+	if *spatialOrder != 2 && *spatialOrder != 4 {
+		slog.Error("Invalid -spatial-order", "spatial_order", *spatialOrder, "available", []int{2, 4})
+		os.Exit(1)
+	}
+	switch strings.ToLower(*stencil) {
+	case config.StencilCompact, config.StencilWide:
+	default:
+		slog.Error("Unknown stencil", "stencil", *stencil, "available", []string{config.StencilCompact, config.StencilWide})
+		os.Exit(1)
+	}
+	if *spatialOrder == 4 {
+		params.Stencil = strings.ToLower(*stencil)
+	} else if !rejectFlags("-spatial-order 2", nil, "stencil") {
+		os.Exit(1)
+	}
+	switch params.IC {
+	case config.ICSine, config.ICTopHat:
+	default:
+		slog.Error("Unknown initial condition", "ic", *ic, "available", []string{config.ICSine, config.ICTopHat})
+		os.Exit(1)
+	}
+	// Без явного -rannacher старт включается у CN для ступеньки
+	rannacherSet := false
+	flag.Visit(func(f *flag.Flag) { rannacherSet = rannacherSet || f.Name == "rannacher" })
+	params.Rannacher = *rannacher
+	if !rannacherSet {
+		params.Rannacher = solver.RannacherByDefault(params)
+	}
+	if !*adapt && !rejectFlags("fixed time steps (no -adapt)", nil, "tol") {
+		os.Exit(1)
+	}
+	if *adapt && !(*tol > 0) {
+		slog.Error("Invalid -tol", "tol", *tol)
+		os.Exit(1)
+	}
+	// Адаптивная схема раскладывает матрицы сама, прогонкой Томаса
+	if *adapt && !rejectFlags("-adapt", nil, "tridiagonal", "verify-residual", "rannacher") {
+		os.Exit(1)
+	}
+	switch params.Storage {
+	case config.StorageFull, config.StorageFinalOnly, solver.StorageCheckpoint:
+	default:
+		slog.Error("Unknown storage mode", "storage", params.Storage, "available", []string{config.StorageFull, config.StorageFinalOnly, solver.StorageCheckpoint})
+		os.Exit(1)
+	}
+	// Эталон — точное решение для начального условия; без него столбцы не
+	// считаются
+	var exact mathutils.Reference
+	switch strings.ToLower(*columns) {
+	case io.ColumnsAll:
+		exact = solver.PresetReference(params)
+	case io.ColumnsNumeric:
+	default:
+		slog.Error("Unknown CSV columns", "columns", *columns, "available", []string{io.ColumnsAll, io.ColumnsNumeric})
+		os.Exit(1)
+	}
+	thresholds, err := parseThresholds(*threshold)
+	if err != nil {
+		slog.Error("Invalid -threshold", "error", err)
+		os.Exit(1)
+	}
+	animate := *gifFile != "" || *framesDir != ""
+	if params.Storage != config.StorageFull && animate {
+		slog.Error("GIF and PNG frames need every time level in memory, they can only be written with full storage")
+		os.Exit(1)
+	}
+	if *pipeline && (params.Storage != config.StorageFull || animate) {
+		slog.Error("Pipelined output writes every level as it is computed, it needs full storage and cannot be combined with GIF or PNG frames")
+		os.Exit(1)
 	}
 
 	nx := int(1.0 / params.Dx)
@@ -42,32 +376,484 @@ func main() {
 		"dt", params.Dt,
 		"tmax", params.Tmax,
 		"outfile", params.Outfile,
+		"storage", params.Storage,
+		"pipeline", *pipeline,
+		"ic", params.IC,
+		"rannacher", params.Rannacher,
+		"spatial_order", params.SpatialOrder,
+		"stencil", params.Stencil,
+		"lambda", params.Lambda,
+		"source", params.Source,
 	)
 	slog.Info("Grid configuration", "nx", nx, "nt", nt)
 
 	start := time.Now()
 
-	var u [][]float64
-
-	switch params.Method {
-	case "FTCS":
-		u = solver.SolveFTCS(nx, nt, params.Dx, params.Dt)
-	case "BTCS":
-		u = solver.SolveBTCS(nx, nt, params.Dx, params.Dt)
-	case "CN":
-		u = solver.SolveCrankNicolson(nx, nt, params.Dx, params.Dt)
-	default:
-		slog.Error("Unknown method", "method", params.Method)
+	m, ok := solver.Lookup(params.Method)
+	if !ok {
+		slog.Error("Unknown method", "method", params.Method, "available", solver.Methods())
+		os.Exit(1)
+	}
+	// История шагов пишет каждый расчёт через m; в лог — после расчёта
+	var steps []solver.AdaptiveStep
+	if params.Adapt {
+		if m, err = solver.AdaptiveMethod(m, params.Tol, &steps); err != nil {
+			slog.Error("Invalid -adapt", "error", err)
+			os.Exit(1)
+		}
+	}
+	if params.Rannacher {
+		if m, err = solver.RannacherMethod(m); err != nil {
+			slog.Error("Invalid -rannacher", "error", err)
+			os.Exit(1)
+		}
+	}
+	if params.Lambda != 0 {
+		if m, err = solver.ReactionMethod(m, params.Lambda); err != nil {
+			slog.Error("Invalid -lambda", "error", err)
+			os.Exit(1)
+		}
+	}
+	if params.Source != "" {
+		src, _ := params.ParseSource()
+		if m, err = solver.SourceMethod(m, src); err != nil {
+			slog.Error("Invalid -source", "error", err)
+			os.Exit(1)
+		}
+	}
+	switch {
+	case params.Stencil == config.StencilWide:
+		if m, err = solver.WideMethod(m); err != nil {
+			slog.Error("Invalid -stencil", "error", err)
+			os.Exit(1)
+		}
+	case params.SpatialOrder == 4:
+		if m, err = solver.CompactMethod(m); err != nil {
+			slog.Error("Invalid -spatial-order", "error", err)
+			os.Exit(1)
+		}
+	}
+	if m.System != nil {
+		slog.Info("Step matrix", "r", params.Dt/(params.Dx*params.Dx), "condition", solver.StepCondition(m, nx, params.Dx, params.Dt))
+	}
+	u0 := solver.InitialProfile(params, nx)
+	principle := solver.NewPrincipleMonitor(u0)
+	peaks := principle.TracePeaks(nt + 1)
+	audit := solver.NewFluxAudit(params.Dx, params.Dt).WithSource(m.Source)
+	var symmetry *solver.SymmetryMonitor
+	if *checkSymmetry {
+		symmetry = solver.NewSymmetryMonitor(u0)
+	}
+	// В CSV слои идут с шагом levelDt: в final-only это 0 и nt·dt
+	levelDt := params.Dt
+	if params.Storage == config.StorageFinalOnly {
+		levelDt = params.Dt * float64(nt)
+	}
+	heat, err := openHeat(*heatCSV, params.Dx, levelDt, solver.PresetReference(params))
+	if err != nil {
+		slog.Error("Failed to create heat content file", "file", *heatCSV, "error", err)
+		os.Exit(1)
+	}
+	modes, err := openModes(*modesCSV, nx, *modeCount, *modesStride, levelDt, strings.ToLower(*modesNorm))
+	if err != nil {
+		slog.Error("Failed to create mode coefficients file", "file", *modesCSV, "error", err)
 		os.Exit(1)
 	}
+	if *pipeline {
+		var last []float64
+		err := writeCSV(params.Outfile, params.Dx, params.Dt, exact, csvMeta, func(emit solver.EmitFunc) error {
+			p := solver.NewPipeline(pipelineDepth, len(u0), modes.tee(heat.tee(emit)))
+			var err error
+			last, err = m.Stream(u0, nt, params.Dx, params.Dt, func(n int, row []float64) error {
+				principle.Check(n, row)
+				audit.Add(n, row)
+				symmetry.Check(n, row)
+				return p.Emit(n, row)
+			})
+			// Ошибка расчёта или записи возвращается одна
+			return p.Close(err)
+		})
+		if err != nil {
+			slog.Error("Pipelined computation failed", "error", err)
+			os.Exit(1)
+		}
+		slog.Info("Computation and CSV output completed", "runtime_sec", time.Since(start).Seconds())
+		logPrinciple(principle.Report(), params.Source != "")
+		logCrossings(peaks.Crossings(params.Dt, params.Dx, thresholds...), float64(nt)*params.Dt)
+		logFlux(audit.Report(last))
+		logSteps(steps, params.Dt)
+		slog.Info("Results successfully saved", "file", params.Outfile)
+		heat.close()
+		modes.close(m, params.Dx, params.Dt)
+		checkSymmetric(*checkSymmetry, symmetry.Report())
+		return
+	}
+	if params.Storage == solver.StorageCheckpoint {
+		// Слои между контрольными пересчитываются при записи CSV
+		every := *checkpointEvery
+		if every <= 0 {
+			every = solver.CheckpointEvery(nx, nt, *checkpointMem<<20)
+		}
+		res, err := solver.Checkpointed(context.Background(), params, every, solver.Hooks{})
+		if err != nil {
+			slog.Error("Computation failed", "error", err)
+			os.Exit(1)
+		}
+		slog.Info("Computation completed", "runtime_sec", time.Since(start).Seconds(), "checkpoint_mib", float64(res.Bytes())/(1<<20))
+		logPrinciple(res.MaxPrinciple, params.Source != "")
+		logCrossings(res.Crossings(thresholds...), float64(nt)*params.Dt)
+		logFlux(res.Flux)
+		checkSymmetric(*checkSymmetry, res.Symmetry)
+		if err := writeCSV(params.Outfile, params.Dx, params.Dt, exact, csvMeta, func(emit solver.EmitFunc) error {
+			return res.Each(modes.tee(heat.tee(emit)))
+		}); err != nil {
+			slog.Error("Error saving results", "error", err)
+			os.Exit(1)
+		}
+		slog.Info("Results successfully saved", "file", params.Outfile, "runtime_sec", time.Since(start).Seconds())
+		heat.close()
+		modes.close(m, params.Dx, params.Dt)
+		return
+	}
+	var u [][]float64
+	if params.Storage == config.StorageFinalOnly {
+		var first []float64
+		last, err := m.Stream(u0, nt, params.Dx, params.Dt, func(n int, row []float64) error {
+			principle.Check(n, row)
+			audit.Add(n, row)
+			symmetry.Check(n, row)
+			if n == 0 {
+				first = slices.Clone(row)
+			}
+			return nil
+		})
+		if err != nil {
+			slog.Error("Computation failed", "error", err)
+			os.Exit(1)
+		}
+		u = [][]float64{first, last}
+	} else {
+		if *verifyResidual == solver.ResidualStrict {
+			// Solve не возвращает ошибок: слои собираются из Stream, который
+			// останавливается на первой невязке выше допуска
+			u, err = solveStrict(m, u0, nt, params.Dx, params.Dt)
+			if err != nil {
+				slog.Error("Computation failed", "error", err)
+				os.Exit(1)
+			}
+		} else {
+			u = m.Solve(u0, nt, params.Dx, params.Dt)
+		}
+		for n, row := range u {
+			principle.Check(n, row)
+			audit.Add(n, row)
+			symmetry.Check(n, row)
+		}
+	}
 
 	elapsed := time.Since(start)
 	slog.Info("Computation completed", "runtime_sec", elapsed.Seconds())
+	logPrinciple(principle.Report(), params.Source != "")
+	logCrossings(peaks.Crossings(params.Dt, params.Dx, thresholds...), float64(nt)*params.Dt)
+	logFlux(audit.Report(u[len(u)-1]))
+	logSteps(steps, params.Dt)
+	checkSymmetric(*checkSymmetry, symmetry.Report())
 
-	if err := io.SaveToCSV(u, params.Dx, params.Dt, params.Outfile); err != nil {
+	if err := io.SaveToCSV(u, params.Dx, levelDt, params.Outfile, exact, csvMeta...); err != nil {
 		slog.Error("Error saving results", "error", err)
 		os.Exit(1)
 	}
 
 	slog.Info("Results successfully saved", "file", params.Outfile)
+	if heat != nil {
+		for n, row := range u {
+			if err := heat.w.WriteLevel(n, row); err != nil {
+				slog.Error("Error writing heat content", "error", err)
+				os.Exit(1)
+			}
+		}
+		heat.close()
+	}
+	if modes != nil {
+		for n, row := range u {
+			if err := modes.w.WriteLevel(n, row); err != nil {
+				slog.Error("Error writing mode coefficients", "error", err)
+				os.Exit(1)
+			}
+		}
+		modes.close(m, params.Dx, params.Dt)
+	}
+
+	anim := animationOptions(*gifStride, *threads)
+	if *gifFile != "" {
+		if err := writeGIF(*gifFile, u, anim); err != nil {
+			slog.Error("Error writing GIF", "error", err)
+			os.Exit(1)
+		}
+		slog.Info("Animation saved", "file", *gifFile)
+	}
+	if *framesDir != "" {
+		if err := writePNGFrames(*framesDir, u, anim); err != nil {
+			slog.Error("Error writing PNG frames", "error", err)
+			os.Exit(1)
+		}
+		slog.Info("PNG frames saved", "dir", *framesDir, "frames", render.FrameCount(len(u), max(1, *gifStride)))
+	}
+}
+
+// Все слои через Stream, чтобы ошибка схемы не терялась
+func solveStrict(m solver.Method, u0 []float64, nt int, dx, dt float64) ([][]float64, error) {
+	u := make([][]float64, nt+1)
+	_, err := m.Stream(u0, nt, dx, dt, func(n int, row []float64) error {
+		u[n] = slices.Clone(row)
+		return nil
+	})
+	return u, err
+}
+
+// Итог проверки принципа максимума: нарушения — предупреждением. С
+// источником (sourced) принцип не действует, и выход за границы данных —
+// только сведения
+func logPrinciple(mp solver.MaxPrinciple, sourced bool) {
+	if sourced {
+		slog.Info("Maximum principle does not apply with a source term", "min", mp.Lo, "max", mp.Hi, "nodes_outside", mp.Count)
+		return
+	}
+	if mp.Count == 0 {
+		slog.Info("Maximum principle holds", "min", mp.Lo, "max", mp.Hi)
+		return
+	}
+	slog.Warn("Maximum principle violated", "min", mp.Lo, "max", mp.Hi, "violations", mp.Count,
+		"first_step", mp.First.Step, "first_node", mp.First.Node, "first_magnitude", mp.First.Magnitude,
+		"worst_step", mp.Worst.Step, "worst_node", mp.Worst.Node, "worst_magnitude", mp.Worst.Magnitude)
+}
+
+// Пороги из -threshold через запятую
+func parseThresholds(s string) ([]float64, error) {
+	var out []float64
+	for part := range strings.SplitSeq(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		v, err := strconv.ParseFloat(part, 64)
+		if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+			return nil, fmt.Errorf("threshold %q is not a finite number", part)
+		}
+		out = append(out, v)
+	}
+	return out, nil
+}
+
+// Баланс тепла: изменение ∫u dx против притока через границы и тепла от
+// источника. С источником — ещё наибольший небаланс одного шага против
+// наибольшего члена баланса
+func logFlux(b solver.FluxBalance) {
+	slog.Info("Heat balance", "q_initial", b.Initial, "q_final", b.Final, "boundary_inflow", b.Inflow,
+		"source_supplied", b.Supplied, "imbalance", b.Imbalance, "relative_imbalance", b.Relative)
+	if b.PeakRate > 0 {
+		slog.Info("Per-step heat balance", "worst_imbalance", b.StepImbalance, "step", b.StepWorst,
+			"peak_rate", b.PeakRate, "relative", b.StepImbalance/b.PeakRate)
+	}
+}
+
+// История шагов -adapt по интервалам выдачи: сколько шагов, отказов и
+// какие dt ушли на каждый. Подряд идущие интервалы, пройденные одним
+// шагом dt без отказов, сводятся в одну строку. В конце — итог
+func logSteps(steps []solver.AdaptiveStep, dt float64) {
+	if steps == nil {
+		return
+	}
+	from, to := 0, 0 // интервалы с одним полным шагом, ещё не выведенные
+	flush := func() {
+		if from > 0 {
+			slog.Info("Adaptive steps", "levels", fmt.Sprintf("%d-%d", from, to), "t", float64(to)*dt, "steps", to-from+1, "rejected", 0, "dt_min", dt, "dt_max", dt)
+			from = 0
+		}
+	}
+	var rejected int
+	hmin, hmax := math.Inf(1), 0.0
+	for i, n := 0, 1; i < len(steps); n++ {
+		tOut := float64(n) * dt
+		j, rej := i, 0
+		lo, hi := math.Inf(1), 0.0
+		for ; j < len(steps) && steps[j].T <= tOut*(1+1e-12); j++ {
+			rej += steps[j].Rejected
+			lo, hi = min(lo, steps[j].Dt), max(hi, steps[j].Dt)
+		}
+		rejected += rej
+		hmin, hmax = min(hmin, lo), max(hmax, hi)
+		if j == i+1 && rej == 0 && math.Abs(lo-dt) <= 1e-9*dt {
+			if from == 0 {
+				from = n
+			}
+			to = n
+		} else {
+			flush()
+			slog.Info("Adaptive steps", "levels", strconv.Itoa(n), "t", tOut, "steps", j-i, "rejected", rej, "dt_min", lo, "dt_max", hi)
+		}
+		i = j
+	}
+	flush()
+	slog.Info("Adaptive step control", "accepted", len(steps), "rejected", rejected, "dt_min", hmin, "dt_max", hmax, "output_dt", dt)
+}
+
+// Итог -check-symmetry; асимметрия выше допуска завершает программу с
+// кодом 1
+func checkSymmetric(enabled bool, s solver.Symmetry) {
+	switch {
+	case !enabled:
+	case !s.Checked:
+		slog.Warn("Symmetry not checked, the initial profile is not symmetric", "asymmetry", s.Initial, "tolerance", s.Tolerance)
+	case s.Broken():
+		slog.Error("Symmetry broken", "asymmetry", s.Max, "tolerance", s.Tolerance, "step", s.Step, "node", s.Node)
+		os.Exit(1)
+	default:
+		slog.Info("Symmetry preserved", "asymmetry", s.Max, "tolerance", s.Tolerance)
+	}
+}
+
+// Моменты опускания max_x u ниже порогов -threshold
+func logCrossings(cs []solver.Crossing, tmax float64) {
+	for _, c := range cs {
+		if !c.Reached {
+			slog.Info("Threshold not reached", "threshold", c.Threshold, "tmax", tmax)
+			continue
+		}
+		slog.Info("Threshold crossed", "threshold", c.Threshold, "t", c.Time, "x_max", c.X, "step", c.Step)
+	}
+}
+
+// Таблица теплосодержания (-heat-csv); nil — не записывается
+type heatOutput struct {
+	f    *os.File
+	name string
+	w    *io.HeatCSVWriter
+}
+
+// Эталон — (2/π)·exp(-π²t) для sin(πx) при α = 1. Пустое имя — nil
+func openHeat(filename string, dx, dt float64, exact mathutils.Reference) (*heatOutput, error) {
+	if filename == "" {
+		return nil, nil
+	}
+	f, err := os.Create(filename)
+	if err != nil {
+		return nil, err
+	}
+	return &heatOutput{f: f, name: filename, w: io.NewHeatCSVWriter(f, dx, dt, exact)}, nil
+}
+
+// emit и затем запись теплосодержания того же слоя
+func (h *heatOutput) tee(emit solver.EmitFunc) solver.EmitFunc {
+	if h == nil {
+		return emit
+	}
+	return func(n int, row []float64) error {
+		if err := emit(n, row); err != nil {
+			return err
+		}
+		return h.w.WriteLevel(n, row)
+	}
+}
+
+// Дозапись файла и сводка в лог; при ошибке записи — выход
+func (h *heatOutput) close() {
+	if h == nil {
+		return
+	}
+	err := h.w.Flush()
+	if cerr := h.f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		slog.Error("Error writing heat content", "file", h.name, "error", err)
+		os.Exit(1)
+	}
+	b := h.w.Balance()
+	slog.Info("Heat content saved", "file", h.name, "levels", b.Levels,
+		"q_initial", b.Initial, "q_final", b.Final, "max_drift", b.MaxDrift, "max_rel_error", b.MaxError)
+}
+
+// Слоёв в очереди между расчётом и записью CSV
+const pipelineDepth = 8
+
+// Запись CSV в формате SaveToCSV по мере выдачи слоёв: levels вызывает
+// emit для каждого слоя по порядку (расчёт через конвейер или пересчёт от
+// контрольных слоёв), вся история в памяти не нужна. comments — строки
+// метаданных перед заголовком
+func writeCSV(filename string, dx, dt float64, exact mathutils.Reference, comments []string, levels func(emit solver.EmitFunc) error) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := io.NewCSVLevelWriter(f, dx, dt, exact)
+	for _, line := range comments {
+		w.Comment(line)
+	}
+	if err := levels(w.WriteLevel); err != nil {
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// Кадры GIF и PNG-файлов одинаковые: профиль 400×200 каждые stride слоёв
+func animationOptions(stride, workers int) render.AnimationOptions {
+	return render.AnimationOptions{
+		Width:   400,
+		Height:  200,
+		Stride:  stride,
+		Delay:   5,
+		Workers: workers,
+	}
+}
+
+func writeGIF(filename string, u [][]float64, opts render.AnimationOptions) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	if err := render.WriteAnimation(context.Background(), w, u, opts); err != nil {
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// Кадры frame_00000.png, frame_00001.png, … в каталоге dir (создаётся
+// при необходимости)
+func writePNGFrames(dir string, u [][]float64, opts render.AnimationOptions) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	return render.WritePNGFrames(context.Background(), u, opts, func(frame int) (goio.WriteCloser, error) {
+		return os.Create(filepath.Join(dir, fmt.Sprintf("frame_%05d.png", frame)))
+	})
+}
+
+// Флаги пучка -source laser
+var flagsLaser = []string{"laser-power", "laser-speed", "laser-width", "laser-start"}
+
+// Путь пучка: где центр и сколько мощности попадает на отрезок в начале и
+// в конце, и когда центр уходит с отрезка. После ухода пучок не
+// обрезается — на отрезок попадает только хвост гауссианы
+func logLaser(l mathutils.Laser, tmax float64) {
+	slog.Info("Laser beam", "power", l.Power, "speed", l.Speed, "width", l.Width,
+		"center_start", l.Center(0), "center_end", l.Center(tmax),
+		"deposited_start", l.Deposited(0), "deposited_end", l.Deposited(tmax))
+	switch exit := l.Exit(); {
+	case exit == 0:
+		slog.Warn("Laser beam centre starts outside [0, 1]; only its tail heats the rod", "center", l.Start)
+	case exit < tmax:
+		slog.Info("Laser beam centre leaves [0, 1] before tmax; the rod then cools", "t_exit", exit, "tmax", tmax)
+	}
 }
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log/slog"
+	"math"
+	"os"
+	"strings"
+	"time"
+
+	"heat-solver/internal/io"
+	"heat-solver/internal/mathutils"
+	"heat-solver/internal/solver"
+)
+
+// Флаги маски квадрата: только с -dim 2
+var flagsMask = []string{"mask", "mask-file", "mask-value"}
+
+// Маска из -mask или -mask-file (задать можно только одно из них). Сетка
+// -mask-file задаёт nx и ny сама; явно заданные -nx и -ny должны с ней
+// совпадать
+func loadMask(s square) (mathutils.Mask2D, error) {
+	switch {
+	case s.mask != "" && s.maskFile != "":
+		return mathutils.Mask2D{}, fmt.Errorf("-mask and -mask-file are mutually exclusive")
+	case s.mask == "" && s.maskFile == "":
+		return mathutils.Mask2D{}, fmt.Errorf("-mask-value needs -mask or -mask-file")
+	case s.mask != "":
+		holes, err := mathutils.ParseMaskHoles(s.mask)
+		if err != nil {
+			return mathutils.Mask2D{}, err
+		}
+		return mathutils.NewMaskHoles(s.nx, s.ny, holes, s.maskValue), nil
+	}
+	f, err := os.Open(s.maskFile)
+	if err != nil {
+		return mathutils.Mask2D{}, err
+	}
+	defer f.Close()
+	m, err := mathutils.ReadMaskGrid(f, s.maskValue)
+	if err != nil {
+		return m, fmt.Errorf("%s: %w", s.maskFile, err)
+	}
+	var mismatch error
+	flag.Visit(func(fl *flag.Flag) {
+		if (fl.Name == "nx" && s.nx != m.Nx) || (fl.Name == "ny" && s.ny != m.Ny) {
+			mismatch = fmt.Errorf("%s is a %d×%d grid, but -nx %d -ny %d", s.maskFile, m.Nx, m.Ny, s.nx, s.ny)
+		}
+	})
+	return m, mismatch
+}
+
+// Расчёт на квадрате с маской (-mask или -mask-file): выключенные узлы
+// держат -mask-value, края — ноль, начало — sin(πx)·sin(πy) во
+// включённых узлах. Слои пишутся в CSV x,y,active,t,u_numeric, в конце —
+// наибольшее значение во включённых узлах и, при нулевой -mask-value,
+// скорость его затухания между tmax/2 и tmax. Точного решения нет. Код
+// выхода 1 при ошибке
+func runMasked2D(s square) int {
+	stream, ok := solver.LookupMasked2D(s.method)
+	if !ok {
+		slog.Error("Unknown 2D method", "method", s.method, "available", solver.MethodsMasked2D())
+		return 1
+	}
+	switch strings.ToLower(s.columns) {
+	case io.ColumnsAll:
+		slog.Info("No exact solution on a masked square; the CSV has x,y,active,t,u_numeric only")
+	case io.ColumnsNumeric:
+	default:
+		slog.Error("Unknown CSV columns", "columns", s.columns, "available", []string{io.ColumnsAll, io.ColumnsNumeric})
+		return 1
+	}
+	mask, err := loadMask(s)
+	if err != nil {
+		slog.Error("Invalid mask", "error", err)
+		return 1
+	}
+	if mask.Active() == 0 {
+		slog.Error("Mask leaves no active interior nodes", "nx", mask.Nx, "ny", mask.Ny)
+		return 1
+	}
+	dx, dy := 1/float64(mask.Nx), 1/float64(mask.Ny)
+	nt := int(math.Round(s.tmax / s.dt))
+
+	slog.Info("Simulation parameters", "dim", 2, "method", strings.ToUpper(s.method), "dt", s.dt, "tmax", s.tmax, "outfile", s.out)
+	slog.Info("Grid configuration", "nx", mask.Nx, "ny", mask.Ny, "nt", nt, "dx", dx, "dy", dy, "active", mask.Active(), "mask_value", mask.Value)
+
+	f, err := os.Create(s.out)
+	if err != nil {
+		slog.Error("Failed to create output file", "file", s.out, "error", err)
+		return 1
+	}
+	defer f.Close()
+	w := io.NewCSVMaskedLevelWriter(f, mask, s.dt)
+
+	start := time.Now()
+	mid := nt / 2
+	var peaks [2]float64
+	write := snapshots(s.every, nt, w.WriteLevel)
+	emit := func(n int, row []float64) error {
+		if n == mid {
+			peaks[0] = solver.MaskedMax(row, mask)
+		}
+		return write(n, row)
+	}
+	last, err := stream(solver.SineProfile2D(mask.Nx, mask.Ny, dx, dy), mask, nt, s.dt, 1, emit)
+	if err == nil {
+		err = w.Flush()
+	}
+	if err == nil {
+		err = f.Close()
+	}
+	if err != nil {
+		slog.Error("Computation failed", "error", err)
+		return 1
+	}
+	slog.Info("Computation and CSV output completed", "runtime_sec", time.Since(start).Seconds())
+
+	peaks[1] = solver.MaskedMax(last, mask)
+	args := []any{"t", float64(nt) * s.dt, "max_active", peaks[1]}
+	if nt > mid && mid > 0 && mask.Value == 0 {
+		args = append(args, "decay_rate", -math.Log(peaks[1]/peaks[0])/(float64(nt-mid)*s.dt))
+	}
+	slog.Info("Interior maximum", args...)
+	slog.Info("Results successfully saved", "file", s.out)
+	return 0
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"log/slog"
+	"math"
+	"os"
+
+	"heat-solver/internal/io"
+	"heat-solver/internal/solver"
+)
+
+// Таблица коэффициентов синус-мод (-modes-csv); nil — не записывается
+type modesOutput struct {
+	f    *os.File
+	name string
+	w    *io.ModesCSVWriter
+}
+
+// nx — интервалов сетки, dt — время между передаваемыми слоями. Пустое
+// имя — nil
+func openModes(filename string, nx, modes, stride int, dt float64, norm string) (*modesOutput, error) {
+	if filename == "" {
+		return nil, nil
+	}
+	f, err := os.Create(filename)
+	if err != nil {
+		return nil, err
+	}
+	w, err := io.NewModesCSVWriter(f, nx, modes, stride, dt, norm)
+	if err != nil {
+		f.Close()
+		os.Remove(filename)
+		return nil, err
+	}
+	return &modesOutput{f: f, name: filename, w: w}, nil
+}
+
+// emit и затем строка коэффициентов того же слоя
+func (o *modesOutput) tee(emit solver.EmitFunc) solver.EmitFunc {
+	if o == nil {
+		return emit
+	}
+	return func(n int, row []float64) error {
+		if err := emit(n, row); err != nil {
+			return err
+		}
+		return o.w.WriteLevel(n, row)
+	}
+}
+
+// Дозапись файла и сводка затухания мод: множитель за шаг dt по первому и
+// последнему записанному слою против G(kπ·dx) схемы и exp(−k²π²dt)
+// задачи. Моды, которых нет в начальном профиле, пропускаются
+func (o *modesOutput) close(m solver.Method, dx, dt float64) {
+	if o == nil {
+		return
+	}
+	err := o.w.Flush()
+	if cerr := o.f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		slog.Error("Error writing mode coefficients", "file", o.name, "error", err)
+		os.Exit(1)
+	}
+	slog.Info("Mode coefficients saved", "file", o.name)
+
+	first, last, t0, t1 := o.w.Span()
+	steps := math.Round((t1 - t0) / dt)
+	if steps < 1 {
+		return
+	}
+	var scale float64
+	for _, v := range first {
+		scale = max(scale, math.Abs(v))
+	}
+	for k, a0 := range first {
+		if math.Abs(a0) <= 1e-12*scale {
+			continue
+		}
+		ratio := last[k] / a0
+		measured := math.Copysign(math.Pow(math.Abs(ratio), 1/steps), ratio)
+		theta := float64(k+1) * math.Pi * dx
+		scheme := math.NaN()
+		if m.Amplification != nil {
+			scheme = m.Amplification(dt/(dx*dx), theta)
+		}
+		slog.Info("Mode decay per step", "k", k+1, "measured", measured, "scheme", scheme,
+			"exact", math.Exp(-theta*theta*dt/(dx*dx)-m.Reaction*dt))
+	}
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"heat-solver/internal/io"
+	"heat-solver/internal/mathutils"
+	"heat-solver/internal/solver"
+)
+
+// Параметры расчёта сети стержней (-network)
+type networkRun struct {
+	method  string
+	theta   float64
+	file    string
+	dt      float64
+	tmax    float64
+	out     string
+	columns string
+}
+
+// Сеть из файла топологии (JSON, см. mathutils.Network)
+func loadNetwork(file string) (mathutils.Network, error) {
+	var net mathutils.Network
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return net, err
+	}
+	if err := json.Unmarshal(data, &net); err != nil {
+		return net, fmt.Errorf("%s: %w", file, err)
+	}
+	if err := net.Validate(); err != nil {
+		return net, fmt.Errorf("%s: %w", file, err)
+	}
+	return net, nil
+}
+
+// Имя файла рядом с out с суффиксом: results.csv → results_left.csv
+func networkFile(out, suffix string) string {
+	ext := filepath.Ext(out)
+	return strings.TrimSuffix(out, ext) + "_" + suffix + ext
+}
+
+// Расчёт сети стержней из -network: на каждом стержне u_t = α·u_xx, в
+// свободных узлах — общая температура и сумма потоков ноль, в узлах с
+// temperature — заданная температура. Начало — ноль везде, кроме этих
+// узлов. θ-схема solver.StreamNetworkFrom, -method BTCS, CN (по
+// умолчанию), FTCS или THETA. Слои каждого стержня пишутся в свой CSV
+// (-out с суффиксом _имя стержня), температуры узлов — в CSV с суффиксом
+// _nodes. В конце — температуры узлов против стационара по условию
+// Кирхгофа и тепло сети. Код выхода 1 при ошибке
+func runNetwork(c networkRun) int {
+	rejected := slices.DeleteFunc(slices.Clone(flags1D), func(name string) bool { return name == "theta" || name == "network" })
+	if !rejectFlags("-network", rejected, append([]string{"dx"}, flagsRadial...)...) {
+		return 1
+	}
+	net, err := loadNetwork(c.file)
+	if err != nil {
+		slog.Error("Invalid network", "error", err)
+		return 1
+	}
+	theta, ok := methodTheta("network", c.method, c.theta)
+	if !ok {
+		return 1
+	}
+	switch strings.ToLower(c.columns) {
+	case io.ColumnsAll:
+		slog.Info("No exact solution for the network; the CSV files have x,t,u_numeric only")
+	case io.ColumnsNumeric:
+	default:
+		slog.Error("Unknown CSV columns", "columns", c.columns, "available", []string{io.ColumnsAll, io.ColumnsNumeric})
+		return 1
+	}
+	nt := int(math.Round(c.tmax / c.dt))
+	slog.Info("Simulation parameters", "equation", "rod network", "network", c.file, "segments", len(net.Segments), "nodes", len(net.Nodes), "theta", theta, "dt", c.dt, "tmax", c.tmax, "outfile", c.out)
+
+	type output struct {
+		f *os.File
+		w interface{ Flush() error }
+	}
+	var outputs []output
+	defer func() {
+		for _, o := range outputs {
+			o.f.Close()
+		}
+	}()
+	create := func(name string) (*os.File, bool) {
+		f, err := os.Create(name)
+		if err != nil {
+			slog.Error("Failed to create output file", "file", name, "error", err)
+			return nil, false
+		}
+		return f, true
+	}
+	rods := make([]*io.CSVLevelWriter, len(net.Segments))
+	u0 := make([][]float64, len(net.Segments))
+	for s, seg := range net.Segments {
+		name := networkFile(c.out, seg.Name)
+		f, ok := create(name)
+		if !ok {
+			return 1
+		}
+		rods[s] = io.NewCSVLevelWriter(f, seg.Length/float64(seg.Nx), c.dt, nil)
+		outputs = append(outputs, output{f, rods[s]})
+		u0[s] = make([]float64, seg.Nx+1)
+		slog.Info("Segment", "name", seg.Name, "from", seg.From, "to", seg.To, "length", seg.Length, "nx", seg.Nx, "alpha", seg.Alpha, "file", name)
+	}
+	names := make([]string, len(net.Nodes))
+	for i, node := range net.Nodes {
+		names[i] = node.Name
+	}
+	nodesName := networkFile(c.out, "nodes")
+	f, ok := create(nodesName)
+	if !ok {
+		return 1
+	}
+	nodes := io.NewCSVNodesWriter(f, names, c.dt)
+	outputs = append(outputs, output{f, nodes})
+
+	start := time.Now()
+	last, err := solver.StreamNetworkFrom(net, u0, nt, c.dt, theta, func(n int, rows [][]float64) error {
+		for s, row := range rows {
+			if err := rods[s].WriteLevel(n, row); err != nil {
+				return err
+			}
+		}
+		return nodes.WriteLevel(n, solver.NetworkNodeTemperatures(net, rows))
+	})
+	for _, o := range outputs {
+		if err == nil {
+			err = o.w.Flush()
+		}
+		if err == nil {
+			err = o.f.Close()
+		}
+	}
+	if err != nil {
+		slog.Error("Computation failed", "error", err)
+		return 1
+	}
+	slog.Info("Computation and CSV output completed", "runtime_sec", time.Since(start).Seconds())
+
+	t := float64(nt) * c.dt
+	temps := solver.NetworkNodeTemperatures(net, last)
+	steady, steadyErr := net.Steady()
+	for i, node := range net.Nodes {
+		attrs := []any{"name", node.Name, "t", t, "u", temps[i]}
+		if steadyErr == nil {
+			attrs = append(attrs, "steady", steady[i])
+		}
+		slog.Info("Node temperature", attrs...)
+	}
+	if steadyErr != nil {
+		slog.Info("No steady state to compare with", "reason", steadyErr)
+	}
+	slog.Info("Network heat", "t", t, "heat", solver.NetworkHeat(net, last))
+	slog.Info("Results successfully saved", "segments", len(net.Segments), "nodes_file", nodesName)
+	return 0
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"flag"
+	"log/slog"
+	"math"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"heat-solver/internal/io"
+	"heat-solver/internal/mathutils"
+	"heat-solver/internal/solver"
+)
+
+// Параметры расчёта пористой среды (-equation pme)
+type porousRun struct {
+	method    string
+	m         float64
+	length    float64
+	picardTol float64
+	dx, dt    float64
+	tmax      float64
+	out       string
+	columns   string
+}
+
+// Расчёт u_t = (u^m)_xx на [0, L] с нулём на краях из профиля Баренблатта с
+// носителем полуширины L/10 вокруг L/2 (его момент T0 = 1): BTCS с
+// итерациями Пикара. Слои пишутся в CSV по мере счёта, столбец u_exact —
+// то же решение Баренблатта. В конце — нормы ошибки, полуширина носителя
+// против Баренблатта, масса и наименьшее значение. Код выхода 1 при ошибке
+func runPorous(c porousRun) int {
+	rejected := slices.DeleteFunc(slices.Clone(flags1D), func(name string) bool { return name == "picard-tol" })
+	if !rejectFlags("-equation pme", rejected, append([]string{"dim", "geometry", "rho", "tau", "order", "memory"}, flagsRadial...)...) {
+		return 1
+	}
+	methodSet := false
+	flag.Visit(func(f *flag.Flag) { methodSet = methodSet || f.Name == "method" })
+	if methodSet && !strings.EqualFold(c.method, "BTCS") {
+		slog.Error("The porous medium equation takes -method BTCS only", "method", c.method)
+		return 1
+	}
+	if !(c.m > 1) || math.IsInf(c.m, 0) {
+		slog.Error("Exponent m must be finite and above 1", "m", c.m)
+		return 1
+	}
+	if !(c.length > 0) || math.IsInf(c.length, 0) {
+		slog.Error("Domain length must be positive and finite", "length", c.length)
+		return 1
+	}
+	if !(c.picardTol > 0) {
+		slog.Error("Picard tolerance must be positive", "picard_tol", c.picardTol)
+		return 1
+	}
+	b := mathutils.Barenblatt{M: c.m, Center: c.length / 2, T0: 1, R0: c.length / 10}
+	var exact mathutils.Reference
+	switch strings.ToLower(c.columns) {
+	case io.ColumnsAll:
+		exact = b
+	case io.ColumnsNumeric:
+	default:
+		slog.Error("Unknown CSV columns", "columns", c.columns, "available", []string{io.ColumnsAll, io.ColumnsNumeric})
+		return 1
+	}
+	nx := int(math.Round(c.length / c.dx))
+	if nx < 2 {
+		slog.Error("Grid needs at least 2 intervals", "nx", nx)
+		return 1
+	}
+	dx := c.length / float64(nx)
+	nt := int(math.Round(c.tmax / c.dt))
+
+	slog.Info("Simulation parameters", "equation", "porous medium", "m", c.m, "length", c.length, "picard_tol", c.picardTol, "dt", c.dt, "tmax", c.tmax, "outfile", c.out)
+	slog.Info("Grid configuration", "nx", nx, "nt", nt, "dx", dx)
+
+	f, err := os.Create(c.out)
+	if err != nil {
+		slog.Error("Failed to create output file", "file", c.out, "error", err)
+		return 1
+	}
+	defer f.Close()
+	w := io.NewCSVLevelWriter(f, dx, c.dt, exact)
+
+	least := math.Inf(1)
+	emit := func(n int, row []float64) error {
+		for _, v := range row {
+			least = math.Min(least, v)
+		}
+		return w.WriteLevel(n, row)
+	}
+	start := time.Now()
+	last, err := solver.StreamPorousFrom(solver.BarenblattProfile(nx, dx, b), nt, dx, c.dt, c.m, c.picardTol, emit)
+	if err == nil {
+		err = w.Flush()
+	}
+	if err == nil {
+		err = f.Close()
+	}
+	if err != nil {
+		slog.Error("Computation failed", "error", err)
+		return 1
+	}
+	slog.Info("Computation and CSV output completed", "runtime_sec", time.Since(start).Seconds())
+
+	t := float64(nt) * c.dt
+	l2, linf := mathutils.LevelErrors(last, dx, t, b)
+	slog.Info("Error norms", "t", t, "l2", l2, "linf", linf)
+	radius, exactRadius := solver.PorousFront(last, dx, c.m)-b.Center, b.Radius(t)
+	slog.Info("Support half-width", "numeric", radius, "barenblatt", exactRadius, "relative_difference", math.Abs(radius-exactRadius)/exactRadius)
+	var mass float64
+	for _, v := range last {
+		mass += v * dx
+	}
+	slog.Info("Mass and minimum", "mass", mass, "barenblatt_mass", b.Heat(t), "min_u", least)
+	if exactRadius > 0.45*c.length {
+		slog.Warn("Barenblatt support reaches the ends; lengthen the domain", "radius", exactRadius, "length", c.length)
+	}
+	slog.Info("Results successfully saved", "file", c.out)
+	return 0
+}
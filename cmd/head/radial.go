@@ -0,0 +1,157 @@
+package main
+
+import (
+	"flag"
+	"log/slog"
+	"math"
+	"os"
+	"strings"
+	"time"
+
+	"heat-solver/internal/io"
+	"heat-solver/internal/mathutils"
+	"heat-solver/internal/solver"
+)
+
+// Геометрии одномерного расчёта (-geometry)
+const (
+	geometryCartesian   = "cartesian"
+	geometryCylindrical = "cylindrical"
+	geometrySpherical   = "spherical"
+)
+
+// Радиальная мода с известным затуханием: эталон для CSV и норм ошибки
+type radialMode interface {
+	mathutils.Reference
+	Rate() float64
+}
+
+// Радиальные геометрии: схема, начальный профиль (первая мода) и точное
+// решение из неё
+var radialGeometries = map[string]struct {
+	stream  func(u0 []float64, nt int, dr, dt, alpha, theta float64, emit solver.EmitFunc) ([]float64, error)
+	profile func(nr int, dr float64) []float64
+	exact   radialMode
+}{
+	geometryCylindrical: {solver.StreamCylindricalFrom, solver.BesselProfile, mathutils.BesselMode{Alpha: 1}},
+	geometrySpherical:   {solver.StreamSphericalFrom, solver.SphereProfile, mathutils.SphereMode{Alpha: 1}},
+}
+
+// θ расчёта, в котором доступна только θ-схема (радиальные геометрии,
+// неравномерная сетка): -method FTCS, BTCS, CN (по умолчанию) или THETA с
+// -theta; явный -theta без -method — тоже THETA. При ошибке — запись в лог
+// и false
+func methodTheta(mode, method string, theta float64) (float64, bool) {
+	methodSet, thetaSet := false, false
+	flag.Visit(func(f *flag.Flag) {
+		methodSet = methodSet || f.Name == "method"
+		thetaSet = thetaSet || f.Name == "theta"
+	})
+	switch {
+	case !methodSet && thetaSet, strings.EqualFold(method, "theta"):
+	case !methodSet, strings.EqualFold(method, "CN"), strings.EqualFold(method, "crank-nicolson"):
+		theta = 0.5
+	case strings.EqualFold(method, "BTCS"):
+		theta = 1
+	case strings.EqualFold(method, "FTCS"):
+		theta = 0
+	default:
+		slog.Error("Unknown "+mode+" method", "method", method, "available", []string{"FTCS", "BTCS", "CN", "THETA"})
+		return 0, false
+	}
+	if !(theta >= 0 && theta <= 1) {
+		slog.Error("Invalid -theta", "theta", theta)
+		return 0, false
+	}
+	return theta, true
+}
+
+// Параметры радиального расчёта (-geometry cylindrical или spherical)
+type radialRun struct {
+	geometry string
+	method   string
+	theta    float64
+	dr, dt   float64
+	tmax     float64
+	out      string
+	columns  string
+}
+
+// Флаги, которые в радиальном расчёте ничего бы не делали: одномерные,
+// кроме -theta, и флаги сеток 2D и 3D
+var flagsRadial = []string{"nx", "ny", "nz", "snapshot-every", "grid", "stretch-factor"}
+
+// Радиальный расчёт в цилиндре или шаре радиуса 1 с нулём на r = 1 из
+// первой моды (J₀(λ₁r) или sin(πr)/r): слои пишутся в CSV по мере счёта
+// (столбец x — это r), в конце — нормы ошибки и наблюдаемая скорость
+// затухания в центре против точной. Схема — θ-взвешенная: -method FTCS,
+// BTCS, CN (по умолчанию) или THETA с -theta. Код выхода 1 при ошибке
+func runRadial(c radialRun) int {
+	geo := radialGeometries[c.geometry]
+	var rejected []string
+	for _, name := range flags1D {
+		if name != "theta" {
+			rejected = append(rejected, name)
+		}
+	}
+	if !rejectFlags("-geometry "+c.geometry, rejected, flagsRadial...) {
+		return 1
+	}
+	theta, ok := methodTheta(c.geometry, c.method, c.theta)
+	if !ok {
+		return 1
+	}
+	var exact mathutils.Reference
+	switch strings.ToLower(c.columns) {
+	case io.ColumnsAll:
+		exact = geo.exact
+	case io.ColumnsNumeric:
+	default:
+		slog.Error("Unknown CSV columns", "columns", c.columns, "available", []string{io.ColumnsAll, io.ColumnsNumeric})
+		return 1
+	}
+	nr := int(math.Round(1 / c.dr))
+	if nr < 2 {
+		slog.Error("Radial grid needs at least 2 intervals", "nr", nr)
+		return 1
+	}
+	dr := 1 / float64(nr)
+	nt := int(math.Round(c.tmax / c.dt))
+
+	slog.Info("Simulation parameters", "geometry", c.geometry, "theta", theta, "dt", c.dt, "tmax", c.tmax, "outfile", c.out)
+	slog.Info("Grid configuration", "nr", nr, "nt", nt, "dr", dr)
+
+	f, err := os.Create(c.out)
+	if err != nil {
+		slog.Error("Failed to create output file", "file", c.out, "error", err)
+		return 1
+	}
+	defer f.Close()
+	w := io.NewCSVLevelWriter(f, dr, c.dt, exact)
+
+	start := time.Now()
+	u0 := geo.profile(nr, dr)
+	last, err := geo.stream(u0, nt, dr, c.dt, 1, theta, w.WriteLevel)
+	if err == nil {
+		err = w.Flush()
+	}
+	if err == nil {
+		err = f.Close()
+	}
+	if err != nil {
+		slog.Error("Computation failed", "error", err)
+		return 1
+	}
+	slog.Info("Computation and CSV output completed", "runtime_sec", time.Since(start).Seconds())
+
+	t := float64(nt) * c.dt
+	l2, linf := mathutils.LevelErrors(last, dr, t, geo.exact)
+	slog.Info("Error norms", "t", t, "l2", l2, "linf", linf, "center_error", math.Abs(last[0]-geo.exact.Eval(0, t)))
+	if t > 0 && last[0] > 0 {
+		rate := math.Log(u0[0]/last[0]) / t
+		exactRate := geo.exact.Rate()
+		slog.Info("Decay rate at the center", "observed", rate, "exact", exactRate, "relative_difference", math.Abs(rate-exactRate)/exactRate)
+	}
+	slog.Info("Results successfully saved", "file", c.out)
+	return 0
+}
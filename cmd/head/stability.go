@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"log/slog"
+	"math"
+	"os"
+	"strconv"
+
+	"heat-solver/internal/solver"
+)
+
+// Подкоманда stability: G(θ) фон Неймана без расчёта. r задаётся прямо
+// или через α·dt/dx²; с -dx точки — моды сетки θ_k = kπ·dx
+func runStability(args []string) int {
+	fs := flag.NewFlagSet("stability", flag.ContinueOnError)
+	method := fs.String("method", "FTCS", "Numerical method: FTCS, BTCS, CN, BDF2, RK4, RKC, EXPONENTIAL, SPECTRAL, DUFORT-FRANKEL (DF), or SAULYEV")
+	r := fs.Float64("r", 0, "Mesh ratio α·dt/dx² (0: from -alpha, -dx and -dt)")
+	alpha := fs.Float64("alpha", 1, "Thermal diffusivity α")
+	dx := fs.Float64("dx", 0, "Spatial step size; also sets the wavenumbers to the grid modes kπ·dx")
+	dt := fs.Float64("dt", 0, "Time step size")
+	points := fs.Int("points", 64, "Wavenumbers in (0, π] when -dx is not set")
+	out := fs.String("out", "stability.csv", "Output CSV file with theta,G,abs_G")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	m, ok := solver.Lookup(*method)
+	if !ok {
+		slog.Error("Unknown method", "method", *method, "available", solver.Methods())
+		return 1
+	}
+	n := *points
+	if *dx > 0 {
+		n = int(math.Round(1 / *dx))
+	}
+	if *r <= 0 {
+		if !(*dx > 0 && *dt > 0 && *alpha > 0) {
+			slog.Error("Set -r or positive -alpha, -dx and -dt")
+			return 1
+		}
+		*r = *alpha * *dt / (*dx * *dx)
+	}
+
+	a, err := solver.AnalyzeStability(m, *r, n)
+	if err != nil {
+		slog.Error("Stability analysis failed", "error", err)
+		return 1
+	}
+	if err := writeStabilityCSV(*out, a); err != nil {
+		slog.Error("Error writing stability table", "file", *out, "error", err)
+		return 1
+	}
+
+	slog.Info("Von Neumann analysis",
+		"method", a.Method,
+		"r", a.R,
+		"wavenumbers", len(a.Points),
+		"max_abs_g", a.MaxAbsG,
+		"most_amplified_theta", a.MaxTheta,
+		"stable", a.Stable,
+	)
+	if a.NegativeFrom > 0 {
+		slog.Info("Oscillatory modes: G < 0", "theta_from", a.NegativeFrom, "theta_to", math.Pi)
+	}
+	if !a.Stable {
+		slog.Warn("Amplification factor exceeds 1, the scheme is unstable at this r", "r", a.R, "stability_bound", m.StabilityBound)
+	}
+	slog.Info("Table saved", "file", *out)
+	return 0
+}
+
+func writeStabilityCSV(filename string, a solver.StabilityAnalysis) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	fmt.Fprintln(w, "theta,G,abs_G")
+	var buf []byte
+	for _, p := range a.Points {
+		buf = strconv.AppendFloat(buf[:0], p.Theta, 'g', -1, 64)
+		buf = append(buf, ',')
+		buf = strconv.AppendFloat(buf, p.G, 'g', -1, 64)
+		buf = append(buf, ',')
+		buf = strconv.AppendFloat(buf, math.Abs(p.G), 'g', -1, 64)
+		buf = append(buf, '\n')
+		w.Write(buf)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	return f.Close()
+}
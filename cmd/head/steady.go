@@ -0,0 +1,130 @@
+package main
+
+import (
+	"log/slog"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"heat-solver/internal/io"
+	"heat-solver/internal/mathutils"
+	"heat-solver/internal/solver"
+)
+
+// Метод стационарного решения (-method STEADY)
+const methodSteady = "STEADY"
+
+// Параметры стационарного расчёта
+type steady struct {
+	dx          float64
+	source      string
+	left, right float64
+	out         string
+	columns     string
+}
+
+// Флаги краевых условий: у стационарного расчёта, у составной стенки
+// (-layers) и у расчёта по времени на отрезке с равномерной сеткой
+// (runBoundary); -source — только на отрезке с равномерной сеткой
+var flagsSteady = []string{"bc-left", "bc-right"}
+
+// Флаги, которые стационарному расчёту ничего бы не дали: времени нет,
+// сетка одномерная и равномерная
+var flagsNoTime = []string{"dt", "tmax", "dim", "geometry", "grid", "stretch-factor", "nx", "ny", "nz", "snapshot-every", "threads"}
+
+// Решение −u″ = f с u(0) = left, u(1) = right одной прогонкой
+// (solver.SolveSteady) и запись профиля io.SaveProfileCSV. f — число,
+// предустановка или выражение от x, как у -source расчёта по времени, но
+// без t. Для постоянной f точное решение — парабола, для sine — прямая
+// плюс sin(πx)/π²; по ним считаются столбцы ошибки и нормы, у остальных
+// источников эталона нет. Код выхода 1 при ошибке
+func runSteady(s steady) int {
+	rejected := slices.DeleteFunc(slices.Clone(flags1D), func(name string) bool { return name == "source" })
+	if !rejectFlags("-method STEADY", rejected, flagsNoTime...) {
+		return 1
+	}
+	f, exact, ok := steadySource(s)
+	if !ok {
+		return 1
+	}
+	columns := exact
+	switch strings.ToLower(s.columns) {
+	case io.ColumnsAll:
+	case io.ColumnsNumeric:
+		columns = nil
+	default:
+		slog.Error("Unknown CSV columns", "columns", s.columns, "available", []string{io.ColumnsAll, io.ColumnsNumeric})
+		return 1
+	}
+	for _, v := range []float64{s.left, s.right} {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			slog.Error("Boundary values must be finite", "bc_left", s.left, "bc_right", s.right)
+			return 1
+		}
+	}
+	nx := int(math.Round(1 / s.dx))
+	if nx < 2 {
+		slog.Error("Grid needs at least 2 intervals", "nx", nx)
+		return 1
+	}
+	dx := 1 / float64(nx)
+	for i := 0; i <= nx; i++ {
+		if v := f(float64(i) * dx); math.IsNaN(v) || math.IsInf(v, 0) {
+			slog.Error("Source must be finite at every node", "source", s.source, "x", float64(i)*dx, "f", v)
+			return 1
+		}
+	}
+
+	slog.Info("Simulation parameters", "method", methodSteady, "dx", dx, "source", s.source, "bc_left", s.left, "bc_right", s.right, "outfile", s.out)
+	start := time.Now()
+	u := solver.SolveSteady(nx, dx, 1, f, s.left, s.right)
+	slog.Info("Computation completed", "runtime_sec", time.Since(start).Seconds())
+
+	if exact != nil {
+		var linf float64
+		for i, v := range u {
+			linf = max(linf, math.Abs(v-exact(float64(i)*dx)))
+		}
+		slog.Info("Error against the exact solution", "linf", linf)
+	}
+	if err := io.SaveProfileCSV(u, dx, s.out, columns); err != nil {
+		slog.Error("Failed to save results", "error", err)
+		return 1
+	}
+	slog.Info("Results successfully saved", "file", s.out)
+	return 0
+}
+
+// Источник стационарного расчёта из -source и точное решение, если оно
+// известно (иначе nil). Пустая строка — f = 0. Источник, зависящий от t, —
+// ошибка: стационара у него нет
+func steadySource(s steady) (func(x float64) float64, func(x float64) float64, bool) {
+	text := strings.TrimSpace(s.source)
+	if text == "" {
+		text = "0"
+	}
+	if c, err := strconv.ParseFloat(text, 64); err == nil {
+		if math.IsNaN(c) || math.IsInf(c, 0) {
+			slog.Error("Source must be finite", "source", s.source)
+			return nil, nil, false
+		}
+		return func(float64) float64 { return c }, mathutils.SteadyParabola(1, c, s.left, s.right), true
+	}
+	src, err := mathutils.ParseSource(text)
+	if err != nil {
+		slog.Error("Invalid -source", "error", err)
+		return nil, nil, false
+	}
+	if src.TimeDependent() {
+		slog.Error("The steady source must not depend on t", "source", s.source)
+		return nil, nil, false
+	}
+	f := func(x float64) float64 { return src.Eval(x, 0) }
+	if src.Expr != mathutils.SourceSine {
+		return f, nil, true
+	}
+	linear := mathutils.SteadyParabola(1, 0, s.left, s.right)
+	return f, func(x float64) float64 { return linear(x) + math.Sin(math.Pi*x)/(math.Pi*math.Pi) }, true
+}
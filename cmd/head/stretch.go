@@ -0,0 +1,92 @@
+package main
+
+import (
+	"log/slog"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"heat-solver/internal/io"
+	"heat-solver/internal/mathutils"
+	"heat-solver/internal/solver"
+)
+
+// Сетки одномерного расчёта (-grid)
+const (
+	gridUniform = "uniform"
+	gridStretch = "stretch"
+)
+
+// Параметры расчёта на растянутой сетке (-grid stretch)
+type stretched struct {
+	method  string
+	theta   float64
+	factor  float64
+	dx, dt  float64
+	tmax    float64
+	out     string
+	columns string
+}
+
+// Флаги, которые на растянутой сетке ничего бы не делали: одномерные,
+// кроме -theta и настроек прогонки, и флаги сеток 2D и 3D
+var flagsStretch = []string{"nx", "ny", "nz", "snapshot-every"}
+
+// Расчёт из sin(πx) на узлах mathutils.StretchedNodes(1/dx, factor),
+// сгущённых к краям, θ-схемой с шагами по интервалам. Все слои хранятся и
+// пишутся io.SaveToCSVNodes: в столбце x — сами узлы. В конце — нормы
+// ошибки и наименьший и наибольший шаг. Код выхода 1 при ошибке
+func runStretched(s stretched) int {
+	rejected := slices.DeleteFunc(slices.Clone(flags1D), func(name string) bool {
+		return name == "theta" || name == "tridiagonal" || name == "verify-residual"
+	})
+	if !rejectFlags("-grid stretch", rejected, flagsStretch...) {
+		return 1
+	}
+	theta, ok := methodTheta("stretched-grid", s.method, s.theta)
+	if !ok {
+		return 1
+	}
+	var exact mathutils.Reference
+	switch strings.ToLower(s.columns) {
+	case io.ColumnsAll:
+		exact = mathutils.Analytical{Alpha: 1}
+	case io.ColumnsNumeric:
+	default:
+		slog.Error("Unknown CSV columns", "columns", s.columns, "available", []string{io.ColumnsAll, io.ColumnsNumeric})
+		return 1
+	}
+	if !(s.factor >= 0) {
+		slog.Error("Stretch factor must not be negative", "stretch_factor", s.factor)
+		return 1
+	}
+	nx := int(math.Round(1 / s.dx))
+	if nx < 2 {
+		slog.Error("Grid needs at least 2 intervals", "nx", nx)
+		return 1
+	}
+	x := mathutils.StretchedNodes(nx, s.factor)
+	nt := int(math.Round(s.tmax / s.dt))
+	hmin, hmax := math.Inf(1), 0.0
+	for i := 1; i <= nx; i++ {
+		hmin, hmax = min(hmin, x[i]-x[i-1]), max(hmax, x[i]-x[i-1])
+	}
+
+	slog.Info("Simulation parameters", "grid", gridStretch, "stretch_factor", s.factor, "theta", theta, "dt", s.dt, "tmax", s.tmax, "outfile", s.out)
+	slog.Info("Grid configuration", "nx", nx, "nt", nt, "h_min", hmin, "h_max", hmax)
+
+	start := time.Now()
+	u := solver.SolveThetaNodes(x, nt, s.dt, 1, theta)
+	slog.Info("Computation completed", "runtime_sec", time.Since(start).Seconds())
+
+	t := float64(nt) * s.dt
+	l2, linf := mathutils.NodeErrors(u[nt], x, t, mathutils.Analytical{Alpha: 1})
+	slog.Info("Error norms", "t", t, "l2", l2, "linf", linf)
+	if err := io.SaveToCSVNodes(u, x, s.dt, s.out, exact); err != nil {
+		slog.Error("Failed to save results", "error", err)
+		return 1
+	}
+	slog.Info("Results successfully saved", "file", s.out)
+	return 0
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log/slog"
+
+	"heat-solver/internal/config"
+	"heat-solver/internal/mathutils"
+	"heat-solver/internal/solver"
+)
+
+// Подкоманда suggest: dx и dt для заданной ошибки L2 в момент tmax.
+// Калибровка на грубой сетке, с -verify — контрольный расчёт
+func runSuggest(args []string) int {
+	fs := flag.NewFlagSet("suggest", flag.ContinueOnError)
+	method := fs.String("method", "CN", "Numerical method: FTCS, BTCS, CN, BDF2, RK4, RKC, DUFORT-FRANKEL (DF), SAULYEV, or HOPSCOTCH")
+	target := fs.Float64("target", 1e-5, "Target L2 error at tmax")
+	tmax := fs.Float64("tmax", 0.1, "Maximum simulation time")
+	balance := fs.Bool("balance", false, "Split the target equally between dx and dt instead of minimising nx·nt")
+	calDx := fs.Float64("calibration-dx", 0.05, "Spatial step of the calibration solves")
+	calDt := fs.Float64("calibration-dt", 0, "Time step of the calibration solves (0: tmax/20, or within the stability bound at dx/2)")
+	verify := fs.Bool("verify", false, "Solve at the suggested dx and dt and report the actual error")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	m, ok := solver.Lookup(*method)
+	if !ok {
+		slog.Error("Unknown method", "method", *method, "available", solver.Methods())
+		return 1
+	}
+	p := config.Params{Method: m.Name, Dx: *calDx, Dt: *calDt, Tmax: *tmax}
+	if p.Dt <= 0 {
+		p.Dt = p.Tmax / 20
+		if m.MaxR > 0 {
+			p.Dt = min(p.Dt, 0.8*m.MaxR*p.Dx*p.Dx/4)
+		}
+	}
+	if err := p.Validate(); err != nil {
+		slog.Error("Invalid parameters", "error", err)
+		return 1
+	}
+
+	s, err := solver.Suggest(context.Background(), p, *target, *balance)
+	if err != nil {
+		slog.Error("Suggestion failed", "error", err)
+		return 1
+	}
+	slog.Info("Calibration",
+		"dx", s.Calibration.Dx,
+		"dt", s.Calibration.Dt,
+		"spatial", s.Calibration.Spatial,
+		"temporal", s.Calibration.Temporal,
+		"c_x", s.Cx,
+		"c_t", s.Ct,
+		"opposite_signs", s.Calibration.Opposite,
+	)
+	if s.Calibration.Mixed > 0.1 {
+		slog.Warn("Calibration errors do not separate into dx and dt parts, try a finer calibration grid", "mixed", s.Calibration.Mixed)
+	}
+	slog.Info("Suggested grid",
+		"method", s.Method,
+		"target", s.Target,
+		"dx", s.Dx,
+		"dt", s.Dt,
+		"nx", s.Nx,
+		"nt", s.Nt,
+		"predicted_l2", s.Predicted,
+		"bound_l2", s.Bound,
+		"full_storage_mb", float64(s.FullBytes)/(1<<20),
+		"final_only_kb", float64(s.FinalOnlyBytes)/(1<<10),
+		"runtime_estimate", s.Runtime,
+	)
+	if !*verify {
+		return 0
+	}
+
+	check := config.Params{Method: s.Method, Dx: s.Dx, Dt: s.Dt, Tmax: *tmax, Storage: config.StorageFinalOnly}
+	res, err := solver.Run(context.Background(), check, solver.Hooks{})
+	if err != nil {
+		slog.Error("Verification run failed", "error", err)
+		return 1
+	}
+	defer res.Release()
+	l2, linf := mathutils.LevelErrors(res.Final, s.Dx, float64(s.Nt)*s.Dt, mathutils.Analytical{Alpha: 1})
+	slog.Info("Verification", "l2", l2, "linf", linf, "target", s.Target, "ratio", l2/s.Target, "runtime", res.Runtime)
+	if l2 > 2*s.Target {
+		slog.Warn("Verification error is more than twice the target", "l2", l2, "target", s.Target)
+	}
+	return 0
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"log/slog"
+	"math"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"heat-solver/internal/config"
+	"heat-solver/internal/io"
+	"heat-solver/internal/mathutils"
+	"heat-solver/internal/solver"
+)
+
+// Параметры расчёта двухтемпературной модели (-alpha2, -hcoef, -ic2)
+type twoTemperatureRun struct {
+	method  string
+	theta   float64
+	alpha2  float64
+	h       float64
+	ic, ic2 string
+	dx, dt  float64
+	tmax    float64
+	out     string
+	columns string
+}
+
+// Флаги двухтемпературной модели: любой из них включает её расчёт
+var flagsTwoTemperature = []string{"alpha2", "hcoef", "ic2"}
+
+// Начальное условие -ic2 без нагрева второго поля
+const icZero = "zero"
+
+// Начальный профиль поля по -ic или -ic2 и его амплитуда моды sin(πx) для
+// точного решения; ok = false у ступеньки, у которой точного решения нет
+func twoTemperatureProfile(ic string, nx int, dx float64) (u0 []float64, amp float64, ok bool) {
+	switch strings.ToLower(ic) {
+	case config.ICSine:
+		return solver.SineProfile(nx, dx), 1, true
+	case config.ICTopHat:
+		return solver.TopHatProfile(nx), 0, false
+	}
+	return make([]float64, nx+1), 0, true
+}
+
+// Расчёт u_t = u_xx − h·(u − v), v_t = α₂·v_xx + h·(u − v) на [0, 1] с
+// нулём на краях: θ-схема solver.StreamTwoTemperatureFrom, -method BTCS,
+// CN (по умолчанию), FTCS или THETA. u начинается с -ic, v — с -ic2 (по
+// умолчанию ноль: нагреты только «электроны»). Если оба начальных условия
+// — sin(πx) или ноль, точное решение — mathutils.TwoTemperatureMode. Слои
+// пишутся в CSV по мере счёта со столбцами обоих полей. В конце — нормы
+// ошибки, тепло и энергия. Код выхода 1 при ошибке
+func runTwoTemperature(c twoTemperatureRun) int {
+	rejected := slices.DeleteFunc(slices.Clone(flags1D), func(name string) bool {
+		return name == "theta" || name == "ic" || slices.Contains(flagsTwoTemperature, name)
+	})
+	if !rejectFlags("-alpha2, -hcoef and -ic2", rejected, flagsRadial...) {
+		return 1
+	}
+	theta, ok := methodTheta("two-temperature", c.method, c.theta)
+	if !ok {
+		return 1
+	}
+	if !(c.alpha2 > 0) || math.IsInf(c.alpha2, 0) {
+		slog.Error("Second diffusivity must be positive and finite", "alpha2", c.alpha2)
+		return 1
+	}
+	if !(c.h >= 0) || math.IsInf(c.h, 0) {
+		slog.Error("Exchange coefficient must be non-negative and finite", "hcoef", c.h)
+		return 1
+	}
+	if ic := strings.ToLower(c.ic); ic != config.ICSine && ic != config.ICTopHat {
+		slog.Error("Unknown initial condition", "ic", c.ic, "available", []string{config.ICSine, config.ICTopHat})
+		return 1
+	}
+	if ic2 := strings.ToLower(c.ic2); ic2 != config.ICSine && ic2 != config.ICTopHat && ic2 != icZero {
+		slog.Error("Unknown initial condition of v", "ic2", c.ic2, "available", []string{icZero, config.ICSine, config.ICTopHat})
+		return 1
+	}
+	nx := int(math.Round(1 / c.dx))
+	if nx < 2 {
+		slog.Error("Grid needs at least 2 intervals", "nx", nx)
+		return 1
+	}
+	dx := 1 / float64(nx)
+	nt := int(math.Round(c.tmax / c.dt))
+	u0, a0, okU := twoTemperatureProfile(c.ic, nx, dx)
+	v0, b0, okV := twoTemperatureProfile(c.ic2, nx, dx)
+	mode := mathutils.TwoTemperatureMode{Alpha1: 1, Alpha2: c.alpha2, H: c.h, U0: a0, V0: b0}
+	var exactU, exactV mathutils.Reference
+	switch strings.ToLower(c.columns) {
+	case io.ColumnsAll:
+		if okU && okV {
+			exactU, exactV = mode.U(), mode.V()
+		} else {
+			slog.Info("No exact solution for -ic tophat; the CSV has x,t,u_numeric,v_numeric only")
+		}
+	case io.ColumnsNumeric:
+	default:
+		slog.Error("Unknown CSV columns", "columns", c.columns, "available", []string{io.ColumnsAll, io.ColumnsNumeric})
+		return 1
+	}
+
+	slog.Info("Simulation parameters", "equation", "two-temperature", "alpha1", 1, "alpha2", c.alpha2, "hcoef", c.h, "ic", strings.ToLower(c.ic), "ic2", strings.ToLower(c.ic2), "theta", theta, "dt", c.dt, "tmax", c.tmax, "outfile", c.out)
+	slog.Info("Grid configuration", "nx", nx, "nt", nt, "dx", dx)
+
+	f, err := os.Create(c.out)
+	if err != nil {
+		slog.Error("Failed to create output file", "file", c.out, "error", err)
+		return 1
+	}
+	defer f.Close()
+	w := io.NewCSVTwoFieldLevelWriter(f, dx, c.dt, exactU, exactV)
+
+	start := time.Now()
+	m := solver.TwoTemperature{Alpha1: 1, Alpha2: c.alpha2, H: c.h}
+	lu, lv, err := solver.StreamTwoTemperatureFrom(u0, v0, nt, dx, c.dt, theta, m, w.WriteLevel)
+	if err == nil {
+		err = w.Flush()
+	}
+	if err == nil {
+		err = f.Close()
+	}
+	if err != nil {
+		slog.Error("Computation failed", "error", err)
+		return 1
+	}
+	slog.Info("Computation and CSV output completed", "runtime_sec", time.Since(start).Seconds())
+
+	t := float64(nt) * c.dt
+	if okU && okV {
+		l2u, linfU := mathutils.LevelErrors(lu, dx, t, mode.U())
+		l2v, linfV := mathutils.LevelErrors(lv, dx, t, mode.V())
+		a, b := mode.Amplitudes(t)
+		slog.Info("Error norms", "t", t, "l2_u", l2u, "linf_u", linfU, "l2_v", l2v, "linf_v", linfV)
+		slog.Info("Mode amplitudes", "u", a, "v", b)
+	}
+	heat, energy := solver.TwoTemperatureEnergy(lu, lv, dx)
+	heat0, energy0 := solver.TwoTemperatureEnergy(u0, v0, dx)
+	slog.Info("Heat and energy", "t", t, "heat", heat, "heat_start", heat0, "energy", energy, "energy_start", energy0)
+	slog.Info("Results successfully saved", "file", c.out)
+	return 0
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"heat-solver/internal/solver"
+)
+
+// Подкоманда verify: проверочные случаи всех методов (solver.Verify) и
+// таблица «измерено — ожидалось». Код выхода 1, если хоть один не прошёл
+func runVerify(args []string) int {
+	fs := flag.NewFlagSet("verify", flag.ContinueOnError)
+	verbose := fs.Bool("v", false, "Keep the log of every solve of the suite")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	// Каждый расчёт набора пишет в лог; без -v остаются только ошибки, а
+	// результат — таблица
+	logger := slog.Default()
+	if !*verbose {
+		slog.SetDefault(slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError})))
+	}
+	rep, err := solver.Verify(context.Background())
+	slog.SetDefault(logger)
+	if err != nil {
+		slog.Error("Verification interrupted", "error", err)
+		return 1
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "METHOD\tCASE\tQUANTITY\tMEASURED\tEXPECTED\tRESULT\tDETAIL")
+	for _, r := range rep.Results {
+		result, detail := "PASS", r.Detail
+		if !r.Pass {
+			result = "FAIL"
+		}
+		if r.Err != nil {
+			detail = r.Err.Error()
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", r.Method, r.Case, r.Quantity,
+			strconv.FormatFloat(r.Measured, 'g', 4, 64), expectation(r), result, detail)
+	}
+	w.Flush()
+
+	for _, name := range rep.Unverified {
+		slog.Warn("Method has no verification cases", "method", name)
+	}
+	if !rep.Passed() {
+		slog.Error("Verification failed", "failed", rep.Failed, "cases", len(rep.Results), "runtime", rep.Runtime)
+		return 1
+	}
+	slog.Info("Verification passed", "cases", len(rep.Results), "runtime", rep.Runtime)
+	return 0
+}
+
+func expectation(r solver.VerifyResult) string {
+	expected := strconv.FormatFloat(r.Expected, 'g', -1, 64)
+	if r.Compare == solver.VerifyNear {
+		return expected + " ± " + strconv.FormatFloat(r.Tolerance, 'g', -1, 64)
+	}
+	return r.Compare + " " + expected
+}
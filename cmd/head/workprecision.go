@@ -0,0 +1,159 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"heat-solver/internal/config"
+	"heat-solver/internal/render"
+	"heat-solver/internal/solver"
+)
+
+// Подкоманда work-precision: ошибка L2 против времени расчёта по лестнице
+// сеток для нескольких методов, CSV и по желанию SVG в логарифмических осях
+func runWorkPrecision(args []string) int {
+	fs := flag.NewFlagSet("work-precision", flag.ContinueOnError)
+	methods := fs.String("methods", "FTCS,BTCS,CN", "Comma-separated methods to compare")
+	dx := fs.Float64("dx", 0.05, "Spatial step of the first level")
+	dt := fs.Float64("dt", 0.001, "Time step of the first level; halved on every level")
+	tmax := fs.Float64("tmax", 0.1, "Maximum simulation time")
+	levels := fs.Int("levels", 7, "Levels per method")
+	budget := fs.Duration("budget", time.Minute, "Total time budget; levels that would not fit are skipped (0: no limit)")
+	out := fs.String("out", "work_precision.csv", "Output CSV file with method,level,dx,dt,nx,steps,error,runtime_sec,status")
+	svg := fs.String("svg", "", "Also plot error against runtime in log-log axes to this SVG file")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	var names []string
+	for _, name := range strings.Split(*methods, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	p := config.Params{Method: "CN", Dx: *dx, Dt: *dt, Tmax: *tmax}
+	if err := p.Validate(); err != nil {
+		slog.Error("Invalid parameters", "error", err)
+		return 1
+	}
+	points, err := solver.WorkPrecision(context.Background(), names, p, *levels, *budget)
+	if err != nil {
+		slog.Error("Work-precision sweep failed", "error", err)
+		return 1
+	}
+
+	for _, pt := range points {
+		switch pt.Status {
+		case solver.WorkOK:
+			slog.Info("Work-precision point", "method", pt.Method, "level", pt.Level, "nx", pt.Nx, "nt", pt.Nt,
+				"l2", pt.Error, "runtime", pt.Runtime)
+		case solver.WorkUnstable:
+			slog.Warn("Level skipped, the scheme is unstable", "method", pt.Method, "level", pt.Level,
+				"r", pt.Dt/(pt.Dx*pt.Dx))
+		case solver.WorkDiverged:
+			slog.Warn("Level diverged", "method", pt.Method, "level", pt.Level)
+		case solver.WorkBudget:
+			slog.Warn("Level skipped, it would exceed the time budget", "method", pt.Method, "level", pt.Level, "budget", *budget)
+		}
+	}
+	if err := writeWorkPrecisionCSV(*out, points); err != nil {
+		slog.Error("Error writing work-precision table", "file", *out, "error", err)
+		return 1
+	}
+	slog.Info("Table saved", "file", *out)
+	if *svg == "" {
+		return 0
+	}
+	if err := writeWorkPrecisionSVG(*svg, points, *tmax); err != nil {
+		slog.Error("Error writing work-precision plot", "file", *svg, "error", err)
+		return 1
+	}
+	slog.Info("Plot saved", "file", *svg)
+	return 0
+}
+
+// Пропущенные ступени — с пустыми error и runtime_sec (NaN при чтении в
+// pandas)
+func writeWorkPrecisionCSV(filename string, points []solver.WorkPoint) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	fmt.Fprintln(w, "method,level,dx,dt,nx,steps,error,runtime_sec,status")
+	var buf []byte
+	for _, pt := range points {
+		buf = append(buf[:0], pt.Method...)
+		buf = append(buf, ',')
+		buf = strconv.AppendInt(buf, int64(pt.Level), 10)
+		buf = append(buf, ',')
+		buf = strconv.AppendFloat(buf, pt.Dx, 'g', -1, 64)
+		buf = append(buf, ',')
+		buf = strconv.AppendFloat(buf, pt.Dt, 'g', -1, 64)
+		buf = append(buf, ',')
+		buf = strconv.AppendInt(buf, int64(pt.Nx), 10)
+		buf = append(buf, ',')
+		buf = strconv.AppendInt(buf, int64(pt.Nt), 10)
+		buf = append(buf, ',')
+		if pt.Status == solver.WorkOK {
+			buf = strconv.AppendFloat(buf, pt.Error, 'g', -1, 64)
+			buf = append(buf, ',')
+			buf = strconv.AppendFloat(buf, pt.Runtime.Seconds(), 'g', -1, 64)
+		} else {
+			buf = append(buf, ',')
+		}
+		buf = append(buf, ',')
+		buf = append(buf, pt.Status...)
+		buf = append(buf, '\n')
+		w.Write(buf)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// По серии на метод в порядке первого появления, с фронтом Парето
+func writeWorkPrecisionSVG(filename string, points []solver.WorkPoint, tmax float64) error {
+	var series []render.Series
+	index := map[string]int{}
+	for _, pt := range points {
+		k, ok := index[pt.Method]
+		if !ok {
+			k = len(series)
+			index[pt.Method] = k
+			series = append(series, render.Series{Name: pt.Method})
+		}
+		if pt.Status == solver.WorkOK {
+			series[k].X = append(series[k].X, pt.Runtime.Seconds())
+			series[k].Y = append(series[k].Y, pt.Error)
+		}
+	}
+
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	err = render.LogLogSVG(f, series, render.PlotOptions{
+		Width:  760,
+		Height: 500,
+		Title:  fmt.Sprintf("Work-precision at t = %g, sin(πx)", tmax),
+		XLabel: "runtime, s",
+		YLabel: "L2 error",
+		Front:  true,
+	})
+	if err != nil {
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"heat-solver/internal/config"
+	"heat-solver/internal/mathutils"
+)
+
+// Начальные и граничные условия, для которых известно точное решение
+const (
+	referenceIC = "sine"
+	referenceBC = "dirichlet"
+)
+
+type analyticalRequest struct {
+	Params config.Params
+	Alpha  float64
+	IC     string
+	BC     string
+	Stride int
+}
+
+// Сетка задаётся как в /simulate (dx, dt) или числом интервалов (nx, nt)
+func parseAnalytical(q url.Values) (analyticalRequest, error) {
+	req := analyticalRequest{
+		IC: strings.ToLower(strings.TrimSpace(q.Get("ic"))),
+		BC: strings.ToLower(strings.TrimSpace(q.Get("bc"))),
+	}
+	if req.IC == "" {
+		req.IC = referenceIC
+	}
+	if req.BC == "" {
+		req.BC = referenceBC
+	}
+
+	sim, err := requestFromQuery(q)
+	if err != nil {
+		return req, err
+	}
+	// ic и bc здесь выбирают эталон, а не начальное условие расчёта
+	sim.Method = ""
+	sim.IC = ""
+	sim.Reference = ""
+	req.Stride = sim.Stride
+
+	nx, err := intParam(q, "nx", 0)
+	if err != nil || nx < 0 {
+		return req, paramError("nx", "must be a positive integer")
+	}
+	nt, err := intParam(q, "nt", 0)
+	if err != nil || nt < 0 {
+		return req, paramError("nt", "must be a positive integer")
+	}
+	if nx > 0 && sim.Dx != 0 {
+		return req, paramError("nx", "give either dx or nx, not both")
+	}
+	if nt > 0 && sim.Dt != 0 {
+		return req, paramError("nt", "give either dt or nt, not both")
+	}
+	if req.Alpha, err = floatParam(q, "alpha", 1); err != nil {
+		return req, err
+	}
+	if !(req.Alpha > 0) {
+		return req, paramError("alpha", "must be positive")
+	}
+
+	// Значения по умолчанию те же, что у /simulate
+	if sim.Tmax == 0 {
+		sim.Tmax = 1.0
+	}
+	if nx > 0 {
+		sim.Dx = 1 / float64(nx)
+	}
+	if nt > 0 {
+		sim.Dt = sim.Tmax / float64(nt)
+	}
+	if req.Params, err = sim.params(); err != nil {
+		return req, err
+	}
+	return req, nil
+}
+
+// Есть ли точное решение для выбранных условий
+func (req analyticalRequest) reference() error {
+	if req.IC != referenceIC || req.BC != referenceBC {
+		return fmt.Errorf("%w for ic=%q, bc=%q (only ic=%s with bc=%s)",
+			errNoReference, req.IC, req.BC, referenceIC, referenceBC)
+	}
+	return nil
+}
+
+type analyticalResponse struct {
+	Nx     int       `json:"nx"`
+	Nt     int       `json:"nt"`
+	Dx     float64   `json:"dx"`
+	Dt     float64   `json:"dt"`
+	Tmax   float64   `json:"tmax"`
+	Alpha  float64   `json:"alpha"`
+	IC     string    `json:"ic"`
+	BC     string    `json:"bc"`
+	Stride int       `json:"stride"`
+	X      []float64 `json:"x"`
+	T      []float64 `json:"t"`
+	U      jsonRows  `json:"u_exact" doc:"Exact solution, one row per returned level"`
+}
+
+// Точное решение на сетке /simulate теми же формулами, что и нормы ошибки
+func exactGrid(req analyticalRequest) *analyticalResponse {
+	nx, nt := req.Params.Grid()
+	stride := max(1, req.Stride)
+	resp := &analyticalResponse{
+		Nx:     nx,
+		Nt:     nt,
+		Dx:     req.Params.Dx,
+		Dt:     req.Params.Dt,
+		Tmax:   float64(nt) * req.Params.Dt,
+		Alpha:  req.Alpha,
+		IC:     req.IC,
+		BC:     req.BC,
+		Stride: stride,
+		X:      make([]float64, nx+1),
+	}
+	for i := range resp.X {
+		resp.X[i] = float64(i) * resp.Dx
+	}
+	exact := mathutils.Analytical{Alpha: req.Alpha}.Grid(nx, resp.Dx)
+	for _, n := range levelIndices(nt, stride) {
+		t := float64(n) * resp.Dt
+		row := make([]float64, nx+1)
+		exact(row, t)
+		resp.T = append(resp.T, t)
+		resp.U = append(resp.U, row)
+	}
+	return resp
+}
+
+func (s *server) handleAnalytical(w http.ResponseWriter, r *http.Request) {
+	req, err := parseAnalytical(r.URL.Query())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if err := req.reference(); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if err := s.limits.Check(req.Params); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	key := fmt.Sprintf("analytical|%s|alpha=%g|ic=%s|bc=%s|stride=%d",
+		cacheKey(req.Params), req.Alpha, req.IC, req.BC, max(1, req.Stride))
+	v, hit := s.cache.Get(key)
+	if hit {
+		s.metrics.cacheHits.Inc()
+	} else {
+		s.metrics.cacheMisses.Inc()
+		resp := exactGrid(req)
+		s.cache.Add(key, resp, int64(len(resp.U))*int64(resp.Nx+1)*8, false)
+		v = resp
+	}
+	setCacheHeader(w, hit)
+
+	// Ответ зависит только от параметров запроса
+	w.Header().Set("Cache-Control", "public, max-age=86400")
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v.(*analyticalResponse))
+}
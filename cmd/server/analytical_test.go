@@ -0,0 +1,76 @@
+package main
+
+import (
+	"math"
+	"testing"
+
+	"heat-solver/internal/mathutils"
+)
+
+func TestAnalytical(t *testing.T) {
+	_, ts := newTestServer(t, nil)
+	resp, body := get(t, ts, "/api/v1/analytical?nx=20&nt=40&tmax=0.2&stride=10", 200)
+	var res struct {
+		Nx int         `json:"nx"`
+		Nt int         `json:"nt"`
+		Dx float64     `json:"dx"`
+		Dt float64     `json:"dt"`
+		X  []float64   `json:"x"`
+		T  []float64   `json:"t"`
+		U  [][]float64 `json:"u_exact"`
+	}
+	decode(t, body, &res)
+	if res.Nx != 20 || res.Nt != 40 || res.Dx != 0.05 || res.Dt != 0.005 {
+		t.Errorf("grid nx=%d nt=%d dx=%g dt=%g, want 20, 40, 0.05, 0.005", res.Nx, res.Nt, res.Dx, res.Dt)
+	}
+	if len(res.T) != 5 || len(res.U) != len(res.T) || len(res.X) != res.Nx+1 {
+		t.Fatalf("%d levels at t = %v, %d nodes; want 5 levels and 21 nodes", len(res.U), res.T, len(res.X))
+	}
+	for k, row := range res.U {
+		for i, v := range row {
+			if want := mathutils.AnalyticalSolution(res.X[i], res.T[k]); math.Abs(v-want) > 1e-15 {
+				t.Fatalf("u_exact(x=%g, t=%g) = %g, AnalyticalSolution %g", res.X[i], res.T[k], v, want)
+			}
+		}
+	}
+	if resp.Header.Get("Cache-Control") == "" {
+		t.Error("analytical response without Cache-Control")
+	}
+	if resp, _ := get(t, ts, "/api/v1/analytical?nx=20&nt=40&tmax=0.2&stride=10", 200); resp.Header.Get("X-Cache") != "HIT" {
+		t.Errorf("repeated request X-Cache = %q, want HIT", resp.Header.Get("X-Cache"))
+	}
+
+	// α масштабирует показатель экспоненты
+	_, body = get(t, ts, "/api/v1/analytical?dx=0.1&dt=0.05&tmax=0.1&alpha=0.5", 200)
+	decode(t, body, &res)
+	if want := mathutils.AnalyticalSolutionAlpha(0.3, 0.1, 0.5); math.Abs(res.U[2][3]-want) > 1e-15 {
+		t.Errorf("u_exact(0.3, 0.1) with alpha 0.5 = %g, want %g", res.U[2][3], want)
+	}
+}
+
+func TestAnalyticalErrors(t *testing.T) {
+	_, ts := newTestServer(t, func(cfg *serverConfig) {
+		cfg.Limits.MaxNx = 500
+	})
+	tests := []struct {
+		name, query string
+		status      int
+		code        string
+	}{
+		{"no reference for ic", "ic=tophat", 422, codeNoReference},
+		{"no reference for bc", "bc=neumann", 422, codeNoReference},
+		{"dx and nx", "dx=0.1&nx=10", 400, codeInvalidParameter},
+		{"bad alpha", "alpha=-1", 400, codeInvalidParameter},
+		{"limit", "nx=1000", 413, codeLimitExceeded},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, body := get(t, ts, "/api/v1/analytical?"+tc.query, tc.status)
+			var env errorEnvelope
+			decode(t, body, &env)
+			if env.Error.Code != tc.code {
+				t.Errorf("code %s, want %s", env.Error.Code, tc.code)
+			}
+		})
+	}
+}
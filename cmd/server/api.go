@@ -0,0 +1,634 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"heat-solver/internal/config"
+	heatio "heat-solver/internal/io"
+	"heat-solver/internal/mathutils"
+	"heat-solver/internal/render"
+	"heat-solver/internal/solver"
+)
+
+// Все маршруты сервера. Версии API регистрируются отдельными функциями,
+// чтобы /api/v2 добавлялся рядом без правок существующих.
+func (s *server) routes() http.Handler {
+	mux := http.NewServeMux()
+	// Только GET: иначе "/" перехватывал бы любой метод, и ServeMux не
+	// отвечал бы 405 на неподдерживаемые методы маршрутов API
+	mux.Handle("GET /", s.web)
+
+	s.registerV1(mux, "/api/v1")
+	s.registerLegacy(mux)
+
+	// Служебные маршруты: без лимитов и авторизации, солвер не вызывают
+	mux.HandleFunc("GET /healthz", s.handleHealthz)
+	mux.HandleFunc("GET /readyz", s.handleReadyz)
+	mux.HandleFunc("GET /version", s.handleVersion)
+	mux.HandleFunc("GET /metrics", s.metrics.handleMetrics)
+	mux.HandleFunc("GET /docs", s.handleDocs)
+
+	return requestLogger(s.metrics.middleware(recoverPanics(cors(s.corsOrigins, jsonErrors(mux)))))
+}
+
+func (s *server) registerV1(mux *http.ServeMux, prefix string) {
+	mux.HandleFunc("GET "+prefix+"/simulate", s.requireKey(s.handleSimulateV1))
+	mux.HandleFunc("POST "+prefix+"/simulate", s.requireKey(s.handleSimulateV1))
+	mux.HandleFunc("GET "+prefix+"/simulate.png", s.requireKey(s.handleSimulatePNG))
+	mux.HandleFunc("GET "+prefix+"/simulate.gif", s.requireKey(s.handleSimulateGIF))
+	mux.HandleFunc("GET "+prefix+"/frames", s.requireKey(s.handleFrames))
+	mux.HandleFunc("GET "+prefix+"/analytical", s.requireKey(s.handleAnalytical))
+	mux.HandleFunc("POST "+prefix+"/jobs", s.requireKey(s.handleSubmitJob))
+	mux.HandleFunc("GET "+prefix+"/jobs/{id}", s.requireKey(s.handleGetJob))
+	mux.HandleFunc("GET "+prefix+"/jobs/{id}/result", s.requireKey(s.handleJobResult))
+	mux.HandleFunc("POST "+prefix+"/compare", s.requireKey(s.handleCompare))
+	mux.HandleFunc("GET "+prefix+"/session", s.requireKey(s.handleSession))
+	mux.HandleFunc("POST "+prefix+"/batch", s.requireKey(s.handleBatch))
+	mux.HandleFunc("POST "+prefix+"/evaluate", s.requireKey(s.handleEvaluate))
+	mux.HandleFunc("GET "+prefix+"/methods", s.handleMethods)
+	mux.HandleFunc("GET "+prefix+"/openapi.json", s.handleOpenAPI)
+}
+
+// Пути до версионирования; веб-интерфейс пока работает через них
+func (s *server) registerLegacy(mux *http.ServeMux) {
+	mux.Handle("GET /simulate", deprecated("/api/v1/simulate", s.requireKey(s.handleSimulateLegacy)))
+	mux.Handle("POST /simulate", deprecated("/api/v1/simulate", s.requireKey(s.handleSimulateLegacy)))
+	mux.Handle("GET /simulate.png", deprecated("/api/v1/simulate.png", s.requireKey(s.handleSimulatePNG)))
+	mux.Handle("GET /simulate.gif", deprecated("/api/v1/simulate.gif", s.requireKey(s.handleSimulateGIF)))
+	mux.Handle("GET /methods", deprecated("/api/v1/methods", s.handleMethods))
+}
+
+// Помечает ответ устаревшего маршрута и указывает замену
+func deprecated(successor string, h http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Deprecation", "true")
+		w.Header().Set("Link", "<"+successor+`>; rel="successor-version"`)
+		h(w, r)
+	})
+}
+
+// Параметры расчёта из строки запроса или JSON-тела
+// (тег doc — описание поля в OpenAPI)
+type simulateRequest struct {
+	Method string  `json:"method" doc:"Numerical scheme name or alias, case-insensitive"`
+	Dx     float64 `json:"dx" doc:"Spatial step; rounded so that 1/dx is an integer"`
+	Dt     float64 `json:"dt" doc:"Time step"`
+	Tmax   float64 `json:"tmax" doc:"Final time, at least dt"`
+	Stride int     `json:"stride" doc:"Return every stride-th time level; the final one is always included"`
+	// Начальное условие: именованное (sine или tophat) или выборка
+	IC           string          `json:"ic" doc:"Initial condition preset: sine (default) or tophat (1 on (1/4, 3/4), 0 outside); not allowed together with ic_samples"`
+	ICSamples    []config.Sample `json:"ic_samples" doc:"Initial profile as points {x, u}: x strictly increasing from 0 to 1, u = 0 at both ends; linearly interpolated onto the grid"`
+	Reference    string          `json:"reference" doc:"Reference for error norms with ic_samples: spectral (Fourier series of the interpolated profile); without it norms are null"`
+	Storage      string          `json:"storage" doc:"full keeps every time level; final-only keeps two in memory and returns just the initial and final levels (stride is ignored)"`
+	Precision    string          `json:"precision" doc:"Precision of the stored levels: float64 (default) or float32, which halves memory; time stepping and error norms always use float64"`
+	Adapt        bool            `json:"adapt" doc:"Choose the BTCS or CN time step by step doubling; dt is then the output interval and the largest step"`
+	Tol          float64         `json:"tol" doc:"Local error tolerance per step (max norm) with adapt"`
+	SpatialOrder int             `json:"spatial_order" doc:"Order in x: 2 (three-point differences, default) or 4 (see stencil)"`
+	Stencil      string          `json:"stencil" doc:"Fourth-order stencil with spatial_order 4: compact (default; (1 + dx²/12 δ²) u_t = δ²u/dx² for BTCS and CN) or wide (five points (−1, 16, −30, 16, −1)/12dx² for FTCS, BTCS and CN)"`
+	Lambda       float64         `json:"lambda" doc:"Linear reaction coefficient λ ≥ 0 of u_t = u_xx − λu (FTCS, BTCS and CN); the sine reference becomes exp(−(π² + λ)t)·sin(πx)"`
+	Source       string          `json:"source" doc:"Volumetric source f(x, t) of u_t = u_xx + f (FTCS, BTCS and CN): a preset (sine, gaussian, manufactured) or an expression in x and t such as sin(pi*x) or exp(-t)*x*(1-x); with ic=sine the sine and manufactured presets keep an exact reference, other sources have none"`
+	// Параметры пучка source=laser; без значения — mathutils.DefaultLaser
+	LaserPower *float64 `json:"laser_power" doc:"Peak power density P of source=laser, f = P·exp(−(x − x₀ − vt)²/2σ²) (default 100)"`
+	LaserSpeed *float64 `json:"laser_speed" doc:"Scanning speed v of source=laser; 0 keeps the beam in place (default 0.5). After the centre leaves [0, 1] only the tail of the beam heats the rod"`
+	LaserWidth *float64 `json:"laser_width" doc:"Beam width σ > 0 of source=laser (default 0.05)"`
+	LaserStart *float64 `json:"laser_start" doc:"Beam centre x₀ of source=laser at t = 0 (default 0)"`
+	// Без значения — по config.DiscontinuousIC
+	Rannacher *bool `json:"rannacher" doc:"Rannacher startup for CN: the first two steps are four BTCS half-steps, which damp the oscillations from discontinuous data; by default on for CN with ic=tophat"`
+	// Пороги для max_x u; на расчёт и ключ кэша не влияют
+	Thresholds []float64 `json:"thresholds" doc:"Report the first time max_x u drops below each of these values (JSON response only)"`
+}
+
+// Порогов в одном запросе
+const maxThresholds = 64
+
+const (
+	refAnalytical = "analytical"
+	refSpectral   = "spectral"
+	refNone       = "none"
+)
+
+// Число членов ряда Фурье для эталона по выборке
+const spectralModes = 1024
+
+func requestFromQuery(q url.Values) (simulateRequest, error) {
+	var req simulateRequest
+	var err error
+
+	req.Method = q.Get("method")
+	if req.Dx, err = floatParam(q, "dx", 0); err != nil {
+		return req, err
+	}
+	if req.Dt, err = floatParam(q, "dt", 0); err != nil {
+		return req, err
+	}
+	if req.Tmax, err = floatParam(q, "tmax", 0); err != nil {
+		return req, err
+	}
+	if req.Stride, err = intParam(q, "stride", 0); err != nil {
+		return req, paramError("stride", "must be a positive integer")
+	}
+	req.IC = q.Get("ic")
+	req.Reference = q.Get("reference")
+	req.Storage = q.Get("storage")
+	req.Precision = q.Get("precision")
+	if v := q.Get("adapt"); v != "" {
+		if req.Adapt, err = strconv.ParseBool(v); err != nil {
+			return req, paramError("adapt", "must be true or false")
+		}
+	}
+	if req.Tol, err = floatParam(q, "tol", 0); err != nil {
+		return req, err
+	}
+	if req.SpatialOrder, err = intParam(q, "spatial_order", 0); err != nil {
+		return req, paramError("spatial_order", "must be 2 or 4")
+	}
+	req.Stencil = q.Get("stencil")
+	if req.Lambda, err = floatParam(q, "lambda", 0); err != nil {
+		return req, err
+	}
+	req.Source = q.Get("source")
+	for _, f := range []struct {
+		name string
+		dst  **float64
+	}{{"laser_power", &req.LaserPower}, {"laser_speed", &req.LaserSpeed}, {"laser_width", &req.LaserWidth}, {"laser_start", &req.LaserStart}} {
+		if !q.Has(f.name) {
+			continue
+		}
+		v, err := floatParam(q, f.name, 0)
+		if err != nil {
+			return req, err
+		}
+		*f.dst = &v
+	}
+	if v := q.Get("rannacher"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return req, paramError("rannacher", "must be true or false")
+		}
+		req.Rannacher = &b
+	}
+	return req, nil
+}
+
+// GET — параметры в строке запроса, POST — в JSON-теле
+func decodeSimulateRequest(w http.ResponseWriter, r *http.Request) (simulateRequest, error) {
+	if r.Method != http.MethodPost {
+		return requestFromQuery(r.URL.Query())
+	}
+
+	var req simulateRequest
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		return req, paramError("body", "invalid JSON: "+err.Error())
+	}
+	return req, nil
+}
+
+// Значения по умолчанию, проверка и нормализация
+func (req simulateRequest) params() (config.Params, error) {
+	if req.Method == "" {
+		req.Method = "FTCS"
+	}
+	if req.Dx == 0 {
+		req.Dx = 0.1
+	}
+	if req.Dt == 0 {
+		req.Dt = 0.001
+	}
+	if req.Tmax == 0 {
+		req.Tmax = 1.0
+	}
+	if req.Stride < 0 {
+		return config.Params{}, paramError("stride", "must be a positive integer")
+	}
+	if len(req.Thresholds) > maxThresholds {
+		return config.Params{}, &config.LimitError{Field: "thresholds", Limit: maxThresholds, Value: int64(len(req.Thresholds))}
+	}
+	switch {
+	case req.IC != "" && req.ICSamples != nil:
+		return config.Params{}, fmt.Errorf("%w: ic and ic_samples are mutually exclusive", errConflict)
+	}
+	switch strings.ToLower(req.Reference) {
+	case "", refSpectral:
+	default:
+		return config.Params{}, paramError("reference", "must be "+refSpectral)
+	}
+
+	params := config.Params{
+		Method:       req.Method,
+		Dx:           req.Dx,
+		Dt:           req.Dt,
+		Tmax:         req.Tmax,
+		ICSamples:    req.ICSamples,
+		Storage:      req.Storage,
+		Precision:    req.Precision,
+		Adapt:        req.Adapt,
+		Tol:          req.Tol,
+		IC:           req.IC,
+		SpatialOrder: req.SpatialOrder,
+		Stencil:      req.Stencil,
+		Lambda:       req.Lambda,
+		Source:       req.Source,
+	}
+	if req.Rannacher != nil {
+		params.Rannacher = *req.Rannacher
+	}
+	if params.LaserSource() {
+		params.Laser = req.laser()
+	} else if req.LaserPower != nil || req.LaserSpeed != nil || req.LaserWidth != nil || req.LaserStart != nil {
+		return config.Params{}, paramError("source", "laser_power, laser_speed, laser_width and laser_start need source "+mathutils.SourceLaser)
+	}
+	if err := params.Validate(); err != nil {
+		return params, err
+	}
+	params = params.Normalize()
+
+	// Псевдонимы приводятся к каноническому имени, чтобы ключ кэша был один
+	m, ok := solver.Lookup(params.Method)
+	if !ok {
+		return params, paramError("method", "unknown method "+strconv.Quote(params.Method))
+	}
+	params.Method = m.Name
+	if req.Rannacher == nil {
+		params.Rannacher = solver.RannacherByDefault(params)
+	}
+	return params, nil
+}
+
+// Пучок source=laser: заданные поля поверх mathutils.DefaultLaser
+func (req simulateRequest) laser() mathutils.Laser {
+	l := mathutils.DefaultLaser
+	for _, f := range []struct {
+		src *float64
+		dst *float64
+	}{{req.LaserPower, &l.Power}, {req.LaserSpeed, &l.Speed}, {req.LaserWidth, &l.Width}, {req.LaserStart, &l.Start}} {
+		if f.src != nil {
+			*f.dst = *f.src
+		}
+	}
+	return l
+}
+
+// Эталон для норм ошибки: точное решение для sin(πx), ряд Фурье для
+// ступеньки и для выборки (по запросу), иначе эталона нет (exact == nil)
+func (req simulateRequest) reference(p config.Params) (name string, exact mathutils.Reference) {
+	if exact := solver.PresetReference(p); exact != nil {
+		if config.DiscontinuousIC(p.IC) {
+			return refSpectral, exact
+		}
+		return refAnalytical, exact
+	}
+	// Ряд Фурье выборки не знает об источнике
+	if !strings.EqualFold(req.Reference, refSpectral) || p.Source != "" {
+		return refNone, nil
+	}
+	xs := make([]float64, len(p.ICSamples))
+	us := make([]float64, len(p.ICSamples))
+	for i, s := range p.ICSamples {
+		xs[i], us[i] = s.X, s.U
+	}
+	return refSpectral, mathutils.NewSineSeries(xs, us, spectralModes).WithReaction(p.Lambda)
+}
+
+// Разбор и нормализация параметров из строки запроса
+func parseParams(q url.Values) (config.Params, error) {
+	req, err := requestFromQuery(q)
+	if err != nil {
+		return config.Params{}, err
+	}
+	return req.params()
+}
+
+// Числа, у которых NaN/Inf кодируются как null (обычный json их не допускает)
+type jsonFloat float64
+
+func (f jsonFloat) MarshalJSON() ([]byte, error) {
+	v := float64(f)
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return []byte("null"), nil
+	}
+	return strconv.AppendFloat(nil, v, 'g', -1, 64), nil
+}
+
+// Одна строка решения; NaN/Inf — null
+type jsonRow []float64
+
+func (r jsonRow) MarshalJSON() ([]byte, error) {
+	return heatio.AppendJSONRow(nil, r, 64), nil
+}
+
+type jsonRows [][]float64
+
+func (u jsonRows) MarshalJSON() ([]byte, error) {
+	return resultRows{rows: u, bits: 64}.MarshalJSON()
+}
+
+// Слои результата расчёта. Слои, хранившиеся во float32, записываются
+// кратчайшей для float32 записью — примерно вдвое короче. Слои берутся
+// из rows или, если задан levels, читаются из результата по одному при
+// записи ответа
+type resultRows struct {
+	rows   [][]float64
+	levels levelSource
+	bits   int
+}
+
+func newResultRows(res *solver.Result, stride int) resultRows {
+	return resultRows{levels: resultLevels(res, stride), bits: precisionBits(res)}
+}
+
+func (u resultRows) each(yield func(row []float64) error) error {
+	if u.levels != nil {
+		return u.levels(func(_ float64, row []float64) error { return yield(row) })
+	}
+	for _, row := range u.rows {
+		if err := yield(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (u resultRows) MarshalJSON() ([]byte, error) {
+	buf := []byte{'['}
+	err := u.each(func(row []float64) error {
+		if len(buf) > 1 {
+			buf = append(buf, ',')
+		}
+		buf = heatio.AppendJSONRow(buf, row, u.bits)
+		return nil
+	})
+	return append(buf, ']'), err
+}
+
+// Потоковая запись: по строке за раз через один переиспользуемый буфер
+func (u resultRows) writeJSON(w *bufio.Writer) error {
+	buf := []byte{'['}
+	first := true
+	err := u.each(func(row []float64) error {
+		if !first {
+			buf = append(buf, ',')
+		}
+		first = false
+		buf = heatio.AppendJSONRow(buf, row, u.bits)
+		_, err := w.Write(buf)
+		buf = buf[:0]
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(buf, ']'))
+	return err
+}
+
+// Разрядность хранимых слоёв для strconv.AppendFloat
+func precisionBits(res *solver.Result) int {
+	if res.Precision == config.PrecisionFloat32 {
+		return 32
+	}
+	return 64
+}
+
+type normsResponse struct {
+	L2   jsonFloat `json:"l2"`
+	Linf jsonFloat `json:"linf"`
+}
+
+// Нормы ошибки последнего слоя относительно exact в момент nt·dt. Считаются
+// по res.Final в float64, в том числе при хранении истории во float32
+func finalNorms(res *solver.Result, exact mathutils.Reference) *normsResponse {
+	tmax := float64(res.Nt) * res.Dt
+	l2, linf := mathutils.LevelErrors(res.Final, res.Dx, tmax, exact)
+	return &normsResponse{L2: jsonFloat(l2), Linf: jsonFloat(linf)}
+}
+
+type simulateResponse struct {
+	Method     string         `json:"method" doc:"Canonical scheme name"`
+	Nx         int            `json:"nx" doc:"Number of spatial intervals"`
+	Nt         int            `json:"nt" doc:"Number of time steps"`
+	Dx         float64        `json:"dx" doc:"Spatial step actually used"`
+	Dt         float64        `json:"dt"`
+	Tmax       float64        `json:"tmax" doc:"nt*dt"`
+	R          float64        `json:"r" doc:"Mesh ratio dt/dx²"`
+	Condition  jsonFloat      `json:"condition" doc:"Estimated ∞-norm condition number of the BTCS/CN step matrix; 0 for explicit schemes, null for a singular matrix"`
+	Stride     int            `json:"stride"`
+	X          []float64      `json:"x" doc:"Grid nodes, nx+1 values"`
+	T          []float64      `json:"t" doc:"Times of the returned levels"`
+	U          resultRows     `json:"u" doc:"Solution, one row per returned level; NaN/Inf as null"`
+	Precision  string         `json:"precision" doc:"Precision of the stored levels; float32 values are written with float32 round-trip precision"`
+	IC         string         `json:"ic" doc:"sine, tophat or samples"`
+	Reference  string         `json:"reference" doc:"What the norms are measured against: analytical, spectral or none"`
+	Norms      *normsResponse `json:"norms" doc:"Error against the reference solution at tmax; null when there is no reference"`
+	RuntimeSec float64        `json:"runtime_sec"`
+	Diverged   bool           `json:"diverged"`
+
+	MaxPrinciple maxPrincipleResponse `json:"max_principle" doc:"Check of every computed level against the range of the initial and boundary data"`
+	Thresholds   []thresholdResponse  `json:"thresholds,omitempty" doc:"First drop of max_x u below each requested threshold, in request order"`
+	Adaptive     *adaptiveResponse    `json:"adaptive,omitempty" doc:"Step size history of an adapt run"`
+}
+
+type adaptiveResponse struct {
+	Tol      float64                `json:"tol"`
+	Accepted int                    `json:"accepted"`
+	Rejected int                    `json:"rejected" doc:"Steps retried with a smaller dt, over the whole run"`
+	MinDt    float64                `json:"min_dt"`
+	MaxDt    float64                `json:"max_dt"`
+	Steps    []adaptiveStepResponse `json:"steps" doc:"Accepted steps in order"`
+}
+
+type adaptiveStepResponse struct {
+	T        float64 `json:"t" doc:"Time at the end of the step"`
+	Dt       float64 `json:"dt"`
+	Error    float64 `json:"error" doc:"Step-doubling estimate of the local error"`
+	Rejected int     `json:"rejected" doc:"Tries rejected before this step"`
+}
+
+// История шагов адаптивного расчёта; nil при постоянном шаге
+func newAdaptiveResponse(res *solver.Result, tol float64) *adaptiveResponse {
+	if res.Steps == nil {
+		return nil
+	}
+	out := &adaptiveResponse{Tol: tol, Accepted: len(res.Steps), MinDt: math.Inf(1), Steps: make([]adaptiveStepResponse, len(res.Steps))}
+	for i, s := range res.Steps {
+		out.Rejected += s.Rejected
+		out.MinDt, out.MaxDt = min(out.MinDt, s.Dt), max(out.MaxDt, s.Dt)
+		out.Steps[i] = adaptiveStepResponse{T: s.T, Dt: s.Dt, Error: s.Error, Rejected: s.Rejected}
+	}
+	return out
+}
+
+type thresholdResponse struct {
+	Threshold float64  `json:"threshold"`
+	Reached   bool     `json:"reached" doc:"false when max_x u stays at or above the threshold up to tmax"`
+	T         *float64 `json:"t" doc:"Crossing time, linearly interpolated between the bracketing time levels; null when not reached"`
+	X         *float64 `json:"x" doc:"Position of the maximum at that time, interpolated the same way; null when not reached"`
+	Step      *int     `json:"step" doc:"First time step n with max_x u below the threshold; null when not reached"`
+}
+
+func newThresholdResponses(res *solver.Result, thresholds []float64) []thresholdResponse {
+	var out []thresholdResponse
+	for _, c := range res.Crossings(thresholds...) {
+		tr := thresholdResponse{Threshold: c.Threshold, Reached: c.Reached}
+		if c.Reached {
+			tr.T, tr.X, tr.Step = &c.Time, &c.X, &c.Step
+		}
+		out = append(out, tr)
+	}
+	return out
+}
+
+type maxPrincipleResponse struct {
+	Min        float64            `json:"min" doc:"Lower bound: min of the initial profile and the zero boundary values"`
+	Max        float64            `json:"max" doc:"Upper bound: max of the initial profile and the zero boundary values"`
+	Violations int64              `json:"violations" doc:"Nodes over all levels outside [min, max] beyond round-off"`
+	First      *violationResponse `json:"first" doc:"Earliest violation; null when there is none"`
+	Worst      *violationResponse `json:"worst" doc:"Largest violation; null when there is none"`
+}
+
+type violationResponse struct {
+	Step      int     `json:"step" doc:"Time step n"`
+	Node      int     `json:"node" doc:"Grid node i"`
+	X         float64 `json:"x"`
+	T         float64 `json:"t"`
+	Magnitude float64 `json:"magnitude" doc:"Distance below min or above max"`
+	Below     bool    `json:"below" doc:"true for an undershoot below min"`
+}
+
+func newMaxPrincipleResponse(res *solver.Result) maxPrincipleResponse {
+	mp := res.MaxPrinciple
+	out := maxPrincipleResponse{Min: mp.Lo, Max: mp.Hi, Violations: mp.Count}
+	if mp.Count == 0 {
+		return out
+	}
+	violation := func(v solver.Violation) *violationResponse {
+		return &violationResponse{
+			Step:      v.Step,
+			Node:      v.Node,
+			X:         float64(v.Node) * res.Dx,
+			T:         float64(v.Step) * res.Dt,
+			Magnitude: v.Magnitude,
+			Below:     v.Below,
+		}
+	}
+	out.First, out.Worst = violation(mp.First), violation(mp.Worst)
+	return out
+}
+
+// Ответ v1: координаты, выбранные временные слои и нормы ошибки
+// относительно эталона exact (nil — без норм)
+func newSimulateResponse(res *solver.Result, stride int, ic string, ref string, exact mathutils.Reference) simulateResponse {
+	stride = resultStride(res, stride)
+
+	x := make([]float64, res.Nx+1)
+	for i := range x {
+		x[i] = float64(i) * res.Dx
+	}
+	var t []float64
+	for _, n := range levelIndices(res.Nt, stride) {
+		t = append(t, float64(n)*res.Dt)
+	}
+	var norms *normsResponse
+	if exact != nil {
+		norms = finalNorms(res, exact)
+	}
+
+	return simulateResponse{
+		Method:     res.Method,
+		Nx:         res.Nx,
+		Nt:         res.Nt,
+		Dx:         res.Dx,
+		Dt:         res.Dt,
+		Tmax:       float64(res.Nt) * res.Dt,
+		R:          res.Dt / (res.Dx * res.Dx),
+		Condition:  jsonFloat(res.Condition),
+		Stride:     stride,
+		X:          x,
+		T:          t,
+		U:          newResultRows(res, stride),
+		Precision:  res.Precision,
+		IC:         ic,
+		Reference:  ref,
+		Norms:      norms,
+		RuntimeSec: res.Runtime.Seconds(),
+		Diverged:   res.Diverged,
+
+		MaxPrinciple: newMaxPrincipleResponse(res),
+	}
+}
+
+// Формат ответа выбирается по Accept: JSON, CSV, NDJSON или PNG
+func (s *server) handleSimulateV1(w http.ResponseWriter, r *http.Request) {
+	media, err := negotiate(w, r, resultMedia)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	var image render.HeatmapOptions
+	if media == mediaPNG {
+		if image, err = heatmapOptions(r.URL.Query()); err != nil {
+			writeError(w, r, err)
+			return
+		}
+	}
+
+	req, err := decodeSimulateRequest(w, r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	params, err := req.params()
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	var exact mathutils.Reference
+	if media == mediaCSV {
+		exact, err = csvReference(r.URL.Query(), func() mathutils.Reference {
+			_, exact := req.reference(params)
+			return exact
+		})
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), s.timeout)
+	defer cancel()
+	res, hit, err := s.simulate(ctx, params)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	defer res.Release()
+	setCacheHeader(w, hit)
+
+	if media != mediaJSON {
+		writeLevelsResponse(w, r, media, res.Dx, precisionBits(res), exact, res.Diverged, image, resultLevels(res, req.Stride))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	ref, exact := req.reference(params)
+	ic := params.IC
+	if params.ICSamples != nil {
+		ic = "samples"
+	}
+	resp := newSimulateResponse(res, req.Stride, ic, ref, exact)
+	resp.Thresholds = newThresholdResponses(res, req.Thresholds)
+	resp.Adaptive = newAdaptiveResponse(res, params.Tol)
+	if err := writeJSONStream(w, resp); err != nil {
+		slog.ErrorContext(r.Context(), "Result streaming aborted", "format", media, "error", err)
+	}
+}
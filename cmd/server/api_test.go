@@ -0,0 +1,418 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// Ответ /api/v1/simulate в виде для проверок (u без null)
+type simulateResult struct {
+	Method   string      `json:"method"`
+	Nx       int         `json:"nx"`
+	Nt       int         `json:"nt"`
+	Dx       float64     `json:"dx"`
+	Dt       float64     `json:"dt"`
+	Stride   int         `json:"stride"`
+	X        []float64   `json:"x"`
+	T        []float64   `json:"t"`
+	U        [][]float64 `json:"u"`
+	IC       string      `json:"ic"`
+	Diverged bool        `json:"diverged"`
+	Norms    *struct {
+		L2   float64 `json:"l2"`
+		LInf float64 `json:"linf"`
+	} `json:"norms"`
+}
+
+func TestSimulateV1(t *testing.T) {
+	_, ts := newTestServer(t, nil)
+	const query = "method=crank-nicolson&dx=0.1&dt=0.01&tmax=0.1&stride=5"
+	_, byGet := get(t, ts, "/api/v1/simulate?"+query, 200)
+	_, byPost := postJSON(t, ts, "/api/v1/simulate", `{"method":"crank-nicolson","dx":0.1,"dt":0.01,"tmax":0.1,"stride":5}`, 200)
+
+	var res simulateResult
+	decode(t, byGet, &res)
+	if res.Method != "CN" || res.Nx != 10 || res.Nt != 10 || res.Stride != 5 || res.IC != "sine" {
+		t.Errorf("got method %s, nx %d, nt %d, stride %d, ic %s; want CN, 10, 10, 5, sine", res.Method, res.Nx, res.Nt, res.Stride, res.IC)
+	}
+	if len(res.X) != res.Nx+1 || !slices.Equal(res.T, []float64{0, 0.05, 0.1}) || len(res.U) != len(res.T) {
+		t.Errorf("x has %d nodes, t = %v, %d rows of u; want 11 nodes, t = [0 0.05 0.1] and a row per t", len(res.X), res.T, len(res.U))
+	}
+	if res.Norms == nil || !(res.Norms.LInf > 0 && res.Norms.LInf < 1e-2) {
+		t.Errorf("norms %+v, want a small error against the analytical solution", res.Norms)
+	}
+
+	var post simulateResult
+	decode(t, byPost, &post)
+	if !slices.EqualFunc(res.U, post.U, slices.Equal) {
+		t.Error("GET and POST with the same parameters returned different solutions")
+	}
+}
+
+// Устаревший /simulate: прежняя форма {dx, dt, u} и пометка Deprecation
+func TestSimulateLegacy(t *testing.T) {
+	_, ts := newTestServer(t, nil)
+	resp, body := get(t, ts, "/simulate?method=CN&dx=0.1&dt=0.01&tmax=0.1", 200)
+	if resp.Header.Get("Deprecation") != "true" || resp.Header.Get("Link") != `</api/v1/simulate>; rel="successor-version"` {
+		t.Errorf("Deprecation %q, Link %q; want the deprecation headers", resp.Header.Get("Deprecation"), resp.Header.Get("Link"))
+	}
+	var fields map[string]json.RawMessage
+	decode(t, body, &fields)
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	if !slices.Equal(keys, []string{"dt", "dx", "u"}) {
+		t.Errorf("legacy response fields %v, want [dt dx u]", keys)
+	}
+
+	var legacy struct {
+		Dx, Dt float64
+		U      [][]float64
+	}
+	decode(t, body, &legacy)
+	_, v1 := get(t, ts, "/api/v1/simulate?method=CN&dx=0.1&dt=0.01&tmax=0.1", 200)
+	var res simulateResult
+	decode(t, v1, &res)
+	if legacy.Dx != res.Dx || legacy.Dt != res.Dt || !slices.EqualFunc(legacy.U, res.U, slices.Equal) {
+		t.Error("legacy and v1 responses disagree on the solution")
+	}
+
+	resp, _ = get(t, ts, "/methods", 200)
+	if resp.Header.Get("Deprecation") != "true" {
+		t.Error("legacy /methods without Deprecation header")
+	}
+	for _, path := range []string{"/simulate.png?dx=0.1&tmax=0.01", "/simulate.gif?dx=0.1&tmax=0.01"} {
+		if resp, _ := get(t, ts, path, 200); resp.Header.Get("Deprecation") != "true" {
+			t.Errorf("%s without Deprecation header", path)
+		}
+	}
+	// v1 не помечен устаревшим
+	if resp, _ := get(t, ts, "/api/v1/simulate?dx=0.1&tmax=0.01", 200); resp.Header.Get("Deprecation") != "" {
+		t.Error("/api/v1/simulate marked deprecated")
+	}
+}
+
+// Разошедшееся решение: NaN и Inf в JSON пишутся как null
+func TestSimulateDivergedNulls(t *testing.T) {
+	_, ts := newTestServer(t, nil)
+	_, body := get(t, ts, "/api/v1/simulate?method=FTCS&dx=0.05&dt=0.01&tmax=10&storage=final-only", 200)
+	var res struct {
+		Diverged bool         `json:"diverged"`
+		U        [][]*float64 `json:"u"`
+	}
+	decode(t, body, &res)
+	last := res.U[len(res.U)-1]
+	nulls := 0
+	for _, v := range last {
+		if v == nil {
+			nulls++
+		}
+	}
+	if !res.Diverged || nulls == 0 {
+		t.Errorf("diverged %v with %d nulls in the last level, want a diverged run with nulls", res.Diverged, nulls)
+	}
+}
+
+// Принцип максимума в ответе: у одного внутреннего узла CN при r = 5 первое
+// и наибольшее нарушение — шаг 1 с недолётом 2/3; у BTCS нарушений нет
+func TestSimulateMaxPrinciple(t *testing.T) {
+	_, ts := newTestServer(t, nil)
+	const spike = `"ic_samples":[{"x":0,"u":0},{"x":0.5,"u":1},{"x":1,"u":0}],"dx":0.5,"dt":1.25,"tmax":3.75`
+	tests := []struct {
+		method     string
+		violations int64
+		first      *violationResponse
+	}{
+		{"CN", 2, &violationResponse{Step: 1, Node: 1, X: 0.5, T: 1.25, Magnitude: 2.0 / 3, Below: true}},
+		{"BTCS", 0, nil},
+	}
+	for _, tc := range tests {
+		t.Run(tc.method, func(t *testing.T) {
+			_, body := postJSON(t, ts, "/api/v1/simulate", `{"method":"`+tc.method+`",`+spike+`}`, 200)
+			var res struct {
+				MaxPrinciple maxPrincipleResponse `json:"max_principle"`
+			}
+			decode(t, body, &res)
+			mp := res.MaxPrinciple
+			if mp.Min != 0 || mp.Max != 1 || mp.Violations != tc.violations {
+				t.Fatalf("bounds [%v, %v] with %d violations, want [0, 1] with %d", mp.Min, mp.Max, mp.Violations, tc.violations)
+			}
+			if tc.first == nil {
+				if mp.First != nil || mp.Worst != nil {
+					t.Errorf("first %+v, worst %+v; want null", mp.First, mp.Worst)
+				}
+				return
+			}
+			for _, v := range []*violationResponse{mp.First, mp.Worst} {
+				if v == nil || v.Step != tc.first.Step || v.Node != tc.first.Node || v.X != tc.first.X || v.T != tc.first.T ||
+					v.Below != tc.first.Below || math.Abs(v.Magnitude-tc.first.Magnitude) > 1e-12 {
+					t.Errorf("violation %+v, want %+v", v, tc.first)
+				}
+			}
+		})
+	}
+}
+
+// Обусловленность матрицы шага в ответе: 0 у явной схемы, у BTCS — между
+// 1 и 1 + 4r
+func TestSimulateCondition(t *testing.T) {
+	_, ts := newTestServer(t, nil)
+	tests := []struct {
+		method string
+		lo, hi float64
+	}{
+		{"FTCS", 0, 0},
+		{"BTCS", 1, 1 + 4*10},
+		{"CN", 1, 1 + 2*10},
+	}
+	for _, tc := range tests {
+		t.Run(tc.method, func(t *testing.T) {
+			_, body := get(t, ts, "/api/v1/simulate?method="+tc.method+"&dx=0.1&dt=0.1&tmax=0.1&storage=final-only", 200)
+			var res struct {
+				R         float64 `json:"r"`
+				Condition float64 `json:"condition"`
+			}
+			decode(t, body, &res)
+			if math.Abs(res.R-10) > 1e-12 || res.Condition < tc.lo || res.Condition > tc.hi {
+				t.Errorf("r = %v, condition %v; want r = 10 and condition in [%v, %v]", res.R, res.Condition, tc.lo, tc.hi)
+			}
+		})
+	}
+}
+
+// Пик sin(πx) опускается ниже ½ при t = ln 2/π² в x = ½; порог 10⁻⁶ до
+// tmax не достигается, и его поля пустые
+func TestSimulateThresholds(t *testing.T) {
+	_, ts := newTestServer(t, nil)
+	_, body := postJSON(t, ts, "/api/v1/simulate", `{"method":"CN","dx":0.01,"dt":1e-4,"tmax":0.1,"storage":"final-only","thresholds":[0.5,1e-6]}`, 200)
+	var res struct {
+		Thresholds []struct {
+			Threshold float64  `json:"threshold"`
+			Reached   bool     `json:"reached"`
+			T         *float64 `json:"t"`
+			X         *float64 `json:"x"`
+			Step      *int     `json:"step"`
+		} `json:"thresholds"`
+	}
+	decode(t, body, &res)
+	if len(res.Thresholds) != 2 {
+		t.Fatalf("%d thresholds, want 2", len(res.Thresholds))
+	}
+	half, tiny := res.Thresholds[0], res.Thresholds[1]
+	want := math.Ln2 / (math.Pi * math.Pi)
+	if half.Threshold != 0.5 || !half.Reached || half.T == nil || half.X == nil || half.Step == nil ||
+		math.Abs(*half.T-want) > 2e-4 || math.Abs(*half.X-0.5) > 1e-12 || *half.T > float64(*half.Step)*1e-4 || *half.T < float64(*half.Step-1)*1e-4 {
+		t.Errorf("threshold 0.5: %s; want t ≈ %.5f at x = 0.5", body, want)
+	}
+	if tiny.Threshold != 1e-6 || tiny.Reached || tiny.T != nil || tiny.X != nil || tiny.Step != nil {
+		t.Errorf("threshold 1e-6 reported as reached: %s", body)
+	}
+
+	many := strings.Repeat("0.5,", maxThresholds) + "0.5"
+	_, body = postJSON(t, ts, "/api/v1/simulate", `{"dx":0.1,"dt":0.001,"tmax":0.01,"thresholds":[`+many+`]}`, 413)
+	var env errorEnvelope
+	decode(t, body, &env)
+	if env.Error.Code != codeLimitExceeded || env.Error.Field != "thresholds" {
+		t.Errorf("error %+v, want %s on thresholds", env.Error, codeLimitExceeded)
+	}
+}
+
+// Схемы, добавленные к FTCS, BTCS и CN, выбираются по имени и псевдониму
+// и при допустимом шаге дают решение рядом с точным
+func TestSimulateMethods(t *testing.T) {
+	_, ts := newTestServer(t, nil)
+	tests := []struct {
+		method, name string
+		dx, dt       float64
+		linf         float64
+	}{
+		{"dufort-frankel", "DUFORT-FRANKEL", 0.05, 0.001, 1e-3},
+		// r = 5: FTCS здесь расходится
+		{"DF", "DUFORT-FRANKEL", 0.05, 0.0125, 0.1},
+		{"bdf2", "BDF2", 0.05, 0.01, 1e-3},
+		{"rk4", "RK4", 0.05, 0.0016, 1e-3},
+		// dt — только шаг выдачи
+		{"dopri5", "RK45", 0.05, 0.05, 1e-3},
+		{"dst", "SPECTRAL", 1.0 / 16, 0.1, 1e-14},
+		{"btcs-re", "BTCS-RICHARDSON", 0.05, 0.01, 1e-3},
+		// r = 2
+		{"ade", "SAULYEV", 0.05, 0.005, 5e-3},
+		// r = 1
+		{"odd-even-hopscotch", "HOPSCOTCH", 0.05, 0.0025, 5e-3},
+	}
+	for _, tc := range tests {
+		t.Run(tc.method, func(t *testing.T) {
+			query := fmt.Sprintf("/api/v1/simulate?method=%s&dx=%g&dt=%g&tmax=0.5&storage=final-only", tc.method, tc.dx, tc.dt)
+			_, body := get(t, ts, query, 200)
+			var res simulateResult
+			decode(t, body, &res)
+			if res.Method != tc.name || res.Diverged || res.Norms == nil || !(res.Norms.LInf < tc.linf) {
+				t.Errorf("method %s, diverged %v, norms %+v; want %s within %g of the exact solution", res.Method, res.Diverged, res.Norms, tc.name, tc.linf)
+			}
+		})
+	}
+}
+
+// Ступенька у CN при r = 16: старт Раннахера включён по умолчанию и гасит
+// колебания у разрывов, rannacher=false их возвращает; у других схем
+// старта нет
+func TestSimulateRannacher(t *testing.T) {
+	_, ts := newTestServer(t, nil)
+	// Полная вариация сверх одного максимума, наибольшая по слоям
+	oscillation := func(u [][]float64) float64 {
+		var e float64
+		for _, row := range u {
+			var tv, peak float64
+			for i, v := range row {
+				peak = max(peak, v)
+				if i > 0 {
+					tv += math.Abs(v - row[i-1])
+				}
+			}
+			e = max(e, tv/2-peak)
+		}
+		return e
+	}
+	const query = "/api/v1/simulate?method=CN&ic=tophat&dx=0.025&dt=0.01&tmax=0.1"
+	tests := []struct {
+		name      string
+		rannacher string
+		damped    bool
+	}{
+		{"default", "", true},
+		{"on", "&rannacher=true", true},
+		{"off", "&rannacher=false", false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, body := get(t, ts, query+tc.rannacher, 200)
+			var res simulateResult
+			decode(t, body, &res)
+			osc := oscillation(res.U)
+			if res.IC != "tophat" || len(res.U) != res.Nt+1 || (osc < 1e-12) != tc.damped || !tc.damped && osc < 0.1 {
+				t.Errorf("ic %q, %d levels of %d, oscillation %.3g; want damped: %v", res.IC, len(res.U), res.Nt+1, osc, tc.damped)
+			}
+		})
+	}
+
+	_, body := get(t, ts, "/api/v1/simulate?method=BTCS&ic=tophat&dx=0.025&dt=0.01&tmax=0.1&rannacher=true", 400)
+	var env errorEnvelope
+	decode(t, body, &env)
+	if env.Error.Code != codeInvalidParameter || env.Error.Field != "rannacher" {
+		t.Errorf("error %+v, want %s on rannacher", env.Error, codeInvalidParameter)
+	}
+}
+
+// spatial_order=4 у CN на грубой сетке точнее второго порядка в десятки
+// раз; у явной схемы компактной разности нет
+func TestSimulateSpatialOrder(t *testing.T) {
+	_, ts := newTestServer(t, nil)
+	const query = "/api/v1/simulate?method=CN&dx=0.1&dt=0.0001&tmax=0.1&storage=final-only"
+	linf := make(map[string]float64)
+	for _, order := range []string{"", "&spatial_order=2", "&spatial_order=4"} {
+		_, body := get(t, ts, query+order, 200)
+		var res simulateResult
+		decode(t, body, &res)
+		if res.Norms == nil {
+			t.Fatalf("%s: no norms", order)
+		}
+		linf[order] = res.Norms.LInf
+	}
+	if linf[""] != linf["&spatial_order=2"] || !(linf["&spatial_order=4"] < linf[""]/50) {
+		t.Errorf("L∞ %v by spatial order; want 2 by default and 4 far more accurate", linf)
+	}
+
+	_, body := get(t, ts, "/api/v1/simulate?method=FTCS&dx=0.1&dt=0.001&tmax=0.1&spatial_order=4", 400)
+	var env errorEnvelope
+	decode(t, body, &env)
+	if env.Error.Code != codeInvalidParameter || env.Error.Field != "spatial_order" {
+		t.Errorf("error %+v, want %s on spatial_order", env.Error, codeInvalidParameter)
+	}
+}
+
+// Источник: у manufactured из sin(πx) есть эталон, у gaussian — нет;
+// ошибка разбора и метод без источника — 400 на поле source
+func TestSimulateSource(t *testing.T) {
+	_, ts := newTestServer(t, nil)
+	const query = "/api/v1/simulate?dx=0.05&dt=0.001&tmax=0.5&storage=final-only"
+	tests := []struct {
+		params string
+		linf   float64 // 0 — эталона нет
+	}{
+		{"&method=CN&source=manufactured", 3e-3},
+		{"&method=BTCS&source=sine", 5e-3},
+		{"&method=CN&source=gaussian", 0},
+		{"&method=FTCS&source=" + url.QueryEscape("exp(-t)*x*(1-x)"), 0},
+	}
+	for _, tc := range tests {
+		t.Run(tc.params, func(t *testing.T) {
+			_, body := get(t, ts, query+tc.params, 200)
+			var res simulateResult
+			decode(t, body, &res)
+			if res.Diverged {
+				t.Fatal("diverged")
+			}
+			switch {
+			case tc.linf == 0 && res.Norms != nil:
+				t.Errorf("norms %+v without a reference", *res.Norms)
+			case tc.linf > 0 && (res.Norms == nil || res.Norms.LInf > tc.linf):
+				t.Errorf("norms %v, want L∞ ≤ %g", res.Norms, tc.linf)
+			}
+		})
+	}
+
+	for _, params := range []string{"&method=CN&source=" + url.QueryEscape("sin(x"), "&method=DF&source=sine"} {
+		_, body := get(t, ts, query+params, 400)
+		var env errorEnvelope
+		decode(t, body, &env)
+		if env.Error.Code != codeInvalidParameter || env.Error.Field != "source" {
+			t.Errorf("%s: error %+v, want %s on source", params, env.Error, codeInvalidParameter)
+		}
+	}
+}
+
+// Пучок source=laser: неподвижный пучок греет у своего центра, без
+// source=laser параметры пучка не принимаются, ширина должна быть
+// положительной
+func TestSimulateLaser(t *testing.T) {
+	_, ts := newTestServer(t, nil)
+	const query = "/api/v1/simulate?method=CN&dx=0.02&dt=0.001&tmax=0.2&storage=final-only"
+	_, body := get(t, ts, query+"&source=laser&laser_speed=0&laser_start=0.3&laser_power=10", 200)
+	var res simulateResult
+	decode(t, body, &res)
+	final := res.U[len(res.U)-1]
+	hottest := 0
+	for i, v := range final {
+		if v > final[hottest] {
+			hottest = i
+		}
+	}
+	if res.Norms != nil || math.Abs(float64(hottest)*res.Dx-0.3) > 0.05 {
+		t.Errorf("hottest node at x = %v, norms %v; want near the beam at 0.3 without a reference", float64(hottest)*res.Dx, res.Norms)
+	}
+
+	tests := []struct {
+		params string
+		field  string
+	}{
+		{"&laser_power=10", "source"},
+		{"&source=sine&laser_speed=1", "source"},
+		{"&source=laser&laser_width=0", "laser_width"},
+		{"&source=laser&laser_width=-0.1", "laser_width"},
+	}
+	for _, tc := range tests {
+		_, body := get(t, ts, query+tc.params, 400)
+		var env errorEnvelope
+		decode(t, body, &env)
+		if env.Error.Code != codeInvalidParameter || env.Error.Field != tc.field {
+			t.Errorf("%s: error %+v, want %s on %s", tc.params, env.Error, codeInvalidParameter, tc.field)
+		}
+	}
+}
@@ -0,0 +1,243 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"heat-solver/internal/heatpb"
+)
+
+var (
+	errUnauthorized = errors.New("unauthorized")
+	errForbidden    = errors.New("forbidden")
+)
+
+// Владелец ключа, прошедшего проверку. Передаётся в контексте запроса,
+// чтобы к нему можно было привязать лимиты
+type principal struct {
+	Name string
+}
+
+func principalFrom(ctx context.Context) (principal, bool) {
+	p, ok := ctx.Value(principalKey).(principal)
+	return p, ok
+}
+
+type apiKey struct {
+	Name    string
+	Hash    [sha256.Size]byte
+	Revoked bool
+}
+
+// Набор ключей; хранятся только SHA-256 хэши. Пустой набор — авторизация выключена
+type keyStore struct {
+	keys []apiKey
+}
+
+func hashKey(key string) [sha256.Size]byte {
+	return sha256.Sum256([]byte(key))
+}
+
+// Строка вида name:sha256hex[:revoked]
+func parseKeyEntry(line string) (apiKey, error) {
+	parts := strings.Split(line, ":")
+	if len(parts) < 2 || len(parts) > 3 || parts[0] == "" {
+		return apiKey{}, fmt.Errorf("want name:sha256hex[:revoked], got %q", line)
+	}
+	k := apiKey{Name: parts[0]}
+	h, err := hex.DecodeString(parts[1])
+	if err != nil || len(h) != sha256.Size {
+		return apiKey{}, fmt.Errorf("key %q: hash must be 64 hex digits", k.Name)
+	}
+	copy(k.Hash[:], h)
+	if len(parts) == 3 {
+		if parts[2] != "revoked" {
+			return apiKey{}, fmt.Errorf("key %q: unknown flag %q", k.Name, parts[2])
+		}
+		k.Revoked = true
+	}
+	return k, nil
+}
+
+// Ключи из файла (по одному на строку, # — комментарий) и записи из
+// конфигурации (api_keys или HEAT_API_KEYS)
+func loadKeyStore(path string, entries []string) (*keyStore, error) {
+	ks := &keyStore{}
+	add := func(line string) error {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			return nil
+		}
+		k, err := parseKeyEntry(line)
+		if err != nil {
+			return err
+		}
+		ks.keys = append(ks.keys, k)
+		return nil
+	}
+
+	if path != "" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		sc := bufio.NewScanner(f)
+		for n := 1; sc.Scan(); n++ {
+			if err := add(sc.Text()); err != nil {
+				return nil, fmt.Errorf("%s:%d: %w", path, n, err)
+			}
+		}
+		if err := sc.Err(); err != nil {
+			return nil, err
+		}
+	}
+	for _, entry := range entries {
+		if err := add(entry); err != nil {
+			return nil, fmt.Errorf("api_keys: %w", err)
+		}
+	}
+	return ks, nil
+}
+
+func (ks *keyStore) enabled() bool {
+	return ks != nil && len(ks.keys) > 0
+}
+
+// Проверка предъявленного ключа. Сравниваются хэши со всеми ключами без
+// досрочного выхода, чтобы время ответа не зависело от совпадения
+func (ks *keyStore) authenticate(key string) (principal, error) {
+	if key == "" {
+		return principal{}, fmt.Errorf("%w: missing API key", errUnauthorized)
+	}
+	h := hashKey(key)
+	var match *apiKey
+	for i := range ks.keys {
+		if subtle.ConstantTimeCompare(h[:], ks.keys[i].Hash[:]) == 1 {
+			match = &ks.keys[i]
+		}
+	}
+	switch {
+	case match == nil:
+		return principal{}, fmt.Errorf("%w: invalid API key", errUnauthorized)
+	case match.Revoked:
+		return principal{}, fmt.Errorf("%w: API key %q has been revoked", errForbidden, match.Name)
+	}
+	return principal{Name: match.Name}, nil
+}
+
+// Ключ из Authorization: Bearer или X-API-Key
+func keyFromHeader(h http.Header) (string, error) {
+	if auth := h.Get("Authorization"); auth != "" {
+		scheme, token, ok := strings.Cut(auth, " ")
+		token = strings.TrimSpace(token)
+		if !ok || !strings.EqualFold(scheme, "Bearer") || token == "" {
+			return "", fmt.Errorf("%w: malformed Authorization header, want Bearer <key>", errUnauthorized)
+		}
+		return token, nil
+	}
+	return h.Get("X-API-Key"), nil
+}
+
+// Требует действующий ключ, если ключи настроены
+func (s *server) requireKey(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !s.keys.enabled() {
+			next(w, r)
+			return
+		}
+		key, err := keyFromHeader(r.Header)
+		if err == nil {
+			var p principal
+			if p, err = s.keys.authenticate(key); err == nil {
+				next(w, r.WithContext(context.WithValue(r.Context(), principalKey, p)))
+				return
+			}
+		}
+		slog.WarnContext(r.Context(), "Request rejected by API key check", "path", r.URL.Path, "reason", err)
+		if errors.Is(err, errUnauthorized) {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="heat-solver"`)
+		}
+		writeError(w, r, err)
+	}
+}
+
+// Та же проверка для gRPC: ключ в метаданных authorization или x-api-key
+func (s *server) grpcAuth(ctx context.Context) (context.Context, error) {
+	if !s.keys.enabled() {
+		return ctx, nil
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	h := http.Header{}
+	for _, name := range []string{"Authorization", "X-API-Key"} {
+		if v := md.Get(name); len(v) > 0 {
+			h.Set(name, v[0])
+		}
+	}
+	key, err := keyFromHeader(h)
+	if err == nil {
+		var p principal
+		if p, err = s.keys.authenticate(key); err == nil {
+			return context.WithValue(ctx, principalKey, p), nil
+		}
+	}
+	slog.WarnContext(ctx, "gRPC call rejected by API key check", "reason", err)
+	return nil, grpcError(err)
+}
+
+func (s *server) unaryAuth(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	// Список методов открыт, как и GET /methods
+	if info.FullMethod == heatpb.HeatSolver_ListMethods_FullMethodName {
+		return handler(ctx, req)
+	}
+	ctx, err := s.grpcAuth(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+type authedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (a authedStream) Context() context.Context {
+	return a.ctx
+}
+
+func (s *server) streamAuth(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := s.grpcAuth(ss.Context())
+	if err != nil {
+		return err
+	}
+	return handler(srv, authedStream{ServerStream: ss, ctx: ctx})
+}
+
+// Хэш для файла ключей: ключ читается со stdin, чтобы не попасть в историю shell
+func printKeyHash(in io.Reader, out io.Writer) error {
+	line, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return err
+	}
+	key := strings.TrimSpace(line)
+	if key == "" {
+		return errors.New("empty key on stdin")
+	}
+	h := hashKey(key)
+	_, err = fmt.Fprintln(out, hex.EncodeToString(h[:]))
+	return err
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRequireKey(t *testing.T) {
+	logs := captureLogs(t)
+	keys, err := loadKeyStore("", []string{keyEntry("ci", "good-key", false), keyEntry("old", "old-key", true)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &server{keys: keys}
+	var seen principal
+	h := s.requireKey(func(w http.ResponseWriter, r *http.Request) {
+		seen, _ = principalFrom(r.Context())
+	})
+
+	tests := []struct {
+		name      string
+		header    string
+		value     string
+		status    int
+		code      string
+		principal string
+	}{
+		{"bearer", "Authorization", "Bearer good-key", 200, "", "ci"},
+		{"bearer lowercase scheme", "Authorization", "bearer good-key", 200, "", "ci"},
+		{"x-api-key", "X-API-Key", "good-key", 200, "", "ci"},
+		{"missing", "", "", 401, codeUnauthorized, ""},
+		{"unknown key", "X-API-Key", "guess", 401, codeUnauthorized, ""},
+		{"malformed scheme", "Authorization", "Basic Z29vZC1rZXk=", 401, codeUnauthorized, ""},
+		{"malformed empty bearer", "Authorization", "Bearer ", 401, codeUnauthorized, ""},
+		{"malformed no scheme", "Authorization", "good-key", 401, codeUnauthorized, ""},
+		{"revoked", "X-API-Key", "old-key", 403, codeForbidden, ""},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			seen = principal{}
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/simulate", nil)
+			if tc.header != "" {
+				req.Header.Set(tc.header, tc.value)
+			}
+			w := httptest.NewRecorder()
+			h(w, req)
+			if w.Code != tc.status {
+				t.Fatalf("status %d, want %d; body %s", w.Code, tc.status, w.Body)
+			}
+			if seen.Name != tc.principal {
+				t.Errorf("principal %q, want %q", seen.Name, tc.principal)
+			}
+			if tc.status == 200 {
+				return
+			}
+			var env errorEnvelope
+			decode(t, w.Body.Bytes(), &env)
+			if env.Error.Code != tc.code {
+				t.Errorf("code %s, want %s", env.Error.Code, tc.code)
+			}
+			if got := w.Header().Get("WWW-Authenticate"); (tc.status == 401) != (got != "") {
+				t.Errorf("WWW-Authenticate = %q for status %d", got, tc.status)
+			}
+		})
+	}
+
+	// Сами ключи в журнал не попадают
+	logs.mu.Lock()
+	defer logs.mu.Unlock()
+	for _, secret := range []string{"good-key", "old-key", "guess", "Z29vZC1rZXk="} {
+		if bytes.Contains(logs.buf.Bytes(), []byte(secret)) {
+			t.Errorf("raw key %q logged", secret)
+		}
+	}
+}
+
+// Тяжёлые маршруты закрыты ключом, служебные и статика — нет
+func TestAuthRoutes(t *testing.T) {
+	_, ts := newTestServer(t, func(cfg *serverConfig) {
+		cfg.APIKeys = []string{keyEntry("ci", "good-key", false)}
+	})
+	for _, path := range []string{"/", "/healthz", "/docs", "/api/v1/methods", "/api/v1/openapi.json"} {
+		get(t, ts, path, 200)
+	}
+	for _, path := range []string{"/api/v1/simulate", "/api/v1/simulate.png", "/api/v1/analytical", "/api/v1/jobs/x", "/simulate"} {
+		get(t, ts, path, 401)
+	}
+	postJSON(t, ts, "/api/v1/jobs", `{}`, 401)
+
+	req, _ := http.NewRequest(http.MethodGet, ts.URL+"/api/v1/simulate?dx=0.1&tmax=0.01", nil)
+	req.Header.Set("X-API-Key", "good-key")
+	send(t, ts, req, 200)
+}
+
+func TestLoadKeyStore(t *testing.T) {
+	good := keyEntry("ci", "k", false)
+	dir := t.TempDir()
+	file := filepath.Join(dir, "keys")
+	if err := os.WriteFile(file, []byte("# keys\n\n"+good+"\n"+keyEntry("old", "o", true)+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		path    string
+		entries []string
+		keys    int
+		wantErr string
+	}{
+		{"none", "", nil, 0, ""},
+		{"file", file, nil, 2, ""},
+		{"file and entries", file, []string{keyEntry("env", "e", false)}, 3, ""},
+		{"missing file", filepath.Join(dir, "nope"), nil, 0, "no such file"},
+		{"no hash", "", []string{"ci"}, 0, "want name:sha256hex"},
+		{"short hash", "", []string{"ci:abcd"}, 0, "64 hex digits"},
+		{"unknown flag", "", []string{good + ":disabled"}, 0, "unknown flag"},
+		{"empty name", "", []string{":" + strings.Repeat("0", 64)}, 0, "want name:sha256hex"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ks, err := loadKeyStore(tc.path, tc.entries)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("error %v, want one containing %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(ks.keys) != tc.keys || ks.enabled() != (tc.keys > 0) {
+				t.Errorf("%d keys, enabled %v; want %d", len(ks.keys), ks.enabled(), tc.keys)
+			}
+		})
+	}
+}
+
+func TestPrintKeyHash(t *testing.T) {
+	var out bytes.Buffer
+	if err := printKeyHash(strings.NewReader("secret\n"), &out); err != nil {
+		t.Fatal(err)
+	}
+	want := strings.TrimPrefix(keyEntry("", "secret", false), ":") + "\n"
+	if out.String() != want {
+		t.Errorf("printKeyHash = %q, want %q", out.String(), want)
+	}
+	if err := printKeyHash(strings.NewReader("\n"), &out); err == nil {
+		t.Error("empty key accepted")
+	}
+}
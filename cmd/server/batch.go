@@ -0,0 +1,174 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"heat-solver/internal/config"
+	"heat-solver/internal/mathutils"
+	"heat-solver/internal/solver"
+)
+
+// Наибольшее число наборов параметров в одном пакете
+const maxBatchItems = 100
+
+// Итог расчёта одного элемента пакета
+const (
+	batchOK       = "ok"
+	batchDiverged = "diverged"
+	batchError    = "error"
+	batchSkipped  = "skipped"
+)
+
+// Необязательные поля элемента ответа (include_fields)
+const fieldFinalProfile = "final_profile"
+
+type batchItem struct {
+	Index        int            `json:"index"`
+	Status       string         `json:"status" doc:"ok, diverged, error (see error) or skipped (deadline or shutdown before the item started)"`
+	Method       string         `json:"method,omitempty"`
+	Nx           int            `json:"nx,omitempty"`
+	Nt           int            `json:"nt,omitempty"`
+	R            float64        `json:"r,omitempty"`
+	RuntimeSec   float64        `json:"runtime_sec,omitempty"`
+	Reference    string         `json:"reference,omitempty"`
+	Norms        *normsResponse `json:"norms,omitempty"`
+	FinalProfile jsonRow        `json:"final_profile,omitempty" doc:"Solution at tmax; only with include_fields=final_profile"`
+	Error        *errorBody     `json:"error,omitempty"`
+}
+
+type batchResponse struct {
+	Items []batchItem `json:"items" doc:"One entry per input item, in input order"`
+}
+
+// Поля из include_fields (через запятую)
+func parseIncludeFields(v string) (finalProfile bool, err error) {
+	for f := range strings.SplitSeq(v, ",") {
+		switch strings.TrimSpace(f) {
+		case "":
+		case fieldFinalProfile:
+			finalProfile = true
+		default:
+			return false, paramError("include_fields", "unknown field "+f+", want "+fieldFinalProfile)
+		}
+	}
+	return finalProfile, nil
+}
+
+func itemError(item *batchItem, err error) {
+	_, body := classifyError(err)
+	item.Status = batchError
+	item.Error = &body
+}
+
+// Пакет наборов параметров. Ошибка в одном наборе не отменяет остальные;
+// сумма ячеек всех корректных наборов ограничена тем же лимитом, что и
+// один запрос. Расчёты идут в общем пуле с фоновыми задачами, весь пакет —
+// в пределах дедлайна запроса; не начатые к дедлайну помечаются skipped
+func (s *server) handleBatch(w http.ResponseWriter, r *http.Request) {
+	finalProfile, err := parseIncludeFields(r.URL.Query().Get("include_fields"))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	var reqs []simulateRequest
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, 4<<20))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&reqs); err != nil {
+		writeError(w, r, paramError("body", "invalid JSON: "+err.Error()))
+		return
+	}
+	if len(reqs) == 0 {
+		writeError(w, r, paramError("body", "empty batch"))
+		return
+	}
+	if len(reqs) > maxBatchItems {
+		writeError(w, r, &config.LimitError{Field: "items", Limit: maxBatchItems, Value: int64(len(reqs))})
+		return
+	}
+
+	items := make([]batchItem, len(reqs))
+	params := make([]config.Params, len(reqs))
+	var cells int64
+	for i, req := range reqs {
+		items[i].Index = i
+		p, err := req.params()
+		if err == nil {
+			err = s.limits.Check(p)
+		}
+		if err == nil {
+			if _, ok := solver.Lookup(p.Method); !ok {
+				err = paramError("method", fmt.Sprintf("unknown method %q", p.Method))
+			}
+		}
+		if err != nil {
+			itemError(&items[i], err)
+			continue
+		}
+		params[i] = p
+		nx, nt := p.Grid()
+		cells += int64(nx+1) * int64(nt+1)
+	}
+	if s.limits.MaxCells > 0 && cells > s.limits.MaxCells {
+		writeError(w, r, &config.LimitError{Field: "cells", Limit: s.limits.MaxCells, Value: cells})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), s.timeout)
+	defer cancel()
+
+	// Места в пуле занимаются по порядку, чтобы при дедлайне пропускались
+	// последние элементы, а не случайные
+	var wg sync.WaitGroup
+	for i := range items {
+		if items[i].Status == batchError {
+			continue
+		}
+		release, err := s.jobs.acquire(ctx)
+		if err != nil || ctx.Err() != nil {
+			if release != nil {
+				release()
+			}
+			items[i].Status = batchSkipped
+			continue
+		}
+		wg.Go(func() {
+			defer release()
+			s.runBatchItem(ctx, &items[i], reqs[i], params[i], finalProfile)
+		})
+	}
+	wg.Wait()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(batchResponse{Items: items})
+}
+
+func (s *server) runBatchItem(ctx context.Context, item *batchItem, req simulateRequest, p config.Params, finalProfile bool) {
+	// Stream прерывается по дедлайну и не держит в памяти всю сетку
+	res, err := solver.Stream(ctx, p, s.metrics.solverHooks(), func(int, []float64) error { return nil })
+	if err != nil {
+		itemError(item, err)
+		return
+	}
+
+	item.Status = batchOK
+	if res.Diverged {
+		item.Status = batchDiverged
+	}
+	item.Method = res.Method
+	item.Nx, item.Nt = res.Nx, res.Nt
+	item.R = res.Dt / (res.Dx * res.Dx)
+	item.RuntimeSec = res.Runtime.Seconds()
+	var exact mathutils.Reference
+	if item.Reference, exact = req.reference(p); exact != nil {
+		item.Norms = finalNorms(res, exact)
+	}
+	if finalProfile {
+		item.FinalProfile = res.Final
+	}
+}
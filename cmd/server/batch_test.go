@@ -0,0 +1,101 @@
+package main
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+type batchResult struct {
+	Items []struct {
+		Index        int        `json:"index"`
+		Status       string     `json:"status"`
+		Method       string     `json:"method"`
+		R            float64    `json:"r"`
+		Norms        *struct{}  `json:"norms"`
+		FinalProfile []*float64 `json:"final_profile"`
+		Error        *errorBody `json:"error"`
+	} `json:"items"`
+}
+
+// Корректный, некорректный и неустойчивый наборы: три разных статуса
+// в порядке входа
+func TestBatch(t *testing.T) {
+	_, ts := newTestServer(t, nil)
+	_, body := postJSON(t, ts, "/api/v1/batch?include_fields=final_profile", `[
+		{"method":"CN","dx":0.1,"dt":0.01,"tmax":0.1},
+		{"method":"NOPE"},
+		{"method":"FTCS","dx":0.05,"dt":0.01,"tmax":10}
+	]`, 200)
+	var res batchResult
+	decode(t, body, &res)
+	if len(res.Items) != 3 {
+		t.Fatalf("%d items, want 3", len(res.Items))
+	}
+	ok, invalid, unstable := res.Items[0], res.Items[1], res.Items[2]
+	if ok.Index != 0 || ok.Status != batchOK || ok.Method != "CN" || ok.Norms == nil || len(ok.FinalProfile) != 11 || ok.Error != nil {
+		t.Errorf("item 0 %+v, want ok with norms and an 11-node final profile", ok)
+	}
+	if invalid.Index != 1 || invalid.Status != batchError || invalid.Error == nil || invalid.Error.Code != codeInvalidParameter || invalid.Error.Field != "method" {
+		t.Errorf("item 1 %+v, want an invalid_parameter error on method", invalid)
+	}
+	if unstable.Index != 2 || unstable.Status != batchDiverged || math.Abs(unstable.R-4) > 1e-9 {
+		t.Errorf("item 2 %+v, want diverged at r = 4", unstable)
+	}
+
+	// Без include_fields профиля нет
+	_, body = postJSON(t, ts, "/api/v1/batch", `[{"dx":0.1,"tmax":0.01}]`, 200)
+	var plain batchResult
+	decode(t, body, &plain)
+	if plain.Items[0].FinalProfile != nil {
+		t.Error("final_profile returned without include_fields")
+	}
+}
+
+func TestBatchLimits(t *testing.T) {
+	_, ts := newTestServer(t, func(cfg *serverConfig) {
+		cfg.Limits.MaxCells = 3000
+	})
+	tests := []struct {
+		name, query, body string
+		status            int
+		code              string
+	}{
+		{"empty", "", `[]`, 400, codeInvalidParameter},
+		{"not an array", "", `{"dx":0.1}`, 400, codeInvalidParameter},
+		{"unknown field", "include_fields=u", `[{}]`, 400, codeInvalidParameter},
+		{"combined cells", "", `[{"dx":0.1,"dt":0.001,"tmax":0.2},{"dx":0.1,"dt":0.001,"tmax":0.2}]`, 413, codeLimitExceeded},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, body := postJSON(t, ts, "/api/v1/batch?"+tc.query, tc.body, tc.status)
+			var env errorEnvelope
+			decode(t, body, &env)
+			if env.Error.Code != tc.code {
+				t.Errorf("code %s, want %s", env.Error.Code, tc.code)
+			}
+		})
+	}
+}
+
+// Дедлайн посреди пакета: текущий элемент прерывается с timeout,
+// не начатые помечаются skipped
+func TestBatchDeadline(t *testing.T) {
+	_, ts := newTestServer(t, func(cfg *serverConfig) {
+		cfg.Limits.MaxCells = 0
+		cfg.Jobs.Workers = 1
+		cfg.RequestTimeout = duration(100 * time.Millisecond)
+	})
+	_, body := postJSON(t, ts, "/api/v1/batch", `[
+		{"method":"CN","dx":0.001,"dt":0.00001,"tmax":1},
+		{"dx":0.1,"tmax":0.01}
+	]`, 200)
+	var res batchResult
+	decode(t, body, &res)
+	if first := res.Items[0]; first.Status != batchError || first.Error == nil || first.Error.Code != codeTimeout {
+		t.Errorf("item 0 %+v, want a timeout error", first)
+	}
+	if res.Items[1].Status != batchSkipped {
+		t.Errorf("item 1 status %s, want skipped", res.Items[1].Status)
+	}
+}
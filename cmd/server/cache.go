@@ -0,0 +1,124 @@
+package main
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"sync"
+	"time"
+
+	"heat-solver/internal/config"
+)
+
+type cacheEntry struct {
+	key     string
+	value   any
+	size    int64
+	expires time.Time
+}
+
+// Значение с подсчётом ссылок (*solver.Result на буферах из пула): кэш
+// держит свою ссылку, пока значение в нём, и выдаёт из Get новую
+type refCounted interface {
+	Retain()
+	Release()
+}
+
+// LRU-кэш готовых результатов (решений и закодированных изображений),
+// ключ — канонические параметры запуска
+type resultCache struct {
+	mu          sync.Mutex
+	maxEntries  int
+	maxBytes    int64
+	ttl         time.Duration
+	cacheFailed bool
+
+	ll    *list.List
+	items map[string]*list.Element
+	bytes int64
+}
+
+func newResultCache(maxEntries int, maxBytes int64, ttl time.Duration, cacheFailed bool) *resultCache {
+	return &resultCache{
+		maxEntries:  maxEntries,
+		maxBytes:    maxBytes,
+		ttl:         ttl,
+		cacheFailed: cacheFailed,
+		ll:          list.New(),
+		items:       make(map[string]*list.Element),
+	}
+}
+
+// Ключ кэша: все поля нормализованных параметров, кроме имени выходного файла.
+// Выборка начального профиля входит в ключ хэшем
+func cacheKey(p config.Params) string {
+	p.Outfile = ""
+	if p.ICSamples == nil {
+		return fmt.Sprintf("%+v", p)
+	}
+	h := sha256.New()
+	for _, s := range p.ICSamples {
+		binary.Write(h, binary.LittleEndian, [2]float64{s.X, s.U})
+	}
+	p.ICSamples = nil
+	return fmt.Sprintf("%+v|ic=%x", p, h.Sum(nil))
+}
+
+// Значение по ключу. Для refCounted вызывающий получает ссылку и
+// освобождает её через Release
+func (c *resultCache) Get(key string) (any, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	e := el.Value.(*cacheEntry)
+	if c.ttl > 0 && time.Now().After(e.expires) {
+		c.remove(el)
+		return nil, false
+	}
+	c.ll.MoveToFront(el)
+	if rc, ok := e.value.(refCounted); ok {
+		rc.Retain()
+	}
+	return e.value, true
+}
+
+// failed — значение получено из разошедшегося расчёта
+func (c *resultCache) Add(key string, value any, size int64, failed bool) {
+	if c.maxEntries <= 0 || (failed && !c.cacheFailed) {
+		return
+	}
+	if c.maxBytes > 0 && size > c.maxBytes {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		c.remove(el)
+	}
+	if rc, ok := value.(refCounted); ok {
+		rc.Retain()
+	}
+	e := &cacheEntry{key: key, value: value, size: size, expires: time.Now().Add(c.ttl)}
+	c.items[key] = c.ll.PushFront(e)
+	c.bytes += size
+
+	for c.ll.Len() > c.maxEntries || (c.maxBytes > 0 && c.bytes > c.maxBytes) {
+		c.remove(c.ll.Back())
+	}
+}
+
+func (c *resultCache) remove(el *list.Element) {
+	e := c.ll.Remove(el).(*cacheEntry)
+	delete(c.items, e.key)
+	c.bytes -= e.size
+	if rc, ok := e.value.(refCounted); ok {
+		rc.Release()
+	}
+}
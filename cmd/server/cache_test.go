@@ -0,0 +1,69 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// Повторный запрос с теми же параметрами — попадание в кэш и заметно
+// быстрее расчёта; прореживание берётся из того же результата, а другой
+// шаг (другое r = α·dt/dx²) — промах
+func TestSimulateCache(t *testing.T) {
+	_, ts := newTestServer(t, nil)
+	const base = "/api/v1/simulate?method=CN&dx=0.002&dt=0.00001&tmax=0.2&stride=1000"
+
+	timed := func(path string) (string, time.Duration) {
+		start := time.Now()
+		resp, _ := get(t, ts, path, 200)
+		return resp.Header.Get("X-Cache"), time.Since(start)
+	}
+
+	cache, miss := timed(base)
+	if cache != "MISS" {
+		t.Fatalf("first request: X-Cache = %q, want MISS", cache)
+	}
+	cache, hit := timed(base)
+	if cache != "HIT" {
+		t.Fatalf("repeated request: X-Cache = %q, want HIT", cache)
+	}
+	if hit >= miss/2 {
+		t.Errorf("hit took %v, miss %v: want the hit markedly faster", hit, miss)
+	}
+
+	tests := []struct {
+		name, path, want string
+	}{
+		{"other stride", "/api/v1/simulate?method=CN&dx=0.002&dt=0.00001&tmax=0.2&stride=500", "HIT"},
+		{"lowercase method", "/api/v1/simulate?method=cn&dx=0.002&dt=0.00001&tmax=0.2&stride=1000", "HIT"},
+		{"other dt", "/api/v1/simulate?method=CN&dx=0.002&dt=0.00002&tmax=0.2&stride=1000", "MISS"},
+		{"other method", "/api/v1/simulate?method=BTCS&dx=0.002&dt=0.00001&tmax=0.2&stride=1000", "MISS"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got, _ := timed(tc.path); got != tc.want {
+				t.Errorf("X-Cache = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+// Разные α в /evaluate дают разное время расчёта τ = α·t, поэтому и разные
+// ключи кэша
+func TestEvaluateCacheAlpha(t *testing.T) {
+	_, ts := newTestServer(t, nil)
+	body := func(alpha string) string {
+		return `{"method":"CN","dx":0.01,"dt":0.0001,"alpha":` + alpha +
+			`,"measurements":[{"x":0.5,"t":0.05,"u":0.5}]}`
+	}
+	for _, step := range []struct{ alpha, want string }{
+		{"1", "MISS"},
+		{"1", "HIT"},
+		{"0.5", "MISS"},
+		{"0.5", "HIT"},
+	} {
+		resp, _ := postJSON(t, ts, "/api/v1/evaluate", body(step.alpha), 200)
+		if got := resp.Header.Get("X-Cache"); got != step.want {
+			t.Errorf("alpha=%s: X-Cache = %q, want %q", step.alpha, got, step.want)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"heat-solver/internal/config"
+	"heat-solver/internal/mathutils"
+	"heat-solver/internal/solver"
+)
+
+// Одна задача, несколько методов
+type compareRequest struct {
+	Methods []string `json:"methods" doc:"Schemes to compare; all registered ones when empty"`
+	Dx      float64  `json:"dx"`
+	Dt      float64  `json:"dt"`
+	Tmax    float64  `json:"tmax"`
+	Stride  int      `json:"stride" doc:"Return every stride-th grid node of the profiles; the last node is always included"`
+}
+
+type compareStability struct {
+	Type      string `json:"type"`
+	Bound     string `json:"bound,omitempty"`
+	Satisfied bool   `json:"satisfied"`
+}
+
+type compareResult struct {
+	Method     string           `json:"method"`
+	Profile    []jsonFloat      `json:"profile" doc:"Solution at tmax on the returned nodes; NaN/Inf as null"`
+	Norms      normsResponse    `json:"norms"`
+	RuntimeSec float64          `json:"runtime_sec"`
+	Stability  compareStability `json:"stability"`
+	Diverged   bool             `json:"diverged"`
+}
+
+type compareResponse struct {
+	Nx      int             `json:"nx"`
+	Nt      int             `json:"nt"`
+	Dx      float64         `json:"dx"`
+	Dt      float64         `json:"dt"`
+	Tmax    float64         `json:"tmax"`
+	R       float64         `json:"r"`
+	Stride  int             `json:"stride"`
+	X       []float64       `json:"x"`
+	Exact   []float64       `json:"exact" doc:"Analytical solution at tmax on the returned nodes"`
+	Results []compareResult `json:"results"`
+}
+
+// Параметры для каждого метода; псевдонимы одного метода схлопываются
+func (req compareRequest) params() ([]config.Params, error) {
+	names := req.Methods
+	if len(names) == 0 {
+		names = solver.Methods()
+	}
+	if req.Stride < 0 {
+		return nil, paramError("stride", "must be a positive integer")
+	}
+
+	var out []config.Params
+	seen := map[string]bool{}
+	for _, name := range names {
+		if name == "" {
+			return nil, paramError("methods", "empty method name")
+		}
+		p, err := simulateRequest{Method: name, Dx: req.Dx, Dt: req.Dt, Tmax: req.Tmax}.params()
+		if err != nil {
+			return nil, err
+		}
+		if !seen[p.Method] {
+			seen[p.Method] = true
+			out = append(out, p)
+		}
+	}
+	return out, nil
+}
+
+func (s *server) handleCompare(w http.ResponseWriter, r *http.Request) {
+	var req compareRequest
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		writeError(w, r, paramError("body", "invalid JSON: "+err.Error()))
+		return
+	}
+	all, err := req.params()
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	// Все методы считаются на одной сетке, поэтому лимит ячеек — на сумму
+	for _, p := range all {
+		if err := s.limits.Check(p); err != nil {
+			writeError(w, r, err)
+			return
+		}
+	}
+	nx, nt := all[0].Grid()
+	cells := int64(len(all)) * int64(nx+1) * int64(nt+1)
+	if s.limits.MaxCells > 0 && cells > s.limits.MaxCells {
+		writeError(w, r, &config.LimitError{Field: "cells", Limit: s.limits.MaxCells, Value: cells})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), s.timeout)
+	defer cancel()
+
+	stride := max(1, req.Stride)
+	dx, dt := all[0].Dx, all[0].Dt
+	tmax := float64(nt) * dt
+	ratio := dt / (dx * dx)
+	// Прореживание узлов устроено так же, как прореживание слоёв по времени
+	nodes := levelIndices(nx, stride)
+
+	resp := compareResponse{
+		Nx:     nx,
+		Nt:     nt,
+		Dx:     dx,
+		Dt:     dt,
+		Tmax:   tmax,
+		R:      ratio,
+		Stride: stride,
+	}
+	for _, i := range nodes {
+		x := float64(i) * dx
+		resp.X = append(resp.X, x)
+		resp.Exact = append(resp.Exact, mathutils.AnalyticalSolution(x, tmax))
+	}
+
+	// Методы считаются по очереди; расходимость одного не прерывает остальные
+	for _, p := range all {
+		res, _, err := s.solve(ctx, p)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		m, _ := solver.Lookup(res.Method)
+		final := res.Final
+		l2, linf := mathutils.ComputeErrors(res.Rows(), res.Dx, res.Dt)
+
+		item := compareResult{
+			Method:     res.Method,
+			Norms:      normsResponse{L2: jsonFloat(l2), Linf: jsonFloat(linf)},
+			RuntimeSec: res.Runtime.Seconds(),
+			Stability:  compareStability{Type: "unconditional", Satisfied: true},
+			Diverged:   res.Diverged,
+		}
+		if !m.Unconditional() {
+			item.Stability = compareStability{Type: "conditional", Bound: m.StabilityBound, Satisfied: m.StableAt(ratio)}
+		}
+		for _, i := range nodes {
+			item.Profile = append(item.Profile, jsonFloat(final[i]))
+		}
+		res.Release()
+		resp.Results = append(resp.Results, item)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+// При r > 0.5 FTCS расходится, CN — нет; в ответе есть оба метода
+func TestCompare(t *testing.T) {
+	_, ts := newTestServer(t, nil)
+	_, body := postJSON(t, ts, "/api/v1/compare", `{"methods":["FTCS","crank-nicolson","CN"],"dx":0.05,"dt":0.01,"tmax":10,"stride":4}`, 200)
+	var res struct {
+		Nx      int       `json:"nx"`
+		R       float64   `json:"r"`
+		X       []float64 `json:"x"`
+		Exact   []float64 `json:"exact"`
+		Results []struct {
+			Method    string     `json:"method"`
+			Profile   []*float64 `json:"profile"`
+			Diverged  bool       `json:"diverged"`
+			Stability struct {
+				Type      string `json:"type"`
+				Satisfied bool   `json:"satisfied"`
+			} `json:"stability"`
+		} `json:"results"`
+	}
+	decode(t, body, &res)
+
+	if res.Nx != 20 || math.Abs(res.R-4) > 1e-9 {
+		t.Errorf("nx %d, r %g; want 20 and 4", res.Nx, res.R)
+	}
+	if !slices.Equal(res.X, []float64{0, 0.2, 0.4, 0.6000000000000001, 0.8, 1}) || len(res.Exact) != len(res.X) {
+		t.Errorf("x = %v with %d exact values, want every 4th node and the last one", res.X, len(res.Exact))
+	}
+	// Псевдонимы одного метода схлопываются в одну запись
+	if len(res.Results) != 2 {
+		t.Fatalf("%d results, want FTCS and CN", len(res.Results))
+	}
+
+	ftcs, cn := res.Results[0], res.Results[1]
+	if ftcs.Method != "FTCS" || !ftcs.Diverged || ftcs.Stability.Type != "conditional" || ftcs.Stability.Satisfied {
+		t.Errorf("FTCS entry %+v, want a diverged run with an unsatisfied stability bound", ftcs)
+	}
+	if cn.Method != "CN" || cn.Diverged || cn.Stability.Type != "unconditional" || !cn.Stability.Satisfied {
+		t.Errorf("CN entry %+v, want a stable run", cn)
+	}
+	if len(cn.Profile) != len(res.X) {
+		t.Fatalf("CN profile has %d values, want %d", len(cn.Profile), len(res.X))
+	}
+	for i, v := range cn.Profile {
+		if v == nil || math.Abs(*v-res.Exact[i]) > 1e-3 {
+			t.Errorf("CN at x=%g: %v, exact %g", res.X[i], v, res.Exact[i])
+		}
+	}
+}
+
+func TestCompareLimits(t *testing.T) {
+	_, ts := newTestServer(t, func(cfg *serverConfig) {
+		cfg.Limits.MaxCells = 3000
+	})
+	// Один метод укладывается в лимит, сумма двух — нет
+	postJSON(t, ts, "/api/v1/compare", `{"methods":["CN"],"dx":0.1,"dt":0.001,"tmax":0.2}`, 200)
+	postJSON(t, ts, "/api/v1/compare", `{"methods":["CN","BTCS"],"dx":0.1,"dt":0.001,"tmax":0.2}`, 413)
+	postJSON(t, ts, "/api/v1/compare", `{"methods":["NOPE"]}`, 400)
+}
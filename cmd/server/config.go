@@ -0,0 +1,299 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"heat-solver/internal/config"
+	"heat-solver/internal/solver"
+)
+
+// Конфигурация сервера. Источники по возрастанию приоритета: значения по
+// умолчанию, JSON-файл (-config или HEAT_CONFIG), переменные окружения
+// HEAT_<ФЛАГ> (например HEAT_MAX_NX для -max-nx), явно заданные флаги
+type serverConfig struct {
+	listenConfig
+	GRPCAddr        string        `json:"grpc_addr"`
+	Limits          config.Limits `json:"limits"`
+	RequestTimeout  duration      `json:"request_timeout"`
+	DrainDelay      duration      `json:"drain_delay"`
+	ShutdownTimeout duration      `json:"shutdown_timeout"`
+	Cache           cacheConfig   `json:"cache"`
+	Jobs            jobConfig     `json:"jobs"`
+	DataDir         string        `json:"data_dir"`
+	APIKeysFile     string        `json:"api_keys_file"`
+	// Записи name:sha256hex[:revoked] в дополнение к файлу; из окружения — HEAT_API_KEYS
+	APIKeys     []string `json:"api_keys"`
+	CORSOrigins []string `json:"cors_origins"`
+	WebDir      string   `json:"web_dir"`
+	// Потоков на один расчёт, 0 — GOMAXPROCS
+	SolverThreads int `json:"solver_threads"`
+	// Прогонка в BTCS и CN: thomas или parallel
+	Tridiagonal string `json:"tridiagonal"`
+	// Проверка невязки прогонки на каждом шаге: off, warn или strict
+	VerifyResidual string `json:"verify_residual"`
+}
+
+type cacheConfig struct {
+	Entries int      `json:"entries"`
+	Bytes   int64    `json:"bytes"`
+	TTL     duration `json:"ttl"`
+	Failed  bool     `json:"failed"`
+}
+
+// Флаги режима запуска, не входящие в конфигурацию
+type serverFlags struct {
+	ConfigFile  string
+	PrintConfig bool
+	HashKey     bool
+}
+
+func defaultServerConfig() serverConfig {
+	return serverConfig{
+		listenConfig:    listenConfig{Port: 8080},
+		Limits:          config.Limits{MaxNx: 10000, MaxNt: 1000000, MaxCells: 20000000},
+		RequestTimeout:  duration(time.Minute),
+		ShutdownTimeout: duration(30 * time.Second),
+		Cache:           cacheConfig{Entries: 64, Bytes: 256 << 20, TTL: duration(10 * time.Minute)},
+		Jobs: jobConfig{
+			Workers:  2,
+			Timeout:  duration(10 * time.Minute),
+			MaxAge:   duration(24 * time.Hour),
+			MaxBytes: 1 << 30,
+		},
+		Tridiagonal:    solver.TridiagThomas,
+		VerifyResidual: solver.ResidualOff,
+	}
+}
+
+func (c *serverConfig) bindFlags(fs *flag.FlagSet) {
+	fs.StringVar(&c.Addr, "addr", c.Addr, "Listen host or host:port")
+	fs.IntVar(&c.Port, "port", c.Port, "Listen port, 0 picks a free one")
+	fs.StringVar(&c.TLSCert, "tls-cert", c.TLSCert, "TLS certificate file")
+	fs.StringVar(&c.TLSKey, "tls-key", c.TLSKey, "TLS private key file")
+	fs.StringVar(&c.GRPCAddr, "grpc-addr", c.GRPCAddr, "gRPC listen address, empty disables gRPC")
+	fs.IntVar(&c.Cache.Entries, "cache-entries", c.Cache.Entries, "Max cached results (0 disables the cache)")
+	fs.Int64Var(&c.Cache.Bytes, "cache-bytes", c.Cache.Bytes, "Max total size of cached results in bytes")
+	fs.Var(&c.Cache.TTL, "cache-ttl", "Lifetime of a cached result")
+	fs.BoolVar(&c.Cache.Failed, "cache-failed", c.Cache.Failed, "Also cache diverged runs")
+	fs.IntVar(&c.Limits.MaxNx, "max-nx", c.Limits.MaxNx, "Max spatial intervals per request (0: no limit)")
+	fs.IntVar(&c.Limits.MaxNt, "max-nt", c.Limits.MaxNt, "Max time steps per request (0: no limit)")
+	fs.Int64Var(&c.Limits.MaxCells, "max-cells", c.Limits.MaxCells, "Max stored grid cells (nx+1)(nt+1) per request (0: no limit)")
+	fs.Var(&c.RequestTimeout, "request-timeout", "Deadline for producing a single response")
+	fs.Var(&c.DrainDelay, "drain-delay", "Time to report not-ready before closing the listener on shutdown")
+	fs.Var(&c.ShutdownTimeout, "shutdown-timeout", "Max time to finish in-flight requests on shutdown")
+	fs.StringVar(&c.APIKeysFile, "api-keys", c.APIKeysFile, "File with API key hashes name:sha256hex[:revoked]; no keys disables auth (env HEAT_API_KEYS_FILE)")
+	fs.StringVar(&c.DataDir, "data-dir", c.DataDir, "Directory for job results; empty keeps jobs in memory")
+	fs.IntVar(&c.Jobs.Workers, "job-workers", c.Jobs.Workers, "Jobs and batch items solved concurrently")
+	fs.Var(&c.Jobs.Timeout, "job-timeout", "Deadline for a single job")
+	fs.Var(&c.Jobs.MaxAge, "job-max-age", "Remove finished jobs older than this (0 keeps them)")
+	fs.Int64Var(&c.Jobs.MaxBytes, "job-max-bytes", c.Jobs.MaxBytes, "Remove the oldest finished jobs while results exceed this size (0 disables)")
+	fs.Var((*stringList)(&c.CORSOrigins), "cors-origins", "Comma-separated origins allowed to call the API from a browser, * for any")
+	fs.StringVar(&c.WebDir, "web-dir", c.WebDir, "Serve the web UI from this directory instead of the embedded copy")
+	fs.IntVar(&c.SolverThreads, "solver-threads", c.SolverThreads, "Threads for a single large FTCS or parallel tridiagonal solve (0: GOMAXPROCS)")
+	fs.StringVar(&c.Tridiagonal, "tridiagonal", c.Tridiagonal, "Tridiagonal solver for BTCS and CN: thomas or parallel (blocks solved concurrently, for very large nx)")
+	fs.StringVar(&c.VerifyResidual, "verify-residual", c.VerifyResidual, "Check the residual of every BTCS and CN step: off, warn (log) or strict (fail the solve); one extra pass per step")
+}
+
+func (f *serverFlags) bind(fs *flag.FlagSet) {
+	fs.StringVar(&f.ConfigFile, "config", f.ConfigFile, "JSON configuration file (env HEAT_CONFIG)")
+	fs.BoolVar(&f.PrintConfig, "print-config", false, "Print the effective configuration with secrets redacted and exit")
+	fs.BoolVar(&f.HashKey, "hash-key", false, "Read an API key from stdin, print its hash for the keys file and exit")
+}
+
+// Переменные окружения, имена которых не выводятся из имени флага
+var envAliases = map[string]string{
+	"api-keys": "HEAT_API_KEYS_FILE",
+}
+
+func envName(flagName string) string {
+	if name, ok := envAliases[flagName]; ok {
+		return name
+	}
+	return "HEAT_" + strings.ToUpper(strings.ReplaceAll(flagName, "-", "_"))
+}
+
+// Сборка конфигурации из всех источников. Файл ищется первым проходом по
+// аргументам, так как от него зависят значения по умолчанию для флагов
+func loadServerConfig(name string, args []string, getenv func(string) string) (serverConfig, serverFlags, error) {
+	opts := serverFlags{ConfigFile: getenv("HEAT_CONFIG")}
+	pre := flag.NewFlagSet(name, flag.ContinueOnError)
+	pre.SetOutput(io.Discard)
+	scratch := defaultServerConfig()
+	scratch.bindFlags(pre)
+	opts.bind(pre)
+	pre.Parse(args) // ошибки разбора сообщит второй проход
+
+	cfg := defaultServerConfig()
+	if opts.ConfigFile != "" {
+		if err := cfg.loadFile(opts.ConfigFile); err != nil {
+			return cfg, opts, err
+		}
+	}
+
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	cfg.bindFlags(fs)
+	var errs []error
+	fs.VisitAll(func(f *flag.Flag) {
+		if v := getenv(envName(f.Name)); v != "" {
+			if err := f.Value.Set(v); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", envName(f.Name), err))
+			}
+		}
+	})
+	if v := getenv("HEAT_API_KEYS"); v != "" {
+		cfg.APIKeys = splitList(v)
+	}
+	if err := errors.Join(errs...); err != nil {
+		return cfg, opts, err
+	}
+	opts.bind(fs)
+	if err := fs.Parse(args); err != nil {
+		return cfg, opts, err
+	}
+	return cfg, opts, nil
+}
+
+func (c *serverConfig) loadFile(path string) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(c); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+// Проверка при старте; сообщения называют поле так же, как в JSON-файле
+func (c serverConfig) validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+	check(c.Port >= 0 && c.Port <= 65535, "port must be in [0, 65535]")
+	check((c.TLSCert == "") == (c.TLSKey == ""), "tls_cert and tls_key must be set together")
+	check(c.Limits.MaxNx >= 0, "limits.max_nx must not be negative (0 disables the limit)")
+	check(c.Limits.MaxNt >= 0, "limits.max_nt must not be negative (0 disables the limit)")
+	check(c.Limits.MaxCells >= 0, "limits.max_cells must not be negative (0 disables the limit)")
+	check(c.RequestTimeout > 0, "request_timeout must be positive")
+	check(c.DrainDelay >= 0, "drain_delay must not be negative")
+	check(c.ShutdownTimeout > 0, "shutdown_timeout must be positive")
+	check(c.Cache.Entries >= 0, "cache.entries must not be negative")
+	check(c.Cache.Bytes >= 0, "cache.bytes must not be negative")
+	check(c.Cache.TTL >= 0, "cache.ttl must not be negative")
+	check(c.Jobs.Workers > 0, "jobs.workers must be positive")
+	check(c.Jobs.Timeout > 0, "jobs.timeout must be positive")
+	check(c.Jobs.MaxAge >= 0, "jobs.max_age must not be negative (0 keeps jobs)")
+	check(c.Jobs.MaxBytes >= 0, "jobs.max_bytes must not be negative (0 disables the size limit)")
+	check(c.SolverThreads >= 0, "solver_threads must not be negative (0 uses GOMAXPROCS)")
+	check(c.Tridiagonal == solver.TridiagThomas || c.Tridiagonal == solver.TridiagParallel,
+		"tridiagonal must be %s or %s", solver.TridiagThomas, solver.TridiagParallel)
+	check(c.VerifyResidual == solver.ResidualOff || c.VerifyResidual == solver.ResidualWarn || c.VerifyResidual == solver.ResidualStrict,
+		"verify_residual must be %s, %s or %s", solver.ResidualOff, solver.ResidualWarn, solver.ResidualStrict)
+	for _, o := range c.CORSOrigins {
+		if o == "*" {
+			continue
+		}
+		u, err := url.Parse(o)
+		check(err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "" && u.Path == "" && u.RawQuery == "",
+			"cors_origins: %q must be * or scheme://host[:port]", o)
+	}
+	return errors.Join(errs...)
+}
+
+// Копия для лога и -print-config: хэши ключей скрыты, имена видны
+func (c serverConfig) redacted() serverConfig {
+	keys := make([]string, len(c.APIKeys))
+	for i, entry := range c.APIKeys {
+		name, rest, _ := strings.Cut(entry, ":")
+		keys[i] = name + ":<redacted>"
+		if strings.HasSuffix(rest, ":revoked") {
+			keys[i] += ":revoked"
+		}
+	}
+	c.APIKeys = keys
+	return c
+}
+
+// Запись о конфигурации в журнале запуска; ключи скрыты, как в -print-config
+func logConfig(file string, c serverConfig) {
+	slog.Info("Configuration loaded", "file", file, "config", c.String())
+}
+
+func (c serverConfig) String() string {
+	var buf bytes.Buffer
+	c.writeJSON(&buf, "")
+	return strings.TrimSpace(buf.String())
+}
+
+func (c serverConfig) writeJSON(w io.Writer, indent string) error {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", indent)
+	return enc.Encode(c.redacted())
+}
+
+// Длительность в конфигурации: строка вида "30s" или "10m" в JSON и флагах
+type duration time.Duration
+
+func (d duration) String() string { return time.Duration(d).String() }
+
+func (d *duration) Set(s string) error {
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = duration(v)
+	return nil
+}
+
+func (d duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+func (d *duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return fmt.Errorf("duration must be a string like \"30s\": %w", err)
+	}
+	return d.Set(s)
+}
+
+// Список через запятую во флаге и переменной окружения
+type stringList []string
+
+func (l *stringList) String() string {
+	if l == nil {
+		return ""
+	}
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(s string) error {
+	*l = splitList(s)
+	return nil
+}
+
+func splitList(s string) []string {
+	var out []string
+	for part := range strings.SplitSeq(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
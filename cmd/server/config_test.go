@@ -0,0 +1,167 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"heat-solver/internal/config"
+)
+
+// Окружение из словаря вместо os.Getenv
+func envMap(env map[string]string) func(string) string {
+	return func(k string) string { return env[k] }
+}
+
+// Файл-пример, поверх него окружение, поверх окружения флаги
+func TestLoadServerConfig(t *testing.T) {
+	key := keyEntry("ci", "secret", false)
+	cfg, opts, err := loadServerConfig("server", []string{"-port", "9000", "-cache-failed"}, envMap(map[string]string{
+		"HEAT_CONFIG":    "config.example.json",
+		"HEAT_PORT":      "7000",
+		"HEAT_MAX_NX":    "500",
+		"HEAT_CACHE_TTL": "1m",
+		"HEAT_API_KEYS":  key + ", " + keyEntry("old", "o", true),
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opts.ConfigFile != "config.example.json" || opts.PrintConfig {
+		t.Errorf("flags %+v, want the file from HEAT_CONFIG", opts)
+	}
+
+	want := defaultServerConfig()
+	want.Addr = "127.0.0.1"
+	want.Port = 9000
+	want.Limits = config.Limits{MaxNx: 500, MaxNt: 1000000, MaxCells: 20000000}
+	want.Cache.TTL = duration(time.Minute)
+	want.Cache.Failed = true
+	want.APIKeys = []string{key, keyEntry("old", "o", true)}
+	want.CORSOrigins = []string{"http://localhost:3000"}
+	if cfg.String() != want.String() || !slices.Equal(cfg.APIKeys, want.APIKeys) {
+		t.Errorf("config\n%s\nwant\n%s", cfg, want)
+	}
+	if err := cfg.validate(); err != nil {
+		t.Errorf("example configuration invalid: %v", err)
+	}
+
+	// Флаг -config важнее HEAT_CONFIG
+	file := filepath.Join(t.TempDir(), "server.json")
+	if err := os.WriteFile(file, []byte(`{"port": 1234, "jobs": {"workers": 8}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, _, err = loadServerConfig("server", []string{"-config", file}, envMap(map[string]string{"HEAT_CONFIG": "missing.json"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Port != 1234 || cfg.Jobs.Workers != 8 || cfg.Jobs.Timeout != defaultServerConfig().Jobs.Timeout {
+		t.Errorf("port %d, workers %d, job timeout %v; want the file over the defaults", cfg.Port, cfg.Jobs.Workers, cfg.Jobs.Timeout)
+	}
+}
+
+func TestLoadServerConfigErrors(t *testing.T) {
+	dir := t.TempDir()
+	unknown := filepath.Join(dir, "unknown.json")
+	if err := os.WriteFile(unknown, []byte(`{"max_nx": 10}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		args    []string
+		env     map[string]string
+		wantErr string
+	}{
+		{"missing file", []string{"-config", filepath.Join(dir, "nope.json")}, nil, "no such file"},
+		{"unknown field", []string{"-config", unknown}, nil, `unknown field "max_nx"`},
+		{"bad env value", nil, map[string]string{"HEAT_MAX_NX": "many"}, "HEAT_MAX_NX"},
+		{"bad env duration", nil, map[string]string{"HEAT_REQUEST_TIMEOUT": "5"}, "HEAT_REQUEST_TIMEOUT"},
+		{"unknown flag", []string{"-max-nz", "5"}, nil, "max-nz"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, _, err := loadServerConfig("server", tc.args, envMap(tc.env))
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("error %v, want one containing %q", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateServerConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		change  func(c *serverConfig)
+		wantErr string
+	}{
+		{"defaults", func(*serverConfig) {}, ""},
+		{"port", func(c *serverConfig) { c.Port = 70000 }, "port must be in [0, 65535]"},
+		{"tls pair", func(c *serverConfig) { c.TLSCert = "cert.pem" }, "tls_cert and tls_key must be set together"},
+		{"max_nx", func(c *serverConfig) { c.Limits.MaxNx = -1 }, "limits.max_nx must not be negative"},
+		{"workers", func(c *serverConfig) { c.Jobs.Workers = 0 }, "jobs.workers must be positive"},
+		{"timeout", func(c *serverConfig) { c.RequestTimeout = 0 }, "request_timeout must be positive"},
+		{"tridiagonal", func(c *serverConfig) { c.Tridiagonal = "lu" }, "tridiagonal must be"},
+		{"cors origin", func(c *serverConfig) { c.CORSOrigins = []string{"example.com"} }, `cors_origins: "example.com"`},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := defaultServerConfig()
+			tc.change(&cfg)
+			err := cfg.validate()
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("error %v, want one containing %q", err, tc.wantErr)
+			}
+		})
+	}
+
+	// Все ошибки сообщаются разом
+	cfg := defaultServerConfig()
+	cfg.Port, cfg.Jobs.Workers = -1, 0
+	if err := cfg.validate(); err == nil || strings.Count(err.Error(), "\n") != 1 {
+		t.Errorf("error %v, want both problems", err)
+	}
+}
+
+// В журнал запуска и -print-config хэши ключей не попадают
+func TestConfigRedaction(t *testing.T) {
+	logs := captureLogs(t)
+	cfg := defaultServerConfig()
+	cfg.APIKeys = []string{keyEntry("ci", "secret", false), keyEntry("old", "o", true)}
+	hash := strings.TrimPrefix(cfg.APIKeys[0], "ci:")
+
+	logConfig("server.json", cfg)
+	var printed bytes.Buffer
+	if err := cfg.writeJSON(&printed, "  "); err != nil {
+		t.Fatal(err)
+	}
+
+	var rec map[string]any
+	for _, r := range logs.records(t) {
+		if r["msg"] == "Configuration loaded" {
+			rec = r
+		}
+	}
+	if rec == nil {
+		t.Fatal("no configuration record in the boot log")
+	}
+	for name, out := range map[string]string{"boot log": rec["config"].(string), "print-config": printed.String()} {
+		if strings.Contains(out, hash) {
+			t.Errorf("%s contains a key hash: %s", name, out)
+		}
+		if !strings.Contains(out, "ci:<redacted>") || !strings.Contains(out, "old:<redacted>:revoked") {
+			t.Errorf("%s without the redacted key names: %s", name, out)
+		}
+	}
+	if !strings.HasPrefix(cfg.APIKeys[0], "ci:"+hash) {
+		t.Error("redaction changed the configuration itself")
+	}
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"heat-solver/internal/config"
+	"heat-solver/internal/solver"
+)
+
+// Машиночитаемые коды ошибок API
+const (
+	codeInvalidParameter = "invalid_parameter"
+	codeUnauthorized     = "unauthorized"
+	codeForbidden        = "forbidden"
+	codeLimitExceeded    = "limit_exceeded"
+	codeDiverged         = "diverged"
+	codeNoReference      = "no_reference"
+	codeConflict         = "conflicting_parameters"
+	codeJobNotReady      = "job_not_ready"
+	codeTimeout          = "timeout"
+	codeNotFound         = "not_found"
+	codeNotAcceptable    = "not_acceptable"
+	codeMethodNotAllowed = "method_not_allowed"
+	codeInternal         = "internal"
+)
+
+var (
+	errNotFound    = errors.New("not found")
+	errNoReference = errors.New("no analytical reference")
+	errConflict    = errors.New("conflicting parameters")
+)
+
+type errorBody struct {
+	Code      string   `json:"code"`
+	Message   string   `json:"message"`
+	Field     string   `json:"field,omitempty"`
+	Supported []string `json:"supported,omitempty"`
+	RequestID string   `json:"request_id,omitempty"`
+}
+
+type errorEnvelope struct {
+	Error errorBody `json:"error"`
+}
+
+// Ошибка параметра запроса
+func paramError(field, message string) error {
+	return &config.ValidationError{Field: field, Message: message}
+}
+
+// Статус и код по типу ошибки
+func classifyError(err error) (status int, body errorBody) {
+	var verr *config.ValidationError
+	var lerr *config.LimitError
+	var naerr *notAcceptableError
+	switch {
+	case errors.As(err, &verr):
+		return http.StatusBadRequest, errorBody{Code: codeInvalidParameter, Message: err.Error(), Field: verr.Field}
+	case errors.As(err, &lerr):
+		return http.StatusRequestEntityTooLarge, errorBody{Code: codeLimitExceeded, Message: err.Error(), Field: lerr.Field}
+	case errors.Is(err, solver.ErrDiverged):
+		return http.StatusUnprocessableEntity, errorBody{Code: codeDiverged, Message: err.Error()}
+	case errors.Is(err, errJobNotReady):
+		return http.StatusConflict, errorBody{Code: codeJobNotReady, Message: err.Error()}
+	case errors.Is(err, errConflict):
+		return http.StatusUnprocessableEntity, errorBody{Code: codeConflict, Message: err.Error()}
+	case errors.Is(err, errNoReference):
+		return http.StatusUnprocessableEntity, errorBody{Code: codeNoReference, Message: err.Error()}
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout, errorBody{Code: codeTimeout, Message: "request deadline exceeded"}
+	case errors.Is(err, errUnauthorized):
+		return http.StatusUnauthorized, errorBody{Code: codeUnauthorized, Message: err.Error()}
+	case errors.Is(err, errForbidden):
+		return http.StatusForbidden, errorBody{Code: codeForbidden, Message: err.Error()}
+	case errors.As(err, &naerr):
+		return http.StatusNotAcceptable, errorBody{Code: codeNotAcceptable, Message: err.Error(), Supported: naerr.Supported}
+	case errors.Is(err, errNotFound):
+		return http.StatusNotFound, errorBody{Code: codeNotFound, Message: err.Error()}
+	default:
+		return http.StatusInternalServerError, errorBody{Code: codeInternal, Message: "internal server error"}
+	}
+}
+
+// Ответ с ошибкой в едином JSON-конверте
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	status, body := classifyError(err)
+	if status == http.StatusInternalServerError {
+		slog.ErrorContext(r.Context(), "Request failed", "error", err)
+	}
+	writeErrorBody(w, r, status, body)
+}
+
+func writeErrorBody(w http.ResponseWriter, r *http.Request, status int, body errorBody) {
+	body.RequestID = requestIDFrom(r.Context())
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Del("Content-Length")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(errorEnvelope{Error: body})
+}
+
+// Перехват текстовых ошибок стандартных обработчиков (404/405 ServeMux,
+// FileServer) и замена их телом в JSON-конверте
+type errorRewriter struct {
+	http.ResponseWriter
+	r         *http.Request
+	rewritten bool
+}
+
+func (e *errorRewriter) WriteHeader(code int) {
+	ct := e.Header().Get("Content-Type")
+	if code < 400 || e.rewritten || !strings.HasPrefix(ct, "text/plain") {
+		e.ResponseWriter.WriteHeader(code)
+		return
+	}
+	e.rewritten = true
+	body := errorBody{Code: codeInternal, Message: http.StatusText(code)}
+	switch code {
+	case http.StatusNotFound:
+		body.Code = codeNotFound
+	case http.StatusMethodNotAllowed:
+		body.Code = codeMethodNotAllowed
+	case http.StatusBadRequest:
+		body.Code = codeInvalidParameter
+	}
+	writeErrorBody(e.ResponseWriter, e.r, code, body)
+}
+
+func (e *errorRewriter) Write(b []byte) (int, error) {
+	if e.rewritten {
+		return len(b), nil
+	}
+	return e.ResponseWriter.Write(b)
+}
+
+func (e *errorRewriter) Unwrap() http.ResponseWriter {
+	return e.ResponseWriter
+}
+
+func jsonErrors(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(&errorRewriter{ResponseWriter: w, r: r}, r)
+	})
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"heat-solver/internal/config"
+	"heat-solver/internal/solver"
+)
+
+// Каждый класс внутренних ошибок, в том числе обёрнутый через %w,
+// получает свой статус и код
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		code   string
+		field  string
+	}{
+		{"validation", paramError("dx", "must be positive"), 400, codeInvalidParameter, "dx"},
+		{"limit", &config.LimitError{Field: "nx", Limit: 10, Value: 20}, 413, codeLimitExceeded, "nx"},
+		{"diverged", fmt.Errorf("run: %w", solver.ErrDiverged), 422, codeDiverged, ""},
+		{"job not ready", errJobNotReady, 409, codeJobNotReady, ""},
+		{"conflict", fmt.Errorf("%w: ic and ic_samples", errConflict), 422, codeConflict, ""},
+		{"no reference", fmt.Errorf("%w: tophat", errNoReference), 422, codeNoReference, ""},
+		{"timeout", fmt.Errorf("solve: %w", context.DeadlineExceeded), 504, codeTimeout, ""},
+		{"unauthorized", errUnauthorized, 401, codeUnauthorized, ""},
+		{"forbidden", errForbidden, 403, codeForbidden, ""},
+		{"not acceptable", &notAcceptableError{Accept: "image/bmp", Supported: []string{"application/json"}}, 406, codeNotAcceptable, ""},
+		{"not found", fmt.Errorf("job x: %w", errNotFound), 404, codeNotFound, ""},
+		{"other", errors.New("disk on fire"), 500, codeInternal, ""},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			status, body := classifyError(tc.err)
+			if status != tc.status || body.Code != tc.code || body.Field != tc.field {
+				t.Errorf("classifyError = %d %+v, want %d code %s field %q", status, body, tc.status, tc.code, tc.field)
+			}
+			if tc.status == 500 && body.Message != "internal server error" {
+				t.Errorf("internal error message %q leaks details", body.Message)
+			}
+		})
+	}
+}
+
+// Ошибки настоящих запросов: статус, код и JSON-конверт с request_id,
+// в том числе 404/405 от ServeMux
+func TestErrorResponses(t *testing.T) {
+	_, ts := newTestServer(t, func(cfg *serverConfig) {
+		cfg.Limits.MaxNx = 500
+	})
+	tests := []struct {
+		name, method, path, body, accept string
+		status                           int
+		code                             string
+	}{
+		{"invalid parameter", "GET", "/api/v1/simulate?dx=abc", "", "", 400, codeInvalidParameter},
+		{"invalid JSON body", "POST", "/api/v1/simulate", "{", "", 400, codeInvalidParameter},
+		{"unknown method", "GET", "/api/v1/simulate?method=NOPE", "", "", 400, codeInvalidParameter},
+		{"limit", "GET", "/api/v1/simulate?dx=0.001", "", "", 413, codeLimitExceeded},
+		{"conflict", "POST", "/api/v1/simulate", `{"ic":"tophat","ic_samples":[{"x":0,"u":0},{"x":1,"u":0}]}`, "", 422, codeConflict},
+		{"diverged", "POST", "/api/v1/evaluate", `{"method":"FTCS","dx":0.05,"dt":0.01,"fit_alpha":true,"measurements":[{"x":0.5,"t":0.5,"u":0},{"x":0.5,"t":0.9,"u":0}]}`, "", 422, codeDiverged},
+		{"not acceptable", "GET", "/api/v1/simulate", "", "image/bmp", 406, codeNotAcceptable},
+		{"unknown job", "GET", "/api/v1/jobs/nope", "", "", 404, codeNotFound},
+		{"no route", "GET", "/api/v1/nothing", "", "", 404, codeNotFound},
+		{"wrong method", "DELETE", "/api/v1/simulate", "", "", 405, codeMethodNotAllowed},
+		{"wrong method for static files", "POST", "/index.html", "", "", 405, codeMethodNotAllowed},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest(tc.method, ts.URL+tc.path, strings.NewReader(tc.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tc.accept != "" {
+				req.Header.Set("Accept", tc.accept)
+			}
+			resp, data := send(t, ts, req, tc.status)
+			if ct := resp.Header.Get("Content-Type"); ct != "application/json" {
+				t.Errorf("Content-Type = %q, want application/json", ct)
+			}
+			var env errorEnvelope
+			decode(t, data, &env)
+			if env.Error.Code != tc.code || env.Error.Message == "" {
+				t.Errorf("error %+v, want code %s with a message", env.Error, tc.code)
+			}
+			if env.Error.RequestID == "" || env.Error.RequestID != resp.Header.Get("X-Request-ID") {
+				t.Errorf("request_id %q, want the X-Request-ID %q", env.Error.RequestID, resp.Header.Get("X-Request-ID"))
+			}
+		})
+	}
+}
+
+func TestErrorTimeout(t *testing.T) {
+	_, ts := newTestServer(t, func(cfg *serverConfig) {
+		cfg.RequestTimeout = duration(time.Millisecond)
+	})
+	_, data := get(t, ts, "/api/v1/simulate?method=CN&dx=0.001&dt=0.00001&tmax=1&storage=final-only", 504)
+	var env errorEnvelope
+	decode(t, data, &env)
+	if env.Error.Code != codeTimeout {
+		t.Errorf("error %+v, want code %s", env.Error, codeTimeout)
+	}
+}
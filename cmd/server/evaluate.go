@@ -0,0 +1,378 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"heat-solver/internal/config"
+	"heat-solver/internal/mathutils"
+	"heat-solver/internal/solver"
+)
+
+// Ограничения загружаемых измерений
+const (
+	maxEvaluateBody   = 8 << 20
+	maxEvaluatePoints = 100000
+)
+
+// Диапазон подбора α по умолчанию
+const (
+	defaultAlphaMin = 0.1
+	defaultAlphaMax = 10
+)
+
+type measurement struct {
+	X float64 `json:"x"`
+	T float64 `json:"t"`
+	U float64 `json:"u" doc:"Observed value u_obs"`
+	// Строка CSV-файла (0 для JSON)
+	line int
+}
+
+// Сравнение измерений с расчётом. Параметры расчёта — как у /simulate;
+// α учитывается заменой времени τ = αt, как в интерактивной сессии, а
+// подбирается solver.FitAlpha, как в подкоманде fit
+type evaluateRequest struct {
+	simulateRequest
+	Alpha        float64       `json:"alpha" doc:"Diffusivity used for the simulation; default 1, ignored with fit_alpha; values other than 1 need lambda and source unset"`
+	FitAlpha     bool          `json:"fit_alpha" doc:"Fit α by least squares over the points inside the domain"`
+	AlphaMin     float64       `json:"alpha_min" doc:"Lower bound of the α search (default 0.1)"`
+	AlphaMax     float64       `json:"alpha_max" doc:"Upper bound of the α search (default 10); the simulation runs up to alpha_max times the latest measurement time inside the domain, so the cell limit applies to that"`
+	Measurements []measurement `json:"measurements" doc:"Measured points; with multipart upload they come from the CSV file part data"`
+}
+
+type evaluatePoint struct {
+	Index    int        `json:"index"`
+	Line     int        `json:"line,omitempty" doc:"Line of the uploaded CSV file"`
+	X        float64    `json:"x"`
+	T        float64    `json:"t"`
+	UObs     float64    `json:"u_obs"`
+	USim     *jsonFloat `json:"u_sim,omitempty" doc:"Simulation interpolated to (x, t); absent for points outside the domain"`
+	Residual *jsonFloat `json:"residual,omitempty" doc:"u_obs − u_sim"`
+	Outside  string     `json:"outside,omitempty" doc:"Why the point was excluded from the statistics"`
+}
+
+type evaluateStats struct {
+	Points        int       `json:"points"`
+	Used          int       `json:"used" doc:"Points inside the simulated domain"`
+	Outside       int       `json:"outside"`
+	RMS           jsonFloat `json:"rms"`
+	Bias          jsonFloat `json:"bias" doc:"Mean residual"`
+	MaxAbsError   jsonFloat `json:"max_abs_error"`
+	MaxErrorIndex int       `json:"max_error_index"`
+}
+
+type evaluateResponse struct {
+	Method      string          `json:"method"`
+	Nx          int             `json:"nx"`
+	Nt          int             `json:"nt"`
+	Dx          float64         `json:"dx"`
+	Dt          float64         `json:"dt"`
+	Tmax        float64         `json:"tmax"`
+	Alpha       float64         `json:"alpha" doc:"α used for the residuals: given or fitted"`
+	AlphaFitted bool            `json:"alpha_fitted"`
+	AlphaLow    *float64        `json:"alpha_ci95_low,omitempty" doc:"Rough 95% confidence interval of the fitted α (Gauss–Newton linearisation)"`
+	AlphaHigh   *float64        `json:"alpha_ci95_high,omitempty"`
+	Diverged    bool            `json:"diverged"`
+	Stats       evaluateStats   `json:"stats"`
+	Points      []evaluatePoint `json:"points"`
+}
+
+// JSON-тело или multipart: параметры в строке запроса, CSV в части data
+func decodeEvaluateRequest(w http.ResponseWriter, r *http.Request) (evaluateRequest, error) {
+	if r.ContentLength > maxEvaluateBody {
+		return evaluateRequest{}, &config.LimitError{Field: "body_bytes", Limit: maxEvaluateBody, Value: r.ContentLength}
+	}
+	body := http.MaxBytesReader(w, r.Body, maxEvaluateBody)
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "multipart/form-data" {
+		var req evaluateRequest
+		dec := json.NewDecoder(body)
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&req); err != nil {
+			return req, paramError("body", "invalid JSON: "+err.Error())
+		}
+		return req, nil
+	}
+
+	q := r.URL.Query()
+	var req evaluateRequest
+	var err error
+	if req.simulateRequest, err = requestFromQuery(q); err != nil {
+		return req, err
+	}
+	if req.Alpha, err = floatParam(q, "alpha", 0); err != nil {
+		return req, err
+	}
+	if req.AlphaMin, err = floatParam(q, "alpha_min", 0); err != nil {
+		return req, err
+	}
+	if req.AlphaMax, err = floatParam(q, "alpha_max", 0); err != nil {
+		return req, err
+	}
+	if v := q.Get("fit_alpha"); v != "" {
+		if req.FitAlpha, err = strconv.ParseBool(v); err != nil {
+			return req, paramError("fit_alpha", "must be true or false")
+		}
+	}
+
+	r.Body = body
+	mr, err := r.MultipartReader()
+	if err != nil {
+		return req, paramError("body", err.Error())
+	}
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			return req, paramError("data", "missing CSV file part data")
+		}
+		if err != nil {
+			return req, paramError("body", err.Error())
+		}
+		if part.FormName() == "data" {
+			req.Measurements, err = readMeasurements(part)
+			return req, err
+		}
+	}
+}
+
+// CSV с заголовком: столбцы x, t и u (или u_obs), прочие игнорируются.
+// Строки с # — метаданные (как "# lambda=5" в CSV из CLI) — пропускаются
+func readMeasurements(r io.Reader) ([]measurement, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+	cr.Comment = '#'
+	header, err := cr.Read()
+	if err != nil {
+		return nil, paramError("data", "cannot read CSV header: "+err.Error())
+	}
+	cols := map[string]int{}
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "u_obs" {
+			name = "u"
+		}
+		if _, dup := cols[name]; !dup {
+			cols[name] = i
+		}
+	}
+	for _, name := range []string{"x", "t", "u"} {
+		if _, ok := cols[name]; !ok {
+			return nil, paramError("data", "CSV header has no column "+name+", want x,t,u or x,t,u_obs")
+		}
+	}
+
+	var points []measurement
+	for {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			return points, nil
+		}
+		if err != nil {
+			// ParseError уже называет строку; FieldPos без записи паникует
+			var perr *csv.ParseError
+			if errors.As(err, &perr) {
+				return nil, paramError("data", err.Error())
+			}
+			return nil, paramError("data", "cannot read CSV: "+err.Error())
+		}
+		line, _ := cr.FieldPos(0)
+		if len(points) == maxEvaluatePoints {
+			return nil, &config.LimitError{Field: "points", Limit: maxEvaluatePoints, Value: maxEvaluatePoints + 1}
+		}
+		m := measurement{line: line}
+		for _, c := range []struct {
+			name string
+			dst  *float64
+		}{{"x", &m.X}, {"t", &m.T}, {"u", &m.U}} {
+			name, dst, i := c.name, c.dst, cols[c.name]
+			if i >= len(rec) {
+				return nil, paramError("data", fmt.Sprintf("line %d: missing column %s", line, name))
+			}
+			if *dst, err = strconv.ParseFloat(strings.TrimSpace(rec[i]), 64); err != nil {
+				return nil, paramError("data", fmt.Sprintf("line %d: column %s: %q is not a number", line, name, rec[i]))
+			}
+		}
+		points = append(points, m)
+	}
+}
+
+// Причина исключения точки; пустая строка — точка внутри области
+func outsideDomain(m measurement, tEnd float64) string {
+	switch {
+	case math.IsNaN(m.X) || math.IsNaN(m.T) || math.IsNaN(m.U) || math.IsInf(m.U, 0):
+		return "not a finite value"
+	case m.X < 0 || m.X > 1:
+		return "x outside [0, 1]"
+	case m.T < 0 || m.T > tEnd:
+		return fmt.Sprintf("t outside [0, %g]", tEnd)
+	}
+	return ""
+}
+
+// Замена времени τ = αt переводит u_t = α·u_xx в u_τ = u_xx, но реакция
+// и источник при этом делились бы на α (а у пучка менялись бы скорость и
+// время), поэтому α ≠ 1 и подбор α берутся только без них
+func checkTimeScaling(p config.Params) error {
+	switch {
+	case p.Lambda != 0:
+		return fmt.Errorf("%w: alpha other than 1 and fit_alpha cannot be combined with lambda", errConflict)
+	case p.Source != "":
+		return fmt.Errorf("%w: alpha other than 1 and fit_alpha cannot be combined with source", errConflict)
+	}
+	return nil
+}
+
+func (s *server) handleEvaluate(w http.ResponseWriter, r *http.Request) {
+	req, err := decodeEvaluateRequest(w, r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	p, err := req.params()
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if len(req.Measurements) == 0 {
+		writeError(w, r, paramError("measurements", "no measurements"))
+		return
+	}
+	if len(req.Measurements) > maxEvaluatePoints {
+		writeError(w, r, &config.LimitError{Field: "points", Limit: maxEvaluatePoints, Value: int64(len(req.Measurements))})
+		return
+	}
+	if req.Alpha == 0 {
+		req.Alpha = 1
+	}
+	if !(req.Alpha > 0) || math.IsInf(req.Alpha, 0) {
+		writeError(w, r, paramError("alpha", "must be positive"))
+		return
+	}
+	if req.AlphaMin == 0 {
+		req.AlphaMin = defaultAlphaMin
+	}
+	if req.AlphaMax == 0 {
+		req.AlphaMax = defaultAlphaMax
+	}
+	if req.Alpha != 1 || req.FitAlpha {
+		if err := checkTimeScaling(p); err != nil {
+			writeError(w, r, err)
+			return
+		}
+	}
+	if req.FitAlpha && !(req.AlphaMin > 0 && req.AlphaMin < req.AlphaMax && !math.IsInf(req.AlphaMax, 0)) {
+		writeError(w, r, paramError("alpha_min", "alpha_min and alpha_max must satisfy 0 < alpha_min < alpha_max"))
+		return
+	}
+
+	nx, nt := p.Grid()
+	tEnd := float64(nt) * p.Dt
+	var diverged bool
+	points := make([]evaluatePoint, len(req.Measurements))
+	var inside []int
+	for i, m := range req.Measurements {
+		points[i] = evaluatePoint{Index: i, Line: m.line, X: m.X, T: m.T, UObs: m.U}
+		if points[i].Outside = outsideDomain(m, tEnd); points[i].Outside == "" {
+			inside = append(inside, i)
+		}
+	}
+	if len(inside) == 0 {
+		writeError(w, r, paramError("measurements", fmt.Sprintf("none of the %d points lies inside x in [0, 1], t in [0, %g]", len(points), tEnd)))
+		return
+	}
+	if req.FitAlpha && len(inside) < 2 {
+		writeError(w, r, paramError("measurements", fmt.Sprintf("fit_alpha needs at least 2 points inside the domain, got %d", len(inside))))
+		return
+	}
+
+	// Значения расчёта в точках inside. При подборе — solver.FitAlpha с
+	// расчётом сервера (кэш и ограничения сетки), иначе — расчёт во
+	// времени τ = αt с шагом α·dt; для интерполяции по t нужны все слои
+	ctx, cancel := context.WithTimeout(r.Context(), s.timeout)
+	defer cancel()
+	model := make([]float64, len(inside))
+	alpha := req.Alpha
+	var low, high *float64
+	if req.FitAlpha {
+		samples := make([]solver.ProbeSample, len(inside))
+		for k, i := range inside {
+			m := req.Measurements[i]
+			samples[k] = solver.ProbeSample{T: m.T, X: m.X, U: m.U}
+		}
+		fit, err := solver.FitAlpha(ctx, p, samples, req.AlphaMin, req.AlphaMax, func(ctx context.Context, sim config.Params) (*solver.Result, error) {
+			res, hit, err := s.simulate(ctx, sim)
+			if err == nil {
+				setCacheHeader(w, hit)
+			}
+			return res, err
+		})
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		alpha, model, low, high = fit.Alpha, fit.Model, &fit.Low, &fit.High
+	} else {
+		sim := p
+		sim.Storage = config.StorageFull
+		sim.Dt, sim.Tmax = p.Dt*req.Alpha, p.Tmax*req.Alpha
+		res, hit, err := s.simulate(ctx, sim)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		defer res.Release()
+		setCacheHeader(w, hit)
+		if res.Diverged {
+			w.Header().Set("X-Diverged", "true")
+			diverged = true
+		}
+		u := res.Rows()
+		for k, i := range inside {
+			m := req.Measurements[i]
+			model[k] = mathutils.Bilinear(u, res.Dx, res.Dt, m.X, alpha*m.T)
+		}
+	}
+
+	stats := evaluateStats{Points: len(points), Used: len(inside), Outside: len(points) - len(inside), MaxErrorIndex: inside[0]}
+	var sum, sumSq float64
+	for k, i := range inside {
+		u := jsonFloat(model[k])
+		d := jsonFloat(points[i].UObs) - u
+		points[i].USim, points[i].Residual = &u, &d
+		sum += float64(d)
+		sumSq += float64(d * d)
+		if a := math.Abs(float64(d)); a > float64(stats.MaxAbsError) || math.IsNaN(a) {
+			stats.MaxAbsError, stats.MaxErrorIndex = jsonFloat(a), i
+		}
+	}
+	n := float64(len(inside))
+	stats.RMS, stats.Bias = jsonFloat(math.Sqrt(sumSq/n)), jsonFloat(sum/n)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(evaluateResponse{
+		Method:      p.Method,
+		Nx:          nx,
+		Nt:          nt,
+		Dx:          p.Dx,
+		Dt:          p.Dt,
+		Tmax:        tEnd,
+		Alpha:       alpha,
+		AlphaFitted: req.FitAlpha,
+		AlphaLow:    low,
+		AlphaHigh:   high,
+		Diverged:    diverged,
+		Stats:       stats,
+		Points:      points,
+	})
+}
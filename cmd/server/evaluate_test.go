@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"testing"
+
+	"heat-solver/internal/mathutils"
+)
+
+type evaluateResult struct {
+	Alpha       float64  `json:"alpha"`
+	AlphaFitted bool     `json:"alpha_fitted"`
+	AlphaLow    *float64 `json:"alpha_ci95_low"`
+	AlphaHigh   *float64 `json:"alpha_ci95_high"`
+	Stats       struct {
+		Points, Used, Outside int
+		RMS                   float64 `json:"rms"`
+		Bias                  float64 `json:"bias"`
+		MaxAbsError           float64 `json:"max_abs_error"`
+	} `json:"stats"`
+	Points []struct {
+		Line     int      `json:"line"`
+		Residual *float64 `json:"residual"`
+		Outside  string   `json:"outside"`
+	} `json:"points"`
+}
+
+// Точные значения exp(−απ²t)·sin(πx) в узлах x = 0.1…0.9 для нескольких t
+func syntheticMeasurements(alpha float64) []measurement {
+	var out []measurement
+	for _, t := range []float64{0.02, 0.05, 0.1, 0.15} {
+		for i := 1; i <= 9; i++ {
+			x := float64(i) / 10
+			out = append(out, measurement{X: x, T: t, U: mathutils.AnalyticalSolutionAlpha(x, t, alpha)})
+		}
+	}
+	return out
+}
+
+func measurementsJSON(ms []measurement) string {
+	parts := make([]string, len(ms))
+	for i, m := range ms {
+		parts[i] = fmt.Sprintf(`{"x":%g,"t":%g,"u":%g}`, m.X, m.T, m.U)
+	}
+	return "[" + strings.Join(parts, ",") + "]"
+}
+
+// Измерения из точного решения: невязки на уровне ошибки дискретизации,
+// точка вне области попадает в отчёт, но не в статистику
+func TestEvaluate(t *testing.T) {
+	_, ts := newTestServer(t, nil)
+	ms := append(syntheticMeasurements(1), measurement{X: 0.5, T: 5, U: 0})
+	_, body := postJSON(t, ts, "/api/v1/evaluate", `{"method":"CN","dx":0.02,"dt":0.0005,"tmax":0.2,"measurements":`+measurementsJSON(ms)+`}`, 200)
+	var res evaluateResult
+	decode(t, body, &res)
+
+	n := len(ms) - 1
+	if res.Alpha != 1 || res.AlphaFitted || res.Stats.Points != n+1 || res.Stats.Used != n || res.Stats.Outside != 1 {
+		t.Errorf("alpha %g fitted %v, stats %+v; want alpha 1 and %d of %d points used", res.Alpha, res.AlphaFitted, res.Stats, n, n+1)
+	}
+	if !(res.Stats.MaxAbsError < 1e-3) || !(res.Stats.RMS <= res.Stats.MaxAbsError) || !(math.Abs(res.Stats.Bias) <= res.Stats.RMS) {
+		t.Errorf("stats %+v, want residuals at the discretization error level", res.Stats)
+	}
+	if out := res.Points[n]; out.Outside == "" || out.Residual != nil {
+		t.Errorf("point outside the domain %+v, want it reported without a residual", out)
+	}
+}
+
+func TestEvaluateFitAlpha(t *testing.T) {
+	_, ts := newTestServer(t, nil)
+	const truth = 0.7
+	_, body := postJSON(t, ts, "/api/v1/evaluate", `{"method":"CN","dx":0.02,"dt":0.0005,"tmax":0.2,"fit_alpha":true,"alpha_min":0.2,"alpha_max":2,"measurements":`+measurementsJSON(syntheticMeasurements(truth))+`}`, 200)
+	var res evaluateResult
+	decode(t, body, &res)
+	if !res.AlphaFitted || math.Abs(res.Alpha-truth) > 5e-3 {
+		t.Errorf("fitted alpha %g, want %g", res.Alpha, truth)
+	}
+	if res.AlphaLow == nil || res.AlphaHigh == nil || !(*res.AlphaLow <= res.Alpha && res.Alpha <= *res.AlphaHigh) {
+		t.Errorf("confidence interval [%v, %v] around %g", res.AlphaLow, res.AlphaHigh, res.Alpha)
+	}
+	if !(res.Stats.RMS < 1e-3) {
+		t.Errorf("rms %g after fitting, want the discretization error level", res.Stats.RMS)
+	}
+}
+
+// Загрузка CSV через multipart: параметры в строке запроса, номера строк
+// в отчёте и в ошибках разбора
+func TestEvaluateUpload(t *testing.T) {
+	_, ts := newTestServer(t, nil)
+	upload := func(t *testing.T, csv string, want int) []byte {
+		t.Helper()
+		var buf bytes.Buffer
+		mw := multipart.NewWriter(&buf)
+		fw, _ := mw.CreateFormFile("data", "measurements.csv")
+		fw.Write([]byte(csv))
+		mw.Close()
+		req, _ := http.NewRequest(http.MethodPost, ts.URL+"/api/v1/evaluate?method=CN&dx=0.02&dt=0.0005&tmax=0.2", &buf)
+		req.Header.Set("Content-Type", mw.FormDataContentType())
+		_, body := send(t, ts, req, want)
+		return body
+	}
+
+	var csv strings.Builder
+	csv.WriteString("# lab 3\nx,t,u_obs,sensor\n")
+	for _, m := range syntheticMeasurements(1)[:3] {
+		fmt.Fprintf(&csv, "%g,%g,%g,a\n", m.X, m.T, m.U)
+	}
+	var res evaluateResult
+	decode(t, upload(t, csv.String(), 200), &res)
+	if len(res.Points) != 3 || res.Points[0].Line != 3 || res.Points[2].Line != 5 || !(res.Stats.MaxAbsError < 1e-3) {
+		t.Errorf("points %+v, stats %+v; want lines 3-5 with small residuals", res.Points, res.Stats)
+	}
+
+	tests := []struct {
+		name, csv, message string
+	}{
+		{"no column", "x,t\n0.5,0.1\n", "no column u"},
+		{"not a number", "x,t,u\n0.5,0.1,0.3\n0.5,abc,0.3\n", "line 3: column t"},
+		{"missing value", "x,t,u\n0.5,0.1\n", "line 2: missing column u"},
+		{"bad quoting", "x,t,u\n0.5,0.1,0.3\n\"0.5,0.1,0.3\n", "line 3"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var env errorEnvelope
+			decode(t, upload(t, tc.csv, 400), &env)
+			if env.Error.Field != "data" || !strings.Contains(env.Error.Message, tc.message) {
+				t.Errorf("error %+v, want %q on data", env.Error, tc.message)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"heat-solver/internal/config"
+	heatio "heat-solver/internal/io"
+)
+
+// Решение двоичными кадрами для браузера (формат — heatio.WriteFrames):
+// без разбора JSON каждый кадр читается прямо в Float32Array. float32 —
+// с потерей точности не больше (max−min)·2⁻²⁵, float64 — точно
+func (s *server) handleFrames(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	req, err := requestFromQuery(q)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	valueSize := 4
+	switch q.Get("precision") {
+	case "", config.PrecisionFloat32:
+	case config.PrecisionFloat64:
+		valueSize = 8
+	default:
+		writeError(w, r, paramError("precision", "must be "+config.PrecisionFloat32+" or "+config.PrecisionFloat64))
+		return
+	}
+	params, err := req.params()
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), s.timeout)
+	defer cancel()
+	res, hit, err := s.simulate(ctx, params)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	defer res.Release()
+	setCacheHeader(w, hit)
+	if res.Diverged {
+		w.Header().Set("X-Diverged", "true")
+	}
+
+	frames := thinLevels(res, req.Stride)
+	h := heatio.FramesHeader{
+		Nx:        res.Nx,
+		Nt:        res.Nt,
+		Stride:    resultStride(res, req.Stride),
+		Frames:    len(frames),
+		ValueSize: valueSize,
+		Diverged:  res.Diverged,
+		Dx:        res.Dx,
+		Dt:        res.Dt,
+		Scale:     1,
+	}
+	if valueSize == 4 {
+		h.Offset, h.Scale = heatio.FramesScale(frames)
+	}
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Length", strconv.FormatInt(h.Size(), 10))
+	heatio.WriteFrames(w, h, frames)
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"encoding/binary"
+	"math"
+	"strconv"
+	"testing"
+
+	heatio "heat-solver/internal/io"
+)
+
+// Разбор ответа /api/v1/frames так же, как это делает браузер
+func parseFrames(t *testing.T, body []byte) (heatio.FramesHeader, [][]float64) {
+	t.Helper()
+	if len(body) < heatio.FramesHeaderSize || string(body[:8]) != "HEATFRM1" {
+		t.Fatalf("no frames header in %q", excerpt(body))
+	}
+	le := binary.LittleEndian
+	f64 := func(off int) float64 { return math.Float64frombits(le.Uint64(body[off:])) }
+	h := heatio.FramesHeader{
+		Nx:        int(le.Uint32(body[8:])),
+		Nt:        int(le.Uint32(body[12:])),
+		Stride:    int(le.Uint32(body[16:])),
+		Frames:    int(le.Uint32(body[20:])),
+		ValueSize: int(le.Uint32(body[24:])),
+		Diverged:  le.Uint32(body[28:])&1 != 0,
+		Dx:        f64(32),
+		Dt:        f64(40),
+		Offset:    f64(48),
+		Scale:     f64(56),
+	}
+	if int64(len(body)) != h.Size() {
+		t.Fatalf("%d bytes, header %+v says %d", len(body), h, h.Size())
+	}
+
+	data := body[heatio.FramesHeaderSize:]
+	frames := make([][]float64, h.Frames)
+	for k := range frames {
+		frames[k] = make([]float64, h.Nx+1)
+		for i := range frames[k] {
+			off := (k*(h.Nx+1) + i) * h.ValueSize
+			if h.ValueSize == 4 {
+				frames[k][i] = h.Offset + h.Scale*float64(math.Float32frombits(le.Uint32(data[off:])))
+			} else {
+				frames[k][i] = math.Float64frombits(le.Uint64(data[off:]))
+			}
+		}
+	}
+	return h, frames
+}
+
+// Кадры совпадают со слоями JSON: float32 в пределах (max−min)·2⁻²⁵,
+// float64 точно
+func TestFrames(t *testing.T) {
+	_, ts := newTestServer(t, nil)
+	const query = "method=CN&dx=0.02&dt=0.001&tmax=0.1&stride=7"
+	_, body := get(t, ts, "/api/v1/simulate?"+query, 200)
+	var want simulateResult
+	decode(t, body, &want)
+
+	for _, precision := range []string{"float32", "float64"} {
+		t.Run(precision, func(t *testing.T) {
+			resp, body := get(t, ts, "/api/v1/frames?precision="+precision+"&"+query, 200)
+			if ct := resp.Header.Get("Content-Type"); ct != "application/octet-stream" {
+				t.Errorf("Content-Type = %q", ct)
+			}
+			if cl := resp.Header.Get("Content-Length"); cl != strconv.Itoa(len(body)) {
+				t.Errorf("Content-Length = %s for %d bytes", cl, len(body))
+			}
+			h, frames := parseFrames(t, body)
+			if h.Nx != want.Nx || h.Nt != want.Nt || h.Stride != 7 || h.Dx != want.Dx || h.Dt != want.Dt || h.Diverged {
+				t.Errorf("header %+v, want the grid of the JSON response", h)
+			}
+			if len(frames) != len(want.U) {
+				t.Fatalf("%d frames, JSON has %d levels", len(frames), len(want.U))
+			}
+
+			tol := 0.0
+			if precision == "float32" {
+				if h.ValueSize != 4 {
+					t.Fatalf("value size %d, want 4", h.ValueSize)
+				}
+				// Граница формата scale·2⁻²⁴ с запасом на округление при восстановлении
+				tol = 2 * h.Scale * 0x1p-24
+			}
+			for k, frame := range frames {
+				for i, v := range frame {
+					if d := math.Abs(v - want.U[k][i]); d > tol {
+						t.Fatalf("frame %d node %d: %g, JSON %g (diff %g > %g)", k, i, v, want.U[k][i], d, tol)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestFramesDiverged(t *testing.T) {
+	_, ts := newTestServer(t, nil)
+	resp, body := get(t, ts, "/api/v1/frames?method=FTCS&dx=0.05&dt=0.01&tmax=10&storage=final-only", 200)
+	if resp.Header.Get("X-Diverged") != "true" {
+		t.Error("diverged run without X-Diverged")
+	}
+	h, frames := parseFrames(t, body)
+	last := frames[len(frames)-1]
+	if !h.Diverged || !hasNonFinite(last) {
+		t.Errorf("diverged flag %v, last frame %v; want the flag and NaN/Inf passed through", h.Diverged, last)
+	}
+	get(t, ts, "/api/v1/frames?precision=float16", 400)
+}
+
+func hasNonFinite(row []float64) bool {
+	for _, v := range row {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return true
+		}
+	}
+	return false
+}
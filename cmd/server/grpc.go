@@ -0,0 +1,170 @@
+package main
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+
+	"heat-solver/internal/config"
+	"heat-solver/internal/heatpb"
+	"heat-solver/internal/mathutils"
+	"heat-solver/internal/solver"
+)
+
+// gRPC-сервис поверх тех же проверок, лимитов и кэша, что и HTTP
+type grpcService struct {
+	heatpb.UnimplementedHeatSolverServer
+	s *server
+}
+
+func (s *server) newGRPCServer() *grpc.Server {
+	gs := grpc.NewServer(
+		grpc.UnaryInterceptor(s.unaryAuth),
+		grpc.StreamInterceptor(s.streamAuth),
+	)
+	heatpb.RegisterHeatSolverServer(gs, &grpcService{s: s})
+	return gs
+}
+
+func requestFromProto(in *heatpb.SimulateRequest) simulateRequest {
+	return simulateRequest{
+		Method: in.GetMethod(),
+		Dx:     in.GetDx(),
+		Dt:     in.GetDt(),
+		Tmax:   in.GetTmax(),
+		Stride: int(in.GetStride()),
+	}
+}
+
+func (g *grpcService) run(ctx context.Context, in *heatpb.SimulateRequest) (*solver.Result, int, error) {
+	req := requestFromProto(in)
+	params, err := req.params()
+	if err != nil {
+		return nil, 0, grpcError(err)
+	}
+	ctx, cancel := context.WithTimeout(ctx, g.s.timeout)
+	defer cancel()
+
+	res, _, err := g.s.simulate(ctx, params)
+	if err != nil {
+		return nil, 0, grpcError(err)
+	}
+	return res, max(1, req.Stride), nil
+}
+
+func timeLevel(res *solver.Result, n int) *heatpb.TimeLevel {
+	return &heatpb.TimeLevel{
+		Index: uint32(n),
+		T:     float64(n) * res.Dt,
+		U:     res.Level(n),
+	}
+}
+
+// Слои ответа ссылаются на результат и кодируются уже после возврата,
+// поэтому результат не освобождается: его буфер соберёт сборщик мусора
+func (g *grpcService) Simulate(ctx context.Context, in *heatpb.SimulateRequest) (*heatpb.SimulateResponse, error) {
+	res, stride, err := g.run(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+
+	x := make([]float64, res.Nx+1)
+	for i := range x {
+		x[i] = float64(i) * res.Dx
+	}
+	var levels []*heatpb.TimeLevel
+	for _, n := range levelIndices(res.Nt, stride) {
+		levels = append(levels, timeLevel(res, n))
+	}
+	l2, linf := mathutils.ComputeErrors(res.Rows(), res.Dx, res.Dt)
+
+	return &heatpb.SimulateResponse{
+		Method:     res.Method,
+		Nx:         uint32(res.Nx),
+		Nt:         uint32(res.Nt),
+		Dx:         res.Dx,
+		Dt:         res.Dt,
+		R:          res.Dt / (res.Dx * res.Dx),
+		X:          x,
+		Levels:     levels,
+		Norms:      &heatpb.Norms{L2: l2, Linf: linf},
+		RuntimeSec: res.Runtime.Seconds(),
+		Diverged:   res.Diverged,
+	}, nil
+}
+
+func (g *grpcService) SimulateStream(in *heatpb.SimulateRequest, stream grpc.ServerStreamingServer[heatpb.TimeLevel]) error {
+	res, stride, err := g.run(stream.Context(), in)
+	if err != nil {
+		return err
+	}
+	defer res.Release()
+	for _, n := range levelIndices(res.Nt, stride) {
+		if err := stream.Send(timeLevel(res, n)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (g *grpcService) ListMethods(ctx context.Context, in *heatpb.ListMethodsRequest) (*heatpb.ListMethodsResponse, error) {
+	out := &heatpb.ListMethodsResponse{}
+	for _, m := range solver.Registered() {
+		out.Methods = append(out.Methods, &heatpb.MethodInfo{
+			Name:           m.Name,
+			Aliases:        m.Aliases,
+			Description:    m.Description,
+			Explicit:       m.Explicit,
+			StabilityBound: m.StabilityBound,
+			OrderTime:      uint32(m.OrderTime),
+			OrderSpace:     uint32(m.OrderSpace),
+		})
+	}
+	return out, nil
+}
+
+// Те же классы ошибок, что и в HTTP, в канонических кодах gRPC
+func grpcError(err error) error {
+	_, body := classifyError(err)
+
+	var verr *config.ValidationError
+	var lerr *config.LimitError
+	var st *status.Status
+	switch {
+	case errors.As(err, &verr):
+		st = status.New(codes.InvalidArgument, err.Error())
+		st = withDetails(st, &errdetails.BadRequest{
+			FieldViolations: []*errdetails.BadRequest_FieldViolation{{Field: verr.Field, Description: verr.Message}},
+		})
+	case errors.As(err, &lerr):
+		st = status.New(codes.ResourceExhausted, err.Error())
+		st = withDetails(st, &errdetails.QuotaFailure{
+			Violations: []*errdetails.QuotaFailure_Violation{{Subject: lerr.Field, Description: err.Error()}},
+		})
+	case errors.Is(err, solver.ErrDiverged), errors.Is(err, errNoReference), errors.Is(err, errConflict):
+		st = status.New(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, errUnauthorized):
+		st = status.New(codes.Unauthenticated, err.Error())
+	case errors.Is(err, errForbidden):
+		st = status.New(codes.PermissionDenied, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		st = status.New(codes.DeadlineExceeded, body.Message)
+	case errors.Is(err, context.Canceled):
+		st = status.New(codes.Canceled, err.Error())
+	default:
+		st = status.New(codes.Internal, body.Message)
+	}
+	return withDetails(st, &errdetails.ErrorInfo{Reason: body.Code, Domain: "heat-solver"}).Err()
+}
+
+func withDetails(st *status.Status, details ...protoadapt.MessageV1) *status.Status {
+	if d, err := st.WithDetails(details...); err == nil {
+		return d
+	}
+	return st
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net"
+	"slices"
+	"testing"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"heat-solver/internal/heatpb"
+	"heat-solver/internal/solver"
+)
+
+// Клиент gRPC-сервиса s через bufconn, без сетевого порта
+func newTestGRPCClient(t *testing.T, s *server) heatpb.HeatSolverClient {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	gs := s.newGRPCServer()
+	go gs.Serve(lis)
+	t.Cleanup(gs.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return heatpb.NewHeatSolverClient(conn)
+}
+
+// Unary и потоковый вызовы дают те же числа, что и HTTP
+func TestGRPCMatchesHTTP(t *testing.T) {
+	s, ts := newTestServer(t, nil)
+	client := newTestGRPCClient(t, s)
+	ctx := context.Background()
+	in := &heatpb.SimulateRequest{Method: "CN", Dx: 0.1, Dt: 0.01, Tmax: 0.1, Stride: 3}
+
+	_, body := get(t, ts, "/api/v1/simulate?method=CN&dx=0.1&dt=0.01&tmax=0.1&stride=3", 200)
+	var want simulateResult
+	decode(t, body, &want)
+
+	out, err := client.Simulate(ctx, in)
+	if err != nil {
+		t.Fatalf("Simulate: %v", err)
+	}
+	if out.Method != want.Method || int(out.Nx) != want.Nx || int(out.Nt) != want.Nt || out.Dx != want.Dx || out.Dt != want.Dt {
+		t.Errorf("grid %s nx=%d nt=%d dx=%g dt=%g, HTTP %s nx=%d nt=%d dx=%g dt=%g",
+			out.Method, out.Nx, out.Nt, out.Dx, out.Dt, want.Method, want.Nx, want.Nt, want.Dx, want.Dt)
+	}
+	if !slices.Equal(out.X, want.X) {
+		t.Errorf("x = %v, HTTP %v", out.X, want.X)
+	}
+	if len(out.Levels) != len(want.U) {
+		t.Fatalf("%d levels, HTTP %d", len(out.Levels), len(want.U))
+	}
+	for k, level := range out.Levels {
+		if level.T != want.T[k] || !slices.Equal(level.U, want.U[k]) {
+			t.Errorf("level %d (t=%g) differs from HTTP (t=%g)", k, level.T, want.T[k])
+		}
+	}
+	if out.Norms.GetL2() != want.Norms.L2 || out.Norms.GetLinf() != want.Norms.LInf {
+		t.Errorf("norms %v, HTTP %+v", out.Norms, want.Norms)
+	}
+
+	stream, err := client.SimulateStream(ctx, in)
+	if err != nil {
+		t.Fatalf("SimulateStream: %v", err)
+	}
+	k := 0
+	for ; ; k++ {
+		level, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Recv: %v", err)
+		}
+		if k >= len(out.Levels) || level.Index != out.Levels[k].Index || !slices.Equal(level.U, out.Levels[k].U) {
+			t.Fatalf("streamed level %d (index %d) differs from the unary response", k, level.Index)
+		}
+	}
+	if k != len(out.Levels) {
+		t.Errorf("streamed %d levels, want %d", k, len(out.Levels))
+	}
+
+	methods, err := client.ListMethods(ctx, &heatpb.ListMethodsRequest{})
+	if err != nil {
+		t.Fatalf("ListMethods: %v", err)
+	}
+	var names []string
+	for _, m := range methods.Methods {
+		names = append(names, m.Name)
+	}
+	if !slices.Equal(names, solver.Methods()) {
+		t.Errorf("ListMethods = %v, want %v", names, solver.Methods())
+	}
+}
+
+func TestGRPCErrors(t *testing.T) {
+	s, _ := newTestServer(t, func(cfg *serverConfig) {
+		cfg.Limits.MaxNx = 500
+		cfg.APIKeys = []string{keyEntry("ci", "secret", false)}
+	})
+	client := newTestGRPCClient(t, s)
+	authed := metadata.AppendToOutgoingContext(context.Background(), "x-api-key", "secret")
+	tests := []struct {
+		name   string
+		ctx    context.Context
+		in     *heatpb.SimulateRequest
+		code   codes.Code
+		reason string
+		field  string
+	}{
+		{"ok", authed, &heatpb.SimulateRequest{Dx: 0.1, Tmax: 0.01}, codes.OK, "", ""},
+		{"invalid", authed, &heatpb.SimulateRequest{Method: "NOPE"}, codes.InvalidArgument, codeInvalidParameter, "method"},
+		{"limit", authed, &heatpb.SimulateRequest{Dx: 0.001}, codes.ResourceExhausted, codeLimitExceeded, ""},
+		{"no key", context.Background(), &heatpb.SimulateRequest{}, codes.Unauthenticated, codeUnauthorized, ""},
+		{"wrong key", metadata.AppendToOutgoingContext(context.Background(), "x-api-key", "guess"), &heatpb.SimulateRequest{}, codes.Unauthenticated, codeUnauthorized, ""},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := client.Simulate(tc.ctx, tc.in)
+			st := status.Convert(err)
+			if st.Code() != tc.code {
+				t.Fatalf("code %v (%v), want %v", st.Code(), err, tc.code)
+			}
+			if tc.code == codes.OK {
+				return
+			}
+			var reason, field string
+			for _, d := range st.Details() {
+				switch d := d.(type) {
+				case *errdetails.ErrorInfo:
+					reason = d.Reason
+				case *errdetails.BadRequest:
+					field = d.FieldViolations[0].Field
+				}
+			}
+			if reason != tc.reason || field != tc.field {
+				t.Errorf("details reason %q field %q, want %q %q", reason, field, tc.reason, tc.field)
+			}
+		})
+	}
+
+	// Список методов открыт и без ключа
+	if _, err := client.ListMethods(context.Background(), &heatpb.ListMethodsRequest{}); err != nil {
+		t.Errorf("ListMethods without a key: %v", err)
+	}
+}
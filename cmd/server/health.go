@@ -0,0 +1,69 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"runtime/debug"
+
+	"heat-solver/internal/solver"
+)
+
+// Процесс обслуживает запросы — всегда 200
+func (s *server) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Write([]byte("ok\n"))
+}
+
+// 503 до окончания инициализации и во время остановки
+func (s *server) handleReadyz(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	if !s.ready.Load() {
+		// Балансировщику — текст, как описано в OpenAPI, а не JSON-конверт jsonErrors
+		if rw, ok := w.(*errorRewriter); ok {
+			w = rw.ResponseWriter
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte("not ready\n"))
+		return
+	}
+	w.Write([]byte("ready\n"))
+}
+
+type versionInfo struct {
+	Module    string   `json:"module"`
+	Version   string   `json:"version"`
+	GoVersion string   `json:"go_version"`
+	Revision  string   `json:"revision,omitempty"`
+	BuildTime string   `json:"build_time,omitempty"`
+	Modified  bool     `json:"modified,omitempty"`
+	Methods   []string `json:"methods"`
+}
+
+// Сведения о сборке из runtime/debug.BuildInfo
+func readVersionInfo() versionInfo {
+	info := versionInfo{Version: "unknown", Methods: solver.Methods()}
+
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+	info.Module = bi.Main.Path
+	info.Version = bi.Main.Version
+	info.GoVersion = bi.GoVersion
+	for _, s := range bi.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			info.Revision = s.Value
+		case "vcs.time":
+			info.BuildTime = s.Value
+		case "vcs.modified":
+			info.Modified = s.Value == "true"
+		}
+	}
+	return info
+}
+
+func (s *server) handleVersion(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s.version)
+}
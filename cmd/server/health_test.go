@@ -0,0 +1,82 @@
+package main
+
+import (
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+
+	"heat-solver/internal/solver"
+)
+
+func TestServiceEndpoints(t *testing.T) {
+	s, ts := newTestServer(t, nil)
+	tests := []struct {
+		path   string
+		ready  bool
+		status int
+		body   string
+	}{
+		{"/healthz", true, 200, "ok\n"},
+		{"/readyz", true, 200, "ready\n"},
+		{"/healthz", false, 200, "ok\n"},
+		{"/readyz", false, 503, "not ready\n"},
+	}
+	for _, tc := range tests {
+		s.ready.Store(tc.ready)
+		if _, body := get(t, ts, tc.path, tc.status); string(body) != tc.body {
+			t.Errorf("%s (ready=%v): body %q, want %q", tc.path, tc.ready, body, tc.body)
+		}
+	}
+	s.ready.Store(true)
+
+	_, body := get(t, ts, "/version", 200)
+	var v versionInfo
+	decode(t, body, &v)
+	if v.Version == "" || v.GoVersion == "" {
+		t.Errorf("version info without version or Go version: %+v", v)
+	}
+	if !slices.Equal(v.Methods, solver.Methods()) {
+		t.Errorf("methods = %v, want %v", v.Methods, solver.Methods())
+	}
+}
+
+// Служебные маршруты не требуют ключа даже при включённой проверке
+func TestServiceEndpointsBypassAuth(t *testing.T) {
+	_, ts := newTestServer(t, func(cfg *serverConfig) {
+		cfg.APIKeys = []string{keyEntry("ci", "secret", false)}
+	})
+	for _, path := range []string{"/healthz", "/readyz", "/version", "/metrics"} {
+		get(t, ts, path, 200)
+	}
+	get(t, ts, "/api/v1/simulate", 401)
+}
+
+// Во время остановки /readyz отвечает 503, а /healthz — 200, пока
+// слушатель не закрыт; после закрытия соединения не принимаются
+func TestShutdownDrain(t *testing.T) {
+	s, ts := newTestServer(t, nil)
+	const drain = 300 * time.Millisecond
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.shutdown(ts.Config, nil, drain, time.Second)
+	}()
+
+	deadline := time.Now().Add(drain / 2)
+	for s.ready.Load() {
+		if time.Now().After(deadline) {
+			t.Fatal("readiness still on after shutdown started")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	get(t, ts, "/readyz", 503)
+	get(t, ts, "/healthz", 200)
+
+	<-done
+	resp, err := http.Get(ts.URL + "/healthz")
+	if err == nil {
+		resp.Body.Close()
+		t.Fatalf("/healthz after shutdown: status %d, want a refused connection", resp.StatusCode)
+	}
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image/png"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"heat-solver/internal/config"
+	"heat-solver/internal/render"
+)
+
+// Ограничения на размер выходных изображений
+const (
+	maxImageSide   = 4096
+	maxImagePixels = 4 << 20
+)
+
+// Размер изображения из запроса с проверкой ограничений
+func imageSize(q url.Values, defW, defH int) (int, int, error) {
+	w, err := intParam(q, "width", defW)
+	if err != nil || w < 1 || w > maxImageSide {
+		return 0, 0, paramError("width", fmt.Sprintf("must be in [1, %d]", maxImageSide))
+	}
+	h, err := intParam(q, "height", defH)
+	if err != nil || h < 1 || h > maxImageSide {
+		return 0, 0, paramError("height", fmt.Sprintf("must be in [1, %d]", maxImageSide))
+	}
+	if w*h > maxImagePixels {
+		return 0, 0, &config.LimitError{Field: "pixels", Limit: maxImagePixels, Value: int64(w * h)}
+	}
+	return w, h, nil
+}
+
+// Границы цветовой шкалы vmin/vmax (обе или ни одной)
+func valueRange(q url.Values) (float64, float64, error) {
+	if q.Get("vmin") == "" && q.Get("vmax") == "" {
+		return 0, 0, nil
+	}
+	vmin, err1 := strconv.ParseFloat(q.Get("vmin"), 64)
+	vmax, err2 := strconv.ParseFloat(q.Get("vmax"), 64)
+	if err1 != nil || err2 != nil || !(vmin < vmax) {
+		return 0, 0, paramError("vmin", "vmin and vmax are both required with vmin < vmax")
+	}
+	return vmin, vmax, nil
+}
+
+// Параметры тепловой карты из запроса: width, height, colormap, vmin, vmax
+func heatmapOptions(q url.Values) (render.HeatmapOptions, error) {
+	width, height, err := imageSize(q, 800, 400)
+	if err != nil {
+		return render.HeatmapOptions{}, err
+	}
+	cmName := q.Get("colormap")
+	if cmName == "" {
+		cmName = "hot"
+	}
+	cm, err := render.LookupColormap(cmName)
+	if err != nil {
+		return render.HeatmapOptions{}, paramError("colormap", err.Error())
+	}
+	vmin, vmax, err := valueRange(q)
+	if err != nil {
+		return render.HeatmapOptions{}, err
+	}
+	return render.HeatmapOptions{Width: width, Height: height, Colormap: cm, Vmin: vmin, Vmax: vmax}, nil
+}
+
+// Тепловая карта пространство–время в PNG
+func (s *server) handleSimulatePNG(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	opts, err := heatmapOptions(q)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	params, err := parseParams(q)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	// Картинке нужна вся история, storage=final-only здесь не применяется
+	params.Storage = config.StorageFull
+	ctx, cancel := context.WithTimeout(r.Context(), s.timeout)
+	defer cancel()
+	res, hit, err := s.simulate(ctx, params)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	defer res.Release()
+	setCacheHeader(w, hit)
+
+	opts.Banner = res.Diverged
+	img := render.Heatmap(res.Rows(), opts)
+	if res.Diverged {
+		w.Header().Set("X-Diverged", "true")
+	}
+
+	w.Header().Set("Content-Type", "image/png")
+	png.Encode(w, img)
+}
+
+// Ограничения анимации
+const (
+	maxGIFFrames = 1000
+	maxGIFPixels = 200 << 20
+)
+
+// Запись в ответ с отправкой клиенту после каждого кадра
+type flushWriter struct {
+	w  io.Writer
+	rc *http.ResponseController
+}
+
+func (f flushWriter) Write(p []byte) (int, error) {
+	n, err := f.w.Write(p)
+	if err == nil {
+		f.rc.Flush()
+	}
+	return n, err
+}
+
+// Анимация профиля в GIF; кадры отправляются по мере кодирования
+func (s *server) handleSimulateGIF(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	width, height, err := imageSize(q, 400, 200)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	stride, err := intParam(q, "stride", 10)
+	if err != nil || stride < 1 {
+		writeError(w, r, paramError("stride", "must be a positive integer"))
+		return
+	}
+	delay, err := intParam(q, "delay", 5)
+	if err != nil || delay < 0 || delay > 1000 {
+		writeError(w, r, paramError("delay", "must be in [0, 1000] hundredths of a second"))
+		return
+	}
+
+	params, err := parseParams(q)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	// Картинке нужна вся история, storage=final-only здесь не применяется
+	params.Storage = config.StorageFull
+	_, nt := params.Grid()
+	frames := render.FrameCount(nt+1, stride)
+	if frames > maxGIFFrames {
+		writeError(w, r, &config.LimitError{Field: "frames", Limit: maxGIFFrames, Value: int64(frames)})
+		return
+	}
+	if frames*width*height > maxGIFPixels {
+		writeError(w, r, &config.LimitError{Field: "pixels", Limit: maxGIFPixels, Value: int64(frames * width * height)})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), s.timeout)
+	defer cancel()
+
+	key := fmt.Sprintf("gif|%s|w=%d|h=%d|stride=%d|delay=%d", cacheKey(params), width, height, stride, delay)
+	if v, ok := s.cache.Get(key); ok {
+		s.metrics.cacheHits.Inc()
+		setCacheHeader(w, true)
+		w.Header().Set("Content-Type", "image/gif")
+		w.Write(v.([]byte))
+		return
+	}
+
+	res, hit, err := s.simulate(ctx, params)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	defer res.Release()
+	setCacheHeader(w, hit)
+	w.Header().Set("Content-Type", "image/gif")
+
+	// Копия потока для кэша; ответ при этом не буферизуется
+	var encoded bytes.Buffer
+	out := io.MultiWriter(flushWriter{w: w, rc: http.NewResponseController(w)}, &encoded)
+	err = render.WriteAnimation(ctx, out, res.Rows(), render.AnimationOptions{
+		Width:  width,
+		Height: height,
+		Stride: stride,
+		Delay:  delay,
+	})
+	if err != nil {
+		// Заголовки уже отправлены — остаётся только оборвать поток
+		slog.WarnContext(ctx, "GIF encoding aborted", "error", err)
+		return
+	}
+	s.cache.Add(key, encoded.Bytes(), int64(encoded.Len()), res.Diverged)
+}
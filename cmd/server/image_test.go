@@ -0,0 +1,148 @@
+package main
+
+import (
+	"bytes"
+	"image"
+	"image/gif"
+	"image/png"
+	"testing"
+
+	"heat-solver/internal/render"
+)
+
+func TestSimulatePNG(t *testing.T) {
+	_, ts := newTestServer(t, nil)
+	const width, height = 200, 100
+	resp, body := get(t, ts, "/api/v1/simulate.png?method=CN&dx=0.02&dt=0.001&tmax=0.2&width=200&height=100", 200)
+	if ct := resp.Header.Get("Content-Type"); ct != "image/png" {
+		t.Fatalf("Content-Type = %q, want image/png", ct)
+	}
+	img, err := png.Decode(bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("png.Decode: %v", err)
+	}
+	if b := img.Bounds(); b.Dx() != width || b.Dy() != height {
+		t.Fatalf("image %dx%d, want %dx%d", b.Dx(), b.Dy(), width, height)
+	}
+
+	// Нижняя строка — t = 0; у карты hot самый горячий пиксель самый яркий
+	hottest, best := -1, -1
+	for x := 0; x < width; x++ {
+		r, g, b, _ := img.At(x, height-1).RGBA()
+		if v := int(r + g + b); v > best {
+			hottest, best = x, v
+		}
+	}
+	if hottest < width*4/10 || hottest > width*6/10 {
+		t.Errorf("hottest early-time pixel at x = %d of %d, want near mid-domain", hottest, width)
+	}
+
+	// Тот же расчёт уже в кэше после JSON-запроса
+	get(t, ts, "/api/v1/simulate?method=CN&dx=0.01&dt=0.001&tmax=0.2", 200)
+	resp, _ = get(t, ts, "/api/v1/simulate.png?method=CN&dx=0.01&dt=0.001&tmax=0.2", 200)
+	if got := resp.Header.Get("X-Cache"); got != "HIT" {
+		t.Errorf("PNG after the same JSON request: X-Cache = %q, want HIT", got)
+	}
+}
+
+// Разошедшийся расчёт: 200 с ячейками NaN/Inf цвета-метки и полосой сверху
+func TestSimulatePNGDiverged(t *testing.T) {
+	_, ts := newTestServer(t, nil)
+	resp, body := get(t, ts, "/api/v1/simulate.png?method=FTCS&dx=0.05&dt=0.01&tmax=10&width=100&height=100", 200)
+	if resp.Header.Get("X-Diverged") != "true" {
+		t.Error("X-Diverged header missing")
+	}
+	img, err := png.Decode(bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("png.Decode: %v", err)
+	}
+	nan := 0
+	for y := 0; y < 100; y++ {
+		for x := 0; x < 100; x++ {
+			r, g, b, a := img.At(x, y).RGBA()
+			nr, ng, nb, na := render.NaNColor.RGBA()
+			if r == nr && g == ng && b == nb && a == na {
+				nan++
+			}
+		}
+	}
+	if nan == 0 {
+		t.Error("no NaN-colored cells in a diverged run")
+	}
+}
+
+func TestSimulatePNGLimits(t *testing.T) {
+	_, ts := newTestServer(t, nil)
+	tests := []struct {
+		query  string
+		status int
+	}{
+		{"width=0", 400},
+		{"height=5000", 400},
+		{"width=4096&height=4096", 413},
+		{"colormap=nope", 400},
+		{"vmin=1", 400},
+		{"vmin=1&vmax=0", 400},
+		{"vmin=0&vmax=1&colormap=viridis", 200},
+	}
+	for _, tc := range tests {
+		get(t, ts, "/api/v1/simulate.png?dx=0.1&tmax=0.01&"+tc.query, tc.status)
+	}
+}
+
+func TestSimulateGIF(t *testing.T) {
+	_, ts := newTestServer(t, nil)
+	const width, height, stride, delay = 120, 60, 10, 7
+	resp, body := get(t, ts, "/api/v1/simulate.gif?method=BTCS&dx=0.05&dt=0.001&tmax=0.05&width=120&height=60&stride=10&delay=7", 200)
+	if ct := resp.Header.Get("Content-Type"); ct != "image/gif" {
+		t.Fatalf("Content-Type = %q, want image/gif", ct)
+	}
+	if !bytes.HasPrefix(body, []byte("GIF89a")) {
+		t.Fatalf("body starts with %q, want a GIF89a header", body[:min(6, len(body))])
+	}
+	g, err := gif.DecodeAll(bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("gif.DecodeAll: %v", err)
+	}
+	if want := render.FrameCount(51, stride); len(g.Image) != want {
+		t.Errorf("%d frames, want %d", len(g.Image), want)
+	}
+	for i, frame := range g.Image {
+		if frame.Bounds() != image.Rect(0, 0, width, height) {
+			t.Fatalf("frame %d bounds %v, want %dx%d", i, frame.Bounds(), width, height)
+		}
+		if n := len(frame.Palette); n == 0 || n > 256 {
+			t.Fatalf("frame %d: palette of %d colors", i, n)
+		}
+		for _, idx := range frame.Pix {
+			if int(idx) >= len(frame.Palette) {
+				t.Fatalf("frame %d: pixel index %d outside the palette of %d", i, idx, len(frame.Palette))
+			}
+		}
+		if g.Delay[i] != delay {
+			t.Errorf("frame %d: delay %d, want %d", i, g.Delay[i], delay)
+		}
+	}
+
+	resp, again := get(t, ts, "/api/v1/simulate.gif?method=BTCS&dx=0.05&dt=0.001&tmax=0.05&width=120&height=60&stride=10&delay=7", 200)
+	if resp.Header.Get("X-Cache") != "HIT" || !bytes.Equal(again, body) {
+		t.Errorf("repeated GIF: X-Cache = %q, identical body %v; want a cached copy", resp.Header.Get("X-Cache"), bytes.Equal(again, body))
+	}
+}
+
+func TestSimulateGIFLimits(t *testing.T) {
+	_, ts := newTestServer(t, nil)
+	tests := []struct {
+		query  string
+		status int
+	}{
+		{"dt=0.01&stride=0", 400},
+		{"dt=0.01&delay=-1", 400},
+		{"dt=0.01&delay=1001", 400},
+		{"dt=0.0001&tmax=1&stride=1", 413},
+		{"dt=0.01&tmax=1&stride=1&width=4096&height=4096", 413},
+	}
+	for _, tc := range tests {
+		get(t, ts, "/api/v1/simulate.gif?dx=0.1&"+tc.query, tc.status)
+	}
+}
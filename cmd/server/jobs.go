@@ -0,0 +1,477 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"heat-solver/internal/config"
+	heatio "heat-solver/internal/io"
+	"heat-solver/internal/mathutils"
+	"heat-solver/internal/metrics"
+	"heat-solver/internal/render"
+	"heat-solver/internal/solver"
+)
+
+var errJobNotReady = errors.New("job result not available")
+
+// Фоновые задачи: очередь с ограниченным числом одновременных расчётов,
+// индекс в памяти поверх хранилища и периодическая очистка по возрасту и объёму
+type jobManager struct {
+	store    jobStore
+	run      func(ctx context.Context, p config.Params) (*solver.Result, error)
+	timeout  time.Duration
+	maxAge   time.Duration
+	maxBytes int64
+	queued   *metrics.Gauge
+
+	mu   sync.Mutex
+	jobs map[string]*jobMeta
+	sem  chan struct{}
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+type jobConfig struct {
+	Workers  int      `json:"workers"`
+	Timeout  duration `json:"timeout"`
+	MaxAge   duration `json:"max_age"`
+	MaxBytes int64    `json:"max_bytes"`
+}
+
+// Загрузка существующих задач. Незавершённые к моменту остановки помечаются
+// как проваленные: их расчёт был потерян вместе с процессом
+func newJobManager(store jobStore, cfg jobConfig, run func(context.Context, config.Params) (*solver.Result, error), queued *metrics.Gauge) (*jobManager, error) {
+	metas, err := store.List()
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	m := &jobManager{
+		store:    store,
+		run:      run,
+		timeout:  time.Duration(cfg.Timeout),
+		maxAge:   time.Duration(cfg.MaxAge),
+		maxBytes: cfg.MaxBytes,
+		queued:   queued,
+		jobs:     make(map[string]*jobMeta, len(metas)),
+		sem:      make(chan struct{}, max(1, cfg.Workers)),
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+
+	interrupted := 0
+	for _, meta := range metas {
+		if !meta.finished() {
+			now := time.Now().UTC()
+			meta.Status = jobFailed
+			meta.Error = "interrupted by server restart"
+			meta.FinishedAt = &now
+			if err := store.Put(meta); err != nil {
+				cancel()
+				return nil, err
+			}
+			interrupted++
+		}
+		m.jobs[meta.ID] = &meta
+	}
+	slog.Info("Job store loaded", "jobs", len(metas), "interrupted", interrupted)
+	return m, nil
+}
+
+// Копия метаданных задачи
+func (m *jobManager) get(id string) (jobMeta, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	j, ok := m.jobs[id]
+	if !ok {
+		return jobMeta{}, false
+	}
+	return *j, true
+}
+
+// Изменение задачи под блокировкой и сохранение в хранилище
+func (m *jobManager) update(id string, change func(j *jobMeta)) {
+	m.mu.Lock()
+	j, ok := m.jobs[id]
+	if !ok {
+		m.mu.Unlock()
+		return
+	}
+	change(j)
+	meta := *j
+	m.mu.Unlock()
+
+	if err := m.store.Put(meta); err != nil {
+		slog.Error("Cannot save job", "job", id, "error", err)
+	}
+}
+
+func (m *jobManager) submit(p config.Params) (jobMeta, error) {
+	nx, nt := p.Grid()
+	meta := jobMeta{
+		ID:        newRequestID(),
+		Status:    jobQueued,
+		Method:    p.Method,
+		Dx:        p.Dx,
+		Dt:        p.Dt,
+		Tmax:      p.Tmax,
+		Nx:        nx,
+		Nt:        nt,
+		CustomIC:  p.ICSamples != nil,
+		IC:        p.IC,
+		Lambda:    p.Lambda,
+		Source:    p.Source,
+		CreatedAt: time.Now().UTC(),
+	}
+	if err := m.store.Put(meta); err != nil {
+		return meta, err
+	}
+	m.mu.Lock()
+	m.jobs[meta.ID] = &meta
+	m.mu.Unlock()
+
+	m.queued.Add(1)
+	m.wg.Add(1)
+	go m.execute(meta.ID, p)
+	return meta, nil
+}
+
+func (m *jobManager) execute(id string, p config.Params) {
+	defer m.wg.Done()
+
+	release, err := m.acquire(m.ctx)
+	m.queued.Add(-1)
+	if err != nil {
+		m.fail(id, errors.New("server shut down before the job started"))
+		return
+	}
+	defer release()
+
+	m.update(id, func(j *jobMeta) {
+		now := time.Now().UTC()
+		j.Status = jobRunning
+		j.StartedAt = &now
+	})
+
+	ctx, cancel := context.WithTimeout(m.ctx, m.timeout)
+	defer cancel()
+	res, err := m.run(ctx, p)
+	if err != nil {
+		m.fail(id, err)
+		return
+	}
+	defer res.Release()
+
+	size, err := m.store.WriteResult(id, func(w io.Writer) error {
+		// В final-only два слоя: записываются как один шаг nt·dt
+		return heatio.WriteBinary(w, res.Rows(), res.Dx, res.Dt*float64(res.LevelStep()))
+	})
+	if err != nil {
+		m.fail(id, fmt.Errorf("saving result: %w", err))
+		return
+	}
+	m.update(id, func(j *jobMeta) {
+		now := time.Now().UTC()
+		j.Status = jobDone
+		j.Diverged = res.Diverged
+		j.RuntimeSec = res.Runtime.Seconds()
+		j.ResultBytes = size
+		j.FinishedAt = &now
+	})
+}
+
+// Место в пуле расчётов, общем для задач и пакетов (/batch).
+// Ошибка — если ctx отменён или сервер останавливается раньше
+func (m *jobManager) acquire(ctx context.Context) (release func(), err error) {
+	select {
+	case m.sem <- struct{}{}:
+		return func() { <-m.sem }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-m.ctx.Done():
+		return nil, m.ctx.Err()
+	}
+}
+
+func (m *jobManager) fail(id string, err error) {
+	slog.Warn("Job failed", "job", id, "error", err)
+	m.update(id, func(j *jobMeta) {
+		now := time.Now().UTC()
+		j.Status = jobFailed
+		j.Error = err.Error()
+		j.FinishedAt = &now
+	})
+}
+
+// Удаление завершённых задач старше maxAge, затем самых старых, пока
+// суммарный объём результатов больше maxBytes
+func (m *jobManager) sweep(now time.Time) {
+	m.mu.Lock()
+	var finished []*jobMeta
+	for _, j := range m.jobs {
+		if j.finished() && j.FinishedAt != nil {
+			finished = append(finished, j)
+		}
+	}
+	slices.SortFunc(finished, func(a, b *jobMeta) int { return a.FinishedAt.Compare(*b.FinishedAt) })
+
+	var total int64
+	for _, j := range finished {
+		total += j.ResultBytes
+	}
+	var expired []string
+	for _, j := range finished {
+		tooOld := m.maxAge > 0 && now.Sub(*j.FinishedAt) > m.maxAge
+		tooBig := m.maxBytes > 0 && total > m.maxBytes
+		if !tooOld && !tooBig {
+			continue
+		}
+		total -= j.ResultBytes
+		expired = append(expired, j.ID)
+		delete(m.jobs, j.ID)
+	}
+	m.mu.Unlock()
+
+	for _, id := range expired {
+		if err := m.store.Delete(id); err != nil {
+			slog.Error("Cannot delete expired job", "job", id, "error", err)
+		}
+	}
+	if len(expired) > 0 {
+		slog.Info("Expired jobs removed", "count", len(expired))
+	}
+}
+
+func (m *jobManager) sweepEvery(interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case now := <-t.C:
+			m.sweep(now)
+		case <-m.ctx.Done():
+			return
+		}
+	}
+}
+
+// Отмена ожидающих задач и ожидание выполняющихся, пока не истёк ctx.
+// Не дождавшиеся задачи останутся "running" и при запуске станут failed
+func (m *jobManager) close(ctx context.Context) {
+	m.cancel()
+	done := make(chan struct{})
+	go func() {
+		m.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		slog.Warn("Shutdown timeout: abandoning running jobs")
+	}
+}
+
+// Идентификаторы задач — 16 шестнадцатеричных цифр (см. newRequestID)
+func validJobID(id string) bool {
+	if len(id) != 16 {
+		return false
+	}
+	for _, c := range id {
+		if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *server) job(r *http.Request) (jobMeta, error) {
+	id := r.PathValue("id")
+	if validJobID(id) {
+		if meta, ok := s.jobs.get(id); ok {
+			return meta, nil
+		}
+	}
+	return jobMeta{}, fmt.Errorf("job %q: %w", id, errNotFound)
+}
+
+func (s *server) handleSubmitJob(w http.ResponseWriter, r *http.Request) {
+	req, err := decodeSimulateRequest(w, r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	params, err := req.params()
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if err := s.limits.Check(params); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	meta, err := s.jobs.submit(params)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.Header().Set("Location", "/api/v1/jobs/"+meta.ID)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(meta)
+}
+
+func (s *server) handleGetJob(w http.ResponseWriter, r *http.Request) {
+	meta, err := s.job(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(meta)
+}
+
+// Результат читается из хранилища построчно и сразу пишется в ответ;
+// формат выбирается по Accept, как у /simulate
+func (s *server) handleJobResult(w http.ResponseWriter, r *http.Request) {
+	media, err := negotiate(w, r, resultMedia)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	meta, err := s.job(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if meta.Status != jobDone {
+		msg := "job is " + meta.Status
+		if meta.Error != "" {
+			msg += ": " + meta.Error
+		}
+		writeError(w, r, fmt.Errorf("%w: %s", errJobNotReady, msg))
+		return
+	}
+	q := r.URL.Query()
+	stride, err := intParam(q, "stride", 1)
+	if err != nil || stride < 1 {
+		writeError(w, r, paramError("stride", "must be a positive integer"))
+		return
+	}
+	var image render.HeatmapOptions
+	if media == mediaPNG {
+		if image, err = heatmapOptions(q); err != nil {
+			writeError(w, r, err)
+			return
+		}
+	}
+	// Эталон у задач есть только для предустановок: выборка не сохраняется
+	var exact mathutils.Reference
+	if media == mediaCSV {
+		exact, err = csvReference(q, func() mathutils.Reference {
+			if meta.CustomIC {
+				return nil
+			}
+			return solver.PresetReference(config.Params{IC: meta.IC, Lambda: meta.Lambda, Source: meta.Source})
+		})
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+	}
+
+	f, err := s.jobs.store.OpenResult(meta.ID)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	defer f.Close()
+	br, err := heatio.NewBinaryReader(f)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	hdr := br.Header
+	levels := levelIndices(hdr.Nt, stride)
+	source := storedLevels(br, levels)
+
+	if media != mediaJSON {
+		writeLevelsResponse(w, r, media, hdr.Dx, 64, exact, meta.Diverged, image, source)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	bw := bufio.NewWriterSize(w, 64<<10)
+	buf := make([]byte, 0, 64<<10)
+
+	buf = append(buf, `{"id":`...)
+	buf = strconv.AppendQuote(buf, meta.ID)
+	buf = append(buf, `,"method":`...)
+	buf = strconv.AppendQuote(buf, meta.Method)
+	buf = fmt.Appendf(buf, `,"nx":%d,"nt":%d,"dx":%s,"dt":%s,"stride":%d,"diverged":%t,"t":[`,
+		hdr.Nx, hdr.Nt, strconv.FormatFloat(hdr.Dx, 'g', -1, 64), strconv.FormatFloat(hdr.Dt, 'g', -1, 64),
+		stride, meta.Diverged)
+	for k, n := range levels {
+		if k > 0 {
+			buf = append(buf, ',')
+		}
+		buf = strconv.AppendFloat(buf, float64(n)*hdr.Dt, 'g', -1, 64)
+	}
+	buf = append(buf, `],"u":[`...)
+
+	first := true
+	err = source(func(_ float64, row []float64) error {
+		if !first {
+			buf = append(buf, ',')
+		}
+		first = false
+		buf = heatio.AppendJSONRow(buf, row, 64)
+		_, err := bw.Write(buf)
+		buf = buf[:0]
+		return err
+	})
+	if err != nil {
+		// Часть ответа уже отправлена — остаётся только оборвать поток
+		slog.ErrorContext(r.Context(), "Job result streaming aborted", "job", meta.ID, "error", err)
+		return
+	}
+	buf = append(buf, "]}\n"...)
+	bw.Write(buf)
+	bw.Flush()
+}
+
+// Слои levels (по возрастанию) из двоичного результата; остальные пропускаются
+func storedLevels(br *heatio.BinaryReader, levels []int) levelSource {
+	return func(yield func(t float64, row []float64) error) error {
+		row := make([]float64, br.Header.Nx+1)
+		next := 0
+		for n := 0; n <= br.Header.Nt && next < len(levels); n++ {
+			if n != levels[next] {
+				if err := br.SkipRow(); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := br.ReadRow(row); err != nil {
+				return err
+			}
+			if err := yield(float64(n)*br.Header.Dt, row); err != nil {
+				return err
+			}
+			next++
+		}
+		return nil
+	}
+}
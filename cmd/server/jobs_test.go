@@ -0,0 +1,139 @@
+package main
+
+import (
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+// Ожидание завершения задачи через GET /api/v1/jobs/{id}
+func waitJob(t *testing.T, ts *httptest.Server, id string) jobMeta {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		var meta jobMeta
+		_, body := get(t, ts, "/api/v1/jobs/"+id, 200)
+		decode(t, body, &meta)
+		if meta.finished() {
+			return meta
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("job %s still %s", id, meta.Status)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestJobLifecycle(t *testing.T) {
+	_, ts := newTestServer(t, nil)
+	resp, body := postJSON(t, ts, "/api/v1/jobs", `{"method":"CN","dx":0.1,"dt":0.01,"tmax":0.1}`, 202)
+	var meta jobMeta
+	decode(t, body, &meta)
+	if !validJobID(meta.ID) || resp.Header.Get("Location") != "/api/v1/jobs/"+meta.ID {
+		t.Fatalf("job id %q, Location %q", meta.ID, resp.Header.Get("Location"))
+	}
+	if meta = waitJob(t, ts, meta.ID); meta.Status != jobDone || meta.ResultBytes == 0 {
+		t.Fatalf("job %+v, want done with a stored result", meta)
+	}
+
+	_, body = get(t, ts, "/api/v1/jobs/"+meta.ID+"/result", 200)
+	var job, sim simulateResult
+	decode(t, body, &job)
+	_, body = get(t, ts, "/api/v1/simulate?method=CN&dx=0.1&dt=0.01&tmax=0.1", 200)
+	decode(t, body, &sim)
+	if !slices.Equal(job.T, sim.T) || !slices.EqualFunc(job.U, sim.U, slices.Equal) {
+		t.Error("job result differs from /api/v1/simulate with the same parameters")
+	}
+
+	get(t, ts, "/api/v1/jobs/0123456789abcdef", 404)
+	get(t, ts, "/api/v1/jobs/../etc", 404)
+	postJSON(t, ts, "/api/v1/jobs", `{"method":"NOPE"}`, 400)
+}
+
+// Перезапуск: второй сервер над тем же каталогом отдаёт готовый результат,
+// а задача, выполнявшаяся в момент сбоя, становится failed
+func TestJobStoreRestart(t *testing.T) {
+	dir := t.TempDir()
+	withDir := func(cfg *serverConfig) { cfg.DataDir = dir }
+
+	first, ts := newTestServer(t, withDir)
+	_, body := postJSON(t, ts, "/api/v1/jobs", `{"method":"BTCS","dx":0.05,"dt":0.01,"tmax":0.2}`, 202)
+	var done jobMeta
+	decode(t, body, &done)
+	waitJob(t, ts, done.ID)
+	_, before := get(t, ts, "/api/v1/jobs/"+done.ID+"/result?stride=5", 200)
+
+	crashed := jobMeta{ID: "00000000000000aa", Status: jobRunning, Method: "CN", CreatedAt: time.Now().UTC()}
+	if err := first.jobs.store.Put(crashed); err != nil {
+		t.Fatal(err)
+	}
+	// Остаток прерванной записи убирается при открытии хранилища
+	if err := os.WriteFile(filepath.Join(dir, jobTempPrefix+"partial"), []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	ts.Close()
+
+	_, ts = newTestServer(t, withDir)
+	_, after := get(t, ts, "/api/v1/jobs/"+done.ID+"/result?stride=5", 200)
+	if string(after) != string(before) {
+		t.Error("result after restart differs from the one served before")
+	}
+
+	var meta jobMeta
+	_, body = get(t, ts, "/api/v1/jobs/"+crashed.ID, 200)
+	decode(t, body, &meta)
+	if meta.Status != jobFailed || meta.Error == "" || meta.FinishedAt == nil {
+		t.Errorf("interrupted job %+v, want failed with an error", meta)
+	}
+	get(t, ts, "/api/v1/jobs/"+crashed.ID+"/result", 409)
+
+	if tmp, _ := filepath.Glob(filepath.Join(dir, jobTempPrefix+"*")); len(tmp) != 0 {
+		t.Errorf("temporary files left after restart: %v", tmp)
+	}
+}
+
+// Очистка: сначала по возрасту, затем самые старые сверх объёма
+func TestJobSweep(t *testing.T) {
+	now := time.Now().UTC()
+	ago := func(d time.Duration) *time.Time {
+		at := now.Add(-d)
+		return &at
+	}
+	store := newMemJobStore()
+	for _, meta := range []jobMeta{
+		{ID: "old", Status: jobDone, ResultBytes: 10, FinishedAt: ago(3 * time.Hour)},
+		{ID: "big", Status: jobDone, ResultBytes: 100, FinishedAt: ago(time.Hour)},
+		{ID: "new", Status: jobDone, ResultBytes: 100, FinishedAt: ago(time.Minute)},
+		{ID: "failed", Status: jobFailed, FinishedAt: ago(30 * time.Minute)},
+		{ID: "running", Status: jobRunning},
+	} {
+		if err := store.Put(meta); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// "running" при загрузке становится failed и не старше остальных;
+	// незавершённая задача добавляется в индекс уже после загрузки
+	m, err := newJobManager(store, jobConfig{MaxAge: duration(2 * time.Hour), MaxBytes: 150}, nil, newServerMetrics().jobQueue)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.close(t.Context())
+	m.jobs["queued"] = &jobMeta{ID: "queued", Status: jobQueued}
+
+	m.sweep(now)
+	var left []string
+	for id := range m.jobs {
+		left = append(left, id)
+	}
+	slices.Sort(left)
+	if want := []string{"failed", "new", "queued", "running"}; !slices.Equal(left, want) {
+		t.Errorf("jobs after sweep %v, want %v", left, want)
+	}
+	stored, _ := store.List()
+	if len(stored) != 3 {
+		t.Errorf("%d jobs left in the store, want 3", len(stored))
+	}
+}
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Состояния фоновой задачи
+const (
+	jobQueued  = "queued"
+	jobRunning = "running"
+	jobDone    = "done"
+	jobFailed  = "failed"
+)
+
+// Метаданные задачи; сохраняются в хранилище при каждой смене состояния
+type jobMeta struct {
+	ID          string     `json:"id"`
+	Status      string     `json:"status"`
+	Method      string     `json:"method"`
+	Dx          float64    `json:"dx"`
+	Dt          float64    `json:"dt"`
+	Tmax        float64    `json:"tmax"`
+	Nx          int        `json:"nx"`
+	Nt          int        `json:"nt"`
+	CustomIC    bool       `json:"custom_ic,omitempty"`
+	IC          string     `json:"ic,omitempty"`
+	Lambda      float64    `json:"lambda,omitempty"`
+	Source      string     `json:"source,omitempty"`
+	Diverged    bool       `json:"diverged"`
+	RuntimeSec  float64    `json:"runtime_sec,omitempty"`
+	Error       string     `json:"error,omitempty"`
+	ResultBytes int64      `json:"result_bytes,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	StartedAt   *time.Time `json:"started_at,omitempty"`
+	FinishedAt  *time.Time `json:"finished_at,omitempty"`
+}
+
+func (j *jobMeta) finished() bool {
+	return j.Status == jobDone || j.Status == jobFailed
+}
+
+// Хранилище задач: метаданные и решение в двоичном формате internal/io
+type jobStore interface {
+	Put(meta jobMeta) error
+	List() ([]jobMeta, error)
+	// Запись результата через write; возвращает размер записанного
+	WriteResult(id string, write func(io.Writer) error) (int64, error)
+	OpenResult(id string) (io.ReadCloser, error)
+	Delete(id string) error
+}
+
+// Хранилище в памяти: используется, когда каталог данных не задан
+type memJobStore struct {
+	mu      sync.Mutex
+	metas   map[string]jobMeta
+	results map[string][]byte
+}
+
+func newMemJobStore() *memJobStore {
+	return &memJobStore{metas: map[string]jobMeta{}, results: map[string][]byte{}}
+}
+
+func (m *memJobStore) Put(meta jobMeta) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.metas[meta.ID] = meta
+	return nil
+}
+
+func (m *memJobStore) List() ([]jobMeta, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := make([]jobMeta, 0, len(m.metas))
+	for _, meta := range m.metas {
+		out = append(out, meta)
+	}
+	return out, nil
+}
+
+func (m *memJobStore) WriteResult(id string, write func(io.Writer) error) (int64, error) {
+	var buf bytes.Buffer
+	if err := write(&buf); err != nil {
+		return 0, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.results[id] = buf.Bytes()
+	return int64(buf.Len()), nil
+}
+
+func (m *memJobStore) OpenResult(id string) (io.ReadCloser, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	b, ok := m.results[id]
+	if !ok {
+		return nil, fmt.Errorf("result of job %s: %w", id, fs.ErrNotExist)
+	}
+	return io.NopCloser(bytes.NewReader(b)), nil
+}
+
+func (m *memJobStore) Delete(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.metas, id)
+	delete(m.results, id)
+	return nil
+}
+
+// Хранилище в каталоге: <id>.json и <id>.bin, запись через временный файл
+// и rename, чтобы после сбоя не оставалось полузаписанных файлов
+type fsJobStore struct {
+	dir string
+}
+
+const jobTempPrefix = ".tmp-"
+
+func newFSJobStore(dir string) (*fsJobStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	// Остатки записей, прерванных сбоем
+	tmp, _ := filepath.Glob(filepath.Join(dir, jobTempPrefix+"*"))
+	for _, name := range tmp {
+		os.Remove(name)
+	}
+	return &fsJobStore{dir: dir}, nil
+}
+
+func (f *fsJobStore) path(id, ext string) string {
+	return filepath.Join(f.dir, id+ext)
+}
+
+// Атомарная запись: временный файл в том же каталоге, fsync, rename
+func (f *fsJobStore) writeAtomic(name string, write func(io.Writer) error) (int64, error) {
+	tmp, err := os.CreateTemp(f.dir, jobTempPrefix+"*")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(tmp.Name())
+
+	cw := &countingWriter{w: tmp}
+	err = write(cw)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), name)
+	}
+	return cw.n, err
+}
+
+func (f *fsJobStore) Put(meta jobMeta) error {
+	_, err := f.writeAtomic(f.path(meta.ID, ".json"), func(w io.Writer) error {
+		return json.NewEncoder(w).Encode(meta)
+	})
+	return err
+}
+
+func (f *fsJobStore) List() ([]jobMeta, error) {
+	names, err := filepath.Glob(filepath.Join(f.dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	var out []jobMeta
+	for _, name := range names {
+		b, err := os.ReadFile(name)
+		if err != nil {
+			return nil, err
+		}
+		var meta jobMeta
+		if err := json.Unmarshal(b, &meta); err != nil || meta.ID != strings.TrimSuffix(filepath.Base(name), ".json") {
+			slog.Warn("Skipping unreadable job metadata", "file", name, "error", err)
+			continue
+		}
+		out = append(out, meta)
+	}
+	return out, nil
+}
+
+func (f *fsJobStore) WriteResult(id string, write func(io.Writer) error) (int64, error) {
+	return f.writeAtomic(f.path(id, ".bin"), write)
+}
+
+func (f *fsJobStore) OpenResult(id string) (io.ReadCloser, error) {
+	return os.Open(f.path(id, ".bin"))
+}
+
+// Сначала результат, затем метаданные: прерванное удаление оставит задачу
+// без результата, а не результат без задачи
+func (f *fsJobStore) Delete(id string) error {
+	for _, ext := range []string{".bin", ".json"} {
+		if err := os.Remove(f.path(id, ext)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
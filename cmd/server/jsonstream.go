@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"reflect"
+)
+
+// Значение, которое пишет свой JSON по частям, не собирая его в памяти
+type jsonStreamer interface {
+	writeJSON(w *bufio.Writer) error
+}
+
+// Структура v как JSON-объект — тот же текст, что у json.Encoder.Encode.
+// Поля-потоки (jsonStreamer) пишутся по частям через буфер 64 КиБ, так
+// что память ответа не растёт с размером решения. Перед первым потоком
+// уже записанные поля отправляются клиенту. Заголовки уходят с первыми
+// байтами, поэтому ошибка посреди записи может только оборвать ответ
+func writeJSONStream(w http.ResponseWriter, v any) error {
+	bw := bufio.NewWriterSize(w, 64<<10)
+	rv := reflect.ValueOf(v)
+	bw.WriteByte('{')
+	first := true
+	for _, f := range jsonFields(rv.Type()) {
+		fv := rv.FieldByName(f.GoName)
+		if f.OmitEmpty && emptyJSON(fv) {
+			continue
+		}
+		if !first {
+			bw.WriteByte(',')
+		}
+		first = false
+		name, _ := json.Marshal(f.Name)
+		bw.Write(name)
+		bw.WriteByte(':')
+
+		if s, ok := fv.Interface().(jsonStreamer); ok {
+			if err := bw.Flush(); err != nil {
+				return err
+			}
+			http.NewResponseController(w).Flush()
+			if err := s.writeJSON(bw); err != nil {
+				return err
+			}
+			continue
+		}
+		b, err := json.Marshal(fv.Interface())
+		if err != nil {
+			return err
+		}
+		bw.Write(b)
+	}
+	bw.WriteString("}\n")
+	return bw.Flush()
+}
+
+// Пустое значение в смысле omitempty пакета encoding/json
+func emptyJSON(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Interface, reflect.Pointer:
+		return v.IsZero()
+	}
+	return false
+}
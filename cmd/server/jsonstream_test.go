@@ -0,0 +1,118 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"heat-solver/internal/config"
+	"heat-solver/internal/mathutils"
+	"heat-solver/internal/solver"
+)
+
+// Расчёт для проверок записи ответа; освобождается в конце теста
+func solveForJSON(t testing.TB, p config.Params) *solver.Result {
+	t.Helper()
+	res, err := solver.Run(context.Background(), p.Normalize(), solver.Hooks{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(res.Release)
+	return res
+}
+
+// Ответ, который пишет в память (discard — отбрасывает) и запоминает
+// время первой записи
+type firstByteRecorder struct {
+	header  http.Header
+	body    bytes.Buffer
+	discard bool
+	start   time.Time
+	first   time.Duration
+}
+
+func newFirstByteRecorder() *firstByteRecorder {
+	return &firstByteRecorder{header: make(http.Header), start: time.Now()}
+}
+
+func (w *firstByteRecorder) Header() http.Header { return w.header }
+func (w *firstByteRecorder) WriteHeader(int)     {}
+func (w *firstByteRecorder) Write(b []byte) (int, error) {
+	if w.first == 0 {
+		w.first = time.Since(w.start)
+	}
+	if w.discard {
+		return len(b), nil
+	}
+	return w.body.Write(b)
+}
+
+// Потоковая запись даёт побайтно тот же текст, что json.Encoder.Encode
+func TestWriteJSONStream(t *testing.T) {
+	p := config.Params{Method: "CN", Dx: 0.05, Dt: 0.001, Tmax: 0.02}
+	res := solveForJSON(t, p)
+	p.Precision = config.PrecisionFloat32
+	res32 := solveForJSON(t, p)
+	diverged := solveForJSON(t, config.Params{Method: "FTCS", Dx: 0.05, Dt: 0.01, Tmax: 10, Storage: config.StorageFinalOnly})
+	exact := mathutils.Analytical{Alpha: 1}
+
+	tests := []struct {
+		name string
+		v    any
+	}{
+		{"v1 every level", newSimulateResponse(res, 1, "sine", "analytical", exact)},
+		{"v1 with stride", newSimulateResponse(res, 7, "sine", "analytical", exact)},
+		{"v1 float32", newSimulateResponse(res32, 1, "sine", "analytical", exact)},
+		{"v1 diverged without norms", newSimulateResponse(diverged, 1, "sine", "none", nil)},
+		{"legacy without stride", legacySimulateResponse{Dt: res.Dt, Dx: res.Dx, U: newResultRows(res, 1)}},
+		{"legacy with stride", legacySimulateResponse{Dt: res.Dt, Dx: res.Dx, Stride: 5, U: newResultRows(res, 5)}},
+		{"no rows", legacySimulateResponse{Dx: 0.1, U: resultRows{}}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var want bytes.Buffer
+			if err := json.NewEncoder(&want).Encode(tc.v); err != nil {
+				t.Fatal(err)
+			}
+			w := newFirstByteRecorder()
+			if err := writeJSONStream(w, tc.v); err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(w.body.Bytes(), want.Bytes()) {
+				t.Errorf("streamed JSON differs from json.Encoder:\n%.300s\nwant\n%.300s", w.body.Bytes(), want.Bytes())
+			}
+		})
+	}
+}
+
+// Ответ /api/v1/simulate на 2001 слой по 1001 узлу (≈ 40 МБ JSON): сборка
+// в памяти через json.Encoder и потоковая запись. B/op — память на ответ,
+// ttfb-ms — время до первого байта
+func BenchmarkSimulateJSON(b *testing.B) {
+	res := solveForJSON(b, config.Params{Method: "CN", Dx: 0.001, Dt: 5e-5, Tmax: 0.1})
+	resp := newSimulateResponse(res, 1, "sine", "analytical", mathutils.Analytical{Alpha: 1})
+	for _, enc := range []struct {
+		name  string
+		write func(w http.ResponseWriter, v any) error
+	}{
+		{"Encoder", func(w http.ResponseWriter, v any) error { return json.NewEncoder(w).Encode(v) }},
+		{"stream", writeJSONStream},
+	} {
+		b.Run(enc.name, func(b *testing.B) {
+			b.ReportAllocs()
+			var ttfb time.Duration
+			for b.Loop() {
+				w := newFirstByteRecorder()
+				w.discard = true
+				if err := enc.write(w, resp); err != nil {
+					b.Fatal(err)
+				}
+				ttfb += w.first
+			}
+			b.ReportMetric(float64(ttfb.Milliseconds())/float64(b.N), "ttfb-ms")
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"syscall"
+)
+
+// Первый дескриптор, передаваемый systemd при socket activation
+const systemdFirstFD = 3
+
+type listenConfig struct {
+	Addr    string `json:"addr"`
+	Port    int    `json:"port"`
+	TLSCert string `json:"tls_cert"`
+	TLSKey  string `json:"tls_key"`
+}
+
+// Итоговый адрес: --addr может уже содержать порт (например 127.0.0.1:0)
+func (c listenConfig) address() string {
+	if _, _, err := net.SplitHostPort(c.Addr); err == nil {
+		return c.Addr
+	}
+	return net.JoinHostPort(c.Addr, strconv.Itoa(c.Port))
+}
+
+func (c listenConfig) useTLS() bool {
+	return c.TLSCert != "" || c.TLSKey != ""
+}
+
+// Проверка TLS-файлов при старте: оба заданы и читаются
+func (c listenConfig) validateTLS() error {
+	if !c.useTLS() {
+		return nil
+	}
+	if c.TLSCert == "" || c.TLSKey == "" {
+		return errors.New("--tls-cert and --tls-key must be provided together")
+	}
+	if _, err := tls.LoadX509KeyPair(c.TLSCert, c.TLSKey); err != nil {
+		return fmt.Errorf("cannot load TLS key pair: %w", err)
+	}
+	return nil
+}
+
+// Слушающий сокет: переданный systemd или открытый по адресу
+func (c listenConfig) listen() (net.Listener, error) {
+	if ln, err := systemdListener(); ln != nil || err != nil {
+		return ln, err
+	}
+
+	addr := c.address()
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		if errors.Is(err, syscall.EADDRINUSE) {
+			return nil, fmt.Errorf("address %s is already in use; choose another --port or stop the other process", addr)
+		}
+		return nil, fmt.Errorf("cannot listen on %s: %w", addr, err)
+	}
+	return ln, nil
+}
+
+// Обслуживание httpServer на ln. Адрес и порт пишутся в журнал: по ним
+// обвязки находят порт, выбранный при --port 0
+func (c listenConfig) serve(httpServer *http.Server, ln net.Listener) error {
+	scheme := "http"
+	if c.useTLS() {
+		scheme = "https"
+	}
+	slog.Info("🚀 Server running",
+		"url", scheme+"://"+ln.Addr().String(),
+		"port", ln.Addr().(*net.TCPAddr).Port,
+	)
+	if c.useTLS() {
+		return httpServer.ServeTLS(ln, c.TLSCert, c.TLSKey)
+	}
+	return httpServer.Serve(ln)
+}
+
+// Socket activation: LISTEN_PID/LISTEN_FDS выставляет systemd
+func systemdListener() (net.Listener, error) {
+	pid, err := strconv.Atoi(os.Getenv("LISTEN_PID"))
+	if err != nil || pid != os.Getpid() {
+		return nil, nil
+	}
+	if n, _ := strconv.Atoi(os.Getenv("LISTEN_FDS")); n < 1 {
+		return nil, nil
+	}
+
+	f := os.NewFile(systemdFirstFD, "systemd-socket")
+	defer f.Close()
+	ln, err := net.FileListener(f)
+	if err != nil {
+		return nil, fmt.Errorf("cannot use systemd socket: %w", err)
+	}
+	return ln, nil
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Самоподписанный сертификат для 127.0.0.1 в каталоге теста
+func selfSignedCert(t *testing.T) (certFile, keyFile string, pool *x509.CertPool) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "heat-solver test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	certFile, keyFile = filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool = x509.NewCertPool()
+	pool.AddCert(cert)
+	return certFile, keyFile, pool
+}
+
+func TestListenAddress(t *testing.T) {
+	tests := []struct {
+		addr string
+		port int
+		want string
+	}{
+		{"", 8080, ":8080"},
+		{"127.0.0.1", 9000, "127.0.0.1:9000"},
+		{"127.0.0.1:0", 8080, "127.0.0.1:0"},
+		{"::1", 8080, "[::1]:8080"},
+	}
+	for _, tc := range tests {
+		if got := (listenConfig{Addr: tc.addr, Port: tc.port}).address(); got != tc.want {
+			t.Errorf("address(%q, %d) = %q, want %q", tc.addr, tc.port, got, tc.want)
+		}
+	}
+}
+
+func TestValidateTLS(t *testing.T) {
+	cert, key, _ := selfSignedCert(t)
+	missing := filepath.Join(t.TempDir(), "missing.pem")
+	tests := []struct {
+		name, cert, key string
+		wantErr         string
+	}{
+		{"plain HTTP", "", "", ""},
+		{"valid pair", cert, key, ""},
+		{"cert only", cert, "", "must be provided together"},
+		{"key only", "", key, "must be provided together"},
+		{"unreadable key", cert, missing, "cannot load TLS key pair"},
+		{"swapped files", key, cert, "cannot load TLS key pair"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := listenConfig{TLSCert: tc.cert, TLSKey: tc.key}.validateTLS()
+			switch {
+			case tc.wantErr == "" && err != nil:
+				t.Errorf("unexpected error: %v", err)
+			case tc.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tc.wantErr)):
+				t.Errorf("error %v, want one containing %q", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestListenPortInUse(t *testing.T) {
+	taken, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer taken.Close()
+	_, err = listenConfig{Addr: taken.Addr().String()}.listen()
+	if err == nil || !strings.Contains(err.Error(), "already in use") {
+		t.Errorf("listen on a taken port: %v, want an already-in-use error", err)
+	}
+}
+
+// Сервер на порту 0: порт берётся из записи журнала о запуске, запрос
+// идёт по HTTP и по TLS с самоподписанным сертификатом
+func TestServeOnLoggedPort(t *testing.T) {
+	cert, key, pool := selfSignedCert(t)
+	tests := []struct {
+		name   string
+		lc     listenConfig
+		scheme string
+		client *http.Client
+	}{
+		{"http", listenConfig{Addr: "127.0.0.1:0"}, "http", &http.Client{}},
+		{"https", listenConfig{Addr: "127.0.0.1:0", TLSCert: cert, TLSKey: key}, "https",
+			&http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}}}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			logs := captureLogs(t)
+			s, _ := newTestServer(t, nil)
+			if err := tc.lc.validateTLS(); err != nil {
+				t.Fatal(err)
+			}
+			ln, err := tc.lc.listen()
+			if err != nil {
+				t.Fatal(err)
+			}
+			httpServer := &http.Server{Handler: s.routes()}
+			served := make(chan error, 1)
+			go func() { served <- tc.lc.serve(httpServer, ln) }()
+			defer func() {
+				httpServer.Shutdown(context.Background())
+				if err := <-served; err != http.ErrServerClosed {
+					t.Errorf("serve: %v", err)
+				}
+			}()
+
+			// Запись появляется из горутины serve
+			var port float64
+			for deadline := time.Now().Add(5 * time.Second); port == 0; time.Sleep(5 * time.Millisecond) {
+				if time.Now().After(deadline) {
+					t.Fatal("bound port was not logged")
+				}
+				for _, rec := range logs.records(t) {
+					if rec["msg"] == "🚀 Server running" {
+						port, _ = rec["port"].(float64)
+						if !strings.HasPrefix(rec["url"].(string), tc.scheme+"://") {
+							t.Errorf("logged url %v, want scheme %s", rec["url"], tc.scheme)
+						}
+					}
+				}
+			}
+			resp, err := tc.client.Get(fmt.Sprintf("%s://127.0.0.1:%d/healthz", tc.scheme, int(port)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				t.Errorf("status %d, want 200", resp.StatusCode)
+			}
+			if tc.scheme == "https" && resp.TLS == nil {
+				t.Error("response was not served over TLS")
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"heat-solver/internal/solver"
+)
+
+type stabilityInfo struct {
+	Type  string `json:"type"`
+	Bound string `json:"bound,omitempty"`
+}
+
+type orderInfo struct {
+	Time  int `json:"time"`
+	Space int `json:"space"`
+}
+
+type methodInfo struct {
+	Name        string        `json:"name"`
+	Aliases     []string      `json:"aliases"`
+	Description string        `json:"description"`
+	Scheme      string        `json:"scheme"`
+	Stability   stabilityInfo `json:"stability"`
+	Order       orderInfo     `json:"order"`
+}
+
+type methodsResponse struct {
+	Methods []methodInfo `json:"methods"`
+}
+
+// Описание методов строится из реестра солвера
+func describeMethods() []methodInfo {
+	var out []methodInfo
+	for _, m := range solver.Registered() {
+		info := methodInfo{
+			Name:        m.Name,
+			Aliases:     m.Aliases,
+			Description: m.Description,
+			Scheme:      "implicit",
+			Stability:   stabilityInfo{Type: "unconditional"},
+			Order:       orderInfo{Time: m.OrderTime, Space: m.OrderSpace},
+		}
+		if info.Aliases == nil {
+			info.Aliases = []string{}
+		}
+		if m.Explicit {
+			info.Scheme = "explicit"
+		}
+		if !m.Unconditional() {
+			info.Stability = stabilityInfo{Type: "conditional", Bound: m.StabilityBound}
+		}
+		out = append(out, info)
+	}
+	return out
+}
+
+func (s *server) handleMethods(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(methodsResponse{Methods: describeMethods()})
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"slices"
+	"testing"
+
+	"heat-solver/internal/solver"
+)
+
+func TestMethods(t *testing.T) {
+	_, ts := newTestServer(t, nil)
+	_, body := get(t, ts, "/api/v1/methods", 200)
+	var resp methodsResponse
+	decode(t, body, &resp)
+
+	byName := make(map[string]methodInfo, len(resp.Methods))
+	var names []string
+	for _, m := range resp.Methods {
+		byName[m.Name] = m
+		names = append(names, m.Name)
+	}
+	if want := solver.Methods(); !slices.Equal(names, want) {
+		t.Fatalf("methods %v, want the registry order %v", names, want)
+	}
+
+	tests := []struct {
+		name      string
+		scheme    string
+		stability stabilityInfo
+		order     orderInfo
+		alias     string
+	}{
+		{"FTCS", "explicit", stabilityInfo{"conditional", "r = dt/dx² ≤ 1/2"}, orderInfo{1, 2}, "EXPLICIT"},
+		{"BTCS", "implicit", stabilityInfo{"unconditional", ""}, orderInfo{1, 2}, "IMPLICIT"},
+		{"CN", "implicit", stabilityInfo{"unconditional", ""}, orderInfo{2, 2}, "CRANK-NICOLSON"},
+	}
+	for _, tc := range tests {
+		m, ok := byName[tc.name]
+		if !ok {
+			t.Errorf("%s missing", tc.name)
+			continue
+		}
+		if m.Scheme != tc.scheme || m.Stability != tc.stability || m.Order != tc.order {
+			t.Errorf("%s: scheme %s, stability %+v, order %+v; want %s, %+v, %+v",
+				tc.name, m.Scheme, m.Stability, m.Order, tc.scheme, tc.stability, tc.order)
+		}
+		if !slices.Contains(m.Aliases, tc.alias) {
+			t.Errorf("%s: aliases %v, want %s among them", tc.name, m.Aliases, tc.alias)
+		}
+		if m.Description == "" {
+			t.Errorf("%s: empty description", tc.name)
+		}
+	}
+}
+
+// Схема, зарегистрированная после запуска, сразу видна в /methods
+func TestMethodsFollowRegistry(t *testing.T) {
+	const name = "TEST-FTCS-COPY"
+	if _, ok := solver.Lookup(name); !ok {
+		m, _ := solver.Lookup("FTCS")
+		m.Name, m.Aliases, m.Verification = name, nil, nil
+		m.Description = "Test-only copy of FTCS"
+		solver.Register(m)
+	}
+	_, ts := newTestServer(t, nil)
+	_, body := get(t, ts, "/api/v1/methods", 200)
+	var resp methodsResponse
+	decode(t, body, &resp)
+	last := resp.Methods[len(resp.Methods)-1]
+	if last.Name != name || last.Stability.Type != "conditional" || last.Aliases == nil {
+		t.Errorf("last method %+v, want the test scheme with conditional stability", last)
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"heat-solver/internal/metrics"
+	"heat-solver/internal/solver"
+)
+
+type serverMetrics struct {
+	registry        *metrics.Registry
+	requests        *metrics.Counter
+	requestDuration *metrics.Histogram
+	solveDuration   *metrics.Histogram
+	activeSolves    *metrics.Gauge
+	jobQueue        *metrics.Gauge
+	cacheHits       *metrics.Counter
+	cacheMisses     *metrics.Counter
+	bufferPool      *metrics.Counter
+	diverged        *metrics.Counter
+}
+
+func newServerMetrics() *serverMetrics {
+	r := metrics.NewRegistry()
+	return &serverMetrics{
+		registry:        r,
+		requests:        r.NewCounter("heat_http_requests_total", "HTTP requests by endpoint and status code.", "endpoint", "status"),
+		requestDuration: r.NewHistogram("heat_http_request_duration_seconds", "HTTP request duration.", metrics.DefaultBuckets, "endpoint"),
+		solveDuration:   r.NewHistogram("heat_solver_duration_seconds", "Solver wall-clock time.", metrics.DefaultBuckets, "method"),
+		activeSolves:    r.NewGauge("heat_active_solves", "Solves currently running."),
+		jobQueue:        r.NewGauge("heat_job_queue_depth", "Jobs waiting for a free worker."),
+		cacheHits:       r.NewCounter("heat_cache_hits_total", "Result cache hits."),
+		cacheMisses:     r.NewCounter("heat_cache_misses_total", "Result cache misses."),
+		bufferPool:      r.NewCounter("heat_buffer_pool_requests_total", "Solver buffer requests by outcome: hit, miss or bypass (too large to pool).", "outcome"),
+		diverged:        r.NewCounter("heat_diverged_runs_total", "Runs whose solution diverged.", "method"),
+	}
+}
+
+// Хуки солвера, обновляющие метрики
+func (m *serverMetrics) solverHooks() solver.Hooks {
+	return solver.Hooks{
+		OnStart: func(method string) {
+			m.activeSolves.Add(1)
+		},
+		OnFinish: func(res *solver.Result) {
+			m.activeSolves.Add(-1)
+			m.solveDuration.Observe(res.Runtime.Seconds(), res.Method)
+			if res.Diverged {
+				m.diverged.Inc(res.Method)
+			}
+		},
+	}
+}
+
+func (m *serverMetrics) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	m.registry.WriteTo(w)
+}
+
+// Счётчики и длительность запросов по шаблону маршрута
+func (m *serverMetrics) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w}
+		next.ServeHTTP(rec, r)
+
+		if rec.status == 0 {
+			rec.status = http.StatusOK
+		}
+		// r.Pattern заполняет ServeMux; пустой — ни один маршрут не подошёл
+		endpoint := r.Pattern
+		if endpoint == "" {
+			endpoint = "unmatched"
+		}
+		m.requests.Inc(endpoint, strconv.Itoa(rec.status))
+		m.requestDuration.Observe(time.Since(start).Seconds(), endpoint)
+	})
+}
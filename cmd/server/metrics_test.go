@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"heat-solver/internal/solver"
+)
+
+// Значения выдачи /metrics по имени ряда с метками
+func scrape(t *testing.T, data []byte) map[string]float64 {
+	t.Helper()
+	series := make(map[string]float64)
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		line := sc.Text()
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		i := strings.LastIndexByte(line, ' ')
+		if i < 0 {
+			t.Fatalf("malformed metrics line %q", line)
+		}
+		v, err := strconv.ParseFloat(line[i+1:], 64)
+		if err != nil {
+			t.Fatalf("malformed metrics value in %q: %v", line, err)
+		}
+		series[line[:i]] = v
+	}
+	return series
+}
+
+func TestMetrics(t *testing.T) {
+	_, ts := newTestServer(t, nil)
+	const sim = "/api/v1/simulate?method=CN&dx=0.05&dt=0.001&tmax=0.1"
+	get(t, ts, sim, 200)
+	get(t, ts, sim, 200)
+	get(t, ts, "/api/v1/simulate?method=BTCS&dx=0.05&dt=0.001&tmax=0.1", 200)
+	_, data := get(t, ts, "/api/v1/simulate?method=FTCS&dx=0.05&dt=0.01&tmax=1", 200)
+	var unstable struct{ Diverged bool }
+	decode(t, data, &unstable)
+	if !unstable.Diverged {
+		t.Fatal("unstable FTCS run was not reported as diverged")
+	}
+	get(t, ts, "/api/v1/simulate?dx=abc", 400)
+
+	resp, data := get(t, ts, "/metrics", 200)
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("Content-Type = %q, want the Prometheus text format", ct)
+	}
+	got := scrape(t, data)
+	tests := []struct {
+		series string
+		want   float64
+	}{
+		{`heat_http_requests_total{endpoint="GET /api/v1/simulate",status="200"}`, 4},
+		{`heat_http_requests_total{endpoint="GET /api/v1/simulate",status="400"}`, 1},
+		{`heat_http_request_duration_seconds_count{endpoint="GET /api/v1/simulate"}`, 5},
+		{`heat_solver_duration_seconds_count{method="CN"}`, 1},
+		{`heat_solver_duration_seconds_count{method="BTCS"}`, 1},
+		{`heat_solver_duration_seconds_count{method="FTCS"}`, 1},
+		{`heat_diverged_runs_total{method="FTCS"}`, 1},
+		{`heat_cache_hits_total`, 1},
+		{`heat_cache_misses_total`, 3},
+		{`heat_active_solves`, 0},
+		{`heat_job_queue_depth`, 0},
+	}
+	for _, tc := range tests {
+		v, ok := got[tc.series]
+		switch {
+		case !ok:
+			t.Errorf("series %s missing", tc.series)
+		case v != tc.want:
+			t.Errorf("%s = %g, want %g", tc.series, v, tc.want)
+		}
+	}
+	for _, method := range []string{"CN", "BTCS", "FTCS"} {
+		sum := got[`heat_solver_duration_seconds_sum{method="`+method+`"}`]
+		if !(sum > 0 && sum < 60) {
+			t.Errorf("solver duration sum for %s = %g s, want a plausible positive time", method, sum)
+		}
+	}
+}
+
+// Повторные запросы одного размера без кэша берут сетки из пула, попадания
+// видны в метриках, и данные прошлого запроса в ответ не попадают
+func TestBufferPoolReuse(t *testing.T) {
+	s, ts := newTestServer(t, func(cfg *serverConfig) {
+		cfg.Cache.Entries = 0
+	})
+	solver.SetPoolObserver(func(outcome string) { s.metrics.bufferPool.Inc(outcome) })
+	t.Cleanup(func() { solver.SetPoolObserver(nil) })
+
+	const sim = "/api/v1/simulate?method=CN&dx=0.01&dt=0.0001&tmax=0.05&stride=50&ic="
+	want := make(map[string][][]float64)
+	for i := range 20 {
+		ic := []string{"sine", "tophat"}[i%2]
+		_, body := get(t, ts, sim+ic, 200)
+		var res simulateResult
+		decode(t, body, &res)
+		if first, ok := want[ic]; !ok {
+			want[ic] = res.U
+		} else if !slices.EqualFunc(res.U, first, slices.Equal) {
+			t.Fatalf("request %d (ic=%s) differs from the first one with the same parameters", i, ic)
+		}
+	}
+
+	_, data := get(t, ts, "/metrics", 200)
+	got := scrape(t, data)
+	hits := got[`heat_buffer_pool_requests_total{outcome="hit"}`]
+	misses := got[`heat_buffer_pool_requests_total{outcome="miss"}`]
+	if hits < 20 || hits < 2*misses {
+		t.Errorf("%g pool hits and %g misses over 20 requests, want mostly hits", hits, misses)
+	}
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// Записи slog в JSON на время теста
+type logCapture struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (c *logCapture) Write(p []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.buf.Write(p)
+}
+
+func (c *logCapture) records(t *testing.T) []map[string]any {
+	t.Helper()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var out []map[string]any
+	for _, line := range bytes.Split(bytes.TrimSpace(c.buf.Bytes()), []byte("\n")) {
+		if len(line) == 0 {
+			continue
+		}
+		var rec map[string]any
+		if err := json.Unmarshal(line, &rec); err != nil {
+			t.Fatalf("log line %q: %v", line, err)
+		}
+		out = append(out, rec)
+	}
+	return out
+}
+
+// Подменяет журнал по умолчанию; прежний возвращается по окончании теста
+func captureLogs(t *testing.T) *logCapture {
+	c := &logCapture{}
+	prev := slog.Default()
+	slog.SetDefault(slog.New(contextHandler{slog.NewJSONHandler(c, nil)}))
+	t.Cleanup(func() { slog.SetDefault(prev) })
+	return c
+}
+
+// Запись журнала с данным сообщением и request_id
+func findRecord(recs []map[string]any, msg, id string) map[string]any {
+	for _, r := range recs {
+		if r["msg"] == msg && r["request_id"] == id {
+			return r
+		}
+	}
+	return nil
+}
+
+func TestRequestID(t *testing.T) {
+	_, ts := newTestServer(t, nil)
+	tests := []struct {
+		name, incoming string
+		keep           bool
+	}{
+		{"generated", "", false},
+		{"honored", "trace-42", true},
+		{"control characters replaced", "bad\tid", false},
+		{"too long replaced", strings.Repeat("x", 129), false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, ts.URL+"/healthz", nil)
+			if tc.incoming != "" {
+				req.Header.Set("X-Request-ID", tc.incoming)
+			}
+			resp, err := ts.Client().Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			id := resp.Header.Get("X-Request-ID")
+			switch {
+			case tc.keep && id != tc.incoming:
+				t.Errorf("X-Request-ID = %q, want %q", id, tc.incoming)
+			case !tc.keep && (id == "" || id == tc.incoming):
+				t.Errorf("X-Request-ID = %q, want a generated ID", id)
+			}
+		})
+	}
+}
+
+func TestRequestLogRecord(t *testing.T) {
+	logs := captureLogs(t)
+	_, ts := newTestServer(t, nil)
+
+	req, _ := http.NewRequest(http.MethodGet, ts.URL+"/api/v1/simulate?method=CN&dx=0.1&api_key=secret", nil)
+	req.Header.Set("X-Request-ID", "log-test")
+	resp, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	rec := findRecord(logs.records(t), "HTTP request", "log-test")
+	if rec == nil {
+		t.Fatal("no HTTP request record with the request ID")
+	}
+	for key, want := range map[string]any{"method": "GET", "path": "/api/v1/simulate", "status": float64(200)} {
+		if rec[key] != want {
+			t.Errorf("%s = %v, want %v", key, rec[key], want)
+		}
+	}
+	params, _ := rec["params"].(map[string]any)
+	if params["method"] != "CN" || params["api_key"] != "[REDACTED]" {
+		t.Errorf("params = %v, want method CN and a redacted api_key", params)
+	}
+	if b, _ := rec["bytes"].(float64); b <= 0 {
+		t.Errorf("bytes = %v, want the response size", rec["bytes"])
+	}
+	if _, ok := rec["duration_ms"].(float64); !ok {
+		t.Errorf("duration_ms = %v, want a number", rec["duration_ms"])
+	}
+}
+
+// Паника обработчика: 500 с JSON-ошибкой, ID запроса в ответе и в записи
+// журнала со стеком
+func TestPanicRecovery(t *testing.T) {
+	logs := captureLogs(t)
+	h := requestLogger(recoverPanics(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	})))
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/simulate", nil)
+	req.Header.Set("X-Request-ID", "panic-test")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("status %d, want 500", w.Code)
+	}
+	var env errorEnvelope
+	decode(t, w.Body.Bytes(), &env)
+	if env.Error.Code != codeInternal || env.Error.RequestID != "panic-test" {
+		t.Errorf("error body %+v, want code %s with the request ID", env.Error, codeInternal)
+	}
+
+	recs := logs.records(t)
+	rec := findRecord(recs, "Panic in handler", "panic-test")
+	if rec == nil {
+		t.Fatal("no panic record with the request ID")
+	}
+	if rec["panic"] != "boom" || !strings.Contains(rec["stack"].(string), "TestPanicRecovery") {
+		t.Errorf("panic record %v, want the panic value and the stack", rec)
+	}
+	if r := findRecord(recs, "HTTP request", "panic-test"); r == nil || r["status"] != float64(500) {
+		t.Errorf("request record %v, want status 500", r)
+	}
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"fmt"
+	"image/png"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	heatio "heat-solver/internal/io"
+	"heat-solver/internal/mathutils"
+	"heat-solver/internal/render"
+	"heat-solver/internal/solver"
+)
+
+// Форматы результата расчёта; первый — формат по умолчанию
+const (
+	mediaJSON   = "application/json"
+	mediaCSV    = "text/csv"
+	mediaNDJSON = "application/x-ndjson"
+	mediaPNG    = "image/png"
+)
+
+var resultMedia = []string{mediaJSON, mediaCSV, mediaNDJSON, mediaPNG}
+
+// Ни один из предлагаемых форматов не подходит под Accept
+type notAcceptableError struct {
+	Accept    string
+	Supported []string
+}
+
+func (e *notAcceptableError) Error() string {
+	return fmt.Sprintf("cannot produce any of %q; supported: %s", e.Accept, strings.Join(e.Supported, ", "))
+}
+
+// Выбор формата по заголовку Accept: берётся предложение с наибольшим q,
+// при равенстве — раньше стоящее в offers. q предложения определяет самый
+// точный подходящий диапазон (type/subtype, затем type/*, затем */*).
+// Ответ всегда помечается Vary: Accept, в том числе ошибка 406
+func negotiate(w http.ResponseWriter, r *http.Request, offers []string) (string, error) {
+	w.Header().Add("Vary", "Accept")
+	accept := strings.Join(r.Header.Values("Accept"), ",")
+	if strings.TrimSpace(accept) == "" {
+		return offers[0], nil
+	}
+	ranges := parseAccept(accept)
+
+	best, bestQ := "", 0.0
+	for _, offer := range offers {
+		q, spec := 0.0, -1
+		for _, ar := range ranges {
+			if s := ar.matches(offer); s > spec {
+				q, spec = ar.q, s
+			}
+		}
+		if q > bestQ {
+			best, bestQ = offer, q
+		}
+	}
+	if best == "" {
+		return "", &notAcceptableError{Accept: accept, Supported: offers}
+	}
+	return best, nil
+}
+
+type acceptRange struct {
+	typ, sub string
+	q        float64
+}
+
+// Точность совпадения диапазона с типом: 2 — полное, 1 — type/*, 0 — */*,
+// -1 — не подходит
+func (ar acceptRange) matches(media string) int {
+	typ, sub, _ := strings.Cut(media, "/")
+	switch {
+	case ar.typ == "*" && ar.sub == "*":
+		return 0
+	case ar.typ != typ:
+		return -1
+	case ar.sub == "*":
+		return 1
+	case ar.sub == sub:
+		return 2
+	}
+	return -1
+}
+
+// Разбор Accept; некорректные элементы пропускаются, параметры кроме q
+// не учитываются
+func parseAccept(accept string) []acceptRange {
+	var out []acceptRange
+	for part := range strings.SplitSeq(accept, ",") {
+		media, params, _ := strings.Cut(part, ";")
+		typ, sub, ok := strings.Cut(strings.ToLower(strings.TrimSpace(media)), "/")
+		if !ok || typ == "" || sub == "" || typ == "*" && sub != "*" {
+			continue
+		}
+		ar := acceptRange{typ: typ, sub: sub, q: 1}
+		for p := range strings.SplitSeq(params, ";") {
+			k, v, _ := strings.Cut(strings.TrimSpace(p), "=")
+			if strings.EqualFold(k, "q") {
+				q, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+				if err != nil || q < 0 || q > 1 {
+					q = 0
+				}
+				ar.q = q
+			}
+		}
+		out = append(out, ar)
+	}
+	return out
+}
+
+// Источник выводимых слоёв: yield вызывается для каждого слоя по порядку.
+// row может переиспользоваться между вызовами
+type levelSource func(yield func(t float64, row []float64) error) error
+
+// Слои решения в памяти с шагом stride (последний всегда включается)
+func resultLevels(res *solver.Result, stride int) levelSource {
+	return func(yield func(t float64, row []float64) error) error {
+		for _, n := range levelIndices(res.Nt, resultStride(res, stride)) {
+			if err := yield(float64(n)*res.Dt, res.Level(n/res.LevelStep())); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// Запись слоёв в формате media (кроме JSON, у которого своя структура
+// ответа у каждого эндпоинта). Для PNG слои собираются в тепловую карту.
+// bits — разрядность хранимых значений для текстовых форматов (32 или 64),
+// exact — эталон для столбцов u_exact и error в CSV (nil — без них)
+func writeLevels(w io.Writer, media string, dx float64, bits int, exact mathutils.Reference, image render.HeatmapOptions, levels levelSource) error {
+	var rw heatio.RowWriter
+	switch media {
+	case mediaCSV:
+		rw = heatio.NewCSVRowWriter(w, dx, bits, exact)
+	case mediaNDJSON:
+		rw = heatio.NewNDJSONRowWriter(w, bits)
+	case mediaPNG:
+		var u [][]float64
+		err := levels(func(_ float64, row []float64) error {
+			u = append(u, slices.Clone(row))
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		return png.Encode(w, render.Heatmap(u, image))
+	default:
+		return fmt.Errorf("no writer for %s", media)
+	}
+	if err := levels(rw.WriteRow); err != nil {
+		return err
+	}
+	return rw.Close()
+}
+
+// Эталон для столбцов u_exact и error в CSV по параметру columns: nil при
+// columns=numeric (по умолчанию). reference вызывается только для
+// columns=all; если эталона нет, это ошибка no_reference
+func csvReference(q url.Values, reference func() mathutils.Reference) (mathutils.Reference, error) {
+	switch strings.ToLower(q.Get("columns")) {
+	case "", heatio.ColumnsNumeric:
+		return nil, nil
+	case heatio.ColumnsAll:
+		if exact := reference(); exact != nil {
+			return exact, nil
+		}
+		return nil, fmt.Errorf("%w: columns=%s needs a reference solution", errNoReference, heatio.ColumnsAll)
+	}
+	return nil, paramError("columns", "must be "+heatio.ColumnsNumeric+" or "+heatio.ColumnsAll)
+}
+
+// Content-Type ответа для выбранного формата
+func contentType(media string) string {
+	if media == mediaCSV {
+		return media + "; charset=utf-8"
+	}
+	return media
+}
+
+// Ответ в формате media. Заголовки отправляются до записи слоёв, поэтому
+// ошибка посреди потока только логируется
+func writeLevelsResponse(w http.ResponseWriter, r *http.Request, media string, dx float64, bits int, exact mathutils.Reference, diverged bool, image render.HeatmapOptions, levels levelSource) {
+	if diverged {
+		w.Header().Set("X-Diverged", "true")
+	}
+	image.Banner = diverged
+	w.Header().Set("Content-Type", contentType(media))
+	if err := writeLevels(w, media, dx, bits, exact, image, levels); err != nil {
+		slog.ErrorContext(r.Context(), "Result streaming aborted", "format", media, "error", err)
+	}
+}
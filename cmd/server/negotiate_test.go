@@ -0,0 +1,198 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"image/png"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"heat-solver/internal/mathutils"
+)
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		accept string
+		want   string
+	}{
+		{"", mediaJSON},
+		{"*/*", mediaJSON},
+		{"text/csv", mediaCSV},
+		{"TEXT/CSV; charset=utf-8", mediaCSV},
+		{"image/*", mediaPNG},
+		{"application/json;q=0.5, application/x-ndjson", mediaNDJSON},
+		{"text/csv;q=0.2, image/png;q=0.8, */*;q=0.1", mediaPNG},
+		{"*/*;q=0.9, application/json;q=0", mediaCSV},
+		{"image/bmp, text/*;q=0.3", mediaCSV},
+		{"application/xml", ""},
+		{"text/csv;q=0", ""},
+		{"garbage", ""},
+	}
+	for _, tc := range tests {
+		t.Run(tc.accept, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tc.accept != "" {
+				r.Header.Set("Accept", tc.accept)
+			}
+			w := httptest.NewRecorder()
+			got, err := negotiate(w, r, resultMedia)
+			var naerr *notAcceptableError
+			if tc.want == "" {
+				if !errors.As(err, &naerr) || !slices.Equal(naerr.Supported, resultMedia) {
+					t.Fatalf("negotiate = %q, %v; want notAcceptableError", got, err)
+				}
+			} else if got != tc.want || err != nil {
+				t.Fatalf("negotiate = %q, %v; want %q", got, err, tc.want)
+			}
+			if w.Header().Get("Vary") != "Accept" {
+				t.Errorf("Vary = %q, want Accept", w.Header().Get("Vary"))
+			}
+		})
+	}
+}
+
+// Каждый формат с /api/v1/simulate и из результата задачи: Content-Type,
+// Vary и структура тела
+func TestResultFormats(t *testing.T) {
+	_, ts := newTestServer(t, nil)
+	_, body := postJSON(t, ts, "/api/v1/jobs", `{"method":"CN","dx":0.1,"dt":0.01,"tmax":0.1}`, 202)
+	var meta jobMeta
+	decode(t, body, &meta)
+	waitJob(t, ts, meta.ID)
+
+	const nodes, levels = 11, 3
+	check := map[string]func(t *testing.T, body []byte){
+		mediaJSON: func(t *testing.T, body []byte) {
+			var res simulateResult
+			decode(t, body, &res)
+			if len(res.U) != levels || len(res.U[0]) != nodes {
+				t.Errorf("%d levels, want %d of %d nodes", len(res.U), levels, nodes)
+			}
+		},
+		mediaCSV: func(t *testing.T, body []byte) {
+			records, err := csv.NewReader(bytes.NewReader(body)).ReadAll()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(records[0], []string{"x", "t", "u"}) || len(records) != 1+levels*nodes {
+				t.Errorf("header %v with %d rows, want x,t,u and %d rows", records[0], len(records)-1, levels*nodes)
+			}
+		},
+		mediaNDJSON: func(t *testing.T, body []byte) {
+			sc := bufio.NewScanner(bytes.NewReader(body))
+			n := 0
+			for ; sc.Scan(); n++ {
+				var level struct {
+					T float64   `json:"t"`
+					U []float64 `json:"u"`
+				}
+				if err := json.Unmarshal(sc.Bytes(), &level); err != nil || len(level.U) != nodes {
+					t.Fatalf("line %d %q: %v", n, excerpt(sc.Bytes()), err)
+				}
+			}
+			if n != levels {
+				t.Errorf("%d lines, want %d", n, levels)
+			}
+		},
+		mediaPNG: func(t *testing.T, body []byte) {
+			if _, err := png.Decode(bytes.NewReader(body)); err != nil {
+				t.Errorf("invalid PNG: %v", err)
+			}
+		},
+	}
+
+	for _, path := range []string{
+		"/api/v1/simulate?method=CN&dx=0.1&dt=0.01&tmax=0.1&stride=5",
+		"/api/v1/jobs/" + meta.ID + "/result?stride=5",
+	} {
+		for _, media := range resultMedia {
+			t.Run(strings.Split(path, "?")[0]+" "+media, func(t *testing.T) {
+				req, _ := http.NewRequest(http.MethodGet, ts.URL+path, nil)
+				req.Header.Set("Accept", media+";q=0.9, application/xml")
+				resp, body := send(t, ts, req, 200)
+				if ct := resp.Header.Get("Content-Type"); ct != contentType(media) {
+					t.Errorf("Content-Type = %q, want %q", ct, contentType(media))
+				}
+				if !slices.Contains(resp.Header.Values("Vary"), "Accept") {
+					t.Errorf("Vary = %q, want Accept", resp.Header.Values("Vary"))
+				}
+				check[media](t, body)
+			})
+		}
+
+		req, _ := http.NewRequest(http.MethodGet, ts.URL+path, nil)
+		req.Header.Set("Accept", "application/xml")
+		resp, body := send(t, ts, req, 406)
+		var env errorEnvelope
+		decode(t, body, &env)
+		if env.Error.Code != codeNotAcceptable || !slices.Equal(env.Error.Supported, resultMedia) || resp.Header.Get("Vary") != "Accept" {
+			t.Errorf("406 %+v with Vary %q, want the supported list and Vary: Accept", env.Error, resp.Header.Get("Vary"))
+		}
+	}
+}
+
+// Столбцы CSV по параметру columns: эталон и ошибка только по запросу и
+// только при известном точном решении
+func TestCSVColumns(t *testing.T) {
+	_, ts := newTestServer(t, nil)
+	const sim = "/api/v1/simulate?method=CN&dx=0.1&dt=0.01&tmax=0.1&stride=5"
+	tests := []struct {
+		name   string
+		query  string
+		status int
+		header []string
+		code   string
+	}{
+		{"default", "", 200, []string{"x", "t", "u"}, ""},
+		{"numeric", "&columns=numeric", 200, []string{"x", "t", "u"}, ""},
+		{"all", "&columns=ALL", 200, []string{"x", "t", "u", "u_exact", "error"}, ""},
+		{"all without reference", "&columns=all&ic=tophat&source=sine", 422, nil, codeNoReference},
+		{"numeric without reference", "&columns=numeric&ic=tophat&source=sine", 200, []string{"x", "t", "u"}, ""},
+		{"unknown", "&columns=some", 400, nil, codeInvalidParameter},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, ts.URL+sim+tc.query, nil)
+			req.Header.Set("Accept", mediaCSV)
+			_, body := send(t, ts, req, tc.status)
+			if tc.status != 200 {
+				var env errorEnvelope
+				decode(t, body, &env)
+				if env.Error.Code != tc.code {
+					t.Errorf("code %s, want %s", env.Error.Code, tc.code)
+				}
+				return
+			}
+			// csv.Reader проверяет, что во всех строках столько же полей, сколько в заголовке
+			records, err := csv.NewReader(bytes.NewReader(body)).ReadAll()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(records[0], tc.header) {
+				t.Fatalf("header %v, want %v", records[0], tc.header)
+			}
+			if len(tc.header) < 5 {
+				return
+			}
+			for _, rec := range records[1:] {
+				var v [5]float64
+				for i, f := range rec {
+					if v[i], err = strconv.ParseFloat(f, 64); err != nil {
+						t.Fatal(err)
+					}
+				}
+				if ue := mathutils.AnalyticalSolution(v[0], v[1]); math.Abs(v[3]-ue) > 1e-6 || math.Abs(v[4]-math.Abs(v[2]-v[3])) > 2e-6 {
+					t.Errorf("row %v: want u_exact %g and error |u − u_exact|", rec, ue)
+				}
+			}
+		})
+	}
+}
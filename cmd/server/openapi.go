@@ -0,0 +1,581 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"heat-solver/internal/config"
+	heatio "heat-solver/internal/io"
+	"heat-solver/internal/mathutils"
+	"heat-solver/internal/render"
+	"heat-solver/internal/solver"
+)
+
+// Поля config.Params, учтённые в simulateRequest и спецификации. Новое поле
+// в Params ломает сборку на этом преобразовании: его нужно добавить в
+// simulateRequest (спецификация строится из него) и только потом сюда.
+type specParams struct {
+	Method       string
+	Dx           float64
+	Dt           float64
+	Tmax         float64
+	Outfile      string
+	IC           string
+	ICSamples    []config.Sample
+	Storage      string
+	Precision    string
+	Adapt        bool
+	Tol          float64
+	Rannacher    bool
+	SpatialOrder int
+	Stencil      string
+	Lambda       float64
+	Source       string
+	Laser        mathutils.Laser
+}
+
+var _ = config.Params(specParams{})
+
+type jsonSchema = map[string]any
+
+type jsonField struct {
+	Name      string
+	GoName    string
+	Doc       string
+	OmitEmpty bool
+	Type      reflect.Type
+}
+
+// Поля структуры в порядке объявления, как их видит encoding/json
+func jsonFields(t reflect.Type) []jsonField {
+	var out []jsonField
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		out = append(out, jsonField{
+			Name:      name,
+			GoName:    f.Name,
+			Doc:       f.Tag.Get("doc"),
+			OmitEmpty: slices.Contains(strings.Split(opts, ","), "omitempty"),
+			Type:      f.Type,
+		})
+	}
+	return out
+}
+
+// JSON Schema для Go-типа по тегам json и doc
+func schemaOf(t reflect.Type) jsonSchema {
+	nullableNumber := jsonSchema{"type": []string{"number", "null"}}
+	switch t {
+	case reflect.TypeFor[jsonFloat]():
+		return nullableNumber
+	case reflect.TypeFor[jsonRows](), reflect.TypeFor[resultRows]():
+		return jsonSchema{"type": "array", "items": jsonSchema{"type": "array", "items": nullableNumber}}
+	case reflect.TypeFor[time.Time]():
+		return jsonSchema{"type": "string", "format": "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return jsonSchema{"type": "boolean"}
+	case reflect.Int, reflect.Int32, reflect.Int64, reflect.Uint32, reflect.Uint64:
+		return jsonSchema{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return jsonSchema{"type": "number"}
+	case reflect.String:
+		return jsonSchema{"type": "string"}
+	case reflect.Slice:
+		return jsonSchema{"type": "array", "items": schemaOf(t.Elem())}
+	case reflect.Pointer:
+		return schemaOf(t.Elem())
+	case reflect.Struct:
+		props := jsonSchema{}
+		required := []string{}
+		for _, f := range jsonFields(t) {
+			ps := schemaOf(f.Type)
+			if f.Doc != "" {
+				ps["description"] = f.Doc
+			}
+			props[f.Name] = ps
+			if !f.OmitEmpty {
+				required = append(required, f.Name)
+			}
+		}
+		return jsonSchema{"type": "object", "properties": props, "required": required}
+	}
+	panic("openapi: unsupported type " + t.String())
+}
+
+// Схема параметров расчёта: значения по умолчанию и ограничения берутся из
+// того же кода, что их применяет
+func (s *server) requestSchema() jsonSchema {
+	t := reflect.TypeFor[simulateRequest]()
+	sch := schemaOf(t)
+	delete(sch, "required")
+	sch["additionalProperties"] = false
+	props := sch["properties"].(jsonSchema)
+
+	defaults, _ := simulateRequest{}.params()
+	dv := reflect.ValueOf(defaults)
+	for _, f := range jsonFields(t) {
+		if v := dv.FieldByName(f.GoName); v.IsValid() {
+			props[f.Name].(jsonSchema)["default"] = v.Interface()
+		}
+	}
+
+	props["method"].(jsonSchema)["examples"] = solver.Methods()
+	dx := props["dx"].(jsonSchema)
+	dx["exclusiveMinimum"] = 0
+	dx["maximum"] = 0.5
+	if s.limits.MaxNx > 0 {
+		dx["minimum"] = 1 / float64(s.limits.MaxNx)
+	}
+	props["dt"].(jsonSchema)["exclusiveMinimum"] = 0
+	props["tmax"].(jsonSchema)["exclusiveMinimum"] = 0
+	props["stride"].(jsonSchema)["minimum"] = 0
+	props["ic"].(jsonSchema)["enum"] = []string{config.ICSine, config.ICTopHat}
+	// Значение по умолчанию зависит от метода и ic
+	delete(props["rannacher"].(jsonSchema), "default")
+	props["reference"].(jsonSchema)["enum"] = []string{refSpectral}
+	props["storage"].(jsonSchema)["enum"] = []string{config.StorageFull, config.StorageFinalOnly}
+	props["precision"].(jsonSchema)["enum"] = []string{config.PrecisionFloat64, config.PrecisionFloat32}
+	props["spatial_order"].(jsonSchema)["enum"] = []int{2, 4}
+	props["stencil"].(jsonSchema)["enum"] = []string{config.StencilCompact, config.StencilWide}
+	props["lambda"].(jsonSchema)["minimum"] = 0
+	tol := props["tol"].(jsonSchema)
+	tol["default"] = config.DefaultTol
+	tol["exclusiveMinimum"] = 0
+	samples := props["ic_samples"].(jsonSchema)
+	samples["minItems"] = 2
+	samples["maxItems"] = config.MaxICSamples
+
+	sch["description"] = "Grid limits (0 means unlimited): nx = 1/dx ≤ max_nx, nt = tmax/dt ≤ max_nt, (nx+1)(nt+1) ≤ max_cells."
+	sch["x-limits"] = jsonSchema{
+		"max_nx":    s.limits.MaxNx,
+		"max_nt":    s.limits.MaxNt,
+		"max_cells": s.limits.MaxCells,
+	}
+	return sch
+}
+
+// Параметры строки запроса из свойств схемы
+func queryParams(t reflect.Type, sch jsonSchema) []jsonSchema {
+	props := sch["properties"].(jsonSchema)
+	var out []jsonSchema
+	for _, f := range jsonFields(t) {
+		ps := props[f.Name].(jsonSchema)
+		// Составные поля передаются только в JSON-теле
+		if t := ps["type"]; t == "array" || t == "object" {
+			continue
+		}
+		param := jsonSchema{"name": f.Name, "in": "query", "schema": ps}
+		if d, ok := ps["description"]; ok {
+			param["description"] = d
+		}
+		out = append(out, param)
+	}
+	return out
+}
+
+func queryParam(name, desc string, schema jsonSchema) jsonSchema {
+	return jsonSchema{"name": name, "in": "query", "description": desc, "schema": schema}
+}
+
+// Параметры размера изображения с пределами из image.go
+func imageParams(defW, defH int) []jsonSchema {
+	side := func(def int) jsonSchema {
+		return jsonSchema{"type": "integer", "minimum": 1, "maximum": maxImageSide, "default": def}
+	}
+	return []jsonSchema{
+		queryParam("width", "Image width in pixels", side(defW)),
+		queryParam("height", fmt.Sprintf("Image height in pixels; width*height ≤ %d", maxImagePixels), side(defH)),
+	}
+}
+
+func jsonContent(schema jsonSchema) jsonSchema {
+	return jsonSchema{"application/json": jsonSchema{"schema": schema}}
+}
+
+func ref(name string) jsonSchema {
+	return jsonSchema{"$ref": "#/components/schemas/" + name}
+}
+
+func okResponse(desc, contentType string, schema jsonSchema) jsonSchema {
+	return jsonSchema{"description": desc, "content": jsonSchema{contentType: jsonSchema{"schema": schema}}}
+}
+
+// Ответ с выбором формата по Accept: к JSON добавляются CSV, NDJSON и PNG
+func negotiated(resp jsonSchema) jsonSchema {
+	content := resp["content"].(jsonSchema)
+	text := jsonSchema{"type": "string"}
+	content[mediaCSV] = jsonSchema{"schema": text, "example": "x,t,u\n0,0,0\n0.1,0,0.309\n"}
+	content[mediaNDJSON] = jsonSchema{"schema": text, "example": `{"t":0,"u":[0,0.309,0]}` + "\n"}
+	content[mediaPNG] = jsonSchema{"schema": jsonSchema{"type": "string", "format": "binary"}}
+	resp["description"] = resp["description"].(string) + "; format chosen by Accept (" + strings.Join(resultMedia, ", ") + ")"
+	return resp
+}
+
+// Ответы с ошибками, общие для всех эндпоинтов расчёта
+func solveErrors(responses jsonSchema) jsonSchema {
+	errResp := func(desc string) jsonSchema {
+		return jsonSchema{"description": desc, "content": jsonContent(ref("Error"))}
+	}
+	responses["400"] = errResp("Invalid parameter")
+	responses["413"] = errResp("Grid or output size exceeds a server limit")
+	responses["504"] = errResp("Request deadline exceeded")
+	responses["500"] = errResp("Internal error")
+	return responses
+}
+
+// Документ OpenAPI 3.1, построенный из типов запросов и ответов
+func (s *server) openAPISpec(prefix string) jsonSchema {
+	reqType := reflect.TypeFor[simulateRequest]()
+	reqSchema := s.requestSchema()
+	simParams := queryParams(reqType, reqSchema)
+
+	errSchema := schemaOf(reflect.TypeFor[errorEnvelope]())
+	errProps := errSchema["properties"].(jsonSchema)["error"].(jsonSchema)["properties"].(jsonSchema)
+	errProps["code"].(jsonSchema)["enum"] = []string{
+		codeInvalidParameter, codeUnauthorized, codeForbidden, codeLimitExceeded, codeDiverged, codeNoReference, codeConflict, codeJobNotReady, codeTimeout,
+		codeNotFound, codeNotAcceptable, codeMethodNotAllowed, codeInternal,
+	}
+
+	cacheHeader := jsonSchema{"X-Cache": jsonSchema{
+		"description": "HIT when the result came from the server cache",
+		"schema":      jsonSchema{"type": "string", "enum": []string{"HIT", "MISS"}},
+	}}
+	simulateOK := negotiated(okResponse("Solution", "application/json", ref("SimulateResponse")))
+	simulateOK["headers"] = cacheHeader
+
+	heatmapParams := append(imageParams(800, 400),
+		queryParam("colormap", "Color map", jsonSchema{"type": "string", "enum": render.ColormapNames(), "default": "hot"}),
+		queryParam("vmin", "Lower end of the color scale; requires vmax", jsonSchema{"type": "number"}),
+		queryParam("vmax", "Upper end of the color scale; requires vmin", jsonSchema{"type": "number"}),
+	)
+	pngParams := append(slices.Clone(simParams), heatmapParams...)
+	// Параметры картинки для Accept: image/png на эндпоинтах с выбором формата
+	acceptPNGParams := make([]jsonSchema, len(heatmapParams))
+	for i, p := range heatmapParams {
+		p = maps.Clone(p)
+		p["description"] = p["description"].(string) + " (Accept: image/png only)"
+		acceptPNGParams[i] = p
+	}
+	// Параметры всех форматов на эндпоинтах с выбором по Accept
+	acceptParams := append(slices.Clone(acceptPNGParams),
+		queryParam("columns", "CSV columns: numeric (x,t,u) or all, which adds u_exact and error = |u − u_exact| "+
+			"and needs a reference solution, otherwise 422 no_reference (Accept: text/csv only)",
+			jsonSchema{"type": "string", "enum": []string{heatio.ColumnsNumeric, heatio.ColumnsAll}, "default": heatio.ColumnsNumeric}),
+	)
+	noReference := jsonSchema{
+		"description": "columns=all without a reference solution (code no_reference)",
+		"content":     jsonContent(ref("Error")),
+	}
+	notAcceptable := jsonSchema{
+		"description": "No supported format matches Accept (code not_acceptable, with the supported list)",
+		"content":     jsonContent(ref("Error")),
+	}
+
+	gifParams := slices.DeleteFunc(slices.Clone(simParams), func(p jsonSchema) bool { return p["name"] == "stride" })
+	gifParams = append(gifParams, imageParams(400, 200)...)
+	gifParams = append(gifParams,
+		queryParam("stride", fmt.Sprintf("Time levels per frame; at most %d frames", maxGIFFrames),
+			jsonSchema{"type": "integer", "minimum": 1, "default": 10}),
+		queryParam("delay", "Frame delay in hundredths of a second",
+			jsonSchema{"type": "integer", "minimum": 0, "maximum": 1000, "default": 5}),
+	)
+
+	framesParams := slices.DeleteFunc(slices.Clone(simParams), func(p jsonSchema) bool { return p["name"] == "precision" })
+	framesParams = append(framesParams,
+		queryParam("precision", "float32: u = offset + scale·v with error ≤ (max−min)·2⁻²⁵; float64: exact. "+
+			"Given explicitly, it also sets the precision of the stored levels",
+			jsonSchema{"type": "string", "enum": []string{config.PrecisionFloat32, config.PrecisionFloat64}, "default": config.PrecisionFloat32}),
+	)
+
+	// Эндпоинты расчёта при включённых ключах требуют авторизации
+	secured := func(op jsonSchema) jsonSchema {
+		if s.keys.enabled() {
+			op["security"] = []jsonSchema{{"bearerKey": []string{}}, {"headerKey": []string{}}}
+			responses := op["responses"].(jsonSchema)
+			responses["401"] = jsonSchema{"description": "Missing, malformed or unknown API key", "content": jsonContent(ref("Error"))}
+			responses["403"] = jsonSchema{"description": "Revoked API key", "content": jsonContent(ref("Error"))}
+		}
+		return op
+	}
+
+	analyticalParams := slices.DeleteFunc(slices.Clone(simParams), func(p jsonSchema) bool { return p["name"] == "method" })
+	analyticalParams = append(analyticalParams,
+		queryParam("nx", "Number of spatial intervals, instead of dx", jsonSchema{"type": "integer", "minimum": 1}),
+		queryParam("nt", "Number of time steps, instead of dt", jsonSchema{"type": "integer", "minimum": 1}),
+		queryParam("alpha", "Diffusivity", jsonSchema{"type": "number", "exclusiveMinimum": 0, "default": 1}),
+		queryParam("ic", "Initial condition", jsonSchema{"type": "string", "default": referenceIC}),
+		queryParam("bc", "Boundary conditions", jsonSchema{"type": "string", "default": referenceBC}),
+	)
+
+	jobIDParam := jsonSchema{"name": "id", "in": "path", "required": true, "schema": jsonSchema{"type": "string", "pattern": "^[0-9a-f]{16}$"}}
+
+	compareSchema := schemaOf(reflect.TypeFor[compareRequest]())
+	delete(compareSchema, "required")
+	compareSchema["additionalProperties"] = false
+	for _, name := range []string{"dx", "dt", "tmax"} {
+		compareSchema["properties"].(jsonSchema)[name] = reqSchema["properties"].(jsonSchema)[name]
+	}
+	compareSchema["x-limits"] = reqSchema["x-limits"]
+	compareSchema["description"] = "The cell limit applies to the sum over all compared methods."
+
+	// Поля simulateRequest встроены и в схеме копируются явно
+	evaluateSchema := schemaOf(reflect.TypeFor[evaluateRequest]())
+	evaluateSchema["additionalProperties"] = false
+	evaluateSchema["required"] = []string{"measurements"}
+	maps.Copy(evaluateSchema["properties"].(jsonSchema), reqSchema["properties"].(jsonSchema))
+	evaluateSchema["x-limits"] = jsonSchema{"max_points": maxEvaluatePoints, "max_body_bytes": maxEvaluateBody}
+	evaluateQuery := append(slices.Clone(simParams),
+		queryParam("alpha", "Diffusivity (multipart only)", jsonSchema{"type": "number", "exclusiveMinimum": 0, "default": 1}),
+		queryParam("fit_alpha", "Fit α (multipart only)", jsonSchema{"type": "boolean", "default": false}),
+		queryParam("alpha_min", "Lower bound of the α search (multipart only)", jsonSchema{"type": "number", "default": defaultAlphaMin}),
+		queryParam("alpha_max", "Upper bound of the α search (multipart only)", jsonSchema{"type": "number", "default": defaultAlphaMax}),
+	)
+
+	textOK := func(desc string) jsonSchema {
+		return okResponse(desc, "text/plain", jsonSchema{"type": "string"})
+	}
+
+	return jsonSchema{
+		"openapi": "3.1.0",
+		"info": jsonSchema{
+			"title":       "Heat solver API",
+			"version":     s.version.Version,
+			"description": "Numerical solution of the 1D heat equation u_t = u_xx on [0, 1] with u(0, t) = u(1, t) = 0.",
+		},
+		"paths": jsonSchema{
+			prefix + "/simulate": jsonSchema{
+				"get": secured(jsonSchema{
+					"summary":    "Run a simulation",
+					"parameters": append(slices.Clone(simParams), acceptParams...),
+					"responses":  solveErrors(jsonSchema{"200": simulateOK, "406": notAcceptable, "422": noReference}),
+				}),
+				"post": secured(jsonSchema{
+					"summary":     "Run a simulation with parameters in a JSON body",
+					"parameters":  acceptParams,
+					"requestBody": jsonSchema{"required": true, "content": jsonContent(ref("SimulateRequest"))},
+					"responses":   solveErrors(jsonSchema{"200": simulateOK, "406": notAcceptable, "422": noReference}),
+				}),
+			},
+			prefix + "/simulate.png": jsonSchema{
+				"get": secured(jsonSchema{
+					"summary":    "Space–time heatmap of the solution",
+					"parameters": pngParams,
+					"responses": solveErrors(jsonSchema{
+						"200": okResponse("PNG image", "image/png", jsonSchema{"type": "string", "format": "binary"}),
+					}),
+				}),
+			},
+			prefix + "/simulate.gif": jsonSchema{
+				"get": secured(jsonSchema{
+					"summary":    "Animated temperature profile",
+					"parameters": gifParams,
+					"responses": solveErrors(jsonSchema{
+						"200": okResponse("GIF animation, streamed frame by frame", "image/gif", jsonSchema{"type": "string", "format": "binary"}),
+					}),
+				}),
+			},
+			prefix + "/frames": jsonSchema{
+				"get": secured(jsonSchema{
+					"summary": "Solution as binary frames for Float32Array/Float64Array",
+					"description": "64-byte little-endian header: magic HEATFRM1, uint32 nx, nt, stride, frames, value size (4 or 8), flags (bit 0: diverged), " +
+						"float64 dx, dt, offset, scale. Then frames×(nx+1) values; frame k is time level min(k·stride, nt), u = offset + scale·v.",
+					"parameters": framesParams,
+					"responses": solveErrors(jsonSchema{
+						"200": okResponse("Header and frames", "application/octet-stream", jsonSchema{"type": "string", "format": "binary"}),
+					}),
+				}),
+			},
+			prefix + "/analytical": jsonSchema{
+				"get": secured(jsonSchema{
+					"summary":    "Exact solution on the same grid /simulate would use",
+					"parameters": analyticalParams,
+					"responses": solveErrors(jsonSchema{
+						"200": okResponse("Exact solution", "application/json", schemaOf(reflect.TypeFor[analyticalResponse]())),
+						"422": jsonSchema{"description": "No analytical reference for the chosen ic/bc (code no_reference)", "content": jsonContent(ref("Error"))},
+					}),
+				}),
+			},
+			prefix + "/compare": jsonSchema{
+				"post": secured(jsonSchema{
+					"summary":     "Run several schemes on the same grid and compare them with the exact solution",
+					"requestBody": jsonSchema{"required": true, "content": jsonContent(ref("CompareRequest"))},
+					"responses": solveErrors(jsonSchema{
+						"200": okResponse("Per-method final profiles", "application/json", schemaOf(reflect.TypeFor[compareResponse]())),
+					}),
+				}),
+			},
+			prefix + "/batch": jsonSchema{
+				"post": secured(jsonSchema{
+					"summary": "Run up to " + strconv.Itoa(maxBatchItems) + " parameter sets in one request",
+					"description": "Items run on the shared worker pool within the request deadline. An invalid item gets " +
+						"status error without affecting the others. The cell limit applies to the sum over all valid items.",
+					"parameters": []jsonSchema{
+						queryParam("include_fields", "Extra per-item fields, comma-separated", jsonSchema{"type": "string", "enum": []string{fieldFinalProfile}}),
+					},
+					"requestBody": jsonSchema{"required": true, "content": jsonContent(jsonSchema{
+						"type": "array", "minItems": 1, "maxItems": maxBatchItems, "items": ref("SimulateRequest"),
+					})},
+					"responses": solveErrors(jsonSchema{
+						"200": okResponse("Per-item results in input order", "application/json", schemaOf(reflect.TypeFor[batchResponse]())),
+					}),
+				}),
+			},
+			prefix + "/evaluate": jsonSchema{
+				"post": secured(jsonSchema{
+					"summary": "Compare measurements (x, t, u_obs) with a simulation",
+					"description": "The simulation is bilinearly interpolated to each point. Points outside x in [0, 1], t in [0, tmax] are " +
+						"listed with the reason and left out of the statistics. With fit_alpha the diffusivity minimising the squared " +
+						"residuals is searched in [alpha_min, alpha_max]. Parameters come from the JSON body, or from the query string " +
+						"when a CSV file (header x,t,u or x,t,u_obs) is uploaded as multipart part data.",
+					"parameters": evaluateQuery,
+					"requestBody": jsonSchema{"required": true, "content": jsonSchema{
+						"application/json": jsonSchema{"schema": evaluateSchema},
+						"multipart/form-data": jsonSchema{"schema": jsonSchema{
+							"type":       "object",
+							"required":   []string{"data"},
+							"properties": jsonSchema{"data": jsonSchema{"type": "string", "format": "binary"}},
+						}},
+					}},
+					"responses": solveErrors(jsonSchema{
+						"200": okResponse("Residuals and statistics", "application/json", schemaOf(reflect.TypeFor[evaluateResponse]())),
+					}),
+				}),
+			},
+			prefix + "/session": jsonSchema{
+				"get": secured(jsonSchema{
+					"summary": "Interactive WebSocket session",
+					"description": "Send {\"type\": \"params\", ...} with the SimulateRequest fields plus alpha; the server answers with " +
+						"params, frame and summary messages tagged with a run number. A new params message cancels the running solve; " +
+						"updates arriving within " + sessionDebounce.String() + " of each other start a single run. Errors arrive as " +
+						"{\"type\": \"error\", \"error\": Error} and keep the session open. REST limits apply to every run.",
+					"responses": jsonSchema{
+						"101": jsonSchema{"description": "Switching to the WebSocket protocol"},
+						"403": jsonSchema{"description": "Cross-origin browser request"},
+					},
+				}),
+			},
+			prefix + "/jobs": jsonSchema{
+				"post": secured(jsonSchema{
+					"summary":     "Queue a simulation as a background job",
+					"requestBody": jsonSchema{"required": true, "content": jsonContent(ref("SimulateRequest"))},
+					"responses": solveErrors(jsonSchema{
+						"202": okResponse("Job accepted; Location points at it", "application/json", ref("Job")),
+					}),
+				}),
+			},
+			prefix + "/jobs/{id}": jsonSchema{
+				"parameters": []jsonSchema{jobIDParam},
+				"get": secured(jsonSchema{
+					"summary": "Job status",
+					"responses": jsonSchema{
+						"200": okResponse("Job", "application/json", ref("Job")),
+						"404": jsonSchema{"description": "Unknown or expired job", "content": jsonContent(ref("Error"))},
+					},
+				}),
+			},
+			prefix + "/jobs/{id}/result": jsonSchema{
+				"parameters": []jsonSchema{jobIDParam},
+				"get": secured(jsonSchema{
+					"summary": "Solution of a finished job, streamed from the job store",
+					"parameters": append([]jsonSchema{
+						queryParam("stride", "Return every stride-th time level; the final one is always included",
+							jsonSchema{"type": "integer", "minimum": 1, "default": 1}),
+					}, acceptParams...),
+					"responses": jsonSchema{
+						"200": negotiated(okResponse("Solution", "application/json", jsonSchema{"type": "object"})),
+						"406": notAcceptable,
+						"422": noReference,
+						"404": jsonSchema{"description": "Unknown or expired job", "content": jsonContent(ref("Error"))},
+						"409": jsonSchema{"description": "Job not finished or failed (code job_not_ready)", "content": jsonContent(ref("Error"))},
+					},
+				}),
+			},
+			prefix + "/methods": jsonSchema{
+				"get": jsonSchema{
+					"summary":   "Available numerical schemes",
+					"responses": jsonSchema{"200": okResponse("Schemes", "application/json", schemaOf(reflect.TypeFor[methodsResponse]()))},
+				},
+			},
+			prefix + "/openapi.json": jsonSchema{
+				"get": jsonSchema{
+					"summary":   "This document",
+					"responses": jsonSchema{"200": okResponse("OpenAPI document", "application/json", jsonSchema{"type": "object"})},
+				},
+			},
+			"/healthz": jsonSchema{
+				"get": jsonSchema{"summary": "Liveness probe", "responses": jsonSchema{"200": textOK("Alive")}},
+			},
+			"/readyz": jsonSchema{
+				"get": jsonSchema{"summary": "Readiness probe", "responses": jsonSchema{
+					"200": textOK("Ready"),
+					"503": textOK("Starting or shutting down"),
+				}},
+			},
+			"/version": jsonSchema{
+				"get": jsonSchema{"summary": "Build information", "responses": jsonSchema{
+					"200": okResponse("Build information", "application/json", schemaOf(reflect.TypeFor[versionInfo]())),
+				}},
+			},
+			"/metrics": jsonSchema{
+				"get": jsonSchema{"summary": "Prometheus metrics", "responses": jsonSchema{"200": textOK("Metrics in text exposition format")}},
+			},
+		},
+		"components": jsonSchema{
+			"schemas": jsonSchema{
+				"SimulateRequest":  reqSchema,
+				"SimulateResponse": schemaOf(reflect.TypeFor[simulateResponse]()),
+				"CompareRequest":   compareSchema,
+				"Job":              schemaOf(reflect.TypeFor[jobMeta]()),
+				"Error":            errSchema,
+			},
+			"securitySchemes": jsonSchema{
+				"bearerKey": jsonSchema{"type": "http", "scheme": "bearer"},
+				"headerKey": jsonSchema{"type": "apiKey", "in": "header", "name": "X-API-Key"},
+			},
+		},
+	}
+}
+
+func (s *server) handleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s.openAPISpec("/api/v1"))
+}
+
+const docsPage = `<!DOCTYPE html>
+<html>
+<head>
+  <meta charset="utf-8">
+  <title>Heat solver API</title>
+</head>
+<body>
+  <redoc spec-url="/api/v1/openapi.json"></redoc>
+  <script src="https://cdn.redoc.ly/redoc/latest/bundles/redoc.standalone.js"></script>
+</body>
+</html>
+`
+
+// Страница документации (Redoc) поверх /api/v1/openapi.json
+func (s *server) handleDocs(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write([]byte(docsPage))
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"heat-solver/internal/config"
+)
+
+// Свойства запроса в спецификации для каждого поля config.Params. Новое
+// поле Params без строки здесь (и без поля в simulateRequest) роняет тест
+var paramProperties = map[string][]string{
+	"Method":       {"method"},
+	"Dx":           {"dx"},
+	"Dt":           {"dt"},
+	"Tmax":         {"tmax"},
+	"Outfile":      nil, // только CLI
+	"IC":           {"ic"},
+	"ICSamples":    {"ic_samples"},
+	"Storage":      {"storage"},
+	"Precision":    {"precision"},
+	"Adapt":        {"adapt"},
+	"Tol":          {"tol"},
+	"Rannacher":    {"rannacher"},
+	"SpatialOrder": {"spatial_order"},
+	"Stencil":      {"stencil"},
+	"Lambda":       {"lambda"},
+	"Source":       {"source"},
+	"Laser":        {"laser_power", "laser_speed", "laser_width", "laser_start"},
+}
+
+// Свойства запроса, не входящие в Params: представление ответа
+var responseOnlyProperties = []string{"stride", "reference", "thresholds"}
+
+// Спецификация, как её получает клиент
+func fetchSpec(t *testing.T) map[string]any {
+	t.Helper()
+	_, ts := newTestServer(t, func(cfg *serverConfig) {
+		cfg.Limits = config.Limits{MaxNx: 2000, MaxNt: 50000, MaxCells: 1 << 20}
+	})
+	_, body := get(t, ts, "/api/v1/openapi.json", 200)
+	var spec map[string]any
+	decode(t, body, &spec)
+	return spec
+}
+
+// Значение по пути из ключей объектов
+func lookup(t *testing.T, v any, path ...string) any {
+	t.Helper()
+	for i, key := range path {
+		obj, ok := v.(map[string]any)
+		if !ok {
+			t.Fatalf("%s is not an object", strings.Join(path[:i], "."))
+		}
+		if v, ok = obj[key]; !ok {
+			t.Fatalf("%s missing", strings.Join(path[:i+1], "."))
+		}
+	}
+	return v
+}
+
+func TestOpenAPIParamsInSync(t *testing.T) {
+	spec := fetchSpec(t)
+	props := lookup(t, spec, "components", "schemas", "SimulateRequest", "properties").(map[string]any)
+
+	covered := slices.Clone(responseOnlyProperties)
+	pt := reflect.TypeFor[config.Params]()
+	for i := range pt.NumField() {
+		f := pt.Field(i)
+		names, ok := paramProperties[f.Name]
+		if !ok {
+			t.Errorf("config.Params.%s is not in the spec: add it to simulateRequest and paramProperties", f.Name)
+			continue
+		}
+		for _, name := range names {
+			if _, ok := props[name]; !ok {
+				t.Errorf("config.Params.%s: property %s missing from SimulateRequest", f.Name, name)
+			}
+		}
+		covered = append(covered, names...)
+	}
+	for name := range props {
+		if !slices.Contains(covered, name) {
+			t.Errorf("SimulateRequest property %s maps to no config.Params field", name)
+		}
+	}
+	for name := range paramProperties {
+		if _, ok := pt.FieldByName(name); !ok {
+			t.Errorf("paramProperties lists %s, which config.Params no longer has", name)
+		}
+	}
+}
+
+func TestOpenAPIDocument(t *testing.T) {
+	spec := fetchSpec(t)
+	if v := lookup(t, spec, "openapi"); !strings.HasPrefix(v.(string), "3.") {
+		t.Errorf("openapi = %v, want version 3", v)
+	}
+
+	limits := lookup(t, spec, "components", "schemas", "SimulateRequest", "x-limits").(map[string]any)
+	if limits["max_nx"] != 2000.0 || limits["max_nt"] != 50000.0 || limits["max_cells"] != float64(1<<20) {
+		t.Errorf("x-limits = %v, want the configured limits", limits)
+	}
+	if v := lookup(t, spec, "components", "schemas", "SimulateRequest", "properties", "dx", "minimum"); v != 1/2000.0 {
+		t.Errorf("dx minimum = %v, want 1/max_nx", v)
+	}
+
+	codes := lookup(t, spec, "components", "schemas", "Error", "properties", "error", "properties", "code", "enum").([]any)
+	for _, code := range []string{codeInvalidParameter, codeLimitExceeded, codeDiverged, codeTimeout, codeNotFound, codeInternal} {
+		if !slices.Contains(codes, any(code)) {
+			t.Errorf("error code %s missing from the Error schema", code)
+		}
+	}
+
+	paths := lookup(t, spec, "paths").(map[string]any)
+	for _, p := range []string{
+		"/api/v1/simulate", "/api/v1/simulate.png", "/api/v1/simulate.gif",
+		"/api/v1/jobs", "/api/v1/jobs/{id}", "/api/v1/jobs/{id}/result",
+		"/api/v1/methods", "/api/v1/openapi.json", "/healthz", "/readyz",
+	} {
+		if _, ok := paths[p]; !ok {
+			t.Errorf("path %s missing", p)
+		}
+	}
+	bad := lookup(t, spec, "paths", "/api/v1/simulate", "get", "responses", "400", "content", "application/json", "schema", "$ref")
+	if bad != "#/components/schemas/Error" {
+		t.Errorf("400 response schema %v, want the error envelope", bad)
+	}
+}
+
+func TestDocsPage(t *testing.T) {
+	_, ts := newTestServer(t, nil)
+	resp, body := get(t, ts, "/docs", 200)
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/html") {
+		t.Errorf("Content-Type = %q, want HTML", ct)
+	}
+	if !strings.Contains(string(body), "/api/v1/openapi.json") {
+		t.Error("docs page does not load the spec")
+	}
+}
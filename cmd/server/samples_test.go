@@ -0,0 +1,74 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+// Треугольный профиль с вершиной в x = 0.3: слой t = 0 совпадает
+// с линейной интерполяцией выборки на узлах сетки
+func TestICSamples(t *testing.T) {
+	_, ts := newTestServer(t, nil)
+	const samples = `[{"x":0,"u":0},{"x":0.3,"u":1.5},{"x":1,"u":0}]`
+	triangle := func(x float64) float64 {
+		if x <= 0.3 {
+			return 1.5 * x / 0.3
+		}
+		return 1.5 * (1 - x) / 0.7
+	}
+
+	_, body := postJSON(t, ts, "/api/v1/simulate", `{"method":"CN","dx":0.05,"dt":0.01,"tmax":0.1,"ic_samples":`+samples+`}`, 200)
+	var res struct {
+		simulateResult
+		Reference string `json:"reference"`
+	}
+	decode(t, body, &res)
+	if res.IC != "samples" || res.Reference != "none" || res.Norms != nil {
+		t.Errorf("ic %q, reference %q, norms %+v; want samples, none and null norms", res.IC, res.Reference, res.Norms)
+	}
+	for i, x := range res.X {
+		if got, want := res.U[0][i], triangle(x); math.Abs(got-want) > 1e-12 {
+			t.Errorf("u(%g, 0) = %g, want %g", x, got, want)
+		}
+	}
+
+	// Спектральный эталон даёт нормы и для выборки
+	_, body = postJSON(t, ts, "/api/v1/simulate", `{"method":"CN","dx":0.05,"dt":0.01,"tmax":0.1,"reference":"spectral","ic_samples":`+samples+`}`, 200)
+	decode(t, body, &res)
+	if res.Reference != "spectral" || res.Norms == nil || !(res.Norms.LInf < 1e-2) {
+		t.Errorf("reference %q, norms %+v; want small spectral norms", res.Reference, res.Norms)
+	}
+
+	// Задача с выборкой помечается как custom_ic
+	_, body = postJSON(t, ts, "/api/v1/jobs", `{"dx":0.1,"tmax":0.01,"ic_samples":`+samples+`}`, 202)
+	var meta jobMeta
+	decode(t, body, &meta)
+	if !meta.CustomIC {
+		t.Error("job with ic_samples not marked custom_ic")
+	}
+}
+
+func TestICSamplesInvalid(t *testing.T) {
+	_, ts := newTestServer(t, nil)
+	tests := []struct {
+		name, samples string
+		status        int
+		code          string
+	}{
+		{"one point", `[{"x":0,"u":0}]`, 400, codeInvalidParameter},
+		{"not increasing", `[{"x":0,"u":0},{"x":0.5,"u":1},{"x":0.5,"u":1},{"x":1,"u":0}]`, 400, codeInvalidParameter},
+		{"short of the domain", `[{"x":0,"u":0},{"x":0.9,"u":0}]`, 400, codeInvalidParameter},
+		{"nonzero boundary", `[{"x":0,"u":1},{"x":1,"u":0}]`, 400, codeInvalidParameter},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, body := postJSON(t, ts, "/api/v1/simulate", `{"dx":0.1,"tmax":0.01,"ic_samples":`+tc.samples+`}`, tc.status)
+			var env errorEnvelope
+			decode(t, body, &env)
+			if env.Error.Code != tc.code || env.Error.Field != "ic_samples" {
+				t.Errorf("error %+v, want %s on ic_samples", env.Error, tc.code)
+			}
+		})
+	}
+	postJSON(t, ts, "/api/v1/simulate", `{"ic":"sine","ic_samples":[{"x":0,"u":0},{"x":1,"u":0}]}`, 422)
+}
@@ -1,69 +1,338 @@
 package main
 
 import (
-	"encoding/json"
-	"log"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net"
 	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
 	"strconv"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"google.golang.org/grpc"
 
 	"heat-solver/internal/config"
 	"heat-solver/internal/solver"
 )
 
+type server struct {
+	cache       *resultCache
+	limits      config.Limits
+	timeout     time.Duration
+	corsOrigins []string
+	metrics     *serverMetrics
+	version     versionInfo
+	keys        *keyStore
+	jobs        *jobManager
+	ready       atomic.Bool
+	web         http.Handler
+	// Отменяется при остановке: закрывает сессии WebSocket, которые
+	// http.Server.Shutdown не отслеживает
+	sessionsCtx context.Context
+}
+
+// Сервер из проверенной конфигурации; задачи подключаются отдельно,
+// так как им нужен метод simulate готового сервера
+func newServer(cfg serverConfig, keys *keyStore, web http.Handler, sessionsCtx context.Context) *server {
+	return &server{
+		cache:       newResultCache(cfg.Cache.Entries, cfg.Cache.Bytes, time.Duration(cfg.Cache.TTL), cfg.Cache.Failed),
+		limits:      cfg.Limits,
+		timeout:     time.Duration(cfg.RequestTimeout),
+		corsOrigins: cfg.CORSOrigins,
+		metrics:     newServerMetrics(),
+		version:     readVersionInfo(),
+		keys:        keys,
+		web:         web,
+		sessionsCtx: sessionsCtx,
+	}
+}
+
 func main() {
-	http.Handle("/", http.FileServer(http.Dir("./web")))
+	cfg, opts, err := loadServerConfig(os.Args[0], os.Args[1:], os.Getenv)
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "config:", err)
+		os.Exit(2)
+	}
 
-	http.HandleFunc("/simulate", func(w http.ResponseWriter, r *http.Request) {
-		method := r.URL.Query().Get("method")
-		if method == "" {
-			method = "FTCS"
-		}
-		dx, _ := strconv.ParseFloat(r.URL.Query().Get("dx"), 64)
-		if dx == 0 {
-			dx = 0.1
-		}
-		dt, _ := strconv.ParseFloat(r.URL.Query().Get("dt"), 64)
-		if dt == 0 {
-			dt = 0.001
-		}
-		tmax, _ := strconv.ParseFloat(r.URL.Query().Get("tmax"), 64)
-		if tmax == 0 {
-			tmax = 1.0
+	if opts.HashKey {
+		if err := printKeyHash(os.Stdin, os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
 		}
+		return
+	}
+	if err := cfg.validate(); err != nil {
+		fmt.Fprintln(os.Stderr, "invalid configuration:")
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if opts.PrintConfig {
+		cfg.writeJSON(os.Stdout, "  ")
+		return
+	}
 
-		nx := int(1.0 / dx)
-		nt := int(tmax / dt)
+	logger := slog.New(contextHandler{slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+		Level: slog.LevelInfo,
+	})})
+	slog.SetDefault(logger)
+	logConfig(opts.ConfigFile, cfg)
+	solver.SetThreads(cfg.SolverThreads)
+	solver.SetTridiagonal(cfg.Tridiagonal)
+	solver.SetResidualCheck(cfg.VerifyResidual)
 
-		params := config.Params{
-			Method: method,
-			Dx:     dx,
-			Dt:     dt,
-			Tmax:   tmax,
-		}
+	keys, err := loadKeyStore(cfg.APIKeysFile, cfg.APIKeys)
+	if err != nil {
+		slog.Error("Cannot load API keys", "error", err)
+		os.Exit(1)
+	}
+	if keys.enabled() {
+		slog.Info("API key auth enabled", "keys", len(keys.keys))
+	}
+
+	webUI, err := webHandler(cfg.WebDir)
+	if err != nil {
+		slog.Error("Cannot load web UI", "error", err)
+		os.Exit(1)
+	}
+
+	lc := cfg.listenConfig
+	if err := lc.validateTLS(); err != nil {
+		slog.Error("Invalid TLS configuration", "error", err)
+		os.Exit(1)
+	}
+	ln, err := lc.listen()
+	if err != nil {
+		slog.Error("Cannot start listener", "error", err)
+		os.Exit(1)
+	}
 
-		var u [][]float64
-		switch params.Method {
-		case "FTCS":
-			u = solver.SolveFTCS(nx, nt, params.Dx, params.Dt)
-		case "BTCS":
-			u = solver.SolveBTCS(nx, nt, params.Dx, params.Dt)
-		case "CN":
-			u = solver.SolveCrankNicolson(nx, nt, params.Dx, params.Dt)
-		default:
-			http.Error(w, "Unknown method", http.StatusBadRequest)
-			return
+	sessionsCtx, closeSessions := context.WithCancel(context.Background())
+	s := newServer(cfg, keys, webUI, sessionsCtx)
+	solver.SetPoolObserver(func(outcome string) { s.metrics.bufferPool.Inc(outcome) })
+
+	var store jobStore = newMemJobStore()
+	if cfg.DataDir != "" {
+		if store, err = newFSJobStore(cfg.DataDir); err != nil {
+			slog.Error("Cannot open data directory", "dir", cfg.DataDir, "error", err)
+			os.Exit(1)
 		}
+	}
+	run := func(ctx context.Context, p config.Params) (*solver.Result, error) {
+		res, _, err := s.simulate(ctx, p)
+		return res, err
+	}
+	if s.jobs, err = newJobManager(store, cfg.Jobs, run, s.metrics.jobQueue); err != nil {
+		slog.Error("Cannot load jobs", "dir", cfg.DataDir, "error", err)
+		os.Exit(1)
+	}
+	s.jobs.sweep(time.Now())
+	go s.jobs.sweepEvery(time.Minute)
+
+	httpServer := &http.Server{Handler: s.routes()}
+	httpServer.RegisterOnShutdown(closeSessions)
 
-		response := map[string]interface{}{
-			"dx": params.Dx,
-			"dt": params.Dt,
-			"u":  u,
+	var grpcServer *grpc.Server
+	if cfg.GRPCAddr != "" {
+		gln, err := net.Listen("tcp", cfg.GRPCAddr)
+		if err != nil {
+			slog.Error("Cannot start gRPC listener", "addr", cfg.GRPCAddr, "error", err)
+			os.Exit(1)
 		}
+		grpcServer = s.newGRPCServer()
+		go func() {
+			if err := grpcServer.Serve(gln); err != nil {
+				slog.Error("gRPC server failed", "error", err)
+			}
+		}()
+		slog.Info("gRPC server running", "addr", gln.Addr().String())
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Serve возвращается сразу после начала Shutdown, поэтому main ждёт done
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		<-ctx.Done()
+		s.shutdown(httpServer, grpcServer, time.Duration(cfg.DrainDelay), time.Duration(cfg.ShutdownTimeout))
+	}()
+
+	s.ready.Store(true)
+	err = lc.serve(httpServer, ln)
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		slog.Error("Server failed", "error", err)
+		os.Exit(1)
+	}
+	<-done
+}
+
+// Плавная остановка: /readyz отвечает 503 в течение drainDelay, пока
+// балансировщик выводит экземпляр, затем закрываются слушатели и
+// дожидаются начатые запросы и задачи — не дольше timeout
+func (s *server) shutdown(httpServer *http.Server, grpcServer *grpc.Server, drainDelay, timeout time.Duration) {
+	slog.Info("Shutting down: readiness off")
+	s.ready.Store(false)
+	time.Sleep(drainDelay)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if grpcServer != nil {
+		// GracefulStop не знает о таймауте, поэтому при его истечении — Stop
+		go func() {
+			<-shutdownCtx.Done()
+			grpcServer.Stop()
+		}()
+		defer grpcServer.GracefulStop()
+	}
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		slog.Error("Shutdown error", "error", err)
+	}
+	s.jobs.close(shutdownCtx)
+}
+
+// Устаревший /simulate: прежняя минимальная форма ответа {dx, dt, u}
+func (s *server) handleSimulateLegacy(w http.ResponseWriter, r *http.Request) {
+	req, err := requestFromQuery(r.URL.Query())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	params, err := req.params()
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
 
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(response)
-	})
+	ctx, cancel := context.WithTimeout(r.Context(), s.timeout)
+	defer cancel()
+	res, hit, err := s.simulate(ctx, params)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	defer res.Release()
+	setCacheHeader(w, hit)
+
+	response := legacySimulateResponse{Dt: res.Dt, Dx: res.Dx, U: newResultRows(res, req.Stride)}
+	if req.Stride > 1 {
+		response.Stride = req.Stride
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := writeJSONStream(w, response); err != nil {
+		slog.ErrorContext(r.Context(), "Result streaming aborted", "format", mediaJSON, "error", err)
+	}
+}
+
+// Ответ устаревшего /simulate; поля в порядке ключей прежнего ответа-map
+type legacySimulateResponse struct {
+	Dt     float64    `json:"dt"`
+	Dx     float64    `json:"dx"`
+	Stride int        `json:"stride,omitempty"`
+	U      resultRows `json:"u"`
+}
+
+// Общий путь всех эндпоинтов расчёта: проверка лимитов и кэш.
+// Результат освобождается вызывающим через Release (см. solve)
+func (s *server) simulate(ctx context.Context, params config.Params) (*solver.Result, bool, error) {
+	if err := s.limits.Check(params); err != nil {
+		return nil, false, err
+	}
+	return s.solve(ctx, params)
+}
+
+func setCacheHeader(w http.ResponseWriter, hit bool) {
+	if hit {
+		w.Header().Set("X-Cache", "HIT")
+	} else {
+		w.Header().Set("X-Cache", "MISS")
+	}
+}
+
+// Вещественный параметр запроса; пустое значение или 0 — значение по умолчанию
+func floatParam(q url.Values, name string, def float64) (float64, error) {
+	v := q.Get(name)
+	if v == "" {
+		return def, nil
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return 0, paramError(name, "must be a number")
+	}
+	if f == 0 {
+		return def, nil
+	}
+	return f, nil
+}
+
+// Целочисленный параметр запроса со значением по умолчанию
+func intParam(q url.Values, name string, def int) (int, error) {
+	v := q.Get(name)
+	if v == "" {
+		return def, nil
+	}
+	return strconv.Atoi(v)
+}
+
+// Расчёт с учётом кэша; второе значение — попадание в кэш. Слои
+// результата могут лежать в пуле буферов: после использования результата
+// вызывающий освобождает его через Release
+func (s *server) solve(ctx context.Context, params config.Params) (*solver.Result, bool, error) {
+	key := cacheKey(params)
+	if v, ok := s.cache.Get(key); ok {
+		s.metrics.cacheHits.Inc()
+		return v.(*solver.Result), true, nil
+	}
+	s.metrics.cacheMisses.Inc()
+
+	res, err := solver.Run(ctx, params, s.metrics.solverHooks())
+	if err != nil {
+		return nil, false, err
+	}
+	s.cache.Add(key, res, res.Bytes(), res.Diverged)
+	return res, false, nil
+}
+
+// Номера временных слоёв при прореживании: каждый stride-й плюс последний
+func levelIndices(nt, stride int) []int {
+	stride = max(1, stride)
+	idx := make([]int, 0, nt/stride+2)
+	for n := 0; n <= nt; n += stride {
+		idx = append(idx, n)
+	}
+	if nt%stride != 0 {
+		idx = append(idx, nt)
+	}
+	return idx
+}
+
+// Прореживание по времени, которое фактически применяется к результату:
+// в final-only хранятся только начальный и последний слои
+func resultStride(res *solver.Result, stride int) int {
+	return max(1, stride, res.LevelStep())
+}
 
-	log.Println("🚀 Server running on http://localhost:8080")
-	http.ListenAndServe(":8080", nil)
+// Прореживание по времени. Кэшированный массив не изменяется.
+func thinLevels(res *solver.Result, stride int) [][]float64 {
+	if stride <= 1 && res.LevelStep() == 1 {
+		return res.Rows()
+	}
+	step := res.LevelStep()
+	idx := levelIndices(res.Nt, resultStride(res, stride))
+	out := make([][]float64, len(idx))
+	for k, n := range idx {
+		out[k] = res.Level(n / step)
+	}
+	return out
 }
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"heat-solver/internal/config"
+	"heat-solver/internal/solver"
+)
+
+// Журнал сервера в тестах не нужен: проверки записей журнала подменяют
+// обработчик сами
+func TestMain(m *testing.M) {
+	slog.SetDefault(slog.New(contextHandler{slog.NewTextHandler(io.Discard, nil)}))
+	os.Exit(m.Run())
+}
+
+// Сервер в той же сборке, что и в main, с конфигурацией по умолчанию,
+// изменённой change, за httptest.Server
+func newTestServer(t *testing.T, change func(cfg *serverConfig)) (*server, *httptest.Server) {
+	t.Helper()
+	cfg := defaultServerConfig()
+	if change != nil {
+		change(&cfg)
+	}
+	if err := cfg.validate(); err != nil {
+		t.Fatalf("invalid test configuration: %v", err)
+	}
+	keys, err := loadKeyStore(cfg.APIKeysFile, cfg.APIKeys)
+	if err != nil {
+		t.Fatalf("loadKeyStore: %v", err)
+	}
+	web, err := webHandler(cfg.WebDir)
+	if err != nil {
+		t.Fatalf("webHandler: %v", err)
+	}
+
+	sessionsCtx, closeSessions := context.WithCancel(context.Background())
+	s := newServer(cfg, keys, web, sessionsCtx)
+
+	var store jobStore = newMemJobStore()
+	if cfg.DataDir != "" {
+		if store, err = newFSJobStore(cfg.DataDir); err != nil {
+			t.Fatalf("newFSJobStore: %v", err)
+		}
+	}
+	run := func(ctx context.Context, p config.Params) (*solver.Result, error) {
+		res, _, err := s.simulate(ctx, p)
+		return res, err
+	}
+	if s.jobs, err = newJobManager(store, cfg.Jobs, run, s.metrics.jobQueue); err != nil {
+		t.Fatalf("newJobManager: %v", err)
+	}
+
+	ts := httptest.NewServer(s.routes())
+	s.ready.Store(true)
+	t.Cleanup(func() {
+		closeSessions()
+		ts.Close()
+		s.jobs.close(context.Background())
+	})
+	return s, ts
+}
+
+// GET с проверкой кода ответа; тело возвращается целиком
+func get(t *testing.T, ts *httptest.Server, path string, want int) (*http.Response, []byte) {
+	t.Helper()
+	return do(t, ts, http.MethodGet, path, "", want)
+}
+
+// POST тела JSON с проверкой кода ответа
+func postJSON(t *testing.T, ts *httptest.Server, path, body string, want int) (*http.Response, []byte) {
+	t.Helper()
+	return do(t, ts, http.MethodPost, path, body, want)
+}
+
+func do(t *testing.T, ts *httptest.Server, method, path, body string, want int) (*http.Response, []byte) {
+	t.Helper()
+	req, err := http.NewRequest(method, ts.URL+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return send(t, ts, req, want)
+}
+
+// Готовый запрос (с нужными заголовками) с проверкой кода ответа
+func send(t *testing.T, ts *httptest.Server, req *http.Request, want int) (*http.Response, []byte) {
+	t.Helper()
+	resp, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatalf("%s %s: %v", req.Method, req.URL.Path, err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("%s %s: reading body: %v", req.Method, req.URL.Path, err)
+	}
+	if resp.StatusCode != want {
+		t.Fatalf("%s %s: status %d, want %d; body %q", req.Method, req.URL.Path, resp.StatusCode, want, excerpt(data))
+	}
+	return resp, data
+}
+
+// Строка ключа name:sha256hex[:revoked] для APIKeys
+func keyEntry(name, key string, revoked bool) string {
+	h := hashKey(key)
+	entry := name + ":" + hex.EncodeToString(h[:])
+	if revoked {
+		entry += ":revoked"
+	}
+	return entry
+}
+
+// Разбор тела JSON в v
+func decode(t *testing.T, data []byte, v any) {
+	t.Helper()
+	if err := json.Unmarshal(data, v); err != nil {
+		t.Fatalf("invalid JSON %q: %v", excerpt(data), err)
+	}
+}
+
+// Начало тела для сообщений об ошибке
+func excerpt(data []byte) []byte {
+	if len(data) > 200 {
+		return append(data[:200:200], "..."...)
+	}
+	return data
+}
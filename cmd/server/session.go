@@ -0,0 +1,367 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"golang.org/x/net/websocket"
+
+	"heat-solver/internal/config"
+	heatio "heat-solver/internal/io"
+	"heat-solver/internal/mathutils"
+	"heat-solver/internal/render"
+	"heat-solver/internal/solver"
+)
+
+// Интерактивная сессия по WebSocket. Клиент присылает параметры, сервер
+// считает и отправляет кадры; новые параметры прерывают текущий расчёт.
+// В сессии не больше одного расчёта одновременно, частые обновления
+// схлопываются: запускается последнее, пришедшее после паузы sessionDebounce.
+//
+// Клиент → сервер:
+//
+//	{"type": "params", "method": ..., "dx": ..., "dt": ..., "tmax": ..., "alpha": ..., "stride": ..., "ic_samples": ...}
+//
+// Сервер → клиент (run — номер запуска; кадры прерванного запуска обрываются):
+//
+//	{"type": "params", "run": 1, ...}  — принятые параметры запуска
+//	{"type": "frame", "run": 1, "n": ..., "t": ..., "u": [...]}
+//	{"type": "summary", "run": 1, ...} — запуск завершён
+//	{"type": "error", "run": 1, "error": {...}} — тело как у REST-ошибок
+const (
+	msgParams  = "params"
+	msgFrame   = "frame"
+	msgSummary = "summary"
+	msgError   = "error"
+)
+
+const (
+	sessionDebounce     = 150 * time.Millisecond
+	sessionMaxMessage   = 1 << 20
+	sessionWriteTimeout = 10 * time.Second
+	// Кадров на запуск, если stride не задан
+	sessionDefaultFrames = 100
+)
+
+type sessionRequest struct {
+	Type  string  `json:"type"`
+	Alpha float64 `json:"alpha"`
+	simulateRequest
+}
+
+type sessionStarted struct {
+	Type      string    `json:"type"`
+	Run       int       `json:"run"`
+	Method    string    `json:"method"`
+	Nx        int       `json:"nx"`
+	Nt        int       `json:"nt"`
+	Dx        float64   `json:"dx"`
+	Dt        float64   `json:"dt"`
+	Tmax      float64   `json:"tmax"`
+	Alpha     float64   `json:"alpha"`
+	R         float64   `json:"r"`
+	Stride    int       `json:"stride"`
+	Frames    int       `json:"frames"`
+	Precision string    `json:"precision"`
+	X         []float64 `json:"x"`
+}
+
+// Кадр с готовой JSON-строкой u: при float32 значения округляются до
+// float32 и записываются кратчайшей для него записью
+type sessionFrame struct {
+	Type string          `json:"type"`
+	Run  int             `json:"run"`
+	N    int             `json:"n"`
+	T    float64         `json:"t"`
+	U    json.RawMessage `json:"u"`
+}
+
+type sessionSummary struct {
+	Type       string         `json:"type"`
+	Run        int            `json:"run"`
+	Frames     int            `json:"frames"`
+	RuntimeSec float64        `json:"runtime_sec"`
+	Diverged   bool           `json:"diverged"`
+	Reference  string         `json:"reference"`
+	Norms      *normsResponse `json:"norms"`
+}
+
+type sessionError struct {
+	Type  string    `json:"type"`
+	Run   int       `json:"run,omitempty"`
+	Error errorBody `json:"error"`
+}
+
+// Параметры запуска. Коэффициент α учитывается заменой времени τ = αt:
+// схема с шагом α·dt до α·tmax даёт то же решение на тех же слоях
+type sessionRun struct {
+	req    sessionRequest
+	params config.Params
+	scaled config.Params
+	method string
+	stride int
+}
+
+func (s *server) sessionRun(req sessionRequest) (sessionRun, error) {
+	if req.Alpha == 0 {
+		req.Alpha = 1
+	}
+	if !(req.Alpha > 0) || math.IsInf(req.Alpha, 0) {
+		return sessionRun{}, paramError("alpha", "must be positive")
+	}
+	p, err := req.params()
+	if err != nil {
+		return sessionRun{}, err
+	}
+	m, ok := solver.Lookup(p.Method)
+	if !ok {
+		return sessionRun{}, paramError("method", fmt.Sprintf("unknown method %q", p.Method))
+	}
+	if req.Alpha != 1 {
+		if err := checkTimeScaling(p); err != nil {
+			return sessionRun{}, err
+		}
+	}
+	if err := s.limits.Check(p); err != nil {
+		return sessionRun{}, err
+	}
+
+	_, nt := p.Grid()
+	stride := req.Stride
+	if stride == 0 {
+		stride = max(1, (nt+sessionDefaultFrames-1)/sessionDefaultFrames)
+	}
+	if frames := render.FrameCount(nt+1, stride); frames > maxGIFFrames {
+		return sessionRun{}, &config.LimitError{Field: "frames", Limit: maxGIFFrames, Value: int64(frames)}
+	}
+
+	scaled := p
+	scaled.Dt *= req.Alpha
+	scaled.Tmax *= req.Alpha
+	return sessionRun{req: req, params: p, scaled: scaled, method: m.Name, stride: stride}, nil
+}
+
+type session struct {
+	s  *server
+	ws *websocket.Conn
+	mu sync.Mutex // запись в соединение
+}
+
+func (ss *session) send(v any) error {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	ss.ws.SetWriteDeadline(time.Now().Add(sessionWriteTimeout))
+	return websocket.JSON.Send(ss.ws, v)
+}
+
+func (ss *session) sendError(run int, err error) {
+	status, body := classifyError(err)
+	if status == http.StatusInternalServerError {
+		slog.Error("Session run failed", "run", run, "error", err)
+	}
+	ss.send(sessionError{Type: msgError, Run: run, Error: body})
+}
+
+// Чтение сообщений клиента до закрытия соединения. Некорректные сообщения
+// получают ответ error и не прерывают сессию
+func (ss *session) readLoop(ctx context.Context, updates chan<- sessionRequest) {
+	defer close(updates)
+	for {
+		var data []byte
+		if err := websocket.Message.Receive(ss.ws, &data); err != nil {
+			return
+		}
+		var req sessionRequest
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&req); err != nil {
+			ss.sendError(0, paramError("body", err.Error()))
+			continue
+		}
+		if req.Type != msgParams {
+			ss.sendError(0, paramError("type", fmt.Sprintf("unknown message type %q, want %q", req.Type, msgParams)))
+			continue
+		}
+		select {
+		case updates <- req:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Один запуск: кадры каждого stride-го слоя, затем итог. Отмена ctx
+// (новые параметры, закрытие сессии) обрывает поток кадров без сообщения
+func (ss *session) run(ctx context.Context, run int, req sessionRequest) {
+	sr, err := ss.s.sessionRun(req)
+	if err != nil {
+		ss.sendError(run, err)
+		return
+	}
+	p := sr.params
+	nx, nt := p.Grid()
+	x := make([]float64, nx+1)
+	for i := range x {
+		x[i] = float64(i) * p.Dx
+	}
+	err = ss.send(sessionStarted{
+		Type:      msgParams,
+		Run:       run,
+		Method:    sr.method,
+		Nx:        nx,
+		Nt:        nt,
+		Dx:        p.Dx,
+		Dt:        p.Dt,
+		Tmax:      float64(nt) * p.Dt,
+		Alpha:     sr.req.Alpha,
+		R:         sr.scaled.Dt / (p.Dx * p.Dx),
+		Stride:    sr.stride,
+		Frames:    render.FrameCount(nt+1, sr.stride),
+		Precision: p.Precision,
+		X:         x,
+	})
+	if err != nil {
+		return
+	}
+
+	bits := 64
+	if p.Precision == config.PrecisionFloat32 {
+		bits = 32
+	}
+	frames := 0
+	res, err := solver.Stream(ctx, sr.scaled, ss.s.metrics.solverHooks(), func(n int, row []float64) error {
+		if n%sr.stride != 0 && n != nt {
+			return nil
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		frames++
+		u := heatio.AppendJSONRow(nil, row, bits)
+		return ss.send(sessionFrame{Type: msgFrame, Run: run, N: n, T: float64(n) * p.Dt, U: u})
+	})
+	if errors.Is(err, context.Canceled) {
+		return
+	}
+	if err != nil {
+		ss.sendError(run, err)
+		return
+	}
+
+	summary := sessionSummary{
+		Type:       msgSummary,
+		Run:        run,
+		Frames:     frames,
+		RuntimeSec: res.Runtime.Seconds(),
+		Diverged:   res.Diverged,
+	}
+	var exact mathutils.Reference
+	summary.Reference, exact = sr.req.reference(p)
+	// Расчёт шёл во времени τ = αt, эталон задан для α = 1
+	if exact != nil {
+		summary.Norms = finalNorms(res, exact)
+	}
+	ss.send(summary)
+}
+
+// Запуск в фоне с дедлайном запроса; done закрывается по завершении
+func (ss *session) start(ctx context.Context, run int, req sessionRequest) (context.CancelFunc, chan struct{}) {
+	runCtx, cancel := context.WithTimeout(ctx, ss.s.timeout)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer cancel()
+		ss.run(runCtx, run, req)
+	}()
+	return cancel, done
+}
+
+// Цикл сессии: новые параметры сразу отменяют текущий запуск, следующий
+// стартует после паузы sessionDebounce и только когда предыдущий завершился
+func (s *server) serveSession(ws *websocket.Conn) {
+	ws.MaxPayloadBytes = sessionMaxMessage
+	ctx, cancel := context.WithCancel(s.sessionsCtx)
+	defer cancel()
+	// Закрытие соединения при остановке сервера прерывает чтение
+	defer context.AfterFunc(ctx, func() { ws.Close() })()
+
+	ss := &session{s: s, ws: ws}
+	updates := make(chan sessionRequest)
+	go ss.readLoop(ctx, updates)
+
+	slog.Info("Session opened", "remote", ws.Request().RemoteAddr)
+	defer slog.Info("Session closed", "remote", ws.Request().RemoteAddr)
+
+	var (
+		pending   sessionRequest
+		runs      int
+		runCancel context.CancelFunc = func() {}
+		runDone                      = make(chan struct{})
+	)
+	close(runDone)
+	debounce := time.NewTimer(time.Hour)
+	debounce.Stop()
+	defer func() {
+		runCancel()
+		<-runDone
+	}()
+
+	for {
+		select {
+		case req, ok := <-updates:
+			if !ok {
+				return
+			}
+			runCancel()
+			pending = req
+			debounce.Reset(sessionDebounce)
+		case <-debounce.C:
+			<-runDone
+			runs++
+			runCancel, runDone = ss.start(ctx, runs, pending)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Браузер присылает Origin: принимаются только запросы со своего хоста.
+// Клиенты без Origin (не браузеры) допускаются
+func sessionHandshake(cfg *websocket.Config, r *http.Request) error {
+	if r.Header.Get("Origin") == "" {
+		return nil
+	}
+	origin, err := websocket.Origin(cfg, r)
+	if err != nil {
+		return err
+	}
+	if origin.Host != r.Host {
+		return fmt.Errorf("cross-origin session from %s", origin.Host)
+	}
+	cfg.Origin = origin
+	return nil
+}
+
+// Middleware оборачивают ResponseWriter, а x/net/websocket требует
+// http.Hijacker напрямую — перехват идёт через ResponseController
+type hijackWriter struct {
+	http.ResponseWriter
+}
+
+func (h hijackWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return http.NewResponseController(h.ResponseWriter).Hijack()
+}
+
+func (s *server) handleSession(w http.ResponseWriter, r *http.Request) {
+	websocket.Server{Handler: s.serveSession, Handshake: sessionHandshake}.ServeHTTP(hijackWriter{w}, r)
+}
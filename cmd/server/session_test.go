@@ -0,0 +1,135 @@
+package main
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+// Сообщение сессии в виде для проверок
+type sessionMessage struct {
+	Type   string     `json:"type"`
+	Run    int        `json:"run"`
+	N      int        `json:"n"`
+	Frames int        `json:"frames"`
+	Alpha  float64    `json:"alpha"`
+	Error  *errorBody `json:"error"`
+}
+
+func dialSession(t *testing.T, ts *httptest.Server, origin string) *websocket.Conn {
+	t.Helper()
+	url := "ws" + strings.TrimPrefix(ts.URL, "http") + "/api/v1/session"
+	ws, err := websocket.Dial(url, "", origin)
+	if err != nil {
+		t.Fatalf("dial session: %v", err)
+	}
+	t.Cleanup(func() { ws.Close() })
+	ws.SetDeadline(time.Now().Add(30 * time.Second))
+	return ws
+}
+
+func receive(t *testing.T, ws *websocket.Conn) sessionMessage {
+	t.Helper()
+	var msg sessionMessage
+	if err := websocket.JSON.Receive(ws, &msg); err != nil {
+		t.Fatalf("receive: %v", err)
+	}
+	return msg
+}
+
+// Вторые параметры посреди долгого расчёта: кадры первого запуска
+// обрываются без итога, второй запуск доходит до summary
+func TestSessionUpdate(t *testing.T) {
+	_, ts := newTestServer(t, func(cfg *serverConfig) {
+		cfg.Limits.MaxCells = 0
+	})
+	ws := dialSession(t, ts, ts.URL)
+
+	slow := `{"type":"params","method":"CN","dx":0.001,"dt":0.00001,"tmax":1}`
+	if err := websocket.Message.Send(ws, slow); err != nil {
+		t.Fatal(err)
+	}
+	first := receive(t, ws)
+	if first.Type != msgParams || first.Run != 1 {
+		t.Fatalf("first message %+v, want params of run 1", first)
+	}
+	if msg := receive(t, ws); msg.Type != msgFrame || msg.Run != 1 {
+		t.Fatalf("message %+v, want a frame of run 1", msg)
+	}
+
+	updated := time.Now()
+	fast := `{"type":"params","method":"CN","dx":0.1,"dt":0.01,"tmax":0.1,"alpha":0.5,"stride":5}`
+	if err := websocket.Message.Send(ws, fast); err != nil {
+		t.Fatal(err)
+	}
+	frames1, frames2 := 1, 0
+	var second sessionMessage
+	for {
+		msg := receive(t, ws)
+		switch {
+		case msg.Type == msgError:
+			t.Fatalf("error %+v", msg.Error)
+		case msg.Run == 1 && msg.Type == msgFrame:
+			if second.Run != 0 {
+				t.Fatal("frame of run 1 after run 2 started")
+			}
+			frames1++
+		case msg.Run == 1:
+			t.Fatalf("run 1 sent %s after being replaced", msg.Type)
+		case msg.Type == msgParams:
+			second = msg
+			if time.Since(updated) > 2*time.Second {
+				t.Errorf("run 2 started %v after the update", time.Since(updated))
+			}
+		case msg.Type == msgFrame:
+			frames2++
+		case msg.Type == msgSummary:
+			if msg.Run != 2 || msg.Frames != second.Frames || frames2 != second.Frames || second.Alpha != 0.5 {
+				t.Errorf("summary %+v after %d frames, want run 2 with %d frames at alpha 0.5", msg, frames2, second.Frames)
+			}
+			if frames1 >= first.Frames {
+				t.Errorf("run 1 sent all %d frames, want it cut short", frames1)
+			}
+			return
+		}
+	}
+}
+
+func TestSessionErrors(t *testing.T) {
+	_, ts := newTestServer(t, func(cfg *serverConfig) {
+		cfg.Limits.MaxNx = 500
+	})
+	ws := dialSession(t, ts, ts.URL)
+	tests := []struct {
+		name, msg string
+		run       int
+		code      string
+	}{
+		{"not JSON", `{`, 0, codeInvalidParameter},
+		{"unknown type", `{"type":"hello"}`, 0, codeInvalidParameter},
+		{"invalid method", `{"type":"params","method":"NOPE"}`, 1, codeInvalidParameter},
+		{"limit", `{"type":"params","dx":0.001}`, 2, codeLimitExceeded},
+		{"bad alpha", `{"type":"params","alpha":-1}`, 3, codeInvalidParameter},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := websocket.Message.Send(ws, tc.msg); err != nil {
+				t.Fatal(err)
+			}
+			msg := receive(t, ws)
+			if msg.Type != msgError || msg.Run != tc.run || msg.Error == nil || msg.Error.Code != tc.code {
+				t.Errorf("message %+v (error %+v), want error %s for run %d", msg, msg.Error, tc.code, tc.run)
+			}
+		})
+	}
+
+	// Браузер с чужого сайта не может открыть сессию
+	url := "ws" + strings.TrimPrefix(ts.URL, "http") + "/api/v1/session"
+	if ws, err := websocket.Dial(url, "", "http://evil.example"); err == nil {
+		ws.Close()
+		t.Error("cross-origin session accepted")
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"heat-solver/web"
+)
+
+// Веб-интерфейс. По умолчанию файлы встроены в бинарник и не зависят от
+// рабочего каталога; dir подменяет их файлами с диска (разработка UI).
+// Ошибка — если dir задан, но не содержит index.html
+func webHandler(dir string) (http.Handler, error) {
+	if dir != "" {
+		if _, err := os.Stat(filepath.Join(dir, "index.html")); err != nil {
+			return nil, fmt.Errorf("web dir %s: %w", dir, err)
+		}
+		files := http.FileServer(http.Dir(dir))
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Правки на диске видны сразу
+			w.Header().Set("Cache-Control", "no-cache")
+			files.ServeHTTP(w, r)
+		}), nil
+	}
+	return embeddedWeb(web.FS)
+}
+
+// У встроенных файлов нет времени изменения, поэтому для повторной проверки
+// кэша используется ETag по содержимому. index.html проверяется при каждом
+// запросе, чтобы новая версия сервера сразу отдавала новый интерфейс;
+// остальные файлы кэшируются на час
+func embeddedWeb(fsys fs.FS) (http.Handler, error) {
+	etags := map[string]string{}
+	err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		b, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+		sum := sha256.Sum256(b)
+		etags["/"+name] = `"` + hex.EncodeToString(sum[:8]) + `"`
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	etags["/"] = etags["/index.html"]
+
+	files := http.FileServer(http.FS(fsys))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := path.Clean("/" + r.URL.Path)
+		if etag, ok := etags[name]; ok {
+			w.Header().Set("ETag", etag)
+			if name == "/" || strings.HasSuffix(name, ".html") {
+				w.Header().Set("Cache-Control", "no-cache")
+			} else {
+				w.Header().Set("Cache-Control", "public, max-age=3600")
+			}
+		}
+		files.ServeHTTP(w, r)
+	}), nil
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"heat-solver/web"
+)
+
+// Встроенный интерфейс не зависит от рабочего каталога
+func TestEmbeddedWeb(t *testing.T) {
+	t.Chdir(t.TempDir())
+	_, ts := newTestServer(t, nil)
+
+	tests := []struct {
+		path, file, contentType, cache string
+	}{
+		{"/", "index.html", "text/html", "no-cache"},
+		{"/script.js", "script.js", "text/javascript", "public, max-age=3600"},
+		{"/style.css", "style.css", "text/css", "public, max-age=3600"},
+		{"/background.png", "background.png", "image/png", "public, max-age=3600"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.path, func(t *testing.T) {
+			want, err := fs.ReadFile(web.FS, tc.file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, body := get(t, ts, tc.path, 200)
+			if string(body) != string(want) {
+				t.Errorf("body differs from the embedded %s", tc.file)
+			}
+			if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, tc.contentType) {
+				t.Errorf("Content-Type = %q, want %s", ct, tc.contentType)
+			}
+			if cc := resp.Header.Get("Cache-Control"); cc != tc.cache {
+				t.Errorf("Cache-Control = %q, want %q", cc, tc.cache)
+			}
+
+			etag := resp.Header.Get("ETag")
+			req, _ := http.NewRequest(http.MethodGet, ts.URL+tc.path, nil)
+			req.Header.Set("If-None-Match", etag)
+			send(t, ts, req, http.StatusNotModified)
+		})
+	}
+	get(t, ts, "/missing.js", 404)
+}
+
+func TestWebDir(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "index.html"), []byte("<p>dev build</p>"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	_, ts := newTestServer(t, func(cfg *serverConfig) { cfg.WebDir = dir })
+	resp, body := get(t, ts, "/", 200)
+	if string(body) != "<p>dev build</p>" || resp.Header.Get("Cache-Control") != "no-cache" {
+		t.Errorf("got %q with Cache-Control %q, want the file from --web-dir", body, resp.Header.Get("Cache-Control"))
+	}
+
+	// Каталог без index.html — ошибка при запуске, а не 404 в браузере
+	if _, err := webHandler(t.TempDir()); err == nil {
+		t.Error("web dir without index.html accepted")
+	}
+	if _, err := webHandler(filepath.Join(dir, "nope")); err == nil {
+		t.Error("missing web dir accepted")
+	}
+}
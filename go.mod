@@ -1,3 +1,13 @@
 module heat-solver
 
-go 1.24.6
+go 1.25.0
+
+require (
+	golang.org/x/net v0.57.0
+	golang.org/x/sys v0.47.0
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20260706201446-f0a921348800
+	google.golang.org/grpc v1.84.0
+	google.golang.org/protobuf v1.36.11
+)
+
+require golang.org/x/text v0.40.0 // indirect
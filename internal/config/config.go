@@ -1,9 +1,314 @@
 package config
 
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"heat-solver/internal/mathutils"
+)
+
 type Params struct {
-    Method  string
-    Dx      float64
-    Dt      float64
-    Tmax    float64
-    Outfile string
+	Method  string
+	Dx      float64
+	Dt      float64
+	Tmax    float64
+	Outfile string
+	// Предустановленное начальное условие: sine (sin(πx), по умолчанию)
+	// или tophat (ступенька); без выборки ICSamples
+	IC string
+	// Начальный профиль, заданный выборкой; пусто — предустановка IC
+	ICSamples []Sample
+	// Хранение слоёв: full (по умолчанию) или final-only
+	Storage string
+	// Точность хранимых слоёв: float64 (по умолчанию) или float32.
+	// Шаги по времени всегда считаются в float64
+	Precision string
+	// Шаг BTCS и CN по оценке ошибки удвоением шага с допуском Tol на
+	// шаг; Dt — тогда шаг выдачи слоёв и наибольший шаг
+	Adapt bool
+	Tol   float64
+	// Старт Раннахера у CN: первые два шага — четыре полушага BTCS,
+	// гасящие высокие моды разрывных начальных данных
+	Rannacher bool
+	// Порядок аппроксимации по x: 2 (трёхточечная разность, по умолчанию)
+	// или 4 (компактная схема у BTCS и CN)
+	SpatialOrder int
+	// Шаблон четвёртого порядка: compact (по умолчанию) или wide
+	// (пятиточечная разность у FTCS, BTCS и CN); только при SpatialOrder = 4
+	Stencil string
+	// Коэффициент линейной реакции λ ≥ 0 в u_t = u_xx − λu (FTCS, BTCS
+	// и CN); 0 — чистая теплопроводность
+	Lambda float64
+	// Объёмный источник f(x, t) в u_t = u_xx + f: предустановка (sine,
+	// gaussian, manufactured) или выражение от x и t (FTCS, BTCS и CN);
+	// пусто — источника нет
+	Source string
+	// Параметры пучка при Source = laser; при другом источнике — нулевые
+	Laser mathutils.Laser
+}
+
+// Допуск локальной ошибки шага при Adapt по умолчанию
+const DefaultTol = 1e-6
+
+// Режимы хранения решения. final-only держит в памяти два слоя и
+// возвращает только начальный и последний
+const (
+	StorageFull      = "full"
+	StorageFinalOnly = "final-only"
+)
+
+// Точность хранения решения
+const (
+	PrecisionFloat64 = "float64"
+	PrecisionFloat32 = "float32"
+)
+
+// Шаблоны четвёртого порядка по x. compact — (1 + δ²/12)⁻¹δ², система
+// трёхдиагональная; wide — (−1, 16, −30, 16, −1)/12, у неявных схем
+// система пятидиагональная
+const (
+	StencilCompact = "compact"
+	StencilWide    = "wide"
+)
+
+// Предустановленные начальные условия. tophat — 1 на (¼, ¾) и 0 вне,
+// ½ в узлах разрыва
+const (
+	ICSine   = "sine"
+	ICTopHat = "tophat"
+)
+
+// Начальное условие с разрывом: при нём у CN по умолчанию включён старт
+// Раннахера
+func DiscontinuousIC(ic string) bool {
+	return strings.EqualFold(ic, ICTopHat)
+}
+
+// Точка начального профиля
+type Sample struct {
+	X float64 `json:"x"`
+	U float64 `json:"u"`
+}
+
+// Наибольшее число точек начального профиля
+const MaxICSamples = 10000
+
+// Ошибка в конкретном параметре запуска
+type ValidationError struct {
+	Field   string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid %s: %s", e.Field, e.Message)
+}
+
+// Проверка шагов сетки и времени расчёта
+func (p Params) Validate() error {
+	if math.IsNaN(p.Dx) || p.Dx <= 0 || p.Dx > 0.5 {
+		return &ValidationError{Field: "dx", Message: "must be in (0, 0.5]"}
+	}
+	if math.IsNaN(p.Dt) || math.IsInf(p.Dt, 0) || p.Dt <= 0 {
+		return &ValidationError{Field: "dt", Message: "must be positive"}
+	}
+	if math.IsNaN(p.Tmax) || math.IsInf(p.Tmax, 0) || p.Tmax <= 0 {
+		return &ValidationError{Field: "tmax", Message: "must be positive"}
+	}
+	if p.Tmax < p.Dt {
+		return &ValidationError{Field: "tmax", Message: "must be at least one time step"}
+	}
+	switch strings.ToLower(p.Storage) {
+	case "", StorageFull, StorageFinalOnly:
+	default:
+		return &ValidationError{Field: "storage", Message: "must be " + StorageFull + " or " + StorageFinalOnly}
+	}
+	switch strings.ToLower(p.Precision) {
+	case "", PrecisionFloat64, PrecisionFloat32:
+	default:
+		return &ValidationError{Field: "precision", Message: "must be " + PrecisionFloat64 + " or " + PrecisionFloat32}
+	}
+	if p.Adapt && (math.IsNaN(p.Tol) || math.IsInf(p.Tol, 0) || p.Tol < 0) {
+		return &ValidationError{Field: "tol", Message: "must be positive"}
+	}
+	if p.Adapt && p.Rannacher {
+		return &ValidationError{Field: "rannacher", Message: "not available with adapt, whose step control already shrinks the first steps"}
+	}
+	switch p.SpatialOrder {
+	case 0, 2, 4:
+	default:
+		return &ValidationError{Field: "spatial_order", Message: "must be 2 or 4"}
+	}
+	switch strings.ToLower(p.Stencil) {
+	case "":
+	case StencilCompact, StencilWide:
+		if p.SpatialOrder != 4 {
+			return &ValidationError{Field: "stencil", Message: "only applies with spatial_order 4"}
+		}
+	default:
+		return &ValidationError{Field: "stencil", Message: "must be " + StencilCompact + " or " + StencilWide}
+	}
+	if math.IsNaN(p.Lambda) || math.IsInf(p.Lambda, 0) || p.Lambda < 0 {
+		return &ValidationError{Field: "lambda", Message: "must be finite and not negative"}
+	}
+	if err := p.validateLaser(); err != nil {
+		return err
+	}
+	if _, err := p.ParseSource(); err != nil {
+		return &ValidationError{Field: "source", Message: err.Error()}
+	}
+	switch strings.ToLower(p.IC) {
+	case "", ICSine, ICTopHat:
+	default:
+		return &ValidationError{Field: "ic", Message: "must be " + ICSine + " or " + ICTopHat}
+	}
+	if p.IC != "" && p.ICSamples != nil {
+		return &ValidationError{Field: "ic", Message: "not allowed together with ic_samples"}
+	}
+	if p.ICSamples != nil {
+		return validateSamples(p.ICSamples)
+	}
+	return nil
+}
+
+// Выборка должна покрывать [0, 1] строго возрастающими x и обращаться в ноль
+// на границах (условия Дирихле)
+func validateSamples(s []Sample) error {
+	field := "ic_samples"
+	if len(s) < 2 || len(s) > MaxICSamples {
+		return &ValidationError{Field: field, Message: fmt.Sprintf("need between 2 and %d points", MaxICSamples)}
+	}
+	for i, p := range s {
+		if math.IsNaN(p.X) || math.IsInf(p.X, 0) || math.IsNaN(p.U) || math.IsInf(p.U, 0) {
+			return &ValidationError{Field: field, Message: fmt.Sprintf("point %d is not finite", i)}
+		}
+		if i > 0 && p.X <= s[i-1].X {
+			return &ValidationError{Field: field, Message: fmt.Sprintf("x must be strictly increasing (point %d)", i)}
+		}
+	}
+	first, last := s[0], s[len(s)-1]
+	if first.X != 0 || last.X != 1 {
+		return &ValidationError{Field: field, Message: "x must start at 0 and end at 1"}
+	}
+	if first.U != 0 || last.U != 0 {
+		return &ValidationError{Field: field, Message: "u must be 0 at x = 0 and x = 1 (Dirichlet boundaries)"}
+	}
+	return nil
+}
+
+// Число интервалов по пространству и времени (с округлением)
+func (p Params) Grid() (nx, nt int) {
+	return int(math.Round(1.0 / p.Dx)), int(math.Round(p.Tmax / p.Dt))
+}
+
+// Канонический вид параметров: dx подгоняется под целое число интервалов
+func (p Params) Normalize() Params {
+	nx, _ := p.Grid()
+	p.Method = strings.ToUpper(strings.TrimSpace(p.Method))
+	p.Dx = 1.0 / float64(nx)
+	p.Storage = strings.ToLower(p.Storage)
+	if p.Storage == "" {
+		p.Storage = StorageFull
+	}
+	p.Precision = strings.ToLower(p.Precision)
+	if p.Precision == "" {
+		p.Precision = PrecisionFloat64
+	}
+	if p.SpatialOrder == 0 {
+		p.SpatialOrder = 2
+	}
+	p.Stencil = strings.ToLower(p.Stencil)
+	if p.SpatialOrder == 4 && p.Stencil == "" {
+		p.Stencil = StencilCompact
+	}
+	p.IC = strings.ToLower(p.IC)
+	if p.IC == "" && p.ICSamples == nil {
+		p.IC = ICSine
+	}
+	// Допуск без Adapt не влияет на расчёт; 0 — допуск по умолчанию
+	switch {
+	case !p.Adapt:
+		p.Tol = 0
+	case p.Tol == 0:
+		p.Tol = DefaultTol
+	}
+	return p
+}
+
+// Источник из Source; у laser параметры пучка берутся из Laser и
+// проверяются
+func (p Params) ParseSource() (mathutils.Source, error) {
+	if err := p.validateLaser(); err != nil {
+		return mathutils.Source{}, err
+	}
+	if p.LaserSource() {
+		return p.Laser.Source(), nil
+	}
+	return mathutils.ParseSource(p.Source)
+}
+
+// Источник — движущийся пучок (Source = laser)
+func (p Params) LaserSource() bool {
+	return strings.EqualFold(strings.TrimSpace(p.Source), mathutils.SourceLaser)
+}
+
+func (p Params) validateLaser() error {
+	if !p.LaserSource() {
+		if p.Laser != (mathutils.Laser{}) {
+			return &ValidationError{Field: "source", Message: "beam parameters need source " + mathutils.SourceLaser}
+		}
+		return nil
+	}
+	l := p.Laser
+	for _, f := range []struct {
+		name  string
+		value float64
+	}{{"laser_power", l.Power}, {"laser_speed", l.Speed}, {"laser_start", l.Start}} {
+		if math.IsNaN(f.value) || math.IsInf(f.value, 0) {
+			return &ValidationError{Field: f.name, Message: "must be finite"}
+		}
+	}
+	if math.IsNaN(l.Width) || math.IsInf(l.Width, 0) || l.Width <= 0 {
+		return &ValidationError{Field: "laser_width", Message: "must be positive and finite"}
+	}
+	return nil
+}
+
+// Ограничения размера сетки для одного запуска
+type Limits struct {
+	MaxNx    int   `json:"max_nx"`
+	MaxNt    int   `json:"max_nt"`
+	MaxCells int64 `json:"max_cells"`
+}
+
+// Превышение ограничения размера
+type LimitError struct {
+	Field string
+	Limit int64
+	Value int64
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("%s = %d exceeds limit %d", e.Field, e.Value, e.Limit)
+}
+
+// Проверка сетки параметров; нулевое ограничение не действует
+func (l Limits) Check(p Params) error {
+	nx, nt := p.Grid()
+	if l.MaxNx > 0 && nx > l.MaxNx {
+		return &LimitError{Field: "nx", Limit: int64(l.MaxNx), Value: int64(nx)}
+	}
+	if l.MaxNt > 0 && nt > l.MaxNt {
+		return &LimitError{Field: "nt", Limit: int64(l.MaxNt), Value: int64(nt)}
+	}
+	// Ограничение на хранимые ячейки: в final-only хранятся два слоя
+	rows := int64(nt + 1)
+	if strings.EqualFold(p.Storage, StorageFinalOnly) {
+		rows = 2
+	}
+	cells := int64(nx+1) * rows
+	if l.MaxCells > 0 && cells > l.MaxCells {
+		return &LimitError{Field: "cells", Limit: l.MaxCells, Value: cells}
+	}
+	return nil
 }
@@ -0,0 +1,4 @@
+// Package heatpb — сообщения и сервис gRPC API, сгенерированные из heat.proto
+package heatpb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative heat.proto
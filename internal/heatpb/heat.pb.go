@@ -0,0 +1,624 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        v5.28.3
+// source: heat.proto
+
+package heatpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Те же поля, что у config.Params; нули означают значения по умолчанию
+type SimulateRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Method        string                 `protobuf:"bytes,1,opt,name=method,proto3" json:"method,omitempty"`
+	Dx            float64                `protobuf:"fixed64,2,opt,name=dx,proto3" json:"dx,omitempty"`
+	Dt            float64                `protobuf:"fixed64,3,opt,name=dt,proto3" json:"dt,omitempty"`
+	Tmax          float64                `protobuf:"fixed64,4,opt,name=tmax,proto3" json:"tmax,omitempty"`
+	Stride        uint32                 `protobuf:"varint,5,opt,name=stride,proto3" json:"stride,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SimulateRequest) Reset() {
+	*x = SimulateRequest{}
+	mi := &file_heat_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SimulateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SimulateRequest) ProtoMessage() {}
+
+func (x *SimulateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_heat_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SimulateRequest.ProtoReflect.Descriptor instead.
+func (*SimulateRequest) Descriptor() ([]byte, []int) {
+	return file_heat_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *SimulateRequest) GetMethod() string {
+	if x != nil {
+		return x.Method
+	}
+	return ""
+}
+
+func (x *SimulateRequest) GetDx() float64 {
+	if x != nil {
+		return x.Dx
+	}
+	return 0
+}
+
+func (x *SimulateRequest) GetDt() float64 {
+	if x != nil {
+		return x.Dt
+	}
+	return 0
+}
+
+func (x *SimulateRequest) GetTmax() float64 {
+	if x != nil {
+		return x.Tmax
+	}
+	return 0
+}
+
+func (x *SimulateRequest) GetStride() uint32 {
+	if x != nil {
+		return x.Stride
+	}
+	return 0
+}
+
+type TimeLevel struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Index         uint32                 `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	T             float64                `protobuf:"fixed64,2,opt,name=t,proto3" json:"t,omitempty"`
+	U             []float64              `protobuf:"fixed64,3,rep,packed,name=u,proto3" json:"u,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TimeLevel) Reset() {
+	*x = TimeLevel{}
+	mi := &file_heat_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TimeLevel) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TimeLevel) ProtoMessage() {}
+
+func (x *TimeLevel) ProtoReflect() protoreflect.Message {
+	mi := &file_heat_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TimeLevel.ProtoReflect.Descriptor instead.
+func (*TimeLevel) Descriptor() ([]byte, []int) {
+	return file_heat_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *TimeLevel) GetIndex() uint32 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *TimeLevel) GetT() float64 {
+	if x != nil {
+		return x.T
+	}
+	return 0
+}
+
+func (x *TimeLevel) GetU() []float64 {
+	if x != nil {
+		return x.U
+	}
+	return nil
+}
+
+type Norms struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	L2            float64                `protobuf:"fixed64,1,opt,name=l2,proto3" json:"l2,omitempty"`
+	Linf          float64                `protobuf:"fixed64,2,opt,name=linf,proto3" json:"linf,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Norms) Reset() {
+	*x = Norms{}
+	mi := &file_heat_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Norms) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Norms) ProtoMessage() {}
+
+func (x *Norms) ProtoReflect() protoreflect.Message {
+	mi := &file_heat_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Norms.ProtoReflect.Descriptor instead.
+func (*Norms) Descriptor() ([]byte, []int) {
+	return file_heat_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Norms) GetL2() float64 {
+	if x != nil {
+		return x.L2
+	}
+	return 0
+}
+
+func (x *Norms) GetLinf() float64 {
+	if x != nil {
+		return x.Linf
+	}
+	return 0
+}
+
+type SimulateResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Method        string                 `protobuf:"bytes,1,opt,name=method,proto3" json:"method,omitempty"`
+	Nx            uint32                 `protobuf:"varint,2,opt,name=nx,proto3" json:"nx,omitempty"`
+	Nt            uint32                 `protobuf:"varint,3,opt,name=nt,proto3" json:"nt,omitempty"`
+	Dx            float64                `protobuf:"fixed64,4,opt,name=dx,proto3" json:"dx,omitempty"`
+	Dt            float64                `protobuf:"fixed64,5,opt,name=dt,proto3" json:"dt,omitempty"`
+	R             float64                `protobuf:"fixed64,6,opt,name=r,proto3" json:"r,omitempty"`
+	X             []float64              `protobuf:"fixed64,7,rep,packed,name=x,proto3" json:"x,omitempty"`
+	Levels        []*TimeLevel           `protobuf:"bytes,8,rep,name=levels,proto3" json:"levels,omitempty"`
+	Norms         *Norms                 `protobuf:"bytes,9,opt,name=norms,proto3" json:"norms,omitempty"`
+	RuntimeSec    float64                `protobuf:"fixed64,10,opt,name=runtime_sec,json=runtimeSec,proto3" json:"runtime_sec,omitempty"`
+	Diverged      bool                   `protobuf:"varint,11,opt,name=diverged,proto3" json:"diverged,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SimulateResponse) Reset() {
+	*x = SimulateResponse{}
+	mi := &file_heat_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SimulateResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SimulateResponse) ProtoMessage() {}
+
+func (x *SimulateResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_heat_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SimulateResponse.ProtoReflect.Descriptor instead.
+func (*SimulateResponse) Descriptor() ([]byte, []int) {
+	return file_heat_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *SimulateResponse) GetMethod() string {
+	if x != nil {
+		return x.Method
+	}
+	return ""
+}
+
+func (x *SimulateResponse) GetNx() uint32 {
+	if x != nil {
+		return x.Nx
+	}
+	return 0
+}
+
+func (x *SimulateResponse) GetNt() uint32 {
+	if x != nil {
+		return x.Nt
+	}
+	return 0
+}
+
+func (x *SimulateResponse) GetDx() float64 {
+	if x != nil {
+		return x.Dx
+	}
+	return 0
+}
+
+func (x *SimulateResponse) GetDt() float64 {
+	if x != nil {
+		return x.Dt
+	}
+	return 0
+}
+
+func (x *SimulateResponse) GetR() float64 {
+	if x != nil {
+		return x.R
+	}
+	return 0
+}
+
+func (x *SimulateResponse) GetX() []float64 {
+	if x != nil {
+		return x.X
+	}
+	return nil
+}
+
+func (x *SimulateResponse) GetLevels() []*TimeLevel {
+	if x != nil {
+		return x.Levels
+	}
+	return nil
+}
+
+func (x *SimulateResponse) GetNorms() *Norms {
+	if x != nil {
+		return x.Norms
+	}
+	return nil
+}
+
+func (x *SimulateResponse) GetRuntimeSec() float64 {
+	if x != nil {
+		return x.RuntimeSec
+	}
+	return 0
+}
+
+func (x *SimulateResponse) GetDiverged() bool {
+	if x != nil {
+		return x.Diverged
+	}
+	return false
+}
+
+type ListMethodsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListMethodsRequest) Reset() {
+	*x = ListMethodsRequest{}
+	mi := &file_heat_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListMethodsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListMethodsRequest) ProtoMessage() {}
+
+func (x *ListMethodsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_heat_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListMethodsRequest.ProtoReflect.Descriptor instead.
+func (*ListMethodsRequest) Descriptor() ([]byte, []int) {
+	return file_heat_proto_rawDescGZIP(), []int{4}
+}
+
+type MethodInfo struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Name        string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Aliases     []string               `protobuf:"bytes,2,rep,name=aliases,proto3" json:"aliases,omitempty"`
+	Description string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	Explicit    bool                   `protobuf:"varint,4,opt,name=explicit,proto3" json:"explicit,omitempty"`
+	// Пусто для безусловно устойчивых схем
+	StabilityBound string `protobuf:"bytes,5,opt,name=stability_bound,json=stabilityBound,proto3" json:"stability_bound,omitempty"`
+	OrderTime      uint32 `protobuf:"varint,6,opt,name=order_time,json=orderTime,proto3" json:"order_time,omitempty"`
+	OrderSpace     uint32 `protobuf:"varint,7,opt,name=order_space,json=orderSpace,proto3" json:"order_space,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *MethodInfo) Reset() {
+	*x = MethodInfo{}
+	mi := &file_heat_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MethodInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MethodInfo) ProtoMessage() {}
+
+func (x *MethodInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_heat_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MethodInfo.ProtoReflect.Descriptor instead.
+func (*MethodInfo) Descriptor() ([]byte, []int) {
+	return file_heat_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *MethodInfo) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *MethodInfo) GetAliases() []string {
+	if x != nil {
+		return x.Aliases
+	}
+	return nil
+}
+
+func (x *MethodInfo) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *MethodInfo) GetExplicit() bool {
+	if x != nil {
+		return x.Explicit
+	}
+	return false
+}
+
+func (x *MethodInfo) GetStabilityBound() string {
+	if x != nil {
+		return x.StabilityBound
+	}
+	return ""
+}
+
+func (x *MethodInfo) GetOrderTime() uint32 {
+	if x != nil {
+		return x.OrderTime
+	}
+	return 0
+}
+
+func (x *MethodInfo) GetOrderSpace() uint32 {
+	if x != nil {
+		return x.OrderSpace
+	}
+	return 0
+}
+
+type ListMethodsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Methods       []*MethodInfo          `protobuf:"bytes,1,rep,name=methods,proto3" json:"methods,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListMethodsResponse) Reset() {
+	*x = ListMethodsResponse{}
+	mi := &file_heat_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListMethodsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListMethodsResponse) ProtoMessage() {}
+
+func (x *ListMethodsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_heat_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListMethodsResponse.ProtoReflect.Descriptor instead.
+func (*ListMethodsResponse) Descriptor() ([]byte, []int) {
+	return file_heat_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ListMethodsResponse) GetMethods() []*MethodInfo {
+	if x != nil {
+		return x.Methods
+	}
+	return nil
+}
+
+var File_heat_proto protoreflect.FileDescriptor
+
+const file_heat_proto_rawDesc = "" +
+	"\n" +
+	"\n" +
+	"heat.proto\x12\aheat.v1\"u\n" +
+	"\x0fSimulateRequest\x12\x16\n" +
+	"\x06method\x18\x01 \x01(\tR\x06method\x12\x0e\n" +
+	"\x02dx\x18\x02 \x01(\x01R\x02dx\x12\x0e\n" +
+	"\x02dt\x18\x03 \x01(\x01R\x02dt\x12\x12\n" +
+	"\x04tmax\x18\x04 \x01(\x01R\x04tmax\x12\x16\n" +
+	"\x06stride\x18\x05 \x01(\rR\x06stride\"=\n" +
+	"\tTimeLevel\x12\x14\n" +
+	"\x05index\x18\x01 \x01(\rR\x05index\x12\f\n" +
+	"\x01t\x18\x02 \x01(\x01R\x01t\x12\f\n" +
+	"\x01u\x18\x03 \x03(\x01R\x01u\"+\n" +
+	"\x05Norms\x12\x0e\n" +
+	"\x02l2\x18\x01 \x01(\x01R\x02l2\x12\x12\n" +
+	"\x04linf\x18\x02 \x01(\x01R\x04linf\"\x95\x02\n" +
+	"\x10SimulateResponse\x12\x16\n" +
+	"\x06method\x18\x01 \x01(\tR\x06method\x12\x0e\n" +
+	"\x02nx\x18\x02 \x01(\rR\x02nx\x12\x0e\n" +
+	"\x02nt\x18\x03 \x01(\rR\x02nt\x12\x0e\n" +
+	"\x02dx\x18\x04 \x01(\x01R\x02dx\x12\x0e\n" +
+	"\x02dt\x18\x05 \x01(\x01R\x02dt\x12\f\n" +
+	"\x01r\x18\x06 \x01(\x01R\x01r\x12\f\n" +
+	"\x01x\x18\a \x03(\x01R\x01x\x12*\n" +
+	"\x06levels\x18\b \x03(\v2\x12.heat.v1.TimeLevelR\x06levels\x12$\n" +
+	"\x05norms\x18\t \x01(\v2\x0e.heat.v1.NormsR\x05norms\x12\x1f\n" +
+	"\vruntime_sec\x18\n" +
+	" \x01(\x01R\n" +
+	"runtimeSec\x12\x1a\n" +
+	"\bdiverged\x18\v \x01(\bR\bdiverged\"\x14\n" +
+	"\x12ListMethodsRequest\"\xe1\x01\n" +
+	"\n" +
+	"MethodInfo\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x18\n" +
+	"\aaliases\x18\x02 \x03(\tR\aaliases\x12 \n" +
+	"\vdescription\x18\x03 \x01(\tR\vdescription\x12\x1a\n" +
+	"\bexplicit\x18\x04 \x01(\bR\bexplicit\x12'\n" +
+	"\x0fstability_bound\x18\x05 \x01(\tR\x0estabilityBound\x12\x1d\n" +
+	"\n" +
+	"order_time\x18\x06 \x01(\rR\torderTime\x12\x1f\n" +
+	"\vorder_space\x18\a \x01(\rR\n" +
+	"orderSpace\"D\n" +
+	"\x13ListMethodsResponse\x12-\n" +
+	"\amethods\x18\x01 \x03(\v2\x13.heat.v1.MethodInfoR\amethods2\xd9\x01\n" +
+	"\n" +
+	"HeatSolver\x12?\n" +
+	"\bSimulate\x12\x18.heat.v1.SimulateRequest\x1a\x19.heat.v1.SimulateResponse\x12@\n" +
+	"\x0eSimulateStream\x12\x18.heat.v1.SimulateRequest\x1a\x12.heat.v1.TimeLevel0\x01\x12H\n" +
+	"\vListMethods\x12\x1b.heat.v1.ListMethodsRequest\x1a\x1c.heat.v1.ListMethodsResponseB\x1dZ\x1bheat-solver/internal/heatpbb\x06proto3"
+
+var (
+	file_heat_proto_rawDescOnce sync.Once
+	file_heat_proto_rawDescData []byte
+)
+
+func file_heat_proto_rawDescGZIP() []byte {
+	file_heat_proto_rawDescOnce.Do(func() {
+		file_heat_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_heat_proto_rawDesc), len(file_heat_proto_rawDesc)))
+	})
+	return file_heat_proto_rawDescData
+}
+
+var file_heat_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_heat_proto_goTypes = []any{
+	(*SimulateRequest)(nil),     // 0: heat.v1.SimulateRequest
+	(*TimeLevel)(nil),           // 1: heat.v1.TimeLevel
+	(*Norms)(nil),               // 2: heat.v1.Norms
+	(*SimulateResponse)(nil),    // 3: heat.v1.SimulateResponse
+	(*ListMethodsRequest)(nil),  // 4: heat.v1.ListMethodsRequest
+	(*MethodInfo)(nil),          // 5: heat.v1.MethodInfo
+	(*ListMethodsResponse)(nil), // 6: heat.v1.ListMethodsResponse
+}
+var file_heat_proto_depIdxs = []int32{
+	1, // 0: heat.v1.SimulateResponse.levels:type_name -> heat.v1.TimeLevel
+	2, // 1: heat.v1.SimulateResponse.norms:type_name -> heat.v1.Norms
+	5, // 2: heat.v1.ListMethodsResponse.methods:type_name -> heat.v1.MethodInfo
+	0, // 3: heat.v1.HeatSolver.Simulate:input_type -> heat.v1.SimulateRequest
+	0, // 4: heat.v1.HeatSolver.SimulateStream:input_type -> heat.v1.SimulateRequest
+	4, // 5: heat.v1.HeatSolver.ListMethods:input_type -> heat.v1.ListMethodsRequest
+	3, // 6: heat.v1.HeatSolver.Simulate:output_type -> heat.v1.SimulateResponse
+	1, // 7: heat.v1.HeatSolver.SimulateStream:output_type -> heat.v1.TimeLevel
+	6, // 8: heat.v1.HeatSolver.ListMethods:output_type -> heat.v1.ListMethodsResponse
+	6, // [6:9] is the sub-list for method output_type
+	3, // [3:6] is the sub-list for method input_type
+	3, // [3:3] is the sub-list for extension type_name
+	3, // [3:3] is the sub-list for extension extendee
+	0, // [0:3] is the sub-list for field type_name
+}
+
+func init() { file_heat_proto_init() }
+func file_heat_proto_init() {
+	if File_heat_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_heat_proto_rawDesc), len(file_heat_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   7,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_heat_proto_goTypes,
+		DependencyIndexes: file_heat_proto_depIdxs,
+		MessageInfos:      file_heat_proto_msgTypes,
+	}.Build()
+	File_heat_proto = out.File
+	file_heat_proto_goTypes = nil
+	file_heat_proto_depIdxs = nil
+}
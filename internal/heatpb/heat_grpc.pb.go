@@ -0,0 +1,211 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.2
+// - protoc             v5.28.3
+// source: heat.proto
+
+package heatpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	HeatSolver_Simulate_FullMethodName       = "/heat.v1.HeatSolver/Simulate"
+	HeatSolver_SimulateStream_FullMethodName = "/heat.v1.HeatSolver/SimulateStream"
+	HeatSolver_ListMethods_FullMethodName    = "/heat.v1.HeatSolver/ListMethods"
+)
+
+// HeatSolverClient is the client API for HeatSolver service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Решение одномерного уравнения теплопроводности теми же солверами, что и HTTP API
+type HeatSolverClient interface {
+	// Полная сетка и нормы ошибки одним ответом
+	Simulate(ctx context.Context, in *SimulateRequest, opts ...grpc.CallOption) (*SimulateResponse, error)
+	// Одно сообщение на каждый stride-й временной слой (плюс последний)
+	SimulateStream(ctx context.Context, in *SimulateRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[TimeLevel], error)
+	// Методы из реестра солвера
+	ListMethods(ctx context.Context, in *ListMethodsRequest, opts ...grpc.CallOption) (*ListMethodsResponse, error)
+}
+
+type heatSolverClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewHeatSolverClient(cc grpc.ClientConnInterface) HeatSolverClient {
+	return &heatSolverClient{cc}
+}
+
+func (c *heatSolverClient) Simulate(ctx context.Context, in *SimulateRequest, opts ...grpc.CallOption) (*SimulateResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SimulateResponse)
+	err := c.cc.Invoke(ctx, HeatSolver_Simulate_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *heatSolverClient) SimulateStream(ctx context.Context, in *SimulateRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[TimeLevel], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &HeatSolver_ServiceDesc.Streams[0], HeatSolver_SimulateStream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[SimulateRequest, TimeLevel]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type HeatSolver_SimulateStreamClient = grpc.ServerStreamingClient[TimeLevel]
+
+func (c *heatSolverClient) ListMethods(ctx context.Context, in *ListMethodsRequest, opts ...grpc.CallOption) (*ListMethodsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListMethodsResponse)
+	err := c.cc.Invoke(ctx, HeatSolver_ListMethods_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// HeatSolverServer is the server API for HeatSolver service.
+// All implementations must embed UnimplementedHeatSolverServer
+// for forward compatibility.
+//
+// Решение одномерного уравнения теплопроводности теми же солверами, что и HTTP API
+type HeatSolverServer interface {
+	// Полная сетка и нормы ошибки одним ответом
+	Simulate(context.Context, *SimulateRequest) (*SimulateResponse, error)
+	// Одно сообщение на каждый stride-й временной слой (плюс последний)
+	SimulateStream(*SimulateRequest, grpc.ServerStreamingServer[TimeLevel]) error
+	// Методы из реестра солвера
+	ListMethods(context.Context, *ListMethodsRequest) (*ListMethodsResponse, error)
+	mustEmbedUnimplementedHeatSolverServer()
+}
+
+// UnimplementedHeatSolverServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedHeatSolverServer struct{}
+
+func (UnimplementedHeatSolverServer) Simulate(context.Context, *SimulateRequest) (*SimulateResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Simulate not implemented")
+}
+func (UnimplementedHeatSolverServer) SimulateStream(*SimulateRequest, grpc.ServerStreamingServer[TimeLevel]) error {
+	return status.Error(codes.Unimplemented, "method SimulateStream not implemented")
+}
+func (UnimplementedHeatSolverServer) ListMethods(context.Context, *ListMethodsRequest) (*ListMethodsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListMethods not implemented")
+}
+func (UnimplementedHeatSolverServer) mustEmbedUnimplementedHeatSolverServer() {}
+func (UnimplementedHeatSolverServer) testEmbeddedByValue()                    {}
+
+// UnsafeHeatSolverServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to HeatSolverServer will
+// result in compilation errors.
+type UnsafeHeatSolverServer interface {
+	mustEmbedUnimplementedHeatSolverServer()
+}
+
+func RegisterHeatSolverServer(s grpc.ServiceRegistrar, srv HeatSolverServer) {
+	// If the following call panics, it indicates UnimplementedHeatSolverServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&HeatSolver_ServiceDesc, srv)
+}
+
+func _HeatSolver_Simulate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SimulateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HeatSolverServer).Simulate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HeatSolver_Simulate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HeatSolverServer).Simulate(ctx, req.(*SimulateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _HeatSolver_SimulateStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SimulateRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(HeatSolverServer).SimulateStream(m, &grpc.GenericServerStream[SimulateRequest, TimeLevel]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type HeatSolver_SimulateStreamServer = grpc.ServerStreamingServer[TimeLevel]
+
+func _HeatSolver_ListMethods_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListMethodsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HeatSolverServer).ListMethods(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HeatSolver_ListMethods_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HeatSolverServer).ListMethods(ctx, req.(*ListMethodsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// HeatSolver_ServiceDesc is the grpc.ServiceDesc for HeatSolver service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var HeatSolver_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "heat.v1.HeatSolver",
+	HandlerType: (*HeatSolverServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Simulate",
+			Handler:    _HeatSolver_Simulate_Handler,
+		},
+		{
+			MethodName: "ListMethods",
+			Handler:    _HeatSolver_ListMethods_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "SimulateStream",
+			Handler:       _HeatSolver_SimulateStream_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "heat.proto",
+}
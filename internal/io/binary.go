@@ -0,0 +1,115 @@
+package io
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// Компактный двоичный формат решения: 32-байтовый заголовок
+// (магия "HEATBIN1", nx и nt — uint32, dx и dt — float64), затем nt+1 строк
+// по nx+1 значений float64. Все числа little-endian.
+var binaryMagic = [8]byte{'H', 'E', 'A', 'T', 'B', 'I', 'N', '1'}
+
+const BinaryHeaderSize = 32
+
+var ErrBadBinary = errors.New("not a heat-solver binary solution")
+
+type BinaryHeader struct {
+	Nx, Nt int
+	Dx, Dt float64
+}
+
+// Размер файла с решением данного размера
+func (h BinaryHeader) Size() int64 {
+	return BinaryHeaderSize + int64(h.Nt+1)*int64(h.Nx+1)*8
+}
+
+func WriteBinary(w io.Writer, u [][]float64, dx, dt float64) error {
+	bw := bufio.NewWriter(w)
+
+	var hdr [BinaryHeaderSize]byte
+	copy(hdr[:8], binaryMagic[:])
+	binary.LittleEndian.PutUint32(hdr[8:], uint32(len(u[0])-1))
+	binary.LittleEndian.PutUint32(hdr[12:], uint32(len(u)-1))
+	binary.LittleEndian.PutUint64(hdr[16:], math.Float64bits(dx))
+	binary.LittleEndian.PutUint64(hdr[24:], math.Float64bits(dt))
+	if _, err := bw.Write(hdr[:]); err != nil {
+		return err
+	}
+
+	var b [8]byte
+	for _, row := range u {
+		for _, v := range row {
+			binary.LittleEndian.PutUint64(b[:], math.Float64bits(v))
+			if _, err := bw.Write(b[:]); err != nil {
+				return err
+			}
+		}
+	}
+	return bw.Flush()
+}
+
+// Построчное чтение, без загрузки всего решения в память
+type BinaryReader struct {
+	Header BinaryHeader
+	r      *bufio.Reader
+	buf    []byte
+	row    int
+}
+
+func NewBinaryReader(r io.Reader) (*BinaryReader, error) {
+	br := &BinaryReader{r: bufio.NewReader(r)}
+
+	var hdr [BinaryHeaderSize]byte
+	if _, err := io.ReadFull(br.r, hdr[:]); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrBadBinary, err)
+	}
+	if [8]byte(hdr[:8]) != binaryMagic {
+		return nil, ErrBadBinary
+	}
+	br.Header = BinaryHeader{
+		Nx: int(binary.LittleEndian.Uint32(hdr[8:])),
+		Nt: int(binary.LittleEndian.Uint32(hdr[12:])),
+		Dx: math.Float64frombits(binary.LittleEndian.Uint64(hdr[16:])),
+		Dt: math.Float64frombits(binary.LittleEndian.Uint64(hdr[24:])),
+	}
+	br.buf = make([]byte, (br.Header.Nx+1)*8)
+	return br, nil
+}
+
+// Следующий временной слой в dst (длина nx+1); после последнего — io.EOF
+func (br *BinaryReader) ReadRow(dst []float64) error {
+	if br.row > br.Header.Nt {
+		return io.EOF
+	}
+	if _, err := io.ReadFull(br.r, br.buf); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return err
+	}
+	for i := range dst {
+		dst[i] = math.Float64frombits(binary.LittleEndian.Uint64(br.buf[i*8:]))
+	}
+	br.row++
+	return nil
+}
+
+// Пропуск слоя без декодирования
+func (br *BinaryReader) SkipRow() error {
+	if br.row > br.Header.Nt {
+		return io.EOF
+	}
+	if _, err := br.r.Discard(len(br.buf)); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return err
+	}
+	br.row++
+	return nil
+}
@@ -0,0 +1,93 @@
+package io
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+
+	"heat-solver/internal/mathutils"
+)
+
+// Послойная запись двумерного решения в CSV: строка на узел,
+// x,y,t,u_numeric и, если exact задан, u_exact и error. Слой — строка из
+// (nx+1)·(ny+1) значений, x меняется быстрее (solver.Solve2DFTCS)
+type CSV2DLevelWriter struct {
+	nodeLevelWriter
+}
+
+func NewCSV2DLevelWriter(w io.Writer, nx, ny int, dx, dy, dt float64, exact mathutils.Reference2D) *CSV2DLevelWriter {
+	c := &CSV2DLevelWriter{newNodeLevelWriter(w, "x,y", dt)}
+	c.coords = make([][]byte, 0, (nx+1)*(ny+1))
+	for j := 0; j <= ny; j++ {
+		for i := 0; i <= nx; i++ {
+			c.coords = append(c.coords, appendCoords(nil, float64(i)*dx, float64(j)*dy))
+		}
+	}
+	if exact != nil {
+		c.setExact(exact.Grid(nx, ny, dx, dy))
+	}
+	return c
+}
+
+// Общая часть многомерных писателей: координаты узлов ("x,y," или
+// "x,y,z,") готовятся один раз, на слое форматируются только t и значения
+type nodeLevelWriter struct {
+	w      *bufio.Writer
+	names  string
+	dt     float64
+	exact  mathutils.GridFunc
+	ue     []float64
+	coords [][]byte
+	ts     []byte
+	buf    []byte
+	header bool
+}
+
+func newNodeLevelWriter(w io.Writer, names string, dt float64) nodeLevelWriter {
+	return nodeLevelWriter{w: bufio.NewWriterSize(w, 1<<20), names: names, dt: dt}
+}
+
+func (c *nodeLevelWriter) setExact(exact mathutils.GridFunc) {
+	c.exact = exact
+	c.ue = make([]float64, len(c.coords))
+}
+
+// Координаты через запятую и завершающая запятая
+func appendCoords(dst []byte, xs ...float64) []byte {
+	for _, x := range xs {
+		dst = strconv.AppendFloat(dst, x, 'f', 6, 64)
+		dst = append(dst, ',')
+	}
+	return dst
+}
+
+// Строки слоя n; слои пишутся по порядку, номера могут идти с пропусками
+func (c *nodeLevelWriter) WriteLevel(n int, row []float64) error {
+	if !c.header {
+		c.header = true
+		header := c.names + ",t,u_numeric\n"
+		if c.exact != nil {
+			header = c.names + ",t,u_numeric,u_exact,error\n"
+		}
+		if _, err := c.w.WriteString(header); err != nil {
+			return err
+		}
+	}
+
+	t := float64(n) * c.dt
+	c.ts = strconv.AppendFloat(c.ts[:0], t, 'f', 6, 64)
+	c.ts = append(c.ts, ',')
+	var err error
+	if c.exact == nil {
+		c.buf, err = writeNumericLevel(c.w, c.buf, c.coords, c.ts, row)
+	} else {
+		c.exact(c.ue, t)
+		c.buf, err = writeExactLevel(c.w, c.buf, c.coords, c.ts, row, c.ue)
+	}
+	return err
+}
+
+// Дозапись буфера; w не закрывается
+func (c *nodeLevelWriter) Flush() error {
+	return c.w.Flush()
+}
@@ -0,0 +1,31 @@
+package io
+
+import (
+	"io"
+
+	"heat-solver/internal/mathutils"
+)
+
+// Послойная запись трёхмерного решения в CSV: x,y,z,t,u_numeric и, если
+// exact задан, u_exact и error. Слой — строка из (nx+1)·(ny+1)·(nz+1)
+// значений, x меняется быстрее всего (solver.Stream3DDouglasGunnFrom).
+// Слой 64³ — около 275 тысяч строк, поэтому обычно пишется не каждый слой
+type CSV3DLevelWriter struct {
+	nodeLevelWriter
+}
+
+func NewCSV3DLevelWriter(w io.Writer, nx, ny, nz int, dx, dy, dz, dt float64, exact mathutils.Reference3D) *CSV3DLevelWriter {
+	c := &CSV3DLevelWriter{newNodeLevelWriter(w, "x,y,z", dt)}
+	c.coords = make([][]byte, 0, (nx+1)*(ny+1)*(nz+1))
+	for k := 0; k <= nz; k++ {
+		for j := 0; j <= ny; j++ {
+			for i := 0; i <= nx; i++ {
+				c.coords = append(c.coords, appendCoords(nil, float64(i)*dx, float64(j)*dy, float64(k)*dz))
+			}
+		}
+	}
+	if exact != nil {
+		c.setExact(exact.Grid(nx, ny, nz, dx, dy, dz))
+	}
+	return c
+}
@@ -0,0 +1,110 @@
+package io
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+)
+
+// Кадры решения для браузера: 64-байтовый заголовок, затем frames кадров
+// по nx+1 значений float32 или float64, little-endian. Размер заголовка
+// кратен 8, поэтому данные можно читать прямо в Float32Array/Float64Array.
+//
+//	 0  магия "HEATFRM1"
+//	 8  uint32 nx
+//	12  uint32 nt
+//	16  uint32 stride
+//	20  uint32 frames
+//	24  uint32 размер значения в байтах: 4 или 8
+//	28  uint32 флаги: бит 0 — решение разошлось
+//	32  float64 dx
+//	40  float64 dt
+//	48  float64 offset
+//	56  float64 scale
+//
+// Кадр k — слой min(k·stride, nt). Значение восстанавливается как
+// u = offset + scale·v. Для float32 offset и scale переводят конечные
+// значения в [-1, 1], так что ошибка округления не больше
+// scale·2⁻²⁴ = (max−min)·2⁻²⁵ независимо от уровня решения;
+// NaN и ±Inf передаются как есть. Для float64 offset = 0, scale = 1
+// и значения точные.
+var framesMagic = [8]byte{'H', 'E', 'A', 'T', 'F', 'R', 'M', '1'}
+
+const FramesHeaderSize = 64
+
+type FramesHeader struct {
+	Nx, Nt, Stride, Frames int
+	// 4 (float32) или 8 (float64)
+	ValueSize     int
+	Diverged      bool
+	Dx, Dt        float64
+	Offset, Scale float64
+}
+
+func (h FramesHeader) Size() int64 {
+	return FramesHeaderSize + int64(h.Frames)*int64(h.Nx+1)*int64(h.ValueSize)
+}
+
+// Offset и scale для float32 по конечным значениям кадров
+func FramesScale(frames [][]float64) (offset, scale float64) {
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, row := range frames {
+		for _, v := range row {
+			if !math.IsInf(v, 0) && !math.IsNaN(v) {
+				lo, hi = min(lo, v), max(hi, v)
+			}
+		}
+	}
+	if lo > hi {
+		return 0, 1
+	}
+	offset, scale = lo/2+hi/2, hi/2-lo/2
+	if scale == 0 || math.IsInf(scale, 0) {
+		scale = 1
+	}
+	return offset, scale
+}
+
+// Запись заголовка и кадров. Nx, Frames, ValueSize и масштаб в h должны
+// соответствовать frames
+func WriteFrames(w io.Writer, h FramesHeader, frames [][]float64) error {
+	if h.ValueSize != 4 && h.ValueSize != 8 {
+		return fmt.Errorf("frames: value size %d, want 4 or 8", h.ValueSize)
+	}
+	bw := bufio.NewWriter(w)
+
+	var hdr [FramesHeaderSize]byte
+	copy(hdr[:8], framesMagic[:])
+	binary.LittleEndian.PutUint32(hdr[8:], uint32(h.Nx))
+	binary.LittleEndian.PutUint32(hdr[12:], uint32(h.Nt))
+	binary.LittleEndian.PutUint32(hdr[16:], uint32(h.Stride))
+	binary.LittleEndian.PutUint32(hdr[20:], uint32(h.Frames))
+	binary.LittleEndian.PutUint32(hdr[24:], uint32(h.ValueSize))
+	if h.Diverged {
+		binary.LittleEndian.PutUint32(hdr[28:], 1)
+	}
+	binary.LittleEndian.PutUint64(hdr[32:], math.Float64bits(h.Dx))
+	binary.LittleEndian.PutUint64(hdr[40:], math.Float64bits(h.Dt))
+	binary.LittleEndian.PutUint64(hdr[48:], math.Float64bits(h.Offset))
+	binary.LittleEndian.PutUint64(hdr[56:], math.Float64bits(h.Scale))
+	if _, err := bw.Write(hdr[:]); err != nil {
+		return err
+	}
+
+	var b [8]byte
+	for _, row := range frames {
+		for _, v := range row {
+			if h.ValueSize == 4 {
+				binary.LittleEndian.PutUint32(b[:], math.Float32bits(float32((v-h.Offset)/h.Scale)))
+			} else {
+				binary.LittleEndian.PutUint64(b[:], math.Float64bits(v))
+			}
+			if _, err := bw.Write(b[:h.ValueSize]); err != nil {
+				return err
+			}
+		}
+	}
+	return bw.Flush()
+}
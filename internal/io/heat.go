@@ -0,0 +1,70 @@
+package io
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"strconv"
+
+	"heat-solver/internal/mathutils"
+)
+
+// Теплосодержание по слоям в CSV: t,Q и, если exact задан, Q_exact и
+// rel_error = |Q − Q_exact|/|Q_exact|. Q пишется с полной точностью
+// float64, чтобы уход на уровне округления был виден. Заодно ведётся
+// сводка Balance
+type HeatCSVWriter struct {
+	w       *bufio.Writer
+	dx      float64
+	dt      float64
+	exact   mathutils.Reference
+	buf     []byte
+	header  bool
+	balance mathutils.HeatBalance
+}
+
+func NewHeatCSVWriter(w io.Writer, dx, dt float64, exact mathutils.Reference) *HeatCSVWriter {
+	return &HeatCSVWriter{w: bufio.NewWriter(w), dx: dx, dt: dt, exact: exact}
+}
+
+// Строка слоя n (время n·dt); слои пишутся по порядку
+func (h *HeatCSVWriter) WriteLevel(n int, row []float64) error {
+	if !h.header {
+		h.header = true
+		header := "t,Q\n"
+		if h.exact != nil {
+			header = "t,Q,Q_exact,rel_error\n"
+		}
+		if _, err := h.w.WriteString(header); err != nil {
+			return err
+		}
+	}
+
+	t := float64(n) * h.dt
+	q := mathutils.HeatContent(row, h.dx)
+	h.buf = strconv.AppendFloat(h.buf[:0], t, 'f', 6, 64)
+	h.buf = append(h.buf, ',')
+	h.buf = strconv.AppendFloat(h.buf, q, 'g', -1, 64)
+	qe := math.NaN()
+	if h.exact != nil {
+		qe = h.exact.Heat(t)
+		h.buf = append(h.buf, ',')
+		h.buf = strconv.AppendFloat(h.buf, qe, 'g', -1, 64)
+		h.buf = append(h.buf, ',')
+		h.buf = strconv.AppendFloat(h.buf, math.Abs(q-qe)/math.Abs(qe), 'g', 6, 64)
+	}
+	h.buf = append(h.buf, '\n')
+	h.balance.Add(q, qe)
+	_, err := h.w.Write(h.buf)
+	return err
+}
+
+// Сводка по записанным слоям
+func (h *HeatCSVWriter) Balance() mathutils.HeatBalance {
+	return h.balance
+}
+
+// Дозапись буфера; w не закрывается
+func (h *HeatCSVWriter) Flush() error {
+	return h.w.Flush()
+}
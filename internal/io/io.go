@@ -1,7 +1,8 @@
 package io
 
 import (
-	"encoding/csv"
+	"bufio"
+	"io"
 	"log/slog"
 	"math"
 	"os"
@@ -10,7 +11,35 @@ import (
 	"heat-solver/internal/mathutils"
 )
 
-func SaveToCSV(u [][]float64, dx, dt float64, filename string) error {
+// Наборы столбцов CSV: только численное решение или вместе с точным
+// решением и модулем ошибки (только когда есть эталон)
+const (
+	ColumnsNumeric = "numeric"
+	ColumnsAll     = "all"
+)
+
+// Сохранение слоёв u в CSV: x,t,u_numeric и, если exact задан, столбцы
+// u_exact и error = |u_numeric − u_exact|. При exact == nil эталон не
+// вычисляется вовсе. comments — строки метаданных перед заголовком
+// (см. CSVLevelWriter.Comment)
+func SaveToCSV(u [][]float64, dx, dt float64, filename string, exact mathutils.Reference, comments ...string) error {
+	return saveCSV(u, filename, exact, func(w io.Writer) *CSVLevelWriter {
+		c := NewCSVLevelWriter(w, dx, dt, exact)
+		for _, line := range comments {
+			c.Comment(line)
+		}
+		return c
+	})
+}
+
+// То же на неравномерной сетке: в столбце x — узлы x[i], а не i·dx
+func SaveToCSVNodes(u [][]float64, x []float64, dt float64, filename string, exact mathutils.Reference) error {
+	return saveCSV(u, filename, exact, func(w io.Writer) *CSVLevelWriter {
+		return NewCSVNodesLevelWriter(w, x, dt, exact)
+	})
+}
+
+func saveCSV(u [][]float64, filename string, exact mathutils.Reference, writer func(io.Writer) *CSVLevelWriter) error {
 	slog.Info("Saving results to CSV", "file", filename)
 
 	file, err := os.Create(filename)
@@ -24,13 +53,7 @@ func SaveToCSV(u [][]float64, dx, dt float64, filename string) error {
 		}
 	}()
 
-	writer := csv.NewWriter(file)
-	defer writer.Flush()
-
-	if err := writer.Write([]string{"x", "t", "u_numeric", "u_exact", "error"}); err != nil {
-		slog.Error("Failed to write CSV header", "error", err)
-		return err
-	}
+	w := writer(file)
 
 	nt := len(u) - 1
 	nx := len(u[0]) - 1
@@ -39,28 +62,145 @@ func SaveToCSV(u [][]float64, dx, dt float64, filename string) error {
 		"rows", (nt+1)*(nx+1),
 		"nx", nx,
 		"nt", nt,
+		"exact", exact != nil,
 	)
 
 	for n := 0; n <= nt; n++ {
-		t := float64(n) * dt
-		for i := 0; i <= nx; i++ {
-			x := float64(i) * dx
-			exact := mathutils.AnalyticalSolution(x, t)
-			errVal := math.Abs(u[n][i] - exact)
-
-			if err := writer.Write([]string{
-				strconv.FormatFloat(x, 'f', 6, 64),
-				strconv.FormatFloat(t, 'f', 6, 64),
-				strconv.FormatFloat(u[n][i], 'f', 6, 64),
-				strconv.FormatFloat(exact, 'f', 6, 64),
-				strconv.FormatFloat(errVal, 'f', 6, 64),
-			}); err != nil {
-				slog.Error("Failed to write CSV record", "row", n, "col", i, "error", err)
-				return err
-			}
+		if err := w.WriteLevel(n, u[n]); err != nil {
+			slog.Error("Failed to write CSV record", "row", n, "error", err)
+			return err
 		}
 	}
+	if err := w.Flush(); err != nil {
+		slog.Error("Failed to write CSV file", "file", filename, "error", err)
+		return err
+	}
 
 	slog.Info("CSV file successfully written", "file", filename)
 	return nil
 }
+
+// Послойная запись CSV в формате SaveToCSV: слой n относится к времени
+// n·dt, заголовок пишется перед первым слоем. Строки собираются в один
+// переиспользуемый буфер без csv.Writer: числа и заголовок не содержат
+// символов, требующих кавычек
+type CSVLevelWriter struct {
+	w     *bufio.Writer
+	dx    float64
+	dt    float64
+	exact mathutils.Reference
+	// Эталон на узлах слоя: создаётся по первому слою, значения — в ue
+	grid mathutils.GridFunc
+	ue   []float64
+	// "x," узлов: координаты одинаковы на всех слоях и форматируются один раз
+	xs     [][]byte
+	ts     []byte
+	buf    []byte
+	header bool
+	// Строки метаданных до заголовка
+	comments []string
+}
+
+func NewCSVLevelWriter(w io.Writer, dx, dt float64, exact mathutils.Reference) *CSVLevelWriter {
+	return &CSVLevelWriter{w: bufio.NewWriterSize(w, 1<<20), dx: dx, dt: dt, exact: exact}
+}
+
+// Запись слоёв на узлах x (len(x) значений в слое): координаты и эталон
+// (через Eval в узлах) готовятся сразу
+func NewCSVNodesLevelWriter(w io.Writer, x []float64, dt float64, exact mathutils.Reference) *CSVLevelWriter {
+	c := &CSVLevelWriter{w: bufio.NewWriterSize(w, 1<<20), dt: dt, exact: exact}
+	c.xs = make([][]byte, len(x))
+	for i, xi := range x {
+		c.xs[i] = append(strconv.AppendFloat(nil, xi, 'f', 6, 64), ',')
+	}
+	if exact != nil {
+		c.grid = mathutils.NodesGrid(exact, x)
+		c.ue = make([]float64, len(x))
+	}
+	return c
+}
+
+// Строка метаданных "# line" перед заголовком, например "lambda=5";
+// действует до первого слоя. Читатели пропускают такие строки как
+// комментарии (pandas — с comment='#')
+func (c *CSVLevelWriter) Comment(line string) {
+	c.comments = append(c.comments, line)
+}
+
+// Строки слоя n; слои пишутся по порядку
+func (c *CSVLevelWriter) WriteLevel(n int, row []float64) error {
+	if !c.header {
+		c.header = true
+		for _, line := range c.comments {
+			if _, err := c.w.WriteString("# " + line + "\n"); err != nil {
+				return err
+			}
+		}
+		header := "x,t,u_numeric\n"
+		if c.exact != nil {
+			header = "x,t,u_numeric,u_exact,error\n"
+		}
+		if _, err := c.w.WriteString(header); err != nil {
+			return err
+		}
+	}
+	for i := len(c.xs); i < len(row); i++ {
+		x := strconv.AppendFloat(nil, float64(i)*c.dx, 'f', 6, 64)
+		c.xs = append(c.xs, append(x, ','))
+	}
+
+	t := float64(n) * c.dt
+	c.ts = strconv.AppendFloat(c.ts[:0], t, 'f', 6, 64)
+	c.ts = append(c.ts, ',')
+	var err error
+	if c.exact == nil {
+		c.buf, err = writeNumericLevel(c.w, c.buf, c.xs, c.ts, row)
+	} else {
+		if c.grid == nil {
+			c.grid = c.exact.Grid(len(row)-1, c.dx)
+			c.ue = make([]float64, len(row))
+		}
+		c.grid(c.ue, t)
+		c.buf, err = writeExactLevel(c.w, c.buf, c.xs, c.ts, row, c.ue)
+	}
+	return err
+}
+
+// Дозапись буфера; w не закрывается
+func (c *CSVLevelWriter) Flush() error {
+	return c.w.Flush()
+}
+
+// Строки слоя "x,t,u" для всех узлов; xs — "x," узлов, ts — "t,".
+// buf переиспользуется между вызовами
+func writeNumericLevel(w *bufio.Writer, buf []byte, xs [][]byte, ts []byte, row []float64) ([]byte, error) {
+	for i, v := range row {
+		buf = append(buf[:0], xs[i]...)
+		buf = append(buf, ts...)
+		buf = strconv.AppendFloat(buf, v, 'f', 6, 64)
+		buf = append(buf, '\n')
+		if _, err := w.Write(buf); err != nil {
+			return buf, err
+		}
+	}
+	return buf, nil
+}
+
+// То же со столбцами точного решения ues и ошибки
+func writeExactLevel(w *bufio.Writer, buf []byte, xs [][]byte, ts []byte, row, ues []float64) ([]byte, error) {
+	for i, v := range row {
+		ue := ues[i]
+		buf = append(buf[:0], xs[i]...)
+		buf = append(buf, ts...)
+		buf = strconv.AppendFloat(buf, v, 'f', 6, 64)
+		buf = append(buf, ',')
+		buf = strconv.AppendFloat(buf, ue, 'f', 6, 64)
+		buf = append(buf, ',')
+		buf = strconv.AppendFloat(buf, math.Abs(v-ue), 'f', 6, 64)
+		buf = append(buf, '\n')
+		if _, err := w.Write(buf); err != nil {
+			return buf, err
+		}
+	}
+	return buf, nil
+}
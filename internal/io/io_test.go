@@ -0,0 +1,588 @@
+package io
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"heat-solver/internal/mathutils"
+	"heat-solver/internal/solver"
+)
+
+// Журнал записи в тестах не нужен
+func TestMain(m *testing.M) {
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	os.Exit(m.Run())
+}
+
+// Слои nt+1 × nx+1 с гладким решением и особыми значениями
+func testLevels(nt, nx int) [][]float64 {
+	u := make([][]float64, nt+1)
+	for n := range u {
+		u[n] = make([]float64, nx+1)
+		for i := range u[n] {
+			u[n][i] = math.Exp(-float64(n)*0.01) * math.Sin(math.Pi*float64(i)/float64(nx))
+		}
+	}
+	u[nt][1] = math.NaN()
+	u[nt][2] = math.Inf(1)
+	u[nt][3] = math.Inf(-1)
+	u[nt][4] = math.Copysign(0, -1)
+	u[nt][5] = -1e-9
+	return u
+}
+
+// Прежняя запись через csv.Writer и FormatFloat: образец формата
+func csvWriterReference(w io.Writer, u [][]float64, x []float64, dt float64, exact mathutils.Reference) error {
+	cw := csv.NewWriter(w)
+	header := []string{"x", "t", "u_numeric"}
+	if exact != nil {
+		header = append(header, "u_exact", "error")
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	format := func(v float64) string { return strconv.FormatFloat(v, 'f', 6, 64) }
+	for n, row := range u {
+		t := float64(n) * dt
+		for i, v := range row {
+			record := []string{format(x[i]), format(t), format(v)}
+			if exact != nil {
+				ue := exact.Eval(x[i], t)
+				record = append(record, format(ue), format(math.Abs(v-ue)))
+			}
+			if err := cw.Write(record); err != nil {
+				return err
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// SaveToCSV и SaveToCSVNodes пишут побайтно то же, что csv.Writer
+func TestSaveToCSVMatchesCSVWriter(t *testing.T) {
+	const nt, nx = 7, 40
+	u := testLevels(nt, nx)
+	dir := t.TempDir()
+	tests := []struct {
+		name  string
+		dx    float64
+		dt    float64
+		exact mathutils.Reference
+	}{
+		{"numeric", 1.0 / nx, 0.001, nil},
+		{"exact", 1.0 / nx, 0.001, mathutils.Analytical{Alpha: 1}},
+		{"exact with alpha", 0.025, 0.0003, mathutils.Analytical{Alpha: 0.3}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			x := make([]float64, nx+1)
+			for i := range x {
+				x[i] = float64(i) * tc.dx
+			}
+			var want bytes.Buffer
+			if err := csvWriterReference(&want, u, x, tc.dt, tc.exact); err != nil {
+				t.Fatal(err)
+			}
+
+			file := filepath.Join(dir, tc.name+".csv")
+			if err := SaveToCSV(u, tc.dx, tc.dt, file, tc.exact); err != nil {
+				t.Fatal(err)
+			}
+			nodesFile := filepath.Join(dir, tc.name+"-nodes.csv")
+			if err := SaveToCSVNodes(u, x, tc.dt, nodesFile, tc.exact); err != nil {
+				t.Fatal(err)
+			}
+			for _, f := range []string{file, nodesFile} {
+				got, err := os.ReadFile(f)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !bytes.Equal(got, want.Bytes()) {
+					t.Errorf("%s differs from the csv.Writer output:\n%s", filepath.Base(f), firstDiff(got, want.Bytes()))
+				}
+			}
+		})
+	}
+}
+
+// На растянутой сетке в столбце x — сами узлы, и эталон считается в них
+func TestSaveToCSVNodesStretched(t *testing.T) {
+	const nt, nx = 5, 24
+	u := testLevels(nt, nx)
+	x := mathutils.StretchedNodes(nx, 2)
+	exact := mathutils.Analytical{Alpha: 1}
+	var want bytes.Buffer
+	if err := csvWriterReference(&want, u, x, 0.001, exact); err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(t.TempDir(), "stretched.csv")
+	if err := SaveToCSVNodes(u, x, 0.001, file, exact); err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want.Bytes()) {
+		t.Errorf("stretched output differs from the csv.Writer output:\n%s", firstDiff(got, want.Bytes()))
+	}
+}
+
+// Профиль без столбца t: с эталоном и без него
+func TestSaveProfileCSV(t *testing.T) {
+	u := []float64{1, 1.5, -0.25}
+	tests := []struct {
+		name  string
+		exact func(x float64) float64
+		want  string
+	}{
+		{"numeric", nil, "x,u_numeric\n0.000000,1.000000\n0.500000,1.500000\n1.000000,-0.250000\n"},
+		{"exact", func(x float64) float64 { return 1 + x }, "x,u_numeric,u_exact,error\n" +
+			"0.000000,1.000000,1.000000,0.000000\n0.500000,1.500000,1.500000,0.000000\n1.000000,-0.250000,2.000000,2.250000\n"},
+	}
+	dir := t.TempDir()
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			file := filepath.Join(dir, tc.name+".csv")
+			if err := SaveProfileCSV(u, 0.5, file, tc.exact); err != nil {
+				t.Fatal(err)
+			}
+			got, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tc.want {
+				t.Errorf("got\n%s\nwant\n%s", got, tc.want)
+			}
+		})
+	}
+	if err := SaveProfileCSV(u, 0.5, filepath.Join(dir, "missing", "u.csv"), nil); err == nil {
+		t.Error("no error for a file in a missing directory")
+	}
+}
+
+// Первая различающаяся строка двух выдач
+func firstDiff(got, want []byte) string {
+	g, w := bytes.Split(got, []byte("\n")), bytes.Split(want, []byte("\n"))
+	for i := range min(len(g), len(w)) {
+		if !bytes.Equal(g[i], w[i]) {
+			return fmt.Sprintf("line %d: %q, want %q", i+1, g[i], w[i])
+		}
+	}
+	return fmt.Sprintf("%d lines, want %d", len(g), len(w))
+}
+
+// Запись 1001×1001 слоёв: прежний csv.Writer и CSVLevelWriter
+func BenchmarkSaveToCSV(b *testing.B) {
+	const n = 1000
+	u := testLevels(n, n)
+	x := make([]float64, n+1)
+	for i := range x {
+		x[i] = float64(i) / n
+	}
+	exact := mathutils.Analytical{Alpha: 1}
+	b.Run("csv.Writer", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			if err := csvWriterReference(io.Discard, u, x, 0.001, exact); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("CSVLevelWriter", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			w := NewCSVLevelWriter(io.Discard, 1.0/n, 0.001, exact)
+			for k, row := range u {
+				if err := w.WriteLevel(k, row); err != nil {
+					b.Fatal(err)
+				}
+			}
+			if err := w.Flush(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+// Заголовок и число столбцов в обоих режимах; комментарии идут до заголовка
+func TestCSVColumns(t *testing.T) {
+	const nt, nx = 2, 5
+	u := testLevels(nt, nx)
+	exact := mathutils.Analytical{Alpha: 1}
+	tests := []struct {
+		name   string
+		exact  mathutils.Reference
+		header string
+	}{
+		{"numeric", nil, "x,t,u_numeric"},
+		{"all", exact, "x,t,u_numeric,u_exact,error"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			w := NewCSVLevelWriter(&buf, 1.0/nx, 0.01, tc.exact)
+			w.Comment("method=CN")
+			for n, row := range u {
+				if err := w.WriteLevel(n, row); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := w.Flush(); err != nil {
+				t.Fatal(err)
+			}
+
+			lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+			if lines[0] != "# method=CN" || lines[1] != tc.header || len(lines) != 2+(nt+1)*(nx+1) {
+				t.Fatalf("starts with %q, %q and has %d lines; want the comment, %q and %d rows",
+					lines[0], lines[1], len(lines), tc.header, (nt+1)*(nx+1))
+			}
+			columns := strings.Count(tc.header, ",") + 1
+			for k, line := range lines[2:] {
+				fields := strings.Split(line, ",")
+				if len(fields) != columns {
+					t.Fatalf("row %d %q has %d fields, want %d", k, line, len(fields), columns)
+				}
+				if tc.exact == nil {
+					continue
+				}
+				n, i := k/(nx+1), k%(nx+1)
+				want := []string{
+					strconv.FormatFloat(exact.Eval(float64(i)/nx, float64(n)*0.01), 'f', 6, 64),
+					strconv.FormatFloat(math.Abs(u[n][i]-exact.Eval(float64(i)/nx, float64(n)*0.01)), 'f', 6, 64),
+				}
+				if fields[3] != want[0] || fields[4] != want[1] {
+					t.Errorf("row %d exact,error = %s,%s; want %s,%s", k, fields[3], fields[4], want[0], want[1])
+				}
+			}
+		})
+	}
+}
+
+// Запись 1001×1001 слоёв без столбцов эталона и с ними
+func BenchmarkCSVColumns(b *testing.B) {
+	const n = 1000
+	u := testLevels(n, n)
+	for _, tc := range []struct {
+		name  string
+		exact mathutils.Reference
+	}{
+		{ColumnsNumeric, nil},
+		{ColumnsAll, mathutils.Analytical{Alpha: 1}},
+	} {
+		b.Run(tc.name, func(b *testing.B) {
+			for b.Loop() {
+				w := NewCSVLevelWriter(io.Discard, 1.0/n, 0.001, tc.exact)
+				for k, row := range u {
+					if err := w.WriteLevel(k, row); err != nil {
+						b.Fatal(err)
+					}
+				}
+				if err := w.Flush(); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// Столбцы CSV теплосодержания и сводка: CN на sin(πx) — ошибка Q падает
+// вчетверо при вдвое меньших dx и dt, при теплоизолированных концах Q
+// сохраняется до округления
+func TestHeatCSVWriter(t *testing.T) {
+	run := func(nx int, dt float64, exact mathutils.Reference, stream func(u0 []float64, nt int, dx float64, emit solver.EmitFunc) error) (string, mathutils.HeatBalance) {
+		dx := 1.0 / float64(nx)
+		var buf bytes.Buffer
+		w := NewHeatCSVWriter(&buf, dx, dt, exact)
+		u0 := solver.SineProfile(nx, dx)
+		if err := stream(u0, int(math.Round(0.1/dt)), dx, w.WriteLevel); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Flush(); err != nil {
+			t.Fatal(err)
+		}
+		return buf.String(), w.Balance()
+	}
+	cn := func(u0 []float64, nt int, dx float64, emit solver.EmitFunc) error {
+		_, err := solver.StreamCrankNicolsonFrom(u0, nt, dx, dx*dx, emit)
+		return err
+	}
+	exact := mathutils.Analytical{Alpha: 1}
+
+	var errs []float64
+	for _, nx := range []int{20, 40, 80} {
+		dx := 1.0 / float64(nx)
+		out, b := run(nx, dx*dx, exact, cn)
+		lines := strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+		if lines[0] != "t,Q,Q_exact,rel_error" || len(lines) != b.Levels+1 || b.Levels != int(math.Round(0.1/(dx*dx)))+1 {
+			t.Fatalf("nx=%d: header %q, %d lines for %d levels", nx, lines[0], len(lines), b.Levels)
+		}
+		fields := strings.Split(lines[len(lines)-1], ",")
+		q, _ := strconv.ParseFloat(fields[1], 64)
+		if len(fields) != 4 || q != b.Final || math.Abs(q-exact.Heat(0.1)) > 0.01*exact.Heat(0.1) {
+			t.Errorf("nx=%d: last row %q, want Q = %v close to %v", nx, lines[len(lines)-1], b.Final, exact.Heat(0.1))
+		}
+		errs = append(errs, b.MaxError)
+	}
+	for k := 1; k < len(errs); k++ {
+		if ratio := errs[k-1] / errs[k]; ratio < 3.5 || ratio > 4.5 {
+			t.Errorf("heat error %v → %v, ratio %.2f; want second order", errs[k-1], errs[k], ratio)
+		}
+	}
+
+	insulated, err := mathutils.ParseBoundary("neumann")
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, b := run(50, 1e-4, nil, func(u0 []float64, nt int, dx float64, emit solver.EmitFunc) error {
+		_, err := solver.StreamBoundaryFrom(u0, nt, dx, 1e-4, 1, 0.5, 0, insulated, insulated, emit)
+		return err
+	})
+	if !strings.HasPrefix(out, "t,Q\n") || b.MaxDrift > 1e-13 || b.MaxError != 0 {
+		t.Errorf("insulated run: header %q, drift %g, error %g; want t,Q and drift at round-off",
+			out[:strings.IndexByte(out, '\n')], b.MaxDrift, b.MaxError)
+	}
+}
+
+// Каждый stride-й слой sin(2πx)·0.5ⁿ: заголовок t,a_1,…, нули у
+// отсутствующих мод (NaN в нормировке relative) и Span по записанным слоям
+func TestModesCSVWriter(t *testing.T) {
+	const nx, nt, modes, dt = 8, 6, 3, 0.1
+	tests := []struct {
+		norm string
+		a2   func(n int) float64
+		zero string
+	}{
+		{mathutils.ModeAmplitude, func(n int) float64 { return math.Pow(0.5, float64(n)) }, "0"},
+		{mathutils.ModeOrthonormal, func(n int) float64 { return math.Pow(0.5, float64(n)) / math.Sqrt2 }, "0"},
+		{mathutils.ModeRelative, func(n int) float64 { return math.Pow(0.5, float64(n)) }, "NaN"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.norm, func(t *testing.T) {
+			var buf bytes.Buffer
+			w, err := NewModesCSVWriter(&buf, nx, modes, 2, dt, tc.norm)
+			if err != nil {
+				t.Fatal(err)
+			}
+			row := make([]float64, nx+1)
+			for n := 0; n <= nt; n++ {
+				for i := range row {
+					row[i] = math.Pow(0.5, float64(n)) * math.Sin(2*math.Pi*float64(i)/nx)
+				}
+				if err := w.WriteLevel(n, row); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := w.Flush(); err != nil {
+				t.Fatal(err)
+			}
+			records, err := csv.NewReader(&buf).ReadAll()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(records) != nt/2+2 || strings.Join(records[0], ",") != "t,a_1,a_2,a_3" {
+				t.Fatalf("%d records, header %v", len(records), records[0])
+			}
+			for j, rec := range records[1:] {
+				n := 2 * j
+				got := make([]float64, len(rec))
+				for k, f := range rec {
+					got[k], _ = strconv.ParseFloat(f, 64)
+				}
+				small := func(v float64) bool { return tc.zero == "NaN" && math.IsNaN(v) || math.Abs(v) < 1e-15 }
+				if got[0] != float64(n)*dt || math.Abs(got[2]-tc.a2(n)) > 1e-15 || !small(got[1]) || !small(got[3]) {
+					t.Errorf("step %d: row %v, want a_2 = %v and %s elsewhere", n, rec, tc.a2(n), tc.zero)
+				}
+			}
+			first, last, t0, t1 := w.Span()
+			if t0 != 0 || t1 != float64(nt)*dt || math.Abs(first[1]-1) > 1e-15 || math.Abs(last[1]-math.Pow(0.5, nt)) > 1e-15 {
+				t.Errorf("span %v at %v → %v at %v", first, t0, last, t1)
+			}
+		})
+	}
+	if _, err := NewModesCSVWriter(io.Discard, nx, nx, 1, dt, mathutils.ModeAmplitude); err == nil {
+		t.Error("nx modes accepted")
+	}
+	if _, err := NewModesCSVWriter(io.Discard, nx, 1, 1, dt, "energy"); err == nil {
+		t.Error("unknown normalization accepted")
+	}
+}
+
+// Столбец layer — номер слоя узла по Slab.LayerAt: узел на границе слоёв
+// относится к правому слою
+func TestCSVLayersLevelWriter(t *testing.T) {
+	slab := mathutils.Slab{{Thickness: 0.25, Conductivity: 1}, {Thickness: 0.5, Conductivity: 0.1}, {Thickness: 0.25, Conductivity: 1}}
+	const nx, dt = 4, 0.5
+	dx := slab.Length() / nx
+	layers := make([]int, nx+1)
+	for i := range layers {
+		layers[i] = slab.LayerAt(float64(i) * dx)
+	}
+	var buf bytes.Buffer
+	w := NewCSVLayersLevelWriter(&buf, dx, dt, layers)
+	for n, row := range [][]float64{{1, 0, 0, 0, 0}, {1, 0.5, 0.25, 0.125, 0}} {
+		if err := w.WriteLevel(n, row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	want := `x,layer,t,u_numeric
+0.000000,0,0.000000,1.000000
+0.250000,1,0.000000,0.000000
+0.500000,1,0.000000,0.000000
+0.750000,2,0.000000,0.000000
+1.000000,2,0.000000,0.000000
+0.000000,0,0.500000,1.000000
+0.250000,1,0.500000,0.500000
+0.500000,1,0.500000,0.250000
+0.750000,2,0.500000,0.125000
+1.000000,2,0.500000,0.000000
+`
+	if buf.String() != want {
+		t.Errorf("CSV:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
+
+// Два поля: столбцы u и v, с эталонами — ещё точные значения и ошибки
+// обоих полей
+func TestCSVTwoFieldLevelWriter(t *testing.T) {
+	mode := mathutils.TwoTemperatureMode{Alpha1: 1, Alpha2: 1, U0: 1, V0: 0.5}
+	u := []float64{0, 1, 0}
+	v := []float64{0, 0.25, 0}
+	tests := []struct {
+		name   string
+		exact  [2]mathutils.Reference
+		output string
+	}{
+		{"numeric", [2]mathutils.Reference{}, "x,t,u_numeric,v_numeric\n" +
+			"0.000000,0.000000,0.000000,0.000000\n0.500000,0.000000,1.000000,0.250000\n1.000000,0.000000,0.000000,0.000000\n"},
+		{"exact", [2]mathutils.Reference{mode.U(), mode.V()}, "x,t,u_numeric,v_numeric,u_exact,v_exact,u_error,v_error\n" +
+			"0.000000,0.000000,0.000000,0.000000,0.000000,0.000000,0.000000,0.000000\n" +
+			"0.500000,0.000000,1.000000,0.250000,1.000000,0.500000,0.000000,0.250000\n" +
+			"1.000000,0.000000,0.000000,0.000000,0.000000,0.000000,0.000000,0.000000\n"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			w := NewCSVTwoFieldLevelWriter(&buf, 0.5, 0.1, tc.exact[0], tc.exact[1])
+			if err := w.WriteLevel(0, u, v); err != nil {
+				t.Fatal(err)
+			}
+			if err := w.Flush(); err != nil {
+				t.Fatal(err)
+			}
+			if buf.String() != tc.output {
+				t.Errorf("CSV:\n%s\nwant:\n%s", buf.String(), tc.output)
+			}
+		})
+	}
+}
+
+// Температуры узлов сети: заголовок с именами узлов один раз, затем по
+// строке на слой
+func TestCSVNodesWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewCSVNodesWriter(&buf, []string{"J", "A", "B"}, 0.25)
+	for n, temps := range [][]float64{{0, 1, 0}, {0.5, 1, 0}, {0.4375, 1, 0}} {
+		if err := w.WriteLevel(n, temps); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	want := `t,J,A,B
+0.000000,0.000000,1.000000,0.000000
+0.250000,0.500000,1.000000,0.000000
+0.500000,0.437500,1.000000,0.000000
+`
+	if buf.String() != want {
+		t.Errorf("CSV:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
+
+// Маска: active = 1 только у включённых внутренних узлов; у выключенного
+// узла и у краёв — 0
+func TestCSVMaskedLevelWriter(t *testing.T) {
+	mask := mathutils.Mask2D{Nx: 3, Ny: 2, Masked: make([]bool, 12), Value: 1}
+	mask.Masked[6] = true
+	var buf bytes.Buffer
+	w := NewCSVMaskedLevelWriter(&buf, mask, 0.5)
+	if err := w.WriteLevel(1, []float64{0, 0, 0, 0, 0, 0.25, 1, 0, 0, 0, 0, 0}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	want := `x,y,active,t,u_numeric
+0.000000,0.000000,0,0.500000,0.000000
+0.333333,0.000000,0,0.500000,0.000000
+0.666667,0.000000,0,0.500000,0.000000
+1.000000,0.000000,0,0.500000,0.000000
+0.000000,0.500000,0,0.500000,0.000000
+0.333333,0.500000,1,0.500000,0.250000
+0.666667,0.500000,0,0.500000,1.000000
+1.000000,0.500000,0,0.500000,0.000000
+0.000000,1.000000,0,0.500000,0.000000
+0.333333,1.000000,0,0.500000,0.000000
+0.666667,1.000000,0,0.500000,0.000000
+1.000000,1.000000,0,0.500000,0.000000
+`
+	if buf.String() != want {
+		t.Errorf("CSV:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
+
+// Экстраполяция по пространству: столбец u_richardson, с эталоном — ещё
+// точные значения и ошибки обоих решений
+func TestCSVRichardsonLevelWriter(t *testing.T) {
+	raw, ext := []float64{0, 0.5, 0}, []float64{0, 0.75, 0}
+	var plain bytes.Buffer
+	w := NewCSVRichardsonLevelWriter(&plain, 0.5, 0.1, nil)
+	if err := w.WriteLevel(2, raw, ext); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	want := `x,t,u_numeric,u_richardson
+0.000000,0.200000,0.000000,0.000000
+0.500000,0.200000,0.500000,0.750000
+1.000000,0.200000,0.000000,0.000000
+`
+	if plain.String() != want {
+		t.Errorf("CSV:\n%s\nwant:\n%s", plain.String(), want)
+	}
+
+	var withExact bytes.Buffer
+	w = NewCSVRichardsonLevelWriter(&withExact, 0.5, 0.1, mathutils.Analytical{Alpha: 1})
+	if err := w.WriteLevel(0, raw, ext); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	r := csv.NewReader(&withExact)
+	records, err := r.ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(records[0], ","); got != "x,t,u_numeric,u_richardson,u_exact,error,error_richardson" {
+		t.Fatalf("header %s", got)
+	}
+	if mid := strings.Join(records[2], ","); mid != "0.500000,0.000000,0.500000,0.750000,1.000000,0.500000,0.250000" {
+		t.Errorf("middle row %s", mid)
+	}
+}
@@ -0,0 +1,26 @@
+package io
+
+import (
+	"io"
+	"strconv"
+)
+
+// Послойная запись решения составной стенки в CSV: x,layer,t,u_numeric,
+// layer — номер слоя узла с нуля (узел на границе слоёв относится к
+// правому, см. mathutils.Slab.LayerAt). Точного решения у переходной
+// задачи нет, поэтому столбцов u_exact и error тоже нет
+type CSVLayersLevelWriter struct {
+	nodeLevelWriter
+}
+
+// layers — номер слоя каждого из узлов x_i = i·dx
+func NewCSVLayersLevelWriter(w io.Writer, dx, dt float64, layers []int) *CSVLayersLevelWriter {
+	c := &CSVLayersLevelWriter{newNodeLevelWriter(w, "x,layer", dt)}
+	c.coords = make([][]byte, len(layers))
+	for i, layer := range layers {
+		coord := appendCoords(nil, float64(i)*dx)
+		coord = strconv.AppendInt(coord, int64(layer), 10)
+		c.coords[i] = append(coord, ',')
+	}
+	return c
+}
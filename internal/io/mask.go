@@ -0,0 +1,33 @@
+package io
+
+import (
+	"io"
+
+	"heat-solver/internal/mathutils"
+)
+
+// Послойная запись двумерного решения с маской в CSV:
+// x,y,active,t,u_numeric, где active — 1 у включённого узла и 0 у
+// выключенного (в нём u_numeric — температура маски) и у краёв квадрата.
+// По active графики отбрасывают отверстия
+type CSVMaskedLevelWriter struct {
+	nodeLevelWriter
+}
+
+func NewCSVMaskedLevelWriter(w io.Writer, mask mathutils.Mask2D, dt float64) *CSVMaskedLevelWriter {
+	c := &CSVMaskedLevelWriter{newNodeLevelWriter(w, "x,y,active", dt)}
+	nx, ny := mask.Nx, mask.Ny
+	c.coords = make([][]byte, 0, (nx+1)*(ny+1))
+	for j := 0; j <= ny; j++ {
+		for i := 0; i <= nx; i++ {
+			coord := appendCoords(nil, float64(i)/float64(nx), float64(j)/float64(ny))
+			if i > 0 && i < nx && j > 0 && j < ny && !mask.Masked[j*(nx+1)+i] {
+				coord = append(coord, "1,"...)
+			} else {
+				coord = append(coord, "0,"...)
+			}
+			c.coords = append(c.coords, coord)
+		}
+	}
+	return c
+}
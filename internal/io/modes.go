@@ -0,0 +1,100 @@
+package io
+
+import (
+	"bufio"
+	"io"
+	"slices"
+	"strconv"
+
+	"heat-solver/internal/mathutils"
+)
+
+// Коэффициенты первых синус-мод по слоям в CSV: t,a_1,…,a_M в нормировке
+// norm (mathutils.ModeAmplitude и др.). Пишется каждый stride-й слой.
+// Амплитуды первого и последнего записанного слоя сохраняются для сводки
+type ModesCSVWriter struct {
+	w       *bufio.Writer
+	dst     *mathutils.SineTransform
+	dt      float64
+	stride  int
+	norm    string
+	buf     []byte
+	a       []float64
+	first   []float64
+	last    []float64
+	firstT  float64
+	lastT   float64
+	written int
+}
+
+// nx — число интервалов сетки, dt — время между соседними слоями
+func NewModesCSVWriter(w io.Writer, nx, modes, stride int, dt float64, norm string) (*ModesCSVWriter, error) {
+	if err := mathutils.CheckModeNorm(norm); err != nil {
+		return nil, err
+	}
+	dst, err := mathutils.NewSineTransform(nx, modes)
+	if err != nil {
+		return nil, err
+	}
+	return &ModesCSVWriter{
+		w:      bufio.NewWriter(w),
+		dst:    dst,
+		dt:     dt,
+		stride: max(1, stride),
+		norm:   norm,
+		a:      make([]float64, modes),
+		last:   make([]float64, modes),
+	}, nil
+}
+
+// Строка слоя n (время n·dt), если n кратно stride; слои передаются по
+// порядку, начиная с нулевого
+func (m *ModesCSVWriter) WriteLevel(n int, row []float64) error {
+	if n%m.stride != 0 {
+		return nil
+	}
+	if m.written == 0 {
+		m.buf = append(m.buf[:0], 't')
+		for k := 1; k <= m.dst.Modes(); k++ {
+			m.buf = append(m.buf, ",a_"...)
+			m.buf = strconv.AppendInt(m.buf, int64(k), 10)
+		}
+		m.buf = append(m.buf, '\n')
+		if _, err := m.w.Write(m.buf); err != nil {
+			return err
+		}
+	}
+
+	t := float64(n) * m.dt
+	m.dst.Transform(m.last, row)
+	if m.written == 0 {
+		m.first, m.firstT = slices.Clone(m.last), t
+	}
+	m.lastT = t
+	m.written++
+
+	copy(m.a, m.last)
+	mathutils.NormalizeModes(m.a, m.first, m.norm)
+	m.buf = strconv.AppendFloat(m.buf[:0], t, 'g', -1, 64)
+	for _, v := range m.a {
+		m.buf = append(m.buf, ',')
+		m.buf = strconv.AppendFloat(m.buf, v, 'g', -1, 64)
+	}
+	m.buf = append(m.buf, '\n')
+	_, err := m.w.Write(m.buf)
+	return err
+}
+
+// Амплитуды первого и последнего записанного слоя и их времена; nil до
+// первой записи
+func (m *ModesCSVWriter) Span() (first, last []float64, t0, t1 float64) {
+	if m.written == 0 {
+		return nil, nil, 0, 0
+	}
+	return m.first, m.last, m.firstT, m.lastT
+}
+
+// Дозапись буфера; w не закрывается
+func (m *ModesCSVWriter) Flush() error {
+	return m.w.Flush()
+}
@@ -0,0 +1,45 @@
+package io
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Температуры узлов сети стержней по слоям в CSV: t и по столбцу на узел
+// с его именем в заголовке
+type CSVNodesWriter struct {
+	w      *bufio.Writer
+	names  []string
+	dt     float64
+	buf    []byte
+	header bool
+}
+
+func NewCSVNodesWriter(w io.Writer, names []string, dt float64) *CSVNodesWriter {
+	return &CSVNodesWriter{w: bufio.NewWriter(w), names: names, dt: dt}
+}
+
+// Строка слоя n (время n·dt), temps — по значению на узел в порядке names
+func (c *CSVNodesWriter) WriteLevel(n int, temps []float64) error {
+	if !c.header {
+		c.header = true
+		if _, err := c.w.WriteString("t," + strings.Join(c.names, ",") + "\n"); err != nil {
+			return err
+		}
+	}
+	c.buf = strconv.AppendFloat(c.buf[:0], float64(n)*c.dt, 'f', 6, 64)
+	for _, v := range temps {
+		c.buf = append(c.buf, ',')
+		c.buf = strconv.AppendFloat(c.buf, v, 'f', 6, 64)
+	}
+	c.buf = append(c.buf, '\n')
+	_, err := c.w.Write(c.buf)
+	return err
+}
+
+// Дозапись буфера; w не закрывается
+func (c *CSVNodesWriter) Flush() error {
+	return c.w.Flush()
+}
@@ -0,0 +1,60 @@
+package io
+
+import (
+	"bufio"
+	"log/slog"
+	"math"
+	"os"
+	"strconv"
+)
+
+// Сохранение одного профиля u (узлы i·dx) в CSV: x,u_numeric и, если
+// exact задан, u_exact и error = |u_numeric − u_exact|. Для
+// стационарного решения, у которого нет столбца t
+func SaveProfileCSV(u []float64, dx float64, filename string, exact func(x float64) float64) error {
+	slog.Info("Saving profile to CSV", "file", filename, "rows", len(u), "exact", exact != nil)
+
+	file, err := os.Create(filename)
+	if err != nil {
+		slog.Error("Failed to create output file", "file", filename, "error", err)
+		return err
+	}
+	w := bufio.NewWriter(file)
+	header := "x,u_numeric\n"
+	if exact != nil {
+		header = "x,u_numeric,u_exact,error\n"
+	}
+	_, err = w.WriteString(header)
+	var buf []byte
+	for i, v := range u {
+		if err != nil {
+			break
+		}
+		x := float64(i) * dx
+		buf = strconv.AppendFloat(buf[:0], x, 'f', 6, 64)
+		buf = append(buf, ',')
+		buf = strconv.AppendFloat(buf, v, 'f', 6, 64)
+		if exact != nil {
+			ue := exact(x)
+			buf = append(buf, ',')
+			buf = strconv.AppendFloat(buf, ue, 'f', 6, 64)
+			buf = append(buf, ',')
+			buf = strconv.AppendFloat(buf, math.Abs(v-ue), 'f', 6, 64)
+		}
+		buf = append(buf, '\n')
+		_, err = w.Write(buf)
+	}
+	if err == nil {
+		err = w.Flush()
+	}
+	if cerr := file.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		slog.Error("Failed to write CSV file", "file", filename, "error", err)
+		return err
+	}
+
+	slog.Info("CSV file successfully written", "file", filename)
+	return nil
+}
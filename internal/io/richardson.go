@@ -0,0 +1,75 @@
+package io
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"strconv"
+
+	"heat-solver/internal/mathutils"
+)
+
+// Послойная запись экстраполяции Ричардсона по пространству
+// (solver.StreamSpatialRichardson) в CSV: x,t,u_numeric,u_richardson и,
+// если exact задан, u_exact,error,error_richardson
+type CSVRichardsonLevelWriter struct {
+	w      *bufio.Writer
+	dx, dt float64
+	exact  mathutils.Reference
+	grid   mathutils.GridFunc
+	ue     []float64
+	xs     [][]byte
+	buf    []byte
+	header bool
+}
+
+func NewCSVRichardsonLevelWriter(w io.Writer, dx, dt float64, exact mathutils.Reference) *CSVRichardsonLevelWriter {
+	return &CSVRichardsonLevelWriter{w: bufio.NewWriterSize(w, 1<<20), dx: dx, dt: dt, exact: exact}
+}
+
+// Строки слоя n; слои пишутся по порядку
+func (c *CSVRichardsonLevelWriter) WriteLevel(n int, raw, extrapolated []float64) error {
+	if !c.header {
+		c.header = true
+		header := "x,t,u_numeric,u_richardson\n"
+		if c.exact != nil {
+			header = "x,t,u_numeric,u_richardson,u_exact,error,error_richardson\n"
+		}
+		if _, err := c.w.WriteString(header); err != nil {
+			return err
+		}
+		c.xs = make([][]byte, len(raw))
+		for i := range c.xs {
+			c.xs[i] = appendCoords(nil, float64(i)*c.dx)
+		}
+		if c.exact != nil {
+			c.grid = c.exact.Grid(len(raw)-1, c.dx)
+			c.ue = make([]float64, len(raw))
+		}
+	}
+
+	t := float64(n) * c.dt
+	if c.exact != nil {
+		c.grid(c.ue, t)
+	}
+	for i := range raw {
+		c.buf = append(c.buf[:0], c.xs[i]...)
+		c.buf = appendCoords(c.buf, t, raw[i])
+		c.buf = strconv.AppendFloat(c.buf, extrapolated[i], 'f', 6, 64)
+		if c.exact != nil {
+			c.buf = append(c.buf, ',')
+			c.buf = appendCoords(c.buf, c.ue[i], math.Abs(raw[i]-c.ue[i]))
+			c.buf = strconv.AppendFloat(c.buf, math.Abs(extrapolated[i]-c.ue[i]), 'f', 6, 64)
+		}
+		c.buf = append(c.buf, '\n')
+		if _, err := c.w.Write(c.buf); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Дозапись буфера; w не закрывается
+func (c *CSVRichardsonLevelWriter) Flush() error {
+	return c.w.Flush()
+}
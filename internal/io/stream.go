@@ -0,0 +1,122 @@
+package io
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"strconv"
+
+	"heat-solver/internal/mathutils"
+)
+
+// Построчная запись решения: WriteRow вызывается для каждого выводимого
+// временного слоя по порядку, Close дописывает буфер (w не закрывается)
+type RowWriter interface {
+	WriteRow(t float64, row []float64) error
+	Close() error
+}
+
+// CSV в длинном формате: заголовок x,t,u и по строке на узел.
+// NaN и Inf записываются как есть ("NaN", "+Inf"). bitSize — как в
+// strconv.FormatFloat: 32 для значений, хранившихся во float32. Если
+// exact задан, добавляются столбцы u_exact и error = |u − u_exact|
+func NewCSVRowWriter(w io.Writer, dx float64, bitSize int, exact mathutils.Reference) RowWriter {
+	return &csvRowWriter{w: bufio.NewWriterSize(w, 64<<10), dx: dx, bitSize: bitSize, exact: exact}
+}
+
+type csvRowWriter struct {
+	w       *bufio.Writer
+	dx      float64
+	bitSize int
+	exact   mathutils.Reference
+	grid    mathutils.GridFunc
+	ue      []float64
+	buf     []byte
+	header  bool
+}
+
+func (c *csvRowWriter) WriteRow(t float64, row []float64) error {
+	if !c.header {
+		c.header = true
+		header := "x,t,u\n"
+		if c.exact != nil {
+			header = "x,t,u,u_exact,error\n"
+		}
+		if _, err := c.w.WriteString(header); err != nil {
+			return err
+		}
+	}
+	if c.exact != nil {
+		if c.grid == nil {
+			c.grid = c.exact.Grid(len(row)-1, c.dx)
+			c.ue = make([]float64, len(row))
+		}
+		c.grid(c.ue, t)
+	}
+	for i, v := range row {
+		x := float64(i) * c.dx
+		c.buf = strconv.AppendFloat(c.buf[:0], x, 'g', -1, 64)
+		c.buf = append(c.buf, ',')
+		c.buf = strconv.AppendFloat(c.buf, t, 'g', -1, 64)
+		c.buf = append(c.buf, ',')
+		c.buf = strconv.AppendFloat(c.buf, v, 'g', -1, c.bitSize)
+		if c.exact != nil {
+			ue := c.ue[i]
+			c.buf = append(c.buf, ',')
+			c.buf = strconv.AppendFloat(c.buf, ue, 'g', -1, 64)
+			c.buf = append(c.buf, ',')
+			c.buf = strconv.AppendFloat(c.buf, math.Abs(v-ue), 'g', -1, 64)
+		}
+		c.buf = append(c.buf, '\n')
+		if _, err := c.w.Write(c.buf); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *csvRowWriter) Close() error {
+	return c.w.Flush()
+}
+
+// NDJSON: по объекту {"t": ..., "u": [...]} на слой; NaN и Inf — null
+func NewNDJSONRowWriter(w io.Writer, bitSize int) RowWriter {
+	return &ndjsonRowWriter{w: bufio.NewWriterSize(w, 64<<10), bitSize: bitSize}
+}
+
+type ndjsonRowWriter struct {
+	w       *bufio.Writer
+	bitSize int
+	buf     []byte
+}
+
+func (n *ndjsonRowWriter) WriteRow(t float64, row []float64) error {
+	n.buf = append(n.buf[:0], `{"t":`...)
+	n.buf = strconv.AppendFloat(n.buf, t, 'g', -1, 64)
+	n.buf = append(n.buf, `,"u":`...)
+	n.buf = AppendJSONRow(n.buf, row, n.bitSize)
+	n.buf = append(n.buf, "}\n"...)
+	_, err := n.w.Write(n.buf)
+	return err
+}
+
+func (n *ndjsonRowWriter) Close() error {
+	return n.w.Flush()
+}
+
+// Строка решения как JSON-массив; NaN и Inf — null. При bitSize = 32
+// значения записываются кратчайшей записью, точной для float32
+func AppendJSONRow(buf []byte, row []float64, bitSize int) []byte {
+	buf = append(buf, '[')
+	for i, v := range row {
+		if i > 0 {
+			buf = append(buf, ',')
+		}
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			buf = append(buf, "null"...)
+		} else {
+			buf = strconv.AppendFloat(buf, v, 'g', -1, bitSize)
+		}
+	}
+	return append(buf, ']')
+}
@@ -0,0 +1,80 @@
+package io
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"strconv"
+
+	"heat-solver/internal/mathutils"
+)
+
+// Послойная запись двух полей (solver.StreamTwoTemperatureFrom) в CSV:
+// x,t,u_numeric,v_numeric и, если эталоны заданы, u_exact,v_exact,
+// u_error,v_error
+type CSVTwoFieldLevelWriter struct {
+	w      *bufio.Writer
+	dx, dt float64
+	exactU mathutils.Reference
+	exactV mathutils.Reference
+	gridU  mathutils.GridFunc
+	gridV  mathutils.GridFunc
+	ue, ve []float64
+	xs     [][]byte
+	buf    []byte
+	header bool
+}
+
+// exactU и exactV задаются оба или ни одного
+func NewCSVTwoFieldLevelWriter(w io.Writer, dx, dt float64, exactU, exactV mathutils.Reference) *CSVTwoFieldLevelWriter {
+	return &CSVTwoFieldLevelWriter{w: bufio.NewWriterSize(w, 1<<20), dx: dx, dt: dt, exactU: exactU, exactV: exactV}
+}
+
+// Строки слоя n; слои пишутся по порядку
+func (c *CSVTwoFieldLevelWriter) WriteLevel(n int, u, v []float64) error {
+	exact := c.exactU != nil && c.exactV != nil
+	if !c.header {
+		c.header = true
+		header := "x,t,u_numeric,v_numeric\n"
+		if exact {
+			header = "x,t,u_numeric,v_numeric,u_exact,v_exact,u_error,v_error\n"
+		}
+		if _, err := c.w.WriteString(header); err != nil {
+			return err
+		}
+		c.xs = make([][]byte, len(u))
+		for i := range c.xs {
+			c.xs[i] = appendCoords(nil, float64(i)*c.dx)
+		}
+		if exact {
+			c.gridU, c.gridV = c.exactU.Grid(len(u)-1, c.dx), c.exactV.Grid(len(v)-1, c.dx)
+			c.ue, c.ve = make([]float64, len(u)), make([]float64, len(v))
+		}
+	}
+
+	t := float64(n) * c.dt
+	if exact {
+		c.gridU(c.ue, t)
+		c.gridV(c.ve, t)
+	}
+	for i := range u {
+		c.buf = append(c.buf[:0], c.xs[i]...)
+		c.buf = appendCoords(c.buf, t, u[i])
+		c.buf = strconv.AppendFloat(c.buf, v[i], 'f', 6, 64)
+		if exact {
+			c.buf = append(c.buf, ',')
+			c.buf = appendCoords(c.buf, c.ue[i], c.ve[i], math.Abs(u[i]-c.ue[i]))
+			c.buf = strconv.AppendFloat(c.buf, math.Abs(v[i]-c.ve[i]), 'f', 6, 64)
+		}
+		c.buf = append(c.buf, '\n')
+		if _, err := c.w.Write(c.buf); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Дозапись буфера; w не закрывается
+func (c *CSVTwoFieldLevelWriter) Flush() error {
+	return c.w.Flush()
+}
@@ -0,0 +1,32 @@
+package mathutils
+
+import "math"
+
+// Точное решение u_t + v·u_x = α·u_xx: затухающая бегущая волна
+// exp(−π²αt)·sin(π(x − vt)). При t = 0 — sin(πx), на краях значения
+// зависят от времени (краевые условия Дирихле берутся из Eval)
+type TravellingWave struct {
+	Alpha float64
+	V     float64
+}
+
+func (w TravellingWave) Eval(x, t float64) float64 {
+	return math.Exp(-math.Pi*math.Pi*w.Alpha*t) * math.Sin(math.Pi*(x-w.V*t))
+}
+
+// Сдвиг зависит от t, поэтому синус считается на каждом слое;
+// экспонента — один раз на слой
+func (w TravellingWave) Grid(nx int, dx float64) GridFunc {
+	return func(dst []float64, t float64) {
+		decay := math.Exp(-math.Pi * math.Pi * w.Alpha * t)
+		shift := w.V * t
+		for i := range dst {
+			dst[i] = decay * math.Sin(math.Pi*(float64(i)*dx-shift))
+		}
+	}
+}
+
+// ∫₀¹ exp(−π²αt)·sin(π(x − vt)) dx = (2/π)·exp(−π²αt)·cos(πvt)
+func (w TravellingWave) Heat(t float64) float64 {
+	return 2 / math.Pi * math.Exp(-math.Pi*math.Pi*w.Alpha*t) * math.Cos(math.Pi*w.V*t)
+}
@@ -0,0 +1,216 @@
+package mathutils
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Виды краевых условий на конце отрезка
+const (
+	// Значение u = g(t)
+	BCDirichlet = "dirichlet"
+	// Производная ∂u/∂x = g(t) (по оси x, не по внешней нормали): ноль —
+	// теплоизолированный край
+	BCNeumann = "neumann"
+	// Конвективный теплообмен −k·∂u/∂n = h·(u − u_amb), n — внешняя
+	// нормаль: справа −k·u_x = h·(u − u_amb), слева k·u_x = h·(u − u_amb)
+	BCRobin = "robin"
+)
+
+// Краевое условие на конце отрезка: вид и g(t) — число или выражение от
+// t; у условия Робина вместо g — h, u_amb и k. Нулевое значение — u = 0
+type Boundary struct {
+	Kind string
+	// Запись g (у условия Робина — параметров) как задана
+	Expr string
+	g    func(t float64) float64
+	// g зависит от t
+	timeDependent bool
+	// Коэффициент теплоотдачи h ≥ 0, температура среды и
+	// теплопроводность k > 0 условия Робина
+	H, Ambient, K float64
+}
+
+// Краевое условие из записи вид:g — "neumann:0", "dirichlet:1",
+// "neumann:sin(t)"; просто число или выражение — значение Дирихле, просто
+// вид — g = 0. Выражение — как у ParseSource, но только от t. Условие
+// Робина — "robin:h=10,uamb=0" с необязательным k=… (по умолчанию 1)
+func ParseBoundary(s string) (Boundary, error) {
+	s = strings.TrimSpace(s)
+	kind, expr, found := strings.Cut(s, ":")
+	kind = strings.ToLower(strings.TrimSpace(kind))
+	switch {
+	case s == "":
+		return Boundary{Kind: BCDirichlet, Expr: "0", g: func(float64) float64 { return 0 }}, nil
+	case !found && (kind == BCDirichlet || kind == BCNeumann):
+		expr = "0"
+	case !found && kind == BCRobin:
+		return Boundary{}, fmt.Errorf("boundary %q: robin needs h, e.g. robin:h=10,uamb=0", s)
+	case !found:
+		kind, expr = BCDirichlet, s
+	case kind == BCRobin:
+		return parseRobin(s, strings.TrimSpace(expr))
+	case kind != BCDirichlet && kind != BCNeumann:
+		return Boundary{}, fmt.Errorf("boundary %q: unknown kind %q, want %s, %s or %s", s, kind, BCDirichlet, BCNeumann, BCRobin)
+	}
+	expr = strings.TrimSpace(expr)
+	p := &exprParser{src: expr}
+	n, err := p.parse()
+	if err != nil {
+		return Boundary{}, fmt.Errorf("boundary %q: %w", s, err)
+	}
+	if n.usesX {
+		return Boundary{}, fmt.Errorf("boundary %q: the value may depend on t only", s)
+	}
+	g := func(t float64) float64 { return n.eval(0, t) }
+	if v := g(0); math.IsNaN(v) || math.IsInf(v, 0) {
+		return Boundary{}, fmt.Errorf("boundary %q: value %g at t = 0 is not finite", s, v)
+	}
+	return Boundary{Kind: kind, Expr: expr, g: g, timeDependent: n.usesT}, nil
+}
+
+// Параметры условия Робина h=…,uamb=…,k=… через запятую; h обязателен
+func parseRobin(s, params string) (Boundary, error) {
+	b := Boundary{Kind: BCRobin, Expr: params, K: 1}
+	seen := map[string]bool{}
+	for _, f := range strings.Split(params, ",") {
+		key, val, ok := strings.Cut(f, "=")
+		key = strings.ToLower(strings.TrimSpace(key))
+		if !ok {
+			return Boundary{}, fmt.Errorf("boundary %q: %q is not key=value", s, strings.TrimSpace(f))
+		}
+		v, err := strconv.ParseFloat(strings.TrimSpace(val), 64)
+		if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+			return Boundary{}, fmt.Errorf("boundary %q: %s = %q is not a finite number", s, key, strings.TrimSpace(val))
+		}
+		switch key {
+		case "h":
+			b.H = v
+		case "uamb":
+			b.Ambient = v
+		case "k":
+			b.K = v
+		default:
+			return Boundary{}, fmt.Errorf("boundary %q: unknown robin parameter %q, want h, uamb or k", s, key)
+		}
+		seen[key] = true
+	}
+	switch {
+	case !seen["h"]:
+		return Boundary{}, fmt.Errorf("boundary %q: robin needs h", s)
+	case b.H < 0:
+		return Boundary{}, fmt.Errorf("boundary %q: h = %g, want h ≥ 0", s, b.H)
+	case b.K <= 0:
+		return Boundary{}, fmt.Errorf("boundary %q: k = %g, want k > 0", s, b.K)
+	}
+	return b, nil
+}
+
+// g(t); у нулевого Boundary и у условия Робина — 0
+func (b Boundary) Value(t float64) float64 {
+	if b.g == nil {
+		return 0
+	}
+	return b.g(t)
+}
+
+// Условие на производную
+func (b Boundary) IsNeumann() bool {
+	return b.Kind == BCNeumann
+}
+
+// Условие на значение (в том числе нулевое значение Boundary); у условий
+// Неймана и Робина край — неизвестная схемы
+func (b Boundary) IsDirichlet() bool {
+	return b.Kind != BCNeumann && b.Kind != BCRobin
+}
+
+// Число Био h·L/k при длине L; у других видов — 0
+func (b Boundary) Biot(length float64) float64 {
+	if b.Kind != BCRobin {
+		return 0
+	}
+	return b.H * length / b.K
+}
+
+// Данные однородны: g ≡ 0, у условия Робина — u_amb = 0
+func (b Boundary) IsHomogeneous() bool {
+	if b.Kind == BCRobin {
+		return b.Ambient == 0
+	}
+	return !b.timeDependent && b.Value(0) == 0
+}
+
+// g меняется со временем
+func (b Boundary) TimeDependent() bool {
+	return b.timeDependent
+}
+
+// Запись вид:g, как у -bc-left; у условия Робина — все три параметра
+func (b Boundary) String() string {
+	if b.Kind == BCRobin {
+		return fmt.Sprintf("%s:h=%g,uamb=%g,k=%g", BCRobin, b.H, b.Ambient, b.K)
+	}
+	kind, expr := b.Kind, b.Expr
+	if kind == "" {
+		kind = BCDirichlet
+	}
+	if expr == "" {
+		expr = "0"
+	}
+	return kind + ":" + expr
+}
+
+// Первая мода u_t = α·u_xx на [0, 1] с однородными условиями на краях:
+// exp(−αk²t)·sin(kx + φ). Оба края Дирихле — sin(πx); Неймана слева —
+// cos(πx/2); Неймана справа — sin(πx/2); Неймана на обоих — cos(πx)
+// (постоянная мода не затухает, и первой берётся следующая)
+type BoundaryMode struct {
+	Alpha                     float64
+	LeftNeumann, RightNeumann bool
+}
+
+// Волновое число k и сдвиг φ
+func (m BoundaryMode) wave() (k, shift float64) {
+	k = math.Pi
+	if m.LeftNeumann != m.RightNeumann {
+		k = math.Pi / 2
+	}
+	if m.LeftNeumann {
+		shift = math.Pi / 2
+	}
+	return k, shift
+}
+
+// Скорость затухания моды αk²
+func (m BoundaryMode) Rate() float64 {
+	k, _ := m.wave()
+	return m.Alpha * k * k
+}
+
+func (m BoundaryMode) Eval(x, t float64) float64 {
+	k, shift := m.wave()
+	return math.Exp(-m.Rate()*t) * math.Sin(k*x+shift)
+}
+
+func (m BoundaryMode) Grid(nx int, dx float64) GridFunc {
+	k, shift := m.wave()
+	shape := make([]float64, nx+1)
+	for i := range shape {
+		shape[i] = math.Sin(k*float64(i)*dx + shift)
+	}
+	return func(dst []float64, t float64) {
+		decay := math.Exp(-m.Rate() * t)
+		for i, v := range shape[:len(dst)] {
+			dst[i] = decay * v
+		}
+	}
+}
+
+// ∫₀¹ sin(kx + φ) dx = (cos φ − cos(k + φ))/k
+func (m BoundaryMode) Heat(t float64) float64 {
+	k, shift := m.wave()
+	return math.Exp(-m.Rate()*t) * (math.Cos(shift) - math.Cos(k+shift)) / k
+}
@@ -0,0 +1,152 @@
+package mathutils
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestParseBoundary(t *testing.T) {
+	tests := []struct {
+		spec    string
+		kind    string
+		str     string
+		g       func(t float64) float64
+		dynT    bool
+		neumann bool
+	}{
+		{"", BCDirichlet, "dirichlet:0", func(float64) float64 { return 0 }, false, false},
+		{"neumann", BCNeumann, "neumann:0", func(float64) float64 { return 0 }, false, true},
+		{"dirichlet", BCDirichlet, "dirichlet:0", func(float64) float64 { return 0 }, false, false},
+		{"neumann:0", BCNeumann, "neumann:0", func(float64) float64 { return 0 }, false, true},
+		{" Neumann : -2.5 ", BCNeumann, "neumann:-2.5", func(float64) float64 { return -2.5 }, false, true},
+		{"dirichlet:1", BCDirichlet, "dirichlet:1", func(float64) float64 { return 1 }, false, false},
+		{"0.5", BCDirichlet, "dirichlet:0.5", func(float64) float64 { return 0.5 }, false, false},
+		{"neumann:sin(t)", BCNeumann, "neumann:sin(t)", math.Sin, true, true},
+		{"DIRICHLET:1 - exp(-t)", BCDirichlet, "dirichlet:1 - exp(-t)", func(t float64) float64 { return 1 - math.Exp(-t) }, true, false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.spec, func(t *testing.T) {
+			b, err := ParseBoundary(tc.spec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if b.Kind != tc.kind || b.String() != tc.str || b.TimeDependent() != tc.dynT || b.IsNeumann() != tc.neumann || b.IsDirichlet() == tc.neumann {
+				t.Errorf("%+v: kind %s, %q, time-dependent %v", b, b.Kind, b.String(), b.TimeDependent())
+			}
+			for _, tm := range []float64{0, 0.3, 2} {
+				if got := b.Value(tm); math.Abs(got-tc.g(tm)) > 1e-15 {
+					t.Errorf("g(%v) = %v, want %v", tm, got, tc.g(tm))
+				}
+			}
+			if homogeneous := !tc.dynT && tc.g(0) == 0; b.IsHomogeneous() != homogeneous {
+				t.Errorf("homogeneous %v", b.IsHomogeneous())
+			}
+		})
+	}
+	var zero Boundary
+	if !zero.IsDirichlet() || zero.IsNeumann() || zero.Value(1) != 0 || zero.String() != "dirichlet:0" || !zero.IsHomogeneous() {
+		t.Errorf("zero boundary %q", zero.String())
+	}
+}
+
+func TestParseBoundaryErrors(t *testing.T) {
+	for _, s := range []string{"periodic:0", "neumann:x", "dirichlet:sin(", "neumann:", "neumann:log(t)", "dirichlet:1/0", "y"} {
+		if b, err := ParseBoundary(s); err == nil {
+			t.Errorf("%q accepted as %q", s, b.String())
+		}
+	}
+}
+
+// Первая мода для каждой пары краёв: решает уравнение (невязка по
+// разностям), выполняет однородные условия и даёт ∫ по формуле
+// Симпсона; скорость затухания — αk²
+func TestBoundaryMode(t *testing.T) {
+	tests := []struct {
+		left, right bool
+		rate        float64
+	}{
+		{false, false, math.Pi * math.Pi},
+		{true, false, math.Pi * math.Pi / 4},
+		{false, true, math.Pi * math.Pi / 4},
+		{true, true, math.Pi * math.Pi},
+	}
+	for _, tc := range tests {
+		t.Run(fmt.Sprintf("neumann %v/%v", tc.left, tc.right), func(t *testing.T) {
+			m := BoundaryMode{Alpha: 0.5, LeftNeumann: tc.left, RightNeumann: tc.right}
+			if math.Abs(m.Rate()-0.5*tc.rate) > 1e-14 {
+				t.Errorf("rate %v, want %v", m.Rate(), 0.5*tc.rate)
+			}
+			const h, tm = 1e-4, 0.3
+			for _, x := range []float64{0.1, 0.45, 0.9} {
+				ut := (m.Eval(x, tm+h) - m.Eval(x, tm-h)) / (2 * h)
+				uxx := (m.Eval(x+h, tm) - 2*m.Eval(x, tm) + m.Eval(x-h, tm)) / (h * h)
+				if math.Abs(ut-m.Alpha*uxx) > 1e-5 {
+					t.Errorf("x=%v: u_t = %v, α·u_xx = %v", x, ut, m.Alpha*uxx)
+				}
+			}
+			for side, neumann := range map[float64]bool{0: tc.left, 1: tc.right} {
+				var v float64
+				if neumann {
+					v = (m.Eval(side+h, tm) - m.Eval(side-h, tm)) / (2 * h)
+				} else {
+					v = m.Eval(side, tm)
+				}
+				if math.Abs(v) > 1e-7 {
+					t.Errorf("x=%v: boundary residual %v", side, v)
+				}
+			}
+			const n = 1000
+			sum := m.Eval(0, tm) + m.Eval(1, tm)
+			for i := 1; i < n; i++ {
+				sum += float64(2+2*(i%2)) * m.Eval(float64(i)/n, tm)
+			}
+			if heat := m.Heat(tm); math.Abs(heat-sum/(3*n)) > 1e-12 {
+				t.Errorf("heat %v, quadrature %v", heat, sum/(3*n))
+			}
+			grid := make([]float64, 11)
+			m.Grid(10, 0.1)(grid, tm)
+			for i, v := range grid {
+				if math.Abs(v-m.Eval(float64(i)*0.1, tm)) > 1e-15 {
+					t.Errorf("grid u[%d] = %v, eval %v", i, v, m.Eval(float64(i)*0.1, tm))
+				}
+			}
+		})
+	}
+}
+
+func TestParseRobin(t *testing.T) {
+	tests := []struct {
+		spec          string
+		h, ambient, k float64
+		str           string
+		homogeneous   bool
+	}{
+		{"robin:h=10,uamb=0", 10, 0, 1, "robin:h=10,uamb=0,k=1", true},
+		{"ROBIN: h = 2.5 , k=0.5, uamb=-1", 2.5, -1, 0.5, "robin:h=2.5,uamb=-1,k=0.5", false},
+		{"robin:h=0", 0, 0, 1, "robin:h=0,uamb=0,k=1", true},
+	}
+	for _, tc := range tests {
+		b, err := ParseBoundary(tc.spec)
+		if err != nil {
+			t.Fatalf("%q: %v", tc.spec, err)
+		}
+		if b.Kind != BCRobin || b.H != tc.h || b.Ambient != tc.ambient || b.K != tc.k || b.String() != tc.str {
+			t.Errorf("%q: %+v, %q", tc.spec, b, b.String())
+		}
+		if b.IsDirichlet() || b.IsNeumann() || b.TimeDependent() || b.Value(1) != 0 || b.IsHomogeneous() != tc.homogeneous {
+			t.Errorf("%q: dirichlet %v, neumann %v, homogeneous %v", tc.spec, b.IsDirichlet(), b.IsNeumann(), b.IsHomogeneous())
+		}
+		if bi := b.Biot(2); bi != 2*tc.h/tc.k {
+			t.Errorf("%q: Bi = %v over length 2", tc.spec, bi)
+		}
+	}
+	if bi := (Boundary{Kind: BCNeumann}).Biot(1); bi != 0 {
+		t.Errorf("neumann Bi = %v", bi)
+	}
+	for _, s := range []string{"robin", "robin:", "robin:uamb=1", "robin:h=-1", "robin:h=1,k=0", "robin:h=x", "robin:h=inf", "robin:h=1,foo=2", "robin:h"} {
+		if b, err := ParseBoundary(s); err == nil {
+			t.Errorf("%q accepted as %q", s, b.String())
+		}
+	}
+}
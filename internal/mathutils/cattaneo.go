@@ -0,0 +1,52 @@
+package mathutils
+
+import "math"
+
+// Точное решение уравнения Каттанео–Вернотта τ·u_tt + u_t = α·u_xx из
+// sin(πx) с u_t(0) = 0 и нулём на краях: A(t)·sin(πx), где
+// τA″ + A′ + κA = 0, κ = απ², A(0) = 1, A′(0) = 0. С γ = 1/(2τ):
+// при κ/τ > γ² мода колеблется, A = e^{−γt}(cos ωt + γ/ω·sin ωt),
+// ω² = κ/τ − γ²; иначе затухает без колебаний. При τ = 0 —
+// exp(−απ²t), как у уравнения теплопроводности
+type CattaneoMode struct {
+	Alpha float64
+	Tau   float64
+}
+
+// Скорость распространения возмущений √(α/τ); при τ = 0 — +Inf
+func (c CattaneoMode) Speed() float64 {
+	return math.Sqrt(c.Alpha / c.Tau)
+}
+
+// Амплитуда моды A(t)
+func (c CattaneoMode) Amplitude(t float64) float64 {
+	kappa := math.Pi * math.Pi * c.Alpha
+	if c.Tau == 0 {
+		return math.Exp(-kappa * t)
+	}
+	gamma := 1 / (2 * c.Tau)
+	w2 := kappa/c.Tau - gamma*gamma
+	switch {
+	case w2 > 0:
+		w := math.Sqrt(w2)
+		return math.Exp(-gamma*t) * (math.Cos(w*t) + gamma/w*math.Sin(w*t))
+	case w2 == 0:
+		return math.Exp(-gamma*t) * (1 + gamma*t)
+	}
+	// e^{−γt}·cosh и sinh через две экспоненты: при малом τ γ велико, и
+	// cosh отдельно переполнился бы
+	w := math.Sqrt(-w2)
+	return (1+gamma/w)/2*math.Exp((w-gamma)*t) + (1-gamma/w)/2*math.Exp(-(w+gamma)*t)
+}
+
+func (c CattaneoMode) Eval(x, t float64) float64 {
+	return c.Amplitude(t) * math.Sin(math.Pi*x)
+}
+
+func (c CattaneoMode) Grid(nx int, dx float64) GridFunc {
+	return sineProduct{c.Amplitude}.Grid(nx, dx)
+}
+
+func (c CattaneoMode) Heat(t float64) float64 {
+	return 2 / math.Pi * c.Amplitude(t)
+}
@@ -0,0 +1,45 @@
+package mathutils
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+// A(t) решает τA″ + A′ + π²αA = 0 с A(0) = 1, A′(0) = 0 при колебаниях,
+// критическом затухании (τ = 1/(4π²)) и без колебаний, в том числе при
+// малом τ, где A близка к exp(−π²t); при τ = 0 — ровно exp(−π²t)
+func TestCattaneoMode(t *testing.T) {
+	for _, tau := range []float64{0.5, 0.05, 1 / (4 * math.Pi * math.Pi), 0.01, 1e-4} {
+		t.Run(fmt.Sprintf("τ=%g", tau), func(t *testing.T) {
+			c := CattaneoMode{Alpha: 1, Tau: tau}
+			const h = 1e-5
+			if a0, slope := c.Amplitude(0), (c.Amplitude(h)-c.Amplitude(0))/h; math.Abs(a0-1) > 1e-15 || math.Abs(slope) > 1e-4/tau {
+				t.Errorf("A(0) = %v, A′(0) ≈ %v", a0, slope)
+			}
+			for _, tm := range []float64{0.01, 0.1, 0.5} {
+				a := func(s float64) float64 { return c.Amplitude(tm + s) }
+				d1 := (a(h) - a(-h)) / (2 * h)
+				d2 := (a(h) - 2*a(0) + a(-h)) / (h * h)
+				if r := tau*d2 + d1 + math.Pi*math.Pi*a(0); math.Abs(r) > 1e-4 {
+					t.Errorf("t=%v: residual %.3g", tm, r)
+				}
+			}
+			if tau == 1e-4 {
+				if a, heat := c.Amplitude(0.1), math.Exp(-math.Pi*math.Pi*0.1); math.Abs(a-heat) > 1e-2*heat {
+					t.Errorf("A(0.1) = %v, heat equation %v", a, heat)
+				}
+			}
+			if h := c.Heat(0.1); math.Abs(h-2/math.Pi*c.Amplitude(0.1)) > 1e-15 {
+				t.Errorf("heat %v", h)
+			}
+		})
+	}
+	heat := CattaneoMode{Alpha: 2}
+	if a := heat.Amplitude(0.3); a != math.Exp(-2*math.Pi*math.Pi*0.3) || !math.IsInf(heat.Speed(), 1) {
+		t.Errorf("τ = 0: A = %v, speed %v", a, heat.Speed())
+	}
+	if s := (CattaneoMode{Alpha: 1, Tau: 0.04}).Speed(); s != 5 {
+		t.Errorf("speed %v, want 5", s)
+	}
+}
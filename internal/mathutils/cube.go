@@ -0,0 +1,56 @@
+package mathutils
+
+import "math"
+
+// Эталонное решение в кубе: Eval — в точке, Grid — на слоях сетки
+// (nx+1)·(ny+1)·(nz+1) узлов, x меняется быстрее, z — медленнее всего
+type Reference3D interface {
+	Eval(x, y, z, t float64) float64
+	Grid(nx, ny, nz int, dx, dy, dz float64) GridFunc
+}
+
+// Точное решение в единичном кубе exp(−3απ²t)·sin(πx)·sin(πy)·sin(πz)
+// для начального условия sin(πx)·sin(πy)·sin(πz) с нулевыми гранями
+type Analytical3D struct {
+	Alpha float64
+}
+
+func (a Analytical3D) Eval(x, y, z, t float64) float64 {
+	return math.Exp(-3*math.Pi*math.Pi*a.Alpha*t) * math.Sin(math.Pi*x) * math.Sin(math.Pi*y) * math.Sin(math.Pi*z)
+}
+
+// Форма считается один раз на сетку, экспонента — один раз на слой, как в
+// Analytical2D
+func (a Analytical3D) Grid(nx, ny, nz int, dx, dy, dz float64) GridFunc {
+	plane := Analytical2D{}.Grid(nx, ny, dx, dy)
+	shape := make([]float64, (nx+1)*(ny+1)*(nz+1))
+	w := (nx + 1) * (ny + 1)
+	plane(shape[:w], 0)
+	for k := nz; k >= 0; k-- {
+		sz := math.Sin(math.Pi * (float64(k) * dz))
+		for p, s := range shape[:w] {
+			shape[k*w+p] = s * sz
+		}
+	}
+	return func(dst []float64, t float64) {
+		decay := math.Exp(-3 * math.Pi * math.Pi * a.Alpha * t)
+		for k, s := range shape[:len(dst)] {
+			dst[k] = decay * s
+		}
+	}
+}
+
+// Нормы ошибки L2 (среднеквадратичная по узлам) и L∞ трёхмерного слоя row
+// в момент t
+func LevelErrors3D(row []float64, nx, ny, nz int, dx, dy, dz, t float64, exact Reference3D) (float64, float64) {
+	ue := make([]float64, len(row))
+	exact.Grid(nx, ny, nz, dx, dy, dz)(ue, t)
+
+	var sumSq, linf float64
+	for k, v := range row {
+		err := math.Abs(v - ue[k])
+		sumSq += err * err
+		linf = max(linf, err)
+	}
+	return math.Sqrt(sumSq / float64(len(row))), linf
+}
@@ -0,0 +1,71 @@
+package mathutils
+
+import (
+	"fmt"
+	"math"
+)
+
+// LU-разложение плотной матрицы n×n с выбором ведущего элемента по
+// столбцу; для малых систем (узлы сети стержней, дополнение Шура), где
+// разложение считается один раз, а решается много правых частей
+type DenseLU struct {
+	lu   [][]float64
+	perm []int
+}
+
+// Разложение копии a; ошибка, если матрица вырождена
+func NewDenseLU(a [][]float64) (*DenseLU, error) {
+	n := len(a)
+	lu := make([][]float64, n)
+	for i, row := range a {
+		if len(row) != n {
+			return nil, fmt.Errorf("matrix row %d has %d entries, want %d", i, len(row), n)
+		}
+		lu[i] = append([]float64(nil), row...)
+	}
+	perm := make([]int, n)
+	for i := range perm {
+		perm[i] = i
+	}
+	for k := 0; k < n; k++ {
+		p := k
+		for i := k + 1; i < n; i++ {
+			if math.Abs(lu[i][k]) > math.Abs(lu[p][k]) {
+				p = i
+			}
+		}
+		if lu[p][k] == 0 {
+			return nil, fmt.Errorf("singular matrix at column %d", k)
+		}
+		lu[k], lu[p] = lu[p], lu[k]
+		perm[k], perm[p] = perm[p], perm[k]
+		for i := k + 1; i < n; i++ {
+			l := lu[i][k] / lu[k][k]
+			lu[i][k] = l
+			for j := k + 1; j < n; j++ {
+				lu[i][j] -= l * lu[k][j]
+			}
+		}
+	}
+	return &DenseLU{lu: lu, perm: perm}, nil
+}
+
+// Решение с правой частью b в x (len(x) = len(b)); x не должен
+// пересекаться с b
+func (d *DenseLU) Solve(b, x []float64) {
+	n := len(d.lu)
+	for i := 0; i < n; i++ {
+		s := b[d.perm[i]]
+		for j := 0; j < i; j++ {
+			s -= d.lu[i][j] * x[j]
+		}
+		x[i] = s
+	}
+	for i := n - 1; i >= 0; i-- {
+		s := x[i]
+		for j := i + 1; j < n; j++ {
+			s -= d.lu[i][j] * x[j]
+		}
+		x[i] = s / d.lu[i][i]
+	}
+}
@@ -0,0 +1,60 @@
+package mathutils
+
+import "math"
+
+// Предустановка источника «лазер»: движущийся гауссов пучок с параметрами
+// из Laser (в ParseSource её нет, см. Laser.Source)
+const SourceLaser = "laser"
+
+// Сканирующий лазер: f(x, t) = Power·exp(−(x − Start − Speed·t)²/(2·Width²)).
+// Центр пучка может уйти с отрезка [0, 1]: гауссиана остаётся той же, на
+// отрезок попадает только её хвост, и подводимая мощность падает до нуля
+type Laser struct {
+	// Пиковая плотность мощности P
+	Power float64
+	// Скорость центра v; 0 — неподвижный пучок
+	Speed float64
+	// Полуширина σ > 0
+	Width float64
+	// Положение центра x₀ при t = 0
+	Start float64
+}
+
+// Пучок по умолчанию: проходит отрезок слева направо за время 2
+var DefaultLaser = Laser{Power: 100, Speed: 0.5, Width: 0.05, Start: 0}
+
+// Источник с выражением SourceLaser
+func (l Laser) Source() Source {
+	return Source{Expr: SourceLaser, f: l.Eval, timeDependent: l.Speed != 0}
+}
+
+func (l Laser) Eval(x, t float64) float64 {
+	d := (x - l.Center(t)) / l.Width
+	return l.Power * math.Exp(-d*d/2)
+}
+
+// Центр пучка в момент t
+func (l Laser) Center(t float64) float64 {
+	return l.Start + l.Speed*t
+}
+
+// Мощность, попадающая на отрезок: ∫₀¹ f(x, t) dx =
+// P·σ·√(π/2)·(erf((1 − c)/(√2σ)) + erf(c/(√2σ))), c — центр пучка
+func (l Laser) Deposited(t float64) float64 {
+	c, s := l.Center(t), math.Sqrt2*l.Width
+	return l.Power * l.Width * math.Sqrt(math.Pi/2) * (math.Erf((1-c)/s) + math.Erf(c/s))
+}
+
+// Момент, когда центр уходит с отрезка [0, 1]: 0, если он уже вне его,
+// +Inf, если неподвижный центр на отрезке
+func (l Laser) Exit() float64 {
+	switch {
+	case l.Start < 0 || l.Start > 1:
+		return 0
+	case l.Speed > 0:
+		return (1 - l.Start) / l.Speed
+	case l.Speed < 0:
+		return -l.Start / l.Speed
+	}
+	return math.Inf(1)
+}
@@ -0,0 +1,49 @@
+package mathutils
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+// Мощность на отрезке по erf совпадает с квадратурой Симпсона, в том
+// числе когда центр уже ушёл с отрезка и греет только хвост; момент
+// ухода центра — по скорости и началу
+func TestLaser(t *testing.T) {
+	tests := []struct {
+		l    Laser
+		exit float64
+	}{
+		{DefaultLaser, 2},
+		{Laser{Power: 50, Speed: 0, Width: 0.1, Start: 0.5}, math.Inf(1)},
+		{Laser{Power: 1, Speed: -0.25, Width: 0.02, Start: 0.5}, 2},
+		{Laser{Power: 100, Speed: 1, Width: 0.05, Start: 1.1}, 0},
+		{Laser{Power: 100, Speed: 0, Width: 0.3, Start: -0.2}, 0},
+	}
+	for _, tc := range tests {
+		t.Run(fmt.Sprintf("%+v", tc.l), func(t *testing.T) {
+			if exit := tc.l.Exit(); exit != tc.exit {
+				t.Errorf("exit at %v, want %v", exit, tc.exit)
+			}
+			s := tc.l.Source()
+			if s.Expr != SourceLaser || s.TimeDependent() != (tc.l.Speed != 0) || s.SineSolution() != nil {
+				t.Errorf("source %q, time-dependent %v", s.Expr, s.TimeDependent())
+			}
+			for _, tm := range []float64{0, 0.5, 1.9, 3} {
+				if c := tc.l.Center(tm); s.Eval(c, tm) != tc.l.Power {
+					t.Errorf("t=%v: f = %v at the center %v", tm, s.Eval(c, tm), c)
+				}
+				const n = 20000
+				h := 1.0 / n
+				sum := s.Eval(0, tm) + s.Eval(1, tm)
+				for i := 1; i < n; i++ {
+					sum += float64(2+2*(i%2)) * s.Eval(float64(i)*h, tm)
+				}
+				want := sum * h / 3
+				if got := tc.l.Deposited(tm); math.Abs(got-want) > 1e-9*math.Max(tc.l.Power, 1) {
+					t.Errorf("t=%v: deposited %.12g, quadrature %.12g", tm, got, want)
+				}
+			}
+		})
+	}
+}
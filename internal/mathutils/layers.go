@@ -0,0 +1,151 @@
+package mathutils
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Слой составной стенки: толщина, теплопроводность k и объёмная
+// теплоёмкость c (ρc); температуропроводность слоя — k/c
+type Layer struct {
+	Thickness    float64 `json:"thickness"`
+	Conductivity float64 `json:"conductivity"`
+	// 0 — как 1: тогда k — температуропроводность слоя
+	Capacity float64 `json:"capacity,omitempty"`
+}
+
+// Теплоёмкость с учётом значения по умолчанию
+func (l Layer) capacity() float64 {
+	if l.Capacity == 0 {
+		return 1
+	}
+	return l.Capacity
+}
+
+// Слои стенки слева направо; первый начинается в x = 0
+type Slab []Layer
+
+// Слои из записи "толщина:α,…" (теплоёмкость 1, k = α) или
+// "толщина:k:c,…", например "0.3:1.0,0.4:0.1,0.3:1.0"
+func ParseLayers(s string) (Slab, error) {
+	var slab Slab
+	for i, part := range strings.Split(s, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ":")
+		if len(fields) != 2 && len(fields) != 3 {
+			return nil, fmt.Errorf("layer %d %q: want thickness:alpha or thickness:conductivity:capacity", i+1, part)
+		}
+		var v [3]float64
+		for j, f := range fields {
+			x, err := strconv.ParseFloat(strings.TrimSpace(f), 64)
+			if err != nil {
+				return nil, fmt.Errorf("layer %d %q: bad number %q", i+1, part, f)
+			}
+			v[j] = x
+		}
+		slab = append(slab, Layer{Thickness: v[0], Conductivity: v[1], Capacity: v[2]})
+	}
+	return slab, slab.Validate()
+}
+
+// Хотя бы один слой, у каждого толщина, k и c (если задана) положительны
+// и конечны
+func (s Slab) Validate() error {
+	if len(s) == 0 {
+		return fmt.Errorf("no layers")
+	}
+	positive := func(v float64) bool { return v > 0 && !math.IsInf(v, 0) }
+	for i, l := range s {
+		switch {
+		case !positive(l.Thickness):
+			return fmt.Errorf("layer %d: thickness must be positive and finite, got %g", i+1, l.Thickness)
+		case !positive(l.Conductivity):
+			return fmt.Errorf("layer %d: conductivity must be positive and finite, got %g", i+1, l.Conductivity)
+		case !positive(l.capacity()):
+			return fmt.Errorf("layer %d: heat capacity must be positive and finite, got %g", i+1, l.Capacity)
+		}
+	}
+	return nil
+}
+
+// Общая толщина L
+func (s Slab) Length() float64 {
+	var length float64
+	for _, l := range s {
+		length += l.Thickness
+	}
+	return length
+}
+
+// Координаты границ слоёв: 0, x_1, …, L (len(s)+1 значений)
+func (s Slab) Interfaces() []float64 {
+	x := make([]float64, len(s)+1)
+	for i, l := range s {
+		x[i+1] = x[i] + l.Thickness
+	}
+	return x
+}
+
+// Номер слоя (с нуля), которому принадлежит точка x. Точка на границе
+// слоёв (с точностью до округления) относится к правому слою, x = L — к
+// последнему
+func (s Slab) LayerAt(x float64) int {
+	eps := 1e-9 * s.Length()
+	var right float64
+	for i, l := range s {
+		right += l.Thickness
+		if x < right-eps {
+			return i
+		}
+	}
+	return len(s) - 1
+}
+
+// Тепловое сопротивление отрезка [a, b]: ∫ dx/k по слоям, которые он
+// пересекает (вне стенки — по крайним слоям)
+func (s Slab) Resistance(a, b float64) float64 {
+	return s.integrate(a, b, func(l Layer) float64 { return 1 / l.Conductivity })
+}
+
+// Теплоёмкость отрезка [a, b]: ∫ c dx
+func (s Slab) Capacity(a, b float64) float64 {
+	return s.integrate(a, b, Layer.capacity)
+}
+
+// ∫ g dx по [a, b] для кусочно-постоянной по слоям g; первый слой
+// продолжается влево от 0, последний — вправо от L
+func (s Slab) integrate(a, b float64, g func(Layer) float64) float64 {
+	var sum, left float64
+	for i, l := range s {
+		lo, hi := left, left+l.Thickness
+		if i == 0 {
+			lo = math.Inf(-1)
+		}
+		if i == len(s)-1 {
+			hi = math.Inf(1)
+		}
+		if w := math.Min(b, hi) - math.Max(a, lo); w > 0 {
+			sum += w * g(l)
+		}
+		left += l.Thickness
+	}
+	return sum
+}
+
+// Перепады температуры на слоях в стационаре с u(0) = left, u(L) = right:
+// слои соединены последовательно, поток q = (left − right)/ΣR_j один и тот
+// же, и ΔT_j = q·R_j, R_j = толщина_j/k_j
+func (s Slab) SeriesDrops(left, right float64) []float64 {
+	total := s.Resistance(0, s.Length())
+	drops := make([]float64, len(s))
+	for i, l := range s {
+		drops[i] = (left - right) * l.Thickness / l.Conductivity / total
+	}
+	return drops
+}
+
+// Стационарный профиль: кусочно-линейный, с изломами на границах слоёв
+func (s Slab) Steady(x, left, right float64) float64 {
+	return left - (left-right)*s.Resistance(0, x)/s.Resistance(0, s.Length())
+}
@@ -0,0 +1,73 @@
+package mathutils
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestParseLayers(t *testing.T) {
+	slab, err := ParseLayers(" 0.3:1.0, 0.4:0.1:2 ,0.3:1")
+	want := Slab{{0.3, 1, 0}, {0.4, 0.1, 2}, {0.3, 1, 0}}
+	if err != nil || !slices.Equal(slab, want) {
+		t.Fatalf("layers %+v, %v; want %+v", slab, err, want)
+	}
+	for _, s := range []string{"", "0.3", "0.3:1:1:1", "0.3:x", "0:1", "0.3:-1", "0.3:1:0.0:", "0.3:1:-2", "0.3:inf", "0.3:1,,0.3:1"} {
+		if slab, err := ParseLayers(s); err == nil {
+			t.Errorf("%q accepted as %+v", s, slab)
+		}
+	}
+}
+
+// Стенка 0.3:1, 0.4:0.1 (c = 2), 0.3:1: границы слоёв, принадлежность
+// точек, сопротивление и теплоёмкость отрезков, в том числе за краями;
+// перепады последовательных сопротивлений и кусочно-линейный стационар
+func TestSlab(t *testing.T) {
+	slab := Slab{{0.3, 1, 0}, {0.4, 0.1, 2}, {0.3, 1, 0}}
+	if l := slab.Length(); math.Abs(l-1) > 1e-15 {
+		t.Errorf("length %v", l)
+	}
+	if xs := slab.Interfaces(); len(xs) != 4 || xs[0] != 0 || math.Abs(xs[1]-0.3) > 1e-15 || math.Abs(xs[2]-0.7) > 1e-15 || math.Abs(xs[3]-1) > 1e-15 {
+		t.Errorf("interfaces %v", xs)
+	}
+	for _, tc := range []struct {
+		x     float64
+		layer int
+	}{{-1, 0}, {0, 0}, {0.29, 0}, {0.3, 1}, {0.1 + 0.2, 1}, {0.69, 1}, {0.7, 2}, {1, 2}, {2, 2}} {
+		if got := slab.LayerAt(tc.x); got != tc.layer {
+			t.Errorf("x = %v in layer %d, want %d", tc.x, got, tc.layer)
+		}
+	}
+	for _, tc := range []struct {
+		a, b, r, c float64
+	}{
+		{0, 1, 0.3 + 4 + 0.3, 0.3 + 0.8 + 0.3},
+		{0.2, 0.4, 0.1 + 1, 0.1 + 0.2},
+		{0.5, 0.6, 1, 0.2},
+		{-0.1, 0.1, 0.2, 0.2},
+		{0.9, 1.2, 0.3, 0.3},
+		{0.4, 0.4, 0, 0},
+	} {
+		if r, c := slab.Resistance(tc.a, tc.b), slab.Capacity(tc.a, tc.b); math.Abs(r-tc.r) > 1e-12 || math.Abs(c-tc.c) > 1e-12 {
+			t.Errorf("[%v, %v]: resistance %v, capacity %v; want %v, %v", tc.a, tc.b, r, c, tc.r, tc.c)
+		}
+	}
+
+	drops := slab.SeriesDrops(3, 1)
+	want := []float64{2 * 0.3 / 4.6, 2 * 4 / 4.6, 2 * 0.3 / 4.6}
+	for j := range want {
+		if math.Abs(drops[j]-want[j]) > 1e-15 {
+			t.Errorf("drops %v, want %v", drops, want)
+			break
+		}
+	}
+	for j, x := range slab.Interfaces() {
+		var drop float64
+		for _, d := range drops[:j] {
+			drop += d
+		}
+		if u := slab.Steady(x, 3, 1); math.Abs(u-(3-drop)) > 1e-14 {
+			t.Errorf("steady u(%v) = %v, want %v", x, u, 3-drop)
+		}
+	}
+}
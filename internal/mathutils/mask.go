@@ -0,0 +1,134 @@
+package mathutils
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Маска области на сетке единичного квадрата: узел (x_i, y_j) выключен,
+// если Masked[j·(Nx+1) + i] (порядок узлов — как у двумерных слоёв
+// solver). Выключенные узлы — граница Дирихле с температурой Value; так
+// из квадрата вырезаются отверстия и получаются, например, Г-образные
+// пластины. Края квадрата остаются нулевыми при любой маске
+type Mask2D struct {
+	Nx, Ny int
+	Masked []bool
+	Value  float64
+}
+
+// Прямоугольное отверстие [X0, X1]×[Y0, Y1]; узлы на его сторонах
+// выключены (это граница области)
+type MaskHole struct {
+	X0, X1, Y0, Y1 float64
+}
+
+// Г-образная пластина: квадрат без правой верхней четверти
+const MaskLShape = "L"
+
+// Отверстия из записи "x0:x1:y0:y1,…" или MaskLShape
+// ("0.5:1:0.5:1")
+func ParseMaskHoles(s string) ([]MaskHole, error) {
+	if strings.EqualFold(strings.TrimSpace(s), MaskLShape) {
+		return []MaskHole{{0.5, 1, 0.5, 1}}, nil
+	}
+	var holes []MaskHole
+	for i, part := range strings.Split(s, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ":")
+		if len(fields) != 4 {
+			return nil, fmt.Errorf("hole %d %q: want x0:x1:y0:y1", i+1, part)
+		}
+		var v [4]float64
+		for j, f := range fields {
+			x, err := strconv.ParseFloat(strings.TrimSpace(f), 64)
+			if err != nil || math.IsNaN(x) || math.IsInf(x, 0) {
+				return nil, fmt.Errorf("hole %d %q: bad number %q", i+1, part, f)
+			}
+			v[j] = x
+		}
+		if v[0] > v[1] || v[2] > v[3] {
+			return nil, fmt.Errorf("hole %d %q: want x0 ≤ x1 and y0 ≤ y1", i+1, part)
+		}
+		holes = append(holes, MaskHole{v[0], v[1], v[2], v[3]})
+	}
+	return holes, nil
+}
+
+// Маска с отверстиями holes на сетке nx×ny; узел на стороне отверстия —
+// с допуском в 1e-9 шага
+func NewMaskHoles(nx, ny int, holes []MaskHole, value float64) Mask2D {
+	m := Mask2D{Nx: nx, Ny: ny, Masked: make([]bool, (nx+1)*(ny+1)), Value: value}
+	dx, dy := 1/float64(nx), 1/float64(ny)
+	for j := 0; j <= ny; j++ {
+		y := float64(j) * dy
+		for i := 0; i <= nx; i++ {
+			x := float64(i) * dx
+			for _, h := range holes {
+				if x >= h.X0-1e-9*dx && x <= h.X1+1e-9*dx && y >= h.Y0-1e-9*dy && y <= h.Y1+1e-9*dy {
+					m.Masked[j*(nx+1)+i] = true
+				}
+			}
+		}
+	}
+	return m
+}
+
+// Маска из текстовой сетки: строка на ряд узлов, первая — верхний
+// (y = 1), последняя — нижний (y = 0); символ на узел: '#' или '0' —
+// выключен, '.' или '1' — включён. Запятые и пробелы пропускаются, так
+// что годится и CSV из 0 и 1, пустые строки и строки с '%' в начале —
+// тоже. Все строки одной длины; сетка — (длина − 1)×(строк − 1)
+func ReadMaskGrid(r io.Reader, value float64) (Mask2D, error) {
+	var rows [][]bool
+	sc := bufio.NewScanner(r)
+	for line := 1; sc.Scan(); line++ {
+		text := strings.TrimSpace(sc.Text())
+		if text == "" || strings.HasPrefix(text, "%") {
+			continue
+		}
+		var row []bool
+		for _, ch := range text {
+			switch ch {
+			case '#', '0':
+				row = append(row, true)
+			case '.', '1':
+				row = append(row, false)
+			case ',', ' ', '\t':
+			default:
+				return Mask2D{}, fmt.Errorf("mask line %d: unexpected %q; want '#' or '0' (masked), '.' or '1' (active)", line, ch)
+			}
+		}
+		if len(rows) > 0 && len(row) != len(rows[0]) {
+			return Mask2D{}, fmt.Errorf("mask line %d: %d nodes, the first row has %d", line, len(row), len(rows[0]))
+		}
+		rows = append(rows, row)
+	}
+	if err := sc.Err(); err != nil {
+		return Mask2D{}, err
+	}
+	if len(rows) < 3 || len(rows[0]) < 3 {
+		return Mask2D{}, fmt.Errorf("mask needs at least 3×3 nodes, got %d rows", len(rows))
+	}
+	nx, ny := len(rows[0])-1, len(rows)-1
+	m := Mask2D{Nx: nx, Ny: ny, Masked: make([]bool, (nx+1)*(ny+1)), Value: value}
+	for k, row := range rows {
+		copy(m.Masked[(ny-k)*(nx+1):], row)
+	}
+	return m, nil
+}
+
+// Включённые внутренние узлы (края квадрата не считаются)
+func (m Mask2D) Active() int {
+	var n int
+	for j := 1; j < m.Ny; j++ {
+		for i := 1; i < m.Nx; i++ {
+			if !m.Masked[j*(m.Nx+1)+i] {
+				n++
+			}
+		}
+	}
+	return n
+}
@@ -0,0 +1,95 @@
+package mathutils
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseMaskHoles(t *testing.T) {
+	tests := []struct {
+		s    string
+		want []MaskHole
+	}{
+		{"L", []MaskHole{{0.5, 1, 0.5, 1}}},
+		{" l ", []MaskHole{{0.5, 1, 0.5, 1}}},
+		{"0.2:0.4:0:1", []MaskHole{{0.2, 0.4, 0, 1}}},
+		{"0.1:0.2:0.3:0.4, 0.5:0.5:0.6 : 0.9", []MaskHole{{0.1, 0.2, 0.3, 0.4}, {0.5, 0.5, 0.6, 0.9}}},
+	}
+	for _, tc := range tests {
+		if holes, err := ParseMaskHoles(tc.s); err != nil || !slices.Equal(holes, tc.want) {
+			t.Errorf("%q: %+v, %v; want %+v", tc.s, holes, err, tc.want)
+		}
+	}
+	for _, s := range []string{"", "0.1:0.2:0.3", "0.1:0.2:0.3:0.4:0.5", "0.1:x:0:1", "0.1:nan:0:1", "0:inf:0:1", "0.4:0.2:0:1", "0:1:0.5:0.4", "0:1:0:1,"} {
+		if holes, err := ParseMaskHoles(s); err == nil {
+			t.Errorf("%q accepted as %+v", s, holes)
+		}
+	}
+}
+
+// Г-образная маска на сетке 4×4: выключен правый верхний квадрат 3×3
+// узлов, стороны отверстия — тоже; остальные узлы и все на сетке без
+// отверстий включены
+func TestNewMaskHoles(t *testing.T) {
+	holes, _ := ParseMaskHoles(MaskLShape)
+	m := NewMaskHoles(4, 4, holes, 0.5)
+	if m.Nx != 4 || m.Ny != 4 || m.Value != 0.5 {
+		t.Fatalf("mask %dx%d, value %v", m.Nx, m.Ny, m.Value)
+	}
+	for j := 0; j <= 4; j++ {
+		for i := 0; i <= 4; i++ {
+			if want := i >= 2 && j >= 2; m.Masked[j*5+i] != want {
+				t.Errorf("node (%d, %d) masked %v, want %v", i, j, m.Masked[j*5+i], want)
+			}
+		}
+	}
+	if n := m.Active(); n != 5 {
+		t.Errorf("%d active interior nodes, want 5", n)
+	}
+	if n := NewMaskHoles(4, 3, nil, 0).Active(); n != 6 {
+		t.Errorf("%d active interior nodes without holes, want 6", n)
+	}
+	// Сторона отверстия между узлами: выключаются только узлы внутри
+	thin := NewMaskHoles(10, 10, []MaskHole{{0.25, 0.35, 0, 1}}, 0)
+	for i := 0; i <= 10; i++ {
+		if want := i == 3; thin.Masked[5*11+i] != want {
+			t.Errorf("thin hole: node %d masked %v", i, thin.Masked[5*11+i])
+		}
+	}
+}
+
+// Текстовая сетка: первая строка — верх, комментарии и пустые строки
+// пропускаются, CSV из 0 и 1 — то же, что '#' и '.'
+func TestReadMaskGrid(t *testing.T) {
+	text := `% L-shape
+.....
+..###
+
+..###
+.....
+.....
+`
+	csv := "1,1,1,1,1\n1,1,0,0,0\n1,1,0,0,0\n1,1,1,1,1\n1,1,1,1,1\n"
+	for name, src := range map[string]string{"text": text, "csv": csv} {
+		m, err := ReadMaskGrid(strings.NewReader(src), 2)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if m.Nx != 4 || m.Ny != 4 || m.Value != 2 {
+			t.Fatalf("%s: mask %dx%d, value %v", name, m.Nx, m.Ny, m.Value)
+		}
+		for j := 0; j <= 4; j++ {
+			for i := 0; i <= 4; i++ {
+				if want := i >= 2 && (j == 2 || j == 3); m.Masked[j*5+i] != want {
+					t.Errorf("%s: node (%d, %d) masked %v", name, i, j, m.Masked[j*5+i])
+				}
+			}
+		}
+	}
+	for _, src := range []string{"", "...\n...\n", "..\n..\n..\n", "...\n.x.\n...\n", "...\n....\n...\n"} {
+		if _, err := ReadMaskGrid(strings.NewReader(src), 0); err == nil {
+			t.Errorf("%q accepted", src)
+		}
+	}
+}
@@ -7,8 +7,13 @@ import (
 
 // Аналитическое решение u(x,t) = exp(-π²t) * sin(πx)
 func AnalyticalSolution(x, t float64) float64 {
-	result := math.Exp(-math.Pi*math.Pi*t) * math.Sin(math.Pi*x)
-	slog.Debug("AnalyticalSolution computed", "x", x, "t", t, "u_exact", result)
+	return AnalyticalSolutionAlpha(x, t, 1)
+}
+
+// Решение u_t = α·u_xx с тем же начальным условием: exp(-απ²t) * sin(πx)
+func AnalyticalSolutionAlpha(x, t, alpha float64) float64 {
+	result := math.Exp(-math.Pi*math.Pi*alpha*t) * math.Sin(math.Pi*x)
+	slog.Debug("AnalyticalSolution computed", "x", x, "t", t, "alpha", alpha, "u_exact", result)
 	return result
 }
 
@@ -18,3 +23,313 @@ func InitialCondition(x float64) float64 {
 	slog.Debug("InitialCondition computed", "x", x, "u0", result)
 	return result
 }
+
+// Эталонное решение u(x, t): Eval — в точке, Grid — на слоях сетки,
+// Heat — теплосодержание ∫₀¹ u dx
+type Reference interface {
+	Eval(x, t float64) float64
+	Grid(nx int, dx float64) GridFunc
+	Heat(t float64) float64
+}
+
+// Эталон в узлах x_i = i·dx: заполняет dst (nx+1 значений) на слое t.
+// Множители, зависящие только от x, посчитаны при создании, зависящие от
+// t — один раз на слой. Значения побайтно совпадают с Eval. Функцию нельзя
+// вызывать из нескольких горутин одновременно
+type GridFunc func(dst []float64, t float64)
+
+// Точное решение exp(-(απ² + λ)t)·sin(πx) для начального условия sin(πx):
+// u_t = α·u_xx − λ·u, при λ = 0 — обычное уравнение теплопроводности
+type Analytical struct {
+	Alpha float64
+	// Коэффициент линейной реакции λ
+	Lambda float64
+}
+
+// Скорость затухания απ² + λ; при λ = 0 побитно равна απ²
+func (a Analytical) rate() float64 {
+	return math.Pi*math.Pi*a.Alpha + a.Lambda
+}
+
+func (a Analytical) Eval(x, t float64) float64 {
+	if a.Lambda == 0 {
+		return AnalyticalSolutionAlpha(x, t, a.Alpha)
+	}
+	return math.Exp(-a.rate()*t) * math.Sin(math.Pi*x)
+}
+
+// sin(πx_i) считается один раз на сетку, exp(-(απ² + λ)t) — один раз на слой
+func (a Analytical) Grid(nx int, dx float64) GridFunc {
+	sines := make([]float64, nx+1)
+	for i := range sines {
+		sines[i] = math.Sin(math.Pi * (float64(i) * dx))
+	}
+	rate := a.rate()
+	return func(dst []float64, t float64) {
+		decay := math.Exp(-rate * t)
+		for i, s := range sines[:len(dst)] {
+			dst[i] = decay * s
+		}
+	}
+}
+
+// ∫₀¹ exp(-(απ² + λ)t)·sin(πx) dx = (2/π)·exp(-(απ² + λ)t)
+func (a Analytical) Heat(t float64) float64 {
+	return 2 / math.Pi * math.Exp(-a.rate()*t)
+}
+
+// Теплосодержание слоя Q = ∫ u dx по формуле трапеций на узлах i·dx
+func HeatContent(row []float64, dx float64) float64 {
+	if len(row) < 2 {
+		return 0
+	}
+	q := (row[0] + row[len(row)-1]) / 2
+	for _, v := range row[1 : len(row)-1] {
+		q += v
+	}
+	return q * dx
+}
+
+// Сводка теплосодержания по слоям расчёта. MaxDrift — наибольший уход
+// |Q_n − Q_0|/|Q_0|: при теплоизолированных границах он должен быть на
+// уровне округления, при нулевых температурах на границах это убыль
+// тепла. MaxError — наибольшее |Q_n − Q_exact|/|Q_exact|, если эталон
+// задан (иначе 0)
+type HeatBalance struct {
+	Initial  float64
+	Final    float64
+	MaxDrift float64
+	MaxError float64
+	Levels   int
+}
+
+// Учёт слоя с теплосодержанием q; exact — эталонное значение или NaN
+func (b *HeatBalance) Add(q, exact float64) {
+	if b.Levels == 0 {
+		b.Initial = q
+	}
+	b.Levels++
+	b.Final = q
+	if b.Initial != 0 {
+		b.MaxDrift = max(b.MaxDrift, math.Abs(q-b.Initial)/math.Abs(b.Initial))
+	}
+	if !math.IsNaN(exact) && exact != 0 {
+		b.MaxError = max(b.MaxError, math.Abs(q-exact)/math.Abs(exact))
+	}
+}
+
+// Нормы ошибки L2 и L∞ на последнем временном слое
+func ComputeErrors(u [][]float64, dx, dt float64) (float64, float64) {
+	nt := len(u) - 1
+	return LevelErrors(u[nt], dx, float64(nt)*dt, Analytical{Alpha: 1})
+}
+
+// Нормы ошибки слоя row в момент t относительно эталона exact
+func LevelErrors(row []float64, dx, t float64, exact Reference) (float64, float64) {
+	ue := make([]float64, len(row))
+	exact.Grid(len(row)-1, dx)(ue, t)
+
+	var sumSq, linf float64
+	for i, v := range row {
+		err := math.Abs(v - ue[i])
+		sumSq += err * err
+		// max, а не сравнение: NaN разошедшегося расчёта попадает в обе нормы
+		linf = max(linf, err)
+	}
+
+	l2 := math.Sqrt(sumSq / float64(len(row)))
+	slog.Debug("Error norms computed", "l2", l2, "linf", linf)
+	return l2, linf
+}
+
+// Ряд Фурье по синусам — точное решение задачи Дирихле на [0, 1] для
+// кусочно-линейного начального профиля: u(x,t) = Σ b_k·exp(-k²π²t)·sin(kπx)
+type SineSeries struct {
+	b []float64
+	// Коэффициент реакции λ: все моды дополнительно гаснут как exp(-λt)
+	lambda float64
+}
+
+// Тот же ряд для u_t = u_xx − λu: решение умножается на exp(-λt)
+func (s SineSeries) WithReaction(lambda float64) SineSeries {
+	s.lambda = lambda
+	return s
+}
+
+// Коэффициенты b_k = 2∫u0(x)·sin(kπx)dx, k = 1..modes, для ломаной через
+// точки (xs[j], us[j]); интеграл по каждому отрезку берётся точно
+func NewSineSeries(xs, us []float64, modes int) SineSeries {
+	b := make([]float64, modes)
+	for k := 1; k <= modes; k++ {
+		w := float64(k) * math.Pi
+		// Первообразная (p + q·x)·sin(wx): -(p + q·x)·cos(wx)/w + q·sin(wx)/w²
+		var sum float64
+		for j := 0; j+1 < len(xs); j++ {
+			x0, x1 := xs[j], xs[j+1]
+			q := (us[j+1] - us[j]) / (x1 - x0)
+			p := us[j] - q*x0
+			prim := func(x float64) float64 {
+				return -(p+q*x)*math.Cos(w*x)/w + q*math.Sin(w*x)/(w*w)
+			}
+			sum += prim(x1) - prim(x0)
+		}
+		b[k-1] = 2 * sum
+	}
+	return SineSeries{b: b}
+}
+
+// Стационарное решение −α·u″ = f с постоянной f и u(0) = left,
+// u(1) = right: прямая между краевыми значениями плюс f·x(1−x)/(2α)
+func SteadyParabola(alpha, f, left, right float64) func(x float64) float64 {
+	return func(x float64) float64 {
+		return left + (right-left)*x + f*x*(1-x)/(2*alpha)
+	}
+}
+
+// Ряд для ступеньки: 1 на (a, b), 0 вне; b_k = 2(cos kπa − cos kπb)/(kπ)
+func NewTopHatSeries(a, b float64, modes int) SineSeries {
+	c := make([]float64, modes)
+	for k := 1; k <= modes; k++ {
+		w := float64(k) * math.Pi
+		c[k-1] = 2 * (math.Cos(w*a) - math.Cos(w*b)) / w
+	}
+	return SineSeries{b: c}
+}
+
+// Значение ряда; члены, погашенные множителем exp(-k²π²t), отбрасываются
+func (s SineSeries) Eval(x, t float64) float64 {
+	var u float64
+	for k := 1; k <= len(s.b); k++ {
+		w := float64(k) * math.Pi
+		decay := math.Exp(-w * w * t)
+		if decay < 1e-18 {
+			break
+		}
+		u += s.b[k-1] * decay * math.Sin(w*x)
+	}
+	return u * math.Exp(-s.lambda*t)
+}
+
+// ∫₀¹ sin(kπx) dx = 2/(kπ) для нечётных k и 0 для чётных
+func (s SineSeries) Heat(t float64) float64 {
+	var q float64
+	for k := 1; k <= len(s.b); k += 2 {
+		w := float64(k) * math.Pi
+		decay := math.Exp(-w * w * t)
+		if decay < 1e-18 {
+			break
+		}
+		q += s.b[k-1] * decay * 2 / w
+	}
+	return q * math.Exp(-s.lambda*t)
+}
+
+// Значений sin(kπx_i), которые SineSeries.Grid хранит для всей сетки
+const seriesTableSize = 1 << 20
+
+// exp(-k²π²t) считается один раз на моду и слой. sin(kπx_i) хранится для
+// первых мод, пока таблица не больше seriesTableSize значений; для
+// остальных считается на месте
+func (s SineSeries) Grid(nx int, dx float64) GridFunc {
+	cached := min(len(s.b), seriesTableSize/(nx+1))
+	table := make([]float64, cached*(nx+1))
+	for i := 0; i <= nx; i++ {
+		x := float64(i) * dx
+		for k := 1; k <= cached; k++ {
+			table[i*cached+k-1] = math.Sin(float64(k) * math.Pi * x)
+		}
+	}
+	decay := make([]float64, len(s.b))
+	return func(dst []float64, t float64) {
+		modes := 0
+		damp := math.Exp(-s.lambda * t)
+		for k := 1; k <= len(s.b); k++ {
+			w := float64(k) * math.Pi
+			d := math.Exp(-w * w * t)
+			if d < 1e-18 {
+				break
+			}
+			decay[k-1] = d
+			modes = k
+		}
+		for i := range dst {
+			x := float64(i) * dx
+			sines := table[i*cached : (i+1)*cached]
+			var u float64
+			for k := 1; k <= modes; k++ {
+				var sin float64
+				if k <= cached {
+					sin = sines[k-1]
+				} else {
+					sin = math.Sin(float64(k) * math.Pi * x)
+				}
+				u += s.b[k-1] * decay[k-1] * sin
+			}
+			dst[i] = u * damp
+		}
+	}
+}
+
+// Билинейная интерполяция решения u[n][i] на сетке x_i = i·dx, t_n = n·dt.
+// Точки вне сетки берутся с ближайшего края
+func Bilinear(u [][]float64, dx, dt, x, t float64) float64 {
+	nt, nx := len(u)-1, len(u[0])-1
+	i, fx := cell(x/dx, nx)
+	n, ft := cell(t/dt, nt)
+	at := func(n int) float64 {
+		if fx == 0 {
+			return u[n][i]
+		}
+		return u[n][i]*(1-fx) + u[n][i+1]*fx
+	}
+	if ft == 0 {
+		return at(n)
+	}
+	return at(n)*(1-ft) + at(n+1)*ft
+}
+
+// Номер ячейки и доля внутри неё для координаты s в узлах 0..m
+func cell(s float64, m int) (int, float64) {
+	if m == 0 || s <= 0 {
+		return 0, 0
+	}
+	if s >= float64(m) {
+		return m, 0
+	}
+	k := int(s)
+	return k, s - float64(k)
+}
+
+// Минимум f на [lo, hi], lo > 0, с относительной точностью tol: перебор
+// по логарифмической сетке, затем золотое сечение вокруг лучшего узла.
+// Достаточно, чтобы f была унимодальной вблизи минимума
+func MinimizeLog(f func(float64) float64, lo, hi, tol float64) float64 {
+	const scan = 40
+	a, b := math.Log(lo), math.Log(hi)
+	g := func(s float64) float64 { return f(math.Exp(s)) }
+
+	best, bestVal := 0, math.Inf(1)
+	for k := 0; k <= scan; k++ {
+		if v := g(a + (b-a)*float64(k)/scan); v < bestVal {
+			best, bestVal = k, v
+		}
+	}
+	step := (b - a) / scan
+	a, b = max(a, a+step*float64(best-1)), min(b, a+step*float64(best+1))
+
+	const invPhi = 0.6180339887498949
+	c, d := b-invPhi*(b-a), a+invPhi*(b-a)
+	gc, gd := g(c), g(d)
+	for b-a > tol {
+		if gc <= gd {
+			b, d, gd = d, c, gc
+			c = b - invPhi*(b-a)
+			gc = g(c)
+		} else {
+			a, c, gc = c, d, gd
+			d = a + invPhi*(b-a)
+			gd = g(d)
+		}
+	}
+	return math.Exp((a + b) / 2)
+}
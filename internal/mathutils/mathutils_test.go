@@ -0,0 +1,194 @@
+package mathutils
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+// Нормы ошибки слоя против exp(−π²t)·sin(πx): точный слой, сдвиг на
+// константу и разошедшийся расчёт — NaN или Inf в одном узле должны
+// попасть в обе нормы
+func TestLevelErrors(t *testing.T) {
+	const nx, tm = 10, 0.1
+	dx := 1.0 / nx
+	ref := Analytical{Alpha: 1}
+	exact := make([]float64, nx+1)
+	ref.Grid(nx, dx)(exact, tm)
+	with := func(f func(row []float64)) []float64 {
+		row := append([]float64(nil), exact...)
+		f(row)
+		return row
+	}
+	tests := []struct {
+		name     string
+		row      []float64
+		l2, linf float64
+	}{
+		{"exact", exact, 0, 0},
+		{"shifted by 0.5", with(func(r []float64) {
+			for i := range r {
+				r[i] += 0.5
+			}
+		}), 0.5, 0.5},
+		{"NaN in the middle", with(func(r []float64) { r[nx/2] = math.NaN() }), math.NaN(), math.NaN()},
+		{"NaN at the start", with(func(r []float64) { r[0] = math.NaN() }), math.NaN(), math.NaN()},
+		{"Inf", with(func(r []float64) { r[3] = math.Inf(1) }), math.Inf(1), math.Inf(1)},
+	}
+	same := func(got, want float64) bool {
+		if math.IsNaN(want) {
+			return math.IsNaN(got)
+		}
+		return math.Abs(got-want) <= 1e-12*max(1, math.Abs(want)) || got == want
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			l2, linf := LevelErrors(tc.row, dx, tm, ref)
+			if !same(l2, tc.l2) || !same(linf, tc.linf) {
+				t.Errorf("LevelErrors = (%g, %g), want (%g, %g)", l2, linf, tc.l2, tc.linf)
+			}
+		})
+	}
+}
+
+// Эталоны для проверок Grid против Eval: множитель по t считается один раз
+// на слой, по x — один раз на сетку
+func separableReferences() []struct {
+	name string
+	ref  Reference
+} {
+	samples := NewSineSeries([]float64{0, 0.2, 0.3, 0.7, 1}, []float64{0, 0.5, 1, 0.1, 0}, 64)
+	return []struct {
+		name string
+		ref  Reference
+	}{
+		{"analytical", Analytical{Alpha: 1}},
+		{"analytical alpha=0.3", Analytical{Alpha: 0.3}},
+		{"analytical with reaction", Analytical{Alpha: 1, Lambda: 2}},
+		{"samples series", samples},
+		{"samples series with reaction", samples.WithReaction(3)},
+		{"top hat series", NewTopHatSeries(0.25, 0.75, 1024)},
+		{"Bessel mode", BesselMode{Alpha: 0.7}},
+		{"sphere mode", SphereMode{Alpha: 0.7}},
+	}
+}
+
+// Grid побитно совпадает с Eval в узлах, в том числе когда таблица
+// синусов ряда вмещает не все моды (nx = 2000 при 1024 модах)
+func TestGridMatchesEval(t *testing.T) {
+	for _, tc := range separableReferences() {
+		for _, nx := range []int{1, 10, 2000} {
+			t.Run(fmt.Sprintf("%s/nx=%d", tc.name, nx), func(t *testing.T) {
+				dx := 1.0 / float64(nx)
+				grid := tc.ref.Grid(nx, dx)
+				dst := make([]float64, nx+1)
+				for _, tm := range []float64{0, 1e-6, 0.003, 0.1, 2} {
+					grid(dst, tm)
+					for i, v := range dst {
+						if want := tc.ref.Eval(float64(i)*dx, tm); v != want {
+							t.Fatalf("t=%g: Grid[%d] = %v, Eval = %v", tm, i, v, want)
+						}
+					}
+				}
+			})
+		}
+	}
+}
+
+// Слой эталона при nx = 10⁴: Eval в каждом узле против Grid
+func BenchmarkReferenceLevel(b *testing.B) {
+	const nx = 10000
+	dx := 1.0 / nx
+	dst := make([]float64, nx+1)
+	for _, tc := range separableReferences() {
+		b.Run(tc.name+"/Eval", func(b *testing.B) {
+			tm := 0.0
+			for b.Loop() {
+				tm += 1e-5
+				for i := range dst {
+					dst[i] = tc.ref.Eval(float64(i)*dx, tm)
+				}
+			}
+		})
+		b.Run(tc.name+"/Grid", func(b *testing.B) {
+			grid := tc.ref.Grid(nx, dx)
+			tm := 0.0
+			for b.Loop() {
+				tm += 1e-5
+				grid(dst, tm)
+			}
+		})
+	}
+}
+
+// Формула трапеций точна для линейных профилей; короткие слои дают 0
+func TestHeatContent(t *testing.T) {
+	tests := []struct {
+		name string
+		row  []float64
+		dx   float64
+		want float64
+	}{
+		{"empty", nil, 0.1, 0},
+		{"single node", []float64{5}, 0.1, 0},
+		{"constant", []float64{2, 2, 2, 2, 2}, 0.25, 2},
+		{"linear", []float64{0, 0.25, 0.5, 0.75, 1}, 0.25, 0.5},
+		{"halved ends", []float64{1, 0, 0, 3}, 1, 2},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := HeatContent(tc.row, tc.dx); math.Abs(got-tc.want) > 1e-15 {
+				t.Errorf("HeatContent = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+// Heat эталона совпадает с интегралом его же слоя по мелкой сетке: ошибка
+// трапеций при nx = 2·10⁴ — порядка 10⁻⁹ от Q
+func TestHeatMatchesGrid(t *testing.T) {
+	const nx = 20000
+	dx := 1.0 / nx
+	row := make([]float64, nx+1)
+	for _, r := range separableReferences() {
+		grid := r.ref.Grid(nx, dx)
+		for _, tm := range []float64{0.01, 0.1, 0.5} {
+			grid(row, tm)
+			q, want := HeatContent(row, dx), r.ref.Heat(tm)
+			if math.Abs(q-want) > 1e-8*math.Abs(want) {
+				t.Errorf("%s at t=%g: trapezoid Q %.15g, Heat %.15g", r.name, tm, q, want)
+			}
+		}
+	}
+}
+
+func TestHeatBalance(t *testing.T) {
+	nan := math.NaN()
+	tests := []struct {
+		name      string
+		q, exact  []float64
+		drift     float64
+		maxError  float64
+		initial   float64
+		finalHeat float64
+	}{
+		{"conserved", []float64{2, 2, 2}, []float64{nan, nan, nan}, 0, 0, 2, 2},
+		{"decay", []float64{1, 0.8, 0.5}, []float64{nan, nan, nan}, 0.5, 0, 1, 0.5},
+		{"drift both ways", []float64{1, 1.1, 0.95}, []float64{nan, nan, nan}, 0.1, 0, 1, 0.95},
+		{"against exact", []float64{1, 0.9}, []float64{1, 0.8}, 0.1, 0.125, 1, 0.9},
+		{"zero initial heat", []float64{0, 1}, []float64{0, 1}, 0, 0, 0, 1},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var b HeatBalance
+			for n := range tc.q {
+				b.Add(tc.q[n], tc.exact[n])
+			}
+			if b.Levels != len(tc.q) || b.Initial != tc.initial || b.Final != tc.finalHeat ||
+				math.Abs(b.MaxDrift-tc.drift) > 1e-15 || math.Abs(b.MaxError-tc.maxError) > 1e-15 {
+				t.Errorf("balance %+v, want drift %v, error %v, Q %v → %v over %d levels",
+					b, tc.drift, tc.maxError, tc.initial, tc.finalHeat, len(tc.q))
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package mathutils
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+// E_{1/2}(−x) = exp(x²)·erfc(x) на всех трёх участках (ряд, интеграл,
+// асимптотика); при x > 25 exp(x²) переполняется, и эталон — асимптотика
+// erfc. E_{1/2}(x) = exp(x²)·erfc(−x), E_1(z) = e^z
+func TestMittagLefflerHalf(t *testing.T) {
+	for _, x := range []float64{1e-3, 0.1, 0.5, 0.999, 1.001, 2, 5, 10, 29.9, 30.1, 50, 1000} {
+		want := math.Exp(x*x) * math.Erfc(x)
+		if x > 25 {
+			// erfc(x)·exp(x²) = 1/(x√π)·Σ (−1)^k (2k−1)!!/(2x²)^k
+			sum, term := 1.0, 1.0
+			for k := 1; k < 10; k++ {
+				term *= -float64(2*k-1) / (2 * x * x)
+				sum += term
+			}
+			want = sum / (x * math.Sqrt(math.Pi))
+		}
+		if got := MittagLeffler(0.5, -x); math.Abs(got-want) > 1e-10*want {
+			t.Errorf("E_½(−%g) = %.15g, want %.15g", x, got, want)
+		}
+	}
+	for _, x := range []float64{0.5, 2} {
+		if got, want := MittagLeffler(0.5, x), math.Exp(x*x)*math.Erfc(-x); math.Abs(got-want) > 1e-12*want {
+			t.Errorf("E_½(%g) = %.15g, want %.15g", x, got, want)
+		}
+	}
+	for _, z := range []float64{-40, -1, 0, 3} {
+		if got := MittagLeffler(1, z); got != math.Exp(z) {
+			t.Errorf("E_1(%g) = %v, want e^z", z, got)
+		}
+	}
+}
+
+// При z < 0 и β < 1 функция положительна и убывает, у границ участков
+// (|z| = 1 и 30) нет скачков
+func TestMittagLefflerRegions(t *testing.T) {
+	for _, beta := range []float64{0.1, 0.3, 0.7, 0.9, 0.99} {
+		t.Run(fmt.Sprintf("β=%g", beta), func(t *testing.T) {
+			prev := 1.0
+			for x := 0.05; x < 200; x *= 1.1 {
+				v := MittagLeffler(beta, -x)
+				if !(v > 0 && v < prev) {
+					t.Fatalf("E(−%g) = %v after %v", x, v, prev)
+				}
+				prev = v
+			}
+			for _, edge := range []float64{mlSeriesLimit, mlAsymptoticLimit} {
+				below, above := MittagLeffler(beta, -edge*(1-1e-12)), MittagLeffler(beta, -edge*(1+1e-12))
+				if math.Abs(below-above) > 1e-9*below {
+					t.Errorf("jump at |z| = %g: %.15g → %.15g", edge, below, above)
+				}
+			}
+		})
+	}
+}
+
+// Мода дробного уравнения: A(0) = 1, при β = 1 — exp(−απ²t)
+func TestFractionalMode(t *testing.T) {
+	if a := (FractionalMode{Alpha: 1, Beta: 0.5}).Amplitude(0); a != 1 {
+		t.Errorf("A(0) = %v", a)
+	}
+	f := FractionalMode{Alpha: 2, Beta: 1}
+	if a, want := f.Amplitude(0.1), math.Exp(-2*math.Pi*math.Pi*0.1); math.Abs(a-want) > 1e-15 || math.Abs(f.Heat(0.1)-2/math.Pi*want) > 1e-15 {
+		t.Errorf("β = 1: A = %v, heat %v; want %v", a, f.Heat(0.1), want)
+	}
+}
@@ -0,0 +1,115 @@
+package mathutils
+
+import (
+	"fmt"
+	"math"
+)
+
+// Нормировки коэффициентов синус-мод
+const (
+	// a_k из u_i = Σ a_k·sin(kπx_i)
+	ModeAmplitude = "amplitude"
+	// Коэффициенты по ортонормированному в L2(0,1) базису √2·sin(kπx): a_k/√2
+	ModeOrthonormal = "orthonormal"
+	// a_k(t)/a_k(0); NaN для мод, которых нет в начальном профиле (|a_k(0)|
+	// на уровне округления от наибольшей амплитуды)
+	ModeRelative = "relative"
+)
+
+func CheckModeNorm(norm string) error {
+	switch norm {
+	case ModeAmplitude, ModeOrthonormal, ModeRelative:
+		return nil
+	}
+	return fmt.Errorf("unknown mode normalization %q, want %s, %s or %s", norm, ModeAmplitude, ModeOrthonormal, ModeRelative)
+}
+
+// Дискретное синус-преобразование (DST-I) слоя на сетке x_i = i/nx:
+// a_k = (2/nx)·Σ u_i·sin(kπi/nx) по внутренним узлам, k = 1..modes.
+// Для сеточной функции с нулевыми краями разложение u_i = Σ a_k·sin(kπx_i)
+// по nx−1 модам точное. sin(kπx_i) — собственный вектор шага FTCS, BTCS и
+// CN, поэтому у расчёта a_k(t_n) = G(kπ·dx)ⁿ·a_k(0), а у задачи —
+// exp(−k²π²t)·a_k(0)
+type SineTransform struct {
+	nx, modes int
+	// sin(πj/nx), j = 0..2nx−1: sin(kπi/nx) берётся по индексу k·i mod 2nx
+	sines []float64
+}
+
+// modes от 1 до nx−1
+func NewSineTransform(nx, modes int) (*SineTransform, error) {
+	if modes < 1 || modes > nx-1 {
+		return nil, fmt.Errorf("modes = %d out of range, the grid with nx = %d resolves 1..%d", modes, nx, nx-1)
+	}
+	sines := make([]float64, 2*nx)
+	for j := range sines {
+		sines[j] = math.Sin(math.Pi * float64(j) / float64(nx))
+	}
+	return &SineTransform{nx: nx, modes: modes, sines: sines}, nil
+}
+
+func (s *SineTransform) Modes() int {
+	return s.modes
+}
+
+// Амплитуды a_1..a_modes слоя row (nx+1 значений) в dst
+func (s *SineTransform) Transform(dst, row []float64) {
+	period := 2 * s.nx
+	for k := 1; k <= s.modes; k++ {
+		var sum float64
+		j := 0
+		for _, v := range row[1:s.nx] {
+			j += k
+			if j >= period {
+				j -= period
+			}
+			sum += v * s.sines[j]
+		}
+		dst[k-1] = 2 * sum / float64(s.nx)
+	}
+}
+
+// Слой row (nx+1 значений) из амплитуд a_1..a_modes: row_i = Σ a_k·sin(kπi/nx),
+// края нулевые. При modes = nx−1 обращает Transform
+func (s *SineTransform) Inverse(row, a []float64) {
+	period := 2 * s.nx
+	row[0], row[s.nx] = 0, 0
+	for i := 1; i < s.nx; i++ {
+		var sum float64
+		j := 0
+		for _, ak := range a[:s.modes] {
+			j += i
+			if j >= period {
+				j -= period
+			}
+			sum += ak * s.sines[j]
+		}
+		row[i] = sum
+	}
+}
+
+// Доля наибольшей амплитуды, ниже которой мода считается отсутствующей
+const modeNegligible = 1e-12
+
+// Перевод амплитуд a в нормировку norm на месте; a0 — амплитуды
+// начального слоя (нужны только для ModeRelative)
+func NormalizeModes(a, a0 []float64, norm string) {
+	switch norm {
+	case ModeOrthonormal:
+		for k := range a {
+			a[k] /= math.Sqrt2
+		}
+	case ModeRelative:
+		var scale float64
+		for _, v := range a0 {
+			scale = max(scale, math.Abs(v))
+		}
+		for k := range a {
+			if math.Abs(a0[k]) <= modeNegligible*scale {
+				a[k] = math.NaN()
+				continue
+			}
+			a[k] /= a0[k]
+		}
+	}
+}
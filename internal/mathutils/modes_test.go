@@ -0,0 +1,83 @@
+package mathutils
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// Чистая мода sin(kπx) даёт единственную ненулевую амплитуду, а Inverse
+// по всем nx−1 модам восстанавливает произвольный слой с нулевыми краями
+func TestSineTransform(t *testing.T) {
+	for _, nx := range []int{2, 7, 40} {
+		s, err := NewSineTransform(nx, nx-1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		row, a := make([]float64, nx+1), make([]float64, nx-1)
+		for k := 1; k < nx; k++ {
+			for i := range row {
+				row[i] = math.Sin(float64(k) * math.Pi * float64(i) / float64(nx))
+			}
+			s.Transform(a, row)
+			for j, v := range a {
+				want := 0.0
+				if j == k-1 {
+					want = 1
+				}
+				if math.Abs(v-want) > 1e-13 {
+					t.Errorf("nx=%d, mode %d: a_%d = %v, want %v", nx, k, j+1, v, want)
+				}
+			}
+		}
+
+		rng := rand.New(rand.NewSource(int64(nx)))
+		for i := 1; i < nx; i++ {
+			row[i] = rng.NormFloat64()
+		}
+		row[0], row[nx] = 0, 0
+		back := make([]float64, nx+1)
+		s.Transform(a, row)
+		s.Inverse(back, a)
+		for i := range row {
+			if math.Abs(back[i]-row[i]) > 1e-13 {
+				t.Errorf("nx=%d: node %d restored as %v, want %v", nx, i, back[i], row[i])
+			}
+		}
+	}
+
+	for _, modes := range []int{0, 10} {
+		if _, err := NewSineTransform(10, modes); err == nil {
+			t.Errorf("%d modes accepted on nx = 10", modes)
+		}
+	}
+}
+
+func TestNormalizeModes(t *testing.T) {
+	nan := math.NaN()
+	tests := []struct {
+		norm  string
+		a, a0 []float64
+		want  []float64
+	}{
+		{ModeAmplitude, []float64{2, -1, 0}, []float64{4, 2, 0}, []float64{2, -1, 0}},
+		{ModeOrthonormal, []float64{math.Sqrt2, -2}, nil, []float64{1, -math.Sqrt2}},
+		{ModeRelative, []float64{2, -1, 0}, []float64{4, 2, 0}, []float64{0.5, -0.5, nan}},
+		// Амплитуда на уровне округления от наибольшей — моды нет
+		{ModeRelative, []float64{0.5, 1e-20}, []float64{1, 1e-13}, []float64{0.5, nan}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.norm, func(t *testing.T) {
+			NormalizeModes(tc.a, tc.a0, tc.norm)
+			for k, v := range tc.a {
+				if !(math.Abs(v-tc.want[k]) <= 1e-15 || math.IsNaN(v) && math.IsNaN(tc.want[k])) {
+					t.Errorf("a = %v, want %v", tc.a, tc.want)
+					break
+				}
+			}
+		})
+	}
+	if err := CheckModeNorm("energy"); err == nil {
+		t.Error("unknown normalization accepted")
+	}
+}
@@ -0,0 +1,166 @@
+package mathutils
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Символы, которых не может быть в именах узлов и стержней: имена идут в
+// заголовки CSV и в имена файлов
+const networkNameForbidden = ",/\\\"\n\r"
+
+// Узел сети стержней: стык (общий для нескольких стержней), конец
+// стержня или граница с заданной температурой
+type NetworkNode struct {
+	Name string `json:"name"`
+	// Заданная температура (граница Дирихле); nil — свободный узел. В
+	// свободном узле температура общая для всех стержней, а сумма потоков
+	// в него равна нулю (условие Кирхгофа); свободный конец одного
+	// стержня поэтому теплоизолирован
+	Temperature *float64 `json:"temperature,omitempty"`
+}
+
+// Стержень сети между узлами From и To; координата на нём — от From.
+// Сечение и объёмная теплоёмкость у всех стержней единичные, так что α —
+// и температуропроводность, и теплопроводность
+type Segment struct {
+	Name   string  `json:"name"`
+	From   string  `json:"from"`
+	To     string  `json:"to"`
+	Length float64 `json:"length"`
+	Nx     int     `json:"nx"`
+	Alpha  float64 `json:"alpha"`
+}
+
+// Сеть одномерных стержней, связанных в узлах, например Т-образный стык
+// трёх стержней в одном узле. Файл топологии — JSON
+// {"nodes": [{"name": "J"}, {"name": "A", "temperature": 1}, …],
+// "segments": [{"name": "left", "from": "A", "to": "J", "length": 0.5,
+// "nx": 10, "alpha": 1}, …]}
+type Network struct {
+	Nodes    []NetworkNode `json:"nodes"`
+	Segments []Segment     `json:"segments"`
+}
+
+// Имена узлов и стержней непусты, уникальны и без networkNameForbidden, концы стержней — разные
+// существующие узлы, каждый узел — конец хотя бы одного стержня; длины и
+// α положительны и конечны, nx ≥ 1, температуры конечны
+func (n Network) Validate() error {
+	if len(n.Segments) == 0 {
+		return fmt.Errorf("network has no segments")
+	}
+	nodes := make(map[string]bool, len(n.Nodes))
+	for i, node := range n.Nodes {
+		switch {
+		case node.Name == "":
+			return fmt.Errorf("node %d: empty name", i+1)
+		case strings.ContainsAny(node.Name, networkNameForbidden):
+			return fmt.Errorf("node %q: name must not contain commas, slashes, quotes or line breaks", node.Name)
+		case nodes[node.Name]:
+			return fmt.Errorf("node %q: duplicate name", node.Name)
+		case node.Temperature != nil && (math.IsNaN(*node.Temperature) || math.IsInf(*node.Temperature, 0)):
+			return fmt.Errorf("node %q: temperature must be finite", node.Name)
+		}
+		nodes[node.Name] = false
+	}
+	positive := func(v float64) bool { return v > 0 && !math.IsInf(v, 0) }
+	names := make(map[string]bool, len(n.Segments))
+	for i, s := range n.Segments {
+		_, from := nodes[s.From]
+		_, to := nodes[s.To]
+		switch {
+		case s.Name == "":
+			return fmt.Errorf("segment %d: empty name", i+1)
+		case strings.ContainsAny(s.Name, networkNameForbidden):
+			return fmt.Errorf("segment %q: name must not contain commas, slashes, quotes or line breaks", s.Name)
+		case names[s.Name]:
+			return fmt.Errorf("segment %q: duplicate name", s.Name)
+		case !from:
+			return fmt.Errorf("segment %q: unknown node %q", s.Name, s.From)
+		case !to:
+			return fmt.Errorf("segment %q: unknown node %q", s.Name, s.To)
+		case s.From == s.To:
+			return fmt.Errorf("segment %q: both ends at node %q", s.Name, s.From)
+		case !positive(s.Length):
+			return fmt.Errorf("segment %q: length must be positive and finite, got %g", s.Name, s.Length)
+		case s.Nx < 1:
+			return fmt.Errorf("segment %q: nx must be at least 1, got %d", s.Name, s.Nx)
+		case !positive(s.Alpha):
+			return fmt.Errorf("segment %q: alpha must be positive and finite, got %g", s.Name, s.Alpha)
+		}
+		names[s.Name] = true
+		nodes[s.From], nodes[s.To] = true, true
+	}
+	for _, node := range n.Nodes {
+		if !nodes[node.Name] {
+			return fmt.Errorf("node %q: no segment ends there", node.Name)
+		}
+	}
+	return nil
+}
+
+// Номер узла по имени; −1, если такого нет
+func (n Network) NodeIndex(name string) int {
+	for i, node := range n.Nodes {
+		if node.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// Стационарные температуры узлов: на каждом стержне профиль линейный, и в
+// свободных узлах Σ α_s/L_s·(T_s − T) = 0 по стержням узла (T_s — другой
+// конец стержня). Ошибка, если у связной части сети нет ни одного узла с
+// заданной температурой
+func (n Network) Steady() ([]float64, error) {
+	temps := make([]float64, len(n.Nodes))
+	free := make([]int, len(n.Nodes))
+	var k int
+	for i, node := range n.Nodes {
+		free[i] = -1
+		if node.Temperature != nil {
+			temps[i] = *node.Temperature
+		} else {
+			free[i] = k
+			k++
+		}
+	}
+	if k == 0 {
+		return temps, nil
+	}
+	a := make([][]float64, k)
+	for i := range a {
+		a[i] = make([]float64, k)
+	}
+	b := make([]float64, k)
+	for _, s := range n.Segments {
+		g := s.Alpha / s.Length
+		ends := [2]int{n.NodeIndex(s.From), n.NodeIndex(s.To)}
+		for e, node := range ends {
+			row := free[node]
+			if row < 0 {
+				continue
+			}
+			a[row][row] += g
+			if col := free[ends[1-e]]; col >= 0 {
+				a[row][col] -= g
+			} else {
+				b[row] += g * temps[ends[1-e]]
+			}
+		}
+	}
+	lu, err := NewDenseLU(a)
+	if err != nil {
+		return nil, fmt.Errorf("no steady state: part of the network has no node with a fixed temperature")
+	}
+	x := make([]float64, k)
+	lu.Solve(b, x)
+	for i, row := range free {
+		if row >= 0 {
+			temps[i] = x[row]
+		}
+	}
+	return temps, nil
+}
@@ -0,0 +1,162 @@
+package mathutils
+
+import (
+	"math"
+	"math/rand/v2"
+	"testing"
+)
+
+func networkTemp(t float64) *float64 {
+	return &t
+}
+
+func TestNetworkValidate(t *testing.T) {
+	valid := func() Network {
+		return Network{
+			Nodes: []NetworkNode{{Name: "A", Temperature: networkTemp(1)}, {Name: "J"}, {Name: "B", Temperature: networkTemp(0)}},
+			Segments: []Segment{
+				{Name: "left", From: "A", To: "J", Length: 0.5, Nx: 10, Alpha: 1},
+				{Name: "right", From: "J", To: "B", Length: 0.5, Nx: 1, Alpha: 2},
+			},
+		}
+	}
+	if err := valid().Validate(); err != nil {
+		t.Fatalf("valid network rejected: %v", err)
+	}
+	tests := []struct {
+		name   string
+		modify func(n *Network)
+	}{
+		{"no segments", func(n *Network) { n.Segments = nil }},
+		{"empty node name", func(n *Network) { n.Nodes[1].Name = "" }},
+		{"comma in node name", func(n *Network) { n.Nodes = append(n.Nodes, NetworkNode{Name: "a,b"}) }},
+		{"slash in segment name", func(n *Network) { n.Segments[0].Name = "l/r" }},
+		{"quote in segment name", func(n *Network) { n.Segments[0].Name = `l"` }},
+		{"duplicate node", func(n *Network) { n.Nodes[2].Name = "A" }},
+		{"infinite temperature", func(n *Network) { n.Nodes[0].Temperature = networkTemp(math.Inf(1)) }},
+		{"NaN temperature", func(n *Network) { n.Nodes[0].Temperature = networkTemp(math.NaN()) }},
+		{"empty segment name", func(n *Network) { n.Segments[1].Name = "" }},
+		{"duplicate segment", func(n *Network) { n.Segments[1].Name = "left" }},
+		{"unknown from", func(n *Network) { n.Segments[0].From = "X" }},
+		{"unknown to", func(n *Network) { n.Segments[1].To = "X" }},
+		{"loop", func(n *Network) { n.Segments[1].From = "B" }},
+		{"zero length", func(n *Network) { n.Segments[0].Length = 0 }},
+		{"infinite length", func(n *Network) { n.Segments[0].Length = math.Inf(1) }},
+		{"zero nx", func(n *Network) { n.Segments[0].Nx = 0 }},
+		{"negative alpha", func(n *Network) { n.Segments[1].Alpha = -1 }},
+		{"NaN alpha", func(n *Network) { n.Segments[1].Alpha = math.NaN() }},
+		{"isolated node", func(n *Network) { n.Nodes = append(n.Nodes, NetworkNode{Name: "C"}) }},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			n := valid()
+			tc.modify(&n)
+			if err := n.Validate(); err == nil {
+				t.Errorf("%+v accepted", n)
+			}
+		})
+	}
+}
+
+// Т-образный стык: температура свободного узла — среднее температур
+// концов с весами α/L; цепочка из свободных узлов — линейный профиль;
+// часть сети без заданной температуры — ошибка
+func TestNetworkSteady(t *testing.T) {
+	tee := Network{
+		Nodes: []NetworkNode{{Name: "J"}, {Name: "A", Temperature: networkTemp(1)}, {Name: "B", Temperature: networkTemp(0)}, {Name: "C", Temperature: networkTemp(0.5)}},
+		Segments: []Segment{
+			{Name: "a", From: "A", To: "J", Length: 1, Nx: 20, Alpha: 1},
+			{Name: "b", From: "J", To: "B", Length: 0.5, Nx: 10, Alpha: 0.2},
+			{Name: "c", From: "J", To: "C", Length: 0.8, Nx: 8, Alpha: 3},
+		},
+	}
+	temps, err := tee.Steady()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ga, gb, gc := 1/1.0, 0.2/0.5, 3/0.8
+	if want := (ga*1 + gb*0 + gc*0.5) / (ga + gb + gc); math.Abs(temps[0]-want) > 1e-15 {
+		t.Errorf("junction %v, want %v", temps[0], want)
+	}
+	if temps[1] != 1 || temps[2] != 0 || temps[3] != 0.5 {
+		t.Errorf("fixed temperatures changed: %v", temps)
+	}
+
+	chain := Network{
+		Nodes: []NetworkNode{{Name: "A", Temperature: networkTemp(2)}, {Name: "P"}, {Name: "Q"}, {Name: "B", Temperature: networkTemp(-1)}},
+		Segments: []Segment{
+			{Name: "1", From: "A", To: "P", Length: 0.2, Nx: 2, Alpha: 1},
+			{Name: "2", From: "Q", To: "P", Length: 0.3, Nx: 3, Alpha: 1},
+			{Name: "3", From: "Q", To: "B", Length: 0.5, Nx: 5, Alpha: 1},
+		},
+	}
+	temps, err = chain.Steady()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(temps[1]-(2-3*0.2)) > 1e-14 || math.Abs(temps[2]-(2-3*0.5)) > 1e-14 {
+		t.Errorf("chain %v, want a linear profile", temps)
+	}
+
+	floating := tee
+	floating.Nodes = append(floating.Nodes, NetworkNode{Name: "X"}, NetworkNode{Name: "Y"})
+	floating.Segments = append(floating.Segments, Segment{Name: "d", From: "X", To: "Y", Length: 1, Nx: 4, Alpha: 1})
+	if _, err := floating.Steady(); err == nil {
+		t.Error("floating part accepted")
+	}
+	if i := tee.NodeIndex("C"); i != 3 {
+		t.Errorf("node C at %d", i)
+	}
+	if i := tee.NodeIndex("X"); i != -1 {
+		t.Errorf("unknown node at %d", i)
+	}
+}
+
+// Случайные системы, в том числе с нулём на диагонали (нужен выбор
+// ведущего элемента): невязка решения на уровне округления; вырожденная и
+// неквадратная матрицы отклоняются, исходная матрица не меняется
+func TestDenseLU(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 2))
+	for _, n := range []int{1, 2, 5, 12} {
+		a := make([][]float64, n)
+		for i := range a {
+			a[i] = make([]float64, n)
+			for j := range a[i] {
+				a[i][j] = rng.Float64()*2 - 1
+			}
+		}
+		a[0][0] = 0
+		if n == 1 {
+			a[0][0] = 3
+		}
+		orig := a[0][0]
+		b := make([]float64, n)
+		for i := range b {
+			b[i] = rng.Float64()
+		}
+		lu, err := NewDenseLU(a)
+		if err != nil {
+			t.Fatalf("n=%d: %v", n, err)
+		}
+		if a[0][0] != orig {
+			t.Errorf("n=%d: input matrix modified", n)
+		}
+		x := make([]float64, n)
+		lu.Solve(b, x)
+		for i := range a {
+			s := -b[i]
+			for j := range a[i] {
+				s += a[i][j] * x[j]
+			}
+			if math.Abs(s) > 1e-12 {
+				t.Errorf("n=%d: residual %g in row %d", n, s, i)
+			}
+		}
+	}
+	if _, err := NewDenseLU([][]float64{{1, 2}, {2, 4}}); err == nil {
+		t.Error("singular matrix accepted")
+	}
+	if _, err := NewDenseLU([][]float64{{1, 2}, {2}}); err == nil {
+		t.Error("ragged matrix accepted")
+	}
+}
@@ -0,0 +1,55 @@
+package mathutils
+
+import "math"
+
+// Равномерные узлы x_i = i·dx, i = 0..nx, dx = 1/nx
+func UniformNodes(nx int) []float64 {
+	dx := 1 / float64(nx)
+	x := make([]float64, nx+1)
+	for i := range x {
+		x[i] = float64(i) * dx
+	}
+	x[nx] = 1
+	return x
+}
+
+// Узлы на [0, 1], сгущённые к обоим краям растяжением tanh:
+// x_i = ½·(1 + tanh(β·(2ξ_i − 1))/tanh β), ξ_i = i/nx. β = factor; при
+// β = 0 — равномерные узлы. При β = 2 и большом nx крайний шаг — около
+// 0.15 среднего, шаг в середине — около 2.07 среднего. Отображение
+// гладкое, так что соседние шаги отличаются на O(1/nx²) и трёхточечная
+// разность остаётся второго порядка
+func StretchedNodes(nx int, factor float64) []float64 {
+	if factor == 0 {
+		return UniformNodes(nx)
+	}
+	x := make([]float64, nx+1)
+	t := math.Tanh(factor)
+	for i := range x {
+		xi := float64(i) / float64(nx)
+		x[i] = 0.5 * (1 + math.Tanh(factor*(2*xi-1))/t)
+	}
+	x[0], x[nx] = 0, 1
+	return x
+}
+
+// Эталон на произвольных узлах x: как Grid, но через Eval в каждом узле
+func NodesGrid(exact Reference, x []float64) GridFunc {
+	return func(dst []float64, t float64) {
+		for i, xi := range x[:len(dst)] {
+			dst[i] = exact.Eval(xi, t)
+		}
+	}
+}
+
+// Нормы ошибки L2 (среднеквадратичная по узлам, как LevelErrors) и L∞
+// слоя row на узлах x в момент t
+func NodeErrors(row, x []float64, t float64, exact Reference) (float64, float64) {
+	var sumSq, linf float64
+	for i, v := range row {
+		err := math.Abs(v - exact.Eval(x[i], t))
+		sumSq += err * err
+		linf = max(linf, err)
+	}
+	return math.Sqrt(sumSq / float64(len(row))), linf
+}
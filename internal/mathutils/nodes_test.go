@@ -0,0 +1,72 @@
+package mathutils
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+// Узлы растут от 0 до 1 и симметричны относительно ½; β = 0 — равномерная
+// сетка; при β = 2 крайний шаг около 0.15 среднего, средний — около 2.07
+func TestStretchedNodes(t *testing.T) {
+	tests := []struct {
+		nx           int
+		factor       float64
+		edge, middle float64 // шаги у края и в середине в долях 1/nx; 0 — не проверяется
+	}{
+		{10, 0, 1, 1},
+		{7, 0.5, 0, 0},
+		{1000, 2, 0.149, 2.07},
+		{4000, 2, 0.149, 2.07},
+	}
+	for _, tc := range tests {
+		t.Run(fmt.Sprintf("nx=%d/β=%g", tc.nx, tc.factor), func(t *testing.T) {
+			x := StretchedNodes(tc.nx, tc.factor)
+			if len(x) != tc.nx+1 || x[0] != 0 || x[tc.nx] != 1 {
+				t.Fatalf("%d nodes from %v to %v", len(x), x[0], x[len(x)-1])
+			}
+			for i := 1; i <= tc.nx; i++ {
+				if x[i] <= x[i-1] {
+					t.Fatalf("x[%d] = %v after %v", i, x[i], x[i-1])
+				}
+				if d := math.Abs(x[i] + x[tc.nx-i] - 1); d > 1e-15 {
+					t.Fatalf("x[%d] + x[%d] differs from 1 by %.2g", i, tc.nx-i, d)
+				}
+			}
+			if tc.edge == 0 {
+				return
+			}
+			n := float64(tc.nx)
+			if edge := x[1] * n; math.Abs(edge-tc.edge) > 0.01 {
+				t.Errorf("edge step %.4f of the mean, want %.3f", edge, tc.edge)
+			}
+			if middle := (x[tc.nx/2+1] - x[tc.nx/2]) * n; math.Abs(middle-tc.middle) > 0.01 {
+				t.Errorf("middle step %.4f of the mean, want %.3f", middle, tc.middle)
+			}
+		})
+	}
+}
+
+// На равномерных узлах NodeErrors совпадает с LevelErrors, NodesGrid — с Eval
+func TestNodeErrors(t *testing.T) {
+	const nx = 50
+	exact := Analytical{Alpha: 1}
+	x := UniformNodes(nx)
+	row := make([]float64, nx+1)
+	for i := range row {
+		row[i] = math.Sin(math.Pi*x[i]) * (1 + 0.01*float64(i%3))
+	}
+	l2, linf := NodeErrors(row, x, 0.02, exact)
+	wantL2, wantLinf := LevelErrors(row, 1.0/nx, 0.02, exact)
+	if math.Abs(l2-wantL2) > 1e-15 || math.Abs(linf-wantLinf) > 1e-15 {
+		t.Errorf("NodeErrors %v, %v; LevelErrors %v, %v", l2, linf, wantL2, wantLinf)
+	}
+	dst := make([]float64, nx+1)
+	stretched := StretchedNodes(nx, 2)
+	NodesGrid(exact, stretched)(dst, 0.3)
+	for i, v := range dst {
+		if want := exact.Eval(stretched[i], 0.3); v != want {
+			t.Fatalf("NodesGrid[%d] = %v, Eval = %v", i, v, want)
+		}
+	}
+}
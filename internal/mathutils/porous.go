@@ -0,0 +1,65 @@
+package mathutils
+
+import "math"
+
+// Решение Баренблатта уравнения пористой среды u_t = (u^m)_xx, m > 1:
+// u = τ^{−a}·(C − k·ξ²)₊^{1/(m−1)}, ξ = (x − Center)·τ^{−a}, где
+// a = 1/(m+1), k = (m−1)/(2m(m+1)), а τ = T0 + t — время от точечного
+// источника. C выбрано так, что при t = 0 носитель — отрезок
+// [Center − R0, Center + R0]; дальше его полуширина растёт как
+// R0·(τ/T0)^{1/(m+1)}. Масса (интеграл u) сохраняется
+type Barenblatt struct {
+	M      float64
+	Center float64
+	T0     float64
+	R0     float64
+}
+
+func (b Barenblatt) exponent() float64 {
+	return 1 / (b.M + 1)
+}
+
+func (b Barenblatt) k() float64 {
+	return (b.M - 1) / (2 * b.M * (b.M + 1))
+}
+
+// C = k·(R0·T0^{−a})²: край носителя там, где C = k·ξ²
+func (b Barenblatt) c() float64 {
+	xi := b.R0 * math.Pow(b.T0, -b.exponent())
+	return b.k() * xi * xi
+}
+
+// Полуширина носителя в момент t: R0·((T0 + t)/T0)^{1/(m+1)}
+func (b Barenblatt) Radius(t float64) float64 {
+	return b.R0 * math.Pow((b.T0+t)/b.T0, b.exponent())
+}
+
+func (b Barenblatt) Eval(x, t float64) float64 {
+	tau := b.T0 + t
+	scale := math.Pow(tau, -b.exponent())
+	xi := (x - b.Center) * scale
+	p := b.c() - b.k()*xi*xi
+	if p <= 0 {
+		return 0
+	}
+	return scale * math.Pow(p, 1/(b.M-1))
+}
+
+func (b Barenblatt) Grid(nx int, dx float64) GridFunc {
+	return func(dst []float64, t float64) {
+		for i := range dst {
+			dst[i] = b.Eval(float64(i)*dx, t)
+		}
+	}
+}
+
+// Масса от t не зависит: ∫(C − kξ²)₊^q dξ = 2·√(C/k)·C^q·∫₀¹(1 − s²)^q ds,
+// q = 1/(m−1), а ∫₀¹(1 − s²)^q ds = (√π/2)·Γ(q+1)/Γ(q+3/2). Носитель
+// должен оставаться внутри отрезка расчёта
+func (b Barenblatt) Heat(float64) float64 {
+	q := 1 / (b.M - 1)
+	c := b.c()
+	lg1, _ := math.Lgamma(q + 1)
+	lg2, _ := math.Lgamma(q + 1.5)
+	return 2 * math.Sqrt(c/b.k()) * math.Pow(c, q) * math.Sqrt(math.Pi) / 2 * math.Exp(lg1-lg2)
+}
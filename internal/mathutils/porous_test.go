@@ -0,0 +1,56 @@
+package mathutils
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+// Решение Баренблатта: носитель — ровно [Center − Radius(t), Center +
+// Radius(t)], масса по сетке совпадает с Heat и не меняется, внутри
+// носителя u_t = (u^m)_xx с точностью разностных производных
+func TestBarenblatt(t *testing.T) {
+	for _, m := range []float64{1.5, 2, 3, 5} {
+		for _, tm := range []float64{0, 1, 7} {
+			t.Run(fmt.Sprintf("m=%g/t=%g", m, tm), func(t *testing.T) {
+				b := Barenblatt{M: m, Center: 6, T0: 0.5, R0: 1.5}
+				r := b.Radius(tm)
+				if tm == 0 && r != b.R0 {
+					t.Fatalf("radius %v at t = 0, want %v", r, b.R0)
+				}
+				for _, x := range []float64{b.Center - r, b.Center + r} {
+					inside, outside := b.Center+(x-b.Center)*(1-1e-6), b.Center+(x-b.Center)*(1+1e-9)
+					if b.Eval(inside, tm) <= 0 || b.Eval(outside, tm) != 0 {
+						t.Errorf("edge %v: u = %g inside, %g outside", x, b.Eval(inside, tm), b.Eval(outside, tm))
+					}
+				}
+
+				const nx = 200000
+				dx := 12.0 / nx
+				u := make([]float64, nx+1)
+				b.Grid(nx, dx)(u, tm)
+				var heat float64
+				for _, v := range u {
+					heat += v * dx
+				}
+				if want := b.Heat(tm); math.Abs(heat-want) > 1e-5*want {
+					t.Errorf("mass on the grid %.10g, Heat %.10g", heat, want)
+				}
+
+				const h = 1e-3
+				for _, s := range []float64{0, 0.3, 0.7} {
+					x := b.Center + s*r
+					ut := (b.Eval(x, tm+h) - b.Eval(x, tm-h)) / (2 * h)
+					if tm == 0 {
+						ut = (b.Eval(x, tm+h) - b.Eval(x, tm)) / h
+					}
+					um := func(x float64) float64 { return math.Pow(b.Eval(x, tm), m) }
+					uxx := (um(x+h) - 2*um(x) + um(x-h)) / (h * h)
+					if math.Abs(ut-uxx) > 1e-3*math.Max(1, math.Abs(uxx)) {
+						t.Errorf("x = %v: u_t = %.8g, (u^m)_xx = %.8g", x, ut, uxx)
+					}
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package mathutils
+
+import "math"
+
+// Первые n корней λ·tg λ = Bi (Bi ≥ 0): корень λ_j лежит в
+// [(j−1)π, (j−1)π + π/2) и ищется делением пополам для
+// f(λ) = λ·sin λ − Bi·cos λ — у неё, в отличие от λ·tg λ − Bi, нет
+// полюсов, а знаки на концах отрезка разные. При Bi = 0 корни jπ
+// (первый — ноль), при Bi → ∞ они стремятся к (j − ½)π — корням
+// условия Дирихле
+func BiotRoots(bi float64, n int) []float64 {
+	roots := make([]float64, n)
+	for j := range roots {
+		lo, hi := float64(j)*math.Pi, float64(j)*math.Pi+math.Pi/2
+		switch {
+		case bi == 0:
+			roots[j] = lo
+			continue
+		case math.IsInf(bi, 1):
+			roots[j] = hi
+			continue
+		}
+		f := func(l float64) float64 { return l*math.Sin(l) - bi*math.Cos(l) }
+		fLo := f(lo)
+		for range 200 {
+			mid := (lo + hi) / 2
+			if mid == lo || mid == hi {
+				break
+			}
+			if fm := f(mid); (fm < 0) == (fLo < 0) {
+				lo, fLo = mid, fm
+			} else {
+				hi = mid
+			}
+		}
+		roots[j] = (lo + hi) / 2
+	}
+	return roots
+}
+
+// Корней в ряду RobinSlab: при t ≥ 0.001 член с λ ≈ 200π меньше 1e−170
+const robinSlabTerms = 200
+
+// Остывание пластины [0, 1] с теплоизоляцией при x = 0 (середина
+// симметричной пластины толщины 2) и теплоотдачей −u_x = Bi·(u − u_amb)
+// при x = 1 из однородной температуры Initial (α = 1):
+//
+//	u = u_amb + (Initial − u_amb)·Σ C_j·exp(−λ_j²t)·cos(λ_j·x),
+//	C_j = 4·sin λ_j / (2λ_j + sin 2λ_j),
+//
+// λ_j — корни λ·tg λ = Bi (BiotRoots). При t = 0 ряд сходится медленно
+// (разрыв начала и условия при x = 1), и возвращается само Initial
+type RobinSlab struct {
+	Bi, Initial, Ambient float64
+	roots, coef          []float64
+}
+
+// Пластина с числом Био bi, началом initial и температурой среды ambient
+func NewRobinSlab(bi, initial, ambient float64) RobinSlab {
+	s := RobinSlab{Bi: bi, Initial: initial, Ambient: ambient, roots: BiotRoots(bi, robinSlabTerms)}
+	s.coef = make([]float64, len(s.roots))
+	for j, l := range s.roots {
+		if l > 0 {
+			s.coef[j] = 4 * math.Sin(l) / (2*l + math.Sin(2*l))
+		} else {
+			s.coef[j] = 1 // Bi = 0: температура не меняется
+		}
+	}
+	return s
+}
+
+// Члены ряда с exp(−λ²t) > 1e−17; остальные на результат не влияют
+func (s RobinSlab) terms(t float64) int {
+	for j, l := range s.roots {
+		if l*l*t > 40 {
+			return j
+		}
+	}
+	return len(s.roots)
+}
+
+func (s RobinSlab) Eval(x, t float64) float64 {
+	if t == 0 {
+		return s.Initial
+	}
+	var sum float64
+	for j, l := range s.roots[:s.terms(t)] {
+		sum += s.coef[j] * math.Exp(-l*l*t) * math.Cos(l*x)
+	}
+	return s.Ambient + (s.Initial-s.Ambient)*sum
+}
+
+func (s RobinSlab) Grid(nx int, dx float64) GridFunc {
+	return func(dst []float64, t float64) {
+		for i := range dst {
+			dst[i] = s.Eval(float64(i)*dx, t)
+		}
+	}
+}
+
+// ∫₀¹ u dx: ∫₀¹ cos(λx) dx = sin λ/λ
+func (s RobinSlab) Heat(t float64) float64 {
+	if t == 0 {
+		return s.Initial
+	}
+	var sum float64
+	for j, l := range s.roots[:s.terms(t)] {
+		w := 1.0
+		if l > 0 {
+			w = math.Sin(l) / l
+		}
+		sum += s.coef[j] * math.Exp(-l*l*t) * w
+	}
+	return s.Ambient + (s.Initial-s.Ambient)*sum
+}
@@ -0,0 +1,81 @@
+package mathutils
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+// Корни λ·tg λ = Bi: невязка, отрезки [(j−1)π, (j−1)π + π/2), табличные
+// первые корни и пределы Bi = 0 и Bi = ∞
+func TestBiotRoots(t *testing.T) {
+	first := map[float64]float64{1: 0.8603, 10: 1.4289, 100: 1.5552}
+	for _, bi := range []float64{0, 0.1, 1, 10, 100} {
+		roots := BiotRoots(bi, 6)
+		for j, l := range roots {
+			lo := float64(j) * math.Pi
+			if l < lo || l >= lo+math.Pi/2 {
+				t.Errorf("Bi=%g: root %d = %v outside [%v, %v)", bi, j+1, l, lo, lo+math.Pi/2)
+			}
+			if r := l*math.Sin(l) - bi*math.Cos(l); math.Abs(r) > 1e-12*max(1, bi) {
+				t.Errorf("Bi=%g: root %d = %v, residual %g", bi, j+1, l, r)
+			}
+		}
+		if want, ok := first[bi]; ok && math.Abs(roots[0]-want) > 1e-4 {
+			t.Errorf("Bi=%g: λ₁ = %.6f, table %v", bi, roots[0], want)
+		}
+	}
+	for j, l := range BiotRoots(math.Inf(1), 3) {
+		if want := (float64(j) + 0.5) * math.Pi; l != want {
+			t.Errorf("Bi=∞: root %d = %v, want %v", j+1, l, want)
+		}
+	}
+	if l := BiotRoots(1e8, 1)[0]; math.Abs(l-math.Pi/2) > 1e-7 {
+		t.Errorf("Bi=1e8: λ₁ = %v, want ≈ π/2", l)
+	}
+}
+
+// Ряд остывания пластины решает уравнение, выполняет оба краевых условия,
+// его ∫ — Heat; в начале температура — Initial, в конце — среды
+func TestRobinSlab(t *testing.T) {
+	for _, bi := range []float64{0.5, 10} {
+		t.Run(fmt.Sprintf("Bi=%g", bi), func(t *testing.T) {
+			s := NewRobinSlab(bi, 3, 1)
+			const h, tm = 1e-4, 0.2
+			for _, x := range []float64{0.2, 0.7} {
+				ut := (s.Eval(x, tm+h) - s.Eval(x, tm-h)) / (2 * h)
+				uxx := (s.Eval(x+h, tm) - 2*s.Eval(x, tm) + s.Eval(x-h, tm)) / (h * h)
+				if math.Abs(ut-uxx) > 1e-5 {
+					t.Errorf("x=%v: u_t = %v, u_xx = %v", x, ut, uxx)
+				}
+			}
+			if ux0 := (s.Eval(h, tm) - s.Eval(-h, tm)) / (2 * h); math.Abs(ux0) > 1e-8 {
+				t.Errorf("u_x(0) = %v", ux0)
+			}
+			ux1 := (s.Eval(1+h, tm) - s.Eval(1-h, tm)) / (2 * h)
+			if r := -ux1 - bi*(s.Eval(1, tm)-1); math.Abs(r) > 1e-6 {
+				t.Errorf("robin residual %v", r)
+			}
+			const n = 1000
+			sum := s.Eval(0, tm) + s.Eval(1, tm)
+			for i := 1; i < n; i++ {
+				sum += float64(2+2*(i%2)) * s.Eval(float64(i)/n, tm)
+			}
+			if heat := s.Heat(tm); math.Abs(heat-sum/(3*n)) > 1e-10 {
+				t.Errorf("heat %v, quadrature %v", heat, sum/(3*n))
+			}
+			if u := s.Eval(0.3, 0); u != 3 || s.Heat(0) != 3 {
+				t.Errorf("u(0.3, 0) = %v, heat %v", u, s.Heat(0))
+			}
+			if u := s.Eval(0.3, 1e-3); math.Abs(u-3) > 1e-9 {
+				t.Errorf("u(0.3, 1e-3) = %v, want the initial 3", u)
+			}
+			if u := s.Eval(0.5, 100); math.Abs(u-1) > 1e-9 {
+				t.Errorf("u(0.5, 100) = %v, want the ambient 1", u)
+			}
+		})
+	}
+	if u := NewRobinSlab(0, 2, 0).Eval(0.4, 1); math.Abs(u-2) > 1e-15 {
+		t.Errorf("Bi = 0: u = %v, want 2", u)
+	}
+}
@@ -0,0 +1,358 @@
+package mathutils
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Объёмный источник f(x, t) уравнения u_t = u_xx + f: предустановка или
+// выражение от x и t. Нулевое значение — источника нет
+type Source struct {
+	// Запись источника: имя предустановки или выражение как задано
+	Expr string
+	f    func(x, t float64) float64
+	// Выражение зависит от t
+	timeDependent bool
+	// Точное решение с начальным условием sin(πx), если оно известно
+	exact Reference
+}
+
+// Предустановленные источники
+const (
+	// sin(πx): из sin(πx) решение sin(πx)·(1/π² + (1 − 1/π²)·exp(−π²t))
+	// стремится к стационарному sin(πx)/π²
+	SourceSine = "sine"
+	// Импульс exp(−100(x − ½)²) в середине отрезка
+	SourceGaussian = "gaussian"
+	// sin(πx)·(π²·cos t − sin t): построенный источник, при котором из
+	// sin(πx) точное решение — cos t·sin(πx)
+	SourceManufactured = "manufactured"
+)
+
+// Источник по предустановке (sine, gaussian, manufactured) или по
+// выражению. В выражении — числа, x, t, pi, e, + − * / ^ (или **),
+// скобки и функции sin, cos, tan, exp, log, sqrt, abs, sinh, cosh, tanh.
+// Пустая строка — нулевой Source. У laser есть параметры, и она строится
+// через Laser.Source, а здесь — ошибка
+func ParseSource(s string) (Source, error) {
+	s = strings.TrimSpace(s)
+	switch strings.ToLower(s) {
+	case "":
+		return Source{}, nil
+	case SourceSine:
+		decay := func(t float64) float64 {
+			return 1/(math.Pi*math.Pi) + (1-1/(math.Pi*math.Pi))*math.Exp(-math.Pi*math.Pi*t)
+		}
+		return Source{Expr: SourceSine, f: func(x, _ float64) float64 { return math.Sin(math.Pi * x) }, exact: sineProduct{decay}}, nil
+	case SourceGaussian:
+		return Source{Expr: SourceGaussian, f: func(x, _ float64) float64 { return math.Exp(-100 * (x - 0.5) * (x - 0.5)) }}, nil
+	case SourceManufactured:
+		f := func(x, t float64) float64 {
+			return math.Sin(math.Pi*x) * (math.Pi*math.Pi*math.Cos(t) - math.Sin(t))
+		}
+		return Source{Expr: SourceManufactured, f: f, timeDependent: true, exact: sineProduct{math.Cos}}, nil
+	case SourceLaser:
+		return Source{}, fmt.Errorf("source %q needs beam parameters (power, speed, width, start)", s)
+	}
+	p := &exprParser{src: s}
+	n, err := p.parse()
+	if err != nil {
+		return Source{}, fmt.Errorf("source %q: %w", s, err)
+	}
+	return Source{Expr: s, f: n.eval, timeDependent: n.usesT}, nil
+}
+
+// Значение источника; у нулевого Source — 0
+func (s Source) Eval(x, t float64) float64 {
+	if s.f == nil {
+		return 0
+	}
+	return s.f(x, t)
+}
+
+// Источника нет
+func (s Source) IsZero() bool {
+	return s.f == nil
+}
+
+// Источник меняется со временем
+func (s Source) TimeDependent() bool {
+	return s.timeDependent
+}
+
+// Точное решение с начальным условием sin(πx) (только у предустановок
+// sine и manufactured); иначе nil
+func (s Source) SineSolution() Reference {
+	return s.exact
+}
+
+// Значения источника в узлах x_i = i·dx на момент t
+func (s Source) Fill(dst []float64, dx, t float64) {
+	for i := range dst {
+		dst[i] = s.Eval(float64(i)*dx, t)
+	}
+}
+
+// Решение amp(t)·sin(πx)
+type sineProduct struct {
+	amp func(t float64) float64
+}
+
+func (p sineProduct) Eval(x, t float64) float64 {
+	return p.amp(t) * math.Sin(math.Pi*x)
+}
+
+func (p sineProduct) Grid(nx int, dx float64) GridFunc {
+	shape := make([]float64, nx+1)
+	for i := range shape {
+		shape[i] = math.Sin(math.Pi * float64(i) * dx)
+	}
+	return func(dst []float64, t float64) {
+		a := p.amp(t)
+		for i := range dst {
+			dst[i] = a * shape[i]
+		}
+	}
+}
+
+func (p sineProduct) Heat(t float64) float64 {
+	return 2 / math.Pi * p.amp(t)
+}
+
+// Узел разобранного выражения: значение и зависимость от x и t
+type exprNode struct {
+	eval         func(x, t float64) float64
+	usesX, usesT bool
+}
+
+// Константа без зависимости от x и t сворачивается в число при разборе
+func constNode(v float64) exprNode {
+	return exprNode{eval: func(float64, float64) float64 { return v }}
+}
+
+func (n exprNode) constant() bool {
+	return !n.usesX && !n.usesT
+}
+
+// Функции выражений источника
+var exprFuncs = map[string]func(float64) float64{
+	"sin": math.Sin, "cos": math.Cos, "tan": math.Tan,
+	"exp": math.Exp, "log": math.Log, "sqrt": math.Sqrt, "abs": math.Abs,
+	"sinh": math.Sinh, "cosh": math.Cosh, "tanh": math.Tanh,
+}
+
+// Разбор рекурсивным спуском:
+//
+//	expr    = term {("+" | "-") term}
+//	term    = unary {("*" | "/") unary}
+//	unary   = ("+" | "-") unary | power
+//	power   = primary [("^" | "**") unary]
+//	primary = number | "x" | "t" | "pi" | "e" | func "(" expr ")" | "(" expr ")"
+//
+// Степень правоассоциативна и сильнее унарного минуса: -x^2 = −(x²)
+type exprParser struct {
+	src string
+	pos int
+}
+
+func (p *exprParser) parse() (exprNode, error) {
+	n, err := p.expr()
+	if err != nil {
+		return exprNode{}, err
+	}
+	if p.skip(); p.pos < len(p.src) {
+		return exprNode{}, fmt.Errorf("unexpected %q at position %d", p.src[p.pos:], p.pos+1)
+	}
+	return n, nil
+}
+
+func (p *exprParser) skip() {
+	for p.pos < len(p.src) && p.src[p.pos] == ' ' {
+		p.pos++
+	}
+}
+
+// Следующий оператор op, если он есть; позиция сдвигается за него
+func (p *exprParser) accept(op string) bool {
+	p.skip()
+	if strings.HasPrefix(p.src[p.pos:], op) {
+		p.pos += len(op)
+		return true
+	}
+	return false
+}
+
+func (p *exprParser) expr() (exprNode, error) {
+	n, err := p.term()
+	for err == nil {
+		switch {
+		case p.accept("+"):
+			var r exprNode
+			if r, err = p.term(); err == nil {
+				n = binary(n, r, func(a, b float64) float64 { return a + b })
+			}
+		case p.accept("-"):
+			var r exprNode
+			if r, err = p.term(); err == nil {
+				n = binary(n, r, func(a, b float64) float64 { return a - b })
+			}
+		default:
+			return n, nil
+		}
+	}
+	return exprNode{}, err
+}
+
+func (p *exprParser) term() (exprNode, error) {
+	n, err := p.unary()
+	for err == nil {
+		switch {
+		case p.accept("*"):
+			var r exprNode
+			if r, err = p.unary(); err == nil {
+				n = binary(n, r, func(a, b float64) float64 { return a * b })
+			}
+		case p.accept("/"):
+			var r exprNode
+			if r, err = p.unary(); err == nil {
+				n = binary(n, r, func(a, b float64) float64 { return a / b })
+			}
+		default:
+			return n, nil
+		}
+	}
+	return exprNode{}, err
+}
+
+func (p *exprParser) unary() (exprNode, error) {
+	switch {
+	case p.accept("-"):
+		n, err := p.unary()
+		if err != nil {
+			return exprNode{}, err
+		}
+		return apply(n, func(v float64) float64 { return -v }), nil
+	case p.accept("+"):
+		return p.unary()
+	}
+	return p.power()
+}
+
+func (p *exprParser) power() (exprNode, error) {
+	base, err := p.primary()
+	if err != nil {
+		return exprNode{}, err
+	}
+	if p.accept("^") || p.accept("**") {
+		exp, err := p.unary()
+		if err != nil {
+			return exprNode{}, err
+		}
+		return binary(base, exp, math.Pow), nil
+	}
+	return base, nil
+}
+
+func (p *exprParser) primary() (exprNode, error) {
+	p.skip()
+	if p.pos == len(p.src) {
+		return exprNode{}, fmt.Errorf("unexpected end of expression")
+	}
+	c := rune(p.src[p.pos])
+	switch {
+	case c == '(':
+		p.pos++
+		n, err := p.expr()
+		if err != nil {
+			return exprNode{}, err
+		}
+		if !p.accept(")") {
+			return exprNode{}, fmt.Errorf("missing ) at position %d", p.pos+1)
+		}
+		return n, nil
+	case unicode.IsDigit(c) || c == '.':
+		return p.number()
+	case unicode.IsLetter(c):
+		start := p.pos
+		for p.pos < len(p.src) && (unicode.IsLetter(rune(p.src[p.pos])) || unicode.IsDigit(rune(p.src[p.pos]))) {
+			p.pos++
+		}
+		name := strings.ToLower(p.src[start:p.pos])
+		switch name {
+		case "x":
+			return exprNode{eval: func(x, _ float64) float64 { return x }, usesX: true}, nil
+		case "t":
+			return exprNode{eval: func(_, t float64) float64 { return t }, usesT: true}, nil
+		case "pi":
+			return constNode(math.Pi), nil
+		case "e":
+			return constNode(math.E), nil
+		}
+		fn, ok := exprFuncs[name]
+		if !ok {
+			return exprNode{}, fmt.Errorf("unknown name %q at position %d", name, start+1)
+		}
+		if !p.accept("(") {
+			return exprNode{}, fmt.Errorf("%s needs ( at position %d", name, p.pos+1)
+		}
+		arg, err := p.expr()
+		if err != nil {
+			return exprNode{}, err
+		}
+		if !p.accept(")") {
+			return exprNode{}, fmt.Errorf("missing ) at position %d", p.pos+1)
+		}
+		return apply(arg, fn), nil
+	}
+	return exprNode{}, fmt.Errorf("unexpected %q at position %d", string(c), p.pos+1)
+}
+
+// Число с необязательной дробной частью и порядком: 2, .5, 1e-3
+func (p *exprParser) number() (exprNode, error) {
+	start := p.pos
+	digits := func() {
+		for p.pos < len(p.src) && unicode.IsDigit(rune(p.src[p.pos])) {
+			p.pos++
+		}
+	}
+	digits()
+	if p.pos < len(p.src) && p.src[p.pos] == '.' {
+		p.pos++
+		digits()
+	}
+	// Порядок — только если за e идут цифры (иначе e — константа или имя)
+	if p.pos < len(p.src) && (p.src[p.pos] == 'e' || p.src[p.pos] == 'E') {
+		next := p.pos + 1
+		if next < len(p.src) && (p.src[next] == '+' || p.src[next] == '-') {
+			next++
+		}
+		if next < len(p.src) && unicode.IsDigit(rune(p.src[next])) {
+			p.pos = next
+			digits()
+		}
+	}
+	v, err := strconv.ParseFloat(p.src[start:p.pos], 64)
+	if err != nil {
+		return exprNode{}, fmt.Errorf("bad number %q at position %d", p.src[start:p.pos], start+1)
+	}
+	return constNode(v), nil
+}
+
+func apply(n exprNode, fn func(float64) float64) exprNode {
+	if n.constant() {
+		return constNode(fn(n.eval(0, 0)))
+	}
+	eval := n.eval
+	return exprNode{eval: func(x, t float64) float64 { return fn(eval(x, t)) }, usesX: n.usesX, usesT: n.usesT}
+}
+
+func binary(l, r exprNode, op func(a, b float64) float64) exprNode {
+	if l.constant() && r.constant() {
+		return constNode(op(l.eval(0, 0), r.eval(0, 0)))
+	}
+	le, re := l.eval, r.eval
+	return exprNode{eval: func(x, t float64) float64 { return op(le(x, t), re(x, t)) }, usesX: l.usesX || r.usesX, usesT: l.usesT || r.usesT}
+}
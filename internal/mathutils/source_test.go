@@ -0,0 +1,100 @@
+package mathutils
+
+import (
+	"math"
+	"testing"
+)
+
+// Выражения: приоритеты, правоассоциативная степень, унарный минус слабее
+// степени, числа с порядком, константы и функции; зависимость от t
+func TestParseSource(t *testing.T) {
+	tests := []struct {
+		expr string
+		want func(x, t float64) float64
+		dynT bool
+	}{
+		{"sin(pi*x)", func(x, _ float64) float64 { return math.Sin(math.Pi * x) }, false},
+		{"1 + 2*3 - 4/8", func(_, _ float64) float64 { return 6.5 }, false},
+		{"2^3^2", func(_, _ float64) float64 { return 512 }, false},
+		{"2**3", func(_, _ float64) float64 { return 8 }, false},
+		{"-x^2", func(x, _ float64) float64 { return -x * x }, false},
+		{"--x", func(x, _ float64) float64 { return x }, false},
+		{"(1 - x)*x", func(x, _ float64) float64 { return (1 - x) * x }, false},
+		{"1e-3*t + .5 + 2E+1", func(_, t float64) float64 { return 1e-3*t + 20.5 }, true},
+		{"exp(-t)*x*(1-x)", func(x, t float64) float64 { return math.Exp(-t) * x * (1 - x) }, true},
+		{"2*e", func(_, _ float64) float64 { return 2 * math.E }, false},
+		{"SQRT(abs(x - 1)) + log(1 + t) + tanh(x)", func(x, t float64) float64 {
+			return math.Sqrt(math.Abs(x-1)) + math.Log(1+t) + math.Tanh(x)
+		}, true},
+		{"  cosh( x )  /  sinh(1) + tan(cos(t)) ", func(x, t float64) float64 {
+			return math.Cosh(x)/math.Sinh(1) + math.Tan(math.Cos(t))
+		}, true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.expr, func(t *testing.T) {
+			s, err := ParseSource(tc.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s.IsZero() || s.TimeDependent() != tc.dynT || s.SineSolution() != nil {
+				t.Errorf("zero %v, time-dependent %v, exact solution %v", s.IsZero(), s.TimeDependent(), s.SineSolution())
+			}
+			for _, x := range []float64{0, 0.3, 0.75} {
+				for _, tm := range []float64{0, 0.5, 2} {
+					if got, want := s.Eval(x, tm), tc.want(x, tm); math.Abs(got-want) > 1e-14*math.Max(1, math.Abs(want)) {
+						t.Errorf("f(%v, %v) = %v, want %v", x, tm, got, want)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestParseSourceErrors(t *testing.T) {
+	for _, expr := range []string{"x +", "(x", "x)", "foo(x)", "sin x", "sin(x", "y", "2e", "x # 2", "1..2", "laser", "*x"} {
+		if s, err := ParseSource(expr); err == nil {
+			t.Errorf("%q accepted as %q", expr, s.Expr)
+		}
+	}
+}
+
+// Предустановки: пустая строка — нулевой источник; у sine и manufactured
+// точное решение из sin(πx) удовлетворяет u_t = u_xx + f
+func TestSourcePresets(t *testing.T) {
+	zero, err := ParseSource("  ")
+	if err != nil || !zero.IsZero() || zero.Eval(0.5, 1) != 0 {
+		t.Errorf("empty source: %+v, %v", zero, err)
+	}
+	for _, name := range []string{SourceSine, " Manufactured ", SourceGaussian} {
+		s, err := ParseSource(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ref := s.SineSolution()
+		if ref == nil {
+			if s.Expr != SourceGaussian || s.Eval(0.5, 0) != 1 {
+				t.Errorf("%s: no exact solution", name)
+			}
+			continue
+		}
+		if s.TimeDependent() != (s.Expr == SourceManufactured) {
+			t.Errorf("%s: time-dependent %v", name, s.TimeDependent())
+		}
+		const h = 1e-4
+		for _, x := range []float64{0.2, 0.5} {
+			for _, tm := range []float64{0, 0.1, 1} {
+				if math.Abs(ref.Eval(x, 0)-math.Sin(math.Pi*x)) > 1e-15 {
+					t.Fatalf("%s: initial value %v at x = %v", name, ref.Eval(x, 0), x)
+				}
+				ut := (ref.Eval(x, tm+h) - ref.Eval(x, tm-h)) / (2 * h)
+				uxx := (ref.Eval(x+h, tm) - 2*ref.Eval(x, tm) + ref.Eval(x-h, tm)) / (h * h)
+				if r := ut - uxx - s.Eval(x, tm); math.Abs(r) > 1e-5 {
+					t.Errorf("%s: residual %.3g at x = %v, t = %v", name, r, x, tm)
+				}
+			}
+		}
+		if heat := ref.Heat(0); math.Abs(heat-2/math.Pi) > 1e-15 {
+			t.Errorf("%s: heat %v at t = 0", name, heat)
+		}
+	}
+}
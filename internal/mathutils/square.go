@@ -0,0 +1,53 @@
+package mathutils
+
+import "math"
+
+// Эталонное решение на квадрате: Eval — в точке, Grid — на слоях сетки
+// (nx+1)·(ny+1) узлов, x меняется быстрее
+type Reference2D interface {
+	Eval(x, y, t float64) float64
+	Grid(nx, ny int, dx, dy float64) GridFunc
+}
+
+// Точное решение на единичном квадрате exp(−2απ²t)·sin(πx)·sin(πy) для
+// начального условия sin(πx)·sin(πy) с нулевыми краями
+type Analytical2D struct {
+	Alpha float64
+}
+
+func (a Analytical2D) Eval(x, y, t float64) float64 {
+	return math.Exp(-2*math.Pi*math.Pi*a.Alpha*t) * math.Sin(math.Pi*x) * math.Sin(math.Pi*y)
+}
+
+// sin(πx_i)·sin(πy_j) считается один раз на сетку, экспонента — один раз
+// на слой
+func (a Analytical2D) Grid(nx, ny int, dx, dy float64) GridFunc {
+	shape := make([]float64, (nx+1)*(ny+1))
+	for j := 0; j <= ny; j++ {
+		sy := math.Sin(math.Pi * (float64(j) * dy))
+		for i := 0; i <= nx; i++ {
+			shape[j*(nx+1)+i] = math.Sin(math.Pi*(float64(i)*dx)) * sy
+		}
+	}
+	return func(dst []float64, t float64) {
+		decay := math.Exp(-2 * math.Pi * math.Pi * a.Alpha * t)
+		for k, s := range shape[:len(dst)] {
+			dst[k] = decay * s
+		}
+	}
+}
+
+// Нормы ошибки L2 (среднеквадратичная по узлам, как LevelErrors) и L∞
+// двумерного слоя row в момент t
+func LevelErrors2D(row []float64, nx, ny int, dx, dy, t float64, exact Reference2D) (float64, float64) {
+	ue := make([]float64, len(row))
+	exact.Grid(nx, ny, dx, dy)(ue, t)
+
+	var sumSq, linf float64
+	for k, v := range row {
+		err := math.Abs(v - ue[k])
+		sumSq += err * err
+		linf = max(linf, err)
+	}
+	return math.Sqrt(sumSq / float64(len(row))), linf
+}
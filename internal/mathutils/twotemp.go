@@ -0,0 +1,44 @@
+package mathutils
+
+import "math"
+
+// Точное решение двухтемпературной модели u_t = α₁·u_xx − h·(u − v),
+// v_t = α₂·v_xx + h·(u − v) с нулём на краях из u = U0·sin(πx),
+// v = V0·sin(πx): оба поля остаются модой sin(πx), и амплитуды (a, b)
+// подчиняются (a, b)′ = M·(a, b) с симметричной
+// M = [[−α₁π² − h, h], [h, −α₂π² − h]]. Тогда exp(Mt) = e^{mt}·(cosh(st)·I +
+// sinh(st)/s·(M − mI)), m — половина следа, s = √(((M₁₁ − M₂₂)/2)² + h²).
+// При h → ∞ поля сливаются в (U0 + V0)/2·exp(−(α₁ + α₂)π²t/2)·sin(πx)
+type TwoTemperatureMode struct {
+	Alpha1, Alpha2 float64
+	H              float64
+	U0, V0         float64
+}
+
+// Амплитуды мод u и v в момент t
+func (m TwoTemperatureMode) Amplitudes(t float64) (float64, float64) {
+	k := math.Pi * math.Pi
+	m11, m22 := -m.Alpha1*k-m.H, -m.Alpha2*k-m.H
+	mean, half := (m11+m22)/2, (m11-m22)/2
+	s := math.Hypot(half, m.H)
+	if s == 0 {
+		e := math.Exp(mean * t)
+		return e * m.U0, e * m.V0
+	}
+	// e^{mt}·cosh(st) и e^{mt}·sinh(st)/s через e^{(m+s)t}: при большом h
+	// cosh и sinh отдельно переполнились бы
+	e := math.Exp((mean + s) * t)
+	damp := math.Exp(-2 * s * t)
+	ch, sh := e*(1+damp)/2, e*(1-damp)/(2*s)
+	return ch*m.U0 + sh*(half*m.U0+m.H*m.V0), ch*m.V0 + sh*(m.H*m.U0-half*m.V0)
+}
+
+// Поле u как эталон
+func (m TwoTemperatureMode) U() Reference {
+	return sineProduct{func(t float64) float64 { a, _ := m.Amplitudes(t); return a }}
+}
+
+// Поле v как эталон
+func (m TwoTemperatureMode) V() Reference {
+	return sineProduct{func(t float64) float64 { _, b := m.Amplitudes(t); return b }}
+}
@@ -0,0 +1,50 @@
+package mathutils
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+// Амплитуды решают (a, b)′ = M·(a, b) с начальными U0, V0; при h = 0
+// моды затухают независимо, при большом h сливаются в полусумму с
+// α = (α₁ + α₂)/2 без переполнения
+func TestTwoTemperatureMode(t *testing.T) {
+	k := math.Pi * math.Pi
+	for _, m := range []TwoTemperatureMode{
+		{Alpha1: 1, Alpha2: 0.2, H: 5, U0: 1},
+		{Alpha1: 0.5, Alpha2: 0.5, H: 2, U0: 1, V0: -1},
+		{Alpha1: 1, Alpha2: 0.01, H: 100, U0: 0.3, V0: 2},
+	} {
+		t.Run(fmt.Sprintf("%+v", m), func(t *testing.T) {
+			if a, b := m.Amplitudes(0); math.Abs(a-m.U0) > 1e-14 || math.Abs(b-m.V0) > 1e-14 {
+				t.Errorf("initial amplitudes %v, %v", a, b)
+			}
+			const h = 1e-6
+			for _, tm := range []float64{0.01, 0.1, 0.5} {
+				a, b := m.Amplitudes(tm)
+				a1, b1 := m.Amplitudes(tm + h)
+				a0, b0 := m.Amplitudes(tm - h)
+				da, db := (a1-a0)/(2*h), (b1-b0)/(2*h)
+				ra := da - (-m.Alpha1*k*a - m.H*(a-b))
+				rb := db - (-m.Alpha2*k*b + m.H*(a-b))
+				if math.Abs(ra) > 1e-6*m.H || math.Abs(rb) > 1e-6*m.H {
+					t.Errorf("t=%v: residuals %.3g, %.3g", tm, ra, rb)
+				}
+				if u, v := m.U().Eval(0.5, tm), m.V().Eval(0.5, tm); u != a || v != b {
+					t.Errorf("t=%v: references give %v, %v at x = ½", tm, u, v)
+				}
+			}
+		})
+	}
+
+	free := TwoTemperatureMode{Alpha1: 1, Alpha2: 0.2, U0: 2, V0: 3}
+	if a, b := free.Amplitudes(0.1); math.Abs(a-2*math.Exp(-k*0.1)) > 1e-15 || math.Abs(b-3*math.Exp(-0.2*k*0.1)) > 1e-15 {
+		t.Errorf("h = 0: amplitudes %v, %v", a, b)
+	}
+	stiff := TwoTemperatureMode{Alpha1: 1, Alpha2: 0.2, H: 1e9, U0: 1}
+	want := 0.5 * math.Exp(-0.6*k*0.1)
+	if a, b := stiff.Amplitudes(0.1); math.Abs(a-want) > 1e-8 || math.Abs(b-want) > 1e-8 {
+		t.Errorf("h = 1e9: amplitudes %v, %v; want %v", a, b, want)
+	}
+}
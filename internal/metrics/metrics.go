@@ -0,0 +1,212 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Стандартные границы гистограмм длительности (секунды)
+var DefaultBuckets = []float64{0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 5, 10, 30}
+
+type kind int
+
+const (
+	kindCounter kind = iota
+	kindGauge
+	kindHistogram
+)
+
+func (k kind) String() string {
+	switch k {
+	case kindCounter:
+		return "counter"
+	case kindGauge:
+		return "gauge"
+	default:
+		return "histogram"
+	}
+}
+
+type series struct {
+	labels  []string
+	value   float64
+	buckets []uint64
+	sum     float64
+	count   uint64
+}
+
+type family struct {
+	name    string
+	help    string
+	kind    kind
+	labels  []string
+	buckets []float64
+	series  map[string]*series
+}
+
+// Набор метрик в текстовом формате Prometheus без внешних зависимостей
+type Registry struct {
+	mu       sync.Mutex
+	families []*family
+}
+
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+func (r *Registry) register(name, help string, k kind, buckets []float64, labels []string) *family {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	f := &family{name: name, help: help, kind: k, labels: labels, buckets: buckets, series: make(map[string]*series)}
+	r.families = append(r.families, f)
+	// Метрика без меток видна с нулём сразу, а не после первого изменения
+	if len(labels) == 0 {
+		r.get(f, nil)
+	}
+	return f
+}
+
+// Возвращает серию по значениям меток, создавая её при первом обращении
+func (r *Registry) get(f *family, values []string) *series {
+	if len(values) != len(f.labels) {
+		panic(fmt.Sprintf("metrics: %s expects %d labels, got %d", f.name, len(f.labels), len(values)))
+	}
+	key := strings.Join(values, "\xff")
+	s, ok := f.series[key]
+	if !ok {
+		s = &series{labels: append([]string(nil), values...)}
+		if f.kind == kindHistogram {
+			s.buckets = make([]uint64, len(f.buckets))
+		}
+		f.series[key] = s
+	}
+	return s
+}
+
+type Counter struct {
+	r *Registry
+	f *family
+}
+
+func (r *Registry) NewCounter(name, help string, labels ...string) *Counter {
+	return &Counter{r: r, f: r.register(name, help, kindCounter, nil, labels)}
+}
+
+func (c *Counter) Add(v float64, labels ...string) {
+	c.r.mu.Lock()
+	c.r.get(c.f, labels).value += v
+	c.r.mu.Unlock()
+}
+
+func (c *Counter) Inc(labels ...string) {
+	c.Add(1, labels...)
+}
+
+type Gauge struct {
+	r *Registry
+	f *family
+}
+
+func (r *Registry) NewGauge(name, help string, labels ...string) *Gauge {
+	return &Gauge{r: r, f: r.register(name, help, kindGauge, nil, labels)}
+}
+
+func (g *Gauge) Add(v float64, labels ...string) {
+	g.r.mu.Lock()
+	g.r.get(g.f, labels).value += v
+	g.r.mu.Unlock()
+}
+
+func (g *Gauge) Set(v float64, labels ...string) {
+	g.r.mu.Lock()
+	g.r.get(g.f, labels).value = v
+	g.r.mu.Unlock()
+}
+
+type Histogram struct {
+	r *Registry
+	f *family
+}
+
+func (r *Registry) NewHistogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	return &Histogram{r: r, f: r.register(name, help, kindHistogram, buckets, labels)}
+}
+
+func (h *Histogram) Observe(v float64, labels ...string) {
+	h.r.mu.Lock()
+	defer h.r.mu.Unlock()
+	s := h.r.get(h.f, labels)
+	for i, le := range h.f.buckets {
+		if v <= le {
+			s.buckets[i]++
+		}
+	}
+	s.sum += v
+	s.count++
+}
+
+// Запись всех метрик в текстовом формате экспозиции Prometheus 0.0.4
+func (r *Registry) WriteTo(w io.Writer) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var b strings.Builder
+	for _, f := range r.families {
+		fmt.Fprintf(&b, "# HELP %s %s\n", f.name, f.help)
+		fmt.Fprintf(&b, "# TYPE %s %s\n", f.name, f.kind)
+
+		keys := make([]string, 0, len(f.series))
+		for k := range f.series {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			s := f.series[k]
+			if f.kind != kindHistogram {
+				fmt.Fprintf(&b, "%s%s %s\n", f.name, formatLabels(f.labels, s.labels, "", ""), formatValue(s.value))
+				continue
+			}
+			for i, le := range f.buckets {
+				fmt.Fprintf(&b, "%s_bucket%s %d\n", f.name, formatLabels(f.labels, s.labels, "le", formatValue(le)), s.buckets[i])
+			}
+			fmt.Fprintf(&b, "%s_bucket%s %d\n", f.name, formatLabels(f.labels, s.labels, "le", "+Inf"), s.count)
+			fmt.Fprintf(&b, "%s_sum%s %s\n", f.name, formatLabels(f.labels, s.labels, "", ""), formatValue(s.sum))
+			fmt.Fprintf(&b, "%s_count%s %d\n", f.name, formatLabels(f.labels, s.labels, "", ""), s.count)
+		}
+	}
+
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+func formatLabels(names, values []string, extraName, extraValue string) string {
+	if len(names) == 0 && extraName == "" {
+		return ""
+	}
+	parts := make([]string, 0, len(names)+1)
+	for i, n := range names {
+		parts = append(parts, n+"="+strconv.Quote(values[i]))
+	}
+	if extraName != "" {
+		parts = append(parts, extraName+"="+strconv.Quote(extraValue))
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+func formatValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
@@ -0,0 +1,57 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWriteTo(t *testing.T) {
+	r := NewRegistry()
+	requests := r.NewCounter("requests_total", "Requests.", "endpoint", "status")
+	active := r.NewGauge("active", "Active.")
+	r.NewGauge("idle", "Never changed.")
+	duration := r.NewHistogram("duration_seconds", "Duration.", []float64{0.1, 1}, "method")
+
+	requests.Inc("/b", "200")
+	requests.Add(2, "/a", "500")
+	active.Add(3)
+	active.Add(-1)
+	duration.Observe(0.05, "CN")
+	duration.Observe(0.5, "CN")
+
+	var b strings.Builder
+	if _, err := r.WriteTo(&b); err != nil {
+		t.Fatal(err)
+	}
+	want := `# HELP requests_total Requests.
+# TYPE requests_total counter
+requests_total{endpoint="/a",status="500"} 2
+requests_total{endpoint="/b",status="200"} 1
+# HELP active Active.
+# TYPE active gauge
+active 2
+# HELP idle Never changed.
+# TYPE idle gauge
+idle 0
+# HELP duration_seconds Duration.
+# TYPE duration_seconds histogram
+duration_seconds_bucket{method="CN",le="0.1"} 1
+duration_seconds_bucket{method="CN",le="1"} 2
+duration_seconds_bucket{method="CN",le="+Inf"} 2
+duration_seconds_sum{method="CN"} 0.55
+duration_seconds_count{method="CN"} 2
+`
+	if got := b.String(); got != want {
+		t.Errorf("WriteTo:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestLabelCountMismatchPanics(t *testing.T) {
+	c := NewRegistry().NewCounter("c", "C.", "method")
+	defer func() {
+		if recover() == nil {
+			t.Error("Inc with a missing label did not panic")
+		}
+	}()
+	c.Inc()
+}
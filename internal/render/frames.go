@@ -0,0 +1,138 @@
+package render
+
+import (
+	"bytes"
+	"context"
+	"image/png"
+	"io"
+	"runtime"
+	"sync"
+)
+
+// Кадры независимы: растеризация и сжатие идут в workers горутинах,
+// а запись — по порядку в вызывающей. В работе одновременно не больше
+// 2·workers кадров, поэтому память не растёт с их числом
+type frameResult struct {
+	data []byte
+	err  error
+}
+
+type frameJob struct {
+	i   int
+	out chan<- frameResult
+}
+
+// encode(i) для i = 0..count−1 в workers горутинах (≤ 0 — GOMAXPROCS),
+// write(i, data) — строго по порядку i. Первая ошибка encode, write или
+// отмена ctx прерывает остальные кадры
+func encodeOrdered(ctx context.Context, count, workers int, encode func(i int) ([]byte, error), write func(i int, data []byte) error) error {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	workers = max(1, min(workers, count))
+	if workers == 1 {
+		for i := range count {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			data, err := encode(i)
+			if err != nil {
+				return err
+			}
+			if err := write(i, data); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	stop, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+
+	order := make(chan chan frameResult, 2*workers)
+	jobs := make(chan frameJob)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(order)
+		defer close(jobs)
+		for i := range count {
+			out := make(chan frameResult, 1)
+			select {
+			case order <- out:
+			case <-stop.Done():
+				return
+			}
+			select {
+			case jobs <- frameJob{i: i, out: out}:
+			case <-stop.Done():
+				return
+			}
+		}
+	}()
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				data, err := encode(j.i)
+				j.out <- frameResult{data: data, err: err}
+			}
+		}()
+	}
+
+	i := 0
+	for out := range order {
+		// select выбирает случайно из готовых: без проверки готовый кадр
+		// мог бы записаться и после отмены
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		var r frameResult
+		select {
+		case r = <-out:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		if r.err != nil {
+			return r.err
+		}
+		if err := write(i, r.data); err != nil {
+			return err
+		}
+		i++
+	}
+	return ctx.Err()
+}
+
+// Кадры анимации отдельными PNG: create открывает файл (или другой
+// приёмник) для кадра с номером frame, кадр — слой frame·Stride. Кадры
+// те же, что в WriteAnimation с теми же opts
+func WritePNGFrames(ctx context.Context, u [][]float64, opts AnimationOptions, create func(frame int) (io.WriteCloser, error)) error {
+	vmin, vmax := opts.Vmin, opts.Vmax
+	if vmin == vmax {
+		vmin, vmax = Range(u)
+	}
+	stride := max(1, opts.Stride)
+
+	encode := func(i int) ([]byte, error) {
+		var b bytes.Buffer
+		err := png.Encode(&b, ProfileFrame(u[i*stride], vmin, vmax, opts.Width, opts.Height))
+		return b.Bytes(), err
+	}
+	return encodeOrdered(ctx, FrameCount(len(u), stride), opts.Workers, encode, func(i int, data []byte) error {
+		f, err := create(i)
+		if err != nil {
+			return err
+		}
+		if _, err := f.Write(data); err != nil {
+			f.Close()
+			return err
+		}
+		return f.Close()
+	})
+}
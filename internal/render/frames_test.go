@@ -0,0 +1,178 @@
+package render
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image/gif"
+	"image/png"
+	"io"
+	"math"
+	"slices"
+	"testing"
+)
+
+// Слои nt+1 профилей затухающего синуса с разошедшимся последним слоем
+func testProfiles(nt, nx int) [][]float64 {
+	u := make([][]float64, nt+1)
+	for n := range u {
+		u[n] = make([]float64, nx+1)
+		for i := range u[n] {
+			u[n][i] = math.Exp(-float64(n)*0.01) * math.Sin(math.Pi*float64(i)/float64(nx))
+		}
+	}
+	u[nt][nx/2] = math.NaN()
+	return u
+}
+
+// Прежняя последовательная запись: кадр за кадром через WriteFrame
+func serialAnimation(t *testing.T, u [][]float64, opts AnimationOptions) []byte {
+	t.Helper()
+	vmin, vmax := Range(u)
+	var b bytes.Buffer
+	g, err := NewGIFWriter(&b, opts.Width, opts.Height)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for k := 0; k < len(u); k += opts.Stride {
+		if err := g.WriteFrame(ProfileFrame(u[k], vmin, vmax, opts.Width, opts.Height), opts.Delay); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := g.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return b.Bytes()
+}
+
+// GIF побайтно совпадает с последовательной записью при любом числе
+// горутин, в том числе большем числа кадров
+func TestWriteAnimationWorkers(t *testing.T) {
+	u := testProfiles(100, 64)
+	opts := AnimationOptions{Width: 90, Height: 40, Stride: 3, Delay: 5}
+	want := serialAnimation(t, u, opts)
+	for _, workers := range []int{1, 2, 7, 100, 0} {
+		t.Run(fmt.Sprintf("workers=%d", workers), func(t *testing.T) {
+			opts := opts
+			opts.Workers = workers
+			var b bytes.Buffer
+			if err := WriteAnimation(context.Background(), &b, u, opts); err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(b.Bytes(), want) {
+				t.Fatalf("GIF differs from the serial one (%d bytes, want %d)", b.Len(), len(want))
+			}
+			g, err := gif.DecodeAll(&b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(g.Image) != FrameCount(len(u), opts.Stride) {
+				t.Errorf("%d frames, want %d", len(g.Image), FrameCount(len(u), opts.Stride))
+			}
+		})
+	}
+}
+
+type closingBuffer struct{ bytes.Buffer }
+
+func (*closingBuffer) Close() error { return nil }
+
+// Кадры PNG — те же изображения, что в GIF, по одному файлу в порядке
+// номеров
+func TestWritePNGFrames(t *testing.T) {
+	u := testProfiles(20, 30)
+	opts := AnimationOptions{Width: 50, Height: 30, Stride: 4, Workers: 3}
+	vmin, vmax := Range(u)
+	var frames []*closingBuffer
+	err := WritePNGFrames(context.Background(), u, opts, func(frame int) (io.WriteCloser, error) {
+		if frame != len(frames) {
+			t.Fatalf("frame %d created after %d frames", frame, len(frames))
+		}
+		frames = append(frames, new(closingBuffer))
+		return frames[frame], nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(frames) != FrameCount(len(u), opts.Stride) {
+		t.Fatalf("%d frames, want %d", len(frames), FrameCount(len(u), opts.Stride))
+	}
+	for i, f := range frames {
+		var want bytes.Buffer
+		if err := png.Encode(&want, ProfileFrame(u[i*opts.Stride], vmin, vmax, opts.Width, opts.Height)); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(f.Bytes(), want.Bytes()) {
+			t.Errorf("frame %d differs from png.Encode", i)
+		}
+	}
+}
+
+// Ошибка кодирования, записи или отмена останавливает кадры; записанные
+// до неё идут по порядку
+func TestEncodeOrderedErrors(t *testing.T) {
+	errEncode, errWrite := errors.New("encode"), errors.New("write")
+	tests := []struct {
+		name      string
+		encodeErr int // кадр, кодирование которого отказывает; −1 — нет
+		writeErr  int // кадр, запись которого отказывает; −1 — нет
+		cancel    bool
+		want      error
+		written   int
+	}{
+		{"encode", 37, -1, false, errEncode, 37},
+		{"first encode", 0, -1, false, errEncode, 0},
+		{"write", -1, 12, false, errWrite, 12},
+		{"cancelled", -1, -1, true, context.Canceled, 0},
+		{"all frames", -1, -1, false, nil, 100},
+	}
+	for _, tc := range tests {
+		for _, workers := range []int{1, 4} {
+			t.Run(fmt.Sprintf("%s/workers=%d", tc.name, workers), func(t *testing.T) {
+				ctx, cancel := context.WithCancel(context.Background())
+				defer cancel()
+				if tc.cancel {
+					cancel()
+				}
+				var written []int
+				err := encodeOrdered(ctx, 100, workers, func(i int) ([]byte, error) {
+					if i == tc.encodeErr {
+						return nil, errEncode
+					}
+					return []byte{byte(i)}, nil
+				}, func(i int, data []byte) error {
+					if i == tc.writeErr {
+						return errWrite
+					}
+					if data[0] != byte(i) {
+						t.Errorf("frame %d got the data of frame %d", i, data[0])
+					}
+					written = append(written, i)
+					return nil
+				})
+				if !errors.Is(err, tc.want) {
+					t.Fatalf("error %v, want %v", err, tc.want)
+				}
+				if len(written) != tc.written || !slices.IsSorted(written) {
+					t.Errorf("frames written %v, want 0..%d in order", written, tc.written-1)
+				}
+			})
+		}
+	}
+}
+
+// 501 кадр 400×200 при разном числе горутин
+func BenchmarkWriteAnimation(b *testing.B) {
+	u := testProfiles(500, 1000)
+	for _, workers := range []int{1, 2, 4, 8} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			opts := AnimationOptions{Width: 400, Height: 200, Stride: 1, Delay: 4, Workers: workers}
+			for b.Loop() {
+				if err := WriteAnimation(context.Background(), io.Discard, u, opts); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,258 @@
+package render
+
+import (
+	"bytes"
+	"compress/lzw"
+	"context"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"io"
+	"math"
+)
+
+// Палитра кадров профиля (8 цветов, 3 бита на пиксель)
+const (
+	idxBackground = iota
+	idxGrid
+	idxAxis
+	idxCurve
+	idxNaN
+)
+
+var ProfilePalette = color.Palette{
+	color.RGBA{255, 255, 255, 255},
+	color.RGBA{225, 225, 225, 255},
+	color.RGBA{120, 120, 120, 255},
+	color.RGBA{255, 140, 0, 255},
+	NaNColor,
+	color.RGBA{200, 0, 0, 255},
+	color.RGBA{0, 0, 0, 255},
+	color.RGBA{30, 90, 200, 255},
+}
+
+const paletteBits = 3
+
+// Кадр с профилем u(x) на момент времени; шкала по вертикали [vmin, vmax]
+func ProfileFrame(row []float64, vmin, vmax float64, width, height int) *image.Paletted {
+	img := image.NewPaletted(image.Rect(0, 0, width, height), ProfilePalette)
+
+	margin := max(2, height/20)
+	span := vmax - vmin
+	if span == 0 {
+		span = 1
+	}
+	toY := func(v float64) int {
+		f := (v - vmin) / span
+		return margin + int(math.Round((1-f)*float64(height-1-2*margin)))
+	}
+
+	for x := 0; x < width; x += max(1, width/10) {
+		for y := 0; y < height; y++ {
+			img.SetColorIndex(x, y, idxGrid)
+		}
+	}
+	if vmin <= 0 && vmax >= 0 {
+		y0 := toY(0)
+		for x := 0; x < width; x++ {
+			img.SetColorIndex(x, y0, idxAxis)
+		}
+	}
+
+	nx := len(row)
+	prevX, prevY, havePrev := 0, 0, false
+	for i, v := range row {
+		px := 0
+		if nx > 1 {
+			px = i * (width - 1) / (nx - 1)
+		}
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			for y := 0; y < height; y++ {
+				img.SetColorIndex(px, y, idxNaN)
+			}
+			havePrev = false
+			continue
+		}
+		py := min(height-1, max(0, toY(v)))
+		if havePrev {
+			drawLine(img, prevX, prevY, px, py, idxCurve)
+		}
+		prevX, prevY, havePrev = px, py, true
+	}
+	return img
+}
+
+// Отрезок Брезенхэма толщиной 2 пикселя
+func drawLine(img *image.Paletted, x0, y0, x1, y1 int, idx uint8) {
+	dx := abs(x1 - x0)
+	dy := -abs(y1 - y0)
+	sx, sy := 1, 1
+	if x0 > x1 {
+		sx = -1
+	}
+	if y0 > y1 {
+		sy = -1
+	}
+	e := dx + dy
+	for {
+		img.SetColorIndex(x0, y0, idx)
+		img.SetColorIndex(x0, y0+1, idx)
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		e2 := 2 * e
+		if e2 >= dy {
+			e += dy
+			x0 += sx
+		}
+		if e2 <= dx {
+			e += dx
+			y0 += sy
+		}
+	}
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// Потоковая запись анимированного GIF: каждый кадр кодируется и
+// сразу отправляется в w одним вызовом Write
+type GIFWriter struct {
+	w      io.Writer
+	width  int
+	height int
+	buf    bytes.Buffer
+}
+
+func NewGIFWriter(w io.Writer, width, height int) (*GIFWriter, error) {
+	g := &GIFWriter{w: w, width: width, height: height}
+
+	b := &g.buf
+	b.WriteString("GIF89a")
+	binary.Write(b, binary.LittleEndian, uint16(width))
+	binary.Write(b, binary.LittleEndian, uint16(height))
+	b.WriteByte(0x80 | (paletteBits-1)<<4 | (paletteBits - 1))
+	b.WriteByte(0) // фон
+	b.WriteByte(0) // соотношение сторон
+	for _, c := range ProfilePalette {
+		r, gg, bb, _ := c.RGBA()
+		b.Write([]byte{byte(r >> 8), byte(gg >> 8), byte(bb >> 8)})
+	}
+	// Бесконечный повтор (NETSCAPE2.0)
+	b.Write([]byte{0x21, 0xFF, 0x0B})
+	b.WriteString("NETSCAPE2.0")
+	b.Write([]byte{0x03, 0x01, 0x00, 0x00, 0x00})
+
+	return g, g.flush()
+}
+
+// Кадр с задержкой в сотых долях секунды
+func (g *GIFWriter) WriteFrame(img *image.Paletted, delay int) error {
+	if img.Rect.Dx() != g.width || img.Rect.Dy() != g.height {
+		return errors.New("render: frame size differs from animation size")
+	}
+	if err := appendGIFFrame(&g.buf, img, delay); err != nil {
+		return err
+	}
+	return g.flush()
+}
+
+// Закодированный кадр (appendGIFFrame) — без повторного сжатия
+func (g *GIFWriter) writeEncoded(frame []byte) error {
+	g.buf.Write(frame)
+	return g.flush()
+}
+
+// Блоки управления, дескриптор и LZW-данные кадра. Не зависит от
+// состояния GIFWriter, поэтому кадры можно кодировать параллельно
+func appendGIFFrame(b *bytes.Buffer, img *image.Paletted, delay int) error {
+	width, height := img.Rect.Dx(), img.Rect.Dy()
+	b.Write([]byte{0x21, 0xF9, 0x04, 0x00})
+	binary.Write(b, binary.LittleEndian, uint16(delay))
+	b.Write([]byte{0x00, 0x00})
+
+	b.WriteByte(0x2C)
+	binary.Write(b, binary.LittleEndian, [4]uint16{0, 0, uint16(width), uint16(height)})
+	b.WriteByte(0x00)
+
+	var data bytes.Buffer
+	lw := lzw.NewWriter(&data, lzw.LSB, paletteBits)
+	if _, err := lw.Write(img.Pix); err != nil {
+		return err
+	}
+	if err := lw.Close(); err != nil {
+		return err
+	}
+	b.WriteByte(paletteBits)
+	for p := data.Bytes(); len(p) > 0; {
+		n := min(len(p), 255)
+		b.WriteByte(byte(n))
+		b.Write(p[:n])
+		p = p[n:]
+	}
+	b.WriteByte(0x00)
+	return nil
+}
+
+// Завершающий байт файла
+func (g *GIFWriter) Close() error {
+	g.buf.WriteByte(0x3B)
+	return g.flush()
+}
+
+func (g *GIFWriter) flush() error {
+	_, err := g.w.Write(g.buf.Bytes())
+	g.buf.Reset()
+	return err
+}
+
+type AnimationOptions struct {
+	Width  int
+	Height int
+	// Каждый Stride-й временной слой становится кадром
+	Stride int
+	// Задержка между кадрами, сотые доли секунды
+	Delay int
+	// Границы шкалы; при Vmin == Vmax берутся из данных
+	Vmin, Vmax float64
+	// Горутины для растеризации и сжатия кадров; 0 — GOMAXPROCS.
+	// Результат от числа горутин не зависит
+	Workers int
+}
+
+// Число кадров анимации из levels временных слоёв при заданном шаге
+func FrameCount(levels, stride int) int {
+	return (levels-1)/stride + 1
+}
+
+// Анимация эволюции профиля; кадры строятся и сжимаются параллельно
+// (opts.Workers) и пишутся по порядку. Прерывается при отмене ctx
+func WriteAnimation(ctx context.Context, w io.Writer, u [][]float64, opts AnimationOptions) error {
+	vmin, vmax := opts.Vmin, opts.Vmax
+	if vmin == vmax {
+		vmin, vmax = Range(u)
+	}
+	stride := max(1, opts.Stride)
+
+	g, err := NewGIFWriter(w, opts.Width, opts.Height)
+	if err != nil {
+		return err
+	}
+	encode := func(i int) ([]byte, error) {
+		var b bytes.Buffer
+		err := appendGIFFrame(&b, ProfileFrame(u[i*stride], vmin, vmax, opts.Width, opts.Height), opts.Delay)
+		return b.Bytes(), err
+	}
+	err = encodeOrdered(ctx, FrameCount(len(u), stride), opts.Workers, encode, func(_ int, frame []byte) error {
+		return g.writeEncoded(frame)
+	})
+	if err != nil {
+		return err
+	}
+	return g.Close()
+}
@@ -0,0 +1,155 @@
+package render
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"sort"
+)
+
+// Цвет для NaN/Inf ячеек разошедшегося решения
+var NaNColor = color.RGBA{R: 255, G: 0, B: 255, A: 255}
+
+// Цветовая карта: t ∈ [0, 1] → цвет
+type Colormap func(t float64) color.RGBA
+
+// Опорные точки карты, между ними — линейная интерполяция
+func gradient(stops ...color.RGBA) Colormap {
+	return func(t float64) color.RGBA {
+		t = math.Max(0, math.Min(1, t))
+		pos := t * float64(len(stops)-1)
+		i := int(pos)
+		if i >= len(stops)-1 {
+			return stops[len(stops)-1]
+		}
+		f := pos - float64(i)
+		a, b := stops[i], stops[i+1]
+		lerp := func(x, y uint8) uint8 { return uint8(math.Round(float64(x) + f*(float64(y)-float64(x)))) }
+		return color.RGBA{R: lerp(a.R, b.R), G: lerp(a.G, b.G), B: lerp(a.B, b.B), A: 255}
+	}
+}
+
+var colormaps = map[string]Colormap{
+	"hot": gradient(
+		color.RGBA{0, 0, 0, 255},
+		color.RGBA{230, 0, 0, 255},
+		color.RGBA{255, 210, 0, 255},
+		color.RGBA{255, 255, 255, 255},
+	),
+	"viridis": gradient(
+		color.RGBA{68, 1, 84, 255},
+		color.RGBA{59, 82, 139, 255},
+		color.RGBA{33, 145, 140, 255},
+		color.RGBA{94, 201, 98, 255},
+		color.RGBA{253, 231, 37, 255},
+	),
+	"coolwarm": gradient(
+		color.RGBA{59, 76, 192, 255},
+		color.RGBA{221, 221, 221, 255},
+		color.RGBA{180, 4, 38, 255},
+	),
+	"gray": gradient(
+		color.RGBA{0, 0, 0, 255},
+		color.RGBA{255, 255, 255, 255},
+	),
+}
+
+// Поиск цветовой карты по имени
+func LookupColormap(name string) (Colormap, error) {
+	cm, ok := colormaps[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown colormap %q (available: %v)", name, ColormapNames())
+	}
+	return cm, nil
+}
+
+func ColormapNames() []string {
+	names := make([]string, 0, len(colormaps))
+	for n := range colormaps {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Диапазон конечных значений поля
+func Range(u [][]float64) (lo, hi float64) {
+	lo, hi = math.Inf(1), math.Inf(-1)
+	for _, row := range u {
+		for _, v := range row {
+			if math.IsNaN(v) || math.IsInf(v, 0) {
+				continue
+			}
+			lo = math.Min(lo, v)
+			hi = math.Max(hi, v)
+		}
+	}
+	if lo > hi {
+		return 0, 1
+	}
+	return lo, hi
+}
+
+type HeatmapOptions struct {
+	Width    int
+	Height   int
+	Colormap Colormap
+	// Границы шкалы; при Vmin == Vmax берутся из данных
+	Vmin, Vmax float64
+	// Полоса-предупреждение сверху (например, для разошедшегося решения)
+	Banner bool
+}
+
+// Тепловая карта u(x, t): x — по горизонтали, t растёт снизу вверх
+func Heatmap(u [][]float64, opts HeatmapOptions) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, opts.Width, opts.Height))
+	nt := len(u)
+	nx := len(u[0])
+
+	vmin, vmax := opts.Vmin, opts.Vmax
+	if vmin == vmax {
+		vmin, vmax = Range(u)
+	}
+	span := vmax - vmin
+	if span == 0 {
+		span = 1
+	}
+	cm := opts.Colormap
+	if cm == nil {
+		cm = colormaps["hot"]
+	}
+
+	for py := 0; py < opts.Height; py++ {
+		n := (opts.Height - 1 - py) * nt / opts.Height
+		row := u[n]
+		for px := 0; px < opts.Width; px++ {
+			v := row[px*nx/opts.Width]
+			if math.IsNaN(v) || math.IsInf(v, 0) {
+				img.SetRGBA(px, py, NaNColor)
+				continue
+			}
+			img.SetRGBA(px, py, cm((v-vmin)/span))
+		}
+	}
+
+	if opts.Banner {
+		drawBanner(img)
+	}
+	return img
+}
+
+// Красно-чёрная полоса вверху изображения
+func drawBanner(img *image.RGBA) {
+	b := img.Bounds()
+	h := max(4, b.Dy()/12)
+	for y := 0; y < h && y < b.Dy(); y++ {
+		for x := 0; x < b.Dx(); x++ {
+			c := color.RGBA{R: 200, A: 255}
+			if ((x+y)/8)%2 == 0 {
+				c = color.RGBA{A: 255}
+			}
+			img.SetRGBA(x, y, c)
+		}
+	}
+}
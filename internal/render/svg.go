@@ -0,0 +1,162 @@
+package render
+
+import (
+	"bufio"
+	"cmp"
+	"fmt"
+	"html"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+)
+
+// Именованная серия точек (X[i], Y[i])
+type Series struct {
+	Name string
+	X, Y []float64
+}
+
+type PlotOptions struct {
+	Width, Height int
+	Title         string
+	XLabel        string
+	YLabel        string
+	// Штриховая линия по точкам, которые не хуже других сразу по X и Y
+	// (фронт Парето при минимизации обеих величин)
+	Front bool
+}
+
+// Цвета серий по порядку
+var seriesColors = []string{"#1f77b4", "#d62728", "#2ca02c", "#ff7f0e", "#9467bd", "#8c564b"}
+
+// Поля вокруг области графика, px
+const (
+	plotLeft   = 72
+	plotRight  = 130
+	plotTop    = 36
+	plotBottom = 52
+)
+
+// Точечный график в логарифмических осях X и Y в формате SVG. Точки
+// серии соединяются в порядке следования; неположительные и нечисловые
+// значения пропускаются. Деления — на степенях десяти
+func LogLogSVG(w io.Writer, series []Series, opts PlotOptions) error {
+	xlo, xhi, ylo, yhi := math.Inf(1), math.Inf(-1), math.Inf(1), math.Inf(-1)
+	for _, s := range series {
+		for i, x := range s.X {
+			if !plottable(x, s.Y[i]) {
+				continue
+			}
+			xlo, xhi = min(xlo, x), max(xhi, x)
+			ylo, yhi = min(ylo, s.Y[i]), max(yhi, s.Y[i])
+		}
+	}
+	if xlo > xhi {
+		return fmt.Errorf("no positive finite points to plot")
+	}
+	// Оси — целые декады, не меньше одной
+	x0, x1 := decades(xlo, xhi)
+	y0, y1 := decades(ylo, yhi)
+	pw := float64(opts.Width - plotLeft - plotRight)
+	ph := float64(opts.Height - plotTop - plotBottom)
+	px := func(x float64) float64 { return plotLeft + (math.Log10(x)-x0)/(x1-x0)*pw }
+	py := func(y float64) float64 { return plotTop + (y1-math.Log10(y))/(y1-y0)*ph }
+
+	b := bufio.NewWriter(w)
+	fmt.Fprintf(b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="sans-serif" font-size="12">`+"\n",
+		opts.Width, opts.Height, opts.Width, opts.Height)
+	fmt.Fprintf(b, `<rect width="100%%" height="100%%" fill="white"/>`+"\n")
+	fmt.Fprintf(b, `<text x="%d" y="22" font-size="14">%s</text>`+"\n", plotLeft, html.EscapeString(opts.Title))
+
+	// Сетка и подписи декад
+	for d := x0; d <= x1; d++ {
+		x := px(math.Pow(10, d))
+		fmt.Fprintf(b, `<line x1="%.1f" y1="%d" x2="%.1f" y2="%.1f" stroke="#ddd"/>`+"\n", x, plotTop, x, plotTop+ph)
+		fmt.Fprintf(b, `<text x="%.1f" y="%.1f" text-anchor="middle">1e%d</text>`+"\n", x, plotTop+ph+18, int(d))
+	}
+	for d := y0; d <= y1; d++ {
+		y := py(math.Pow(10, d))
+		fmt.Fprintf(b, `<line x1="%d" y1="%.1f" x2="%.1f" y2="%.1f" stroke="#ddd"/>`+"\n", plotLeft, y, plotLeft+pw, y)
+		fmt.Fprintf(b, `<text x="%d" y="%.1f" text-anchor="end">1e%d</text>`+"\n", plotLeft-6, y+4, int(d))
+	}
+	fmt.Fprintf(b, `<rect x="%d" y="%d" width="%.1f" height="%.1f" fill="none" stroke="black"/>`+"\n", plotLeft, plotTop, pw, ph)
+	fmt.Fprintf(b, `<text x="%.1f" y="%d" text-anchor="middle">%s</text>`+"\n", plotLeft+pw/2, opts.Height-12, html.EscapeString(opts.XLabel))
+	fmt.Fprintf(b, `<text transform="translate(18 %.1f) rotate(-90)" text-anchor="middle">%s</text>`+"\n", plotTop+ph/2, html.EscapeString(opts.YLabel))
+
+	if opts.Front {
+		var front []byte
+		for _, p := range paretoFront(series) {
+			front = fmt.Appendf(front, "%.1f,%.1f ", px(p[0]), py(p[1]))
+		}
+		fmt.Fprintf(b, `<polyline points="%s" fill="none" stroke="#888" stroke-width="1.5" stroke-dasharray="6 4"/>`+"\n", front)
+	}
+	for k, s := range series {
+		color := seriesColors[k%len(seriesColors)]
+		var line []byte
+		for i, x := range s.X {
+			if plottable(x, s.Y[i]) {
+				line = fmt.Appendf(line, "%.1f,%.1f ", px(x), py(s.Y[i]))
+			}
+		}
+		fmt.Fprintf(b, `<polyline points="%s" fill="none" stroke="%s" stroke-width="1.5"/>`+"\n", line, color)
+		for i, x := range s.X {
+			if plottable(x, s.Y[i]) {
+				fmt.Fprintf(b, `<circle cx="%.1f" cy="%.1f" r="3.5" fill="%s"><title>%s: %s, %s</title></circle>`+"\n",
+					px(x), py(s.Y[i]), color, html.EscapeString(s.Name), strconv.FormatFloat(x, 'g', 4, 64), strconv.FormatFloat(s.Y[i], 'g', 4, 64))
+			}
+		}
+		// Легенда справа от области графика
+		ly := plotTop + 10 + 20*k
+		fmt.Fprintf(b, `<circle cx="%.1f" cy="%d" r="4" fill="%s"/>`+"\n", plotLeft+pw+16, ly, color)
+		fmt.Fprintf(b, `<text x="%.1f" y="%d">%s</text>`+"\n", plotLeft+pw+26, ly+4, html.EscapeString(s.Name))
+	}
+	if opts.Front {
+		ly := plotTop + 10 + 20*len(series)
+		fmt.Fprintf(b, `<line x1="%.1f" y1="%d" x2="%.1f" y2="%d" stroke="#888" stroke-width="1.5" stroke-dasharray="6 4"/>`+"\n", plotLeft+pw+8, ly, plotLeft+pw+22, ly)
+		fmt.Fprintf(b, `<text x="%.1f" y="%d">Pareto front</text>`+"\n", plotLeft+pw+26, ly+4)
+	}
+	fmt.Fprintln(b, "</svg>")
+	return b.Flush()
+}
+
+func plottable(x, y float64) bool {
+	return x > 0 && y > 0 && !math.IsInf(x, 0) && !math.IsInf(y, 0)
+}
+
+// Границы оси в декадах: log10 диапазона, округлённый наружу
+func decades(lo, hi float64) (float64, float64) {
+	d0, d1 := math.Floor(math.Log10(lo)), math.Ceil(math.Log10(hi))
+	if d1 == d0 {
+		d1++
+	}
+	return d0, d1
+}
+
+// Точки всех серий, для которых нет другой точки с меньшими X и Y, по
+// возрастанию X
+func paretoFront(series []Series) [][2]float64 {
+	var pts [][2]float64
+	for _, s := range series {
+		for i, x := range s.X {
+			if plottable(x, s.Y[i]) {
+				pts = append(pts, [2]float64{x, s.Y[i]})
+			}
+		}
+	}
+	slices.SortFunc(pts, func(a, b [2]float64) int {
+		if c := cmp.Compare(a[0], b[0]); c != 0 {
+			return c
+		}
+		return cmp.Compare(a[1], b[1])
+	})
+	var front [][2]float64
+	best := math.Inf(1)
+	for _, p := range pts {
+		if p[1] < best {
+			front = append(front, p)
+			best = p[1]
+		}
+	}
+	return front
+}
@@ -0,0 +1,89 @@
+package render
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"math"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParetoFront(t *testing.T) {
+	tests := []struct {
+		name   string
+		series []Series
+		want   [][2]float64
+	}{
+		{"empty", nil, nil},
+		{"one series", []Series{{X: []float64{1, 2, 3}, Y: []float64{3, 1, 2}}}, [][2]float64{{1, 3}, {2, 1}}},
+		{"across series", []Series{
+			{X: []float64{1, 4}, Y: []float64{10, 1}},
+			{X: []float64{2, 3}, Y: []float64{5, 8}},
+		}, [][2]float64{{1, 10}, {2, 5}, {4, 1}}},
+		{"ties keep the lower", []Series{{X: []float64{1, 1, 2}, Y: []float64{2, 1, 1}}}, [][2]float64{{1, 1}}},
+		{"unplottable skipped", []Series{{X: []float64{0, 1, math.Inf(1), 2}, Y: []float64{0.1, 5, 0.1, -1}}}, [][2]float64{{1, 5}}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := paretoFront(tc.series); !slices.Equal(got, tc.want) {
+				t.Errorf("front %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+// SVG разбирается как XML: по кругу на точку и на строку легенды,
+// заголовок экранирован, деления — целые декады
+func TestLogLogSVG(t *testing.T) {
+	series := []Series{
+		{Name: "CN", X: []float64{0.002, 0.01, 0.05}, Y: []float64{1e-4, 2.5e-5, 6e-6}},
+		{Name: "BTCS <θ=1>", X: []float64{0.003, -1, 0.02}, Y: []float64{3e-3, 1, 1.5e-3}},
+	}
+	var buf bytes.Buffer
+	err := LogLogSVG(&buf, series, PlotOptions{Width: 760, Height: 500, Title: "a & b", XLabel: "runtime, s", YLabel: "L2 error", Front: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var circles, polylines int
+	var texts []string
+	dec := xml.NewDecoder(&buf)
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("invalid XML: %v", err)
+		}
+		switch el := tok.(type) {
+		case xml.StartElement:
+			switch el.Name.Local {
+			case "circle":
+				circles++
+			case "polyline":
+				polylines++
+			}
+		case xml.CharData:
+			if s := strings.TrimSpace(string(el)); s != "" {
+				texts = append(texts, s)
+			}
+		}
+	}
+	// 5 точек, 2 строки легенды; линии двух серий и фронт
+	if circles != 7 || polylines != 3 {
+		t.Errorf("%d circles and %d polylines, want 7 and 3", circles, polylines)
+	}
+	for _, want := range []string{"a & b", "BTCS <θ=1>", "Pareto front", "1e-3", "1e-6", "1e-1"} {
+		if !slices.Contains(texts, want) {
+			t.Errorf("no text %q in %q", want, texts)
+		}
+	}
+
+	if err := LogLogSVG(io.Discard, []Series{{X: []float64{0}, Y: []float64{1}}}, PlotOptions{Width: 400, Height: 300}); err == nil {
+		t.Error("plot without positive points accepted")
+	}
+}
@@ -0,0 +1,296 @@
+package solver
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+
+	"heat-solver/internal/config"
+)
+
+// Принятый шаг адаптивного расчёта
+type AdaptiveStep struct {
+	// Момент конца шага
+	T  float64
+	Dt float64
+	// Оценка локальной ошибки удвоением шага (норма max)
+	Error float64
+	// Отказов перед принятием шага
+	Rejected int
+}
+
+// BTCS или CN с выбором шага по оценке удвоением шага: шаг h и два шага
+// h/2 из одного слоя, локальная ошибка — max|u_{h/2} − u_h|/(2^p − 1), p —
+// порядок по времени. Шаг принимается, если ошибка не больше tol, и
+// дальше идёт более точное решение из двух полушагов. dt — шаг выдачи
+// слоёв: шаг не больше dt и обрезается так, чтобы попасть точно в n·dt,
+// поэтому слои те же при любом tol и без интерполяции. Возвращаются слои
+// и история принятых шагов
+func SolveAdaptiveFrom(u0 []float64, nt int, dx, dt, theta, tol float64) ([][]float64, []AdaptiveStep, error) {
+	u := newGrid(nt+1, len(u0))
+	steps, err := adaptiveTheta(u0, nt, dx, dt, theta, tol, u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	return u, steps, nil
+}
+
+// Адаптивный расчёт с хранением двух слоёв выдачи; каждый слой передаётся
+// в emit
+func StreamAdaptiveFrom(u0 []float64, nt int, dx, dt, theta, tol float64, emit EmitFunc) ([]float64, []AdaptiveStep, error) {
+	u := newGrid(2, len(u0))
+	steps, err := adaptiveTheta(u0, nt, dx, dt, theta, tol, u, emit)
+	if err != nil {
+		return nil, nil, err
+	}
+	return level(u, nt), steps, nil
+}
+
+// Метод m (BTCS или CN) с шагом по оценке ошибки удвоением шага и
+// допуском tol, как при Params.Adapt: Solve и Stream считают через
+// SolveAdaptiveFrom и StreamAdaptiveFrom, а принятые шаги последнего
+// расчёта пишутся в *steps
+func AdaptiveMethod(m Method, tol float64, steps *[]AdaptiveStep) (Method, error) {
+	var theta float64
+	switch m.Name {
+	case "BTCS":
+		theta = 1
+	case "CN":
+		theta = 0.5
+	default:
+		return Method{}, &config.ValidationError{Field: "adapt", Message: fmt.Sprintf("adaptive time stepping is available for BTCS and CN, not %s", m.Name)}
+	}
+	m.Adaptive = true
+	m.solveInto = func(u0 []float64, nt int, dx, dt float64, u [][]float64) error {
+		s, err := adaptiveTheta(u0, nt, dx, dt, theta, tol, u, nil)
+		*steps = s
+		return err
+	}
+	m.Solve = func(u0 []float64, nt int, dx, dt float64) [][]float64 {
+		u := newGrid(nt+1, len(u0))
+		m.solveInto(u0, nt, dx, dt, u)
+		return u
+	}
+	m.Stream = func(u0 []float64, nt int, dx, dt float64, emit EmitFunc) ([]float64, error) {
+		last, s, err := StreamAdaptiveFrom(u0, nt, dx, dt, theta, tol, emit)
+		*steps = s
+		return last, err
+	}
+	return m, nil
+}
+
+// Состояние y хранится отдельно от слоёв выдачи u. Матрицы шага h и h/2
+// раскладываются заново, только когда h меняется; прогонка — Томаса при
+// любом SetTridiagonal. Новый шаг — 0.9·err^(−1/(p+1)) от прежнего, от 0.2
+// до 5 раз, после отказа без роста. Шаг, укороченный у момента выдачи, не
+// уменьшает предложенный для следующего
+func adaptiveTheta(u0 []float64, nt int, dx, dt, theta, tol float64, u [][]float64, emit EmitFunc) ([]AdaptiveStep, error) {
+	if !(theta >= 0.5 && theta <= 1) {
+		return nil, fmt.Errorf("adaptive stepping needs an unconditionally stable θ-scheme, got θ = %g", theta)
+	}
+	if !(tol > 0) || math.IsInf(tol, 0) {
+		return nil, fmt.Errorf("tolerance must be positive, got %g", tol)
+	}
+	order := 1.0
+	if theta == 0.5 {
+		order = 2
+	}
+	nx := len(u0) - 1
+	name := "Adaptive " + thetaName(theta)
+	slog.Info("Starting adaptive "+thetaName(theta)+" solver", "nx", nx, "nt", nt, "dx", dx, "output_dt", dt, "tol", tol)
+
+	if err := start(u, u0, emit); err != nil {
+		return nil, err
+	}
+	if nt == 0 {
+		slog.Info(name+" solver finished successfully", "accepted", 0, "rejected", 0)
+		return nil, nil
+	}
+
+	m := nx - 1
+	ws := pool64.get(4 * (nx + 1))
+	defer pool64.put(ws)
+	rows := gridRows(ws, 4, nx+1)
+	y, full, mid, half := rows[0], rows[1], rows[2], rows[3]
+	copy(y, level(u, 0))
+	a, b, c, d, sysFull := workspace(m)
+	defer pool64.put(sysFull)
+	ah, bh, ch, _, sysHalf := workspace(m)
+	defer pool64.put(sysHalf)
+	tFull, tHalf := newThomas(m), newThomas(m)
+	defer tFull.close()
+	defer tHalf.close()
+
+	// Шаг θ-схемы размера h из src в dst по разложенной матрице t
+	factored := math.NaN()
+	coef := 1 / (dx * dx)
+	step := func(t *thomas, src, dst []float64, h float64) {
+		thetaRHS(src, d, (1-theta)*h*coef)
+		t.solve(d, dst[1:nx])
+		dst[0], dst[nx] = 0, 0
+	}
+	factor := func(aa, bb, cc []float64, t *thomas, h float64) {
+		implicit := theta * h * coef
+		for i := range bb {
+			aa[i], bb[i], cc[i] = -implicit, 1+2*implicit, -implicit
+		}
+		t.factor(aa, bb, cc)
+	}
+
+	var steps []AdaptiveStep
+	var rejected, totalRejected int
+	t, h := 0.0, dt
+	minStep := 1e-12 * float64(nt) * dt
+	growth := -1 / (order + 1)
+	scale := 1 / (math.Exp2(order) - 1)
+	for next := 1; next <= nt; {
+		tOut := float64(next) * dt
+		hs := h
+		// Остаток интервала меньше двух шагов делится пополам, а не
+		// оставляет на конец узкий шаг
+		landing := t+hs >= tOut-1e-12*dt
+		if landing {
+			hs = tOut - t
+		} else if t+2*hs > tOut {
+			hs = (tOut - t) / 2
+		}
+		if hs != factored {
+			factor(a, b, c, tFull, hs)
+			factor(ah, bh, ch, tHalf, hs/2)
+			factored = hs
+		}
+		step(tFull, y, full, hs)
+		step(tHalf, y, mid, hs/2)
+		step(tHalf, mid, half, hs/2)
+		var diff float64
+		for i := 1; i < nx; i++ {
+			diff = max(diff, math.Abs(half[i]-full[i]))
+		}
+		est := diff * scale
+		ratio := est / tol
+
+		if !(ratio <= 1) {
+			rejected++
+			totalRejected++
+			fac := 0.2
+			if !math.IsNaN(ratio) {
+				fac = max(0.2, 0.9*math.Pow(ratio, growth))
+			}
+			h = hs * fac
+			slog.Debug("Adaptive step rejected", "t", t, "dt", hs, "error", est)
+			if h < minStep {
+				return nil, fmt.Errorf("%s step size %g fell below %g at t = %g", name, h, minStep, t)
+			}
+			continue
+		}
+
+		y, half = half, y
+		if landing {
+			t = tOut
+		} else {
+			t += hs
+		}
+		steps = append(steps, AdaptiveStep{T: t, Dt: hs, Error: est, Rejected: rejected})
+		slog.Debug("Adaptive step accepted", "t", t, "dt", hs, "error", est, "rejected", rejected)
+		rejected = 0
+
+		fac := 5.0
+		if ratio > 0 {
+			fac = min(5, max(0.2, 0.9*math.Pow(ratio, growth)))
+		}
+		if hs < h {
+			h = min(dt, max(h, hs*fac))
+		} else {
+			h = min(dt, hs*fac)
+		}
+		if landing {
+			row := level(u, next)
+			copy(row, y)
+			if err := emitLevel(emit, next, row); err != nil {
+				return nil, err
+			}
+			next++
+		}
+	}
+
+	hmin, hmax := math.Inf(1), 0.0
+	for _, s := range steps {
+		hmin, hmax = min(hmin, s.Dt), max(hmax, s.Dt)
+	}
+	slog.Info(name+" solver finished successfully", "accepted", len(steps), "rejected", totalRejected,
+		"min_step", hmin, "max_step", hmax)
+	return steps, nil
+}
+
+// Проверочные случаи адаптивного шага; в Verify — под именем ADAPTIVE
+var verificationAdaptive = []VerifyCase{
+	// Постоянный шаг CN с тем же числом шагов ошибается на 0.69: при
+	// r = 13 множитель моды 15 за шаг −0.69 вместо почти нуля
+	adaptiveErrorCase(0.5, 1e-6, 2e-5),
+	// Допуск на шаг, а не на весь расчёт: у BTCS ошибка растёт как √tol
+	adaptiveErrorCase(1, 1e-4, 4e-3),
+}
+
+// Моды начального профиля адаптивных случаев: sin(πx) + sin(15πx), вторая
+// затухает к t ≈ 0.005, и шаг сначала мелкий
+var adaptiveModes = []float64{1, 15}
+
+// Ошибка адаптивной θ-схемы против точного решения полудискретной задачи
+// на сетке nx = 50 по всем слоям выдачи до t = 1 через 0.05 не больше
+// bound. В подробностях — шаги и ошибка постоянного шага с тем же их числом
+func adaptiveErrorCase(theta, tol, bound float64) VerifyCase {
+	const (
+		nx   = 50
+		dt   = 0.05
+		tmax = 1.0
+	)
+	return VerifyCase{
+		Name:     fmt.Sprintf("%s sin(πx)+sin(15πx) tol=%g", thetaName(theta), tol),
+		Quantity: "max error in time",
+		Compare:  VerifyAtMost,
+		Expected: bound,
+		Measure: func(ctx context.Context, m Method) (float64, string, error) {
+			dx := 1.0 / nx
+			u0 := adaptiveSemiDiscrete(nx, dx, 0)
+			nt := int(math.Round(tmax / dt))
+			u, steps, err := SolveAdaptiveFrom(u0, nt, dx, dt, theta, tol)
+			if err != nil {
+				return math.NaN(), "", err
+			}
+			var rejected int
+			for _, s := range steps {
+				rejected += s.Rejected
+			}
+			fixed := SolveThetaFrom(u0, len(steps), dx, tmax/float64(len(steps)), 1, theta)
+			return adaptiveMaxError(u, dx, dt), fmt.Sprintf("%d steps, %d rejected; %d fixed steps: %.3g",
+				len(steps), rejected, len(steps), adaptiveMaxError(fixed, dx, tmax/float64(len(steps)))), nil
+		},
+	}
+}
+
+// Точное решение полудискретной задачи из adaptiveModes: каждая мода
+// sin(kπx) затухает как exp(−(4/dx²)·sin²(kπ·dx/2)·t)
+func adaptiveSemiDiscrete(nx int, dx, t float64) []float64 {
+	u := make([]float64, nx+1)
+	for _, k := range adaptiveModes {
+		s := math.Sin(k * math.Pi * dx / 2)
+		decay := math.Exp(-4 * s * s / (dx * dx) * t)
+		for i := range u {
+			u[i] += decay * math.Sin(k*math.Pi*float64(i)*dx)
+		}
+	}
+	return u
+}
+
+// Наибольшее отклонение слоёв u (шаг dt) от adaptiveSemiDiscrete
+func adaptiveMaxError(u [][]float64, dx, dt float64) float64 {
+	var e float64
+	for n, row := range u {
+		ref := adaptiveSemiDiscrete(len(row)-1, dx, float64(n)*dt)
+		for i, v := range row {
+			e = max(e, math.Abs(v-ref[i]))
+		}
+	}
+	return e
+}
@@ -0,0 +1,161 @@
+package solver
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// Слои выдаются ровно в n·dt: шаг, на котором слой выдаётся, кончается
+// точно в float64(n)·dt, шаги не длиннее dt (до округления укороченного
+// шага n·dt − t) и без пропусков покрывают
+// [0, nt·dt], emit получает слои по порядку, а потоковый расчёт совпадает
+// с полным побитно
+func TestAdaptiveOutputTimes(t *testing.T) {
+	const nx, nt, dt = 50, 20, 0.05
+	dx := 1.0 / nx
+	u0 := adaptiveSemiDiscrete(nx, dx, 0)
+	for _, theta := range []float64{0.5, 1} {
+		for _, tol := range []float64{1e-3, 1e-6} {
+			t.Run(fmt.Sprintf("%s/tol=%g", thetaName(theta), tol), func(t *testing.T) {
+				u, steps, err := SolveAdaptiveFrom(u0, nt, dx, dt, theta, tol)
+				if err != nil {
+					t.Fatal(err)
+				}
+				next, prev := 1, 0.0
+				for k, s := range steps {
+					if s.Dt > dt*(1+1e-12) || s.Dt <= 0 {
+						t.Fatalf("step %d: dt %g outside (0, %g]", k, s.Dt, dt)
+					}
+					if math.Abs(s.T-prev-s.Dt) > 1e-15 {
+						t.Fatalf("step %d: ends at %v, starts at %v with dt %g", k, s.T, prev, s.Dt)
+					}
+					if s.T >= float64(next)*dt-1e-12*dt {
+						if s.T != float64(next)*dt {
+							t.Fatalf("step %d ends at %v, output time %v", k, s.T, float64(next)*dt)
+						}
+						next++
+					}
+					prev = s.T
+				}
+				if next != nt+1 {
+					t.Fatalf("%d output times reached, want %d", next-1, nt)
+				}
+				var emitted []int
+				last, _, err := StreamAdaptiveFrom(u0, nt, dx, dt, theta, tol, func(n int, row []float64) error {
+					emitted = append(emitted, n)
+					if !slices.Equal(row, u[n]) {
+						t.Errorf("streamed level %d differs from the full run", n)
+					}
+					return nil
+				})
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(emitted) != nt+1 || emitted[nt] != nt || !slices.Equal(last, u[nt]) {
+					t.Errorf("emitted levels %v", emitted)
+				}
+			})
+		}
+	}
+}
+
+// История шагов на sin(πx) + sin(15πx): пока затухает вторая мода, шаг
+// мелкий, потом растёт на порядки (при большом допуске — до шага выдачи);
+// с меньшим допуском шагов больше,
+// первый шаг короче, а оценка ошибки принятого шага не больше tol
+func TestAdaptiveStepHistory(t *testing.T) {
+	const nx, nt, dt = 50, 20, 0.05
+	dx := 1.0 / nx
+	u0 := adaptiveSemiDiscrete(nx, dx, 0)
+	for _, theta := range []float64{0.5, 1} {
+		t.Run(thetaName(theta), func(t *testing.T) {
+			prevCount, prevFirst := 0, math.Inf(1)
+			for _, tol := range []float64{1e-3, 1e-5, 1e-7} {
+				_, steps, err := SolveAdaptiveFrom(u0, nt, dx, dt, theta, tol)
+				if err != nil {
+					t.Fatal(err)
+				}
+				for k, s := range steps {
+					if s.Error > tol {
+						t.Fatalf("tol=%g step %d: error %.3g accepted", tol, k, s.Error)
+					}
+				}
+				first, last := steps[0].Dt, steps[len(steps)-1].Dt
+				if first > dt/10 || last < 100*first || tol == 1e-3 && math.Abs(last-dt) > 1e-12*dt {
+					t.Errorf("tol=%g: first step %g, last %g", tol, first, last)
+				}
+				if len(steps) <= prevCount || first >= prevFirst {
+					t.Errorf("tol=%g: %d steps from %g after %d from %g", tol, len(steps), first, prevCount, prevFirst)
+				}
+				prevCount, prevFirst = len(steps), first
+			}
+		})
+	}
+}
+
+// Первая попытка шагом выдачи dt на жёсткой моде отвергается, повтор идёт
+// с меньшим шагом; допуск на пределе точности — ошибка о слишком малом
+// шаге
+func TestAdaptiveRejections(t *testing.T) {
+	const nx, nt, dt = 50, 4, 0.05
+	dx := 1.0 / nx
+	u0 := adaptiveSemiDiscrete(nx, dx, 0)
+	for _, theta := range []float64{0.5, 1} {
+		_, steps, err := SolveAdaptiveFrom(u0, nt, dx, dt, theta, 1e-6)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var rejected int
+		for _, s := range steps {
+			rejected += s.Rejected
+		}
+		if steps[0].Rejected == 0 || steps[0].Dt >= dt/5 {
+			t.Errorf("%s: first step %g after %d rejections", thetaName(theta), steps[0].Dt, steps[0].Rejected)
+		}
+		if rejected < steps[0].Rejected {
+			t.Errorf("%s: %d rejections in total", thetaName(theta), rejected)
+		}
+		if _, _, err := SolveAdaptiveFrom(u0, nt, dx, dt, theta, 1e-300); err == nil || !strings.Contains(err.Error(), "fell below") {
+			t.Errorf("%s: tol 1e-300 gave %v", thetaName(theta), err)
+		}
+	}
+}
+
+func TestAdaptiveRejects(t *testing.T) {
+	u0 := SineProfile(10, 0.1)
+	for _, tc := range []struct {
+		theta, tol float64
+	}{
+		{0, 1e-6},
+		{0.3, 1e-6},
+		{1.5, 1e-6},
+		{0.5, 0},
+		{0.5, -1},
+		{0.5, math.NaN()},
+		{0.5, math.Inf(1)},
+	} {
+		if _, _, err := SolveAdaptiveFrom(u0, 5, 0.1, 0.01, tc.theta, tc.tol); err == nil {
+			t.Errorf("θ=%g tol=%g accepted", tc.theta, tc.tol)
+		}
+	}
+	u, steps, err := SolveAdaptiveFrom(u0, 0, 0.1, 0.01, 0.5, 1e-6)
+	if err != nil || len(steps) != 0 || !slices.Equal(u[0][1:10], u0[1:10]) {
+		t.Errorf("nt = 0: %d steps, %v", len(steps), err)
+	}
+	var history []AdaptiveStep
+	for _, name := range []string{"FTCS", "RK4", "BDF2"} {
+		if _, err := AdaptiveMethod(mustLookup(t, name), 1e-6, &history); err == nil {
+			t.Errorf("%s accepted", name)
+		}
+	}
+	m, err := AdaptiveMethod(mustLookup(t, "CN"), 1e-6, &history)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Stream(u0, 5, 0.1, 0.01, nil); err != nil || len(history) == 0 || history[len(history)-1].T != 5*0.01 {
+		t.Errorf("method stream: %d steps, %v", len(history), err)
+	}
+}
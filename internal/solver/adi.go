@@ -0,0 +1,75 @@
+package solver
+
+import "log/slog"
+
+// ADI Писмена–Рэкфорда на квадрате [0, 1]² с краями из слоёв (нулевыми для
+// sin(πx)·sin(πy)): полшага неявно по x и явно по y, затем наоборот,
+//
+//	(1 − A)·u* = (1 + B)·u^n,  (1 − B)·u^{n+1} = (1 + A)·u*,
+//
+// A = (r_x/2)·δ_x², B = (r_y/2)·δ_y². Каждый полушаг — прогонки по
+// линиям сетки. Схема безусловно устойчива и второго порядка по dt, dx и
+// dy. Слои — как в Solve2DFTCS
+func Solve2DADI(nx, ny, nt int, dx, dy, dt, alpha float64) [][]float64 {
+	u := newGrid(nt+1, (nx+1)*(ny+1))
+	adi2D(SineProfile2D(nx, ny, dx, dy), nx, ny, nt, dx, dy, dt, alpha, u, nil)
+	return u
+}
+
+// ADI с заданным начальным слоем u0 и хранением двух слоёв; каждый слой
+// передаётся в emit
+func Stream2DADIFrom(u0 []float64, nx, ny, nt int, dx, dy, dt, alpha float64, emit EmitFunc) ([]float64, error) {
+	u := newGrid(2, len(u0))
+	if err := adi2D(u0, nx, ny, nt, dx, dy, dt, alpha, u, emit); err != nil {
+		return nil, err
+	}
+	return level(u, nt), nil
+}
+
+// Края u* — adiHalfEdges, края u^{n+1} заданы
+func adi2D(u0 []float64, nx, ny, nt int, dx, dy, dt, alpha float64, u [][]float64, emit EmitFunc) error {
+	rx, ry := alpha*dt/(dx*dx), alpha*dt/(dy*dy)
+	hx, hy := rx/2, ry/2
+	slog.Info("Starting 2D ADI solver", "nx", nx, "ny", ny, "nt", nt, "dx", dx, "dy", dy, "dt", dt, "r_x", rx, "r_y", ry)
+
+	row := level(u, 0)
+	copy(row, u0)
+	zeroEdges2D(row, nx, ny)
+	if err := emitLevel(emit, 0, row); err != nil {
+		return err
+	}
+
+	half := pool64.get((nx + 1) * (ny + 1))
+	defer pool64.put(half)
+	lines := newLineSolver(nx, ny, hx, hy)
+	defer lines.close()
+
+	for k := 0; k < nt; k++ {
+		cur, next := level(u, k), level(u, k+1)
+		zeroEdges2D(next, nx, ny)
+		adiHalfEdges(cur, next, half, nx, ny, hy)
+		lines.sweepX(cur, half, hy)
+		lines.sweepY(half, next, hx)
+		if err := emitLevel(emit, k+1, next); err != nil {
+			return err
+		}
+	}
+
+	slog.Info("2D ADI solver finished successfully")
+	return nil
+}
+
+// Промежуточный слой u* на краях x = 0 и x = 1 (j = 1..ny−1). Сложение
+// полушагов даёт u* = ½·((1 + B)·u^n + (1 − B)·u^{n+1}), и на краях, где
+// u^n и u^{n+1} заданы, u* берётся отсюда, а не из u^n или u^{n+1}:
+// иначе при зависящих от времени краях схема теряет второй порядок
+func adiHalfEdges(cur, next, half []float64, nx, ny int, hy float64) {
+	w := nx + 1
+	for _, i := range [2]int{0, nx} {
+		for j := 1; j < ny; j++ {
+			c := j*w + i
+			half[c] = ((cur[c] + hy*(cur[c-w]-2*cur[c]+cur[c+w])) +
+				(next[c] - hy*(next[c-w]-2*next[c]+next[c+w]))) / 2
+		}
+	}
+}
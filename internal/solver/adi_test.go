@@ -0,0 +1,91 @@
+package solver
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+// sin(πx)·sin(πy) — собственный вектор обоих полушагов: за шаг он
+// умножается на (1−a)(1−b)/((1+a)(1+b)), a = 2r_x·sin²(π·dx/2), b — то
+// же по y. Проверяется и прямоугольная сетка nx ≠ ny, и r далеко за
+// пределом явной схемы
+func TestADISineMode(t *testing.T) {
+	tests := []struct {
+		nx, ny int
+		dt     float64
+	}{
+		{16, 16, 5.0 / 256},
+		{12, 20, 0.01},
+		{8, 30, 0.2},
+	}
+	for _, tc := range tests {
+		t.Run(fmt.Sprintf("%dx%d/dt=%g", tc.nx, tc.ny, tc.dt), func(t *testing.T) {
+			const nt = 10
+			dx, dy := 1/float64(tc.nx), 1/float64(tc.ny)
+			sx, sy := math.Sin(math.Pi*dx/2), math.Sin(math.Pi*dy/2)
+			a, b := 2*tc.dt/(dx*dx)*sx*sx, 2*tc.dt/(dy*dy)*sy*sy
+			g := (1 - a) * (1 - b) / ((1 + a) * (1 + b))
+			u := Solve2DADI(tc.nx, tc.ny, nt, dx, dy, tc.dt, 1)
+			u0 := u[0]
+			for n := range u {
+				scale := math.Pow(g, float64(n))
+				for c, v := range u[n] {
+					if math.Abs(v-scale*u0[c]) > 1e-13 {
+						t.Fatalf("level %d, node %d: %v, want G^n·u0 = %v", n, c, v, scale*u0[c])
+					}
+				}
+			}
+			last, err := Stream2DADIFrom(SineProfile2D(tc.nx, tc.ny, dx, dy), tc.nx, tc.ny, nt, dx, dy, tc.dt, 1, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for c := range last {
+				if last[c] != u[nt][c] {
+					t.Fatalf("streamed node %d = %v, full run %v", c, last[c], u[nt][c])
+				}
+			}
+		})
+	}
+}
+
+// Регрессия при r = 5: 32×32 с профилем, который не собственный вектор
+// схемы, против эталона 128×128 с мелким шагом. Порядок — при
+// измельчении dx и dt вдвое вместе (r растёт с 5 до 20): при постоянном r
+// вклады dx² и dt² разных знаков частично гасят друг друга
+func TestADIReference(t *testing.T) {
+	const n, fine, tmax = 32, 128, 0.1953125
+	h, hf := 1.0/n, 1.0/fine
+	dt := 5 * h * h
+	last, err := Stream2DADIFrom(squarePolyProfile(n), n, n, int(math.Round(tmax/dt)), h, h, dt, 1, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref, err := Stream2DADIFrom(squarePolyProfile(fine), fine, fine, 400, hf, hf, tmax/400, 1, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var linf float64
+	for j := 0; j <= n; j++ {
+		for i := 0; i <= n; i++ {
+			linf = max(linf, math.Abs(last[j*(n+1)+i]-ref[4*j*(fine+1)+4*i]))
+		}
+	}
+	if linf > 2e-4 {
+		t.Errorf("max difference from the %d×%d reference %.3g at r = 5", fine, fine, linf)
+	}
+
+	var prev float64
+	for k, m := range []int{16, 32, 64} {
+		l2, err := squareSineError(Stream2DADIFrom, m, 5.0/float64(16*m), tmax)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if k > 0 {
+			if order := math.Log2(prev / l2); math.Abs(order-2) > 0.15 {
+				t.Errorf("n=%d: error %.3g → %.3g, observed order %.2f", m, prev, l2, order)
+			}
+		}
+		prev = l2
+	}
+}
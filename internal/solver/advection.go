@@ -0,0 +1,178 @@
+package solver
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+
+	"heat-solver/internal/mathutils"
+)
+
+// Уравнение конвекции–диффузии u_t + v·u_x = α·u_xx на [0, 1] с
+// краевыми значениями Дирихле left(t) и right(t). При θ = 0 шаг явный, с
+// разностью против потока: u_i += r·δ²u_i − c·(u_i − u_{i−1}) при v > 0
+// (c = v·dt/dx, при v < 0 — правая разность); первый порядок по x, зато
+// без осцилляций. При θ > 0 конвекция центральная и неявная с тем же
+// весом θ, что и диффузия: система остаётся трёхдиагональной,
+// (−θ(r + c/2), 1 + 2θr, −θ(r − c/2)), порядок по x — второй
+func SolveAdvectionDiffusion(nx, nt int, dx, dt, alpha, v, theta float64) [][]float64 {
+	wave := mathutils.TravellingWave{Alpha: alpha, V: v}
+	u := newGrid(nt+1, nx+1)
+	advectionDiffusion(SineProfile(nx, dx), nt, dx, dt, alpha, v, theta, waveEdge(wave, 0), waveEdge(wave, 1), u, nil)
+	return u
+}
+
+// Конвекция–диффузия с заданным начальным профилем u0 и краевыми
+// значениями; хранятся два слоя, каждый слой передаётся в emit.
+// u0[0] и u0[nx] заменяются на left(0) и right(0)
+func StreamAdvectionDiffusionFrom(u0 []float64, nt int, dx, dt, alpha, v, theta float64, left, right func(t float64) float64, emit EmitFunc) ([]float64, error) {
+	u := newGrid(2, len(u0))
+	if err := advectionDiffusion(u0, nt, dx, dt, alpha, v, theta, left, right, u, emit); err != nil {
+		return nil, err
+	}
+	return level(u, nt), nil
+}
+
+// Краевое значение точной бегущей волны в точке x
+func waveEdge(w mathutils.TravellingWave, x float64) func(t float64) float64 {
+	return func(t float64) float64 { return w.Eval(x, t) }
+}
+
+// Безразмерные числа шага конвекции–диффузии
+type AdvectionNumbers struct {
+	// Число диффузии r = α·dt/dx²
+	Diffusion float64
+	// Число Куранта c = v·dt/dx
+	Courant float64
+	// Сеточное число Пекле Pe = |v|·dx/α = |c|/r
+	Peclet float64
+}
+
+func NewAdvectionNumbers(dx, dt, alpha, v float64) AdvectionNumbers {
+	n := AdvectionNumbers{Diffusion: alpha * dt / (dx * dx), Courant: v * dt / dx}
+	if alpha > 0 {
+		n.Peclet = math.Abs(v) * dx / alpha
+	} else {
+		n.Peclet = math.Inf(1)
+	}
+	return n
+}
+
+// Числа шага в лог и предупреждения: явная схема против потока устойчива
+// и монотонна при 2r + |c| ≤ 1; для 0 < θ < ½ проверяется только
+// диффузионный предел, как у θ-схемы. При центральной конвекции и Pe > 2
+// решение может осциллировать, хотя схема устойчива
+func (n AdvectionNumbers) Check(name string, theta float64) {
+	slog.Info("Stability numbers", "scheme", name, "diffusion_number", n.Diffusion, "courant", n.Courant, "cell_peclet", n.Peclet)
+	switch {
+	case theta == 0 && 2*n.Diffusion+math.Abs(n.Courant) > 1:
+		slog.Warn(name+" may be unstable", "diffusion_number", n.Diffusion, "courant", n.Courant, "limit", "2r + |c| ≤ 1")
+	case theta > 0 && theta < 0.5:
+		warnUnstable(name, n.Diffusion, thetaMaxR(theta))
+	}
+	if theta > 0 && n.Peclet > 2 {
+		slog.Warn(name+" may oscillate: central convection with cell Péclet number above 2", "cell_peclet", n.Peclet)
+	}
+}
+
+func advectionDiffusion(u0 []float64, nt int, dx, dt, alpha, v, theta float64, left, right func(float64) float64, u [][]float64, emit EmitFunc) error {
+	nx := len(u0) - 1
+	nums := NewAdvectionNumbers(dx, dt, alpha, v)
+	r, c := nums.Diffusion, nums.Courant
+	name := "advection–diffusion " + thetaName(theta)
+	nums.Check(name, theta)
+	slog.Info("Starting "+name+" solver", "nx", nx, "nt", nt, "dx", dx, "dt", dt, "velocity", v)
+
+	row := level(u, 0)
+	copy(row, u0)
+	row[0], row[nx] = left(0), right(0)
+	if err := emitLevel(emit, 0, row); err != nil {
+		return err
+	}
+
+	// Веса соседей в L·dt: r + c/2 слева, r − c/2 справа
+	lo, hi := r+c/2, r-c/2
+	var tri tridiagSolver
+	var d []float64
+	if theta > 0 {
+		a, b, cc, dd, ws := workspace(nx - 1)
+		defer pool64.put(ws)
+		for i := range b {
+			a[i], b[i], cc[i] = -theta*lo, 1+2*theta*r, -theta*hi
+		}
+		tri = newTridiag(a, b, cc)
+		defer tri.close()
+		d = dd
+	}
+	// Явная разность против потока: вес соседа выше по потоку
+	up, down := r+math.Max(c, 0), r-math.Min(c, 0)
+
+	for n := 0; n < nt; n++ {
+		cur, next := level(u, n), level(u, n+1)
+		t := float64(n+1) * dt
+		next[0], next[nx] = left(t), right(t)
+		if tri == nil {
+			for i := 1; i < nx; i++ {
+				next[i] = cur[i] + up*cur[i-1] - (up+down)*cur[i] + down*cur[i+1]
+			}
+		} else {
+			w := 1 - theta
+			for i := 1; i < nx; i++ {
+				d[i-1] = cur[i] + w*(lo*cur[i-1]-2*r*cur[i]+hi*cur[i+1])
+			}
+			d[0] += theta * lo * next[0]
+			d[nx-2] += theta * hi * next[nx]
+			tri.solve(d, next[1:nx])
+			if err := tridiagErr(tri); err != nil {
+				return err
+			}
+		}
+		if err := emitLevel(emit, n+1, next); err != nil {
+			return err
+		}
+	}
+
+	slog.Info(name + " solver finished successfully")
+	return nil
+}
+
+// Проверочные случаи конвекции–диффузии; в Verify — под именем ADVECTION
+var verificationAdvection = []VerifyCase{
+	advectionOrderCase(0, 1, 0.05, 0.0005, 0.2, 1, 0.15),
+	advectionOrderCase(0.5, 1, 0.05, 0.001, 0.2, 2, 0.1),
+	advectionOrderCase(1, 1, 0.05, 0.001, 0.2, 2, 0.1),
+}
+
+// Наблюдаемый порядок по dx на бегущей волне exp(−π²t)·sin(π(x − vt))
+// за два деления dx пополам. dt делится на 4: у CN ошибка по времени
+// O(dt²) убывает вместе с пространственной, у явной схемы шаг остаётся
+// устойчивым (r постоянно, c убывает), у BTCS O(dt) убывает быстрее
+// пространственной ошибки
+func advectionOrderCase(theta, v, dx, dt, tmax, order, tol float64) VerifyCase {
+	return VerifyCase{
+		Name:      fmt.Sprintf("%s v=%g order from dx=%g dt=%g", thetaName(theta), v, dx, dt),
+		Quantity:  "observed order",
+		Compare:   VerifyNear,
+		Expected:  order,
+		Tolerance: tol,
+		Measure: func(ctx context.Context, _ Method) (float64, string, error) {
+			wave := mathutils.TravellingWave{Alpha: 1, V: v}
+			var errs [3]float64
+			for k := range errs {
+				if err := ctx.Err(); err != nil {
+					return math.NaN(), "", err
+				}
+				h, tau := dx/math.Exp2(float64(k)), dt/math.Pow(4, float64(k))
+				nx, nt := int(math.Round(1/h)), int(math.Round(tmax/tau))
+				last, err := StreamAdvectionDiffusionFrom(SineProfile(nx, h), nt, h, tau, 1, v, theta, waveEdge(wave, 0), waveEdge(wave, 1), nil)
+				if err != nil {
+					return math.NaN(), "", err
+				}
+				errs[k], _ = mathutils.LevelErrors(last, h, float64(nt)*tau, wave)
+			}
+			first, second := math.Log2(errs[0]/errs[1]), math.Log2(errs[1]/errs[2])
+			return second, fmt.Sprintf("errors %.3g, %.3g, %.3g; orders %.3f, %.3f", errs[0], errs[1], errs[2], first, second), nil
+		},
+	}
+}
@@ -0,0 +1,105 @@
+package solver
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+
+	"heat-solver/internal/mathutils"
+)
+
+// Ошибка L2 против бегущей волны exp(−π²t)·sin(π(x − vt)) при
+// измельчении dx вдвое и dt вчетверо: первый порядок у явной схемы против
+// потока, второй у центральных BTCS и CN, при v обоих знаков
+func TestAdvectionOrder(t *testing.T) {
+	const dx0, dt0, tmax = 0.05, 0.0005, 0.2
+	tests := []struct {
+		theta, v, order float64
+	}{
+		{0, 1, 1},
+		{0, -2, 1},
+		{0.5, 1, 2},
+		{0.5, -2, 2},
+		{1, 1, 2},
+	}
+	for _, tc := range tests {
+		t.Run(fmt.Sprintf("%s/v=%g", thetaName(tc.theta), tc.v), func(t *testing.T) {
+			wave := mathutils.TravellingWave{Alpha: 1, V: tc.v}
+			var errs [3]float64
+			for k := range errs {
+				dx, dt := dx0/math.Exp2(float64(k)), dt0/math.Pow(4, float64(k))
+				nx, nt := int(math.Round(1/dx)), int(math.Round(tmax/dt))
+				last, err := StreamAdvectionDiffusionFrom(SineProfile(nx, dx), nt, dx, dt, 1, tc.v, tc.theta, waveEdge(wave, 0), waveEdge(wave, 1), nil)
+				if err != nil {
+					t.Fatal(err)
+				}
+				errs[k], _ = mathutils.LevelErrors(last, dx, float64(nt)*dt, wave)
+			}
+			if order := math.Log2(errs[1] / errs[2]); math.Abs(order-tc.order) > 0.15 {
+				t.Errorf("L2 errors %.3g, %.3g, %.3g: order %.3f, want %g", errs[0], errs[1], errs[2], order, tc.order)
+			}
+		})
+	}
+}
+
+// При v = 0 шаги совпадают с FTCS, BTCS и CN чистой диффузии, а полный
+// расчёт — с потоковым
+func TestAdvectionZeroVelocity(t *testing.T) {
+	const nx, nt, dx = 20, 30, 0.05
+	for _, tc := range []struct {
+		method string
+		theta  float64
+		dt     float64
+	}{
+		{"FTCS", 0, 0.001},
+		{"BTCS", 1, 0.01},
+		{"CN", 0.5, 0.01},
+	} {
+		want := stencilReference(tc.method, SineProfile(nx, dx), nt, dx, tc.dt)
+		got := SolveAdvectionDiffusion(nx, nt, dx, tc.dt, 1, 0, tc.theta)
+		for n := range got {
+			for i := range got[n] {
+				if math.Abs(got[n][i]-want[n][i]) > 1e-14 {
+					t.Fatalf("%s level %d, node %d: %v, diffusion %v", tc.method, n, i, got[n][i], want[n][i])
+				}
+			}
+		}
+	}
+}
+
+// Числа шага и предупреждения: у явной схемы предел 2r + |c| ≤ 1, у
+// центральных — осцилляции при сеточном Пекле выше 2
+func TestAdvectionNumbers(t *testing.T) {
+	n := NewAdvectionNumbers(0.1, 0.002, 0.5, -3)
+	if math.Abs(n.Diffusion-0.1) > 1e-15 || math.Abs(n.Courant+0.06) > 1e-15 || math.Abs(n.Peclet-0.6) > 1e-15 {
+		t.Errorf("numbers %+v", n)
+	}
+	if pe := NewAdvectionNumbers(0.1, 0.002, 0, 1).Peclet; !math.IsInf(pe, 1) {
+		t.Errorf("Pe = %v without diffusion", pe)
+	}
+	tests := []struct {
+		name      string
+		dx, dt, v float64
+		theta     float64
+		warns     string
+	}{
+		{"upwind stable", 0.05, 0.001, 5, 0, ""},
+		{"upwind at the limit", 0.05, 0.001, 10, 0, ""},
+		{"upwind unstable", 0.05, 0.001, 20, 0, "may be unstable"},
+		{"upwind high Péclet", 0.05, 0.0001, 100, 0, ""},
+		{"central low Péclet", 0.05, 0.01, 20, 0.5, ""},
+		{"central high Péclet", 0.05, 0.01, 100, 1, "may oscillate"},
+		{"theta below ½", 0.05, 0.003, 1, 0.25, "may be unstable"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			warnings := captureWarnings(t)
+			NewAdvectionNumbers(tc.dx, tc.dt, 1, tc.v).Check("advection–diffusion", tc.theta)
+			got := warnings.String()
+			if tc.warns == "" && got != "" || tc.warns != "" && !strings.Contains(got, tc.warns) {
+				t.Errorf("warnings %q, want %q", got, tc.warns)
+			}
+		})
+	}
+}
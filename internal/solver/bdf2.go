@@ -0,0 +1,117 @@
+package solver
+
+import (
+	"log/slog"
+	"math"
+)
+
+// BDF2 (неявная трёхслойная схема второго порядка по времени):
+// (3u^{n+1} − 4u^n + u^{n−1})/(2dt) = δ²u^{n+1}/dx². Устойчива при любом
+// r = dt/dx² и, в отличие от CN, гасит высокие моды (G → 0 при r → ∞),
+// поэтому не даёт звона на разрывных начальных данных
+func SolveBDF2(nx, nt int, dx, dt float64) [][]float64 {
+	return SolveBDF2From(SineProfile(nx, dx), nt, dx, dt)
+}
+
+// BDF2 с заданным начальным профилем u0 (nx+1 значений)
+func SolveBDF2From(u0 []float64, nt int, dx, dt float64) [][]float64 {
+	u := newGrid(nt+1, len(u0))
+	bdf2(u0, nt, dx, dt, u, nil)
+	return u
+}
+
+// BDF2 с хранением двух слоёв; каждый слой передаётся в emit. Правая
+// часть собирается из u^n и u^{n−1} до решения системы, поэтому u^{n+1}
+// пишется на место u^{n−1}
+func StreamBDF2From(u0 []float64, nt int, dx, dt float64, emit EmitFunc) ([]float64, error) {
+	u := newGrid(2, len(u0))
+	if err := bdf2(u0, nt, dx, dt, u, emit); err != nil {
+		return nil, err
+	}
+	return level(u, nt), nil
+}
+
+// Слой 1 — шаг BTCS, дальше на шаге решается система (−2r, 3+4r, −2r)
+// с правой частью 4u^n − u^{n−1}
+func bdf2(u0 []float64, nt int, dx, dt float64, u [][]float64, emit EmitFunc) error {
+	nx := len(u0) - 1
+	r := dt / (dx * dx)
+	slog.Info("Starting BDF2 solver", "nx", nx, "nt", nt, "dx", dx, "dt", dt, "r", r)
+
+	if err := start(u, u0, emit); err != nil {
+		return err
+	}
+	if nt == 0 {
+		slog.Info("BDF2 solver finished successfully")
+		return nil
+	}
+
+	a, b, c, d, ws := workspace(nx - 1)
+	defer pool64.put(ws)
+
+	// Стартовый шаг BTCS: своя матрица, разложение освобождается до
+	// того, как a, b, c заполняются коэффициентами BDF2
+	for i := 0; i < nx-1; i++ {
+		a[i], b[i], c[i] = -r, 1+2*r, -r
+	}
+	cur, next := level(u, 0), level(u, 1)
+	next[0], next[nx] = 0, 0
+	copy(d, cur[1:nx])
+	d[0] += r * next[0]
+	d[nx-2] += r * next[nx]
+	boot := newTridiag(a, b, c)
+	boot.solve(d, next[1:nx])
+	err := tridiagErr(boot)
+	boot.close()
+	if err != nil {
+		return err
+	}
+	if err := emitLevel(emit, 1, next); err != nil {
+		return err
+	}
+
+	for i := 0; i < nx-1; i++ {
+		a[i], b[i], c[i] = -2*r, 3+4*r, -2*r
+	}
+	tri := newTridiag(a, b, c)
+	defer tri.close()
+
+	for n := 1; n < nt; n++ {
+		prev, cur, next := level(u, n-1), level(u, n), level(u, n+1)
+		bdf2RHS(prev, cur, d)
+		next[0], next[nx] = 0, 0
+		d[0] += 2 * r * next[0]
+		d[nx-2] += 2 * r * next[nx]
+
+		tri.solve(d, next[1:nx])
+		if err := tridiagErr(tri); err != nil {
+			return err
+		}
+		if err := emitLevel(emit, n+1, next); err != nil {
+			return err
+		}
+	}
+
+	slog.Info("BDF2 solver finished successfully")
+	return nil
+}
+
+// Правая часть для внутренних узлов: d[i] = 4·cur[i+1] − prev[i+1]
+func bdf2RHS(prev, cur, d []float64) {
+	old, mid := prev[1:len(d)+1], cur[1:len(d)+1]
+	for i := range d {
+		d[i] = 4*mid[i] - old[i]
+	}
+}
+
+// G(θ) BDF2: корни (3+2q)·G² − 4G + 1 = 0 с q = 4r·sin²(θ/2). Возвращается
+// корень с большим |G|; при комплексных корнях (q > ½) — их общий модуль
+// 1/√(3+2q)
+func bdf2Amplification(r, theta float64) float64 {
+	s := math.Sin(theta / 2)
+	q := 4 * r * s * s
+	if disc := 1 - 2*q; disc >= 0 {
+		return (2 + math.Sqrt(disc)) / (3 + 2*q)
+	}
+	return 1 / math.Sqrt(3+2*q)
+}
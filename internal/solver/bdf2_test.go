@@ -0,0 +1,89 @@
+package solver
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"heat-solver/internal/mathutils"
+)
+
+// При мелком dx ошибка BDF2 против точного решения убывает как dt²;
+// BTCS на тех же шагах — как dt
+func TestBDF2TimeOrder(t *testing.T) {
+	const nx, tmax = 1000, 0.4
+	dx := 1.0 / nx
+	exact := mathutils.Analytical{Alpha: 1}
+	tests := []struct {
+		name  string
+		solve SolveFunc
+		order float64
+	}{
+		{"BDF2", SolveBDF2From, 2},
+		{"BTCS", SolveBTCSFrom, 1},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var prev float64
+			for k, dt := range []float64{0.02, 0.01, 0.005, 0.0025} {
+				nt := int(math.Round(tmax / dt))
+				u := tc.solve(SineProfile(nx, dx), nt, dx, dt)
+				l2, _ := mathutils.LevelErrors(u[nt], dx, tmax, exact)
+				if k > 0 {
+					if order := math.Log2(prev / l2); math.Abs(order-tc.order) > 0.15 {
+						t.Errorf("dt=%g: error %.3g → %.3g, observed order %.2f, want %g", dt, prev, l2, order, tc.order)
+					}
+				}
+				prev = l2
+			}
+		})
+	}
+}
+
+// Первый шаг — BTCS; поток заканчивается последним слоем полного расчёта
+func TestBDF2Bootstrap(t *testing.T) {
+	for _, nx := range []int{2, 3, 40} {
+		t.Run(fmt.Sprintf("nx=%d", nx), func(t *testing.T) {
+			dx := 1.0 / float64(nx)
+			dt := 3 * dx * dx
+			u0 := SineProfile(nx, dx)
+			bdf := SolveBDF2From(u0, 10, dx, dt)
+			btcs := SolveBTCSFrom(u0, 1, dx, dt)
+			for i := range btcs[1] {
+				if bdf[1][i] != btcs[1][i] {
+					t.Fatalf("u[1][%d] = %v, BTCS step gives %v", i, bdf[1][i], btcs[1][i])
+				}
+			}
+			last, err := StreamBDF2From(u0, 10, dx, dt, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i := range last {
+				if last[i] != bdf[10][i] {
+					t.Fatalf("streamed u[%d] = %v, full run %v", i, last[i], bdf[10][i])
+				}
+			}
+		})
+	}
+}
+
+// Множитель BDF2 гасит высокие моды при большом r (у CN |G| → 1)
+func TestBDF2Amplification(t *testing.T) {
+	tests := []struct {
+		r, theta, want float64
+	}{
+		{0.1, 0, 1},
+		// q = ½: двойной корень 2/(3+1)
+		{0.125, math.Pi, 0.5},
+		{1e6, math.Pi, 1 / math.Sqrt(3+8e6)},
+	}
+	for _, tc := range tests {
+		if g := bdf2Amplification(tc.r, tc.theta); math.Abs(g-tc.want) > 1e-15 {
+			t.Errorf("G(r=%g, θ=%g) = %v, want %v", tc.r, tc.theta, g, tc.want)
+		}
+	}
+	cn := mustLookup(t, "CN").Amplification
+	if g, c := bdf2Amplification(1e4, math.Pi), cn(1e4, math.Pi); math.Abs(g) > 0.01 || math.Abs(c) < 0.99 {
+		t.Errorf("at r = 1e4: |G| BDF2 %v, CN %v; want BDF2 damping and CN near 1", g, c)
+	}
+}
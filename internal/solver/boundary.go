@@ -0,0 +1,389 @@
+package solver
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"strings"
+
+	"heat-solver/internal/mathutils"
+)
+
+// u_t = α·u_xx на [0, 1] с условием Дирихле u = g(t), Неймана
+// ∂u/∂x = g(t) или Робина −k·∂u/∂n = h·(u − u_amb) на каждом конце
+// (mathutils.Boundary). Условия Неймана и Робина — через фиктивный узел:
+// u_ghost = u_in + 2dx·∂u/∂n, u_in — сосед края внутри, n — внешняя
+// нормаль (слева ∂u/∂n = −u_x). Центральная разность на краю второго
+// порядка, а односторонняя (u_1 − u_0)/dx — только первого, и ошибка от
+// неё расходилась бы на всю область. С ∂u/∂n = q(t) − β·u_b (у условия
+// Неймана β = 0 и q = ±g, у условия Робина β = h/k и q = β·u_amb) край
+// становится обычной неизвестной со строкой
+//
+//	(1 + θr(2 + 2dx·β))·u_b − 2θr·u_in = u_b + (1−θ)r·(2u_in − (2 + 2dx·β)·u_b) + 2r·dx·q̄,
+//
+// q̄ = θ·q^{n+1} + (1−θ)·q^n, справа — значения слоя n. При θ = 0 это
+// явный шаг FTCS, при θ = 1 — BTCS, при θ = ½ — CN
+
+// Первая мода отрезка с однородными условиями вида left и right
+// (mathutils.BoundaryMode) на узлах x_i = i·dx; если на одном из концов
+// условие Робина — единица (остывающая пластина, mathutils.RobinSlab).
+// Под неоднородные g профиль не подгоняется: несовместимость с ними
+// находит CheckCompatibility, а снимает SmoothStart
+func BoundaryProfile(nx int, dx float64, left, right mathutils.Boundary) []float64 {
+	u0 := make([]float64, nx+1)
+	if left.Kind == mathutils.BCRobin || right.Kind == mathutils.BCRobin {
+		for i := range u0 {
+			u0[i] = 1
+		}
+		return u0
+	}
+	mathutils.BoundaryMode{Alpha: 1, LeftNeumann: left.IsNeumann(), RightNeumann: right.IsNeumann()}.Grid(nx, dx)(u0, 0)
+	return u0
+}
+
+// Край с фиктивным узлом: ∂u/∂n = q(t) − β·u_b, outward — знак внешней
+// нормали (−1 слева, +1 справа)
+func ghostEnd(b mathutils.Boundary, outward float64) (beta float64, q func(t float64) float64) {
+	if b.Kind == mathutils.BCRobin {
+		beta = b.H / b.K
+		return beta, func(float64) float64 { return beta * b.Ambient }
+	}
+	return 0, func(t float64) float64 { return outward * b.Value(t) }
+}
+
+// θ-схема с условиями left и right на концах и заданным профилем u0
+// (nx+1 значений); первые startup шагов — по два полушага BTCS (старт
+// Раннахера, 0 — без него). Хранятся два слоя, каждый слой передаётся в
+// emit
+func StreamBoundaryFrom(u0 []float64, nt int, dx, dt, alpha, theta float64, startup int, left, right mathutils.Boundary, emit EmitFunc) ([]float64, error) {
+	u := newGrid(2, len(u0))
+	if err := boundaryScheme(u0, nt, dx, dt, alpha, theta, startup, left, right, u, emit); err != nil {
+		return nil, err
+	}
+	return level(u, nt), nil
+}
+
+// Неизвестные — узлы от lo до hi: края с условиями Неймана и Робина
+// входят в них, край Дирихле берётся из g. Матрица постоянна и
+// раскладывается один раз; у строк краёв она несимметрична, но с
+// диагональным преобладанием, и прогонка устойчива. Первые startup шагов
+// — по два полушага BTCS (старт Раннахера) со своей матрицей
+func boundaryScheme(u0 []float64, nt int, dx, dt, alpha, theta float64, startup int, left, right mathutils.Boundary, u [][]float64, emit EmitFunc) error {
+	nx := len(u0) - 1
+	if nx < 2 {
+		return fmt.Errorf("boundary scheme needs at least 2 intervals, got %d", nx)
+	}
+	r := alpha * dt / (dx * dx)
+	name := thetaName(theta)
+	rows := newBoundaryRows(nx, dx, left, right)
+	// С фиктивными узлами Неймана спектр δ² остаётся в [−4/dx², 0], и
+	// предел r тот же. Условие Робина добавляет 2dx·β к диагонали строки
+	// края, и по Гершгорину предел сжимается в (4 + 2dx·β)/4 раза
+	warnUnstable(name, r, thetaMaxR(theta)*4/(4+2*dx*max(rows.betaLeft, rows.betaRight)))
+	slog.Info("Starting "+name+" solver", "nx", nx, "nt", nt, "dx", dx, "dt", dt, "r", r, "bc_left", left.String(), "bc_right", right.String())
+	startup = min(startup, nt)
+	if startup > 0 {
+		slog.Info("Rannacher startup", "btcs_half_steps", 2*startup)
+	}
+
+	// Не start: края из u0 не обнуляются
+	row := level(u, 0)
+	copy(row, u0)
+	if err := emitLevel(emit, 0, row); err != nil {
+		return err
+	}
+
+	d := pool64.get(rows.n)
+	defer pool64.put(d)
+	tri, release := rows.factor(theta, r)
+	defer release()
+	var half *thomas
+	var mid []float64
+	if startup > 0 {
+		var releaseHalf func()
+		half, releaseHalf = rows.factor(1, r/2)
+		defer releaseHalf()
+		mid = pool64.get(nx + 1)
+		defer pool64.put(mid)
+	}
+
+	for step := 0; step < nt; step++ {
+		cur, next := level(u, step), level(u, step+1)
+		t := float64(step) * dt
+		if step < startup {
+			rows.advance(half, 1, r/2, cur, mid, d, t, t+dt/2)
+			rows.advance(half, 1, r/2, mid, next, d, t+dt/2, t+dt)
+		} else {
+			rows.advance(tri, theta, r, cur, next, d, t, t+dt)
+		}
+		if err := emitLevel(emit, step+1, next); err != nil {
+			return err
+		}
+	}
+
+	slog.Info(name + " solver finished successfully")
+	return nil
+}
+
+// Строки схемы с краевыми условиями: неизвестные — узлы lo..hi (n штук),
+// у краёв с фиктивным узлом ∂u/∂n = q(t) − β·u_b (ghostEnd)
+type boundaryRows struct {
+	nx, lo, hi, n       int
+	dx                  float64
+	left, right         mathutils.Boundary
+	betaLeft, betaRight float64
+	qLeft, qRight       func(t float64) float64
+}
+
+func newBoundaryRows(nx int, dx float64, left, right mathutils.Boundary) boundaryRows {
+	rows := boundaryRows{nx: nx, lo: 1, hi: nx - 1, dx: dx, left: left, right: right}
+	if !left.IsDirichlet() {
+		rows.lo = 0
+	}
+	if !right.IsDirichlet() {
+		rows.hi = nx
+	}
+	rows.n = rows.hi - rows.lo + 1
+	rows.betaLeft, rows.qLeft = ghostEnd(left, -1)
+	rows.betaRight, rows.qRight = ghostEnd(right, 1)
+	return rows
+}
+
+// Разложенная матрица шага с весом θ и r; release возвращает буферы в пул
+func (rows boundaryRows) factor(theta, r float64) (*thomas, func()) {
+	n, implicit := rows.n, theta*r
+	a, b, c, _, ws := workspace(n)
+	for j := range n {
+		a[j], b[j], c[j] = -implicit, 1+2*implicit, -implicit
+	}
+	if !rows.left.IsDirichlet() {
+		b[0], c[0] = 1+implicit*(2+2*rows.dx*rows.betaLeft), -2*implicit
+	}
+	if !rows.right.IsDirichlet() {
+		a[n-1], b[n-1] = -2*implicit, 1+implicit*(2+2*rows.dx*rows.betaRight)
+	}
+	tri := newThomas(n)
+	tri.factor(a, b, c)
+	return tri, func() {
+		tri.close()
+		pool64.put(ws)
+	}
+}
+
+// Шаг из cur (момент t0) в next (момент t1) матрицей tri с весом θ и r; d —
+// буфер правой части
+func (rows boundaryRows) advance(tri *thomas, theta, r float64, cur, next, d []float64, t0, t1 float64) {
+	nx, lo, n, dx := rows.nx, rows.lo, rows.n, rows.dx
+	implicit, explicit := theta*r, (1-theta)*r
+	for i := max(lo, 1); i <= min(rows.hi, nx-1); i++ {
+		d[i-lo] = cur[i] + explicit*(cur[i-1]-2*cur[i]+cur[i+1])
+	}
+	if rows.left.IsDirichlet() {
+		next[0] = rows.left.Value(t1)
+		d[0] += implicit * next[0]
+	} else {
+		q := theta*rows.qLeft(t1) + (1-theta)*rows.qLeft(t0)
+		d[0] = cur[0] + explicit*(2*cur[1]-(2+2*dx*rows.betaLeft)*cur[0]) + 2*r*dx*q
+	}
+	if rows.right.IsDirichlet() {
+		next[nx] = rows.right.Value(t1)
+		d[n-1] += implicit * next[nx]
+	} else {
+		q := theta*rows.qRight(t1) + (1-theta)*rows.qRight(t0)
+		d[n-1] = cur[nx] + explicit*(2*cur[nx-1]-(2+2*dx*rows.betaRight)*cur[nx]) + 2*r*dx*q
+	}
+	tri.solve(d[:n], next[lo:rows.hi+1])
+}
+
+// Проверочные случаи условий Неймана; в Verify — под именем NEUMANN
+var verificationNeumann = []VerifyCase{
+	neumannOrderCase(0, true, false),
+	neumannOrderCase(1, true, false),
+	neumannOrderCase(0.5, true, false),
+	neumannOrderCase(0.5, false, true),
+	neumannOrderCase(0.5, true, true),
+	neumannQuadraticCase(0),
+	neumannQuadraticCase(1),
+	neumannQuadraticCase(0.5),
+}
+
+// Наблюдаемый порядок ошибки L∞ (в неё входят и узлы Неймана) на первой
+// моде mathutils.BoundaryMode с однородными условиями за два измельчения
+// dx вдвое при r = 0.4 (dt — вчетверо): ошибка по времени тоже O(dx²), и
+// у всех схем ожидается второй порядок. Односторонняя разность на краю
+// дала бы здесь первый
+func neumannOrderCase(theta float64, leftNeumann, rightNeumann bool) VerifyCase {
+	const (
+		nx0  = 10
+		r    = 0.4
+		tmax = 0.1
+	)
+	left, right := mathutils.Boundary{Kind: mathutils.BCDirichlet}, mathutils.Boundary{Kind: mathutils.BCDirichlet}
+	if leftNeumann {
+		left.Kind = mathutils.BCNeumann
+	}
+	if rightNeumann {
+		right.Kind = mathutils.BCNeumann
+	}
+	exact := mathutils.BoundaryMode{Alpha: 1, LeftNeumann: leftNeumann, RightNeumann: rightNeumann}
+	return VerifyCase{
+		Name:      fmt.Sprintf("%s %s/%s order from nx=%d r=%g", thetaName(theta), left.Kind, right.Kind, nx0, r),
+		Quantity:  "observed order",
+		Compare:   VerifyNear,
+		Expected:  2,
+		Tolerance: 0.1,
+		Measure: func(ctx context.Context, _ Method) (float64, string, error) {
+			var errs [3]float64
+			for k := range errs {
+				nx := nx0 << k
+				dx := 1 / float64(nx)
+				dt := r * dx * dx
+				nt := int(math.Round(tmax / dt))
+				last, err := StreamBoundaryFrom(BoundaryProfile(nx, dx, left, right), nt, dx, dt, 1, theta, 0, left, right, nil)
+				if err != nil {
+					return math.NaN(), "", err
+				}
+				_, errs[k] = mathutils.LevelErrors(last, dx, float64(nt)*dt, exact)
+			}
+			first, second := math.Log2(errs[0]/errs[1]), math.Log2(errs[1]/errs[2])
+			return second, fmt.Sprintf("L∞ errors %.3g, %.3g, %.3g; orders %.3f, %.3f", errs[0], errs[1], errs[2], first, second), nil
+		},
+	}
+}
+
+// Неоднородные условия с g, зависящим от t: u = x²/2 + t решает u_t = u_xx
+// с u(0) = t и ∂u/∂x(1) = 1. Решение квадратично по x и линейно по t, δ²
+// и фиктивный узел на нём точны, и схема должна воспроизвести его до
+// округления
+func neumannQuadraticCase(theta float64) VerifyCase {
+	return VerifyCase{
+		Name:     fmt.Sprintf("%s x²/2 + t with u(0)=t, u_x(1)=1", thetaName(theta)),
+		Quantity: "max |u − exact|",
+		Compare:  VerifyAtMost,
+		Expected: 1e-12,
+		Measure: func(ctx context.Context, _ Method) (float64, string, error) {
+			const nx, dt, nt = 20, 0.001, 100
+			dx := 1.0 / nx
+			left, err := mathutils.ParseBoundary("dirichlet:t")
+			if err != nil {
+				return math.NaN(), "", err
+			}
+			right, err := mathutils.ParseBoundary("neumann:1")
+			if err != nil {
+				return math.NaN(), "", err
+			}
+			u0 := make([]float64, nx+1)
+			for i := range u0 {
+				x := float64(i) * dx
+				u0[i] = x * x / 2
+			}
+			last, err := StreamBoundaryFrom(u0, nt, dx, dt, 1, theta, 0, left, right, nil)
+			if err != nil {
+				return math.NaN(), "", err
+			}
+			var worst float64
+			for i, v := range last {
+				x := float64(i) * dx
+				worst = math.Max(worst, math.Abs(v-(x*x/2+nt*dt)))
+			}
+			return worst, "", nil
+		},
+	}
+}
+
+// Проверочные случаи условия Робина; в Verify — под именем ROBIN
+var verificationRobin = []VerifyCase{
+	robinOrderCase(0, 10),
+	robinOrderCase(1, 10),
+	robinOrderCase(0.5, 10),
+	robinOrderCase(0.5, 1),
+	robinOrderCase(0.5, 100),
+	robinLimitCase(),
+}
+
+// Условие Робина справа с h = bi (k = 1, u_amb = 0) и теплоизоляция слева
+func robinEnds(bi float64) (left, right mathutils.Boundary) {
+	return mathutils.Boundary{Kind: mathutils.BCNeumann}, mathutils.Boundary{Kind: mathutils.BCRobin, H: bi, K: 1}
+}
+
+// Остывание пластины из u = 1 против ряда mathutils.RobinSlab: наблюдаемый
+// порядок ошибки L∞ (с узлом Робина) за два измельчения dx вдвое при
+// r = 0.3 и tmax = 0.2. У FTCS предел r с условием Робина ниже ½:
+// 2/(4 + 2dx·Bi), 0.33 при dx = 0.1 и Bi = 10
+func robinOrderCase(theta, bi float64) VerifyCase {
+	const (
+		nx0  = 10
+		r    = 0.3
+		tmax = 0.2
+	)
+	left, right := robinEnds(bi)
+	exact := mathutils.NewRobinSlab(bi, 1, 0)
+	return VerifyCase{
+		Name:      fmt.Sprintf("%s Bi=%g series order from nx=%d r=%g", thetaName(theta), bi, nx0, r),
+		Quantity:  "observed order",
+		Compare:   VerifyNear,
+		Expected:  2,
+		Tolerance: 0.1,
+		Measure: func(ctx context.Context, _ Method) (float64, string, error) {
+			var errs [3]float64
+			for k := range errs {
+				nx := nx0 << k
+				dx := 1 / float64(nx)
+				dt := r * dx * dx
+				nt := int(math.Round(tmax / dt))
+				last, err := StreamBoundaryFrom(BoundaryProfile(nx, dx, left, right), nt, dx, dt, 1, theta, 0, left, right, nil)
+				if err != nil {
+					return math.NaN(), "", err
+				}
+				_, errs[k] = mathutils.LevelErrors(last, dx, float64(nt)*dt, exact)
+			}
+			first, second := math.Log2(errs[0]/errs[1]), math.Log2(errs[1]/errs[2])
+			return second, fmt.Sprintf("λ₁ = %.6f; L∞ errors %.3g, %.3g, %.3g; orders %.3f, %.3f",
+				mathutils.BiotRoots(bi, 1)[0], errs[0], errs[1], errs[2], first, second), nil
+		},
+	}
+}
+
+// Предел h → ∞: BTCS с условием Робина при h от 1 до 1e6 против условия
+// Дирихле u = 0 справа из того же u = 1 (у Дирихле край сразу ноль).
+// Отличие убывает как 1/h; значение — отличие при h = 1e6, в подробностях
+// — вся развёртка. BTCS, а не CN: при β·dx ≫ 1 множитель перехода узла
+// Робина у CN близок к −1, и скачок на краю почти не затухает
+func robinLimitCase() VerifyCase {
+	return VerifyCase{
+		Name:     "h→∞ against dirichlet:0",
+		Quantity: "max |u robin − u dirichlet| at h=1e6",
+		Compare:  VerifyAtMost,
+		Expected: 1e-5,
+		Measure: func(ctx context.Context, _ Method) (float64, string, error) {
+			const nx, dt, tmax = 20, 0.001, 0.2
+			dx := 1.0 / nx
+			nt := int(math.Round(tmax / dt))
+			left, cold := mathutils.Boundary{Kind: mathutils.BCNeumann}, mathutils.Boundary{}
+			u0 := make([]float64, nx+1)
+			for i := range nx {
+				u0[i] = 1
+			}
+			dirichlet, err := StreamBoundaryFrom(u0, nt, dx, dt, 1, 1, 0, left, cold, nil)
+			if err != nil {
+				return math.NaN(), "", err
+			}
+			var diff float64
+			var sweep []string
+			for _, h := range []float64{1, 10, 100, 1e3, 1e4, 1e5, 1e6} {
+				_, right := robinEnds(h)
+				last, err := StreamBoundaryFrom(BoundaryProfile(nx, dx, left, right), nt, dx, dt, 1, 1, 0, left, right, nil)
+				if err != nil {
+					return math.NaN(), "", err
+				}
+				diff = 0
+				for i := range last {
+					diff = math.Max(diff, math.Abs(last[i]-dirichlet[i]))
+				}
+				sweep = append(sweep, fmt.Sprintf("%.2g", diff))
+			}
+			return diff, "h = 1…1e6: " + strings.Join(sweep, ", "), nil
+		},
+	}
+}
@@ -0,0 +1,290 @@
+package solver
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"heat-solver/internal/mathutils"
+)
+
+func neumannEnds(left, right bool) (mathutils.Boundary, mathutils.Boundary) {
+	l, r := mathutils.Boundary{Kind: mathutils.BCDirichlet}, mathutils.Boundary{Kind: mathutils.BCDirichlet}
+	if left {
+		l.Kind = mathutils.BCNeumann
+	}
+	if right {
+		r.Kind = mathutils.BCNeumann
+	}
+	return l, r
+}
+
+// Первая мода с условиями Неймана (теплоизолированный левый край с
+// cos(πx/2), правый, оба): ошибка L∞ вместе с узлами Неймана — второго
+// порядка по dx у FTCS, BTCS и CN при r = 0.4
+func TestNeumannOrder(t *testing.T) {
+	const nx0, r, tmax = 10, 0.4, 0.1
+	tests := []struct {
+		theta       float64
+		left, right bool
+	}{
+		{0, true, false},
+		{1, true, false},
+		{0.5, true, false},
+		{0.5, false, true},
+		{0.5, true, true},
+		{1, true, true},
+	}
+	for _, tc := range tests {
+		t.Run(fmt.Sprintf("%s/%v/%v", thetaName(tc.theta), tc.left, tc.right), func(t *testing.T) {
+			left, right := neumannEnds(tc.left, tc.right)
+			exact := mathutils.BoundaryMode{Alpha: 1, LeftNeumann: tc.left, RightNeumann: tc.right}
+			var errs [3]float64
+			for k := range errs {
+				nx := nx0 << k
+				dx := 1 / float64(nx)
+				dt := r * dx * dx
+				nt := int(math.Round(tmax / dt))
+				last, err := StreamBoundaryFrom(BoundaryProfile(nx, dx, left, right), nt, dx, dt, 1, tc.theta, 0, left, right, nil)
+				if err != nil {
+					t.Fatal(err)
+				}
+				_, errs[k] = mathutils.LevelErrors(last, dx, float64(nt)*dt, exact)
+			}
+			for k := 1; k < len(errs); k++ {
+				if order := math.Log2(errs[k-1] / errs[k]); math.Abs(order-2) > 0.1 {
+					t.Errorf("L∞ errors %.3g, %.3g, %.3g: order %.3f", errs[0], errs[1], errs[2], order)
+				}
+			}
+		})
+	}
+}
+
+// u = x²/2 + t с u(0) = t и u_x(1) = 1: δ² и фиктивный узел на нём точны,
+// и каждая схема воспроизводит его до округления; то же с Нейманом слева
+// (u_x(0) = 0) и Дирихле справа u(1) = ½ + t
+func TestNeumannQuadratic(t *testing.T) {
+	const nx, dt, nt = 20, 0.001, 100
+	dx := 1.0 / nx
+	ends := [][2]string{{"dirichlet:t", "neumann:1"}, {"neumann:0", "0.5 + t"}}
+	for _, e := range ends {
+		left, err := mathutils.ParseBoundary(e[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		right, err := mathutils.ParseBoundary(e[1])
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, theta := range []float64{0, 0.5, 1} {
+			u0 := make([]float64, nx+1)
+			for i := range u0 {
+				x := float64(i) * dx
+				u0[i] = x * x / 2
+			}
+			var worst float64
+			_, err := StreamBoundaryFrom(u0, nt, dx, dt, 1, theta, 0, left, right, func(n int, row []float64) error {
+				for i, v := range row {
+					x := float64(i) * dx
+					worst = math.Max(worst, math.Abs(v-(x*x/2+float64(n)*dt)))
+				}
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if worst > 1e-12 {
+				t.Errorf("%s, %s, θ=%g: max error %.3g", e[0], e[1], theta, worst)
+			}
+		}
+	}
+}
+
+// С однородными условиями Дирихле на обоих концах — обычные шаги FTCS,
+// BTCS и CN
+func TestBoundaryDirichlet(t *testing.T) {
+	const nx, nt, dx = 20, 30, 0.05
+	tests := []struct {
+		method string
+		theta  float64
+		dt     float64
+	}{
+		{"FTCS", 0, 0.001},
+		{"BTCS", 1, 0.01},
+		{"CN", 0.5, 0.01},
+	}
+	for _, tc := range tests {
+		t.Run(tc.method, func(t *testing.T) {
+			u0 := SineProfile(nx, dx)
+			want := stencilReference(tc.method, u0, nt, dx, tc.dt)
+			var worst float64
+			_, err := StreamBoundaryFrom(u0, nt, dx, tc.dt, 1, tc.theta, 0, mathutils.Boundary{}, mathutils.Boundary{}, func(n int, row []float64) error {
+				for i, v := range row {
+					worst = math.Max(worst, math.Abs(v-want[n][i]))
+				}
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if worst > 1e-14 {
+				t.Errorf("differs from the Dirichlet scheme by %.3g", worst)
+			}
+		})
+	}
+}
+
+// Оба края теплоизолированы: тепло (формула трапеций) сохраняется до
+// округления при любом θ, а решение выходит на среднее
+func TestNeumannHeat(t *testing.T) {
+	const nx, dx = 20, 0.05
+	left, right := neumannEnds(true, true)
+	u0 := PulseProfile(nx, dx, 0.1, 0.4)
+	heat0 := mathutils.HeatContent(u0, dx)
+	for _, theta := range []float64{0, 0.5, 1} {
+		dt := 0.001
+		if theta > 0 {
+			dt = 0.02
+		}
+		var drift float64
+		last, err := StreamBoundaryFrom(u0, int(math.Round(2/dt)), dx, dt, 1, theta, 0, left, right, func(_ int, row []float64) error {
+			drift = math.Max(drift, math.Abs(mathutils.HeatContent(row, dx)-heat0)/heat0)
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if drift > 1e-13 {
+			t.Errorf("θ=%g: relative heat drift %.3g", theta, drift)
+		}
+		for i, v := range last {
+			if math.Abs(v-heat0) > 1e-6 {
+				t.Errorf("θ=%g: u[%d] = %v, mean %v", theta, i, v, heat0)
+				break
+			}
+		}
+	}
+}
+
+func TestBoundaryRejects(t *testing.T) {
+	left, right := neumannEnds(true, false)
+	if _, err := StreamBoundaryFrom([]float64{1, 0}, 1, 1, 0.1, 1, 1, 0, left, right, nil); err == nil {
+		t.Error("one interval accepted")
+	}
+}
+
+// Остывание пластины из u = 1 против ряда mathutils.RobinSlab при разных
+// числах Био: ошибка L∞ вместе с узлом Робина — второго порядка; r = 0.3
+// ниже предела FTCS с условием Робина
+func TestRobinOrder(t *testing.T) {
+	const nx0, r, tmax = 10, 0.3, 0.2
+	tests := []struct {
+		theta, bi float64
+	}{
+		{0, 10},
+		{1, 10},
+		{0.5, 10},
+		{0.5, 1},
+		{0.5, 100},
+	}
+	for _, tc := range tests {
+		t.Run(fmt.Sprintf("%s/Bi=%g", thetaName(tc.theta), tc.bi), func(t *testing.T) {
+			left, right := robinEnds(tc.bi)
+			exact := mathutils.NewRobinSlab(tc.bi, 1, 0)
+			var errs [3]float64
+			for k := range errs {
+				nx := nx0 << k
+				dx := 1 / float64(nx)
+				dt := r * dx * dx
+				nt := int(math.Round(tmax / dt))
+				last, err := StreamBoundaryFrom(BoundaryProfile(nx, dx, left, right), nt, dx, dt, 1, tc.theta, 0, left, right, nil)
+				if err != nil {
+					t.Fatal(err)
+				}
+				_, errs[k] = mathutils.LevelErrors(last, dx, float64(nt)*dt, exact)
+			}
+			if order := math.Log2(errs[1] / errs[2]); math.Abs(order-2) > 0.1 {
+				t.Errorf("L∞ errors %.3g, %.3g, %.3g: order %.3f", errs[0], errs[1], errs[2], order)
+			}
+		})
+	}
+}
+
+// Развёртка по числу Био: при h → ∞ BTCS с условием Робина сходится к
+// условию Дирихле u = 0 как 1/h, при h = 1e6 — до 1e-5; при h = 0 край
+// теплоизолирован, как у neumann:0, побитно
+func TestRobinLimit(t *testing.T) {
+	const nx, dt, tmax = 20, 0.001, 0.2
+	dx := 1.0 / nx
+	nt := int(math.Round(tmax / dt))
+	left := mathutils.Boundary{Kind: mathutils.BCNeumann}
+	u0 := make([]float64, nx+1)
+	for i := range nx {
+		u0[i] = 1
+	}
+	dirichlet, err := StreamBoundaryFrom(u0, nt, dx, dt, 1, 1, 0, left, mathutils.Boundary{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var prev float64
+	for _, h := range []float64{1e2, 1e3, 1e4, 1e5, 1e6} {
+		_, right := robinEnds(h)
+		last, err := StreamBoundaryFrom(BoundaryProfile(nx, dx, left, right), nt, dx, dt, 1, 1, 0, left, right, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var diff float64
+		for i := range last {
+			diff = math.Max(diff, math.Abs(last[i]-dirichlet[i]))
+		}
+		if prev > 0 {
+			if ratio := prev / diff; ratio < 8 || ratio > 12 {
+				t.Errorf("h=%g: gap %.3g after %.3g, ratio %.2f, want ≈ 10", h, diff, prev, ratio)
+			}
+		}
+		prev = diff
+	}
+	if prev > 1e-5 {
+		t.Errorf("h=1e6: gap %.3g", prev)
+	}
+
+	robin, err := StreamBoundaryFrom(u0, nt, dx, dt, 1, 0.5, 0, left, mathutils.Boundary{Kind: mathutils.BCRobin, K: 1}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	insulated, err := StreamBoundaryFrom(u0, nt, dx, dt, 1, 0.5, 0, left, left, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range robin {
+		if robin[i] != insulated[i] {
+			t.Fatalf("h = 0: u[%d] = %v, neumann:0 %v", i, robin[i], insulated[i])
+		}
+	}
+}
+
+// С температурой среды u_amb = 2 и теплоизоляцией слева пластина из нуля
+// выходит на u_amb при любых k; условие Робина слева зеркально правому
+func TestRobinAmbient(t *testing.T) {
+	const nx, dx = 20, 0.05
+	for _, spec := range []string{"robin:h=5,uamb=2", "robin:h=0.5,uamb=2,k=3"} {
+		robin, err := mathutils.ParseBoundary(spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		insulated := mathutils.Boundary{Kind: mathutils.BCNeumann}
+		right, err := StreamBoundaryFrom(make([]float64, nx+1), 4000, dx, 0.1, 1, 1, 0, insulated, robin, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		left, err := StreamBoundaryFrom(make([]float64, nx+1), 4000, dx, 0.1, 1, 1, 0, robin, insulated, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := range right {
+			if math.Abs(right[i]-2) > 1e-9 || math.Abs(left[nx-i]-right[i]) > 1e-12 {
+				t.Fatalf("%s: u[%d] = %v, mirrored %v, want 2", spec, i, right[i], left[nx-i])
+			}
+		}
+	}
+}
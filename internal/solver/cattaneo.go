@@ -0,0 +1,229 @@
+package solver
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+
+	"heat-solver/internal/config"
+	"heat-solver/internal/mathutils"
+)
+
+// Гиперболическое уравнение теплопроводности Каттанео–Вернотта
+// τ·u_tt + u_t = α·u_xx: поток отстаёт от градиента на время релаксации τ,
+// и возмущения идут с конечной скоростью √(α/τ), а не мгновенно, как у
+// параболического уравнения. Трёхслойная неявная схема
+//
+//	τ(u^{n+1} − 2u^n + u^{n−1})/dt² + (u^{n+1} − u^n)/dt = α·δ²u^{n+1}/dx²
+//
+// на шаге решает трёхдиагональную систему
+// (1 + τ/dt)·u^{n+1} − r·δ²u^{n+1} = (1 + 2τ/dt)·u^n − (τ/dt)·u^{n−1} с
+// постоянной матрицей. Множитель перехода g моды с λ = 4r·sin²(kdx/2)
+// удовлетворяет (a + 1 + λ)g² − (2a + 1)g + a = 0, a = τ/dt: произведение
+// корней a/(a + 1 + λ) < 1, и оба корня внутри единичного круга при любых
+// dt и dx. Порядок по dt первый. При τ = 0 коэффициенты побитно те же, что
+// у BTCS (1 + 2r на диагонали, u^n справа), и решение с BTCS совпадает
+
+// Каттанео с заданным профилем u0 (nx+1 значений, края нулевые, как у
+// start) и u_t(0) = 0 (u^{−1} = u^0); хранятся три слоя, каждый слой
+// передаётся в emit. τ < 0 — ошибка
+func StreamCattaneoFrom(u0 []float64, nt int, dx, dt, alpha, tau float64, emit EmitFunc) ([]float64, error) {
+	u := newGrid(3, len(u0))
+	if err := cattaneo(u0, nt, dx, dt, alpha, tau, u, emit); err != nil {
+		return nil, err
+	}
+	return level(u, nt), nil
+}
+
+// Прямоугольный импульс: 1 на (lo, hi), 0 вне, ½ в узлах на краях
+// импульса (с точностью до округления)
+func PulseProfile(nx int, dx, lo, hi float64) []float64 {
+	u0 := make([]float64, nx+1)
+	edge := func(x, e float64) bool { return math.Abs(x-e) < 1e-9*dx }
+	for i := range u0 {
+		switch x := float64(i) * dx; {
+		case edge(x, lo) || edge(x, hi):
+			u0[i] = 0.5
+		case x > lo && x < hi:
+			u0[i] = 1
+		}
+	}
+	return u0
+}
+
+// Правый фронт: самая правая точка, где |u| пересекает level сверху вниз
+// (линейная интерполяция между узлами). NaN, если пересечения нет
+func CattaneoFront(u []float64, dx, level float64) float64 {
+	for i := len(u) - 2; i >= 0; i-- {
+		if a, b := math.Abs(u[i]), math.Abs(u[i+1]); a >= level && b < level {
+			return (float64(i) + (a-level)/(a-b)) * dx
+		}
+	}
+	return math.NaN()
+}
+
+// Высота скачка на фронте импульса высоты h: скачок делится пополам между
+// волнами, идущими в обе стороны, и затухает как exp(−t/(2τ))
+func CattaneoJump(h, tau, t float64) float64 {
+	return h / 2 * math.Exp(-t/(2*tau))
+}
+
+func cattaneo(u0 []float64, nt int, dx, dt, alpha, tau float64, u [][]float64, emit EmitFunc) error {
+	if !(tau >= 0) || math.IsInf(tau, 0) {
+		return fmt.Errorf("relaxation time must be non-negative and finite, got %g", tau)
+	}
+	nx := len(u0) - 1
+	r := alpha * dt / (dx * dx)
+	lag := tau / dt
+	slog.Info("Starting Cattaneo three-level solver", "nx", nx, "nt", nt, "dx", dx, "dt", dt, "tau", tau, "r", r, "tau_dt", lag)
+
+	if err := start(u, u0, emit); err != nil {
+		return err
+	}
+
+	a, b, c, d, ws := workspace(nx - 1)
+	defer pool64.put(ws)
+	for i := range b {
+		a[i], b[i], c[i] = -r, 1+lag+2*r, -r
+	}
+	tri := newTridiag(a, b, c)
+	defer tri.close()
+
+	for n := 0; n < nt; n++ {
+		cur, next := level(u, n), level(u, n+1)
+		prev := cur
+		if n > 0 {
+			prev = level(u, n-1)
+		}
+		for i := 1; i < nx; i++ {
+			d[i-1] = (1+2*lag)*cur[i] - lag*prev[i]
+		}
+		tri.solve(d, next[1:nx])
+		if err := emitLevel(emit, n+1, next); err != nil {
+			return err
+		}
+	}
+
+	slog.Info("Cattaneo solver finished successfully")
+	return nil
+}
+
+// Проверочные случаи уравнения Каттанео; в Verify — под именем CATTANEO
+var verificationCattaneo = []VerifyCase{
+	cattaneoFrontCase(0.002, 0.0001, 0.05, 0.05, 1),
+	cattaneoFrontCase(0.001, 0.00005, 0.05, 0.05, 1),
+	cattaneoLimitCase(0.05, 0.001, 0.1),
+	cattaneoTauOrderCase(0.05, 0.001, 0.1, 1e-4, 1, 0.1),
+	cattaneoModeCase(0.02, 0.0001, 0.05, 0.2, 2e-3),
+}
+
+// Фронт импульса 1 на (0.45, 0.55) при τ = tau, α = 1 в момент t: правый
+// фронт — точка, где u проходит половину скачка CattaneoJump, — должен
+// стоять в 0.55 + √(α/τ)·t с точностью до cells ячеек. Схема размывает
+// скачок на несколько ячеек, но середина размытия остаётся на фронте.
+// В деталях — u на 0.1 правее фронта: у гиперболического уравнения там
+// почти 0, у уравнения теплопроводности — заметная доля импульса
+func cattaneoFrontCase(dx, dt, tau, t, cells float64) VerifyCase {
+	mode := mathutils.CattaneoMode{Alpha: 1, Tau: tau}
+	return VerifyCase{
+		Name:     fmt.Sprintf("pulse front speed √(α/τ) τ=%g dx=%g dt=%g", tau, dx, dt),
+		Quantity: "|front − exact| / dx",
+		Compare:  VerifyAtMost,
+		Expected: cells,
+		Measure: func(ctx context.Context, _ Method) (float64, string, error) {
+			nx := int(math.Round(1 / dx))
+			nt := int(math.Round(t / dt))
+			u0 := PulseProfile(nx, dx, 0.45, 0.55)
+			last, err := StreamCattaneoFrom(u0, nt, dx, dt, 1, tau, nil)
+			if err != nil {
+				return math.NaN(), "", err
+			}
+			exact := 0.55 + mode.Speed()*float64(nt)*dt
+			front := CattaneoFront(last, dx, CattaneoJump(1, tau, float64(nt)*dt)/2)
+			heat, _ := StreamBTCSFrom(u0, nt, dx, dt, nil)
+			ahead := int(math.Round((exact + 0.1) / dx))
+			return math.Abs(front-exact) / dx, fmt.Sprintf("front %.4f, exact %.4f; u at front + 0.1: %.2g, heat equation %.2g",
+				front, exact, last[ahead], heat[ahead]), nil
+		},
+	}
+}
+
+// При τ = 0 схема совпадает с BTCS (через Run, как из CLI): наибольшее
+// отличие в узлах из sin(πx) — 0
+func cattaneoLimitCase(dx, dt, tmax float64) VerifyCase {
+	return VerifyCase{
+		Name:     fmt.Sprintf("τ = 0 against BTCS dx=%g dt=%g", dx, dt),
+		Quantity: "max |u − u BTCS|",
+		Compare:  VerifyAtMost,
+		Expected: 0,
+		Measure: func(ctx context.Context, _ Method) (float64, string, error) {
+			diff, err := cattaneoBTCSDiff(ctx, dx, dt, tmax, 0)
+			return diff, "", err
+		},
+	}
+}
+
+// Отличие от BTCS при τ → 0 убывает как τ: порядок по τ между tau и
+// tau/10 — log₁₀ отношения отличий
+func cattaneoTauOrderCase(dx, dt, tmax, tau, order, tol float64) VerifyCase {
+	return VerifyCase{
+		Name:      fmt.Sprintf("τ → 0 order against BTCS from τ=%g", tau),
+		Quantity:  "observed order in τ",
+		Compare:   VerifyNear,
+		Expected:  order,
+		Tolerance: tol,
+		Measure: func(ctx context.Context, _ Method) (float64, string, error) {
+			var diffs [2]float64
+			for k := range diffs {
+				var err error
+				if diffs[k], err = cattaneoBTCSDiff(ctx, dx, dt, tmax, tau/math.Pow(10, float64(k))); err != nil {
+					return math.NaN(), "", err
+				}
+			}
+			return math.Log10(diffs[0] / diffs[1]), fmt.Sprintf("differences %.3g, %.3g", diffs[0], diffs[1]), nil
+		},
+	}
+}
+
+// Наибольшее |u − u BTCS| в момент tmax из sin(πx)
+func cattaneoBTCSDiff(ctx context.Context, dx, dt, tmax, tau float64) (float64, error) {
+	p := config.Params{Method: "BTCS", Dx: dx, Dt: dt, Tmax: tmax, Storage: config.StorageFinalOnly, Precision: config.PrecisionFloat64}
+	res, err := Run(ctx, p, Hooks{})
+	if err != nil {
+		return math.NaN(), err
+	}
+	defer res.Release()
+	last, err := StreamCattaneoFrom(SineProfile(res.Nx, res.Dx), res.Nt, res.Dx, res.Dt, 1, tau, nil)
+	if err != nil {
+		return math.NaN(), err
+	}
+	var worst float64
+	for i, v := range last {
+		worst = math.Max(worst, math.Abs(v-res.Final[i]))
+	}
+	return worst, nil
+}
+
+// Колеблющаяся мода sin(πx) при τ = tau против mathutils.CattaneoMode:
+// наибольшая ошибка в узлах в момент tmax не больше tol
+func cattaneoModeCase(dx, dt, tau, tmax, tol float64) VerifyCase {
+	mode := mathutils.CattaneoMode{Alpha: 1, Tau: tau}
+	return VerifyCase{
+		Name:     fmt.Sprintf("damped oscillating mode τ=%g dx=%g dt=%g", tau, dx, dt),
+		Quantity: "L∞ error",
+		Compare:  VerifyAtMost,
+		Expected: tol,
+		Measure: func(ctx context.Context, _ Method) (float64, string, error) {
+			nx := int(math.Round(1 / dx))
+			nt := int(math.Round(tmax / dt))
+			last, err := StreamCattaneoFrom(SineProfile(nx, dx), nt, dx, dt, 1, tau, nil)
+			if err != nil {
+				return math.NaN(), "", err
+			}
+			t := float64(nt) * dt
+			_, linf := mathutils.LevelErrors(last, dx, t, mode)
+			return linf, fmt.Sprintf("A(t) = %.5f, heat equation exp(−π²t) = %.5f", mode.Amplitude(t), math.Exp(-math.Pi*math.Pi*t)), nil
+		},
+	}
+}
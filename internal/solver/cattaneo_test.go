@@ -0,0 +1,150 @@
+package solver
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"testing"
+
+	"heat-solver/internal/mathutils"
+)
+
+// Импульс 1 на (0.45, 0.55): правый фронт (середина скачка CattaneoJump)
+// в момент t стоит в 0.55 + √(α/τ)·t с точностью до ячейки; на 0.1
+// правее фронта возмущения почти нет, а у уравнения теплопроводности там
+// уже заметная доля импульса
+func TestCattaneoFront(t *testing.T) {
+	tests := []struct {
+		tau, dx, dt, t float64
+	}{
+		{0.05, 0.002, 0.0001, 0.05},
+		{0.05, 0.001, 0.00005, 0.05},
+		{0.02, 0.001, 0.00005, 0.03},
+	}
+	for _, tc := range tests {
+		t.Run(fmt.Sprintf("τ=%g/dx=%g", tc.tau, tc.dx), func(t *testing.T) {
+			mode := mathutils.CattaneoMode{Alpha: 1, Tau: tc.tau}
+			nx, nt := int(math.Round(1/tc.dx)), int(math.Round(tc.t/tc.dt))
+			u0 := PulseProfile(nx, tc.dx, 0.45, 0.55)
+			last, err := StreamCattaneoFrom(u0, nt, tc.dx, tc.dt, 1, tc.tau, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tm := float64(nt) * tc.dt
+			exact := 0.55 + mode.Speed()*tm
+			front := CattaneoFront(last, tc.dx, CattaneoJump(1, tc.tau, tm)/2)
+			if math.Abs(front-exact) > tc.dx {
+				t.Errorf("front at %.5f, want %.5f within a cell", front, exact)
+			}
+			heat, err := StreamBTCSFrom(u0, nt, tc.dx, tc.dt, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ahead := int(math.Round((exact + 0.1) / tc.dx))
+			if math.Abs(last[ahead]) > 1e-3 || heat[ahead] < 0.01 {
+				t.Errorf("u at front + 0.1: %.3g, heat equation %.3g", last[ahead], heat[ahead])
+			}
+		})
+	}
+}
+
+// При τ = 0 схема побитно совпадает с BTCS, а отличие от BTCS при малом
+// τ убывает как τ
+func TestCattaneoBTCSLimit(t *testing.T) {
+	const nx, nt, dx, dt = 20, 100, 0.05, 0.001
+	u0 := SineProfile(nx, dx)
+	btcs, err := StreamBTCSFrom(u0, nt, dx, dt, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	diff := func(tau float64) float64 {
+		last, err := StreamCattaneoFrom(u0, nt, dx, dt, 1, tau, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var worst float64
+		for i, v := range last {
+			worst = math.Max(worst, math.Abs(v-btcs[i]))
+		}
+		return worst
+	}
+	if d := diff(0); d != 0 {
+		t.Errorf("τ = 0 differs from BTCS by %g", d)
+	}
+	for _, tau := range []float64{1e-3, 1e-4} {
+		if order := math.Log10(diff(tau) / diff(tau/10)); math.Abs(order-1) > 0.1 {
+			t.Errorf("τ=%g: order %.3f in τ", tau, order)
+		}
+	}
+}
+
+// Мода sin(πx) против CattaneoMode: колеблющаяся (τ = 0.05) и
+// затухающая без колебаний (τ = 0.01); ошибка первого порядка по dt
+func TestCattaneoMode(t *testing.T) {
+	for _, tau := range []float64{0.05, 0.01} {
+		t.Run(fmt.Sprintf("τ=%g", tau), func(t *testing.T) {
+			mode := mathutils.CattaneoMode{Alpha: 1, Tau: tau}
+			const nx, dx, tmax = 100, 0.01, 0.2
+			var prev float64
+			for _, dt := range []float64{4e-4, 2e-4, 1e-4} {
+				nt := int(math.Round(tmax / dt))
+				last, err := StreamCattaneoFrom(SineProfile(nx, dx), nt, dx, dt, 1, tau, nil)
+				if err != nil {
+					t.Fatal(err)
+				}
+				_, linf := mathutils.LevelErrors(last, dx, float64(nt)*dt, mode)
+				if linf > 0.03 {
+					t.Errorf("dt=%g: L∞ error %.3g", dt, linf)
+				}
+				if prev > 0 {
+					if order := math.Log2(prev / linf); order < 0.8 || order > 1.2 {
+						t.Errorf("dt=%g: error %.3g → %.3g, order %.2f", dt, prev, linf, order)
+					}
+				}
+				prev = linf
+			}
+		})
+	}
+}
+
+// Схема безусловно устойчива: при r = 10⁴ и τ/dt от 10⁻³ до 10³ решение
+// из импульса не выходит за [−1, 1] и затухает
+func TestCattaneoStability(t *testing.T) {
+	const nx, dx = 50, 0.02
+	u0 := PulseProfile(nx, dx, 0.4, 0.6)
+	for _, tau := range []float64{0.004, 4, 4000} {
+		var worst float64
+		_, err := StreamCattaneoFrom(u0, 200, dx, 4, 1, tau, func(_ int, row []float64) error {
+			for _, v := range row {
+				worst = math.Max(worst, math.Abs(v))
+			}
+			return nil
+		})
+		if err != nil || worst > 1 {
+			t.Errorf("τ=%g: max |u| %v, error %v", tau, worst, err)
+		}
+	}
+	for _, tau := range []float64{-1, math.NaN(), math.Inf(1)} {
+		if _, err := StreamCattaneoFrom(u0, 1, dx, 0.001, 1, tau, nil); err == nil {
+			t.Errorf("τ = %v accepted", tau)
+		}
+	}
+}
+
+// Импульс и его фронт: ½ в узлах на краях импульса, фронт по уровню —
+// линейная интерполяция справа налево
+func TestPulseFront(t *testing.T) {
+	u0 := PulseProfile(10, 0.1, 0.3, 0.6)
+	if want := []float64{0, 0, 0, 0.5, 1, 1, 0.5, 0, 0, 0, 0}; !slices.Equal(u0, want) {
+		t.Errorf("pulse %v, want %v", u0, want)
+	}
+	if front := CattaneoFront(u0, 0.1, 0.25); math.Abs(front-0.65) > 1e-15 {
+		t.Errorf("front %v, want 0.65", front)
+	}
+	if front := CattaneoFront([]float64{0, -1, -1, 0}, 1, 0.5); front != 2.5 {
+		t.Errorf("front of a negative pulse %v, want 2.5", front)
+	}
+	if front := CattaneoFront(u0, 0.1, 2); !math.IsNaN(front) {
+		t.Errorf("front above the pulse %v, want NaN", front)
+	}
+}
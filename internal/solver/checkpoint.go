@@ -0,0 +1,167 @@
+package solver
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"heat-solver/internal/config"
+)
+
+// Режим хранения Result.Storage для Checkpointed: хранится каждый K-й
+// слой, промежуточные пересчитываются при чтении
+const StorageCheckpoint = "checkpoint"
+
+// Бюджет памяти контрольных слоёв по умолчанию
+const DefaultCheckpointMemory = 256 << 20
+
+// Наименьший шаг K, при котором контрольные слои 0, K, 2K, … ≤ nt по nx+1
+// значений float64 занимают не больше budget байт (budget ≤ 0 —
+// DefaultCheckpointMemory). Хранится минимум два слоя
+func CheckpointEvery(nx, nt int, budget int64) int {
+	if budget <= 0 {
+		budget = DefaultCheckpointMemory
+	}
+	rows := max(2, budget/(int64(nx+1)*8))
+	return max(1, min(nt, int((int64(nt)+rows-2)/(rows-1))))
+}
+
+// Контрольные слои: rows[c] — слой c·every. Промежуточные слои
+// пересчитываются той же схемой stream с теми же dx и dt, поэтому
+// совпадают с исходными побайтно (при тех же SetThreads и SetTridiagonal)
+type checkpoints struct {
+	every  int
+	rows   [][]float64
+	stream StreamFunc
+}
+
+// Расчёт с хранением каждого every-го слоя (every ≤ 0 — по бюджету
+// DefaultCheckpointMemory, см. CheckpointEvery). Контрольные слои
+// хранятся во float64 при любом p.Precision. Result.Level пересчитывает
+// не больше every−1 шагов от ближайшего контрольного слоя, Result.Each
+// выдаёт все слои за один повторный проход. У схемы с Method.Period
+// every округляется вверх до кратного периоду
+func Checkpointed(ctx context.Context, p config.Params, every int, hooks Hooks) (*Result, error) {
+	nx, nt := p.Grid()
+	if every <= 0 {
+		every = CheckpointEvery(nx, nt, 0)
+	}
+	every = min(every, max(1, nt))
+
+	m, ok := Lookup(p.Method)
+	if !ok {
+		return nil, &config.ValidationError{Field: "method", Message: fmt.Sprintf("unknown method %q", p.Method)}
+	}
+	m, err := paramsMethod(m, p, new([]AdaptiveStep))
+	if err != nil {
+		return nil, err
+	}
+	// Промежуточные слои пересчитываются от одного контрольного слоя, а
+	// трёхслойной схеме нужны два; адаптивная схема от слоя выдачи пошла
+	// бы другими шагами, схеме по ячейкам нужны ячейки, а не узлы,
+	// экстраполированной — состояния обоих расчётов, старт Раннахера
+	// повторился бы от каждого контрольного слоя, а зависящий от t
+	// источник пошёл бы от t = 0
+	if m.Levels > 2 {
+		return nil, &config.ValidationError{Field: "method", Message: fmt.Sprintf("method %s is a three-level scheme and cannot be checkpointed", m.Name)}
+	}
+	if m.Adaptive {
+		return nil, &config.ValidationError{Field: "method", Message: fmt.Sprintf("method %s chooses its own steps and cannot be checkpointed", m.Name)}
+	}
+	if m.CellCentered {
+		return nil, &config.ValidationError{Field: "method", Message: fmt.Sprintf("method %s keeps cell averages and cannot be checkpointed", m.Name)}
+	}
+	if m.Combined {
+		return nil, &config.ValidationError{Field: "method", Message: fmt.Sprintf("method %s combines two runs and cannot be checkpointed", m.Name)}
+	}
+	if m.Startup > 0 {
+		return nil, &config.ValidationError{Field: "rannacher", Message: fmt.Sprintf("method %s with a startup of %d steps cannot be checkpointed; turn the startup off", m.Name, m.Startup)}
+	}
+	if m.Source.TimeDependent() {
+		return nil, &config.ValidationError{Field: "source", Message: fmt.Sprintf("source %s depends on t and cannot be checkpointed", m.Source.Expr)}
+	}
+	// Повтор схемы с периодом начинается с того же положения в периоде
+	if m.Period > 1 {
+		every = (every + m.Period - 1) / m.Period * m.Period
+	}
+
+	slog.InfoContext(ctx, "Checkpointed solve started", "method", m.Name, "nx", nx, "nt", nt, "every", every)
+	if hooks.OnStart != nil {
+		hooks.OnStart(m.Name)
+	}
+	start := time.Now()
+	res := &Result{Method: m.Name, Nx: nx, Nt: nt, Dx: p.Dx, Dt: p.Dt, Storage: StorageCheckpoint, Precision: config.PrecisionFloat64}
+	defer func() {
+		res.Runtime = time.Since(start)
+		if hooks.OnFinish != nil {
+			hooks.OnFinish(res)
+		}
+	}()
+
+	flat := pool64.get((nt/every + 1) * (nx + 1))
+	rows := gridRows(flat, nt/every+1, nx+1)
+	check := max(1, streamCheckCells/(nx+1))
+	u0 := InitialProfile(p, nx)
+	principle := NewPrincipleMonitor(u0)
+	peaks := principle.TracePeaks(nt + 1)
+	audit := NewFluxAudit(p.Dx, p.Dt).WithSource(m.Source)
+	symmetry := symmetryMonitor(u0)
+	last, err := m.Stream(u0, nt, p.Dx, p.Dt, func(n int, row []float64) error {
+		principle.Check(n, row)
+		audit.Add(n, row)
+		symmetry.Check(n, row)
+		if n > 0 && n%check == 0 {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+		}
+		if n%every == 0 {
+			copy(rows[n/every], row)
+		}
+		return nil
+	})
+	if err != nil {
+		pool64.put(flat)
+		return nil, err
+	}
+	res.Final = last
+	res.checkpoints = &checkpoints{every: every, rows: rows, stream: m.Stream}
+	res.pooled = newPooledBuffer(flat, nil)
+
+	res.Diverged = Diverged([][]float64{rows[0], last})
+	if res.Diverged {
+		slog.WarnContext(ctx, "Solution diverged", "method", m.Name, "nx", nx, "nt", nt)
+	}
+	res.MaxPrinciple = principle.Report()
+	res.Condition = StepCondition(m, nx, p.Dx, p.Dt)
+	res.Peaks = peaks
+	res.Flux = audit.Report(last)
+	res.Symmetry = symmetry.Report()
+	warnPrinciple(ctx, m, res.MaxPrinciple)
+	warnSymmetry(ctx, m.Name, res.Symmetry)
+	slog.InfoContext(ctx, "Checkpointed solve finished", "method", m.Name, "checkpoints", len(rows), "runtime_sec", time.Since(start).Seconds())
+	return res, nil
+}
+
+// Слой k: контрольный — как есть, иначе пересчёт от ближайшего
+// контрольного слоя новой копией
+func (c *checkpoints) level(r *Result, k int) []float64 {
+	if k == r.Nt {
+		return r.Final
+	}
+	base := c.rows[k/c.every]
+	steps := k % c.every
+	if steps == 0 {
+		return base
+	}
+	// Без emit схема не возвращает ошибок
+	row, _ := c.stream(base, steps, r.Dx, r.Dt, nil)
+	return row
+}
+
+// Все слои по порядку: один проход схемы от начального слоя
+func (c *checkpoints) each(r *Result, yield EmitFunc) error {
+	_, err := c.stream(c.rows[0], r.Nt, r.Dx, r.Dt, yield)
+	return err
+}
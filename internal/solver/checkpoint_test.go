@@ -0,0 +1,155 @@
+package solver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+
+	"heat-solver/internal/config"
+)
+
+func TestCheckpointEvery(t *testing.T) {
+	tests := []struct {
+		name   string
+		nx, nt int
+		budget int64
+		want   int
+	}{
+		{"everything fits", 100, 1000, 1 << 30, 1},
+		{"exactly nt+1 rows", 99, 9, 10 * 100 * 8, 1},
+		{"one row short", 99, 9, 9 * 100 * 8, 2},
+		{"two rows", 99, 1000, 2 * 100 * 8, 1000},
+		{"below two rows", 99, 1000, 1, 1000},
+		{"default budget", 1000, 1_000_000, 0, 30},
+		{"no steps", 10, 0, 1, 1},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			every := CheckpointEvery(tc.nx, tc.nt, tc.budget)
+			if every != tc.want {
+				t.Errorf("CheckpointEvery(%d, %d, %d) = %d, want %d", tc.nx, tc.nt, tc.budget, every, tc.want)
+			}
+			budget := tc.budget
+			if budget <= 0 {
+				budget = DefaultCheckpointMemory
+			}
+			if rows := int64(tc.nt/every + 1); rows > 2 && rows*int64(tc.nx+1)*8 > budget {
+				t.Errorf("%d checkpoint rows exceed the budget of %d bytes", rows, budget)
+			}
+		})
+	}
+}
+
+// Пересчитанные слои побитно совпадают с полным хранением — через Level
+// в любом порядке и через Each; неподходящие схемы отклоняются ошибкой
+// параметров
+func TestCheckpointedBitIdentical(t *testing.T) {
+	for _, name := range Methods() {
+		p := config.Params{Method: name, Dx: 0.05, Dt: 0.001, Tmax: 0.05}.Normalize()
+		full, err := Run(context.Background(), p, Hooks{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer full.Release()
+		for _, every := range []int{1, 3, 7, full.Nt} {
+			t.Run(fmt.Sprintf("%s/every=%d", name, every), func(t *testing.T) {
+				res, err := Checkpointed(context.Background(), p, every, Hooks{})
+				var verr *config.ValidationError
+				if errors.As(err, &verr) {
+					t.Skipf("not checkpointable: %v", err)
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+				defer res.Release()
+
+				if res.Storage != StorageCheckpoint || res.Levels() != full.Nt+1 || res.LevelStep() != 1 {
+					t.Fatalf("storage %s with %d levels %d steps apart, want checkpoint with %d levels",
+						res.Storage, res.Levels(), res.LevelStep(), full.Nt+1)
+				}
+				for k := res.Levels() - 1; k >= 0; k-- {
+					if !slices.Equal(res.Level(k), full.Level(k)) {
+						t.Fatalf("regenerated level %d differs from full storage", k)
+					}
+				}
+				seen := 0
+				err = res.Each(func(n int, row []float64) error {
+					if n != seen || !slices.Equal(row, full.Level(n)) {
+						return fmt.Errorf("Each level %d (expected %d) differs from full storage", n, seen)
+					}
+					seen++
+					return nil
+				})
+				if err != nil || seen != full.Nt+1 {
+					t.Fatalf("Each: %v after %d levels", err, seen)
+				}
+			})
+		}
+	}
+}
+
+// Схемы, которые нельзя пересчитать от одного контрольного слоя
+func TestCheckpointedRejects(t *testing.T) {
+	base := config.Params{Dx: 0.05, Dt: 0.001, Tmax: 0.05}
+	tests := []struct {
+		name   string
+		change func(p *config.Params)
+		field  string
+	}{
+		{"three-level", func(p *config.Params) { p.Method = "DUFORT-FRANKEL" }, "method"},
+		{"adaptive", func(p *config.Params) { p.Method = "RK45" }, "method"},
+		{"combined", func(p *config.Params) { p.Method = "BTCS-RICHARDSON" }, "method"},
+		{"rannacher startup", func(p *config.Params) { p.Method, p.Rannacher = "CN", true }, "rannacher"},
+		{"time-dependent source", func(p *config.Params) { p.Method, p.Source = "CN", "manufactured" }, "source"},
+		{"unknown method", func(p *config.Params) { p.Method = "NOPE" }, "method"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			p := base
+			tc.change(&p)
+			_, err := Checkpointed(context.Background(), p.Normalize(), 4, Hooks{})
+			var verr *config.ValidationError
+			if !errors.As(err, &verr) || verr.Field != tc.field {
+				t.Errorf("error %v, want a validation error for %s", err, tc.field)
+			}
+		})
+	}
+}
+
+// nt = 10⁶ при nx = 100: полное хранение заняло бы 808 МБ. Прямой расчёт
+// с контрольными слоями, их память и повторный проход Each по всем слоям
+func BenchmarkCheckpointed(b *testing.B) {
+	p := config.Params{Method: "CN", Dx: 0.01, Dt: 1e-6, Tmax: 1}.Normalize()
+	nx, nt := p.Grid()
+	b.Logf("nt=%d, full storage %d MiB", nt, int64(nt+1)*int64(nx+1)*8>>20)
+	for _, every := range []int{100, 1000, 10000} {
+		b.Run(fmt.Sprintf("every=%d", every), func(b *testing.B) {
+			var bytes int64
+			for b.Loop() {
+				res, err := Checkpointed(context.Background(), p, every, Hooks{})
+				if err != nil {
+					b.Fatal(err)
+				}
+				bytes = res.Bytes()
+				if err := res.Each(func(int, []float64) error { return nil }); err != nil {
+					b.Fatal(err)
+				}
+				res.Release()
+			}
+			b.ReportMetric(float64(bytes)/(1<<20), "MiB/result")
+		})
+	}
+	b.Run("forward only", func(b *testing.B) {
+		p := p
+		p.Storage = config.StorageFinalOnly
+		for b.Loop() {
+			res, err := Run(context.Background(), p, Hooks{})
+			if err != nil {
+				b.Fatal(err)
+			}
+			res.Release()
+		}
+	})
+}
@@ -0,0 +1,132 @@
+package solver
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"heat-solver/internal/config"
+	"heat-solver/internal/mathutils"
+)
+
+// Компактная схема четвёртого порядка по x с θ-схемой по времени:
+// (1 + δ²/12)·(u^{n+1} − u^n)/dt = α·δ²(θu^{n+1} + (1−θ)u^n)/dx².
+// Оператор (1 + δ²/12)⁻¹δ²/dx² приближает ∂²/∂x² с ошибкой O(dx⁴), а
+// система на шаге остаётся трёхдиагональной. С CN (θ = ½) — порядок
+// (2, 4), с BTCS — (1, 4)
+func SolveCompactCN(nx, nt int, dx, dt float64) [][]float64 {
+	return SolveCompactThetaFrom(SineProfile(nx, dx), nt, dx, dt, 1, 0.5)
+}
+
+// Компактная θ-схема с заданным начальным профилем u0; θ от ½ до 1
+func SolveCompactThetaFrom(u0 []float64, nt int, dx, dt, alpha, theta float64) [][]float64 {
+	u := newGrid(nt+1, len(u0))
+	thetaSchemeWith(u0, nt, dx, dt, alpha, theta, thetaOptions{compact: true}, u, nil)
+	return u
+}
+
+// Компактная θ-схема с хранением двух слоёв; каждый слой передаётся в emit
+func StreamCompactThetaFrom(u0 []float64, nt int, dx, dt, alpha, theta float64, emit EmitFunc) ([]float64, error) {
+	u := newGrid(2, len(u0))
+	if err := thetaSchemeWith(u0, nt, dx, dt, alpha, theta, thetaOptions{compact: true}, u, emit); err != nil {
+		return nil, err
+	}
+	return level(u, nt), nil
+}
+
+// Метод BTCS или CN с компактной схемой по x, как при
+// Params.SpatialOrder = 4. Другие методы её не имеют: у явных схем
+// оператор (1 + δ²/12)⁻¹ сделал бы шаг неявным
+func CompactMethod(m Method) (Method, error) {
+	if m.Adaptive {
+		return Method{}, &config.ValidationError{Field: "spatial_order", Message: "fourth-order compact differences are not available with adaptive time steps"}
+	}
+	if m.wide {
+		return Method{}, &config.ValidationError{Field: "stencil", Message: "the compact and the wide stencil cannot be combined"}
+	}
+	var theta float64
+	switch m.Name {
+	case "BTCS":
+		theta = 1
+	case "CN":
+		theta = 0.5
+	default:
+		return Method{}, &config.ValidationError{Field: "spatial_order", Message: fmt.Sprintf("fourth-order compact differences are available for BTCS and CN, not %s", m.Name)}
+	}
+	m.OrderSpace = 4
+	m.Description += "; fourth-order compact differences in x"
+	m.Amplification = CompactAmplification(theta)
+	m.System = func(n int, r float64) (a, b, c []float64) {
+		return constantSystem(n, 1.0/12-theta*r, 5.0/6+2*theta*r, 1.0/12-theta*r)
+	}
+	m.setTheta(theta)
+	return m, nil
+}
+
+// Solve, Stream и solveInto θ-схемы с вариантами из полей m: Startup —
+// старт Раннахера, OrderSpace = 4 — компактная схема, Reaction — реакция,
+// Source — источник. Обёртки RannacherMethod, CompactMethod, ReactionMethod
+// и SourceMethod так сочетаются в любом порядке
+func (m *Method) setTheta(theta float64) {
+	opt := thetaOptions{startup: m.Startup, compact: m.OrderSpace == 4 && !m.wide, reaction: m.Reaction, source: m.Source}
+	m.Solve = func(u0 []float64, nt int, dx, dt float64) [][]float64 {
+		u := newGrid(nt+1, len(u0))
+		thetaSchemeWith(u0, nt, dx, dt, 1, theta, opt, u, nil)
+		return u
+	}
+	m.Stream = func(u0 []float64, nt int, dx, dt float64, emit EmitFunc) ([]float64, error) {
+		u := newGrid(2, len(u0))
+		if err := thetaSchemeWith(u0, nt, dx, dt, 1, theta, opt, u, emit); err != nil {
+			return nil, err
+		}
+		return level(u, nt), nil
+	}
+	m.solveInto = func(u0 []float64, nt int, dx, dt float64, u [][]float64) error {
+		return thetaSchemeWith(u0, nt, dx, dt, 1, theta, opt, u, nil)
+	}
+}
+
+// Множитель перехода компактной θ-схемы с весом w:
+// G = (1 − s²/3 − (1−w)q)/(1 − s²/3 + wq), s = sin(θ/2), q = 4r·s².
+// Множитель 1 − s²/3 ≥ ⅔ — символ (1 + δ²/12)
+func CompactAmplification(w float64) AmplificationFunc {
+	return func(r, theta float64) float64 {
+		s := math.Sin(theta / 2)
+		q := 4 * r * s * s
+		mass := 1 - s*s/3
+		return (mass - (1-w)*q) / (mass + w*q)
+	}
+}
+
+// Проверочные случаи компактной схемы; в Verify — под именем COMPACT
+var verificationCompact = []VerifyCase{
+	compactOrderCase(0.1, 0.001, 0.1, 4, 0.1),
+}
+
+// Наблюдаемый порядок компактного CN по dx на sin(πx) за два деления dx
+// пополам; dt делится на 4, так что ошибка по времени O(dt²) убывает так
+// же, как по пространству. В подробностях — ошибки обычного CN на тех же
+// сетках
+func compactOrderCase(dx, dt, tmax, order, tol float64) VerifyCase {
+	return VerifyCase{
+		Name:      fmt.Sprintf("CN sine order from dx=%g dt=%g", dx, dt),
+		Quantity:  "observed order",
+		Compare:   VerifyNear,
+		Expected:  order,
+		Tolerance: tol,
+		Measure: func(ctx context.Context, _ Method) (float64, string, error) {
+			var errs, plain [3]float64
+			for k := range errs {
+				h, tau := dx/math.Exp2(float64(k)), dt/math.Pow(4, float64(k))
+				nx, nt := int(math.Round(1/h)), int(math.Round(tmax/tau))
+				t := float64(nt) * tau
+				u0 := SineProfile(nx, h)
+				errs[k], _ = mathutils.LevelErrors(SolveCompactThetaFrom(u0, nt, h, tau, 1, 0.5)[nt], h, t, mathutils.Analytical{Alpha: 1})
+				plain[k], _ = mathutils.LevelErrors(SolveCrankNicolsonFrom(u0, nt, h, tau)[nt], h, t, mathutils.Analytical{Alpha: 1})
+			}
+			first, second := math.Log2(errs[0]/errs[1]), math.Log2(errs[1]/errs[2])
+			return second, fmt.Sprintf("errors %.3g, %.3g, %.3g; orders %.3f, %.3f; second-order CN %.3g, %.3g, %.3g",
+				errs[0], errs[1], errs[2], first, second, plain[0], plain[1], plain[2]), nil
+		},
+	}
+}
@@ -0,0 +1,124 @@
+package solver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"testing"
+
+	"heat-solver/internal/config"
+	"heat-solver/internal/mathutils"
+)
+
+// sin(πx) — собственный вектор и δ², и (1 + δ²/12): за шаг он умножается
+// ровно на CompactAmplification(θ) при угле π·dx
+func TestCompactSineMode(t *testing.T) {
+	tests := []struct {
+		theta float64
+		nx    int
+		r     float64
+	}{
+		{0.5, 10, 0.4},
+		{0.5, 40, 10},
+		{1, 16, 2},
+		{1, 64, 100},
+	}
+	for _, tc := range tests {
+		t.Run(fmt.Sprintf("θ=%g/nx=%d/r=%g", tc.theta, tc.nx, tc.r), func(t *testing.T) {
+			const nt = 8
+			dx := 1 / float64(tc.nx)
+			g := CompactAmplification(tc.theta)(tc.r, math.Pi*dx)
+			u := SolveCompactThetaFrom(SineProfile(tc.nx, dx), nt, dx, tc.r*dx*dx, 1, tc.theta)
+			for n := range u {
+				scale := math.Pow(g, float64(n))
+				for i, v := range u[n] {
+					if math.Abs(v-scale*u[0][i]) > 1e-14 {
+						t.Fatalf("level %d, node %d: %v, want G^n·u0 = %v", n, i, v, scale*u[0][i])
+					}
+				}
+			}
+		})
+	}
+}
+
+// Через Run с SpatialOrder = 4 ошибка CN на sin(πx) падает в 16 раз при
+// делении dx пополам (dt — вчетверо); без него путь прежний: второй
+// порядок и слои побитно те же, что у SolveCrankNicolsonFrom
+func TestCompactSpatialOrder(t *testing.T) {
+	tests := []struct {
+		order int
+		tol   float64
+	}{
+		{2, 0.05},
+		{4, 0.15},
+	}
+	for _, tc := range tests {
+		t.Run(fmt.Sprintf("order %d", tc.order), func(t *testing.T) {
+			var prev float64
+			for k, nx := range []int{10, 20, 40} {
+				dx := 1 / float64(nx)
+				dt := 0.1 * dx * dx
+				p := config.Params{Method: "CN", Dx: dx, Dt: dt, Tmax: 0.1, SpatialOrder: tc.order, Storage: config.StorageFinalOnly}.Normalize()
+				res, err := Run(context.Background(), p, Hooks{})
+				if err != nil {
+					t.Fatal(err)
+				}
+				l2, _ := mathutils.LevelErrors(res.Final, dx, float64(res.Nt)*dt, mathutils.Analytical{Alpha: 1})
+				if tc.order == 2 {
+					if plain := SolveCrankNicolsonFrom(SineProfile(nx, dx), res.Nt, dx, dt)[res.Nt]; !slices.Equal(res.Final, plain) {
+						t.Errorf("nx=%d: default spatial order differs from plain CN", nx)
+					}
+				}
+				res.Release()
+				if k > 0 {
+					if order := math.Log2(prev / l2); math.Abs(order-float64(tc.order)) > tc.tol {
+						t.Errorf("nx=%d: error %.3g → %.3g, observed order %.2f", nx, prev, l2, order)
+					}
+				}
+				prev = l2
+			}
+		})
+	}
+}
+
+func TestCompactMethod(t *testing.T) {
+	tests := []struct {
+		method string
+		theta  float64
+		field  string // поле ошибки; пусто — метод принят
+	}{
+		{"CN", 0.5, ""},
+		{"BTCS", 1, ""},
+		{"FTCS", 0, "spatial_order"},
+		{"RK45", 0, "spatial_order"},
+		{"BDF2", 0, "spatial_order"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.method, func(t *testing.T) {
+			m, err := CompactMethod(mustLookup(t, tc.method))
+			if tc.field != "" {
+				var verr *config.ValidationError
+				if !errors.As(err, &verr) || verr.Field != tc.field {
+					t.Fatalf("error %v, want a validation error on %s", err, tc.field)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if m.OrderSpace != 4 {
+				t.Errorf("order in space %d, want 4", m.OrderSpace)
+			}
+			amp := CompactAmplification(tc.theta)
+			for _, r := range []float64{0.1, 1, 50} {
+				for _, angle := range []float64{0.3, 2, math.Pi} {
+					if got, want := m.Amplification(r, angle), amp(r, angle); got != want {
+						t.Errorf("G(%g, %g) = %v, want %v", r, angle, got, want)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,259 @@
+package solver
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+
+	"heat-solver/internal/mathutils"
+)
+
+// Несовместимость начального профиля с краевым условием в момент 0. У
+// условия Дирихле Initial — u0 на краю, Required — g(0); у условия
+// Неймана — ∂u0/∂x и g(0); у условия Робина — ∂u0/∂n и −β·(u0_b − u_amb).
+// Производная — односторонняя второго порядка по трём узлам
+type Mismatch struct {
+	Side              string
+	Kind              string
+	Initial, Required float64
+	// |Initial − Required|, у условий на производную — умноженное на dx:
+	// это скачок значения у края, который видит сетка, и с допуском
+	// сравнивается величина того же рода, что у условия Дирихле. Гладкий
+	// профиль, согласованный с условием аналитически, даёт здесь O(dx³)
+	// от погрешности разности
+	Size float64
+}
+
+// Стороны отрезка для Mismatch.Side
+const (
+	SideLeft  = "left"
+	SideRight = "right"
+)
+
+// Проверка совместимости u0 (nx+1 значений) с условиями left и right в
+// момент 0: несовместимости с Size > tol. CN на несовместимых данных даёт
+// долгий переходный процесс у края (как у разрывной ступеньки), и нормы
+// ошибки в начале расчёта о схеме ничего не говорят
+func CheckCompatibility(u0 []float64, dx float64, left, right mathutils.Boundary, tol float64) []Mismatch {
+	var found []Mismatch
+	for _, m := range []Mismatch{endMismatch(u0, dx, left, SideLeft), endMismatch(u0, dx, right, SideRight)} {
+		if m.Size > tol || math.IsNaN(m.Size) {
+			found = append(found, m)
+		}
+	}
+	return found
+}
+
+// Узлы края: сам край, его соседи внутрь и знак внешней нормали
+func endNodes(nx int, side string) (b, in1, in2 int, outward float64) {
+	if side == SideLeft {
+		return 0, 1, 2, -1
+	}
+	return nx, nx - 1, nx - 2, 1
+}
+
+// Несовместимость на одном крае
+func endMismatch(u0 []float64, dx float64, bc mathutils.Boundary, side string) Mismatch {
+	b, in1, in2, outward := endNodes(len(u0)-1, side)
+	m := Mismatch{Side: side, Kind: bc.String()}
+	if bc.IsDirichlet() {
+		m.Initial, m.Required = u0[b], bc.Value(0)
+		m.Size = math.Abs(m.Initial - m.Required)
+		return m
+	}
+	// ∂u/∂n по внешней нормали: (3u_b − 4u_in1 + u_in2)/(2dx)
+	dn := (3*u0[b] - 4*u0[in1] + u0[in2]) / (2 * dx)
+	beta, q := ghostEnd(bc, outward)
+	if bc.IsNeumann() {
+		m.Initial, m.Required = outward*dn, bc.Value(0)
+	} else {
+		m.Initial, m.Required = dn, q(0)-beta*u0[b]
+	}
+	m.Size = dx * math.Abs(m.Initial-m.Required)
+	return m
+}
+
+// Согласование u0 с условиями на краях правкой nodes ближайших к каждому
+// краю узлов (остальные не меняются). У условия Дирихле к узлам
+// добавляется δ·(1 − i/nodes), δ = g(0) − u0_b: край становится ровно
+// g(0), поправка линейно сходит на нет. У условий Неймана и Робина
+// добавляется a·(1 − i/nodes)² с a из условия: односторонняя разность
+// точна на квадратичной поправке, и после правки условие выполняется до
+// округления. nodes не меньше 2 и не больше nx/2, чтобы правки краёв не
+// перекрывались
+func SmoothStart(u0 []float64, dx float64, left, right mathutils.Boundary, nodes int) ([]float64, error) {
+	nx := len(u0) - 1
+	if nodes < 2 || 2*nodes > nx {
+		return nil, fmt.Errorf("smoothing needs 2 ≤ nodes ≤ nx/2 = %d, got %d", nx/2, nodes)
+	}
+	u := append([]float64(nil), u0...)
+	for _, end := range []struct {
+		bc   mathutils.Boundary
+		side string
+	}{{left, SideLeft}, {right, SideRight}} {
+		b, _, _, outward := endNodes(nx, end.side)
+		step := -int(outward) // от края внутрь
+		m := nodes
+		if end.bc.IsDirichlet() {
+			delta := end.bc.Value(0) - u[b]
+			for i := 0; i < m; i++ {
+				u[b+step*i] += delta * (1 - float64(i)/float64(m))
+			}
+			continue
+		}
+		// F(u) = ∂u/∂n + β·u_b − q(0) линейна по u; у ψ_i = (1 − i/m)²
+		// ∂ψ/∂n = 2/(m·dx) и ψ_b = 1
+		beta, q := ghostEnd(end.bc, outward)
+		dn := (3*u[b] - 4*u[b+step] + u[b+2*step]) / (2 * dx)
+		residual := dn + beta*u[b] - q(0)
+		a := -residual / (2/(float64(m)*dx) + beta)
+		for i := 0; i < m; i++ {
+			s := 1 - float64(i)/float64(m)
+			u[b+step*i] += a * s * s
+		}
+	}
+	return u, nil
+}
+
+// Проверочные случаи проверки совместимости; в Verify — под именем COMPAT
+var verificationCompat = []VerifyCase{
+	compatDetectCase("u0=1 with cold walls", compatUniform, "dirichlet:0", "dirichlet:0", SideLeft, SideRight),
+	compatDetectCase("u0=x with cold walls", compatLinear, "dirichlet:0", "dirichlet:0", SideRight),
+	compatDetectCase("u0=1 with u(0)=1, u(1)=0", compatUniform, "dirichlet:1", "dirichlet:0", SideRight),
+	compatDetectCase("sin(πx) with cold walls", compatMode, "dirichlet:0", "dirichlet:0"),
+	compatDetectCase("cos(πx/2) with insulated left", compatMode, "neumann:0", "dirichlet:0"),
+	compatDetectCase("sin(πx/2) with insulated right", compatMode, "dirichlet:0", "neumann:0"),
+	compatDetectCase("u0=1 with flux 1 on the left", compatUniform, "neumann:1", "dirichlet:1", SideLeft),
+	compatDetectCase("u0=1 with flux 1 on the right", compatUniform, "dirichlet:1", "neumann:1", SideRight),
+	compatDetectCase("u0=1 with robin h=10 on the right", compatUniform, "neumann:0", "robin:h=10,uamb=0", SideRight),
+	compatSmoothCase("dirichlet:0", "dirichlet:0"),
+	compatSmoothCase("neumann:1", "robin:h=10,uamb=0.5"),
+	compatRannacherCase(0.05, 0.01, 0.1, 0.1),
+}
+
+// Начальные профили проверочных случаев на nx отрезках
+type compatProfile func(nx int, dx float64, left, right mathutils.Boundary) []float64
+
+func compatUniform(nx int, _ float64, _, _ mathutils.Boundary) []float64 {
+	u0 := make([]float64, nx+1)
+	for i := range u0 {
+		u0[i] = 1
+	}
+	return u0
+}
+
+func compatLinear(nx int, dx float64, _, _ mathutils.Boundary) []float64 {
+	u0 := make([]float64, nx+1)
+	for i := range u0 {
+		u0[i] = float64(i) * dx
+	}
+	return u0
+}
+
+func compatMode(nx int, dx float64, left, right mathutils.Boundary) []float64 {
+	return BoundaryProfile(nx, dx, left, right)
+}
+
+// Условия left и right из записи, как у -bc-left
+func compatEnds(left, right string) (mathutils.Boundary, mathutils.Boundary, error) {
+	l, err := mathutils.ParseBoundary(left)
+	if err != nil {
+		return l, l, err
+	}
+	r, err := mathutils.ParseBoundary(right)
+	return l, r, err
+}
+
+// Проверка на nx = 10 (dx = 0.1 — по умолчанию у -dx) с допуском 1e-3 (по
+// умолчанию у -compat-tol) находит несовместимость ровно на сторонах
+// sides. Значение — число сторон, где найденное расходится с ожидаемым
+func compatDetectCase(name string, profile compatProfile, left, right string, sides ...string) VerifyCase {
+	return VerifyCase{
+		Name:     fmt.Sprintf("%s (%s, %s)", name, left, right),
+		Quantity: "wrongly flagged sides",
+		Compare:  VerifyAtMost,
+		Expected: 0,
+		Measure: func(ctx context.Context, _ Method) (float64, string, error) {
+			const nx = 10
+			dx := 1.0 / nx
+			l, r, err := compatEnds(left, right)
+			if err != nil {
+				return math.NaN(), "", err
+			}
+			u0 := profile(nx, dx, l, r)
+			all := []Mismatch{endMismatch(u0, dx, l, SideLeft), endMismatch(u0, dx, r, SideRight)}
+			found := CheckCompatibility(u0, dx, l, r, 1e-3)
+			var wrong int
+			var sizes []string
+			for _, m := range all {
+				flagged, want := false, false
+				for _, f := range found {
+					flagged = flagged || f.Side == m.Side
+				}
+				for _, s := range sides {
+					want = want || s == m.Side
+				}
+				if flagged != want {
+					wrong++
+				}
+				sizes = append(sizes, fmt.Sprintf("%s %.3g", m.Side, m.Size))
+			}
+			return float64(wrong), "mismatch " + strings.Join(sizes, ", "), nil
+		},
+	}
+}
+
+// SmoothStart на u0 = 1 (nx = 20, 4 узла у каждого края) снимает
+// несовместимость: наибольший остаток Size после правки
+func compatSmoothCase(left, right string) VerifyCase {
+	return VerifyCase{
+		Name:     fmt.Sprintf("smoothing u0=1 (%s, %s)", left, right),
+		Quantity: "max mismatch after smoothing",
+		Compare:  VerifyAtMost,
+		Expected: 1e-12,
+		Measure: func(ctx context.Context, _ Method) (float64, string, error) {
+			const nx, nodes = 20, 4
+			dx := 1.0 / nx
+			l, r, err := compatEnds(left, right)
+			if err != nil {
+				return math.NaN(), "", err
+			}
+			u0 := compatUniform(nx, dx, l, r)
+			before := max(endMismatch(u0, dx, l, SideLeft).Size, endMismatch(u0, dx, r, SideRight).Size)
+			u, err := SmoothStart(u0, dx, l, r, nodes)
+			if err != nil {
+				return math.NaN(), "", err
+			}
+			after := max(endMismatch(u, dx, l, SideLeft).Size, endMismatch(u, dx, r, SideRight).Size)
+			return after, fmt.Sprintf("before %.3g; interior node %d unchanged: %t", before, nx/2, u[nx/2] == u0[nx/2]), nil
+		},
+	}
+}
+
+// Переходный процесс CN из u0 = 1 с холодными краями при r = dt/dx² ≫ 1:
+// ошибка L∞ в момент tmax против ряда ступеньки на (0, 1) со стартом
+// Раннахера (-compat rannacher) и без него. Значение — их отношение
+func compatRannacherCase(dx, dt, tmax, bound float64) VerifyCase {
+	return VerifyCase{
+		Name:     fmt.Sprintf("CN from u0=1 with cold walls dx=%g dt=%g", dx, dt),
+		Quantity: "L∞ with / without Rannacher",
+		Compare:  VerifyAtMost,
+		Expected: bound,
+		Measure: func(ctx context.Context, _ Method) (float64, string, error) {
+			nx := int(math.Round(1 / dx))
+			nt := int(math.Round(tmax / dt))
+			var cold mathutils.Boundary
+			exact := mathutils.NewTopHatSeries(0, 1, topHatModes)
+			var errs [2]float64
+			for k, startup := range []int{0, RannacherSteps} {
+				last, err := StreamBoundaryFrom(compatUniform(nx, dx, cold, cold), nt, dx, dt, 1, 0.5, startup, cold, cold, nil)
+				if err != nil {
+					return math.NaN(), "", err
+				}
+				_, errs[k] = mathutils.LevelErrors(last, dx, float64(nt)*dt, exact)
+			}
+			return errs[1] / errs[0], fmt.Sprintf("L∞ %.3g without, %.3g with", errs[0], errs[1]), nil
+		},
+	}
+}
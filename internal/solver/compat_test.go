@@ -0,0 +1,173 @@
+package solver
+
+import (
+	"math"
+	"slices"
+	"testing"
+
+	"heat-solver/internal/mathutils"
+)
+
+// Несовместимость находится ровно на тех краях, где u0 не согласуется с
+// условием в момент 0: Дирихле на обоих краях и на одном, Нейман и Робин;
+// первые моды с однородными условиями не помечаются
+func TestCheckCompatibility(t *testing.T) {
+	tests := []struct {
+		name        string
+		profile     compatProfile
+		left, right string
+		sides       []string
+	}{
+		{"u0=1 with cold walls", compatUniform, "dirichlet:0", "dirichlet:0", []string{SideLeft, SideRight}},
+		{"u0=x with cold walls", compatLinear, "dirichlet:0", "dirichlet:0", []string{SideRight}},
+		{"u0=1 with u(0)=1", compatUniform, "dirichlet:1", "dirichlet:0", []string{SideRight}},
+		{"u0=1 with g = cos t", compatUniform, "dirichlet:cos(t)", "neumann:sin(t)", nil},
+		{"sine with cold walls", compatMode, "dirichlet:0", "dirichlet:0", nil},
+		{"insulated left", compatMode, "neumann:0", "dirichlet:0", nil},
+		{"insulated right", compatMode, "dirichlet:0", "neumann:0", nil},
+		{"flux on the left", compatUniform, "neumann:1", "dirichlet:1", []string{SideLeft}},
+		{"flux on the right", compatUniform, "dirichlet:1", "neumann:1", []string{SideRight}},
+		{"robin on the right", compatUniform, "neumann:0", "robin:h=10,uamb=0", []string{SideRight}},
+		{"robin at ambient", compatUniform, "robin:h=10,uamb=1", "robin:h=3,uamb=1", nil},
+		{"robin on the left", compatUniform, "robin:h=10,uamb=0", "neumann:0", []string{SideLeft}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			const nx = 10
+			dx := 1.0 / nx
+			l, r, err := compatEnds(tc.left, tc.right)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var sides []string
+			for _, m := range CheckCompatibility(tc.profile(nx, dx, l, r), dx, l, r, 1e-3) {
+				sides = append(sides, m.Side)
+			}
+			if !slices.Equal(sides, tc.sides) {
+				t.Errorf("flagged %v, want %v", sides, tc.sides)
+			}
+		})
+	}
+}
+
+// Поля несовместимости: значения и размер у Дирихле, у Неймана и Робина —
+// производные и размер, умноженный на dx; NaN в u0 — тоже несовместимость
+func TestMismatchFields(t *testing.T) {
+	const nx = 10
+	dx := 1.0 / nx
+	cold := mathutils.Boundary{}
+	u0 := compatUniform(nx, dx, cold, cold)
+	u0[nx] = 0.25
+	found := CheckCompatibility(u0, dx, cold, cold, 1e-3)
+	want := []Mismatch{
+		{Side: SideLeft, Kind: "dirichlet:0", Initial: 1, Required: 0, Size: 1},
+		{Side: SideRight, Kind: "dirichlet:0", Initial: 0.25, Required: 0, Size: 0.25},
+	}
+	if !slices.Equal(found, want) {
+		t.Errorf("mismatches %+v, want %+v", found, want)
+	}
+
+	flux, robin, err := compatEnds("neumann:2", "robin:h=4,uamb=0,k=2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	found = CheckCompatibility(compatUniform(nx, dx, flux, robin), dx, flux, robin, 0)
+	if len(found) != 2 {
+		t.Fatalf("mismatches %+v", found)
+	}
+	// Слева u_x = 0 против 2, справа ∂u/∂n = 0 против −(h/k)·(u_b − u_amb) = −2
+	if m := found[0]; math.Abs(m.Initial) > 1e-14 || m.Required != 2 || math.Abs(m.Size-2*dx) > 1e-14 {
+		t.Errorf("neumann mismatch %+v", m)
+	}
+	if m := found[1]; math.Abs(m.Initial) > 1e-14 || m.Required != -2 || math.Abs(m.Size-2*dx) > 1e-14 {
+		t.Errorf("robin mismatch %+v", m)
+	}
+
+	u0[3] = math.NaN()
+	u0[0], u0[nx] = math.NaN(), 0
+	if found := CheckCompatibility(u0, dx, cold, cold, 1e-3); len(found) != 1 || found[0].Side != SideLeft {
+		t.Errorf("NaN on the edge: %+v", found)
+	}
+}
+
+// Квадратичный профиль, согласованный с условием Неймана аналитически:
+// односторонняя разность на нём точна, и размер — на уровне округления
+func TestCompatibilityQuadratic(t *testing.T) {
+	const nx = 16
+	dx := 1.0 / nx
+	left, right, err := compatEnds("neumann:0", "neumann:1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	u0 := make([]float64, nx+1)
+	for i := range u0 {
+		x := float64(i) * dx
+		u0[i] = x*x/2 + 3
+	}
+	if found := CheckCompatibility(u0, dx, left, right, 1e-14); len(found) != 0 {
+		t.Errorf("compatible quadratic flagged: %+v", found)
+	}
+}
+
+// Правка снимает несовместимость до округления у любого вида условия,
+// край Дирихле становится g(0), а узлы дальше nodes от краёв не меняются
+func TestSmoothStart(t *testing.T) {
+	ends := [][2]string{
+		{"dirichlet:0", "dirichlet:0"},
+		{"dirichlet:0.5", "neumann:-1"},
+		{"neumann:1", "robin:h=10,uamb=0.5"},
+		{"robin:h=2,uamb=3,k=0.5", "dirichlet:1 + t"},
+	}
+	const nx, nodes = 20, 4
+	dx := 1.0 / nx
+	for _, e := range ends {
+		l, r, err := compatEnds(e[0], e[1])
+		if err != nil {
+			t.Fatal(err)
+		}
+		u0 := compatUniform(nx, dx, l, r)
+		u0[nx/2] = 7
+		u, err := SmoothStart(u0, dx, l, r, nodes)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if found := CheckCompatibility(u, dx, l, r, 1e-12); len(found) != 0 {
+			t.Errorf("%v: still incompatible %+v", e, found)
+		}
+		if !slices.Equal(u[nodes:nx-nodes+1], u0[nodes:nx-nodes+1]) {
+			t.Errorf("%v: interior changed: %v", e, u)
+		}
+		if l.IsDirichlet() && u[0] != l.Value(0) || r.IsDirichlet() && u[nx] != r.Value(0) {
+			t.Errorf("%v: edges %v, %v", e, u[0], u[nx])
+		}
+		if u0[0] != 1 {
+			t.Errorf("%v: input modified", e)
+		}
+	}
+	cold := mathutils.Boundary{}
+	for _, n := range []int{1, 11} {
+		if _, err := SmoothStart(make([]float64, nx+1), dx, cold, cold, n); err == nil {
+			t.Errorf("nodes = %d accepted", n)
+		}
+	}
+}
+
+// CN из u0 = 1 с холодными краями при r = 4: старт Раннахера снижает
+// ошибку переходного процесса больше чем в десять раз
+func TestCompatibilityRannacher(t *testing.T) {
+	const dx, dt, tmax = 0.05, 0.01, 0.1
+	nx, nt := int(math.Round(1/dx)), int(math.Round(tmax/dt))
+	var cold mathutils.Boundary
+	exact := mathutils.NewTopHatSeries(0, 1, topHatModes)
+	var errs [2]float64
+	for k, startup := range []int{0, RannacherSteps} {
+		last, err := StreamBoundaryFrom(compatUniform(nx, dx, cold, cold), nt, dx, dt, 1, 0.5, startup, cold, cold, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, errs[k] = mathutils.LevelErrors(last, dx, float64(nt)*dt, exact)
+	}
+	if errs[1] > errs[0]/10 {
+		t.Errorf("L∞ %.3g without Rannacher, %.3g with", errs[0], errs[1])
+	}
+}
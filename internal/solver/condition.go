@@ -0,0 +1,128 @@
+package solver
+
+import "math"
+
+// Матрица шага неявной схемы на n внутренних узлах при r = dt/dx²:
+// поддиагональ a, диагональ b, наддиагональ c
+type SystemFunc func(n int, r float64) (a, b, c []float64)
+
+// Постоянная трёхдиагональная матрица (lower, diag, upper)
+func constantSystem(n int, lower, diag, upper float64) (a, b, c []float64) {
+	a, b, c = make([]float64, n), make([]float64, n), make([]float64, n)
+	for i := range n {
+		a[i], b[i], c[i] = lower, diag, upper
+	}
+	return a, b, c
+}
+
+// κ∞ матрицы шага метода m на сетке с nx интервалами (оценка
+// ConditionInf). Матрица BTCS и CN постоянна, поэтому считается один раз
+// на расчёт; 0 — у метода нет системы
+func StepCondition(m Method, nx int, dx, dt float64) float64 {
+	if m.System == nil || nx < 2 {
+		return 0
+	}
+	a, b, c := m.System(nx-1, dt/(dx*dx))
+	return ConditionInf(a, b, c)
+}
+
+// Итераций оценки Хейгера; обычно хватает двух-трёх
+const conditionIterations = 5
+
+// Оценка числа обусловленности κ∞ = ‖A‖∞·‖A⁻¹‖∞ трёхдиагональной
+// матрицы (a[0] и c[n−1] не входят). ‖A⁻¹‖∞ = ‖A⁻ᵀ‖₁ оценивается
+// методом Хейгера–Хайема: несколько прогонок с A и Aᵀ вместо обращения.
+// Оценка снизу и обычно совпадает с точным значением или отличается от
+// него в несколько раз. Для вырожденной матрицы — +Inf
+func ConditionInf(a, b, c []float64) float64 {
+	n := len(b)
+	if n == 0 {
+		return 0
+	}
+	var normA float64
+	for i := range n {
+		row := math.Abs(b[i])
+		if i > 0 {
+			row += math.Abs(a[i])
+		}
+		if i < n-1 {
+			row += math.Abs(c[i])
+		}
+		normA = max(normA, row)
+	}
+
+	// Aᵀ: поддиагональ строки i — c[i−1], наддиагональ — a[i+1]
+	at, ct := make([]float64, n), make([]float64, n)
+	for i := 1; i < n; i++ {
+		at[i] = c[i-1]
+		ct[i-1] = a[i]
+	}
+	direct, transposed := newThomas(n), newThomas(n)
+	defer direct.close()
+	defer transposed.close()
+	direct.factor(a, b, c)
+	transposed.factor(at, b, ct)
+
+	// B = A⁻ᵀ: B·v — прогонка с Aᵀ, Bᵀ·v — с A
+	x, y, z := make([]float64, n), make([]float64, n), make([]float64, n)
+	for i := range x {
+		x[i] = 1 / float64(n)
+	}
+	var est float64
+	last := -1
+	for range conditionIterations {
+		transposed.solve(x, y)
+		est = norm1(y)
+		for i, v := range y {
+			x[i] = 1
+			if v < 0 {
+				x[i] = -1
+			}
+		}
+		direct.solve(x, z)
+		j, zmax, zx := 0, 0.0, 0.0
+		for i, v := range z {
+			if math.Abs(v) > zmax {
+				j, zmax = i, math.Abs(v)
+			}
+		}
+		// Скалярное произведение z на предыдущий x: x — единичный вектор
+		// e_last, на первой итерации — 1/n
+		if last < 0 {
+			for _, v := range z {
+				zx += v / float64(n)
+			}
+		} else {
+			zx = z[last]
+		}
+		if zmax <= zx || j == last {
+			break
+		}
+		clear(x)
+		x[j] = 1
+		last = j
+	}
+
+	// Знакопеременный вектор Хайема ловит случаи, где итерация застревает
+	for i := range x {
+		x[i] = 1 + float64(i)/float64(max(n-1, 1))
+		if i%2 == 1 {
+			x[i] = -x[i]
+		}
+	}
+	transposed.solve(x, y)
+	est = max(est, 2*norm1(y)/(3*float64(n)))
+
+	if math.IsNaN(est) || math.IsInf(est, 0) {
+		return math.Inf(1)
+	}
+	return normA * est
+}
+
+func norm1(v []float64) float64 {
+	var s float64
+	for _, x := range v {
+		s += math.Abs(x)
+	}
+	return s
+}
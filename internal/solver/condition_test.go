@@ -0,0 +1,126 @@
+package solver
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// Точное κ∞ по плотной обратной матрице (Гаусс — Жордан с выбором
+// ведущего элемента); для проверок на малых n
+func denseConditionInf(a, b, c []float64) float64 {
+	n := len(b)
+	m := make([][]float64, n)
+	for i := range m {
+		m[i] = make([]float64, 2*n)
+		m[i][i] = b[i]
+		if i > 0 {
+			m[i][i-1] = a[i]
+		}
+		if i < n-1 {
+			m[i][i+1] = c[i]
+		}
+		m[i][n+i] = 1
+	}
+	var normA float64
+	for _, row := range m {
+		var s float64
+		for _, v := range row[:n] {
+			s += math.Abs(v)
+		}
+		normA = max(normA, s)
+	}
+	for k := range n {
+		p := k
+		for i := k + 1; i < n; i++ {
+			if math.Abs(m[i][k]) > math.Abs(m[p][k]) {
+				p = i
+			}
+		}
+		m[k], m[p] = m[p], m[k]
+		if m[k][k] == 0 {
+			return math.Inf(1)
+		}
+		pivot := m[k][k]
+		for j := range m[k] {
+			m[k][j] /= pivot
+		}
+		for i := range n {
+			if i != k && m[i][k] != 0 {
+				f := m[i][k]
+				for j := range m[i] {
+					m[i][j] -= f * m[k][j]
+				}
+			}
+		}
+	}
+	var normInv float64
+	for _, row := range m {
+		var s float64
+		for _, v := range row[n:] {
+			s += math.Abs(v)
+		}
+		normInv = max(normInv, s)
+	}
+	return normA * normInv
+}
+
+// Оценка не больше точного κ∞ и не меньше его трети: матрицы BTCS и CN
+// при r от 10⁻³ до 10⁴ и случайные несимметричные матрицы
+func TestConditionInf(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	type system struct {
+		name    string
+		a, b, c []float64
+	}
+	var systems []system
+	for _, name := range []string{"BTCS", "CN"} {
+		m := mustLookup(t, name)
+		for _, n := range []int{1, 2, 9, 50} {
+			for _, r := range []float64{1e-3, 0.5, 10, 1e4} {
+				a, b, c := m.System(n, r)
+				systems = append(systems, system{fmt.Sprintf("%s n=%d r=%g", name, n, r), a, b, c})
+			}
+		}
+	}
+	for k := range 20 {
+		n := 2 + rng.Intn(40)
+		a, b, c := make([]float64, n), make([]float64, n), make([]float64, n)
+		for i := range n {
+			a[i], b[i], c[i] = rng.NormFloat64(), rng.NormFloat64(), rng.NormFloat64()
+		}
+		systems = append(systems, system{fmt.Sprintf("random %d n=%d", k, n), a, b, c})
+	}
+
+	for _, s := range systems {
+		t.Run(s.name, func(t *testing.T) {
+			est, exact := ConditionInf(s.a, s.b, s.c), denseConditionInf(s.a, s.b, s.c)
+			if !(est <= exact*(1+1e-9) && est >= exact/3) {
+				t.Errorf("estimate %.6g, exact %.6g", est, exact)
+			}
+		})
+	}
+}
+
+func TestConditionInfEdgeCases(t *testing.T) {
+	if k := ConditionInf(nil, nil, nil); k != 0 {
+		t.Errorf("empty matrix: κ = %v, want 0", k)
+	}
+	if k := ConditionInf([]float64{0, 1}, []float64{1, 1}, []float64{1, 0}); !math.IsInf(k, 1) {
+		t.Errorf("singular matrix: κ = %v, want +Inf", k)
+	}
+	if k := ConditionInf([]float64{0}, []float64{-4}, []float64{0}); k != 1 {
+		t.Errorf("1×1 matrix: κ = %v, want 1", k)
+	}
+	if k := StepCondition(mustLookup(t, "FTCS"), 100, 0.01, 1e-5); k != 0 {
+		t.Errorf("explicit scheme: κ = %v, want 0", k)
+	}
+	// κ BTCS растёт как 1 + 4r
+	for _, r := range []float64{1, 100, 1e4} {
+		k := StepCondition(mustLookup(t, "BTCS"), 200, 0.005, r*0.005*0.005)
+		if k < 1 || k > 1+4*r {
+			t.Errorf("BTCS r=%g: κ = %v, want within [1, 1+4r]", r, k)
+		}
+	}
+}
@@ -0,0 +1,227 @@
+package solver
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+)
+
+// Уравнение с проводимостью, зависящей от температуры: u_t = (k(u)·u_x)_x
+// на [0, 1] с нулём на краях. Шаг θ-взвешенный (θ > 0):
+// u_i^{n+1} − u_i^n = θ·dt·L(u^{n+1}) + (1−θ)·dt·L(u^n), где
+// L(u)_i = (k_{i+½}(u_{i+1} − u_i) − k_{i−½}(u_i − u_{i−1}))/dx², а
+// k_{i+½} = k((u_i + u_{i+1})/2). Неявная часть нелинейна и решается
+// итерациями Пикара: проводимости граней берутся с предыдущей итерации,
+// матрица собирается заново, и так до тех пор, пока поправка (max-норма)
+// не станет не больше допуска
+
+// Допуск итераций Пикара по умолчанию (max-норма поправки)
+const DefaultPicardTol = 1e-10
+
+// Наибольшее число итераций Пикара на шаге; дальше расчёт останавливается
+// с ошибкой
+const maxPicardIterations = 100
+
+// Проводимость k(u) = K0·(1 + Beta·u)
+type Conductivity struct {
+	K0, Beta float64
+}
+
+func (k Conductivity) Eval(u float64) float64 {
+	return k.K0 * (1 + k.Beta*u)
+}
+
+// Проводимость с заданным профилем u0; хранятся два слоя, каждый слой
+// передаётся в emit. При β = 0 и K0 = 1 каждый шаг θ = 1 побитно
+// совпадает с BTCS (StreamThetaFrom): матрица та же, а вторая итерация
+// повторяет первую. Неположительная проводимость грани или отсутствие
+// сходимости за maxPicardIterations итераций — ошибка
+func StreamConductivityFrom(u0 []float64, nt int, dx, dt float64, k Conductivity, theta, tol float64, emit EmitFunc) ([]float64, error) {
+	u := newGrid(2, len(u0))
+	if err := conductivity(u0, nt, dx, dt, k, theta, tol, u, emit); err != nil {
+		return nil, err
+	}
+	return level(u, nt), nil
+}
+
+// Проводимости граней k_{i+½}, i = 0..nx−1, по слою row
+func faceConductivity(k Conductivity, row, faces []float64) error {
+	for i := range faces {
+		kf := k.Eval((row[i] + row[i+1]) / 2)
+		if !(kf > 0) || math.IsInf(kf, 0) {
+			return fmt.Errorf("conductivity k(u) = %g at face %d is not positive and finite", kf, i)
+		}
+		faces[i] = kf
+	}
+	return nil
+}
+
+func conductivity(u0 []float64, nt int, dx, dt float64, k Conductivity, theta, tol float64, u [][]float64, emit EmitFunc) error {
+	if !(theta > 0 && theta <= 1) {
+		return fmt.Errorf("Picard iteration needs an implicit step, 0 < θ ≤ 1, not %g", theta)
+	}
+	nx := len(u0) - 1
+	r := dt / (dx * dx)
+	name := "k(u) " + thetaName(theta)
+	slog.Info("Starting "+name+" solver", "nx", nx, "nt", nt, "dx", dx, "dt", dt, "k0", k.K0, "beta", k.Beta, "picard_tol", tol)
+
+	if err := start(u, u0, emit); err != nil {
+		return err
+	}
+
+	a, b, c, d, ws := workspace(nx - 1)
+	defer pool64.put(ws)
+	// Грани слоя n и текущей итерации, сама итерация и правая часть
+	// шага до итераций (явная часть от слоя n)
+	buf := pool64.get(2*nx + 2*(nx+1))
+	defer pool64.put(buf)
+	oldFaces, faces := buf[:nx:nx], buf[nx:2*nx:2*nx]
+	iterate, rhs := buf[2*nx:3*nx+1:3*nx+1], buf[3*nx+1:4*nx+2]
+	// Матрица меняется на каждой итерации, поэтому прогонка — Томас с
+	// новым разложением, без проверки невязки и параллельного варианта
+	tri := newThomas(nx - 1)
+	defer tri.close()
+
+	implicit, explicit := theta*r, (1-theta)*r
+	var total, most int
+	for n := 0; n < nt; n++ {
+		cur, next := level(u, n), level(u, n+1)
+		next[0], next[nx] = 0, 0
+		if explicit == 0 {
+			copy(rhs[1:nx], cur[1:nx])
+		} else {
+			if err := faceConductivity(k, cur, oldFaces); err != nil {
+				return fmt.Errorf("step %d: %w", n+1, err)
+			}
+			for i := 1; i < nx; i++ {
+				rhs[i] = cur[i] + explicit*(oldFaces[i]*(cur[i+1]-cur[i])-oldFaces[i-1]*(cur[i]-cur[i-1]))
+			}
+		}
+		copy(iterate, cur)
+		converged := false
+		var update float64
+		for m := 1; m <= maxPicardIterations; m++ {
+			if err := faceConductivity(k, iterate, faces); err != nil {
+				return fmt.Errorf("step %d, Picard iteration %d: %w", n+1, m, err)
+			}
+			for i := 0; i < nx-1; i++ {
+				a[i] = -implicit * faces[i]
+				b[i] = 1 + implicit*(faces[i]+faces[i+1])
+				c[i] = -implicit * faces[i+1]
+			}
+			copy(d, rhs[1:nx])
+			d[0] += implicit * faces[0] * next[0]
+			d[nx-2] += implicit * faces[nx-1] * next[nx]
+			tri.factor(a, b, c)
+			tri.solve(d, next[1:nx])
+
+			update = 0
+			for i := 1; i < nx; i++ {
+				update = math.Max(update, math.Abs(next[i]-iterate[i]))
+			}
+			copy(iterate, next)
+			if math.IsNaN(update) || math.IsInf(update, 0) {
+				return fmt.Errorf("step %d, Picard iteration %d: %w", n+1, m, ErrDiverged)
+			}
+			if update <= tol {
+				total += m
+				most = max(most, m)
+				converged = true
+				break
+			}
+		}
+		if !converged {
+			return fmt.Errorf("step %d: Picard iteration did not converge in %d iterations, last update %g", n+1, maxPicardIterations, update)
+		}
+		if err := emitLevel(emit, n+1, next); err != nil {
+			return err
+		}
+	}
+
+	if nt > 0 {
+		slog.Info("Picard iterations", "total", total, "max_per_step", most, "mean_per_step", float64(total)/float64(nt))
+	}
+	slog.Info(name + " solver finished successfully")
+	return nil
+}
+
+// Проверочные случаи k(u); в Verify — под именем CONDUCTIVITY
+var verificationConductivity = []VerifyCase{
+	conductivityBTCSCase(0.05, 0.002, 0.1),
+	conductivityOrderCase(1, 1, 0.1, 0.004, 0.1, 4, 0.15),
+	conductivityOrderCase(0.5, 1, 0.1, 0.004, 0.1, 2, 0.15),
+}
+
+// β = 0, K0 = 1 при θ = 1 против BTCS на sin(πx): наибольшая разница
+// должна быть ровно нулём
+func conductivityBTCSCase(dx, dt, tmax float64) VerifyCase {
+	return VerifyCase{
+		Name:     fmt.Sprintf("β=0 against BTCS dx=%g dt=%g", dx, dt),
+		Quantity: "max difference",
+		Compare:  VerifyAtMost,
+		Expected: 0,
+		Measure: func(ctx context.Context, _ Method) (float64, string, error) {
+			if err := ctx.Err(); err != nil {
+				return math.NaN(), "", err
+			}
+			nx, nt := int(math.Round(1/dx)), int(math.Round(tmax/dt))
+			u0 := SineProfile(nx, dx)
+			picard, err := StreamConductivityFrom(u0, nt, dx, dt, Conductivity{K0: 1}, 1, DefaultPicardTol, nil)
+			if err != nil {
+				return math.NaN(), "", err
+			}
+			btcs, err := StreamThetaFrom(u0, nt, dx, dt, 1, 1, nil)
+			if err != nil {
+				return math.NaN(), "", err
+			}
+			var diff float64
+			for i := range btcs {
+				diff = math.Max(diff, math.Abs(picard[i]-btcs[i]))
+			}
+			return diff, fmt.Sprintf("%d steps", nt), nil
+		},
+	}
+}
+
+// Наблюдаемый порядок по dx при k = 1 + β·u из sin(πx) против эталона на
+// сетке в 16 раз мельче (с dt, уменьшенным согласованно): dx делится
+// пополам, dt — на dtFactor (4 у BTCS, 2 у CN), ошибка — max-норма в
+// узлах грубой сетки
+func conductivityOrderCase(theta, beta, dx, dt, tmax, dtFactor, tol float64) VerifyCase {
+	k := Conductivity{K0: 1, Beta: beta}
+	return VerifyCase{
+		Name:      fmt.Sprintf("%s β=%g order from dx=%g dt=%g", thetaName(theta), beta, dx, dt),
+		Quantity:  "observed order",
+		Compare:   VerifyNear,
+		Expected:  2,
+		Tolerance: tol,
+		Measure: func(ctx context.Context, _ Method) (float64, string, error) {
+			run := func(refine int) ([]float64, error) {
+				if err := ctx.Err(); err != nil {
+					return nil, err
+				}
+				h, tau := dx/math.Exp2(float64(refine)), dt/math.Pow(dtFactor, float64(refine))
+				nx := int(math.Round(1 / h))
+				return StreamConductivityFrom(SineProfile(nx, h), int(math.Round(tmax/tau)), h, tau, k, theta, DefaultPicardTol, nil)
+			}
+			ref, err := run(4)
+			if err != nil {
+				return math.NaN(), "", err
+			}
+			var errs [3]float64
+			for l := range errs {
+				last, err := run(l)
+				if err != nil {
+					return math.NaN(), "", err
+				}
+				stride := 1 << (4 - l)
+				for i, v := range last {
+					errs[l] = math.Max(errs[l], math.Abs(v-ref[i*stride]))
+				}
+			}
+			first, second := math.Log2(errs[0]/errs[1]), math.Log2(errs[1]/errs[2])
+			return second, fmt.Sprintf("errors %.3g, %.3g, %.3g against dx=%g; orders %.3f, %.3f", errs[0], errs[1], errs[2], dx/16, first, second), nil
+		},
+	}
+}
@@ -0,0 +1,136 @@
+package solver
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+)
+
+// При β = 0 и K0 = 1 схема — обычная θ-схема: BTCS побитно, CN до
+// округления (правая часть считается через грани)
+func TestConductivityLinear(t *testing.T) {
+	tests := []struct {
+		theta float64
+		r     float64
+		tol   float64
+	}{
+		{1, 0.4, 0},
+		{1, 20, 0},
+		{0.5, 0.4, 1e-15},
+		{0.5, 5, 1e-14},
+	}
+	for _, tc := range tests {
+		t.Run(fmt.Sprintf("θ=%g/r=%g", tc.theta, tc.r), func(t *testing.T) {
+			const nx, nt = 40, 50
+			dx := 1.0 / nx
+			dt := tc.r * dx * dx
+			u0 := SineProfile(nx, dx)
+			got, err := StreamConductivityFrom(u0, nt, dx, dt, Conductivity{K0: 1}, tc.theta, DefaultPicardTol, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want, err := StreamThetaFrom(u0, nt, dx, dt, 1, tc.theta, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i := range want {
+				if math.Abs(got[i]-want[i]) > tc.tol {
+					t.Fatalf("node %d: %v, θ-scheme %v", i, got[i], want[i])
+				}
+			}
+		})
+	}
+}
+
+// k = K0·(1 + βu): K0 только растягивает время, так что K0 = 2 с шагом
+// dt — то же, что K0 = 1 с шагом 2dt
+func TestConductivityTimeScale(t *testing.T) {
+	const nx, nt = 32, 40
+	dx := 1.0 / nx
+	u0 := SineProfile(nx, dx)
+	fast, err := StreamConductivityFrom(u0, nt, dx, 0.001, Conductivity{K0: 2, Beta: 1.5}, 1, 1e-14, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	slow, err := StreamConductivityFrom(u0, nt, dx, 0.002, Conductivity{K0: 1, Beta: 1.5}, 1, 1e-14, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range fast {
+		if math.Abs(fast[i]-slow[i]) > 1e-13 {
+			t.Fatalf("node %d: K0=2 gives %v, K0=1 at 2dt gives %v", i, fast[i], slow[i])
+		}
+	}
+}
+
+// Против эталона на сетке в 16 раз мельче: ошибка в узлах грубой сетки
+// падает вчетверо при делении dx пополам (dt — вчетверо у BTCS и вдвое у
+// CN), а проводимость, растущая с u, ускоряет затухание пика
+func TestConductivityReference(t *testing.T) {
+	tests := []struct {
+		theta, beta, dtFactor float64
+	}{
+		{1, 1, 4},
+		{0.5, 1, 2},
+		{0.5, -0.5, 2},
+	}
+	const dx0, dt0, tmax = 0.1, 0.004, 0.1
+	for _, tc := range tests {
+		t.Run(fmt.Sprintf("θ=%g/β=%g", tc.theta, tc.beta), func(t *testing.T) {
+			k := Conductivity{K0: 1, Beta: tc.beta}
+			run := func(refine int) []float64 {
+				h, tau := dx0/math.Exp2(float64(refine)), dt0/math.Pow(tc.dtFactor, float64(refine))
+				nx := int(math.Round(1 / h))
+				last, err := StreamConductivityFrom(SineProfile(nx, h), int(math.Round(tmax/tau)), h, tau, k, tc.theta, DefaultPicardTol, nil)
+				if err != nil {
+					t.Fatal(err)
+				}
+				return last
+			}
+			ref := run(4)
+			if linear := math.Exp(-math.Pi * math.Pi * tmax); (ref[len(ref)/2] < linear) != (tc.beta > 0) {
+				t.Errorf("peak %v against %v for k = 1", ref[len(ref)/2], linear)
+			}
+			var prev float64
+			for l := range 3 {
+				last := run(l)
+				stride := 1 << (4 - l)
+				var e float64
+				for i, v := range last {
+					e = max(e, math.Abs(v-ref[i*stride]))
+				}
+				if l > 0 {
+					if order := math.Log2(prev / e); math.Abs(order-2) > 0.15 {
+						t.Errorf("refinement %d: error %.3g → %.3g, observed order %.2f", l, prev, e, order)
+					}
+				}
+				prev = e
+			}
+		})
+	}
+}
+
+func TestConductivityRejects(t *testing.T) {
+	const nx = 10
+	dx := 1.0 / nx
+	u0 := SineProfile(nx, dx)
+	tests := []struct {
+		name  string
+		k     Conductivity
+		theta float64
+		want  string
+	}{
+		{"explicit", Conductivity{K0: 1}, 0, "implicit step"},
+		{"negative k0", Conductivity{K0: -1}, 1, "not positive"},
+		{"k crosses zero", Conductivity{K0: 1, Beta: -2}, 1, "not positive"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := StreamConductivityFrom(u0, 5, dx, 0.001, tc.k, tc.theta, DefaultPicardTol, nil)
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("error %v, want one mentioning %q", err, tc.want)
+			}
+		})
+	}
+}
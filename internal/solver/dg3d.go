@@ -0,0 +1,271 @@
+package solver
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+
+	"heat-solver/internal/mathutils"
+)
+
+// Трёхмерные слои — одна строка из (nx+1)·(ny+1)·(nz+1) значений: узел
+// (i, j, k) — элемент (k·(ny+1) + j)·(nx+1) + i, x меняется быстрее всего
+
+// sin(πx)·sin(πy)·sin(πz) на узлах единичного куба
+func SineProfile3D(nx, ny, nz int, dx, dy, dz float64) []float64 {
+	u0 := make([]float64, (nx+1)*(ny+1)*(nz+1))
+	for k := 0; k <= nz; k++ {
+		sz := mathutils.InitialCondition(float64(k) * dz)
+		for j := 0; j <= ny; j++ {
+			syz := mathutils.InitialCondition(float64(j)*dy) * sz
+			row := (k*(ny+1) + j) * (nx + 1)
+			for i := 0; i <= nx; i++ {
+				u0[row+i] = mathutils.InitialCondition(float64(i)*dx) * syz
+			}
+		}
+	}
+	return u0
+}
+
+// ADI Дугласа–Ганна (вариант Кранка–Николсон) в кубе [0, 1]³ с нулевыми
+// гранями, A_x = r_x·δ_x² и т. д.:
+//
+//	(1 − A_x/2)·u*      = (1 + A_x/2 + A_y + A_z)·u^n
+//	(1 − A_y/2)·u**     = u*  − (A_y/2)·u^n
+//	(1 − A_z/2)·u^{n+1} = u** − (A_z/2)·u^n
+//
+// Безусловно устойчива, второго порядка по dt и шагам сетки. Каждый этап —
+// прогонки вдоль линий одной оси, а u* и u** пишутся на место следующего
+// слоя: в памяти только два полных слоя. Возвращается последний слой
+// (хранить все слои в 3D не по карману); слои по ходу — Stream3DDouglasGunnFrom
+func Solve3DDouglasGunn(nx, ny, nz, nt int, dx, dy, dz, dt, alpha float64) []float64 {
+	last, _ := Stream3DDouglasGunnFrom(SineProfile3D(nx, ny, nz, dx, dy, dz), nx, ny, nz, nt, dx, dy, dz, dt, alpha, nil)
+	return last
+}
+
+// Дуглас–Ганн с заданным начальным слоем u0; каждый слой передаётся в emit
+func Stream3DDouglasGunnFrom(u0 []float64, nx, ny, nz, nt int, dx, dy, dz, dt, alpha float64, emit EmitFunc) ([]float64, error) {
+	u := newGrid(2, len(u0))
+	if err := douglasGunn(u0, nx, ny, nz, nt, dx, dy, dz, dt, alpha, u, emit); err != nil {
+		return nil, err
+	}
+	return level(u, nt), nil
+}
+
+// Сетка куба и полушаговые веса h = r/2 по осям
+type cube struct {
+	nx, ny, nz int
+	hx, hy, hz float64
+	sy, sz     int // шаг индекса по y и по z
+}
+
+func (g cube) at(i, j, k int) int {
+	return k*g.sz + j*g.sy + i
+}
+
+// Линии по x и y лежат в плоскостях z = const, линии по z — в плоскостях
+// y = const: этапы x и y делят между потоками плоскости k, этап z —
+// плоскости j. Промежуточные значения на гранях равны заданным значениям
+// u^{n+1} (нулю), как требует схема Дугласа–Ганна
+func douglasGunn(u0 []float64, nx, ny, nz, nt int, dx, dy, dz, dt, alpha float64, u [][]float64, emit EmitFunc) error {
+	rx, ry, rz := alpha*dt/(dx*dx), alpha*dt/(dy*dy), alpha*dt/(dz*dz)
+	g := cube{nx: nx, ny: ny, nz: nz, hx: rx / 2, hy: ry / 2, hz: rz / 2, sy: nx + 1, sz: (nx + 1) * (ny + 1)}
+	slog.Info("Starting 3D Douglas–Gunn solver", "nx", nx, "ny", ny, "nz", nz, "nt", nt, "dt", dt, "r_x", rx, "r_y", ry, "r_z", rz)
+
+	row := level(u, 0)
+	copy(row, u0)
+	zeroFaces3D(row, g)
+	if err := emitLevel(emit, 0, row); err != nil {
+		return err
+	}
+
+	ws := pool64.get(3*(nx-1) + 3*(ny-1) + 3*(nz-1))
+	defer pool64.put(ws)
+	sys := gridRows(ws, 3, nx-1)
+	tx := lineThomas(sys[0], sys[1], sys[2], g.hx)
+	defer tx.close()
+	rest := ws[3*(nx-1):]
+	sys = gridRows(rest, 3, ny-1)
+	ty := lineThomas(sys[0], sys[1], sys[2], g.hy)
+	defer ty.close()
+	sys = gridRows(rest[3*(ny-1):], 3, nz-1)
+	tz := lineThomas(sys[0], sys[1], sys[2], g.hz)
+	defer tz.close()
+
+	// Буферы правой части и линии у каждого фрагмента свои
+	planeMin := max(1, ftcsMinChunk/g.sz)
+	poolK := newChunkPool(1, nz, planeMin)
+	poolJ := newChunkPool(1, ny, planeMin)
+	chunks := 1
+	if poolK != nil {
+		defer poolK.close()
+		chunks = max(chunks, poolK.size())
+	}
+	if poolJ != nil {
+		defer poolJ.close()
+		chunks = max(chunks, poolJ.size())
+	}
+	if chunks > 1 {
+		slog.Info("3D Douglas–Gunn runs in parallel", "threads", chunks)
+	}
+	m := max(nx, ny, nz) - 1
+	bufs := pool64.get(2 * m * chunks)
+	defer pool64.put(bufs)
+	scratch := func(c int) (d, line []float64) {
+		return bufs[2*m*c : 2*m*c+m], bufs[2*m*c+m : 2*m*(c+1)]
+	}
+
+	var cur, next []float64
+	stageXY := func(c, lo, hi int) {
+		d, line := scratch(c)
+		for k := lo; k < hi; k++ {
+			dgSweepX(g, cur, next, k, tx, d)
+		}
+		for k := lo; k < hi; k++ {
+			dgSweepY(g, cur, next, k, ty, d, line)
+		}
+	}
+	stageZ := func(c, lo, hi int) {
+		d, line := scratch(c)
+		for j := lo; j < hi; j++ {
+			dgSweepZ(g, cur, next, j, tz, d, line)
+		}
+	}
+
+	for n := 0; n < nt; n++ {
+		cur, next = level(u, n), level(u, n+1)
+		zeroFaces3D(next, g)
+		if poolK != nil {
+			poolK.run(stageXY)
+		} else {
+			stageXY(0, 1, nz)
+		}
+		if poolJ != nil {
+			poolJ.run(stageZ)
+		} else {
+			stageZ(0, 1, ny)
+		}
+		if err := emitLevel(emit, n+1, next); err != nil {
+			return err
+		}
+	}
+
+	slog.Info("3D Douglas–Gunn solver finished successfully")
+	return nil
+}
+
+// u* в плоскости k: строки по x с правой частью (1 + A_x/2 + A_y + A_z)·u^n
+func dgSweepX(g cube, cur, next []float64, k int, t *thomas, d []float64) {
+	rhs := d[:g.nx-1]
+	for j := 1; j < g.ny; j++ {
+		base := g.at(0, j, k)
+		for i := 1; i < g.nx; i++ {
+			c := base + i
+			u := cur[c]
+			rhs[i-1] = u + g.hx*(cur[c-1]-2*u+cur[c+1]) +
+				2*g.hy*(cur[c-g.sy]-2*u+cur[c+g.sy]) +
+				2*g.hz*(cur[c-g.sz]-2*u+cur[c+g.sz])
+		}
+		rhs[0] += g.hx * next[base]
+		rhs[g.nx-2] += g.hx * next[base+g.nx]
+		t.solve(rhs, next[base+1:base+g.nx])
+	}
+}
+
+// u** в плоскости k на месте u*: столбцы по y с правой частью
+// u* − (A_y/2)·u^n
+func dgSweepY(g cube, cur, next []float64, k int, t *thomas, d, line []float64) {
+	rhs, out := d[:g.ny-1], line[:g.ny-1]
+	for i := 1; i < g.nx; i++ {
+		base := g.at(i, 0, k)
+		for j := 1; j < g.ny; j++ {
+			c := base + j*g.sy
+			rhs[j-1] = next[c] - g.hy*(cur[c-g.sy]-2*cur[c]+cur[c+g.sy])
+		}
+		rhs[0] += g.hy * next[base]
+		rhs[g.ny-2] += g.hy * next[base+g.ny*g.sy]
+		t.solve(rhs, out)
+		for j := 1; j < g.ny; j++ {
+			next[base+j*g.sy] = out[j-1]
+		}
+	}
+}
+
+// u^{n+1} в плоскости j на месте u**: линии по z с правой частью
+// u** − (A_z/2)·u^n
+func dgSweepZ(g cube, cur, next []float64, j int, t *thomas, d, line []float64) {
+	rhs, out := d[:g.nz-1], line[:g.nz-1]
+	for i := 1; i < g.nx; i++ {
+		base := g.at(i, j, 0)
+		for k := 1; k < g.nz; k++ {
+			c := base + k*g.sz
+			rhs[k-1] = next[c] - g.hz*(cur[c-g.sz]-2*cur[c]+cur[c+g.sz])
+		}
+		rhs[0] += g.hz * next[base]
+		rhs[g.nz-2] += g.hz * next[base+g.nz*g.sz]
+		t.solve(rhs, out)
+		for k := 1; k < g.nz; k++ {
+			next[base+k*g.sz] = out[k-1]
+		}
+	}
+}
+
+// Нулевые значения на шести гранях куба
+func zeroFaces3D(row []float64, g cube) {
+	clear(row[:g.sz])
+	clear(row[g.nz*g.sz:])
+	for k := 1; k < g.nz; k++ {
+		zeroEdges2D(row[k*g.sz:(k+1)*g.sz], g.nx, g.ny)
+	}
+}
+
+// Проверочные случаи схемы Дугласа–Ганна; в Verify — под именем
+// "3D DOUGLAS-GUNN" после двумерных схем
+var verification3D = []VerifyCase{
+	// r = 1.28 и выше: шаг заметно больше предела явной схемы (1/6)
+	cubeSineCase(16, 0.005, 0.1, 2e-4),
+	cubeOrderCase(8, 0.01, 0.1, 2, 0.15),
+}
+
+// Ошибка L2 на sin(πx)·sin(πy)·sin(πz) на кубической сетке n³ в момент
+// tmax не больше bound
+func cubeSineCase(n int, dt, tmax, bound float64) VerifyCase {
+	return VerifyCase{
+		Name:     fmt.Sprintf("cube sine n=%d dt=%g", n, dt),
+		Quantity: "L2 error",
+		Compare:  VerifyAtMost,
+		Expected: bound,
+		Measure: func(ctx context.Context, m Method) (float64, string, error) {
+			return cubeSineError(n, dt, tmax), "", nil
+		},
+	}
+}
+
+// Наблюдаемый порядок за два измельчения, в которых шаги сетки и dt
+// уменьшаются вдвое вместе
+func cubeOrderCase(n int, dt, tmax, order, tol float64) VerifyCase {
+	return VerifyCase{
+		Name:      fmt.Sprintf("cube order from n=%d dt=%g", n, dt),
+		Quantity:  "observed order",
+		Compare:   VerifyNear,
+		Expected:  order,
+		Tolerance: tol,
+		Measure: func(ctx context.Context, m Method) (float64, string, error) {
+			var errs [3]float64
+			for k := range errs {
+				errs[k] = cubeSineError(n<<k, dt/math.Exp2(float64(k)), tmax)
+			}
+			first, second := math.Log2(errs[0]/errs[1]), math.Log2(errs[1]/errs[2])
+			return second, fmt.Sprintf("errors %.3g, %.3g, %.3g; orders %.3f, %.3f", errs[0], errs[1], errs[2], first, second), nil
+		},
+	}
+}
+
+func cubeSineError(n int, dt, tmax float64) float64 {
+	h := 1 / float64(n)
+	nt := int(math.Round(tmax / dt))
+	last := Solve3DDouglasGunn(n, n, n, nt, h, h, h, dt, 1)
+	l2, _ := mathutils.LevelErrors3D(last, n, n, n, h, h, h, float64(nt)*dt, mathutils.Analytical3D{Alpha: 1})
+	return l2
+}
@@ -0,0 +1,106 @@
+package solver
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"testing"
+)
+
+// sin(πx)·sin(πy)·sin(πz) — собственный вектор всех трёх операторов
+// A_x, A_y, A_z с собственными числами −2a, −2b, −2c, a = 2r_x·sin²(π·dx/2):
+// этапы схемы дают множитель шага
+//
+//	G = (((1 − a − 2b − 2c)/(1 + a) + b)/(1 + b) + c)/(1 + c)
+func TestDouglasGunnSineMode(t *testing.T) {
+	tests := []struct {
+		nx, ny, nz int
+		dt         float64
+	}{
+		{8, 8, 8, 0.005},
+		{10, 14, 18, 0.01},
+		{6, 12, 24, 0.2},
+	}
+	for _, tc := range tests {
+		t.Run(fmt.Sprintf("%dx%dx%d/dt=%g", tc.nx, tc.ny, tc.nz, tc.dt), func(t *testing.T) {
+			const nt = 10
+			dx, dy, dz := 1/float64(tc.nx), 1/float64(tc.ny), 1/float64(tc.nz)
+			weight := func(h float64) float64 {
+				s := math.Sin(math.Pi * h / 2)
+				return 2 * tc.dt / (h * h) * s * s
+			}
+			a, b, c := weight(dx), weight(dy), weight(dz)
+			g := (((1-a-2*b-2*c)/(1+a)+b)/(1+b) + c) / (1 + c)
+			u0 := SineProfile3D(tc.nx, tc.ny, tc.nz, dx, dy, dz)
+			levels := 0
+			last, err := Stream3DDouglasGunnFrom(u0, tc.nx, tc.ny, tc.nz, nt, dx, dy, dz, tc.dt, 1, func(n int, row []float64) error {
+				levels++
+				scale := math.Pow(g, float64(n))
+				for p, v := range row {
+					if math.Abs(v-scale*u0[p]) > 1e-13 {
+						return fmt.Errorf("level %d, node %d: %v, want G^n·u0 = %v", n, p, v, scale*u0[p])
+					}
+				}
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if levels != nt+1 {
+				t.Fatalf("%d levels emitted, want %d", levels, nt+1)
+			}
+			if solved := Solve3DDouglasGunn(tc.nx, tc.ny, tc.nz, nt, dx, dy, dz, tc.dt, 1); !slices.Equal(solved, last) {
+				t.Error("Solve3DDouglasGunn differs from the streamed run")
+			}
+		})
+	}
+}
+
+// Против exp(−3π²t)·sin(πx)·sin(πy)·sin(πz): ошибка при r от 1.28 и второй
+// порядок при измельчении сетки и dt вдвое вместе
+func TestDouglasGunnAnalytic(t *testing.T) {
+	if l2 := cubeSineError(16, 0.005, 0.1); l2 > 2e-4 {
+		t.Errorf("L2 error %.3g on 16³ at dt=0.005", l2)
+	}
+	var prev float64
+	for k, n := range []int{8, 16, 32} {
+		l2 := cubeSineError(n, 0.08/float64(n), 0.1)
+		if k > 0 {
+			if order := math.Log2(prev / l2); math.Abs(order-2) > 0.15 {
+				t.Errorf("n=%d: error %.3g → %.3g, observed order %.2f", n, prev, l2, order)
+			}
+		}
+		prev = l2
+	}
+}
+
+// Разбиение плоскостей по потокам не меняет ни одного бита
+func TestDouglasGunnThreads(t *testing.T) {
+	const n, nt = 40, 5
+	h := 1.0 / n
+	withThreads(t, 1)
+	serial := Solve3DDouglasGunn(n, n+2, n+4, nt, h, h, h, 0.01, 1)
+	for _, threads := range []int{2, 3} {
+		withThreads(t, threads)
+		if got := Solve3DDouglasGunn(n, n+2, n+4, nt, h, h, h, 0.01, 1); !slices.Equal(got, serial) {
+			t.Errorf("%d threads differ from the serial run", threads)
+		}
+	}
+}
+
+// Время на узел и шаг почти не зависит от размера сетки: расчёт линеен
+// по числу узлов (прогонки), от 16³ до 64³
+func BenchmarkDouglasGunn(b *testing.B) {
+	const nt = 4
+	for _, n := range []int{16, 32, 64} {
+		b.Run(fmt.Sprintf("n=%d", n), func(b *testing.B) {
+			h := 1 / float64(n)
+			u0 := SineProfile3D(n, n, n, h, h, h)
+			for b.Loop() {
+				Stream3DDouglasGunnFrom(u0, n, n, n, nt, h, h, h, 0.01, 1, nil)
+			}
+			nodes := float64((n + 1) * (n + 1) * (n + 1))
+			b.ReportMetric(float64(b.Elapsed().Nanoseconds())/float64(b.N)/(nodes*nt), "ns/node-step")
+		})
+	}
+}
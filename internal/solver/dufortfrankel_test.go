@@ -0,0 +1,102 @@
+package solver
+
+import (
+	"bytes"
+	"log/slog"
+	"math"
+	"strings"
+	"testing"
+
+	"heat-solver/internal/mathutils"
+)
+
+// Предупреждения схем на время теста: журнал с уровня Warn в буфер
+func captureWarnings(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	prev := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelWarn})))
+	t.Cleanup(func() { slog.SetDefault(prev) })
+	return &buf
+}
+
+// При r, где FTCS расходится, DuFort–Frankel остаётся ограниченным
+// (|G| ≤ 1 у обоих корней, но при большом r у высоких мод корни почти
+// кратные, и до затухания амплитуда растёт как n·|G|ⁿ: при r = 50 — до
+// 2.2·max|u0|); при r ≤ 1 он близок к точному решению. Предупреждение о
+// несогласованности — только при r > 1
+func TestDuFortFrankel(t *testing.T) {
+	const nx = 50
+	dx := 1.0 / nx
+	exact := mathutils.Analytical{Alpha: 1}
+	tests := []struct {
+		r      float64
+		maxErr float64 // 0 — точность не проверяется, только ограниченность
+		warns  bool
+	}{
+		{0.4, 1e-3, false},
+		{1, 5e-3, false},
+		{5, 0, true},
+		{50, 0, true},
+	}
+	for _, tc := range tests {
+		warnings := captureWarnings(t)
+		dt := tc.r * dx * dx
+		nt := int(math.Ceil(0.5 / dt))
+		u := SolveDuFortFrankel(nx, nt, dx, dt)
+		for n, row := range u {
+			for i, v := range row {
+				if !(math.Abs(v) <= 3) {
+					t.Fatalf("r=%g: u[%d][%d] = %v, want bounded by 3·max|u0|", tc.r, n, i, v)
+				}
+			}
+		}
+		if tc.maxErr > 0 {
+			if l2, _ := mathutils.LevelErrors(u[nt], dx, float64(nt)*dt, exact); l2 > tc.maxErr {
+				t.Errorf("r=%g: L2 error %.3g, want below %g", tc.r, l2, tc.maxErr)
+			}
+		}
+		if got := strings.Contains(warnings.String(), "DuFort–Frankel may be inconsistent"); got != tc.warns {
+			t.Errorf("r=%g: inconsistency warning %v, want %v", tc.r, got, tc.warns)
+		}
+		if tc.r > 0.5 {
+			var peak float64
+			for _, v := range SolveFTCS(nx, nt, dx, dt)[nt] {
+				peak = max(peak, math.Abs(v))
+			}
+			if peak <= 1 {
+				t.Errorf("r=%g: FTCS stayed bounded at %g, the comparison shows nothing", tc.r, peak)
+			}
+		}
+
+		last, err := StreamDuFortFrankelFrom(SineProfile(nx, dx), nt, dx, dt, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := range last {
+			if last[i] != u[nt][i] {
+				t.Fatalf("r=%g: streamed u[%d] = %v, full run %v", tc.r, i, last[i], u[nt][i])
+			}
+		}
+	}
+}
+
+// Стартовый шаг при большом r дробится на подшаги FTCS с r/k ≤ ½ и не
+// раскачивает первый слой
+func TestDuFortFrankelStart(t *testing.T) {
+	const nx = 20
+	dx := 1.0 / nx
+	for _, r := range []float64{0.25, 0.5, 3, 40} {
+		cur := SineProfile(nx, dx)
+		next := make([]float64, nx+1)
+		dfStart(cur, next, r)
+		k := max(1, int(math.Ceil(2*r)))
+		want := math.Pow(1-4*(r/float64(k))*math.Pow(math.Sin(math.Pi*dx/2), 2), float64(k))
+		for i := range next {
+			if math.Abs(next[i]-want*cur[i]) > 1e-14 {
+				t.Errorf("r=%g: first level u[%d] = %v, want %v", r, i, next[i], want*cur[i])
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package solver
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"heat-solver/internal/config"
+	"heat-solver/internal/mathutils"
+)
+
+// Разделение ошибки расчёта (dx, dt) на пространственную и временную
+// части по модели E ≈ Cx·dx^p + Ct·dt^q. Нормы — среднеквадратичные по
+// узлам сетки dx в момент tmax, как в mathutils.LevelErrors
+type ErrorSplit struct {
+	Method string
+	Dx, Dt float64
+	// Порядки p и q, по которым пересчитываются разности (OrderSpace и
+	// OrderTime метода: у CN q = 2, у BTCS и FTCS q = 1)
+	OrderSpace, OrderTime int
+	// Оценки вкладов Cx·dx^p и Ct·dt^q
+	Spatial, Temporal float64
+	// Доля смешанной разности u(dx,dt) − u(dx,dt/2) − u(dx/2,dt) +
+	// u(dx/2,dt/2) от большего из вкладов; малая — модель разделима и
+	// оценкам можно верить
+	Mixed float64
+	// Вклады разного знака: ошибка меньше их суммы (у CN для sin(πx)
+	// пространственная ошибка замедляет затухание, временная — ускоряет)
+	Opposite bool
+	// Ошибка расчёта (dx, dt) относительно точного решения для sin(πx);
+	// NaN для заданного выборкой начального профиля
+	Total float64
+	// Что ограничивает точность: "dx" или "dt"
+	Limiting string
+}
+
+// Разделение ошибки метода p.Method тремя дополнительными расчётами:
+// (dx, dt/2), (dx/2, dt) и (dx/2, dt/2). Разность по dt при фиксированном
+// dx равна Ct·dt^q·(1 − 2^−q), по dx — Cx·dx^p·(1 − 2^−p). Порядки не
+// оцениваются: из четырёх расчётов независимы только три разности.
+// Ошибка — если схема неустойчива на одной из сеток
+func SplitError(ctx context.Context, p config.Params) (ErrorSplit, error) {
+	p = p.Normalize()
+	m, ok := Lookup(p.Method)
+	if !ok {
+		return ErrorSplit{}, &config.ValidationError{Field: "method", Message: fmt.Sprintf("unknown method %q", p.Method)}
+	}
+	if m.OrderSpace < 1 || m.OrderTime < 1 {
+		return ErrorSplit{}, fmt.Errorf("method %s has no declared order of accuracy", m.Name)
+	}
+	if r := p.Dt / (p.Dx * p.Dx / 4); !m.StableAt(r) {
+		return ErrorSplit{}, fmt.Errorf("method %s is unstable at dx/2: r = %g exceeds %g", m.Name, r, m.MaxR)
+	}
+	p.Storage, p.Precision = config.StorageFinalOnly, config.PrecisionFloat64
+
+	// Последние слои в порядке (dx,dt), (dx,dt/2), (dx/2,dt), (dx/2,dt/2)
+	var final [4][]float64
+	for k := range final {
+		q := p
+		if k&1 == 1 {
+			q.Dt /= 2
+		}
+		if k&2 == 2 {
+			q.Dx /= 2
+		}
+		res, err := Run(ctx, q, Hooks{})
+		if err != nil {
+			return ErrorSplit{}, err
+		}
+		if res.Diverged {
+			res.Release()
+			return ErrorSplit{}, fmt.Errorf("solve with dx=%g dt=%g diverged", q.Dx, q.Dt)
+		}
+		final[k] = res.Final
+		if k&2 == 2 {
+			// Узлы сетки dx/2, совпадающие с узлами сетки dx
+			coarse := make([]float64, len(final[0]))
+			for i := range coarse {
+				coarse[i] = res.Final[2*i]
+			}
+			final[k] = coarse
+		}
+		res.Release()
+	}
+
+	var dtDiff, dxDiff, mixed, dot float64
+	for i, u := range final[0] {
+		dtDiff += sq(u - final[1][i])
+		dxDiff += sq(u - final[2][i])
+		dot += (u - final[1][i]) * (u - final[2][i])
+		mixed += sq(u - final[1][i] - final[2][i] + final[3][i])
+	}
+	n := float64(len(final[0]))
+	s := ErrorSplit{
+		Method:     m.Name,
+		Dx:         p.Dx,
+		Dt:         p.Dt,
+		OrderSpace: m.OrderSpace,
+		OrderTime:  m.OrderTime,
+		Spatial:    math.Sqrt(dxDiff/n) / (1 - math.Exp2(-float64(m.OrderSpace))),
+		Temporal:   math.Sqrt(dtDiff/n) / (1 - math.Exp2(-float64(m.OrderTime))),
+		Opposite:   dot < 0,
+		Total:      math.NaN(),
+		Limiting:   "dt",
+	}
+	if s.Spatial > s.Temporal {
+		s.Limiting = "dx"
+	}
+	if larger := max(s.Spatial, s.Temporal); larger > 0 {
+		s.Mixed = math.Sqrt(mixed/n) / larger
+	}
+	if exact := PresetReference(p); exact != nil {
+		_, nt := p.Grid()
+		s.Total, _ = mathutils.LevelErrors(final[0], p.Dx, float64(nt)*p.Dt, exact)
+	}
+	return s, nil
+}
+
+func sq(x float64) float64 { return x * x }
@@ -0,0 +1,88 @@
+package solver
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"heat-solver/internal/config"
+	"heat-solver/internal/mathutils"
+)
+
+// Ошибка расчёта относительно sin(πx)·exp(−π²t) в момент tmax
+func runError(t *testing.T, p config.Params) float64 {
+	t.Helper()
+	p.Storage = config.StorageFinalOnly
+	res, err := Run(context.Background(), p.Normalize(), Hooks{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Release()
+	l2, _ := mathutils.LevelErrors(res.Final, res.Dx, float64(res.Nt)*res.Dt, mathutils.Analytical{Alpha: 1})
+	return l2
+}
+
+// Грубый dt при мелком dx — ошибка от dt, и наоборот. Вклад, который
+// ограничивает точность, совпадает с ошибкой расчёта, где другой шаг
+// пренебрежимо мал
+func TestSplitError(t *testing.T) {
+	tests := []struct {
+		name      string
+		p         config.Params
+		limiting  string
+		orderTime int
+		// Тот же расчёт с измельчённым другим шагом: его ошибка — почти
+		// весь ограничивающий вклад
+		isolated config.Params
+	}{
+		{"CN coarse dt", config.Params{Method: "CN", Dx: 0.005, Dt: 0.02, Tmax: 0.2}, "dt", 2,
+			config.Params{Method: "CN", Dx: 0.0005, Dt: 0.02, Tmax: 0.2}},
+		{"CN coarse dx", config.Params{Method: "CN", Dx: 0.1, Dt: 0.001, Tmax: 0.2}, "dx", 2,
+			config.Params{Method: "CN", Dx: 0.1, Dt: 1e-5, Tmax: 0.2}},
+		{"BTCS coarse dt", config.Params{Method: "BTCS", Dx: 0.01, Dt: 0.005, Tmax: 0.2}, "dt", 1,
+			config.Params{Method: "BTCS", Dx: 0.001, Dt: 0.005, Tmax: 0.2}},
+		{"BTCS coarse dx", config.Params{Method: "BTCS", Dx: 0.1, Dt: 1e-5, Tmax: 0.2}, "dx", 1,
+			config.Params{Method: "BTCS", Dx: 0.1, Dt: 1e-6, Tmax: 0.2}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := SplitError(context.Background(), tc.p)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s.Limiting != tc.limiting || s.OrderTime != tc.orderTime || s.OrderSpace != 2 {
+				t.Fatalf("limiting %s with orders %d/%d, want %s with 2/%d", s.Limiting, s.OrderSpace, s.OrderTime, tc.limiting, tc.orderTime)
+			}
+			dominant, other := s.Temporal, s.Spatial
+			if tc.limiting == "dx" {
+				dominant, other = other, dominant
+			}
+			if dominant < 10*other || s.Mixed > 0.1 {
+				t.Errorf("spatial %.3g, temporal %.3g, mixed %.3g; want a clear split", s.Spatial, s.Temporal, s.Mixed)
+			}
+			if isolated := runError(t, tc.isolated); math.Abs(dominant-isolated) > 0.1*isolated {
+				t.Errorf("%s contribution %.4g, error with the other step refined %.4g", tc.limiting, dominant, isolated)
+			}
+			if math.Abs(s.Total-runError(t, tc.p)) > 1e-15 {
+				t.Errorf("total %.6g differs from the error of the run", s.Total)
+			}
+		})
+	}
+}
+
+func TestSplitErrorRejects(t *testing.T) {
+	tests := []struct {
+		name string
+		p    config.Params
+	}{
+		{"unknown method", config.Params{Method: "NOPE", Dx: 0.1, Dt: 0.001, Tmax: 0.1}},
+		{"unstable at dx/2", config.Params{Method: "FTCS", Dx: 0.1, Dt: 0.004, Tmax: 0.1}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := SplitError(context.Background(), tc.p); err == nil {
+				t.Error("accepted")
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package solver
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"testing"
+
+	"heat-solver/internal/config"
+	"heat-solver/internal/mathutils"
+)
+
+// Ошибка экспоненциального интегратора на sin(πx) — чистая ошибка
+// трёхточечного шаблона |exp(λ₁t) − exp(−π²t)|·‖sin πx‖ при любом dt
+// (среднеквадратичная по nx+1 узлам норма sin πx — √(nx/(2(nx+1)))) и
+// убывает как dx²
+func TestExponentialSpatialError(t *testing.T) {
+	const tmax = 0.5
+	exact := mathutils.Analytical{Alpha: 1}
+	var prev float64
+	for k, nx := range []int{10, 20, 40, 80} {
+		dx := 1.0 / float64(nx)
+		s := math.Sin(math.Pi * dx / 2)
+		lambda := -4 * s * s / (dx * dx)
+		want := math.Abs(math.Exp(lambda*tmax)-math.Exp(-math.Pi*math.Pi*tmax)) * math.Sqrt(float64(nx)/float64(2*(nx+1)))
+		for _, dt := range []float64{0.1, 0.001} {
+			t.Run(fmt.Sprintf("nx=%d/dt=%g", nx, dt), func(t *testing.T) {
+				nt := int(math.Round(tmax / dt))
+				u := SolveExponential(nx, nt, dx, dt)
+				l2, _ := mathutils.LevelErrors(u[nt], dx, tmax, exact)
+				if math.Abs(l2-want) > 1e-13 {
+					t.Errorf("L2 error %.15g, spatial truncation error %.15g", l2, want)
+				}
+			})
+		}
+		if k > 0 {
+			if ratio := prev / want; math.Abs(ratio-4) > 0.1 {
+				t.Errorf("nx=%d: error ratio %.3f, want 4", nx, ratio)
+			}
+		}
+		prev = want
+	}
+}
+
+// Каждая мода случайного профиля затухает как exp(λ_k·t); контрольные
+// слои пересчитываются побитно
+func TestExponentialModes(t *testing.T) {
+	const nx, nt, dt = 16, 12, 0.003
+	dx := 1.0 / nx
+	dst, err := mathutils.NewSineTransform(nx, nx-1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a0 := make([]float64, nx-1)
+	for k := range a0 {
+		a0[k] = 1 / float64(k+1)
+	}
+	u0 := make([]float64, nx+1)
+	dst.Inverse(u0, a0)
+	u := SolveExponentialFrom(u0, nt, dx, dt)
+	a := make([]float64, nx-1)
+	dst.Transform(a, u[nt])
+	for k := range a {
+		s := math.Sin(float64(k+1) * math.Pi / (2 * nx))
+		want := a0[k] * math.Exp(-4*s*s/(dx*dx)*nt*dt)
+		if math.Abs(a[k]-want) > 1e-14 {
+			t.Errorf("mode %d: a = %v, want %v", k+1, a[k], want)
+		}
+	}
+
+	p := config.Params{Method: "EXPONENTIAL", Dx: 0.05, Dt: 0.01, Tmax: 0.2}.Normalize()
+	full, err := Run(context.Background(), p, Hooks{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer full.Release()
+	cp, err := Checkpointed(context.Background(), p, 6, Hooks{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cp.Release()
+	for n := 0; n <= full.Nt; n++ {
+		want, got := full.Level(n), cp.Level(n)
+		for i := range want {
+			if got[i] != want[i] {
+				t.Fatalf("checkpointed u[%d][%d] = %v, full run %v", n, i, got[i], want[i])
+			}
+		}
+	}
+}
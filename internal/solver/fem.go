@@ -0,0 +1,166 @@
+package solver
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+)
+
+// Линейные конечные элементы (P1) на равномерной сетке с θ-схемой по
+// времени: (M + θ·dt·K)·u^{n+1} = (M − (1−θ)·dt·K)·u^n, K = (1/dx)·(−1, 2, −1).
+// Согласованная матрица масс M = (dx/6)·(1, 4, 1), сосредоточенная —
+// dx·I. После деления на dx обе системы трёхдиагональные; с
+// сосредоточенной массой схема совпадает с разностной θ-схемой (при θ = 0
+// — с FTCS). Начальный слой — значения u0 в узлах
+func SolveFEM(nx, nt int, dx, dt, theta float64, lumped bool) [][]float64 {
+	return SolveFEMFrom(SineProfile(nx, dx), nt, dx, dt, theta, lumped)
+}
+
+// МКЭ с заданным начальным профилем u0 (nx+1 значений)
+func SolveFEMFrom(u0 []float64, nt int, dx, dt, theta float64, lumped bool) [][]float64 {
+	u := newGrid(nt+1, len(u0))
+	femScheme(u0, nt, dx, dt, theta, lumped, u, nil)
+	return u
+}
+
+// МКЭ с хранением двух слоёв; каждый слой передаётся в emit
+func StreamFEMFrom(u0 []float64, nt int, dx, dt, theta float64, lumped bool, emit EmitFunc) ([]float64, error) {
+	u := newGrid(2, len(u0))
+	if err := femScheme(u0, nt, dx, dt, theta, lumped, u, emit); err != nil {
+		return nil, err
+	}
+	return level(u, nt), nil
+}
+
+// С согласованной массой на шаге решается система
+// (1/6 − θr, 2/3 + 2θr, 1/6 − θr) с правой частью
+// (1/6 + (1−θ)r)·u_{i−1} + (2/3 − 2(1−θ)r)·u_i + (1/6 + (1−θ)r)·u_{i+1};
+// система есть и при θ = 0 — матрица масс не диагональна
+func femScheme(u0 []float64, nt int, dx, dt, theta float64, lumped bool, u [][]float64, emit EmitFunc) error {
+	nx := len(u0) - 1
+	r := dt / (dx * dx)
+	if lumped {
+		slog.Info("FEM with lumped mass is the finite-difference θ-scheme", "theta", theta)
+		return thetaScheme(u0, nt, dx, dt, 1, theta, u, emit)
+	}
+	warnUnstable("FEM", r, femMaxR(theta))
+	slog.Info("Starting FEM solver", "nx", nx, "nt", nt, "dx", dx, "dt", dt, "r", r, "theta", theta, "mass", "consistent")
+
+	if err := start(u, u0, emit); err != nil {
+		return err
+	}
+
+	lower := 1.0/6 - theta*r
+	side, diag := 1.0/6+(1-theta)*r, 2.0/3-2*(1-theta)*r
+	a, b, c, d, ws := workspace(nx - 1)
+	defer pool64.put(ws)
+	for i := 0; i < nx-1; i++ {
+		a[i] = lower
+		b[i] = 2.0/3 + 2*theta*r
+		c[i] = lower
+	}
+	tri := newTridiag(a, b, c)
+	defer tri.close()
+
+	for n := 0; n < nt; n++ {
+		cur, next := level(u, n), level(u, n+1)
+		next[0], next[nx] = 0, 0
+		femRHS(cur, d, side, diag)
+		d[0] -= lower * next[0]
+		d[nx-2] -= lower * next[nx]
+
+		tri.solve(d, next[1:nx])
+		if err := tridiagErr(tri); err != nil {
+			return err
+		}
+		if err := emitLevel(emit, n+1, next); err != nil {
+			return err
+		}
+	}
+
+	slog.Info("FEM solver finished successfully")
+	return nil
+}
+
+// d[i] = side·cur[i] + diag·cur[i+1] + side·cur[i+2], как в thetaRHS
+func femRHS(cur, d []float64, side, diag float64) {
+	left, mid, right := cur[:len(d)], cur[1:len(d)+1], cur[2:len(d)+2]
+	for i := range d {
+		d[i] = side*left[i] + diag*mid[i] + side*right[i]
+	}
+}
+
+// Предел r для согласованной массы при θ < ½: 1/(6(1−2θ)), втрое строже
+// разностного; 0 — безусловная устойчивость
+func femMaxR(theta float64) float64 {
+	if theta >= 0.5 {
+		return 0
+	}
+	return 1 / (6 * (1 - 2*theta))
+}
+
+// G(θ) P1 с согласованной массой: символ массы 1 − (2/3)·sin²(φ/2),
+// жёсткости 4r·sin²(φ/2)
+func femAmplification(theta float64) AmplificationFunc {
+	return func(r, phi float64) float64 {
+		s := math.Sin(phi / 2)
+		m, q := 1-2*s*s/3, 4*r*s*s
+		return (m - (1-theta)*q) / (m + theta*q)
+	}
+}
+
+// Метод МКЭ для реестра под именем FEM, как ThetaMethod: θ от 0 до 1,
+// lumped — сосредоточенная масса. Регистрирует тот, кому он нужен
+// (CLI с -method FEM)
+func FEMMethod(theta float64, lumped bool) (Method, error) {
+	if !(theta >= 0 && theta <= 1) {
+		return Method{}, fmt.Errorf("theta must be between 0 and 1, got %g", theta)
+	}
+	mass := "consistent"
+	if lumped {
+		mass = "lumped"
+	}
+	m := Method{
+		Name:        "FEM",
+		Description: fmt.Sprintf("Linear finite elements with %s mass, θ-scheme in time with θ = %g", mass, theta),
+		Explicit:    lumped && theta == 0,
+		MaxR:        femMaxR(theta),
+		OrderTime:   1,
+		OrderSpace:  2,
+		Solve: func(u0 []float64, nt int, dx, dt float64) [][]float64 {
+			return SolveFEMFrom(u0, nt, dx, dt, theta, lumped)
+		},
+		Stream: func(u0 []float64, nt int, dx, dt float64, emit EmitFunc) ([]float64, error) {
+			return StreamFEMFrom(u0, nt, dx, dt, theta, lumped, emit)
+		},
+		Amplification: femAmplification(theta),
+		System: func(n int, r float64) (a, b, c []float64) {
+			return constantSystem(n, 1.0/6-theta*r, 2.0/3+2*theta*r, 1.0/6-theta*r)
+		},
+		// r = 0.1 в пределах устойчивости при любых θ и массе
+		Verification: []VerifyCase{
+			SineOrderCase(0.05, 0.00025, 0.1, 2, 0.1),
+			SteadyStateCase(0.05, 0.00025, 2, 1e-8),
+		},
+		solveInto: func(u0 []float64, nt int, dx, dt float64, u [][]float64) error {
+			return femScheme(u0, nt, dx, dt, theta, lumped, u, nil)
+		},
+	}
+	if lumped {
+		m.MaxR = thetaMaxR(theta)
+		m.Amplification = WeightedAmplification(theta)
+		m.System = nil
+		if theta > 0 {
+			m.System = func(n int, r float64) (a, b, c []float64) {
+				return constantSystem(n, -theta*r, 1+2*theta*r, -theta*r)
+			}
+		}
+	}
+	if theta == 0.5 {
+		m.OrderTime = 2
+	}
+	if m.MaxR > 0 {
+		m.StabilityBound = fmt.Sprintf("r = dt/dx² ≤ %g", m.MaxR)
+	}
+	return m, nil
+}
@@ -0,0 +1,102 @@
+package solver
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"heat-solver/internal/mathutils"
+)
+
+// Сосредоточенная масса сводит МКЭ к разностной θ-схеме: при θ = 0 —
+// побитно FTCS, при θ = 1 — BTCS
+func TestFEMLumped(t *testing.T) {
+	tests := []struct {
+		theta float64
+		fd    SolveFunc
+	}{
+		{0, SolveFTCSFrom},
+		{1, SolveBTCSFrom},
+	}
+	for _, tc := range tests {
+		for _, nx := range []int{2, 17, 100} {
+			dx := 1.0 / float64(nx)
+			dt := 0.4 * dx * dx
+			u0 := SineProfile(nx, dx)
+			got, want := SolveFEMFrom(u0, 50, dx, dt, tc.theta, true), tc.fd(u0, 50, dx, dt)
+			for n := range want {
+				for i := range want[n] {
+					if got[n][i] != want[n][i] {
+						t.Fatalf("theta=%g, nx=%d: u[%d][%d] = %v, want %v", tc.theta, nx, n, i, got[n][i], want[n][i])
+					}
+				}
+			}
+		}
+	}
+}
+
+// Ошибка в момент 0.1 при CN по времени убывает как dx² для обеих масс;
+// константы ошибки у них одного порядка и разных знаков
+func TestFEMSpatialOrder(t *testing.T) {
+	const tmax, dt = 0.1, 1e-4
+	nt := int(math.Round(tmax / dt))
+	exact := mathutils.Analytical{Alpha: 1}
+	for _, lumped := range []bool{false, true} {
+		t.Run(fmt.Sprintf("lumped=%v", lumped), func(t *testing.T) {
+			var prev float64
+			for k, nx := range []int{10, 20, 40, 80} {
+				dx := 1.0 / float64(nx)
+				u := SolveFEM(nx, nt, dx, dt, 0.5, lumped)
+				l2, _ := mathutils.LevelErrors(u[nt], dx, tmax, exact)
+				if k > 0 {
+					if order := math.Log2(prev / l2); math.Abs(order-2) > 0.1 {
+						t.Errorf("nx=%d: error %.3g → %.3g, observed order %.2f", nx, prev, l2, order)
+					}
+				}
+				prev = l2
+			}
+		})
+	}
+	// Ошибки в центре у двух масс сравнимы по модулю и противоположны по
+	// знаку
+	const nx = 20
+	dx := 1.0 / nx
+	want := math.Exp(-math.Pi * math.Pi * tmax)
+	consistent := SolveFEM(nx, nt, dx, dt, 0.5, false)[nt][nx/2] - want
+	lumped := SolveFEM(nx, nt, dx, dt, 0.5, true)[nt][nx/2] - want
+	if consistent*lumped >= 0 || math.Abs(consistent/lumped) < 0.5 || math.Abs(consistent/lumped) > 2 {
+		t.Errorf("errors at x = ½: consistent %.3g, lumped %.3g; want comparable and of opposite sign", consistent, lumped)
+	}
+}
+
+// Явная схема с согласованной массой устойчива при r ≤ 1/6; мода sin(πx)
+// убывает по femAmplification
+func TestFEMConsistentExplicit(t *testing.T) {
+	m, err := FEMMethod(0, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(m.MaxR-1.0/6) > 1e-15 || m.Explicit || m.System == nil {
+		t.Fatalf("max r %v, explicit %v; want 1/6 with a mass system", m.MaxR, m.Explicit)
+	}
+	const nx, nt = 40, 3000
+	dx := 1.0 / nx
+	for _, r := range []float64{0.16, 0.17} {
+		u := m.Solve(SineProfile(nx, dx), nt, dx, r*dx*dx)
+		var peak float64
+		for _, v := range u[nt] {
+			peak = max(peak, math.Abs(v))
+		}
+		if stable := m.StableAt(r); stable != (peak < 1) {
+			t.Errorf("r=%g: peak %g after %d steps, stable by the bound: %v", r, peak, nt, stable)
+		}
+		if m.StableAt(r) {
+			if want := math.Pow(m.Amplification(r, math.Pi*dx), nt); math.Abs(u[nt][nx/2]-want) > 1e-12 {
+				t.Errorf("r=%g: u(½) = %v, want G^n = %v", r, u[nt][nx/2], want)
+			}
+		}
+	}
+	if _, err := FEMMethod(1.2, true); err == nil {
+		t.Error("theta 1.2 accepted")
+	}
+}
@@ -0,0 +1,204 @@
+package solver
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+)
+
+// Уравнение Фишера–КПП u_t = α·u_xx + ρ·u(1 − u) на [0, L] с постоянными
+// значениями Дирихле u0[0] и u0[nx]. Шаг — расщепление Стрэнга: полшага
+// логистического уравнения u' = ρu(1 − u) по его точному решению, полный
+// шаг CN по диффузии и ещё полшага реакции. Обе части второго порядка,
+// и симметричная композиция тоже второго порядка по dt
+
+// Ступенька фронта: 1 в узлах x_i = i·dx ≤ x0, 0 правее
+func FisherStep(nx int, dx, x0 float64) []float64 {
+	u0 := make([]float64, nx+1)
+	for i := range u0 {
+		if float64(i)*dx <= x0 {
+			u0[i] = 1
+		}
+	}
+	return u0
+}
+
+// Фишер–КПП с заданным профилем u0; хранятся два слоя, каждый слой
+// передаётся в emit. Перед шагом реакции значения прижимаются к [0, 1] —
+// области, которую логистическое уравнение сохраняет (CN на ступеньке при
+// большом r может выйти за неё на округление или на осцилляцию). NaN и
+// бесконечность в слое останавливают расчёт с ErrDiverged
+func StreamFisherFrom(u0 []float64, nt int, dx, dt, alpha, rho float64, emit EmitFunc) ([]float64, error) {
+	u := newGrid(2, len(u0))
+	if err := fisher(u0, nt, dx, dt, alpha, rho, u, emit); err != nil {
+		return nil, err
+	}
+	return level(u, nt), nil
+}
+
+// Минимальная скорость бегущей волны Фишера–КПП 2√(ρα): с неё движется
+// фронт из финитных начальных данных
+func FisherSpeed(alpha, rho float64) float64 {
+	return 2 * math.Sqrt(rho*alpha)
+}
+
+// Положение фронта: самая правая точка, где u пересекает ½ сверху вниз
+// (линейная интерполяция между узлами). NaN, если пересечения нет
+func FisherFront(u []float64, dx float64) float64 {
+	for i := len(u) - 2; i >= 0; i-- {
+		if u[i] >= 0.5 && u[i+1] < 0.5 {
+			return (float64(i) + (u[i]-0.5)/(u[i]-u[i+1])) * dx
+		}
+	}
+	return math.NaN()
+}
+
+func fisher(u0 []float64, nt int, dx, dt, alpha, rho float64, u [][]float64, emit EmitFunc) error {
+	nx := len(u0) - 1
+	r := alpha * dt / (dx * dx)
+	slog.Info("Starting Fisher–KPP Strang solver", "nx", nx, "nt", nt, "dx", dx, "dt", dt, "alpha", alpha, "rho", rho, "r", r)
+
+	row := level(u, 0)
+	copy(row, u0)
+	if err := emitLevel(emit, 0, row); err != nil {
+		return err
+	}
+
+	a, b, c, d, ws := workspace(nx - 1)
+	defer pool64.put(ws)
+	for i := range b {
+		a[i], b[i], c[i] = -r/2, 1+r, -r/2
+	}
+	tri := newTridiag(a, b, c)
+	defer tri.close()
+
+	// Точное решение логистического уравнения за полшага:
+	// u ↦ u·g/(1 + u(g − 1)), g = exp(ρ·dt/2)
+	g := math.Exp(rho * dt / 2)
+	var clamped int
+	var excess float64
+	react := func(row []float64) {
+		for i := 1; i < nx; i++ {
+			v := row[i]
+			if v < 0 || v > 1 {
+				clamped++
+				excess = math.Max(excess, math.Max(-v, v-1))
+				v = math.Min(math.Max(v, 0), 1)
+			}
+			row[i] = v * g / (1 + v*(g-1))
+		}
+	}
+
+	for n := 0; n < nt; n++ {
+		cur, next := level(u, n), level(u, n+1)
+		next[0], next[nx] = u0[0], u0[nx]
+		react(cur)
+		thetaRHS(cur, d, r/2)
+		d[0] += r / 2 * next[0]
+		d[nx-2] += r / 2 * next[nx]
+		tri.solve(d, next[1:nx])
+		if err := tridiagErr(tri); err != nil {
+			return err
+		}
+		react(next)
+		for _, v := range next {
+			if math.IsNaN(v) || math.IsInf(v, 0) {
+				return fmt.Errorf("Fisher–KPP step %d: %w", n+1, ErrDiverged)
+			}
+		}
+		if err := emitLevel(emit, n+1, next); err != nil {
+			return err
+		}
+	}
+
+	if excess > 1e-12 {
+		slog.Warn("Fisher–KPP values left [0, 1] and were clamped", "nodes", clamped, "max_excess", excess, "r", r)
+	}
+	slog.Info("Fisher–KPP Strang solver finished successfully")
+	return nil
+}
+
+// Проверочные случаи Фишера–КПП; в Verify — под именем FISHER
+var verificationFisher = []VerifyCase{
+	fisherSpeedCase(1, 1, 100, 0.2, 0.05, 20, 40, 0.05),
+	fisherSpeedCase(1, 4, 100, 0.1, 0.01, 10, 20, 0.05),
+	fisherOrderCase(1, 2, 20, 0.1, 0.1, 4, 0.1),
+}
+
+// Скорость фронта из ступеньки на [0, length]: смещение точки u = ½ между
+// t1 и t2, делённое на t2 − t1, против 2√(ρα). Фронт подходит к этой
+// скорости снизу, с поправкой Брамсона −3/(2λ)·ln(t2/t1)/(t2 − t1), где
+// λ = √(ρ/α) — наклон хвоста; она приводится в описании
+func fisherSpeedCase(alpha, rho, length, dx, dt, t1, t2, tol float64) VerifyCase {
+	speed := FisherSpeed(alpha, rho)
+	return VerifyCase{
+		Name:      fmt.Sprintf("CN front speed α=%g ρ=%g dx=%g dt=%g", alpha, rho, dx, dt),
+		Quantity:  "front speed",
+		Compare:   VerifyNear,
+		Expected:  speed,
+		Tolerance: tol * speed,
+		Measure: func(ctx context.Context, _ Method) (float64, string, error) {
+			nx := int(math.Round(length / dx))
+			n1, n2 := int(math.Round(t1/dt)), int(math.Round(t2/dt))
+			var x1, x2 float64
+			emit := func(n int, row []float64) error {
+				switch n {
+				case n1:
+					x1 = FisherFront(row, dx)
+				case n2:
+					x2 = FisherFront(row, dx)
+				}
+				return ctx.Err()
+			}
+			if _, err := StreamFisherFrom(FisherStep(nx, dx, length/10), n2, dx, dt, alpha, rho, emit); err != nil {
+				return math.NaN(), "", err
+			}
+			observed := (x2 - x1) / (float64(n2-n1) * dt)
+			bramson := speed - 1.5*math.Sqrt(alpha/rho)*math.Log(t2/t1)/(t2-t1)
+			return observed, fmt.Sprintf("front at %.4g and %.4g; with the Bramson correction %.4g", x1, x2, bramson), nil
+		},
+	}
+}
+
+// Порядок расщепления по dt без точного решения: гладкий фронт
+// ½(1 − tanh(x − length/4)) на [0, length] считается с dt, dt/2, dt/4 и
+// dt/8 на одной сетке, и порядок — log₂ отношения соседних разностей
+// ‖u_dt − u_dt/2‖ (пространственная ошибка у всех расчётов одна и та же)
+func fisherOrderCase(alpha, rho, length, dx, dt, tmax, tol float64) VerifyCase {
+	return VerifyCase{
+		Name:      fmt.Sprintf("Strang self-convergence α=%g ρ=%g dx=%g dt=%g", alpha, rho, dx, dt),
+		Quantity:  "observed order",
+		Compare:   VerifyNear,
+		Expected:  2,
+		Tolerance: tol,
+		Measure: func(ctx context.Context, _ Method) (float64, string, error) {
+			nx := int(math.Round(length / dx))
+			u0 := make([]float64, nx+1)
+			for i := range u0 {
+				u0[i] = 0.5 * (1 - math.Tanh(float64(i)*dx-length/4))
+			}
+			u0[0], u0[nx] = 1, 0
+			var runs [4][]float64
+			for k := range runs {
+				if err := ctx.Err(); err != nil {
+					return math.NaN(), "", err
+				}
+				tau := dt / math.Exp2(float64(k))
+				last, err := StreamFisherFrom(u0, int(math.Round(tmax/tau)), dx, tau, alpha, rho, nil)
+				if err != nil {
+					return math.NaN(), "", err
+				}
+				runs[k] = last
+			}
+			var diffs [3]float64
+			for k := range diffs {
+				for i, v := range runs[k] {
+					diffs[k] = math.Max(diffs[k], math.Abs(v-runs[k+1][i]))
+				}
+			}
+			first, second := math.Log2(diffs[0]/diffs[1]), math.Log2(diffs[1]/diffs[2])
+			return second, fmt.Sprintf("differences %.3g, %.3g, %.3g; orders %.3f, %.3f", diffs[0], diffs[1], diffs[2], first, second), nil
+		},
+	}
+}
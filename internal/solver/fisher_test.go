@@ -0,0 +1,146 @@
+package solver
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestFisherFront(t *testing.T) {
+	tests := []struct {
+		name string
+		u    []float64
+		want float64
+	}{
+		{"step", []float64{1, 1, 0, 0}, 1.5},
+		{"ramp", []float64{1, 0.8, 0.2, 0}, 1.5},
+		{"half at a node", []float64{1, 0.5, 0.25, 0}, 1},
+		{"rightmost crossing", []float64{1, 0, 1, 0.6, 0.4}, 3.5},
+		{"no crossing", []float64{0.4, 0.3, 0.2}, math.NaN()},
+		{"rising", []float64{0, 0.5, 1}, math.NaN()},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := FisherFront(tc.u, 1)
+			if !(math.Abs(got-tc.want) < 1e-15 || math.IsNaN(got) && math.IsNaN(tc.want)) {
+				t.Errorf("front %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+// Фронт из ступеньки идёт со скоростью, близкой к 2√(ρα)
+func TestFisherSpeed(t *testing.T) {
+	tests := []struct {
+		alpha, rho float64
+		dx, dt     float64
+		t1, t2     float64
+	}{
+		{1, 1, 0.2, 0.05, 20, 40},
+		{1, 4, 0.1, 0.01, 10, 20},
+		{0.25, 1, 0.1, 0.05, 20, 40},
+	}
+	const length = 100.0
+	for _, tc := range tests {
+		t.Run(fmt.Sprintf("α=%g/ρ=%g", tc.alpha, tc.rho), func(t *testing.T) {
+			nx := int(math.Round(length / tc.dx))
+			n1, n2 := int(math.Round(tc.t1/tc.dt)), int(math.Round(tc.t2/tc.dt))
+			var x1 float64
+			last, err := StreamFisherFrom(FisherStep(nx, tc.dx, length/10), n2, tc.dx, tc.dt, tc.alpha, tc.rho, func(n int, row []float64) error {
+				if n == n1 {
+					x1 = FisherFront(row, tc.dx)
+				}
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			speed, want := (FisherFront(last, tc.dx)-x1)/(tc.t2-tc.t1), FisherSpeed(tc.alpha, tc.rho)
+			if math.Abs(speed-want) > 0.05*want {
+				t.Errorf("front speed %.4f, want 2√(ρα) = %.4f", speed, want)
+			}
+		})
+	}
+}
+
+// Вдали от краёв однородный профиль не диффундирует и растёт по точному
+// решению логистического уравнения c·e^{ρt}/(1 + c(e^{ρt} − 1))
+func TestFisherLogistic(t *testing.T) {
+	const nx, rho, c = 1000, 2.0, 0.1
+	dx, dt := 0.1, 0.01
+	u0 := make([]float64, nx+1)
+	for i := range u0 {
+		u0[i] = c
+	}
+	const nt = 100
+	last, err := StreamFisherFrom(u0, nt, dx, dt, 1, rho, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := math.Exp(rho * nt * dt)
+	if want := c * e / (1 + c*(e-1)); math.Abs(last[nx/2]-want) > 1e-12 {
+		t.Errorf("middle node %v, logistic solution %v", last[nx/2], want)
+	}
+}
+
+// Самосходимость по dt на гладком фронте: разности соседних расчётов
+// падают вчетверо
+func TestFisherSplittingOrder(t *testing.T) {
+	const length, dx, tmax = 20.0, 0.1, 4.0
+	nx := int(math.Round(length / dx))
+	u0 := make([]float64, nx+1)
+	for i := range u0 {
+		u0[i] = 0.5 * (1 - math.Tanh(float64(i)*dx-length/4))
+	}
+	u0[0], u0[nx] = 1, 0
+	var runs [4][]float64
+	for k := range runs {
+		dt := 0.1 / math.Exp2(float64(k))
+		last, err := StreamFisherFrom(u0, int(math.Round(tmax/dt)), dx, dt, 1, 2, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		runs[k] = last
+	}
+	var diffs [3]float64
+	for k := range diffs {
+		for i, v := range runs[k] {
+			diffs[k] = max(diffs[k], math.Abs(v-runs[k+1][i]))
+		}
+	}
+	for k := 1; k < len(diffs); k++ {
+		if order := math.Log2(diffs[k-1] / diffs[k]); math.Abs(order-2) > 0.1 {
+			t.Errorf("differences %.3g → %.3g, observed order %.2f", diffs[k-1], diffs[k], order)
+		}
+	}
+}
+
+// Защита от разлёта: при r = 50 значения CN за пределами [0, 1]
+// прижимаются с предупреждением, NaN останавливает расчёт с ErrDiverged
+func TestFisherBlowUp(t *testing.T) {
+	const nx = 200
+	dx := 0.1
+	warnings := captureWarnings(t)
+	last, err := StreamFisherFrom(FisherStep(nx, dx, 5), 40, dx, 0.5, 1, 1, func(n int, row []float64) error {
+		for i, v := range row[1:nx] {
+			if v < 0 || v > 1 {
+				return fmt.Errorf("level %d, node %d: %v outside [0, 1]", n, i+1, v)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if last == nil || !strings.Contains(warnings.String(), "clamped") {
+		t.Errorf("no clamp warning at r = 50 on a step: %q", warnings.String())
+	}
+
+	u0 := FisherStep(nx, dx, 5)
+	u0[nx/2] = math.NaN()
+	if _, err := StreamFisherFrom(u0, 5, dx, 0.1, 1, 1, nil); !errors.Is(err, ErrDiverged) {
+		t.Errorf("NaN in the profile: error %v, want ErrDiverged", err)
+	}
+}
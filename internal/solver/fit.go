@@ -0,0 +1,147 @@
+package solver
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand/v2"
+
+	"heat-solver/internal/config"
+	"heat-solver/internal/mathutils"
+)
+
+// Измерение датчика: температура U в точке X в момент T
+type ProbeSample struct {
+	T, X, U float64
+}
+
+// Итог подбора α: оценка, грубый 95-процентный доверительный интервал
+// [Low, High], среднеквадратичная невязка и расчёт в точках измерений
+type AlphaFit struct {
+	Alpha     float64
+	Low, High float64
+	RMS       float64
+	Model     []float64
+}
+
+// Относительная точность подбора α; её же берёт /evaluate сервера
+const fitTol = 1e-6
+
+// Прямой расчёт подбора α; nil — Run без хуков. Сервер подставляет свой
+// расчёт с кэшем и ограничениями сетки
+type FitRun func(ctx context.Context, p config.Params) (*Result, error)
+
+// Подбор коэффициента температуропроводности α в u_t = α·u_xx по
+// измерениям samples методом наименьших квадратов. Расчёт идёт во времени
+// τ = αt (p.Method, p.Dx, p.Dt — шаг по τ, p.IC), так что одно решение до
+// hi·max t годится для любого α из [lo, hi]: значение в точке измерения —
+// билинейная интерполяция решения в (x, αt). Минимум суммы квадратов
+// невязок ищется mathutils.MinimizeLog. Интервал — по линеаризации
+// Гаусса–Ньютона: σ² = S/(N − 1), дисперсия оценки σ²/Σ(∂u/∂α)²,
+// производная — центральной разностью. Замена времени верна только для
+// чистой теплопроводности, поэтому p без реакции и источника. Прямой
+// расчёт — run (nil — Run)
+func FitAlpha(ctx context.Context, p config.Params, samples []ProbeSample, lo, hi float64, run FitRun) (AlphaFit, error) {
+	if len(samples) < 2 {
+		return AlphaFit{}, fmt.Errorf("need at least 2 samples, got %d", len(samples))
+	}
+	if !(lo > 0 && lo < hi) || math.IsInf(hi, 0) {
+		return AlphaFit{}, fmt.Errorf("alpha range [%g, %g]: want 0 < min < max", lo, hi)
+	}
+	if p.Lambda != 0 || p.Source != "" {
+		return AlphaFit{}, fmt.Errorf("fitting alpha rescales time, which needs the pure heat equation, got lambda = %g, source %q", p.Lambda, p.Source)
+	}
+	if run == nil {
+		run = func(ctx context.Context, p config.Params) (*Result, error) { return Run(ctx, p, Hooks{}) }
+	}
+	var tEnd float64
+	for i, s := range samples {
+		switch {
+		case math.IsNaN(s.T) || math.IsNaN(s.X) || math.IsNaN(s.U) || math.IsInf(s.T, 0) || math.IsInf(s.U, 0):
+			return AlphaFit{}, fmt.Errorf("sample %d: not a finite value", i+1)
+		case s.X < 0 || s.X > 1:
+			return AlphaFit{}, fmt.Errorf("sample %d: x = %g outside [0, 1]", i+1, s.X)
+		case s.T < 0:
+			return AlphaFit{}, fmt.Errorf("sample %d: t = %g is negative", i+1, s.T)
+		}
+		tEnd = max(tEnd, s.T)
+	}
+	sim := p
+	sim.Storage, sim.Precision = config.StorageFull, config.PrecisionFloat64
+	sim.Tmax = hi * tEnd
+	// Последний слой не раньше hi·max t: иначе крайние точки брались бы с
+	// края сетки
+	if _, nt := sim.Grid(); float64(nt)*sim.Dt < sim.Tmax {
+		sim.Tmax = float64(nt+1) * sim.Dt
+	}
+	res, err := run(ctx, sim)
+	if err != nil {
+		return AlphaFit{}, err
+	}
+	defer res.Release()
+	if res.Diverged {
+		return AlphaFit{}, fmt.Errorf("forward solve with dx=%g dt=%g: %w", sim.Dx, sim.Dt, ErrDiverged)
+	}
+	u := res.Rows()
+	misfit := func(alpha float64) float64 {
+		var sum float64
+		for _, s := range samples {
+			d := s.U - mathutils.Bilinear(u, res.Dx, res.Dt, s.X, alpha*s.T)
+			sum += d * d
+		}
+		return sum
+	}
+	fit := AlphaFit{Alpha: mathutils.MinimizeLog(misfit, lo, hi, fitTol), Model: make([]float64, len(samples))}
+	h := 1e-3 * fit.Alpha
+	var jj float64
+	for i, s := range samples {
+		fit.Model[i] = mathutils.Bilinear(u, res.Dx, res.Dt, s.X, fit.Alpha*s.T)
+		j := (mathutils.Bilinear(u, res.Dx, res.Dt, s.X, (fit.Alpha+h)*s.T) -
+			mathutils.Bilinear(u, res.Dx, res.Dt, s.X, (fit.Alpha-h)*s.T)) / (2 * h)
+		jj += j * j
+	}
+	ss := misfit(fit.Alpha)
+	n := float64(len(samples))
+	fit.RMS = math.Sqrt(ss / n)
+	half := math.Inf(1)
+	if jj > 0 {
+		half = 1.96 * math.Sqrt(ss/(n-1)/jj)
+	}
+	fit.Low, fit.High = fit.Alpha-half, fit.Alpha+half
+	return fit, nil
+}
+
+// Проверочные случаи подбора α; в Verify — под именем FIT
+var verificationFit = []VerifyCase{
+	fitRoundTripCase(0.7, 0.01, 0.02),
+}
+
+// Подбор α по синтетическим данным: точное решение
+// exp(−απ²t)·sin(πx) при α = alpha на датчиках x = 0.3 и 0.5 в моменты
+// 0.01…0.3 с нормальным шумом σ = noise (зерно фиксировано). CN с
+// dx = 0.02 и dt = 0.001. Значение — относительное отличие оценки от alpha
+func fitRoundTripCase(alpha, noise, tol float64) VerifyCase {
+	return VerifyCase{
+		Name:     fmt.Sprintf("round trip alpha=%g noise=%g", alpha, noise),
+		Quantity: "|alpha fit − alpha| / alpha",
+		Compare:  VerifyAtMost,
+		Expected: tol,
+		Measure: func(ctx context.Context, _ Method) (float64, string, error) {
+			rng := rand.New(rand.NewPCG(1, 2))
+			exact := mathutils.Analytical{Alpha: alpha}
+			var samples []ProbeSample
+			for _, x := range []float64{0.3, 0.5} {
+				for k := 1; k <= 30; k++ {
+					t := 0.01 * float64(k)
+					samples = append(samples, ProbeSample{T: t, X: x, U: exact.Eval(x, t) + noise*rng.NormFloat64()})
+				}
+			}
+			p := config.Params{Method: "CN", Dx: 0.02, Dt: 0.001, IC: config.ICSine}
+			fit, err := FitAlpha(ctx, p, samples, 0.1, 10, nil)
+			if err != nil {
+				return math.NaN(), "", err
+			}
+			return math.Abs(fit.Alpha-alpha) / alpha, fmt.Sprintf("alpha %.4f, 95%% interval [%.4f, %.4f], rms %.3g", fit.Alpha, fit.Low, fit.High, fit.RMS), nil
+		},
+	}
+}
@@ -0,0 +1,133 @@
+package solver
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"testing"
+
+	"heat-solver/internal/config"
+	"heat-solver/internal/mathutils"
+)
+
+// Синтетические данные: exp(−απ²t)·sin(πx) на датчиках x = 0.3 и 0.5 в
+// моменты 0.01…0.3 с нормальным шумом σ = noise
+func fitSamples(alpha, noise float64, seed uint64) []ProbeSample {
+	rng := rand.New(rand.NewPCG(seed, 2))
+	exact := mathutils.Analytical{Alpha: alpha}
+	var samples []ProbeSample
+	for _, x := range []float64{0.3, 0.5} {
+		for k := 1; k <= 30; k++ {
+			t := 0.01 * float64(k)
+			samples = append(samples, ProbeSample{T: t, X: x, U: exact.Eval(x, t) + noise*rng.NormFloat64()})
+		}
+	}
+	return samples
+}
+
+// Круговая проверка: α = 0.7 по зашумлённым данным восстанавливается с
+// точностью 2% при разных зёрнах шума и схемах, а интервал накрывает
+// истинное значение; без шума оценка точнее, интервал уже, а модель в
+// точках измерений совпадает с данными до ошибки схемы
+func TestFitAlphaRoundTrip(t *testing.T) {
+	const alpha = 0.7
+	tests := []struct {
+		method string
+		noise  float64
+		seed   uint64
+		tol    float64
+	}{
+		{"CN", 0.01, 1, 0.02},
+		{"CN", 0.01, 7, 0.02},
+		{"CN", 0.01, 42, 0.02},
+		{"BTCS", 0.01, 1, 0.02},
+		{"CN", 0, 1, 2e-3},
+	}
+	for _, tc := range tests {
+		t.Run(fmt.Sprintf("%s/noise=%g/seed=%d", tc.method, tc.noise, tc.seed), func(t *testing.T) {
+			samples := fitSamples(alpha, tc.noise, tc.seed)
+			p := config.Params{Method: tc.method, Dx: 0.02, Dt: 0.001, IC: config.ICSine}
+			fit, err := FitAlpha(context.Background(), p, samples, 0.1, 10, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if math.Abs(fit.Alpha-alpha)/alpha > tc.tol {
+				t.Errorf("alpha %.4f, want %g within %g", fit.Alpha, alpha, tc.tol)
+			}
+			if tc.noise > 0 && !(fit.Low <= alpha && alpha <= fit.High) {
+				t.Errorf("95%% interval [%.4f, %.4f] misses %g", fit.Low, fit.High, alpha)
+			}
+			if !(fit.Low < fit.Alpha && fit.Alpha < fit.High) || fit.High-fit.Low > 0.05 {
+				t.Errorf("interval [%.4f, %.4f] around %.4f", fit.Low, fit.High, fit.Alpha)
+			}
+			if len(fit.Model) != len(samples) {
+				t.Fatalf("%d model values for %d samples", len(fit.Model), len(samples))
+			}
+			var ss float64
+			for i, s := range samples {
+				d := fit.Model[i] - s.U
+				ss += d * d
+			}
+			if rms := math.Sqrt(ss / float64(len(samples))); math.Abs(rms-fit.RMS) > 1e-12 || rms > tc.noise*1.5+2e-3 {
+				t.Errorf("rms %.3g, reported %.3g", rms, fit.RMS)
+			}
+		})
+	}
+}
+
+// Оценка не зависит от того, с какой стороны диапазона она лежит, а
+// свой расчёт run получает шаг и время по τ = αt до hi·max t
+func TestFitAlphaRun(t *testing.T) {
+	samples := fitSamples(0.7, 0, 1)
+	p := config.Params{Method: "CN", Dx: 0.02, Dt: 0.001, IC: config.ICSine}
+	var calls int
+	var hi float64
+	run := func(ctx context.Context, sim config.Params) (*Result, error) {
+		calls++
+		if sim.Tmax < hi*0.3 || sim.Dt != p.Dt || sim.Storage != config.StorageFull {
+			t.Errorf("forward run tmax %g, dt %g, storage %v", sim.Tmax, sim.Dt, sim.Storage)
+		}
+		return Run(ctx, sim, Hooks{})
+	}
+	for _, r := range [][2]float64{{0.5, 2}, {0.65, 0.9}, {0.2, 0.71}} {
+		hi = r[1]
+		fit, err := FitAlpha(context.Background(), p, samples, r[0], r[1], run)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if math.Abs(fit.Alpha-0.7) > 2e-3 {
+			t.Errorf("range %v: alpha %.5f", r, fit.Alpha)
+		}
+	}
+	if calls != 3 {
+		t.Errorf("%d forward runs, want one per fit", calls)
+	}
+}
+
+func TestFitAlphaRejects(t *testing.T) {
+	good := fitSamples(0.7, 0, 1)
+	p := config.Params{Method: "CN", Dx: 0.02, Dt: 0.001, IC: config.ICSine}
+	tests := []struct {
+		name    string
+		p       config.Params
+		samples []ProbeSample
+		lo, hi  float64
+	}{
+		{"one sample", p, good[:1], 0.1, 10},
+		{"zero min", p, good, 0, 10},
+		{"min above max", p, good, 2, 1},
+		{"infinite max", p, good, 0.1, math.Inf(1)},
+		{"reaction", config.Params{Method: "CN", Dx: 0.02, Dt: 0.001, Lambda: 1}, good, 0.1, 10},
+		{"source", config.Params{Method: "CN", Dx: 0.02, Dt: 0.001, Source: "sine"}, good, 0.1, 10},
+		{"NaN value", p, []ProbeSample{{0.1, 0.5, math.NaN()}, {0.2, 0.5, 0}}, 0.1, 10},
+		{"infinite time", p, []ProbeSample{{math.Inf(1), 0.5, 0}, {0.2, 0.5, 0}}, 0.1, 10},
+		{"x outside", p, []ProbeSample{{0.1, 1.5, 0}, {0.2, 0.5, 0}}, 0.1, 10},
+		{"negative time", p, []ProbeSample{{-0.1, 0.5, 0}, {0.2, 0.5, 0}}, 0.1, 10},
+	}
+	for _, tc := range tests {
+		if fit, err := FitAlpha(context.Background(), tc.p, tc.samples, tc.lo, tc.hi, nil); err == nil {
+			t.Errorf("%s accepted: %+v", tc.name, fit)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package solver
+
+import (
+	"math"
+
+	"heat-solver/internal/mathutils"
+)
+
+// Баланс тепла за расчёт: изменение Q = ∫u dx против притока через
+// границы ∫₀ᵀ (α·u_x(1,t) − α·u_x(0,t)) dt при α = 1 и тепла от источника
+// Supplied = ∫₀ᵀ∫₀¹ f dx dt. Imbalance = (Final − Initial) − Inflow −
+// Supplied; Relative — |Imbalance|/|Final − Initial| (NaN, если Q не
+// изменилось)
+type FluxBalance struct {
+	Initial   float64
+	Final     float64
+	Inflow    float64
+	Supplied  float64
+	Imbalance float64
+	Relative  float64
+	// Только с источником (FluxAudit.WithSource): наибольшее за шаг
+	// |ΔQ/dt − (приток + мощность источника)|, средние по шагу, — в
+	// единицах мощности, шаг, где оно достигнуто, и для сравнения
+	// наибольший по модулю член баланса (мощность источника или приток) за
+	// расчёт. Без источника — нули
+	StepImbalance float64
+	StepWorst     int
+	PeakRate      float64
+}
+
+// Накопление притока тепла через границы по слоям: u_x на краях —
+// односторонние разности второго порядка по трём узлам, по времени —
+// формула трапеций. На слой O(1) операций, поэтому аудит ведётся во всех
+// режимах хранения по мере счёта. С источником на слой добавляется O(nx):
+// мощность источника dx·Σf по внутренним узлам (граничные значения схема
+// не меняет) и теплосодержание для баланса каждого шага
+type FluxAudit struct {
+	dx, dt  float64
+	initial float64
+	inflow  float64
+	prev    float64
+
+	source   mathutils.Source
+	f        []float64
+	power    float64 // мощность источника на предыдущем слое
+	heat     float64 // Q предыдущего слоя
+	supplied float64
+	worst    float64
+	at       int
+	peak     float64
+}
+
+func NewFluxAudit(dx, dt float64) *FluxAudit {
+	return &FluxAudit{dx: dx, dt: dt}
+}
+
+// Учёт тепла от источника src (нулевой — без изменений); до первого Add
+func (a *FluxAudit) WithSource(src mathutils.Source) *FluxAudit {
+	a.source = src
+	return a
+}
+
+// Слой n; слои передаются по порядку, начиная с нулевого
+func (a *FluxAudit) Add(n int, row []float64) {
+	rate := boundaryInflow(row, a.dx)
+	if n == 0 {
+		a.initial = mathutils.HeatContent(row, a.dx)
+	} else {
+		a.inflow += a.dt * (a.prev + rate) / 2
+	}
+	if !a.source.IsZero() {
+		a.addSource(n, row, rate)
+	}
+	a.prev = rate
+}
+
+// Мощность источника на слое n и баланс шага n−1 → n: ΔQ против
+// dt·(приток + мощность) по формуле трапеций
+func (a *FluxAudit) addSource(n int, row []float64, rate float64) {
+	if len(a.f) != len(row) {
+		a.f = make([]float64, len(row))
+	}
+	a.source.Fill(a.f, a.dx, float64(n)*a.dt)
+	var power float64
+	if len(row) > 2 {
+		for _, v := range a.f[1 : len(row)-1] {
+			power += v
+		}
+	}
+	power *= a.dx
+	heat := mathutils.HeatContent(row, a.dx)
+	if n > 0 {
+		a.supplied += a.dt * (a.power + power) / 2
+		gap := math.Abs((heat-a.heat)/a.dt - (a.prev+rate)/2 - (a.power+power)/2)
+		if gap > a.worst {
+			a.worst, a.at = gap, n
+		}
+	}
+	a.peak = max(a.peak, math.Abs(power), math.Abs(rate))
+	a.power, a.heat = power, heat
+}
+
+// Итог по последнему слою final
+func (a *FluxAudit) Report(final []float64) FluxBalance {
+	b := newFluxBalance(a.initial, mathutils.HeatContent(final, a.dx), a.inflow, a.supplied)
+	b.StepImbalance, b.StepWorst, b.PeakRate = a.worst, a.at, a.peak
+	return b
+}
+
+// Небаланс по теплосодержанию в начале и в конце, притоку и теплу от
+// источника за расчёт
+func newFluxBalance(initial, final, inflow, supplied float64) FluxBalance {
+	b := FluxBalance{Initial: initial, Final: final, Inflow: inflow, Supplied: supplied}
+	b.Imbalance = (b.Final - b.Initial) - b.Inflow - b.Supplied
+	b.Relative = math.NaN()
+	if change := b.Final - b.Initial; change != 0 {
+		b.Relative = math.Abs(b.Imbalance) / math.Abs(change)
+	}
+	return b
+}
+
+// u_x(1) − u_x(0): (3u_N − 4u_{N−1} + u_{N−2})/(2dx) и
+// (−3u_0 + 4u_1 − u_2)/(2dx); при nx < 2 — 0
+func boundaryInflow(row []float64, dx float64) float64 {
+	n := len(row) - 1
+	if n < 2 {
+		return 0
+	}
+	right := (3*row[n] - 4*row[n-1] + row[n-2]) / (2 * dx)
+	left := (-3*row[0] + 4*row[1] - row[2]) / (2 * dx)
+	return right - left
+}
@@ -0,0 +1,178 @@
+package solver
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"heat-solver/internal/config"
+	"heat-solver/internal/mathutils"
+)
+
+// Разности второго порядка точны на квадратичных профилях
+func TestBoundaryInflow(t *testing.T) {
+	tests := []struct {
+		name string
+		row  []float64
+		dx   float64
+		want float64
+	}{
+		{"empty", nil, 0.1, 0},
+		{"two nodes", []float64{0, 1}, 1, 0},
+		{"constant", []float64{3, 3, 3, 3}, 1.0 / 3, 0},
+		{"linear", []float64{0, 0.25, 0.5, 0.75, 1}, 0.25, 0},
+		// u = x(1−x): u_x(1) − u_x(0) = −1 − 1
+		{"parabola", []float64{0, 0.1875, 0.25, 0.1875, 0}, 0.25, -2},
+		// u = x²: 2 − 0, по трём узлам
+		{"three nodes", []float64{0, 0.25, 1}, 0.5, 2},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := boundaryInflow(tc.row, tc.dx); math.Abs(got-tc.want) > 1e-14 {
+				t.Errorf("inflow %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+// Приток по слоям с постоянной скоростью −2 и −4 — трапеции дают
+// dt·(−2 −4)/2 на шаг; без изменения Q Relative — NaN
+func TestFluxAudit(t *testing.T) {
+	const dx, dt = 0.25, 0.1
+	audit := NewFluxAudit(dx, dt)
+	parabola := []float64{0, 0.1875, 0.25, 0.1875, 0}
+	double := []float64{0, 0.375, 0.5, 0.375, 0}
+	audit.Add(0, parabola)
+	audit.Add(1, double)
+	audit.Add(2, parabola)
+	b := audit.Report(parabola)
+	q := dx * (0.1875 + 0.25 + 0.1875)
+	wantInflow := dt*(-2-4)/2 + dt*(-4-2)/2
+	if math.Abs(b.Initial-q) > 1e-15 || b.Final != b.Initial || math.Abs(b.Inflow-wantInflow) > 1e-15 ||
+		math.Abs(b.Imbalance+wantInflow) > 1e-15 || !math.IsNaN(b.Relative) || b.Supplied != 0 || b.StepImbalance != 0 {
+		t.Errorf("balance %+v; want Q %v throughout and inflow %v", b, q, wantInflow)
+	}
+}
+
+// Небаланс CN убывает как dx² при r = 1, BTCS — как dt; в режимах
+// full, final-only и с контрольными слоями аудит одинаковый
+func TestFluxBalanceRun(t *testing.T) {
+	tests := []struct {
+		name  string
+		steps []config.Params
+		ratio float64
+	}{
+		{"CN dx", []config.Params{
+			{Method: "CN", Dx: 0.02, Dt: 4e-4, Tmax: 0.1},
+			{Method: "CN", Dx: 0.01, Dt: 1e-4, Tmax: 0.1},
+			{Method: "CN", Dx: 0.005, Dt: 2.5e-5, Tmax: 0.1},
+		}, 4},
+		{"BTCS dt", []config.Params{
+			{Method: "BTCS", Dx: 0.002, Dt: 0.004, Tmax: 0.1},
+			{Method: "BTCS", Dx: 0.002, Dt: 0.002, Tmax: 0.1},
+			{Method: "BTCS", Dx: 0.002, Dt: 0.001, Tmax: 0.1},
+		}, 2},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var prev float64
+			for k, p := range tc.steps {
+				p = p.Normalize()
+				full, err := Run(context.Background(), p, Hooks{})
+				if err != nil {
+					t.Fatal(err)
+				}
+				defer full.Release()
+				final := p
+				final.Storage = config.StorageFinalOnly
+				streamed, err := Run(context.Background(), final, Hooks{})
+				if err != nil {
+					t.Fatal(err)
+				}
+				defer streamed.Release()
+				checkpointed, err := Checkpointed(context.Background(), p, 7, Hooks{})
+				if err != nil {
+					t.Fatal(err)
+				}
+				defer checkpointed.Release()
+				b := full.Flux
+				if streamed.Flux != b || checkpointed.Flux != b {
+					t.Fatalf("dx=%g dt=%g: full %+v, final-only %+v, checkpoint %+v", p.Dx, p.Dt, b, streamed.Flux, checkpointed.Flux)
+				}
+				if b.Final >= b.Initial || b.Inflow >= 0 || b.Relative > 0.05 {
+					t.Errorf("dx=%g dt=%g: balance %+v", p.Dx, p.Dt, b)
+				}
+				imb := math.Abs(b.Imbalance)
+				if k > 0 {
+					if ratio := prev / imb; math.Abs(ratio-tc.ratio) > 0.15*tc.ratio {
+						t.Errorf("imbalance %.3g → %.3g, ratio %.2f; want %g", prev, imb, ratio, tc.ratio)
+					}
+				}
+				prev = imb
+			}
+		})
+	}
+}
+
+// Баланс каждого шага с пучком verifyLaser, который проходит отрезок и
+// уходит с него: небаланс первого порядка по dx (около 3.5·dx от
+// наибольшего члена баланса — разность притока второго порядка и потерь
+// схемы у края), тепло от источника — интеграл мощности пучка на
+// отрезке; full и final-only дают один аудит
+func TestLaserBalance(t *testing.T) {
+	tests := []struct {
+		method string
+		r      float64
+		dxs    []float64
+	}{
+		{"CN", 2.5, []float64{0.02, 0.01, 0.005}},
+		{"BTCS", 5, []float64{0.02, 0.01, 0.005}},
+		{"FTCS", 0.4, []float64{0.02, 0.01}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.method, func(t *testing.T) {
+			var prev float64
+			for _, dx := range tc.dxs {
+				p := config.Params{Method: tc.method, Dx: dx, Dt: tc.r * dx * dx, Tmax: 2,
+					Source: mathutils.SourceLaser, Laser: verifyLaser}.Normalize()
+				full, err := Run(context.Background(), p, Hooks{})
+				if err != nil {
+					t.Fatal(err)
+				}
+				defer full.Release()
+				b := full.Flux
+				p.Storage = config.StorageFinalOnly
+				streamed, err := Run(context.Background(), p, Hooks{})
+				if err != nil {
+					t.Fatal(err)
+				}
+				defer streamed.Release()
+				if streamed.Flux != b {
+					t.Fatalf("dx=%g: full %+v, final-only %+v", dx, b, streamed.Flux)
+				}
+
+				ratio := b.StepImbalance / b.PeakRate
+				if ratio > 4*dx {
+					t.Errorf("dx=%g: step imbalance %.3g of the peak rate", dx, ratio)
+				}
+				if prev > 0 {
+					if order := math.Log2(prev / ratio); order < 0.8 || order > 1.3 {
+						t.Errorf("dx=%g: imbalance %.3g → %.3g, order %.2f", dx, prev, ratio, order)
+					}
+				}
+				prev = ratio
+
+				// ∫₀² Deposited dt по Симпсону
+				const n = 2000
+				h := 2.0 / n
+				sum := verifyLaser.Deposited(0) + verifyLaser.Deposited(2)
+				for i := 1; i < n; i++ {
+					sum += float64(2+2*(i%2)) * verifyLaser.Deposited(float64(i)*h)
+				}
+				if want := sum * h / 3; math.Abs(b.Supplied-want) > 2*dx*want {
+					t.Errorf("dx=%g: supplied %.6g, deposited %.6g", dx, b.Supplied, want)
+				}
+			}
+		})
+	}
+}
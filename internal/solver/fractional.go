@@ -0,0 +1,282 @@
+package solver
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"slices"
+
+	"heat-solver/internal/config"
+	"heat-solver/internal/mathutils"
+)
+
+// Дробное по времени уравнение ∂^β u/∂t^β = α·u_xx с производной Капуто
+// порядка 0 < β ≤ 1 и нулём на краях. Схема L1: на каждом отрезке
+// [t_j, t_{j+1}] u линейна, и
+//
+//	∂^β u(t_{n+1}) ≈ dt^{−β}/Γ(2 − β) · Σ_{k=0..n} b_k·(u^{n+1−k} − u^{n−k}),
+//
+// b_k = (k + 1)^{1−β} − k^{1−β}, b_0 = 1. Производная помнит всю историю:
+// шаг — трёхдиагональная система (1 − μδ²)·u^{n+1} = u^n − Σ_{k≥1}
+// b_k·(u^{n+1−k} − u^{n−k}), μ = Γ(2 − β)·α·dt^β/dx², с постоянной
+// матрицей, но сумма по прошлым слоям делает расчёт O(nt²·nx), а хранить
+// приходится все слои. При β = 1 все b_k с k ≥ 1 — нули, и шаг — BTCS
+// побитно. Из sin(πx) точное решение — E_β(−απ²t^β)·sin(πx)
+// (mathutils.FractionalMode); у него особенность t^β в нуле, и на
+// равномерной сетке по t ошибка L1 в фиксированный момент — первого
+// порядка по dt, а не 2 − β, как у гладких решений
+
+// Операций суммы по истории, с которых расчёт предупреждает о стоимости
+const fractionalWarnOps = 1e9
+
+// Дробное уравнение с заданным профилем u0 (края нулевые, как у start);
+// каждый слой передаётся в emit. memory = 0 — полная история, хранятся
+// все nt+1 слоёв. memory > 0 — короткая память: последние memory слоёв
+// входят в сумму точно, а более старая история — блоками по memory слоёв
+// (fractionalTail), так что хранится около memory + nt/memory слоёв, и
+// шаг стоит O(memory + n/memory) вместо O(n); и то и другое наименьшее
+// при memory ≈ √nt, а ошибка от блоков растёт с их длиной. Просто отбросить хвост
+// (принцип фиксированной памяти) нельзя: уходящее из суммы начальное
+// u^0 − u^n у затухающего решения сравнимо с самим u, и решение
+// затухает в разы быстрее. β вне (0, 1] — ошибка
+func StreamFractionalFrom(u0 []float64, nt int, dx, dt, alpha, beta float64, memory int, emit EmitFunc) ([]float64, error) {
+	if !(beta > 0 && beta <= 1) {
+		return nil, fmt.Errorf("fractional order must be in (0, 1], got %g", beta)
+	}
+	if memory < 0 {
+		return nil, fmt.Errorf("memory must be non-negative, got %d", memory)
+	}
+	window := nt
+	if memory > 0 {
+		window = min(memory, nt)
+	}
+	u := newGrid(window+2, len(u0))
+	if err := fractional(u0, nt, dx, dt, alpha, beta, window, u, emit); err != nil {
+		return nil, err
+	}
+	return level(u, nt), nil
+}
+
+// Хвост суммы по истории старше окна: слои 0, s, 2s, … (s — длина окна)
+// сохраняются, и на блоке [js, (j+1)s] сумма b_{n−m}·(u^{m+1} − u^m)
+// заменяется средним весом блока на u^{(j+1)s} − u^{js}. Веса b_k при
+// k > s меняются на блоке не больше чем в 2^β раз, так что ошибка — доля
+// от вклада блока, а вклад старых блоков мал. Последний неполный блок кончается на u^{n−s},
+// который ещё в окне
+type fractionalTail struct {
+	beta  float64
+	s     int
+	saved [][]float64
+}
+
+// Среднее b_k при k от lo до hi включительно: их сумма
+// (hi+1)^{1−β} − lo^{1−β}, делённая на hi − lo + 1
+func (t *fractionalTail) weight(lo, hi int) float64 {
+	return (math.Pow(float64(hi+1), 1-t.beta) - math.Pow(float64(lo), 1-t.beta)) / float64(hi-lo+1)
+}
+
+// Запоминание слоя m, если он на границе блока
+func (t *fractionalTail) keep(m int, row []float64) {
+	if t.s > 0 && m%t.s == 0 {
+		t.saved = append(t.saved, slices.Clone(row))
+	}
+}
+
+// Вычитание хвоста из d (узлы 1..nx−1) на шаге n → n+1; edge — слой n−s
+func (t *fractionalTail) subtract(d []float64, n int, edge []float64) {
+	end := n - t.s // разности u^{m+1} − u^m при m < end
+	for j := 0; j*t.s < end; j++ {
+		lo, hi := j*t.s, min((j+1)*t.s, end)
+		w := t.weight(n-hi+1, n-lo)
+		from, to := t.saved[j], edge
+		if hi < end {
+			to = t.saved[j+1]
+		}
+		for i := range d {
+			d[i] -= w * (to[i+1] - from[i+1])
+		}
+	}
+}
+
+// Веса L1 b_0..b_window
+func l1Weights(beta float64, window int) []float64 {
+	b := make([]float64, window+1)
+	for k := range b {
+		b[k] = math.Pow(float64(k+1), 1-beta) - math.Pow(float64(k), 1-beta)
+	}
+	return b
+}
+
+func fractional(u0 []float64, nt int, dx, dt, alpha, beta float64, window int, u [][]float64, emit EmitFunc) error {
+	nx := len(u0) - 1
+	mu := math.Gamma(2-beta) * alpha * math.Pow(dt, beta) / (dx * dx)
+	slog.Info("Starting fractional L1 solver", "beta", beta, "nx", nx, "nt", nt, "dx", dx, "dt", dt, "mu", mu, "memory_levels", window)
+	ops := float64(nx-1) * float64(window) * (float64(nt) - float64(window)/2)
+	if ops > fractionalWarnOps {
+		slog.Warn("The history sum of the fractional derivative grows as O(nt²); a short memory cuts it", "history_ops", ops, "stored_levels", window+2,
+			"stored_mib", float64((window+2)*(nx+1)*8)/(1<<20))
+	}
+
+	if err := start(u, u0, emit); err != nil {
+		return err
+	}
+
+	b := l1Weights(beta, window)
+	var tail fractionalTail
+	if window < nt {
+		tail = fractionalTail{beta: beta, s: window}
+		tail.keep(0, level(u, 0))
+	}
+	a, bb, c, d, ws := workspace(nx - 1)
+	defer pool64.put(ws)
+	for i := range bb {
+		a[i], bb[i], c[i] = -mu, 1+2*mu, -mu
+	}
+	tri := newTridiag(a, bb, c)
+	defer tri.close()
+
+	for n := 0; n < nt; n++ {
+		cur, next := level(u, n), level(u, n+1)
+		copy(d, cur[1:nx])
+		for k := 1; k <= min(n, window); k++ {
+			hi, lo := level(u, n+1-k), level(u, n-k)
+			w := b[k]
+			for i := range d {
+				d[i] -= w * (hi[i+1] - lo[i+1])
+			}
+		}
+		if n > window {
+			tail.subtract(d, n, level(u, n-window))
+		}
+		tri.solve(d, next[1:nx])
+		tail.keep(n+1, next)
+		if err := emitLevel(emit, n+1, next); err != nil {
+			return err
+		}
+	}
+
+	slog.Info("Fractional L1 solver finished successfully")
+	return nil
+}
+
+// Проверочные случаи дробного уравнения; в Verify — под именем FRACTIONAL
+var verificationFractional = []VerifyCase{
+	fractionalErrorCase(0.5, 0.02, 0.001, 0.5, 2e-3),
+	fractionalErrorCase(0.8, 0.02, 0.001, 0.5, 2e-3),
+	fractionalOrderCase(0.5, 0.05, 0.01, 0.5, 1, 0.15),
+	fractionalBTCSCase(0.05, 0.001, 0.1),
+	fractionalMemoryCase(0.5, 0.05, 0.001, 1, 30, 0.01),
+}
+
+// Ошибка L∞ схемы L1 из sin(πx) против E_β(−π²t^β)·sin(πx) в момент tmax
+// не больше tol
+func fractionalErrorCase(beta, dx, dt, tmax, tol float64) VerifyCase {
+	mode := mathutils.FractionalMode{Alpha: 1, Beta: beta}
+	return VerifyCase{
+		Name:     fmt.Sprintf("β=%g Mittag-Leffler mode dx=%g dt=%g", beta, dx, dt),
+		Quantity: "L∞ error",
+		Compare:  VerifyAtMost,
+		Expected: tol,
+		Measure: func(ctx context.Context, _ Method) (float64, string, error) {
+			last, t, err := fractionalRun(beta, dx, dt, tmax, 0)
+			if err != nil {
+				return math.NaN(), "", err
+			}
+			_, linf := mathutils.LevelErrors(last, dx, t, mode)
+			return linf, fmt.Sprintf("E_β(−π²t^β) = %.6f, heat equation exp(−π²t) = %.2e", mode.Amplitude(t), math.Exp(-math.Pi*math.Pi*t)), nil
+		},
+	}
+}
+
+// Наблюдаемый порядок по dt при постоянном dx: dt делится пополам дважды,
+// порядок — по разностям решений соседних шагов, так что ошибка по x
+// сокращается
+func fractionalOrderCase(beta, dx, dt, tmax, order, tol float64) VerifyCase {
+	return VerifyCase{
+		Name:      fmt.Sprintf("β=%g L1 order in dt from dt=%g dx=%g", beta, dt, dx),
+		Quantity:  "observed order",
+		Compare:   VerifyNear,
+		Expected:  order,
+		Tolerance: tol,
+		Measure: func(ctx context.Context, _ Method) (float64, string, error) {
+			var levels [3][]float64
+			for k := range levels {
+				var err error
+				if levels[k], _, err = fractionalRun(beta, dx, dt/math.Exp2(float64(k)), tmax, 0); err != nil {
+					return math.NaN(), "", err
+				}
+			}
+			var diffs [2]float64
+			for k := range diffs {
+				for i := range levels[k] {
+					diffs[k] = math.Max(diffs[k], math.Abs(levels[k][i]-levels[k+1][i]))
+				}
+			}
+			return math.Log2(diffs[0] / diffs[1]), fmt.Sprintf("differences %.3g, %.3g", diffs[0], diffs[1]), nil
+		},
+	}
+}
+
+// При β = 1 схема L1 — BTCS (через Run, как из CLI): наибольшее отличие —
+// 0
+func fractionalBTCSCase(dx, dt, tmax float64) VerifyCase {
+	return VerifyCase{
+		Name:     fmt.Sprintf("β = 1 against BTCS dx=%g dt=%g", dx, dt),
+		Quantity: "max |u − u BTCS|",
+		Compare:  VerifyAtMost,
+		Expected: 0,
+		Measure: func(ctx context.Context, _ Method) (float64, string, error) {
+			p := config.Params{Method: "BTCS", Dx: dx, Dt: dt, Tmax: tmax, Storage: config.StorageFinalOnly, Precision: config.PrecisionFloat64}
+			res, err := Run(ctx, p, Hooks{})
+			if err != nil {
+				return math.NaN(), "", err
+			}
+			defer res.Release()
+			last, _, err := fractionalRun(1, res.Dx, res.Dt, tmax, 0)
+			if err != nil {
+				return math.NaN(), "", err
+			}
+			var worst float64
+			for i, v := range last {
+				worst = math.Max(worst, math.Abs(v-res.Final[i]))
+			}
+			return worst, "", nil
+		},
+	}
+}
+
+// Короткая память в memory слоёв против полной истории: наибольшее
+// отличие относительно max|u| в момент tmax не больше tol
+func fractionalMemoryCase(beta, dx, dt, tmax float64, memory int, tol float64) VerifyCase {
+	return VerifyCase{
+		Name:     fmt.Sprintf("β=%g short memory of %d levels dt=%g tmax=%g", beta, memory, dt, tmax),
+		Quantity: "max |u − u full| / max |u full|",
+		Compare:  VerifyAtMost,
+		Expected: tol,
+		Measure: func(ctx context.Context, _ Method) (float64, string, error) {
+			full, _, err := fractionalRun(beta, dx, dt, tmax, 0)
+			if err != nil {
+				return math.NaN(), "", err
+			}
+			short, _, err := fractionalRun(beta, dx, dt, tmax, memory)
+			if err != nil {
+				return math.NaN(), "", err
+			}
+			var worst, peak float64
+			for i, v := range full {
+				worst = math.Max(worst, math.Abs(short[i]-v))
+				peak = math.Max(peak, math.Abs(v))
+			}
+			return worst / peak, fmt.Sprintf("%d of %d levels exact, older ones in blocks", memory, int(math.Round(tmax/dt))), nil
+		},
+	}
+}
+
+// Последний слой схемы L1 из sin(πx) и его момент
+func fractionalRun(beta, dx, dt, tmax float64, memory int) ([]float64, float64, error) {
+	nx := int(math.Round(1 / dx))
+	nt := int(math.Round(tmax / dt))
+	last, err := StreamFractionalFrom(SineProfile(nx, dx), nt, dx, dt, 1, beta, memory, nil)
+	return last, float64(nt) * dt, err
+}
@@ -0,0 +1,125 @@
+package solver
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"testing"
+
+	"heat-solver/internal/mathutils"
+)
+
+// Схема L1 из sin(πx) против E_β(−π²t^β)·sin(πx): ошибка мала при β от
+// 0.3 до 0.9 и первого порядка по dt (особенность t^β в нуле)
+func TestFractionalMode(t *testing.T) {
+	for _, beta := range []float64{0.3, 0.5, 0.9} {
+		t.Run(fmt.Sprintf("β=%g", beta), func(t *testing.T) {
+			mode := mathutils.FractionalMode{Alpha: 1, Beta: beta}
+			last, tm, err := fractionalRun(beta, 0.02, 0.001, 0.5, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, linf := mathutils.LevelErrors(last, 0.02, tm, mode); linf > 2e-3 {
+				t.Errorf("L∞ error %.3g", linf)
+			}
+		})
+	}
+	var levels [3][]float64
+	for k := range levels {
+		var err error
+		if levels[k], _, err = fractionalRun(0.5, 0.05, 0.01/math.Exp2(float64(k)), 0.5, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var diffs [2]float64
+	for k := range diffs {
+		for i := range levels[k] {
+			diffs[k] = math.Max(diffs[k], math.Abs(levels[k][i]-levels[k+1][i]))
+		}
+	}
+	if order := math.Log2(diffs[0] / diffs[1]); math.Abs(order-1) > 0.15 {
+		t.Errorf("differences %.3g, %.3g: order %.3f in dt", diffs[0], diffs[1], order)
+	}
+}
+
+// При β = 1 веса истории — нули, и шаг побитно совпадает с BTCS
+func TestFractionalBTCS(t *testing.T) {
+	const nx, nt, dx, dt = 20, 100, 0.05, 0.001
+	u0 := SineProfile(nx, dx)
+	btcs, err := StreamBTCSFrom(u0, nt, dx, dt, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, memory := range []int{0, 7} {
+		last, err := StreamFractionalFrom(u0, nt, dx, dt, 1, 1, memory, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Equal(last, btcs) {
+			t.Errorf("memory %d: differs from BTCS", memory)
+		}
+	}
+}
+
+// Короткая память: окно не меньше nt — та же полная история; окна около
+// √nt отличаются от неё меньше чем на 1%. Старые слои идут блоками длины
+// окна, так что отличие растёт вместе с окном
+func TestFractionalMemory(t *testing.T) {
+	const beta, dx, dt, tmax = 0.5, 0.05, 0.001, 1
+	full, _, err := fractionalRun(beta, dx, dt, tmax, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wide, _, err := fractionalRun(beta, dx, dt, tmax, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(wide, full) {
+		t.Error("a window of nt levels differs from the full history")
+	}
+	var prev float64
+	for _, memory := range []int{10, 30} {
+		short, _, err := fractionalRun(beta, dx, dt, tmax, memory)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var worst, peak float64
+		for i, v := range full {
+			worst = math.Max(worst, math.Abs(short[i]-v))
+			peak = math.Max(peak, math.Abs(v))
+		}
+		rel := worst / peak
+		if rel > 0.01 || rel <= prev {
+			t.Errorf("memory %d: relative difference %.3g, previous %.3g", memory, rel, prev)
+		}
+		prev = rel
+	}
+}
+
+// Предупреждение о стоимости O(nt²) — только когда сумма по истории
+// превышает fractionalWarnOps; β вне (0, 1] и отрицательная память —
+// ошибки
+func TestFractionalRejects(t *testing.T) {
+	u0 := SineProfile(10, 0.1)
+	buf := captureWarnings(t)
+	if _, err := StreamFractionalFrom(u0, 100, 0.1, 0.001, 1, 0.5, 0, nil); err != nil || buf.Len() != 0 {
+		t.Errorf("small run: %v, warnings %q", err, buf.String())
+	}
+	// Предупреждение — до первого слоя, и расчёт обрывается на нём
+	stop := errors.New("stop")
+	big := SineProfile(20000, 1.0/20000)
+	_, err := StreamFractionalFrom(big, 330, 1.0/20000, 1e-6, 1, 0.5, 0, func(int, []float64) error { return stop })
+	if !errors.Is(err, stop) || !strings.Contains(buf.String(), "O(nt²)") {
+		t.Errorf("large run: %v, warnings %q", err, buf.String())
+	}
+	for _, tc := range []struct {
+		beta   float64
+		memory int
+	}{{0, 0}, {1.5, 0}, {math.NaN(), 0}, {-0.5, 0}, {0.5, -1}} {
+		if _, err := StreamFractionalFrom(u0, 1, 0.1, 0.001, 1, tc.beta, tc.memory, nil); err == nil {
+			t.Errorf("β = %v with memory %d accepted", tc.beta, tc.memory)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package solver
+
+import (
+	"log/slog"
+
+	"heat-solver/internal/mathutils"
+)
+
+// Двумерные слои хранятся одной строкой из (nx+1)·(ny+1) значений:
+// узел (x_i, y_j) = (i·dx, j·dy) — элемент j·(nx+1) + i, x меняется
+// быстрее. Так слои 2D живут в тех же сетках, пулах и EmitFunc, что и 1D
+
+// sin(πx)·sin(πy) на узлах единичного квадрата
+func SineProfile2D(nx, ny int, dx, dy float64) []float64 {
+	u0 := make([]float64, (nx+1)*(ny+1))
+	for j := 0; j <= ny; j++ {
+		sy := mathutils.InitialCondition(float64(j) * dy)
+		for i := 0; i <= nx; i++ {
+			u0[j*(nx+1)+i] = mathutils.InitialCondition(float64(i)*dx) * sy
+		}
+	}
+	return u0
+}
+
+// FTCS на квадрате [0, 1]² с нулевыми краями:
+// u^{n+1} = u^n + r_x·δ_x²u^n + r_y·δ_y²u^n, r_x = α·dt/dx², r_y = α·dt/dy².
+// Устойчива при r_x + r_y ≤ ½. Начальный слой — sin(πx)·sin(πy), точное
+// решение — exp(−2π²αt)·sin(πx)·sin(πy) (mathutils.Analytical2D)
+func Solve2DFTCS(nx, ny, nt int, dx, dy, dt, alpha float64) [][]float64 {
+	u := newGrid(nt+1, (nx+1)*(ny+1))
+	ftcs2D(SineProfile2D(nx, ny, dx, dy), nx, ny, nt, dx, dy, dt, alpha, u, nil)
+	return u
+}
+
+// 2D FTCS с заданным начальным слоем u0 и хранением двух слоёв; каждый
+// слой передаётся в emit
+func Stream2DFTCSFrom(u0 []float64, nx, ny, nt int, dx, dy, dt, alpha float64, emit EmitFunc) ([]float64, error) {
+	u := newGrid(2, len(u0))
+	if err := ftcs2D(u0, nx, ny, nt, dx, dy, dt, alpha, u, emit); err != nil {
+		return nil, err
+	}
+	return level(u, nt), nil
+}
+
+// Внутренние узлы обходятся плитками lineTile×lineTile (ftcs2DTiles), и
+// плитки делятся между потоками, как узлы в ftcs
+func ftcs2D(u0 []float64, nx, ny, nt int, dx, dy, dt, alpha float64, u [][]float64, emit EmitFunc) error {
+	rx, ry := alpha*dt/(dx*dx), alpha*dt/(dy*dy)
+	if rx+ry > 0.5 {
+		slog.Warn("2D FTCS may be unstable", "r_x", rx, "r_y", ry, "r_sum", rx+ry)
+	} else {
+		slog.Debug("2D FTCS stability check passed", "r_x", rx, "r_y", ry)
+	}
+	slog.Info("Starting 2D FTCS solver", "nx", nx, "ny", ny, "nt", nt, "dx", dx, "dy", dy, "dt", dt)
+
+	row := level(u, 0)
+	copy(row, u0)
+	zeroEdges2D(row, nx, ny)
+	if err := emitLevel(emit, 0, row); err != nil {
+		return err
+	}
+
+	var shared [2][]float64
+	update := func(_, lo, hi int) {
+		ftcs2DTiles(shared[0], shared[1], nx, ny, lo, hi, rx, ry)
+	}
+	tiles := lineBlocks(nx) * lineBlocks(ny)
+	pool := newChunkPool(0, tiles, max(1, ftcsMinChunk/(lineTile*lineTile)))
+	if pool != nil {
+		defer pool.close()
+		slog.Info("2D FTCS runs in parallel", "threads", pool.size())
+	}
+
+	for n := 0; n < nt; n++ {
+		cur, next := level(u, n), level(u, n+1)
+		zeroEdges2D(next, nx, ny)
+		if pool != nil {
+			shared = [2][]float64{cur, next}
+			pool.run(update)
+		} else {
+			ftcs2DTiles(cur, next, nx, ny, 0, tiles, rx, ry)
+		}
+		if err := emitLevel(emit, n+1, next); err != nil {
+			return err
+		}
+	}
+
+	slog.Info("2D FTCS solver finished successfully")
+	return nil
+}
+
+// Внутренние узлы плиток t из [lo, hi); плитки нумеруются по строкам,
+// lineBlocks(nx) в строке. Плитка вместе с соседними узлами помещается
+// в кэш. Соседи — срезы одной длины, как в
+// ftcsUpdateGeneric
+func ftcs2DTiles(cur, next []float64, nx, ny, lo, hi int, rx, ry float64) {
+	w, across := nx+1, lineBlocks(nx)
+	diag := 1 - 2*rx - 2*ry
+	for t := lo; t < hi; t++ {
+		j0, i0 := 1+t/across*lineTile, 1+t%across*lineTile
+		j1, i1 := min(j0+lineTile, ny), min(i0+lineTile, nx)
+		for j := j0; j < j1; j++ {
+			out := next[j*w+i0 : j*w+i1]
+			mid := cur[j*w+i0 : j*w+i1]
+			left, right := cur[j*w+i0-1:j*w+i1-1], cur[j*w+i0+1:j*w+i1+1]
+			down, up := cur[(j-1)*w+i0:(j-1)*w+i1], cur[(j+1)*w+i0:(j+1)*w+i1]
+			for i := range out {
+				out[i] = diag*mid[i] + rx*(left[i]+right[i]) + ry*(down[i]+up[i])
+			}
+		}
+	}
+}
+
+// Нулевые значения на четырёх сторонах квадрата
+func zeroEdges2D(row []float64, nx, ny int) {
+	w := nx + 1
+	clear(row[:w])
+	clear(row[ny*w:])
+	for j := 1; j < ny; j++ {
+		row[j*w], row[j*w+nx] = 0, 0
+	}
+}
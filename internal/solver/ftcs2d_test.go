@@ -0,0 +1,123 @@
+package solver
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+
+	"heat-solver/internal/mathutils"
+)
+
+// sin(πx)·sin(πy) — собственный вектор шага: за шаг он умножается на
+// G = 1 − 4r_x·sin²(π·dx/2) − 4r_y·sin²(π·dy/2); потоковый расчёт
+// совпадает с полным побитно, а ошибка против Analytical2D мала
+func TestFTCS2DSineMode(t *testing.T) {
+	tests := []struct {
+		nx, ny int
+		dt     float64
+	}{
+		{16, 16, 1.0 / 1024},
+		{12, 20, 0.0005},
+		{64, 64, 0.2 / (64 * 64)},
+	}
+	for _, tc := range tests {
+		t.Run(fmt.Sprintf("%dx%d", tc.nx, tc.ny), func(t *testing.T) {
+			const nt = 20
+			dx, dy := 1/float64(tc.nx), 1/float64(tc.ny)
+			rx, ry := tc.dt/(dx*dx), tc.dt/(dy*dy)
+			sx, sy := math.Sin(math.Pi*dx/2), math.Sin(math.Pi*dy/2)
+			g := 1 - 4*rx*sx*sx - 4*ry*sy*sy
+			u := Solve2DFTCS(tc.nx, tc.ny, nt, dx, dy, tc.dt, 1)
+			for n := range u {
+				scale := math.Pow(g, float64(n))
+				for c, v := range u[n] {
+					if math.Abs(v-scale*u[0][c]) > 1e-14 {
+						t.Fatalf("level %d, node %d: %v, want G^n·u0 = %v", n, c, v, scale*u[0][c])
+					}
+				}
+			}
+			_, linf := mathutils.LevelErrors2D(u[nt], tc.nx, tc.ny, dx, dy, nt*tc.dt, mathutils.Analytical2D{Alpha: 1})
+			if linf > 2*nt*tc.dt*math.Pi*math.Pi*(dx*dx+dy*dy) {
+				t.Errorf("L∞ error %.3g against Analytical2D", linf)
+			}
+			last, err := Stream2DFTCSFrom(SineProfile2D(tc.nx, tc.ny, dx, dy), tc.nx, tc.ny, nt, dx, dy, tc.dt, 1, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for c := range last {
+				if last[c] != u[nt][c] {
+					t.Fatalf("streamed node %d = %v, full run %v", c, last[c], u[nt][c])
+				}
+			}
+		})
+	}
+}
+
+// Ошибки L2 и L∞ против exp(−2π²αt)·sin(πx)·sin(πy) при r_x + r_y = 0.4
+// и α = 0.5: второй порядок по dx = dy
+func TestFTCS2DOrder(t *testing.T) {
+	const alpha, tmax = 0.5, 0.1
+	exact := mathutils.Analytical2D{Alpha: alpha}
+	var prev [2]float64
+	for k, n := range []int{8, 16, 32} {
+		h := 1 / float64(n)
+		dt := 0.2 * h * h / alpha
+		nt := int(math.Round(tmax / dt))
+		last, err := Stream2DFTCSFrom(SineProfile2D(n, n, h, h), n, n, nt, h, h, dt, alpha, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		l2, linf := mathutils.LevelErrors2D(last, n, n, h, h, float64(nt)*dt, exact)
+		if k > 0 {
+			for q, e := range [2]float64{l2, linf} {
+				if order := math.Log2(prev[q] / e); math.Abs(order-2) > 0.1 {
+					t.Errorf("n=%d: error %.3g → %.3g, order %.3f", n, prev[q], e, order)
+				}
+			}
+		}
+		prev = [2]float64{l2, linf}
+	}
+}
+
+// Предел r_x + r_y ≤ ½: выше него — предупреждение, и шахматная мода
+// растёт, ниже — затухает без предупреждения; сетка прямоугольная, так что
+// r_x ≠ r_y
+func TestFTCS2DStability(t *testing.T) {
+	const nx, ny, nt = 20, 10, 30
+	dx, dy := 1.0/nx, 1.0/ny
+	tests := []struct {
+		dt    float64
+		warns bool
+	}{
+		{0.45 / (1/(dx*dx) + 1/(dy*dy)), false},
+		{0.5 / (1/(dx*dx) + 1/(dy*dy)), false},
+		{0.55 / (1/(dx*dx) + 1/(dy*dy)), true},
+	}
+	for _, tc := range tests {
+		rsum := tc.dt/(dx*dx) + tc.dt/(dy*dy)
+		t.Run(fmt.Sprintf("r=%.2f", rsum), func(t *testing.T) {
+			warnings := captureWarnings(t)
+			u0 := make([]float64, (nx+1)*(ny+1))
+			for j := 1; j < ny; j++ {
+				for i := 1; i < nx; i++ {
+					u0[j*(nx+1)+i] = float64(1 - 2*((i+j)%2))
+				}
+			}
+			last, err := Stream2DFTCSFrom(u0, nx, ny, nt, dx, dy, tc.dt, 1, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var peak float64
+			for _, v := range last {
+				peak = max(peak, math.Abs(v))
+			}
+			if grows := peak > 1; grows != tc.warns {
+				t.Errorf("max |u| %.3g after %d steps", peak, nt)
+			}
+			if warned := strings.Contains(warnings.String(), "2D FTCS may be unstable"); warned != tc.warns {
+				t.Errorf("warning %v, want %v: %s", warned, tc.warns, warnings)
+			}
+		})
+	}
+}
@@ -0,0 +1,26 @@
+//go:build amd64 && !purego
+
+package solver
+
+import "golang.org/x/sys/cpu"
+
+// Векторное ядро FTCS используется, если процессор и ОС поддерживают AVX2
+var useAVX2 = cpu.X86.HasAVX2
+
+// next[i] = cur[i+1] + r·(cur[i+2] − 2·cur[i+1] + cur[i]) для i <
+// len(next)&^3 по четыре значения за раз, без FMA; len(cur) ≥ len(next)+2
+//
+//go:noescape
+func ftcsAVX2(next, cur []float64, r float64)
+
+// Шаг FTCS для внутренних узлов среза: блоки по четыре узла — ядром AVX2,
+// остаток — ftcsUpdateGeneric. Результат побитно совпадает с ftcsUpdateGeneric
+func ftcsUpdate(cur, next []float64, r float64) {
+	n := (len(cur) - 2) &^ 3
+	if !useAVX2 || n <= 0 {
+		ftcsUpdateGeneric(cur, next, r)
+		return
+	}
+	ftcsAVX2(next[1:n+1], cur[:n+2], r)
+	ftcsUpdateGeneric(cur[n:], next[n:], r)
+}
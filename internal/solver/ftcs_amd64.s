@@ -0,0 +1,35 @@
+//go:build amd64 && !purego
+
+#include "textflag.h"
+
+// func ftcsAVX2(next, cur []float64, r float64)
+//
+// Порядок операций тот же, что в ftcsUpdateGeneric: ((right − 2·mid) +
+// left)·r + mid, каждая операция округляется отдельно (без FMA).
+// 2·mid считается как mid + mid — это точно то же значение
+TEXT ·ftcsAVX2(SB), NOSPLIT, $0-56
+	MOVQ         next_base+0(FP), DI
+	MOVQ         next_len+8(FP), CX
+	MOVQ         cur_base+24(FP), SI
+	VBROADCASTSD r+48(FP), Y0
+	SHRQ         $2, CX
+	JZ           done
+
+loop:
+	VMOVUPD (SI), Y1
+	VMOVUPD 8(SI), Y2
+	VMOVUPD 16(SI), Y3
+	VADDPD  Y2, Y2, Y4
+	VSUBPD  Y4, Y3, Y3
+	VADDPD  Y1, Y3, Y3
+	VMULPD  Y0, Y3, Y3
+	VADDPD  Y3, Y2, Y2
+	VMOVUPD Y2, (DI)
+	ADDQ    $32, SI
+	ADDQ    $32, DI
+	DECQ    CX
+	JNZ     loop
+
+done:
+	VZEROUPPER
+	RET
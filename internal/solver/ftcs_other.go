@@ -0,0 +1,11 @@
+//go:build !amd64 || purego
+
+package solver
+
+// Векторного ядра FTCS на этой платформе нет
+const useAVX2 = false
+
+// Шаг FTCS для внутренних узлов среза; без векторного ядра
+func ftcsUpdate(cur, next []float64, r float64) {
+	ftcsUpdateGeneric(cur, next, r)
+}
@@ -0,0 +1,238 @@
+package solver
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+)
+
+// Метод конечных объёмов: неизвестные — средние c_j по ячейкам
+// [j·dx, (j+1)·dx], j = 0..nx−1, с центрами (j+½)·dx. Шаг собирается из
+// потоков через грани F_{j+½} = −α·(c_{j+1} − c_j)/dx:
+// (c_j^{n+1} − c_j^n)/dt = −(F_{j+½} − F_{j−½})/dx, по времени θ-схема
+// (θ = 0 — явная, ½ — CN, 1 — неявная). Край u = 0 задаётся фиктивной
+// ячейкой c_{−1} = −c_0 (c_nx = −c_{nx−1}), так что на граничной грани
+// F = −2α·c/dx. Изменение dx·Σc за шаг равно притоку через крайние грани
+// с точностью до округления; баланс (FluxBalance) возвращается вместе со
+// слоями ячеек. Начальные средние — точные средние sin(πx) по ячейкам
+func SolveFV(nx, nt int, dx, dt, alpha, theta float64) ([][]float64, FluxBalance) {
+	c0 := make([]float64, nx)
+	for j := range c0 {
+		c0[j] = (math.Cos(math.Pi*float64(j)*dx) - math.Cos(math.Pi*float64(j+1)*dx)) / (math.Pi * dx)
+	}
+	c := newGrid(nt+1, nx)
+	b, _ := fvScheme(c0, nt, dx, dt, alpha, theta, func(n int, row []float64) error {
+		copy(c[n], row)
+		return nil
+	})
+	return c, b
+}
+
+// Конечные объёмы с узловым профилем u0 (nx+1 значений): он переводится
+// в средние по ячейкам (c_j = (u_j + u_{j+1})/2), а на выходе значение во
+// внутреннем узле — полусумма соседних ячеек. Обе интерполяции второго
+// порядка, как и сама схема
+func SolveFVFrom(u0 []float64, nt int, dx, dt, alpha, theta float64) [][]float64 {
+	u := newGrid(nt+1, len(u0))
+	fvNodal(u0, nt, dx, dt, alpha, theta, u, nil)
+	return u
+}
+
+// Конечные объёмы с хранением двух слоёв; каждый узловой слой передаётся
+// в emit
+func StreamFVFrom(u0 []float64, nt int, dx, dt, alpha, theta float64, emit EmitFunc) ([]float64, error) {
+	u := newGrid(2, len(u0))
+	if err := fvNodal(u0, nt, dx, dt, alpha, theta, u, emit); err != nil {
+		return nil, err
+	}
+	return level(u, nt), nil
+}
+
+// Нулевой слой — сам u0, как у остальных схем; дальше слои
+// восстанавливаются по ячейкам
+func fvNodal(u0 []float64, nt int, dx, dt, alpha, theta float64, u [][]float64, emit EmitFunc) error {
+	nx := len(u0) - 1
+	c0 := pool64.get(nx)
+	defer pool64.put(c0)
+	for j := range c0 {
+		c0[j] = (u0[j] + u0[j+1]) / 2
+	}
+	_, err := fvScheme(c0, nt, dx, dt, alpha, theta, func(n int, c []float64) error {
+		if n == 0 {
+			return start(u, u0, emit)
+		}
+		row := level(u, n)
+		row[0], row[nx] = 0, 0
+		for i := 1; i < nx; i++ {
+			row[i] = (c[i-1] + c[i]) / 2
+		}
+		return emitLevel(emit, n, row)
+	})
+	return err
+}
+
+// Расчёт по ячейкам; out получает слои по порядку (срез переиспользуется).
+// Явная часть — разность потоков F^n, неявная — система, собранная по
+// граням (fvAssemble). Приток за шаг — dt·((1−θ)·(F_0 − F_nx)^n +
+// θ·(F_0 − F_nx)^{n+1}), где F_0 и F_nx — потоки через левую и правую
+// границу
+func fvScheme(c0 []float64, nt int, dx, dt, alpha, theta float64, out func(n int, c []float64) error) (FluxBalance, error) {
+	cells := len(c0)
+	r := alpha * dt / (dx * dx)
+	warnUnstable("FV", r, thetaMaxR(theta))
+	slog.Info("Starting FV solver", "cells", cells, "nt", nt, "dx", dx, "dt", dt, "r", r, "theta", theta)
+
+	ws := pool64.get(4*cells + 2)
+	defer pool64.put(ws)
+	cur, next := ws[:cells:cells], ws[cells:2*cells:2*cells]
+	flux, fluxNext := ws[2*cells:3*cells+1:3*cells+1], ws[3*cells+1:]
+	copy(cur, c0)
+	if err := out(0, cur); err != nil {
+		return FluxBalance{}, err
+	}
+	initial := fvHeat(cur, dx)
+	conductance := alpha / dx
+	fvFluxes(cur, flux, conductance)
+
+	var tri tridiagSolver
+	a, b, c, d, tws := workspace(cells)
+	defer pool64.put(tws)
+	if theta > 0 {
+		fvAssemble(a, b, c, theta*r)
+		tri = newTridiag(a, b, c)
+		defer tri.close()
+	}
+
+	var inflow float64
+	explicit := (1 - theta) * dt / dx
+	for n := 0; n < nt; n++ {
+		for j := range d {
+			d[j] = cur[j] - explicit*(flux[j+1]-flux[j])
+		}
+		if tri == nil {
+			copy(next, d)
+		} else {
+			tri.solve(d, next)
+			if err := tridiagErr(tri); err != nil {
+				return FluxBalance{}, err
+			}
+		}
+		fvFluxes(next, fluxNext, conductance)
+		inflow += dt * ((1-theta)*(flux[0]-flux[cells]) + theta*(fluxNext[0]-fluxNext[cells]))
+
+		cur, next = next, cur
+		flux, fluxNext = fluxNext, flux
+		if err := out(n+1, cur); err != nil {
+			return FluxBalance{}, err
+		}
+	}
+
+	balance := newFluxBalance(initial, fvHeat(cur, dx), inflow, 0)
+	slog.Info("FV solver finished successfully", "heat_change", balance.Final-balance.Initial,
+		"boundary_inflow", balance.Inflow, "relative_imbalance", balance.Relative)
+	return balance, nil
+}
+
+// Потоки через nx+1 граней, F_j — через левую грань ячейки j;
+// conductance = α/dx. На краях разность с фиктивной ячейкой −c
+func fvFluxes(c, flux []float64, conductance float64) {
+	cells := len(c)
+	flux[0] = -conductance * 2 * c[0]
+	for j := 1; j < cells; j++ {
+		flux[j] = -conductance * (c[j] - c[j-1])
+	}
+	flux[cells] = conductance * 2 * c[cells-1]
+}
+
+// Матрица неявной части по граням: I плюс вклад каждой грани с весом
+// w = θr в две соседние ячейки, у граничной грани — 2w в одну (половина
+// шага до фиктивной ячейки). Внутри строки (−w, 1+2w, −w), на краях
+// диагональ 1+3w
+func fvAssemble(a, b, c []float64, w float64) {
+	cells := len(b)
+	for j := range b {
+		a[j], b[j], c[j] = 0, 1, 0
+	}
+	b[0] += 2 * w
+	b[cells-1] += 2 * w
+	for j := 1; j < cells; j++ {
+		b[j-1] += w
+		b[j] += w
+		c[j-1] -= w
+		a[j] -= w
+	}
+}
+
+// Теплосодержание по ячейкам: dx·Σc
+func fvHeat(c []float64, dx float64) float64 {
+	var q float64
+	for _, v := range c {
+		q += v
+	}
+	return q * dx
+}
+
+// Баланс SolveFV на sin(πx): изменение теплосодержания и приток через
+// граничные грани совпадают до округления
+func fvConservationCase(theta, dx, dt, tmax, bound float64) VerifyCase {
+	return VerifyCase{
+		Name:     fmt.Sprintf("conservation dx=%g dt=%g", dx, dt),
+		Quantity: "relative imbalance",
+		Compare:  VerifyAtMost,
+		Expected: bound,
+		Measure: func(ctx context.Context, m Method) (float64, string, error) {
+			nx, nt := int(math.Round(1/dx)), int(math.Round(tmax/dt))
+			_, f := SolveFV(nx, nt, dx, dt, 1, theta)
+			return f.Relative, fmt.Sprintf("ΔQ %.6g, inflow %.6g", f.Final-f.Initial, f.Inflow), nil
+		},
+	}
+}
+
+// Метод конечных объёмов для реестра под именем FV с θ от 0 до 1, как
+// ThetaMethod. Регистрирует тот, кому он нужен (CLI с -method FV)
+func FVMethod(theta float64) (Method, error) {
+	if !(theta >= 0 && theta <= 1) {
+		return Method{}, fmt.Errorf("theta must be between 0 and 1, got %g", theta)
+	}
+	m := Method{
+		Name:         "FV",
+		Description:  fmt.Sprintf("Cell-centered finite volumes with face fluxes, θ-scheme in time with θ = %g", theta),
+		Explicit:     theta == 0,
+		MaxR:         thetaMaxR(theta),
+		OrderTime:    1,
+		OrderSpace:   2,
+		CellCentered: true,
+		Solve: func(u0 []float64, nt int, dx, dt float64) [][]float64 {
+			return SolveFVFrom(u0, nt, dx, dt, 1, theta)
+		},
+		Stream: func(u0 []float64, nt int, dx, dt float64, emit EmitFunc) ([]float64, error) {
+			return StreamFVFrom(u0, nt, dx, dt, 1, theta, emit)
+		},
+		// Внутри области шаблон тот же, что у θ-схемы
+		Amplification: WeightedAmplification(theta),
+		Verification: []VerifyCase{
+			SineOrderCase(0.05, 0.00025, 0.1, 2, 0.1),
+			SteadyStateCase(0.05, 0.00025, 2, 1e-8),
+			fvConservationCase(theta, 0.05, 0.00025, 0.1, 1e-12),
+		},
+		solveInto: func(u0 []float64, nt int, dx, dt float64, u [][]float64) error {
+			return fvNodal(u0, nt, dx, dt, 1, theta, u, nil)
+		},
+	}
+	if theta > 0 {
+		// Ячеек на одну больше, чем внутренних узлов
+		m.System = func(n int, r float64) (a, b, c []float64) {
+			a, b, c = make([]float64, n+1), make([]float64, n+1), make([]float64, n+1)
+			fvAssemble(a, b, c, theta*r)
+			return a, b, c
+		}
+	}
+	if theta == 0.5 {
+		m.OrderTime = 2
+	}
+	if m.MaxR > 0 {
+		m.StabilityBound = fmt.Sprintf("r = dt/dx² ≤ %g", m.MaxR)
+	}
+	return m, nil
+}
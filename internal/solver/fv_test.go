@@ -0,0 +1,91 @@
+package solver
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"testing"
+
+	"heat-solver/internal/mathutils"
+)
+
+func TestFVFluxes(t *testing.T) {
+	c := []float64{1, 3, 2}
+	flux := make([]float64, 4)
+	fvFluxes(c, flux, 2)
+	// F_0 = −2·2·1, F_1 = −2·(3−1), F_2 = −2·(2−3), F_3 = 2·2·2
+	if want := []float64{-4, -4, 2, 8}; !slices.Equal(flux, want) {
+		t.Errorf("fluxes %v, want %v", flux, want)
+	}
+}
+
+// Сборка по граням даёт (−w, 1+2w, −w) внутри и 1+3w на краях; у
+// единственной ячейки обе грани граничные — 1+4w
+func TestFVAssemble(t *testing.T) {
+	tests := []struct {
+		cells   int
+		w       float64
+		a, b, c []float64
+	}{
+		{1, 0.5, []float64{0}, []float64{3}, []float64{0}},
+		{2, 1, []float64{0, -1}, []float64{4, 4}, []float64{-1, 0}},
+		{4, 0.25, []float64{0, -0.25, -0.25, -0.25}, []float64{1.75, 1.5, 1.5, 1.75}, []float64{-0.25, -0.25, -0.25, 0}},
+	}
+	for _, tc := range tests {
+		t.Run(fmt.Sprintf("cells=%d", tc.cells), func(t *testing.T) {
+			a, b, c := make([]float64, tc.cells), make([]float64, tc.cells), make([]float64, tc.cells)
+			fvAssemble(a, b, c, tc.w)
+			if !slices.Equal(a, tc.a) || !slices.Equal(b, tc.b) || !slices.Equal(c, tc.c) {
+				t.Errorf("matrix (%v, %v, %v), want (%v, %v, %v)", a, b, c, tc.a, tc.b, tc.c)
+			}
+		})
+	}
+}
+
+// Изменение теплосодержания равно притоку через граничные грани до
+// округления при любых θ и устойчивых r
+func TestFVConservation(t *testing.T) {
+	const nx = 40
+	dx := 1.0 / nx
+	for _, theta := range []float64{0, 0.5, 1} {
+		for _, r := range []float64{0.25, 5, 25} {
+			if maxR := thetaMaxR(theta); maxR > 0 && r > maxR {
+				continue
+			}
+			t.Run(fmt.Sprintf("theta=%g/r=%g", theta, r), func(t *testing.T) {
+				dt := r * dx * dx
+				nt := int(math.Ceil(0.2 / dt))
+				c, b := SolveFV(nx, nt, dx, dt, 1, theta)
+				if b.Initial != fvHeat(c[0], dx) || b.Final != fvHeat(c[nt], dx) {
+					t.Fatalf("balance Q %v → %v, cells give %v → %v", b.Initial, b.Final, fvHeat(c[0], dx), fvHeat(c[nt], dx))
+				}
+				// Точный интеграл sin(πx) — 2/π
+				if math.Abs(b.Initial-2/math.Pi) > 1e-15 || b.Inflow >= 0 || b.Relative > 1e-13 {
+					t.Errorf("balance %+v, want Q0 = 2/π and inflow matching ΔQ to round-off", b)
+				}
+			})
+		}
+	}
+}
+
+// Узловой выход второго порядка по dx при CN
+func TestFVNodalOrder(t *testing.T) {
+	const tmax, dt = 0.1, 1e-4
+	nt := int(math.Round(tmax / dt))
+	exact := mathutils.Analytical{Alpha: 1}
+	var prev float64
+	for k, nx := range []int{10, 20, 40, 80} {
+		dx := 1.0 / float64(nx)
+		u := SolveFVFrom(SineProfile(nx, dx), nt, dx, dt, 1, 0.5)
+		if len(u) != nt+1 || len(u[nt]) != nx+1 || u[nt][0] != 0 || u[nt][nx] != 0 {
+			t.Fatalf("nx=%d: %d levels of %d nodes with edges %v, %v", nx, len(u), len(u[nt]), u[nt][0], u[nt][nx])
+		}
+		l2, _ := mathutils.LevelErrors(u[nt], dx, tmax, exact)
+		if k > 0 {
+			if order := math.Log2(prev / l2); math.Abs(order-2) > 0.15 {
+				t.Errorf("nx=%d: error %.3g → %.3g, observed order %.2f", nx, prev, l2, order)
+			}
+		}
+		prev = l2
+	}
+}
@@ -0,0 +1,47 @@
+package solver
+
+// Решение (rows×cols) в одном непрерывном массиве. Строки — срезы общего
+// буфера с ограниченной ёмкостью, поэтому потребители [][]float64 работают
+// как прежде, а на хранение уходят два выделения памяти независимо от nt
+func newGrid(rows, cols int) [][]float64 {
+	return gridRows(make([]float64, rows*cols), rows, cols)
+}
+
+// То же для истории во float32
+func newGrid32(rows, cols int) [][]float32 {
+	return gridRows(make([]float32, rows*cols), rows, cols)
+}
+
+// Строки сетки поверх буфера flat (len ≥ rows·cols)
+func gridRows[T float32 | float64](flat []T, rows, cols int) [][]T {
+	u := make([][]T, rows)
+	for n := range u {
+		u[n] = flat[n*cols : (n+1)*cols : (n+1)*cols]
+	}
+	return u
+}
+
+// Слой n в хранилище схемы: при полном хранении len(u) = nt+1 и это u[n],
+// в режиме final-only два буфера используются по очереди
+func level(u [][]float64, n int) []float64 {
+	return u[n%len(u)]
+}
+
+// Приём очередного слоя n при потоковом расчёте. Срез row переиспользуется
+// на следующих шагах: сохранять его нужно копией. Ошибка прерывает расчёт
+type EmitFunc func(n int, row []float64) error
+
+// Начальный слой с нулевыми граничными значениями
+func start(u [][]float64, u0 []float64, emit EmitFunc) error {
+	row := level(u, 0)
+	copy(row, u0)
+	row[0], row[len(row)-1] = 0, 0
+	return emitLevel(emit, 0, row)
+}
+
+func emitLevel(emit EmitFunc, n int, row []float64) error {
+	if emit == nil {
+		return nil
+	}
+	return emit(n, row)
+}
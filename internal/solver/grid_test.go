@@ -0,0 +1,115 @@
+package solver
+
+import (
+	"runtime/debug"
+	"testing"
+
+	"heat-solver/internal/mathutils"
+)
+
+// Строки сетки — отрезки одного буфера подряд, и append к строке не
+// залезает в следующую
+func TestGridRows(t *testing.T) {
+	const rows, cols = 5, 7
+	flat := make([]float64, rows*cols)
+	for i := range flat {
+		flat[i] = float64(i)
+	}
+	u := gridRows(flat, rows, cols)
+	for n, row := range u {
+		if len(row) != cols || cap(row) != cols {
+			t.Fatalf("row %d: len %d cap %d, want %d", n, len(row), cap(row), cols)
+		}
+		for i, v := range row {
+			if v != float64(n*cols+i) {
+				t.Fatalf("u[%d][%d] = %g, want flat[%d]", n, i, v, n*cols+i)
+			}
+		}
+	}
+	_ = append(u[0], -1)
+	if u[1][0] != cols {
+		t.Error("append to a row overwrote the next one")
+	}
+}
+
+// CN по отдельным строкам и прогонке без разложения, как до общего буфера
+func crankNicolsonReference(u0 []float64, nt int, dx, dt float64) [][]float64 {
+	nx := len(u0) - 1
+	r := dt / (dx * dx)
+	u := make([][]float64, nt+1)
+	u[0] = append([]float64(nil), u0...)
+	u[0][0], u[0][nx] = 0, 0
+	a, b, c := make([]float64, nx-1), make([]float64, nx-1), make([]float64, nx-1)
+	for i := range b {
+		a[i], b[i], c[i] = -r/2, 1+r, -r/2
+	}
+	for n := 0; n < nt; n++ {
+		d := make([]float64, nx-1)
+		thetaRHS(u[n], d, r/2)
+		x := thomasReference(a, b, c, d)
+		u[n+1] = make([]float64, nx+1)
+		copy(u[n+1][1:nx], x)
+	}
+	return u
+}
+
+// Общий буфер не меняет решение: побитно то же, что построчное хранение
+func TestFlatStorageBitIdentical(t *testing.T) {
+	const nx, nt = 40, 60
+	dx, dt := 1.0/nx, 0.002
+	got := SolveCrankNicolson(nx, nt, dx, dt)
+	want := crankNicolsonReference(SineProfile(nx, dx), nt, dx, dt)
+	for n := range want {
+		for i := range want[n] {
+			if got[n][i] != want[n][i] {
+				t.Fatalf("u[%d][%d] = %v, want %v", n, i, got[n][i], want[n][i])
+			}
+		}
+	}
+}
+
+// Число выделений памяти на расчёт не зависит от nt: история — один буфер
+// и срезы строк, рабочие массивы шага — из пула. Сборка мусора на время
+// замера выключена: её собственные выделения зависят от размера сетки
+func TestSolveAllocsIndependentOfNt(t *testing.T) {
+	defer debug.SetGCPercent(debug.SetGCPercent(-1))
+	const nx = 50
+	dx := 1.0 / nx
+	u0 := SineProfile(nx, dx)
+	solvers := map[string]func(u0 []float64, nt int, dx, dt float64) [][]float64{
+		"FTCS": SolveFTCSFrom,
+		"BTCS": SolveBTCSFrom,
+		"CN":   SolveCrankNicolsonFrom,
+	}
+	for name, solve := range solvers {
+		t.Run(name, func(t *testing.T) {
+			allocs := func(nt int) float64 {
+				return testing.AllocsPerRun(5, func() { solve(u0, nt, dx, 0.0001) })
+			}
+			short, long := allocs(1000), allocs(50000)
+			if long != short {
+				t.Errorf("%v allocations for nt=1000, %v for nt=50000; want the same", short, long)
+			}
+		})
+	}
+}
+
+func BenchmarkSolveCN(b *testing.B) {
+	const nx, nt = 1000, 1000
+	dx := 1.0 / nx
+	u0 := SineProfile(nx, dx)
+	b.ReportAllocs()
+	for b.Loop() {
+		SolveCrankNicolsonFrom(u0, nt, dx, 1e-5)
+	}
+}
+
+func BenchmarkComputeErrors(b *testing.B) {
+	const nx, nt = 1000, 1000
+	dx := 1.0 / nx
+	u := SolveCrankNicolson(nx, nt, dx, 1e-5)
+	b.ReportAllocs()
+	for b.Loop() {
+		mathutils.ComputeErrors(u, dx, 1e-5)
+	}
+}
@@ -0,0 +1,99 @@
+package solver
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+
+	"heat-solver/internal/mathutils"
+)
+
+// Шаг «классики» в исходной записи: на переходе к слою m узлы с чётным
+// i+m — FTCS, остальные — неявная формула с соседями уже с нового слоя
+func hopscotchReference(u0 []float64, nt int, dx, dt float64) [][]float64 {
+	nx := len(u0) - 1
+	r := dt / (dx * dx)
+	u := [][]float64{append([]float64(nil), u0...)}
+	u[0][0], u[0][nx] = 0, 0
+	for m := 1; m <= nt; m++ {
+		cur, next := u[m-1], make([]float64, nx+1)
+		for i := 1; i < nx; i++ {
+			if (i+m)%2 == 0 {
+				next[i] = cur[i] + r*(cur[i-1]-2*cur[i]+cur[i+1])
+			}
+		}
+		for i := 1; i < nx; i++ {
+			if (i+m)%2 != 0 {
+				next[i] = (cur[i] + r*(next[i-1]+next[i+1])) / (1 + 2*r)
+			}
+		}
+		u = append(u, next)
+	}
+	return u
+}
+
+func TestHopscotchSteps(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for _, nx := range []int{2, 3, 30, 31} {
+		dx := 1 / float64(nx)
+		u0 := make([]float64, nx+1)
+		for i := range u0 {
+			u0[i] = rng.Float64()
+		}
+		for _, r := range []float64{0.3, 1, 20} {
+			want := hopscotchReference(u0, 9, dx, r*dx*dx)
+			got := SolveHopscotchFrom(u0, 9, dx, r*dx*dx)
+			for n := range want {
+				for i := range want[n] {
+					if math.Abs(got[n][i]-want[n][i]) > 1e-12*(1+math.Abs(want[n][i])) {
+						t.Fatalf("nx=%d r=%g, level %d, node %d: %v, want %v", nx, r, n, i, got[n][i], want[n][i])
+					}
+				}
+			}
+		}
+	}
+}
+
+// При r = 1, вдвое за пределом FTCS, ошибка против sin(πx)·exp(−π²t) мала
+// и падает вчетверо при измельчении dx с тем же r
+func TestHopscotchAccuracy(t *testing.T) {
+	const r, tmax = 1.0, 0.1
+	var prev float64
+	for k, nx := range []int{20, 40, 80, 160} {
+		dx := 1 / float64(nx)
+		dt := r * dx * dx
+		nt := int(math.Round(tmax / dt))
+		last, err := StreamHopscotchFrom(SineProfile(nx, dx), nt, dx, dt, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		l2, _ := mathutils.LevelErrors(last, dx, float64(nt)*dt, mathutils.Analytical{Alpha: 1})
+		if nx == 20 && l2 > 7e-3 {
+			t.Errorf("nx=20: error %.3g", l2)
+		}
+		if k > 0 {
+			if order := math.Log2(prev / l2); math.Abs(order-2) > 0.1 {
+				t.Errorf("nx=%d: error %.3g → %.3g, observed order %.2f", nx, prev, l2, order)
+			}
+		}
+		prev = l2
+	}
+}
+
+// Шаг без прогонки против шага BTCS на большой сетке
+func BenchmarkHopscotch(b *testing.B) {
+	const nt = 20
+	for _, nx := range []int{10000, 1000000} {
+		dx := 1 / float64(nx)
+		u0 := SineProfile(nx, dx)
+		for _, method := range []string{"HOPSCOTCH", "BTCS"} {
+			m, _ := Lookup(method)
+			b.Run(fmt.Sprintf("%s/nx=%d", method, nx), func(b *testing.B) {
+				for b.Loop() {
+					m.Stream(u0, nt, dx, dx*dx, nil)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,219 @@
+package solver
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+
+	"heat-solver/internal/mathutils"
+)
+
+// Составная стенка из слоёв (mathutils.Slab): c(x)·u_t = (k(x)·u_x)_x на
+// [0, L] с кусочно-постоянными k и c и значениями u(0), u(L) на краях.
+// На границе слоёв непрерывны температура и поток k·u_x.
+//
+// Схема консервативная: узлы x_i = i·dx, ячейка узла —
+// [x_i − dx/2, x_i + dx/2], и
+//
+//	C_i·du_i/dt = [k_{i+½}·(u_{i+1} − u_i) − k_{i−½}·(u_i − u_{i−1})] / dx²,
+//
+// C_i — средняя по ячейке теплоёмкость, k_{i+½} = dx / ∫ dx/k по
+// [x_i, x_{i+1}] — среднее гармоническое k по отрезку между узлами. Это
+// последовательное соединение сопротивлений, и в стационаре поток между
+// соседними узлами точен, где бы ни лежали границы слоёв: узловые значения
+// совпадают с кусочно-линейным профилем до округления. Матрица остаётся
+// трёхдиагональной и постоянной и раскладывается один раз; шаг
+// θ-взвешенный, как SolveTheta
+
+// Начальный слой составной стенки: left и right на краях, 0 внутри
+// (внезапный нагрев)
+func LayeredStart(nx int, left, right float64) []float64 {
+	u0 := make([]float64, nx+1)
+	u0[0], u0[nx] = left, right
+	return u0
+}
+
+// Составная стенка с заданным профилем u0 (nx+1 значений на [0, L],
+// dx = L/nx; u0[0] и u0[nx] — значения на краях); хранятся два слоя,
+// каждый слой передаётся в emit
+func StreamLayeredFrom(u0 []float64, nt int, dx, dt, theta float64, slab mathutils.Slab, emit EmitFunc) ([]float64, error) {
+	u := newGrid(2, len(u0))
+	if err := layered(u0, nt, dx, dt, theta, slab, u, emit); err != nil {
+		return nil, err
+	}
+	return level(u, nt), nil
+}
+
+// Коэффициенты строк i = 1..nx−1: dt/dx²·k_{i∓½}/C_i. Их полусумма
+// играет роль r однородной схемы, и по наибольшей из них проверяется
+// устойчивость при θ < ½
+func layeredWeights(nx int, dx, dt float64, slab mathutils.Slab) (lo, hi []float64, r float64) {
+	lo, hi = make([]float64, nx+1), make([]float64, nx+1)
+	face := func(i int) float64 {
+		return dx / slab.Resistance(float64(i)*dx, float64(i+1)*dx)
+	}
+	kLeft := face(0)
+	for i := 1; i < nx; i++ {
+		x := float64(i) * dx
+		c := slab.Capacity(x-dx/2, x+dx/2) / dx
+		kRight := face(i)
+		lo[i], hi[i] = dt/(dx*dx)*kLeft/c, dt/(dx*dx)*kRight/c
+		r = math.Max(r, (lo[i]+hi[i])/2)
+		kLeft = kRight
+	}
+	return lo, hi, r
+}
+
+func layered(u0 []float64, nt int, dx, dt, theta float64, slab mathutils.Slab, u [][]float64, emit EmitFunc) error {
+	nx := len(u0) - 1
+	if nx < 2 {
+		return fmt.Errorf("layered slab needs at least 2 intervals, got %d", nx)
+	}
+	lo, hi, r := layeredWeights(nx, dx, dt, slab)
+	name := "layered " + thetaName(theta)
+	warnUnstable(name, r, thetaMaxR(theta))
+	slog.Info("Starting "+name+" solver", "layers", len(slab), "nx", nx, "nt", nt, "dx", dx, "dt", dt, "max_r", r)
+
+	row := level(u, 0)
+	copy(row, u0)
+	if err := emitLevel(emit, 0, row); err != nil {
+		return err
+	}
+
+	n := nx - 1
+	a, b, c, d, ws := workspace(n)
+	defer pool64.put(ws)
+	for i := 1; i < nx; i++ {
+		a[i-1] = -theta * lo[i]
+		b[i-1] = 1 + theta*(lo[i]+hi[i])
+		c[i-1] = -theta * hi[i]
+	}
+	tri := newThomas(n)
+	defer tri.close()
+	tri.factor(a, b, c)
+
+	for step := 0; step < nt; step++ {
+		cur, next := level(u, step), level(u, step+1)
+		next[0], next[nx] = cur[0], cur[nx]
+		for i := 1; i < nx; i++ {
+			d[i-1] = cur[i] + (1-theta)*(hi[i]*(cur[i+1]-cur[i])-lo[i]*(cur[i]-cur[i-1]))
+		}
+		d[0] += theta * lo[1] * next[0]
+		d[n-1] += theta * hi[nx-1] * next[nx]
+		tri.solve(d, next[1:nx])
+		if err := emitLevel(emit, step+1, next); err != nil {
+			return err
+		}
+	}
+
+	slog.Info(name + " solver finished successfully")
+	return nil
+}
+
+// Температуры на границах слоёв: u(0), u(x_1), …, u(L). Граница внутри
+// отрезка [x_i, x_{i+1}] получает значение из потока между узлами: поток
+// на отрезке постоянен, и u падает пропорционально сопротивлению от x_i.
+// В стационаре это точное значение
+func InterfaceTemperatures(u []float64, dx float64, slab mathutils.Slab) []float64 {
+	nx := len(u) - 1
+	xs := slab.Interfaces()
+	temps := make([]float64, len(xs))
+	for j, x := range xs {
+		s := x / dx
+		i := int(math.Floor(s))
+		switch {
+		case math.Abs(s-math.Round(s)) < 1e-9:
+			temps[j] = u[min(int(math.Round(s)), nx)]
+		case i >= nx:
+			temps[j] = u[nx]
+		default:
+			xi := float64(i) * dx
+			share := slab.Resistance(xi, x) / slab.Resistance(xi, xi+dx)
+			temps[j] = u[i] - share*(u[i]-u[i+1])
+		}
+	}
+	return temps
+}
+
+// Перепады температуры на слоях u(начало) − u(конец) по
+// InterfaceTemperatures
+func LayerDrops(u []float64, dx float64, slab mathutils.Slab) []float64 {
+	temps := InterfaceTemperatures(u, dx, slab)
+	drops := make([]float64, len(slab))
+	for j := range drops {
+		drops[j] = temps[j] - temps[j+1]
+	}
+	return drops
+}
+
+// Проверочные случаи составной стенки; в Verify — под именем LAYERS
+var verificationLayers = []VerifyCase{
+	layerDropsCase(20, 1e-9),
+	layerDropsCase(14, 1e-9),
+	layerOrderCase(20, 0.002, 0.1, 2, 0.15),
+}
+
+// Стенка из -layers "0.3:1.0,0.4:0.1,0.3:1.0": сопротивления 0.3, 4 и 0.3,
+// и на среднем слое падает 4/4.6 ≈ 87% перепада
+var verifySlab = mathutils.Slab{{Thickness: 0.3, Conductivity: 1}, {Thickness: 0.4, Conductivity: 0.1}, {Thickness: 0.3, Conductivity: 1}}
+
+// Перепады на слоях стенки verifySlab в стационаре (BTCS с крупным шагом
+// до t = 100, u(0) = 1, u(L) = 0) против последовательных сопротивлений:
+// наибольшее |ΔT_j − q·R_j| не больше tol. При nx = 14 границы слоёв
+// лежат внутри ячеек (0.3·14 = 4.2), и значения на них восстанавливаются
+// по потоку
+func layerDropsCase(nx int, tol float64) VerifyCase {
+	return VerifyCase{
+		Name:     fmt.Sprintf("series resistance drops nx=%d", nx),
+		Quantity: "max |ΔT − ΔT series|",
+		Compare:  VerifyAtMost,
+		Expected: tol,
+		Measure: func(ctx context.Context, _ Method) (float64, string, error) {
+			dx, dt := verifySlab.Length()/float64(nx), 0.5
+			nt := int(math.Round(100 / dt))
+			last, err := StreamLayeredFrom(LayeredStart(nx, 1, 0), nt, dx, dt, 1, verifySlab, nil)
+			if err != nil {
+				return math.NaN(), "", err
+			}
+			drops, series := LayerDrops(last, dx, verifySlab), verifySlab.SeriesDrops(1, 0)
+			var worst float64
+			for j := range drops {
+				worst = math.Max(worst, math.Abs(drops[j]-series[j]))
+			}
+			return worst, fmt.Sprintf("drops %.6f, series %.6f", drops, series), nil
+		},
+	}
+}
+
+// Наблюдаемый порядок по dx переходного решения на стенке verifySlab
+// (узлы на границах слоёв, BTCS): dx делится пополам, dt — вчетверо, и
+// разности решений соседних сеток в общих узлах сравниваются по L∞
+func layerOrderCase(nx int, dt, tmax, order, tol float64) VerifyCase {
+	return VerifyCase{
+		Name:      fmt.Sprintf("layered self-convergence order from nx=%d dt=%g", nx, dt),
+		Quantity:  "observed order",
+		Compare:   VerifyNear,
+		Expected:  order,
+		Tolerance: tol,
+		Measure: func(ctx context.Context, _ Method) (float64, string, error) {
+			var levels [3][]float64
+			for k := range levels {
+				n, tau := nx<<k, dt/math.Pow(4, float64(k))
+				var err error
+				levels[k], err = StreamLayeredFrom(LayeredStart(n, 1, 0), int(math.Round(tmax/tau)), verifySlab.Length()/float64(n), tau, 1, verifySlab, nil)
+				if err != nil {
+					return math.NaN(), "", err
+				}
+			}
+			var diffs [2]float64
+			for k := range diffs {
+				for i := 0; i <= nx; i++ {
+					diffs[k] = math.Max(diffs[k], math.Abs(levels[k][i<<k]-levels[k+1][i<<(k+1)]))
+				}
+			}
+			observed := math.Log2(diffs[0] / diffs[1])
+			return observed, fmt.Sprintf("differences %.3g, %.3g", diffs[0], diffs[1]), nil
+		},
+	}
+}
@@ -0,0 +1,127 @@
+package solver
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"heat-solver/internal/mathutils"
+)
+
+// Стационар стенки verifySlab: перепады на слоях совпадают с
+// последовательными сопротивлениями до округления и при границах в
+// узлах (nx = 20), и внутри ячеек (nx = 14, 33); узлы лежат на
+// кусочно-линейном профиле
+func TestLayeredSteadyDrops(t *testing.T) {
+	slabs := []struct {
+		name string
+		slab mathutils.Slab
+	}{
+		{"verify slab", verifySlab},
+		{"capacities", mathutils.Slab{{Thickness: 0.5, Conductivity: 2, Capacity: 3}, {Thickness: 0.25, Conductivity: 0.05, Capacity: 0.5}, {Thickness: 0.25, Conductivity: 1}}},
+	}
+	for _, s := range slabs {
+		for _, nx := range []int{20, 14, 33} {
+			t.Run(fmt.Sprintf("%s/nx=%d", s.name, nx), func(t *testing.T) {
+				dx := s.slab.Length() / float64(nx)
+				last, err := StreamLayeredFrom(LayeredStart(nx, 1, 0), 400, dx, 0.5, 1, s.slab, nil)
+				if err != nil {
+					t.Fatal(err)
+				}
+				drops, series := LayerDrops(last, dx, s.slab), s.slab.SeriesDrops(1, 0)
+				for j := range drops {
+					if math.Abs(drops[j]-series[j]) > 1e-9 {
+						t.Errorf("drops %v, series %v", drops, series)
+						break
+					}
+				}
+				for i, v := range last {
+					if want := s.slab.Steady(float64(i)*dx, 1, 0); math.Abs(v-want) > 1e-9 {
+						t.Errorf("u[%d] = %v, steady %v", i, v, want)
+					}
+				}
+			})
+		}
+	}
+}
+
+// Один слой с k = c = 1 — однородная схема: BTCS и CN совпадают с
+// эталонными шагами, FTCS при θ = 0 — тоже
+func TestLayeredUniform(t *testing.T) {
+	slab := mathutils.Slab{{Thickness: 1, Conductivity: 1}}
+	const nx, nt, dx = 20, 30, 0.05
+	tests := []struct {
+		method string
+		theta  float64
+		dt     float64
+	}{
+		{"FTCS", 0, 0.001},
+		{"BTCS", 1, 0.01},
+		{"CN", 0.5, 0.01},
+	}
+	for _, tc := range tests {
+		t.Run(tc.method, func(t *testing.T) {
+			u0 := SineProfile(nx, dx)
+			want := stencilReference(tc.method, u0, nt, dx, tc.dt)
+			var worst float64
+			_, err := StreamLayeredFrom(u0, nt, dx, tc.dt, tc.theta, slab, func(n int, row []float64) error {
+				for i, v := range row {
+					worst = math.Max(worst, math.Abs(v-want[n][i]))
+				}
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if worst > 1e-13 {
+				t.Errorf("differs from the homogeneous scheme by %.3g", worst)
+			}
+		})
+	}
+}
+
+// Слои одного материала, поделённые по-разному, дают то же решение, что
+// и один слой; важна только температуропроводность k/c
+func TestLayeredEquivalent(t *testing.T) {
+	const nx, nt, dx, dt = 40, 50, 0.025, 0.002
+	u0 := SineProfile(nx, dx)
+	whole, err := StreamLayeredFrom(u0, nt, dx, dt, 1, mathutils.Slab{{Thickness: 1, Conductivity: 2}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	split, err := StreamLayeredFrom(u0, nt, dx, dt, 1, mathutils.Slab{{Thickness: 0.33, Conductivity: 2}, {Thickness: 0.67, Conductivity: 2}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	capacity, err := StreamLayeredFrom(u0, nt, dx, dt, 1, mathutils.Slab{{Thickness: 1, Conductivity: 4, Capacity: 2}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range whole {
+		if math.Abs(split[i]-whole[i]) > 1e-14 || math.Abs(capacity[i]-whole[i]) > 1e-14 {
+			t.Fatalf("u[%d]: split %v, k = 4 with c = 2 %v, whole %v", i, split[i], capacity[i], whole[i])
+		}
+	}
+}
+
+// Температура на границе внутри ячейки восстанавливается по потоку
+func TestInterfaceTemperatures(t *testing.T) {
+	const nx = 14
+	dx := verifySlab.Length() / nx
+	u := make([]float64, nx+1)
+	for i := range u {
+		u[i] = verifySlab.Steady(float64(i)*dx, 2, -1)
+	}
+	temps := InterfaceTemperatures(u, dx, verifySlab)
+	for j, x := range verifySlab.Interfaces() {
+		if want := verifySlab.Steady(x, 2, -1); math.Abs(temps[j]-want) > 1e-14 {
+			t.Errorf("interface %d at x = %v: %v, want %v", j, x, temps[j], want)
+		}
+	}
+}
+
+func TestLayeredRejects(t *testing.T) {
+	if _, err := StreamLayeredFrom([]float64{1, 0}, 1, 1, 0.1, 1, verifySlab, nil); err == nil {
+		t.Error("one interval accepted")
+	}
+}
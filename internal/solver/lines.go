@@ -0,0 +1,163 @@
+package solver
+
+// Ширина блока столбцов в прогонках по y и сторона плитки 2D FTCS
+const lineTile = 64
+
+// Неявные прогонки по линиям двумерной сетки (ADI, LOD): на строке
+// решается (−h_x, 1+2h_x, −h_x), на столбце — (−h_y, 1+2h_y, −h_y), как у
+// BTCS с r = h. Матрицы постоянны, поэтому прогонка (thomas) по каждому
+// направлению раскладывается один раз на расчёт, а на линиях выполняется
+// только её обратный ход. Строки делятся между потоками пула rows,
+// блоки по lineTile столбцов — пула blocks; фрагменты пишут в
+// непересекающиеся узлы, поэтому результат не зависит от числа потоков
+type lineSolver struct {
+	nx, ny       int
+	hx, hy       float64
+	tx, ty       *thomas
+	rows, blocks *chunkPool
+	scratch      []lineScratch
+	ws           []float64
+
+	// Слои и явная часть текущей прогонки для фрагментов пула
+	src, dst    []float64
+	explicit    float64
+	sweepRows   func(k, lo, hi int)
+	sweepBlocks func(k, lo, hi int)
+}
+
+// Буферы фрагмента: правая часть строки d, блок правых частей столбцов
+// tr и их решений out — столбец за столбцом, каждый подряд в памяти
+type lineScratch struct {
+	d, tr, out []float64
+}
+
+func newLineSolver(nx, ny int, hx, hy float64) *lineSolver {
+	s := &lineSolver{nx: nx, ny: ny, hx: hx, hy: hy}
+	s.rows = newChunkPool(1, ny, max(1, ftcsMinChunk/(nx+1)))
+	s.blocks = newChunkPool(0, lineBlocks(nx), max(1, ftcsMinChunk/(lineTile*(ny+1))))
+	chunks := 1
+	for _, p := range []*chunkPool{s.rows, s.blocks} {
+		if p != nil {
+			chunks = max(chunks, p.size())
+		}
+	}
+
+	per := nx - 1 + 2*lineTile*(ny-1)
+	ws := pool64.get(3*(nx-1) + 3*(ny-1) + chunks*per)
+	s.ws = ws
+	sys := gridRows(ws, 3, nx-1)
+	s.tx = lineThomas(sys[0], sys[1], sys[2], hx)
+	rest := ws[3*(nx-1):]
+	sys = gridRows(rest, 3, ny-1)
+	s.ty = lineThomas(sys[0], sys[1], sys[2], hy)
+	rest = rest[3*(ny-1):]
+	s.scratch = make([]lineScratch, chunks)
+	for k := range s.scratch {
+		buf := rest[k*per : (k+1)*per]
+		s.scratch[k] = lineScratch{
+			d:   buf[:nx-1],
+			tr:  buf[nx-1 : nx-1+lineTile*(ny-1)],
+			out: buf[nx-1+lineTile*(ny-1):],
+		}
+	}
+	s.sweepRows, s.sweepBlocks = s.rowsX, s.blocksY
+	return s
+}
+
+// Число блоков по lineTile среди внутренних столбцов 1..nx−1
+func lineBlocks(nx int) int {
+	return (nx - 2 + lineTile) / lineTile
+}
+
+func (s *lineSolver) close() {
+	if s.rows != nil {
+		s.rows.close()
+	}
+	if s.blocks != nil {
+		s.blocks.close()
+	}
+	s.tx.close()
+	s.ty.close()
+	pool64.put(s.ws)
+}
+
+// Строки j = 1..ny−1 слоя dst: правая часть — src с явной частью
+// explicit·δ_y²src, края строки берутся из dst и должны быть заданы
+func (s *lineSolver) sweepX(src, dst []float64, explicit float64) {
+	s.src, s.dst, s.explicit = src, dst, explicit
+	if s.rows != nil {
+		s.rows.run(s.sweepRows)
+	} else {
+		s.rowsX(0, 1, s.ny)
+	}
+}
+
+// Столбцы i = 1..nx−1 слоя dst: правая часть — src с явной частью
+// explicit·δ_x²src, края столбца берутся из dst
+func (s *lineSolver) sweepY(src, dst []float64, explicit float64) {
+	s.src, s.dst, s.explicit = src, dst, explicit
+	if s.blocks != nil {
+		s.blocks.run(s.sweepBlocks)
+	} else {
+		s.blocksY(0, 0, lineBlocks(s.nx))
+	}
+}
+
+// Строки j из [lo, hi) с буфером фрагмента k
+func (s *lineSolver) rowsX(k, lo, hi int) {
+	nx, w := s.nx, s.nx+1
+	src, dst, explicit := s.src, s.dst, s.explicit
+	rhs := s.scratch[k].d
+	for j := lo; j < hi; j++ {
+		for i := 1; i < nx; i++ {
+			c := j*w + i
+			rhs[i-1] = src[c] + explicit*(src[c-w]-2*src[c]+src[c+w])
+		}
+		rhs[0] += s.hx * dst[j*w]
+		rhs[nx-2] += s.hx * dst[j*w+nx]
+		s.tx.solve(rhs, dst[j*w+1:j*w+nx])
+	}
+}
+
+// Блоки столбцов из [lo, hi) с буферами фрагмента k. Правые части блока
+// собираются проходом по строкам в транспонированный буфер tr, прогонки
+// идут по непрерывным столбцам tr, и решения возвращаются в dst снова по
+// строкам: слой читается и пишется подряд, а не с шагом nx+1
+func (s *lineSolver) blocksY(k, lo, hi int) {
+	nx, ny, w, m := s.nx, s.ny, s.nx+1, s.ny-1
+	src, dst, explicit := s.src, s.dst, s.explicit
+	tr, out := s.scratch[k].tr, s.scratch[k].out
+	for b := lo; b < hi; b++ {
+		i0 := 1 + b*lineTile
+		i1 := min(i0+lineTile, nx)
+		for j := 1; j < ny; j++ {
+			for i := i0; i < i1; i++ {
+				c := j*w + i
+				tr[(i-i0)*m+j-1] = src[c] + explicit*(src[c-1]-2*src[c]+src[c+1])
+			}
+		}
+		for i := i0; i < i1; i++ {
+			rhs := tr[(i-i0)*m : (i-i0+1)*m]
+			rhs[0] += s.hy * dst[i]
+			rhs[m-1] += s.hy * dst[ny*w+i]
+			s.ty.solve(rhs, out[(i-i0)*m:(i-i0+1)*m])
+		}
+		for j := 1; j < ny; j++ {
+			row := dst[j*w+i0 : j*w+i1]
+			for i := range row {
+				row[i] = out[i*m+j-1]
+			}
+		}
+	}
+}
+
+// Прогонка для (−h, 1+2h, −h) на линии из len(b) внутренних узлов;
+// a, b, c заполняются и должны жить, пока она используется
+func lineThomas(a, b, c []float64, h float64) *thomas {
+	for i := range b {
+		a[i], b[i], c[i] = -h, 1+2*h, -h
+	}
+	t := newThomas(len(b))
+	t.factor(a, b, c)
+	return t
+}
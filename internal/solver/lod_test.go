@@ -0,0 +1,89 @@
+package solver
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"heat-solver/internal/mathutils"
+)
+
+// sin(πx)·sin(πy) — собственный вектор обоих шагов BTCS: за шаг он
+// умножается на 1/((1+a)(1+b)), a = 4r_x·sin²(π·dx/2), b — то же по y
+func TestLODSineMode(t *testing.T) {
+	tests := []struct {
+		nx, ny int
+		dt     float64
+	}{
+		{16, 16, 0.01},
+		{12, 20, 0.05},
+		{8, 30, 0.5},
+	}
+	for _, tc := range tests {
+		t.Run(fmt.Sprintf("%dx%d/dt=%g", tc.nx, tc.ny, tc.dt), func(t *testing.T) {
+			const nt = 10
+			dx, dy := 1/float64(tc.nx), 1/float64(tc.ny)
+			sx, sy := math.Sin(math.Pi*dx/2), math.Sin(math.Pi*dy/2)
+			a, b := 4*tc.dt/(dx*dx)*sx*sx, 4*tc.dt/(dy*dy)*sy*sy
+			g := 1 / ((1 + a) * (1 + b))
+			u := Solve2DLOD(tc.nx, tc.ny, nt, dx, dy, tc.dt, 1)
+			for n := range u {
+				scale := math.Pow(g, float64(n))
+				for c, v := range u[n] {
+					if math.Abs(v-scale*u[0][c]) > 1e-14 {
+						t.Fatalf("level %d, node %d: %v, want G^n·u0 = %v", n, c, v, scale*u[0][c])
+					}
+				}
+			}
+			last, err := Stream2DLODFrom(SineProfile2D(tc.nx, tc.ny, dx, dy), tc.nx, tc.ny, nt, dx, dy, tc.dt, 1, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for c := range last {
+				if last[c] != u[nt][c] {
+					t.Fatalf("streamed node %d = %v, full run %v", c, last[c], u[nt][c])
+				}
+			}
+		})
+	}
+}
+
+// Ошибка по времени против точного решения полудискретной задачи: у LOD
+// при делении dt пополам она падает вдвое, у ADI на тех же шагах —
+// вчетверо, и уже на самом грубом шаге LOD заметно хуже
+func TestLODTimeOrder(t *testing.T) {
+	const n, tmax = 16, 0.2
+	h := 1.0 / n
+	exact := mathutils.Analytical2D{Alpha: semiDiscreteSine(h).Alpha}
+	tests := []struct {
+		name   string
+		stream Stream2DFunc
+		order  float64
+	}{
+		{"LOD", Stream2DLODFrom, 1},
+		{"ADI", Stream2DADIFrom, 2},
+	}
+	var coarse [2]float64
+	for k, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var prev float64
+			for m, dt := range []float64{0.02, 0.01, 0.005, 0.0025} {
+				nt := int(math.Round(tmax / dt))
+				last, err := tc.stream(SineProfile2D(n, n, h, h), n, n, nt, h, h, dt, 1, nil)
+				if err != nil {
+					t.Fatal(err)
+				}
+				l2, _ := mathutils.LevelErrors2D(last, n, n, h, h, float64(nt)*dt, exact)
+				if m == 0 {
+					coarse[k] = l2
+				} else if order := math.Log2(prev / l2); math.Abs(order-tc.order) > 0.1 {
+					t.Errorf("dt=%g: error %.3g → %.3g, observed order %.2f, want %g", dt, prev, l2, order, tc.order)
+				}
+				prev = l2
+			}
+		})
+	}
+	if coarse[0] < 5*coarse[1] {
+		t.Errorf("LOD error %.3g at dt=0.02 is not clearly above ADI %.3g", coarse[0], coarse[1])
+	}
+}
@@ -0,0 +1,375 @@
+package solver
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"strings"
+
+	"heat-solver/internal/mathutils"
+)
+
+// Двумерные схемы на квадрате с маской (mathutils.Mask2D): выключенные
+// узлы держат температуру маски, как края квадрата — ноль, и схема
+// обновляет только включённые. У FTCS это просто пропуск узлов. Неявные
+// прогонки ADI и LOD идут не по всей строке или столбцу, а по отрезкам
+// из подряд идущих включённых узлов: выключенный узел на конце отрезка —
+// его граничное значение, как край квадрата у lineSolver. Матрица отрезка
+// зависит только от его длины, и прогонка раскладывается один раз на
+// каждую встретившуюся длину. Промежуточный слой ADI в выключенных узлах
+// — температура маски: у постоянного граничного значения формула
+// adiHalfEdges даёт его же. Без выключенных узлов расчёт побитно совпадает
+// с Stream2DFTCSFrom, Stream2DADIFrom и Stream2DLODFrom
+
+// Двумерная схема с маской и хранением двух слоёв; сетка — mask.Nx×mask.Ny
+// на единичном квадрате, u0 и слои — как у Stream2DFunc
+type StreamMasked2DFunc func(u0 []float64, mask mathutils.Mask2D, nt int, dt, alpha float64, emit EmitFunc) ([]float64, error)
+
+// Схемы с маской в порядке перечисления
+var methodsMasked2D = []struct {
+	name   string
+	stream StreamMasked2DFunc
+}{
+	{"FTCS", Stream2DMaskedFTCSFrom},
+	{"ADI", Stream2DMaskedADIFrom},
+	{"LOD", Stream2DMaskedLODFrom},
+}
+
+// Схема с маской по имени без учёта регистра
+func LookupMasked2D(name string) (StreamMasked2DFunc, bool) {
+	for _, m := range methodsMasked2D {
+		if strings.EqualFold(m.name, name) {
+			return m.stream, true
+		}
+	}
+	return nil, false
+}
+
+// Имена схем с маской
+func MethodsMasked2D() []string {
+	names := make([]string, len(methodsMasked2D))
+	for i, m := range methodsMasked2D {
+		names[i] = m.name
+	}
+	return names
+}
+
+// Наибольшее значение во включённых внутренних узлах слоя
+func MaskedMax(row []float64, mask mathutils.Mask2D) float64 {
+	peak := math.Inf(-1)
+	w := mask.Nx + 1
+	for j := 1; j < mask.Ny; j++ {
+		for i := 1; i < mask.Nx; i++ {
+			if c := j*w + i; !mask.Masked[c] {
+				peak = math.Max(peak, row[c])
+			}
+		}
+	}
+	return peak
+}
+
+// Нулевые края квадрата и температура маски в выключенных узлах
+func applyMask(row []float64, mask mathutils.Mask2D) {
+	zeroEdges2D(row, mask.Nx, mask.Ny)
+	w := mask.Nx + 1
+	for j := 1; j < mask.Ny; j++ {
+		for i := 1; i < mask.Nx; i++ {
+			if c := j*w + i; mask.Masked[c] {
+				row[c] = mask.Value
+			}
+		}
+	}
+}
+
+// Начальный слой: u0 с краями и выключенными узлами по маске
+func maskedStart(u [][]float64, u0 []float64, mask mathutils.Mask2D, emit EmitFunc) error {
+	if len(u0) != (mask.Nx+1)*(mask.Ny+1) {
+		return fmt.Errorf("initial layer has %d values, mask grid %d×%d needs %d", len(u0), mask.Nx, mask.Ny, (mask.Nx+1)*(mask.Ny+1))
+	}
+	row := level(u, 0)
+	copy(row, u0)
+	applyMask(row, mask)
+	return emitLevel(emit, 0, row)
+}
+
+// 2D FTCS с маской
+func Stream2DMaskedFTCSFrom(u0 []float64, mask mathutils.Mask2D, nt int, dt, alpha float64, emit EmitFunc) ([]float64, error) {
+	nx, ny := mask.Nx, mask.Ny
+	dx, dy := 1/float64(nx), 1/float64(ny)
+	rx, ry := alpha*dt/(dx*dx), alpha*dt/(dy*dy)
+	if rx+ry > 0.5 {
+		slog.Warn("2D FTCS may be unstable", "r_x", rx, "r_y", ry, "r_sum", rx+ry)
+	}
+	slog.Info("Starting masked 2D FTCS solver", "nx", nx, "ny", ny, "active", mask.Active(), "nt", nt, "dt", dt)
+	u := newGrid(2, len(u0))
+	if err := maskedStart(u, u0, mask, emit); err != nil {
+		return nil, err
+	}
+	w := nx + 1
+	diag := 1 - 2*rx - 2*ry
+	for n := 0; n < nt; n++ {
+		cur, next := level(u, n), level(u, n+1)
+		applyMask(next, mask)
+		for j := 1; j < ny; j++ {
+			for i := 1; i < nx; i++ {
+				if c := j*w + i; !mask.Masked[c] {
+					next[c] = diag*cur[c] + rx*(cur[c-1]+cur[c+1]) + ry*(cur[c-w]+cur[c+w])
+				}
+			}
+		}
+		if err := emitLevel(emit, n+1, next); err != nil {
+			return nil, err
+		}
+	}
+	slog.Info("Masked 2D FTCS solver finished successfully")
+	return level(u, nt), nil
+}
+
+// ADI Писмена–Рэкфорда с маской, полушаги — как у Stream2DADIFrom
+func Stream2DMaskedADIFrom(u0 []float64, mask mathutils.Mask2D, nt int, dt, alpha float64, emit EmitFunc) ([]float64, error) {
+	nx, ny := mask.Nx, mask.Ny
+	dx, dy := 1/float64(nx), 1/float64(ny)
+	hx, hy := alpha*dt/(dx*dx)/2, alpha*dt/(dy*dy)/2
+	slog.Info("Starting masked 2D ADI solver", "nx", nx, "ny", ny, "active", mask.Active(), "nt", nt, "dt", dt, "r_x", 2*hx, "r_y", 2*hy)
+	u := newGrid(2, len(u0))
+	if err := maskedStart(u, u0, mask, emit); err != nil {
+		return nil, err
+	}
+	half := pool64.get(len(u0))
+	defer pool64.put(half)
+	applyMask(half, mask)
+	lines := newMaskedLines(mask, hx, hy)
+	defer lines.close()
+	for k := 0; k < nt; k++ {
+		cur, next := level(u, k), level(u, k+1)
+		applyMask(next, mask)
+		adiHalfEdges(cur, next, half, nx, ny, hy)
+		lines.sweepX(cur, half, hy)
+		lines.sweepY(half, next, hx)
+		if err := emitLevel(emit, k+1, next); err != nil {
+			return nil, err
+		}
+	}
+	slog.Info("Masked 2D ADI solver finished successfully")
+	return level(u, nt), nil
+}
+
+// LOD с маской, как Stream2DLODFrom
+func Stream2DMaskedLODFrom(u0 []float64, mask mathutils.Mask2D, nt int, dt, alpha float64, emit EmitFunc) ([]float64, error) {
+	nx, ny := mask.Nx, mask.Ny
+	dx, dy := 1/float64(nx), 1/float64(ny)
+	rx, ry := alpha*dt/(dx*dx), alpha*dt/(dy*dy)
+	slog.Info("Starting masked 2D LOD solver", "nx", nx, "ny", ny, "active", mask.Active(), "nt", nt, "dt", dt, "r_x", rx, "r_y", ry)
+	u := newGrid(2, len(u0))
+	if err := maskedStart(u, u0, mask, emit); err != nil {
+		return nil, err
+	}
+	w := nx + 1
+	half := pool64.get(len(u0))
+	defer pool64.put(half)
+	applyMask(half, mask)
+	lines := newMaskedLines(mask, rx, ry)
+	defer lines.close()
+	for k := 0; k < nt; k++ {
+		cur, next := level(u, k), level(u, k+1)
+		applyMask(next, mask)
+		for j := 1; j < ny; j++ {
+			half[j*w], half[j*w+nx] = next[j*w], next[j*w+nx]
+		}
+		lines.sweepX(cur, half, 0)
+		lines.sweepY(half, next, 0)
+		if err := emitLevel(emit, k+1, next); err != nil {
+			return nil, err
+		}
+	}
+	slog.Info("Masked 2D LOD solver finished successfully")
+	return level(u, nt), nil
+}
+
+// Отрезок подряд идущих включённых узлов линии: номера lo..hi включительно
+type maskRun struct {
+	lo, hi int
+}
+
+// Прогонки lineSolver по отрезкам включённых узлов: rows[j] — отрезки
+// строки j по i, cols[i] — столбца i по j; прогонки по длине отрезка
+type maskedLines struct {
+	nx, ny     int
+	hx, hy     float64
+	rows, cols [][]maskRun
+	tx, ty     map[int]*thomas
+	d, col     []float64
+}
+
+func newMaskedLines(mask mathutils.Mask2D, hx, hy float64) *maskedLines {
+	nx, ny := mask.Nx, mask.Ny
+	s := &maskedLines{nx: nx, ny: ny, hx: hx, hy: hy, tx: map[int]*thomas{}, ty: map[int]*thomas{}}
+	n := max(nx, ny) - 1
+	s.d, s.col = make([]float64, n), make([]float64, n)
+	active := func(i, j int) bool { return !mask.Masked[j*(nx+1)+i] }
+	runs := func(count int, on func(k int) bool, h float64, cache map[int]*thomas) []maskRun {
+		var list []maskRun
+		for k := 1; k < count; k++ {
+			if !on(k) || (k > 1 && on(k-1)) {
+				continue
+			}
+			hi := k
+			for hi+1 < count && on(hi+1) {
+				hi++
+			}
+			list = append(list, maskRun{k, hi})
+			if m := hi - k + 1; cache[m] == nil {
+				a, b, c := make([]float64, m), make([]float64, m), make([]float64, m)
+				cache[m] = lineThomas(a, b, c, h)
+			}
+		}
+		return list
+	}
+	s.rows = make([][]maskRun, ny+1)
+	for j := 1; j < ny; j++ {
+		s.rows[j] = runs(nx, func(i int) bool { return active(i, j) }, hx, s.tx)
+	}
+	s.cols = make([][]maskRun, nx+1)
+	for i := 1; i < nx; i++ {
+		s.cols[i] = runs(ny, func(j int) bool { return active(i, j) }, hy, s.ty)
+	}
+	return s
+}
+
+func (s *maskedLines) close() {
+	for _, t := range s.tx {
+		t.close()
+	}
+	for _, t := range s.ty {
+		t.close()
+	}
+}
+
+// Как lineSolver.sweepX, по отрезкам строк
+func (s *maskedLines) sweepX(src, dst []float64, explicit float64) {
+	w := s.nx + 1
+	for j := 1; j < s.ny; j++ {
+		for _, r := range s.rows[j] {
+			n := r.hi - r.lo + 1
+			rhs := s.d[:n]
+			for i := r.lo; i <= r.hi; i++ {
+				c := j*w + i
+				rhs[i-r.lo] = src[c] + explicit*(src[c-w]-2*src[c]+src[c+w])
+			}
+			rhs[0] += s.hx * dst[j*w+r.lo-1]
+			rhs[n-1] += s.hx * dst[j*w+r.hi+1]
+			s.tx[n].solve(rhs, dst[j*w+r.lo:j*w+r.hi+1])
+		}
+	}
+}
+
+// Как lineSolver.sweepY, по отрезкам столбцов
+func (s *maskedLines) sweepY(src, dst []float64, explicit float64) {
+	w := s.nx + 1
+	for i := 1; i < s.nx; i++ {
+		for _, r := range s.cols[i] {
+			n := r.hi - r.lo + 1
+			rhs, line := s.d[:n], s.col[:n]
+			for j := r.lo; j <= r.hi; j++ {
+				c := j*w + i
+				rhs[j-r.lo] = src[c] + explicit*(src[c-1]-2*src[c]+src[c+1])
+			}
+			rhs[0] += s.hy * dst[(r.lo-1)*w+i]
+			rhs[n-1] += s.hy * dst[(r.hi+1)*w+i]
+			s.ty[n].solve(rhs, line)
+			for j := r.lo; j <= r.hi; j++ {
+				dst[j*w+i] = line[j-r.lo]
+			}
+		}
+	}
+}
+
+// Проверочные случаи схем с маской; в Verify — под именем MASK
+var verificationMask = []VerifyCase{
+	maskEmptyCase("FTCS", Stream2DMaskedFTCSFrom, Stream2DFTCSFrom, 16, 1.0/2048, 0.05),
+	maskEmptyCase("ADI", Stream2DMaskedADIFrom, Stream2DADIFrom, 16, 0.01, 0.1),
+	maskEmptyCase("LOD", Stream2DMaskedLODFrom, Stream2DLODFrom, 16, 0.01, 0.1),
+	maskDecayCase("ADI", Stream2DMaskedADIFrom, 16, 64, 0.03),
+	maskDecayCase("LOD", Stream2DMaskedLODFrom, 16, 64, 0.03),
+}
+
+// Маска без выключенных узлов против схемы без маски из
+// sin(πx)·sin(πy): наибольшее отличие — 0
+func maskEmptyCase(name string, masked StreamMasked2DFunc, plain Stream2DFunc, n int, dt, tmax float64) VerifyCase {
+	return VerifyCase{
+		Name:     fmt.Sprintf("%s empty mask against the square n=%d", name, n),
+		Quantity: "max |u − u square|",
+		Compare:  VerifyAtMost,
+		Expected: 0,
+		Measure: func(ctx context.Context, _ Method) (float64, string, error) {
+			h := 1 / float64(n)
+			nt := int(math.Round(tmax / dt))
+			u0 := SineProfile2D(n, n, h, h)
+			want, err := plain(u0, n, n, nt, h, h, dt, 1, nil)
+			if err != nil {
+				return math.NaN(), "", err
+			}
+			mask := mathutils.NewMaskHoles(n, n, nil, 0)
+			got, err := masked(u0, mask, nt, dt, 1, nil)
+			if err != nil {
+				return math.NaN(), "", err
+			}
+			var worst float64
+			for i := range got {
+				worst = math.Max(worst, math.Abs(got[i]-want[i]))
+			}
+			return worst, "", nil
+		},
+	}
+}
+
+// Скорость затухания наибольшего значения на Г-образной пластине
+// (mathutils.MaskLShape) из единицы, dt = 0.001: −ln(max(0.2)/max(0.1))/0.1.
+// К t = 0.1 остаётся первая мода (симметричное начало не возбуждает
+// вторую, антисимметричную относительно диагонали), и скорость — первое
+// собственное число λ₁ ≈ 9.6397/0.25 ≈ 38.56. Скорость на сетке n против
+// эталонного расчёта на сетке fine: относительное отличие не больше tol
+func maskDecayCase(name string, stream StreamMasked2DFunc, n, fine int, tol float64) VerifyCase {
+	return VerifyCase{
+		Name:     fmt.Sprintf("%s L-shape decay rate n=%d against n=%d", name, n, fine),
+		Quantity: "|rate − rate fine| / rate fine",
+		Compare:  VerifyAtMost,
+		Expected: tol,
+		Measure: func(ctx context.Context, _ Method) (float64, string, error) {
+			coarse, err := maskDecayRate(stream, n)
+			if err != nil {
+				return math.NaN(), "", err
+			}
+			ref, err := maskDecayRate(stream, fine)
+			if err != nil {
+				return math.NaN(), "", err
+			}
+			return math.Abs(coarse-ref) / ref, fmt.Sprintf("rate %.4f, fine %.4f, λ₁ of the L-shape 38.559", coarse, ref), nil
+		},
+	}
+}
+
+func maskDecayRate(stream StreamMasked2DFunc, n int) (float64, error) {
+	const dt = 0.001
+	holes, _ := mathutils.ParseMaskHoles(mathutils.MaskLShape)
+	mask := mathutils.NewMaskHoles(n, n, holes, 0)
+	u0 := make([]float64, (n+1)*(n+1))
+	for i := range u0 {
+		u0[i] = 1
+	}
+	var peaks [2]float64
+	_, err := stream(u0, mask, 200, dt, 1, func(k int, row []float64) error {
+		switch k {
+		case 100:
+			peaks[0] = MaskedMax(row, mask)
+		case 200:
+			peaks[1] = MaskedMax(row, mask)
+		}
+		return nil
+	})
+	if err != nil {
+		return math.NaN(), err
+	}
+	return -math.Log(peaks[1]/peaks[0]) / (100 * dt), nil
+}
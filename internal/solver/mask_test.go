@@ -0,0 +1,184 @@
+package solver
+
+import (
+	"math"
+	"slices"
+	"testing"
+
+	"heat-solver/internal/mathutils"
+)
+
+var maskMethods = []struct {
+	name   string
+	masked StreamMasked2DFunc
+	plain  Stream2DFunc
+	dt     float64
+}{
+	{"FTCS", Stream2DMaskedFTCSFrom, Stream2DFTCSFrom, 1.0 / 2048},
+	{"ADI", Stream2DMaskedADIFrom, Stream2DADIFrom, 0.01},
+	{"LOD", Stream2DMaskedLODFrom, Stream2DLODFrom, 0.01},
+}
+
+// Пустая маска побитно совпадает со схемой без маски на каждом слое
+func TestMaskedEmpty(t *testing.T) {
+	const n, nt = 16, 20
+	h := 1.0 / n
+	for _, tc := range maskMethods {
+		t.Run(tc.name, func(t *testing.T) {
+			u0 := SineProfile2D(n, n, h, h)
+			var want [][]float64
+			_, err := tc.plain(u0, n, n, nt, h, h, tc.dt, 1, func(_ int, row []float64) error {
+				want = append(want, slices.Clone(row))
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = tc.masked(u0, mathutils.NewMaskHoles(n, n, nil, 0), nt, tc.dt, 1, func(k int, row []float64) error {
+				if !slices.Equal(row, want[k]) {
+					t.Errorf("level %d differs from the square", k)
+				}
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+// Маска с нулём на правой половине квадрата оставляет прямоугольник
+// [0, ½]×[0, 1]: решение на нём — как у схемы без маски на сетке 8×16
+// с тем же шагом
+func TestMaskedRectangle(t *testing.T) {
+	const n, nt = 16, 20
+	h := 1.0 / n
+	mask := mathutils.NewMaskHoles(n, n, []mathutils.MaskHole{{X0: 0.5, X1: 1, Y0: 0, Y1: 1}}, 0)
+	for _, tc := range maskMethods {
+		t.Run(tc.name, func(t *testing.T) {
+			u0 := make([]float64, (n+1)*(n+1))
+			small := make([]float64, (n/2+1)*(n+1))
+			for j := 0; j <= n; j++ {
+				for i := 0; i <= n/2; i++ {
+					v := math.Sin(2*math.Pi*float64(i)*h) * math.Sin(math.Pi*float64(j)*h) * (1 + float64(i*j)*h*h)
+					u0[j*(n+1)+i], small[j*(n/2+1)+i] = v, v
+				}
+			}
+			want, err := tc.plain(small, n/2, n, nt, h, h, tc.dt, 1, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := tc.masked(u0, mask, nt, tc.dt, 1, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for j := 0; j <= n; j++ {
+				for i := 0; i <= n; i++ {
+					v, w := got[j*(n+1)+i], 0.0
+					if i <= n/2 {
+						w = want[j*(n/2+1)+i]
+					}
+					if math.Abs(v-w) > 1e-15 {
+						t.Fatalf("node (%d, %d): %v, rectangle %v", i, j, v, w)
+					}
+				}
+			}
+		})
+	}
+}
+
+// Отверстие в середине с температурой 1 из нуля: выключенные узлы держат
+// температуру маски, края квадрата — ноль, включённые узлы не выходят за
+// [0, 1] (принцип максимума) и прогреваются
+func TestMaskedHoleValue(t *testing.T) {
+	const n, nt = 20, 50
+	holes, err := mathutils.ParseMaskHoles("0.4:0.6:0.3:0.5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	mask := mathutils.NewMaskHoles(n, n, holes, 1)
+	for _, tc := range maskMethods {
+		t.Run(tc.name, func(t *testing.T) {
+			w := n + 1
+			last, err := tc.masked(make([]float64, w*w), mask, nt, tc.dt, 1, func(k int, row []float64) error {
+				for c, v := range row {
+					i, j := c%w, c/w
+					switch {
+					case i == 0 || j == 0 || i == n || j == n:
+						if v != 0 {
+							t.Fatalf("level %d: edge node (%d, %d) = %v", k, i, j, v)
+						}
+					case mask.Masked[c]:
+						if v != 1 {
+							t.Fatalf("level %d: masked node (%d, %d) = %v", k, i, j, v)
+						}
+					case v < -1e-15 || v > 1+1e-15:
+						t.Fatalf("level %d: node (%d, %d) = %v outside [0, 1]", k, i, j, v)
+					}
+				}
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if beside, peak := last[8*w+7], MaskedMax(last, mask); beside <= 0 || peak >= 1 {
+				t.Errorf("node next to the hole %v, max over active nodes %v", beside, peak)
+			}
+		})
+	}
+}
+
+// Скорость затухания на Г-образной пластине на сетке 16 против сетки 64:
+// в пределах 3%, а на сетке 64 — близко к λ₁ ≈ 38.56; у LOD при
+// dt = 0.001 заметна ошибка расщепления первого порядка
+func TestMaskedLShapeDecay(t *testing.T) {
+	tests := []struct {
+		name   string
+		stream StreamMasked2DFunc
+		tol    float64
+	}{
+		{"ADI", Stream2DMaskedADIFrom, 0.01},
+		{"LOD", Stream2DMaskedLODFrom, 0.025},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			coarse, err := maskDecayRate(tc.stream, 16)
+			if err != nil {
+				t.Fatal(err)
+			}
+			fine, err := maskDecayRate(tc.stream, 64)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if math.Abs(coarse-fine)/fine > 0.03 || math.Abs(fine-38.559)/38.559 > tc.tol {
+				t.Errorf("rate %.4f on 16×16, %.4f on 64×64, λ₁ = 38.559", coarse, fine)
+			}
+		})
+	}
+}
+
+func TestMaskedMax(t *testing.T) {
+	mask := mathutils.Mask2D{Nx: 3, Ny: 2, Masked: []bool{false, false, false, false, false, false, true, false, false, false, false, false}}
+	row := []float64{9, 9, 9, 9, 9, 0.5, 7, 9, 9, 9, 9, 9}
+	if peak := MaskedMax(row, mask); peak != 0.5 {
+		t.Errorf("max %v, want 0.5 (edges and masked nodes skipped)", peak)
+	}
+}
+
+func TestLookupMasked2D(t *testing.T) {
+	if names := MethodsMasked2D(); !slices.Equal(names, []string{"FTCS", "ADI", "LOD"}) {
+		t.Errorf("methods %v", names)
+	}
+	for _, name := range []string{"ftcs", "Adi", "LOD"} {
+		if _, ok := LookupMasked2D(name); !ok {
+			t.Errorf("%s not found", name)
+		}
+	}
+	if _, ok := LookupMasked2D("CN"); ok {
+		t.Error("CN found")
+	}
+	stream, _ := LookupMasked2D("ADI")
+	if _, err := stream(make([]float64, 10), mathutils.NewMaskHoles(4, 4, nil, 0), 1, 0.01, 1, nil); err == nil {
+		t.Error("initial layer of the wrong size accepted")
+	}
+}
@@ -0,0 +1,114 @@
+package solver
+
+import "math"
+
+// Допуск проверки принципа максимума относительно масштаба данных:
+// отклонения на уровне округления нарушением не считаются
+const principleTolerance = 1e-12
+
+// Узел, вышедший за границы начальных и граничных данных: на сколько
+// (Magnitude > 0) и в какую сторону (Below — ниже минимума)
+type Violation struct {
+	Step      int
+	Node      int
+	Magnitude float64
+	Below     bool
+}
+
+// Итог проверки дискретного принципа максимума за расчёт: число
+// нарушений (узел на слое), первое по времени и наибольшее. При Count = 0
+// First и Worst пустые
+type MaxPrinciple struct {
+	Lo, Hi float64
+	Count  int64
+	First  Violation
+	Worst  Violation
+}
+
+// Проверка слоёв на принцип максимума: решение с нулевыми граничными
+// значениями не выходит за [min(0, min u0), max(0, max u0)]. Один проход
+// по слою за минимумом и максимумом, поэтому включена во всех расчётах;
+// узлы перебираются, только если слой вышел за границы. NaN и Inf не
+// проверяются — это расхождение (Diverged)
+type PrincipleMonitor struct {
+	tol    float64
+	report MaxPrinciple
+	// Максимумы слоёв, если включена запись (TracePeaks)
+	peaks *PeakTrace
+}
+
+// Границы берутся по внутренним узлам u0: граничные узлы расчёт обнуляет
+// (см. start)
+func NewPrincipleMonitor(u0 []float64) *PrincipleMonitor {
+	var lo, hi float64
+	for _, v := range u0[1 : len(u0)-1] {
+		lo, hi = min(lo, v), max(hi, v)
+	}
+	scale := max(math.Abs(lo), math.Abs(hi), 1)
+	return &PrincipleMonitor{tol: principleTolerance * scale, report: MaxPrinciple{Lo: lo, Hi: hi}}
+}
+
+// Запись максимума каждого проверяемого слоя тем же проходом; levels —
+// ожидаемое число слоёв
+func (m *PrincipleMonitor) TracePeaks(levels int) *PeakTrace {
+	m.peaks = NewPeakTrace(levels)
+	return m.peaks
+}
+
+// Проверка слоя n; слои передаются по порядку
+func (m *PrincipleMonitor) Check(n int, row []float64) {
+	rowLo, rowHi, node := extrema(row)
+	if m.peaks != nil {
+		m.peaks.add(rowHi, node)
+	}
+	lo, hi := m.report.Lo-m.tol, m.report.Hi+m.tol
+	if rowLo >= lo && rowHi <= hi || rowHi != rowHi {
+		return
+	}
+	for i, v := range row {
+		if v >= lo && v <= hi || math.IsNaN(v) || math.IsInf(v, 0) {
+			continue
+		}
+		viol := Violation{Step: n, Node: i, Magnitude: v - m.report.Hi}
+		if v < lo {
+			viol.Magnitude, viol.Below = m.report.Lo-v, true
+		}
+		r := &m.report
+		if r.Count == 0 {
+			r.First = viol
+		}
+		if viol.Magnitude > r.Worst.Magnitude {
+			r.Worst = viol
+		}
+		r.Count++
+	}
+}
+
+// Минимум, максимум и первый узел максимума без учёта NaN; для слоя из
+// одних NaN — NaN
+func extrema(row []float64) (lo, hi float64, node int) {
+	lo, hi = row[0], row[0]
+	if lo != lo {
+		// Редкий случай: сравнения с NaN в начале ложны, ищем первое число
+		for i, v := range row {
+			if v == v {
+				lo, hi, node = extrema(row[i:])
+				return lo, hi, node + i
+			}
+		}
+		return lo, hi, 0
+	}
+	for i, v := range row {
+		if v > hi {
+			hi, node = v, i
+		} else if v < lo {
+			lo = v
+		}
+	}
+	return lo, hi, node
+}
+
+// Итог по проверенным слоям
+func (m *PrincipleMonitor) Report() MaxPrinciple {
+	return m.report
+}
@@ -0,0 +1,107 @@
+package solver
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"heat-solver/internal/config"
+)
+
+// Границы берутся по внутренним узлам u0 и нулю; узлы за границами
+// считаются по одному, NaN и Inf пропускаются
+func TestPrincipleMonitor(t *testing.T) {
+	u0 := []float64{7, 0.2, 1, 0.5, -3}
+	tests := []struct {
+		name  string
+		rows  [][]float64
+		count int64
+		first Violation
+		worst Violation
+	}{
+		{"inside", [][]float64{{0, 0, 1, 0.5, 0}, {0, 0.1, 0.9, 0.4, 0}}, 0, Violation{}, Violation{}},
+		{"round-off", [][]float64{{0, 1 + 1e-13, -1e-13, 0, 0}}, 0, Violation{}, Violation{}},
+		{"above", [][]float64{{0, 1.25, 0.5, 0, 0}}, 1, Violation{1, 1, 0.25, false}, Violation{1, 1, 0.25, false}},
+		{"first and worst", [][]float64{{0, 0.5, -0.1, 0, 0}, {0, 1.5, 0, -0.2, 0}},
+			3, Violation{1, 2, 0.1, true}, Violation{2, 1, 0.5, false}},
+		{"diverged nodes skipped", [][]float64{{0, math.NaN(), math.Inf(1), math.Inf(-1), 0}}, 0, Violation{}, Violation{}},
+		{"NaN first", [][]float64{{math.NaN(), 2, 0, 0, 0}}, 1, Violation{1, 1, 1, false}, Violation{1, 1, 1, false}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			m := NewPrincipleMonitor(u0)
+			for n, row := range tc.rows {
+				m.Check(n+1, row)
+			}
+			r := m.Report()
+			if r.Lo != 0 || r.Hi != 1 {
+				t.Fatalf("bounds [%v, %v], want [0, 1]", r.Lo, r.Hi)
+			}
+			if r.Count != tc.count || !sameViolation(r.First, tc.first) || !sameViolation(r.Worst, tc.worst) {
+				t.Errorf("count %d, first %+v, worst %+v; want %d, %+v, %+v", r.Count, r.First, r.Worst, tc.count, tc.first, tc.worst)
+			}
+		})
+	}
+}
+
+func sameViolation(a, b Violation) bool {
+	return a.Step == b.Step && a.Node == b.Node && a.Below == b.Below && math.Abs(a.Magnitude-b.Magnitude) < 1e-12
+}
+
+// Один внутренний узел (nx = 2), CN при r = 5: u ← (1 − r)/(1 + r)·u =
+// −2/3·u. Слои 1 и 3 выходят за [0, 1] на 2/3 и 8/27, слой 2 (4/9) — нет.
+// Итог один и тот же при любом хранении
+func TestMaxPrincipleCrafted(t *testing.T) {
+	spike := []config.Sample{{X: 0, U: 0}, {X: 0.5, U: 1}, {X: 1, U: 0}}
+	want := MaxPrinciple{Lo: 0, Hi: 1, Count: 2, First: Violation{1, 1, 2.0 / 3, true}, Worst: Violation{1, 1, 2.0 / 3, true}}
+	for _, storage := range []string{config.StorageFull, config.StorageFinalOnly, StorageCheckpoint} {
+		t.Run(storage, func(t *testing.T) {
+			p := config.Params{Method: "CN", Dx: 0.5, Dt: 1.25, Tmax: 3.75, ICSamples: spike}
+			var res *Result
+			var err error
+			if storage == StorageCheckpoint {
+				res, err = Checkpointed(context.Background(), p.Normalize(), 2, Hooks{})
+			} else {
+				p.Storage = storage
+				res, err = Run(context.Background(), p.Normalize(), Hooks{})
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer res.Release()
+			r := res.MaxPrinciple
+			if r.Lo != want.Lo || r.Hi != want.Hi || r.Count != want.Count || !sameViolation(r.First, want.First) || !sameViolation(r.Worst, want.Worst) {
+				t.Errorf("%+v, want %+v", r, want)
+			}
+		})
+	}
+}
+
+// Ступенька при r = 5 на грубой сетке: BTCS монотонна, у CN плохо
+// гасимые высокие моды уводят узлы у скачков ниже нуля
+func TestMaxPrincipleStep(t *testing.T) {
+	tests := []struct {
+		method string
+		clean  bool
+	}{
+		{"BTCS", true},
+		{"CN", false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.method, func(t *testing.T) {
+			p := config.Params{Method: tc.method, Dx: 0.1, Dt: 0.05, Tmax: 1, IC: config.ICTopHat}.Normalize()
+			res, err := Run(context.Background(), p, Hooks{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer res.Release()
+			r := res.MaxPrinciple
+			if tc.clean != (r.Count == 0) {
+				t.Fatalf("%d violations, worst %+v", r.Count, r.Worst)
+			}
+			if !tc.clean && !(r.Worst.Below && r.Worst.Magnitude > 0.01) {
+				t.Errorf("first %+v, worst %+v; want undershoots", r.First, r.Worst)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package solver
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"heat-solver/internal/config"
+)
+
+// Начальный профиль sin(3πx): у расчёта ненулевая только a_3, и она
+// убывает по множителю схемы G(3π·dx)ⁿ, а не по exp(−9π²t) задачи. У
+// BTCS при r = 2 их отличие на последнем слое — десятки процентов
+func TestModesDecay(t *testing.T) {
+	const nx, modes = 40, 8
+	dx := 1.0 / nx
+	samples := make([]config.Sample, nx+1)
+	for i := range samples {
+		x := float64(i) * dx
+		samples[i] = config.Sample{X: x, U: math.Sin(3 * math.Pi * x)}
+	}
+	samples[0].U, samples[nx].U = 0, 0
+
+	tests := []struct {
+		method string
+		r      float64
+	}{
+		{"FTCS", 0.4},
+		{"BTCS", 2},
+		{"CN", 2},
+	}
+	for _, tc := range tests {
+		for _, checkpoint := range []bool{false, true} {
+			name := tc.method + "/full"
+			if checkpoint {
+				name = tc.method + "/checkpoint"
+			}
+			t.Run(name, func(t *testing.T) {
+				dt := tc.r * dx * dx
+				p := config.Params{Method: tc.method, Dx: dx, Dt: dt, Tmax: 40 * dt, ICSamples: samples}.Normalize()
+				var res *Result
+				var err error
+				if checkpoint {
+					res, err = Checkpointed(context.Background(), p, 7, Hooks{})
+				} else {
+					res, err = Run(context.Background(), p, Hooks{})
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+				defer res.Release()
+				times, a, err := res.Modes(modes, 4)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(times) != res.Nt/4+1 {
+					t.Fatalf("%d levels for nt = %d and stride 4", len(times), res.Nt)
+				}
+				g := mustLookup(t, tc.method).Amplification(tc.r, 3*math.Pi*dx)
+				for j, coef := range a {
+					n := 4 * j
+					if times[j] != float64(n)*dt {
+						t.Fatalf("level %d at t = %v, want %v", j, times[j], float64(n)*dt)
+					}
+					for k, v := range coef {
+						want := 0.0
+						if k == 2 {
+							want = math.Pow(g, float64(n))
+						}
+						if math.Abs(v-want) > 1e-13 {
+							t.Errorf("step %d: a_%d = %v, want %v", n, k+1, v, want)
+						}
+					}
+				}
+				last := a[len(a)-1][2]
+				pde := math.Exp(-9 * math.Pi * math.Pi * times[len(times)-1])
+				if tc.method == "BTCS" && math.Abs(last-pde) < 0.1*pde {
+					t.Errorf("a_3 = %v follows the PDE decay %v, not the scheme", last, pde)
+				}
+			})
+		}
+	}
+
+	res, err := Run(context.Background(), config.Params{Method: "CN", Dx: dx, Dt: 0.01, Tmax: 0.1}.Normalize(), Hooks{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Release()
+	if _, _, err := res.Modes(nx, 1); err == nil {
+		t.Errorf("%d modes accepted on nx = %d", nx, nx)
+	}
+}
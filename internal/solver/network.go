@@ -0,0 +1,443 @@
+package solver
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"slices"
+
+	"heat-solver/internal/config"
+	"heat-solver/internal/mathutils"
+)
+
+// Сеть стержней (mathutils.Network): на каждом стержне u_t = α_s·u_xx со
+// своими длиной, nx и α, в узлах — общая температура. Узел с заданной
+// температурой — граница Дирихле; свободный узел получает ячейку из
+// половинок прилежащих ячеек всех его стержней, V = Σ dx_s/2, и
+//
+//	V·du/dt = Σ α_s·(u_s − u)/dx_s,
+//
+// u_s — соседний узел на стержне s: сумма потоков в узел равна нулю
+// (условие Кирхгофа), а у узла внутри одного стержня это обычная схема.
+// Шаг θ-взвешенный, как SolveTheta. Матрица — трёхдиагональные блоки
+// стержней A и несколько строк и столбцов свободных узлов (окаймление):
+// [A B; C D]. Она постоянна, и один раз считаются W = A⁻¹B (прогонки по
+// стержням, прилежащим к узлу) и дополнение Шура S = D − C·W с LU
+// (mathutils.DenseLU). Шаг — прогонка по каждому стержню z = A⁻¹f, узлы
+// y = S⁻¹(g − C·z) и поправка x = z − W·y
+
+// Приём слоя n сети: по строке на стержень, Nx+1 значений от From к To
+// (концы — температуры узлов), как EmitFunc
+type NetworkEmitFunc func(n int, rods [][]float64) error
+
+// Конец стержня в свободном узле: стержень и конец (0 — From, 1 — To)
+type networkEnd struct {
+	seg, end int
+}
+
+// Сеть с начальными профилями u0 (по строке на стержень, Nx+1 значений);
+// в узлах с заданной температурой берётся она, в свободных — значение
+// конца первого стержня узла. Хранятся два слоя, каждый передаётся в emit
+func StreamNetworkFrom(net mathutils.Network, u0 [][]float64, nt int, dt, theta float64, emit NetworkEmitFunc) ([][]float64, error) {
+	if err := net.Validate(); err != nil {
+		return nil, err
+	}
+	if len(u0) != len(net.Segments) {
+		return nil, fmt.Errorf("got %d initial profiles for %d segments", len(u0), len(net.Segments))
+	}
+	var levels [2][][]float64
+	for k := range levels {
+		levels[k] = make([][]float64, len(net.Segments))
+	}
+	for s, seg := range net.Segments {
+		if len(u0[s]) != seg.Nx+1 {
+			return nil, fmt.Errorf("segment %q: initial profile has %d values, want %d", seg.Name, len(u0[s]), seg.Nx+1)
+		}
+		for k := range levels {
+			levels[k][s] = make([]float64, seg.Nx+1)
+		}
+		copy(levels[0][s], u0[s])
+	}
+	if err := network(net, nt, dt, theta, levels, emit); err != nil {
+		return nil, err
+	}
+	return levels[nt%2], nil
+}
+
+// Температуры узлов по слою: конец первого стержня каждого узла
+func NetworkNodeTemperatures(net mathutils.Network, rods [][]float64) []float64 {
+	temps := make([]float64, len(net.Nodes))
+	seen := make([]bool, len(net.Nodes))
+	for s, seg := range net.Segments {
+		for e, name := range [2]string{seg.From, seg.To} {
+			if i := net.NodeIndex(name); !seen[i] {
+				seen[i] = true
+				temps[i] = rods[s][e*seg.Nx]
+			}
+		}
+	}
+	return temps
+}
+
+// Тепло сети: Σ по стержням ∫u dx по формуле трапеций; узел делится между
+// стержнями по половинкам ячеек, как в схеме, так что при
+// теплоизолированных концах оно сохраняется
+func NetworkHeat(net mathutils.Network, rods [][]float64) float64 {
+	var heat float64
+	for s, seg := range net.Segments {
+		heat += mathutils.HeatContent(rods[s], seg.Length/float64(seg.Nx))
+	}
+	return heat
+}
+
+func network(net mathutils.Network, nt int, dt, theta float64, levels [2][][]float64, emit NetworkEmitFunc) error {
+	segs := net.Segments
+	nodeOf := make([][2]int, len(segs))
+	for s, seg := range segs {
+		nodeOf[s] = [2]int{net.NodeIndex(seg.From), net.NodeIndex(seg.To)}
+	}
+	// Свободные узлы, их концы стержней и ячейки V
+	free := make([]int, len(net.Nodes))
+	var ends [][]networkEnd
+	for i, node := range net.Nodes {
+		free[i] = -1
+		if node.Temperature == nil {
+			free[i] = len(ends)
+			ends = append(ends, nil)
+		}
+	}
+	volume := make([]float64, len(ends))
+	dxs, rs := make([]float64, len(segs)), make([]float64, len(segs))
+	var maxR float64
+	for s, seg := range segs {
+		dxs[s] = seg.Length / float64(seg.Nx)
+		rs[s] = seg.Alpha * dt / (dxs[s] * dxs[s])
+		maxR = math.Max(maxR, rs[s])
+		for e, node := range nodeOf[s] {
+			if j := free[node]; j >= 0 {
+				ends[j] = append(ends[j], networkEnd{s, e})
+				volume[j] += dxs[s] / 2
+			}
+		}
+	}
+	name := "network " + thetaName(theta)
+	// У свободного узла роль r играет половина dt/V·Σ α_s/dx_s: у узла
+	// посередине одного стержня это ровно r
+	for j, list := range ends {
+		var w float64
+		for _, end := range list {
+			w += segs[end.seg].Alpha / dxs[end.seg]
+		}
+		maxR = math.Max(maxR, dt/volume[j]*w/2)
+	}
+	warnUnstable(name, maxR, thetaMaxR(theta))
+	slog.Info("Starting "+name+" solver", "segments", len(segs), "nodes", len(net.Nodes), "free_nodes", len(ends), "nt", nt, "dt", dt, "max_r", maxR)
+
+	// Соседний с концом end стержня s узел сетки: номер в z стержня или,
+	// у стержня из одного интервала, −1 (сосед — другой узел сети)
+	adjacent := func(s, end int) int {
+		if segs[s].Nx < 2 {
+			return -1
+		}
+		return end * (segs[s].Nx - 2)
+	}
+	temp := func(node int, rods [][]float64) float64 {
+		if t := net.Nodes[node].Temperature; t != nil {
+			return *t
+		}
+		return NetworkNodeTemperatures(net, rods)[node]
+	}
+
+	// Блоки стержней A_s: (−θr, 1 + 2θr, −θr) по внутренним узлам
+	tris := make([]*thomas, len(segs))
+	zs, ds := make([][]float64, len(segs)), make([][]float64, len(segs))
+	for s, seg := range segs {
+		n := seg.Nx - 1
+		if n < 1 {
+			continue
+		}
+		a, b, c, d, ws := workspace(n)
+		defer pool64.put(ws)
+		for i := range b {
+			a[i], b[i], c[i] = -theta*rs[s], 1+2*theta*rs[s], -theta*rs[s]
+		}
+		tris[s] = newThomas(n)
+		defer tris[s].close()
+		tris[s].factor(a, b, c)
+		ds[s], zs[s] = d, make([]float64, n)
+	}
+
+	// W = A⁻¹B: столбец узла j отличен от нуля только на его стержнях
+	k := len(ends)
+	wcols := make([]map[int][]float64, k)
+	for j, list := range ends {
+		wcols[j] = make(map[int][]float64, len(list))
+		for _, end := range list {
+			i := adjacent(end.seg, end.end)
+			if i < 0 {
+				continue
+			}
+			col := make([]float64, len(zs[end.seg]))
+			col[i] = -theta * rs[end.seg]
+			w := make([]float64, len(col))
+			tris[end.seg].solve(col, w)
+			wcols[j][end.seg] = w
+		}
+	}
+	// Коэффициент потока по стержню s в строке узла j: dt/V_j·α_s/dx_s
+	flow := func(j, s int) float64 {
+		return dt / volume[j] * segs[s].Alpha / dxs[s]
+	}
+	var lu *mathutils.DenseLU
+	if k > 0 {
+		sm := make([][]float64, k)
+		for j := range sm {
+			sm[j] = make([]float64, k)
+		}
+		for j, list := range ends {
+			sm[j][j] = 1
+			for _, end := range list {
+				f := theta * flow(j, end.seg)
+				sm[j][j] += f
+				if i := adjacent(end.seg, end.end); i >= 0 {
+					// −C·W: C в строке j — −f у соседнего узла стержня
+					for m := range ends {
+						if w, ok := wcols[m][end.seg]; ok {
+							sm[j][m] += f * w[i]
+						}
+					}
+				} else if other := free[nodeOf[end.seg][1-end.end]]; other >= 0 {
+					sm[j][other] -= f
+				}
+			}
+		}
+		var err error
+		if lu, err = mathutils.NewDenseLU(sm); err != nil {
+			return fmt.Errorf("network junction system: %w", err)
+		}
+	}
+	g, y := make([]float64, k), make([]float64, k)
+
+	cur := levels[0]
+	for s := range segs {
+		for e, node := range nodeOf[s] {
+			cur[s][e*segs[s].Nx] = temp(node, cur)
+		}
+	}
+	if emit != nil {
+		if err := emit(0, cur); err != nil {
+			return err
+		}
+	}
+
+	for step := 0; step < nt; step++ {
+		cur, next := levels[step%2], levels[(step+1)%2]
+		for s, seg := range segs {
+			if tris[s] == nil {
+				continue
+			}
+			r, u, d := rs[s], cur[s], ds[s]
+			for i := 1; i < seg.Nx; i++ {
+				d[i-1] = u[i] + (1-theta)*r*(u[i-1]-2*u[i]+u[i+1])
+			}
+			for e, node := range nodeOf[s] {
+				if t := net.Nodes[node].Temperature; t != nil {
+					d[e*(seg.Nx-2)] += theta * r * *t
+				}
+			}
+			tris[s].solve(d, zs[s])
+		}
+		for j, list := range ends {
+			var u float64
+			for n, end := range list {
+				row := cur[end.seg]
+				if n == 0 {
+					u = row[end.end*segs[end.seg].Nx]
+					g[j] = u
+				}
+				nb := row[1]
+				if end.end == 1 {
+					nb = row[len(row)-2]
+				}
+				f := flow(j, end.seg)
+				g[j] += (1 - theta) * f * (nb - u)
+				if i := adjacent(end.seg, end.end); i >= 0 {
+					g[j] += theta * f * zs[end.seg][i]
+				} else if t := net.Nodes[nodeOf[end.seg][1-end.end]].Temperature; t != nil {
+					g[j] += theta * f * *t
+				}
+			}
+		}
+		if lu != nil {
+			lu.Solve(g, y)
+		}
+		for s, seg := range segs {
+			u := next[s]
+			if tris[s] != nil {
+				copy(u[1:seg.Nx], zs[s])
+				for j := range ends {
+					if w, ok := wcols[j][s]; ok {
+						for i, wi := range w {
+							u[i+1] -= wi * y[j]
+						}
+					}
+				}
+			}
+			for e, node := range nodeOf[s] {
+				if j := free[node]; j >= 0 {
+					u[e*seg.Nx] = y[j]
+				} else {
+					u[e*seg.Nx] = *net.Nodes[node].Temperature
+				}
+			}
+		}
+		if emit != nil {
+			if err := emit(step+1, next); err != nil {
+				return err
+			}
+		}
+	}
+
+	slog.Info(name + " solver finished successfully")
+	return nil
+}
+
+// Проверочные случаи сети стержней; в Verify — под именем NETWORK
+var verificationNetwork = []VerifyCase{
+	networkSplitCase(20, 0.001, 0.1, 1e-12),
+	networkSteadyCase(1e-9),
+	networkHeatCase(1e-12),
+}
+
+func networkTemp(t float64) *float64 {
+	return &t
+}
+
+// Стержень [0, 1] из sin(πx), разрезанный на два стержня по nx/2
+// интервалов со свободным узлом в середине, против CN одного стержня
+// (через Run, как из CLI): узел посередине стержня с ячейкой из двух
+// половинок — обычная схема, и отличие — только округление
+func networkSplitCase(nx int, dt, tmax, tol float64) VerifyCase {
+	return VerifyCase{
+		Name:     fmt.Sprintf("rod split in two nx=%d CN", nx),
+		Quantity: "max |u − u single rod|",
+		Compare:  VerifyAtMost,
+		Expected: tol,
+		Measure: func(ctx context.Context, _ Method) (float64, string, error) {
+			dx := 1 / float64(nx)
+			p := config.Params{Method: "CN", Dx: dx, Dt: dt, Tmax: tmax, Storage: config.StorageFinalOnly, Precision: config.PrecisionFloat64}
+			res, err := Run(ctx, p, Hooks{})
+			if err != nil {
+				return math.NaN(), "", err
+			}
+			defer res.Release()
+			half := nx / 2
+			net := mathutils.Network{
+				Nodes: []mathutils.NetworkNode{{Name: "A", Temperature: networkTemp(0)}, {Name: "J"}, {Name: "B", Temperature: networkTemp(0)}},
+				Segments: []mathutils.Segment{
+					{Name: "left", From: "A", To: "J", Length: 0.5, Nx: half, Alpha: 1},
+					{Name: "right", From: "J", To: "B", Length: 0.5, Nx: nx - half, Alpha: 1},
+				},
+			}
+			sine := SineProfile(nx, dx)
+			rods, err := StreamNetworkFrom(net, [][]float64{sine[:half+1], sine[half:]}, res.Nt, res.Dt, 0.5, nil)
+			if err != nil {
+				return math.NaN(), "", err
+			}
+			var worst float64
+			for i, v := range res.Final {
+				u := rods[0][min(i, half)]
+				if i > half {
+					u = rods[1][i-half]
+				}
+				worst = math.Max(worst, math.Abs(u-v))
+			}
+			return worst, fmt.Sprintf("junction %.6f, single rod %.6f", rods[0][half], res.Final[half]), nil
+		},
+	}
+}
+
+// Т-образный стык: три стержня разной длины и α из одного узла к концам
+// с температурами 1, 0 и 0.5. BTCS с крупным шагом до t = 50 против
+// стационара mathutils.Network.Steady: наибольшее отличие температур
+// узлов не больше tol
+func networkSteadyCase(tol float64) VerifyCase {
+	net := mathutils.Network{
+		Nodes: []mathutils.NetworkNode{{Name: "J"}, {Name: "A", Temperature: networkTemp(1)}, {Name: "B", Temperature: networkTemp(0)}, {Name: "C", Temperature: networkTemp(0.5)}},
+		Segments: []mathutils.Segment{
+			{Name: "a", From: "A", To: "J", Length: 1, Nx: 20, Alpha: 1},
+			{Name: "b", From: "J", To: "B", Length: 0.5, Nx: 10, Alpha: 0.2},
+			{Name: "c", From: "J", To: "C", Length: 0.8, Nx: 8, Alpha: 3},
+		},
+	}
+	return VerifyCase{
+		Name:     "T-junction steady state against Kirchhoff",
+		Quantity: "max |T node − T steady|",
+		Compare:  VerifyAtMost,
+		Expected: tol,
+		Measure: func(ctx context.Context, _ Method) (float64, string, error) {
+			steady, err := net.Steady()
+			if err != nil {
+				return math.NaN(), "", err
+			}
+			u0 := make([][]float64, len(net.Segments))
+			for s, seg := range net.Segments {
+				u0[s] = make([]float64, seg.Nx+1)
+			}
+			rods, err := StreamNetworkFrom(net, u0, 100, 0.5, 1, nil)
+			if err != nil {
+				return math.NaN(), "", err
+			}
+			temps := NetworkNodeTemperatures(net, rods)
+			var worst float64
+			for i := range temps {
+				worst = math.Max(worst, math.Abs(temps[i]-steady[i]))
+			}
+			return worst, fmt.Sprintf("junction %.6f, Kirchhoff %.6f", temps[0], steady[0]), nil
+		},
+	}
+}
+
+// Звезда из трёх стержней со свободными (теплоизолированными) концами,
+// тепло в одном из них: CN сохраняет NetworkHeat, наибольший уход
+// относительно начального не больше tol
+func networkHeatCase(tol float64) VerifyCase {
+	net := mathutils.Network{
+		Nodes: []mathutils.NetworkNode{{Name: "J"}, {Name: "A"}, {Name: "B"}, {Name: "C"}},
+		Segments: []mathutils.Segment{
+			{Name: "a", From: "A", To: "J", Length: 1, Nx: 20, Alpha: 1},
+			{Name: "b", From: "J", To: "B", Length: 0.5, Nx: 10, Alpha: 0.2},
+			{Name: "c", From: "C", To: "J", Length: 0.8, Nx: 8, Alpha: 3},
+		},
+	}
+	return VerifyCase{
+		Name:     "insulated star heat conservation CN",
+		Quantity: "max |Q − Q0| / Q0",
+		Compare:  VerifyAtMost,
+		Expected: tol,
+		Measure: func(ctx context.Context, _ Method) (float64, string, error) {
+			u0 := make([][]float64, len(net.Segments))
+			for s, seg := range net.Segments {
+				u0[s] = make([]float64, seg.Nx+1)
+			}
+			u0[0] = PulseProfile(20, 0.05, 0.2, 0.6)
+			var heat0, drift float64
+			var spread [2]float64
+			_, err := StreamNetworkFrom(net, u0, 2000, 0.001, 0.5, func(n int, rods [][]float64) error {
+				heat := NetworkHeat(net, rods)
+				if n == 0 {
+					heat0 = heat
+				}
+				drift = math.Max(drift, math.Abs(heat-heat0)/heat0)
+				temps := NetworkNodeTemperatures(net, rods)
+				spread = [2]float64{slices.Min(temps), slices.Max(temps)}
+				return nil
+			})
+			if err != nil {
+				return math.NaN(), "", err
+			}
+			return drift, fmt.Sprintf("node temperatures %.4f…%.4f, uniform limit %.4f", spread[0], spread[1], heat0/2.3), nil
+		},
+	}
+}
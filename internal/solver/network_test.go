@@ -0,0 +1,187 @@
+package solver
+
+import (
+	"math"
+	"slices"
+	"testing"
+
+	"heat-solver/internal/mathutils"
+)
+
+// Стержень из sin(πx), разрезанный на стержни со свободными узлами в
+// местах разреза, против одного стержня на каждом слое: узел разреза с
+// ячейкой из двух половинок — обычный узел схемы. Разрез посередине,
+// несимметричный, стержень, развёрнутый от To к From, и стержни в один
+// интервал (без внутренних узлов)
+func TestNetworkSplit(t *testing.T) {
+	const nx, nt, dx = 20, 40, 0.05
+	tests := []struct {
+		name   string
+		method string
+		theta  float64
+		dt     float64
+		cuts   []int
+		flip   bool
+	}{
+		{"CN in half", "CN", 0.5, 0.01, []int{10}, false},
+		{"BTCS in half", "BTCS", 1, 0.01, []int{10}, false},
+		{"FTCS in half", "FTCS", 0, 0.001, []int{10}, false},
+		{"CN 7 and 13", "CN", 0.5, 0.01, []int{7}, false},
+		{"BTCS reversed segment", "BTCS", 1, 0.01, []int{7}, true},
+		{"CN three cuts with one-interval segments", "CN", 0.5, 0.01, []int{1, 2, 19}, false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			bounds := append(append([]int{0}, tc.cuts...), nx)
+			net := mathutils.Network{Nodes: []mathutils.NetworkNode{{Name: "N0", Temperature: networkTemp(0)}}}
+			var u0 [][]float64
+			sine := SineProfile(nx, dx)
+			for s := 1; s < len(bounds); s++ {
+				node := mathutils.NetworkNode{Name: "N" + string(rune('0'+s))}
+				if s == len(bounds)-1 {
+					node.Temperature = networkTemp(0)
+				}
+				net.Nodes = append(net.Nodes, node)
+				seg := mathutils.Segment{Name: "S" + string(rune('0'+s)), From: net.Nodes[s-1].Name, To: node.Name,
+					Length: float64(bounds[s]-bounds[s-1]) * dx, Nx: bounds[s] - bounds[s-1], Alpha: 1}
+				profile := append([]float64(nil), sine[bounds[s-1]:bounds[s]+1]...)
+				if tc.flip && s == 1 {
+					seg.From, seg.To = seg.To, seg.From
+					slices.Reverse(profile)
+				}
+				net.Segments = append(net.Segments, seg)
+				u0 = append(u0, profile)
+			}
+			want := stencilReference(tc.method, sine, nt, dx, tc.dt)
+			var worst float64
+			_, err := StreamNetworkFrom(net, u0, nt, tc.dt, tc.theta, func(n int, rods [][]float64) error {
+				for s, rod := range rods {
+					row := slices.Clone(rod)
+					if tc.flip && s == 0 {
+						slices.Reverse(row)
+					}
+					for i, v := range row {
+						worst = math.Max(worst, math.Abs(v-want[n][bounds[s]+i]))
+					}
+				}
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if worst > 1e-13 {
+				t.Errorf("differs from a single rod by %.3g", worst)
+			}
+		})
+	}
+}
+
+// Т-образный стык с разными длинами и α выходит на стационар Кирхгофа и
+// в узле, и на стержнях (линейные профили между температурами узлов):
+// BTCS с крупным шагом, CN — с мелким, чтобы не оставались колебания
+// жёстких мод
+func TestNetworkSteady(t *testing.T) {
+	net := mathutils.Network{
+		Nodes: []mathutils.NetworkNode{{Name: "J"}, {Name: "A", Temperature: networkTemp(1)}, {Name: "B", Temperature: networkTemp(0)}, {Name: "C", Temperature: networkTemp(0.5)}},
+		Segments: []mathutils.Segment{
+			{Name: "a", From: "A", To: "J", Length: 1, Nx: 20, Alpha: 1},
+			{Name: "b", From: "J", To: "B", Length: 0.5, Nx: 10, Alpha: 0.2},
+			{Name: "c", From: "J", To: "C", Length: 0.8, Nx: 8, Alpha: 3},
+		},
+	}
+	steady, err := net.Steady()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		theta, dt float64
+		nt        int
+	}{{1, 0.5, 100}, {0.5, 0.01, 5000}} {
+		u0 := make([][]float64, len(net.Segments))
+		for s, seg := range net.Segments {
+			u0[s] = make([]float64, seg.Nx+1)
+		}
+		rods, err := StreamNetworkFrom(net, u0, tc.nt, tc.dt, tc.theta, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		temps := NetworkNodeTemperatures(net, rods)
+		for i := range temps {
+			if math.Abs(temps[i]-steady[i]) > 1e-9 {
+				t.Errorf("θ=%g: node %s at %v, steady %v", tc.theta, net.Nodes[i].Name, temps[i], steady[i])
+			}
+		}
+		for s, seg := range net.Segments {
+			from, to := steady[net.NodeIndex(seg.From)], steady[net.NodeIndex(seg.To)]
+			for i, v := range rods[s] {
+				if want := from + (to-from)*float64(i)/float64(seg.Nx); math.Abs(v-want) > 1e-9 {
+					t.Errorf("θ=%g: segment %s u[%d] = %v, want %v", tc.theta, seg.Name, i, v, want)
+					break
+				}
+			}
+		}
+	}
+}
+
+// Звезда со свободными концами: тепло сохраняется и CN, и BTCS, а
+// температуры выравниваются к Q/ΣL
+func TestNetworkHeat(t *testing.T) {
+	net := mathutils.Network{
+		Nodes: []mathutils.NetworkNode{{Name: "J"}, {Name: "A"}, {Name: "B"}, {Name: "C"}},
+		Segments: []mathutils.Segment{
+			{Name: "a", From: "A", To: "J", Length: 1, Nx: 20, Alpha: 1},
+			{Name: "b", From: "J", To: "B", Length: 0.5, Nx: 10, Alpha: 0.2},
+			{Name: "c", From: "C", To: "J", Length: 0.8, Nx: 8, Alpha: 3},
+		},
+	}
+	for _, theta := range []float64{0.5, 1} {
+		u0 := make([][]float64, len(net.Segments))
+		for s, seg := range net.Segments {
+			u0[s] = make([]float64, seg.Nx+1)
+		}
+		u0[0] = PulseProfile(20, 0.05, 0.2, 0.6)
+		heat0 := NetworkHeat(net, u0)
+		var drift float64
+		rods, err := StreamNetworkFrom(net, u0, 400, 0.05, theta, func(_ int, rods [][]float64) error {
+			drift = math.Max(drift, math.Abs(NetworkHeat(net, rods)-heat0)/heat0)
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if drift > 1e-12 {
+			t.Errorf("θ=%g: heat drift %.3g", theta, drift)
+		}
+		uniform := heat0 / 2.3
+		for _, temp := range NetworkNodeTemperatures(net, rods) {
+			if math.Abs(temp-uniform) > 1e-6 {
+				t.Errorf("θ=%g: node temperatures %v, uniform limit %v", theta, NetworkNodeTemperatures(net, rods), uniform)
+				break
+			}
+		}
+	}
+}
+
+func TestNetworkRejects(t *testing.T) {
+	net := mathutils.Network{
+		Nodes:    []mathutils.NetworkNode{{Name: "A", Temperature: networkTemp(0)}, {Name: "B"}},
+		Segments: []mathutils.Segment{{Name: "s", From: "A", To: "B", Length: 1, Nx: 4, Alpha: 1}},
+	}
+	if _, err := StreamNetworkFrom(net, [][]float64{make([]float64, 5)}, 1, 0.01, 1, nil); err != nil {
+		t.Fatalf("valid network rejected: %v", err)
+	}
+	tests := []struct {
+		name string
+		net  mathutils.Network
+		u0   [][]float64
+	}{
+		{"invalid network", mathutils.Network{Nodes: net.Nodes}, nil},
+		{"profile count", net, [][]float64{make([]float64, 5), make([]float64, 5)}},
+		{"profile length", net, [][]float64{make([]float64, 4)}},
+	}
+	for _, tc := range tests {
+		if _, err := StreamNetworkFrom(tc.net, tc.u0, 1, 0.01, 1, nil); err == nil {
+			t.Errorf("%s accepted", tc.name)
+		}
+	}
+}
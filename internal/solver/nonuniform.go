@@ -0,0 +1,142 @@
+package solver
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+
+	"heat-solver/internal/mathutils"
+)
+
+// θ-схема на неравномерной сетке: узлы x[0..nx] возрастают, края x[0] и
+// x[nx] — нулевые значения (sin(πx) в начальном слое). Вторая разность
+// считается по шагам h_{i−1} = x_i − x_{i−1} и h_i = x_{i+1} − x_i,
+//
+//	δ²u_i = 2/(h_{i−1} + h_i)·[(u_{i+1} − u_i)/h_i − (u_i − u_{i−1})/h_{i−1}],
+//
+// и на равномерной сетке совпадает с обычной. Формально она первого
+// порядка при h_{i−1} ≠ h_i, но на гладко растянутой сетке
+// (mathutils.StretchedNodes) разность соседних шагов — O(h²), и порядок
+// остаётся вторым. Системы BTCS и CN по-прежнему трёхдиагональные
+func SolveThetaNodes(x []float64, nt int, dt, alpha, theta float64) [][]float64 {
+	u0 := make([]float64, len(x))
+	for i, xi := range x {
+		u0[i] = mathutils.InitialCondition(xi)
+	}
+	u := newGrid(nt+1, len(x))
+	thetaNodes(u0, x, nt, dt, alpha, theta, u, nil)
+	return u
+}
+
+// θ-схема на узлах x с заданным начальным слоем u0 и хранением двух
+// слоёв; каждый слой передаётся в emit
+func StreamThetaNodesFrom(u0, x []float64, nt int, dt, alpha, theta float64, emit EmitFunc) ([]float64, error) {
+	u := newGrid(2, len(u0))
+	if err := thetaNodes(u0, x, nt, dt, alpha, theta, u, emit); err != nil {
+		return nil, err
+	}
+	return level(u, nt), nil
+}
+
+// Веса соседей wl, wr в δ²u_i на каждом внутреннем узле считаются один
+// раз: матрица, как и у thetaScheme, постоянна. Для условия устойчивости
+// явной части роль r играет α·dt·max 1/(h_{i−1}·h_i): самые мелкие шаги
+// у краёв растянутой сетки
+func thetaNodes(u0, x []float64, nt int, dt, alpha, theta float64, u [][]float64, emit EmitFunc) error {
+	nx := len(x) - 1
+	m := nx - 1
+	ws := pool64.get(2 * m)
+	defer pool64.put(ws)
+	wl, wr := ws[:m:m], ws[m:]
+	var rEff float64
+	for i := 1; i < nx; i++ {
+		hl, hr := x[i]-x[i-1], x[i+1]-x[i]
+		wl[i-1] = 2 / (hl * (hl + hr))
+		wr[i-1] = 2 / (hr * (hl + hr))
+		rEff = max(rEff, alpha*dt/(hl*hr))
+	}
+	name := thetaName(theta) + " on a non-uniform grid"
+	warnUnstable(name, rEff, thetaMaxR(theta))
+	slog.Info("Starting "+name, "nx", nx, "nt", nt, "dt", dt, "r_eff", rEff)
+
+	if err := start(u, u0, emit); err != nil {
+		return err
+	}
+
+	implicit, explicit := theta*alpha*dt, (1-theta)*alpha*dt
+	a, b, c, d, sys := workspace(m)
+	defer pool64.put(sys)
+	for i := range b {
+		a[i] = -implicit * wl[i]
+		b[i] = 1 + implicit*(wl[i]+wr[i])
+		c[i] = -implicit * wr[i]
+	}
+	var tri tridiagSolver
+	if theta > 0 {
+		tri = newTridiag(a, b, c)
+		defer tri.close()
+	}
+
+	for n := 0; n < nt; n++ {
+		cur, next := level(u, n), level(u, n+1)
+		next[0], next[nx] = 0, 0
+		for i := range d {
+			d[i] = cur[i+1] + explicit*(wl[i]*cur[i]-(wl[i]+wr[i])*cur[i+1]+wr[i]*cur[i+2])
+		}
+		if tri == nil {
+			copy(next[1:nx], d)
+		} else {
+			d[0] += implicit * wl[0] * next[0]
+			d[m-1] += implicit * wr[m-1] * next[nx]
+			tri.solve(d, next[1:nx])
+			if err := tridiagErr(tri); err != nil {
+				return err
+			}
+		}
+		if err := emitLevel(emit, n+1, next); err != nil {
+			return err
+		}
+	}
+
+	slog.Info(name + " finished successfully")
+	return nil
+}
+
+// Проверочные случаи неравномерной сетки; в Verify — под именем STRETCHED
+var verificationStretched = []VerifyCase{
+	// CN, dx и dt вдвое вместе: второй порядок по обоим
+	stretchedOrderCase(2, 0.5, 16, 0.01, 0.1, 2, 0.15),
+	// FTCS при r_eff от 0.14 до 0.29: ошибка по dt мала, виден порядок по x
+	stretchedOrderCase(2, 0, 16, 1.0/32768, 0.05, 2, 0.15),
+}
+
+// Наблюдаемый порядок L∞ на sin(πx) на сетке StretchedNodes(nx, factor)
+// за два измельчения nx вдвое; dt уменьшается вчетверо при θ = 0 (r_eff
+// растёт только из-за сгущения у краёв) и вдвое при θ > 0
+func stretchedOrderCase(factor, theta float64, nx int, dt, tmax, order, tol float64) VerifyCase {
+	return VerifyCase{
+		Name:      fmt.Sprintf("%s stretch=%g order from nx=%d dt=%g", thetaName(theta), factor, nx, dt),
+		Quantity:  "observed order",
+		Compare:   VerifyNear,
+		Expected:  order,
+		Tolerance: tol,
+		Measure: func(ctx context.Context, m Method) (float64, string, error) {
+			var errs [3]float64
+			step := dt
+			for k := range errs {
+				x := mathutils.StretchedNodes(nx<<k, factor)
+				nt := int(math.Round(tmax / step))
+				u := SolveThetaNodes(x, nt, step, 1, theta)
+				_, errs[k] = mathutils.NodeErrors(u[nt], x, float64(nt)*step, mathutils.Analytical{Alpha: 1})
+				if theta == 0 {
+					step /= 4
+				} else {
+					step /= 2
+				}
+			}
+			first, second := math.Log2(errs[0]/errs[1]), math.Log2(errs[1]/errs[2])
+			return second, fmt.Sprintf("errors %.3g, %.3g, %.3g; orders %.3f, %.3f", errs[0], errs[1], errs[2], first, second), nil
+		},
+	}
+}
@@ -0,0 +1,110 @@
+package solver
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+
+	"heat-solver/internal/mathutils"
+)
+
+// На равномерных узлах схема совпадает с SolveTheta до округления весов
+// 2/(h·2h) против 1/dx²
+func TestThetaNodesUniform(t *testing.T) {
+	const nx, nt = 40, 30
+	dx := 1.0 / nx
+	for _, theta := range []float64{0, 0.5, 1} {
+		want := SolveTheta(nx, nt, dx, 0.4*dx*dx, 1, theta)
+		got := SolveThetaNodes(mathutils.UniformNodes(nx), nt, 0.4*dx*dx, 1, theta)
+		for n := range want {
+			for i := range want[n] {
+				if math.Abs(got[n][i]-want[n][i]) > 1e-13 {
+					t.Fatalf("θ=%g, level %d, node %d: %v, SolveTheta %v", theta, n, i, got[n][i], want[n][i])
+				}
+			}
+		}
+	}
+}
+
+// На гладко растянутой сетке (β = 2) порядок остаётся вторым и по L2, и
+// по L∞: CN при dt вдвое меньше с каждым nx, FTCS — вчетверо
+func TestThetaNodesOrder(t *testing.T) {
+	tests := []struct {
+		theta   float64
+		dt      float64
+		dtScale float64
+		tmax    float64
+	}{
+		{0.5, 0.01, 2, 0.1},
+		{1, 0.0001, 4, 0.1},
+		{0, 1.0 / 32768, 4, 0.05},
+	}
+	exact := mathutils.Analytical{Alpha: 1}
+	for _, tc := range tests {
+		t.Run(thetaName(tc.theta), func(t *testing.T) {
+			var prevL2, prevLinf float64
+			dt := tc.dt
+			for k, nx := range []int{16, 32, 64} {
+				x := mathutils.StretchedNodes(nx, 2)
+				u0 := make([]float64, nx+1)
+				for i, xi := range x {
+					u0[i] = mathutils.InitialCondition(xi)
+				}
+				nt := int(math.Round(tc.tmax / dt))
+				last, err := StreamThetaNodesFrom(u0, x, nt, dt, 1, tc.theta, nil)
+				if err != nil {
+					t.Fatal(err)
+				}
+				l2, linf := mathutils.NodeErrors(last, x, float64(nt)*dt, exact)
+				if k > 0 {
+					if order := math.Log2(prevL2 / l2); math.Abs(order-2) > 0.2 {
+						t.Errorf("nx=%d: L2 %.3g → %.3g, observed order %.2f", nx, prevL2, l2, order)
+					}
+					if order := math.Log2(prevLinf / linf); math.Abs(order-2) > 0.2 {
+						t.Errorf("nx=%d: L∞ %.3g → %.3g, observed order %.2f", nx, prevLinf, linf, order)
+					}
+				}
+				prevL2, prevLinf = l2, linf
+				dt /= tc.dtScale
+			}
+		})
+	}
+}
+
+// Предел явной схемы проверяется по самым мелким шагам у краёв,
+// r_eff = α·dt·max 1/(h_{i−1}·h_i) ≤ ½: это достаточное условие (по
+// Гершгорину), и при β = 2 схема на деле расходится только после
+// r_eff ≈ 0.7. Предупреждение — сразу за ½
+func TestThetaNodesExplicitLimit(t *testing.T) {
+	const nx = 32
+	x := mathutils.StretchedNodes(nx, 2)
+	hh := 1.0
+	for i := 1; i < nx; i++ {
+		hh = min(hh, (x[i]-x[i-1])*(x[i+1]-x[i]))
+	}
+	for _, tc := range []struct {
+		r        float64
+		warns    bool
+		unstable bool
+	}{
+		{0.45, false, false},
+		{0.6, true, false},
+		{0.8, true, true},
+	} {
+		t.Run(fmt.Sprintf("r_eff=%g", tc.r), func(t *testing.T) {
+			warnings := captureWarnings(t)
+			u := SolveThetaNodes(x, 3000, tc.r*hh, 1, 0)
+			var peak float64
+			for _, v := range u[len(u)-1] {
+				peak = max(peak, math.Abs(v))
+			}
+			if grew := !(peak <= 1); grew != tc.unstable {
+				t.Errorf("max |u| = %.3g, unstable: %v", peak, tc.unstable)
+			}
+			if warned := strings.Contains(warnings.String(), "may be unstable"); warned != tc.warns {
+				t.Errorf("warning %v, want %v", warned, tc.warns)
+			}
+		})
+	}
+}
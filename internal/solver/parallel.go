@@ -0,0 +1,82 @@
+package solver
+
+import (
+	"runtime"
+	"sync/atomic"
+)
+
+// Наибольшее число потоков одного расчёта; 0 — GOMAXPROCS
+var threads atomic.Int64
+
+// Число потоков для обновления слоя FTCS и параллельной прогонки;
+// n ≤ 0 — GOMAXPROCS. Действует на расчёты, начатые после вызова
+func SetThreads(n int) {
+	threads.Store(int64(max(0, n)))
+}
+
+func Threads() int {
+	if n := int(threads.Load()); n > 0 {
+		return n
+	}
+	return runtime.GOMAXPROCS(0)
+}
+
+// Пул потоков, которые на каждом шаге обрабатывают свои непересекающиеся
+// фрагменты [lo, hi). Горутины запускаются один раз на расчёт; фрагмент 0
+// выполняет вызывающая горутина
+type chunkPool struct {
+	bounds []int
+	work   func(k, lo, hi int)
+	start  []chan struct{}
+	done   chan struct{}
+}
+
+// Пул для узлов [lo, hi) с фрагментами не меньше minSize или nil, если
+// фрагментов меньше двух: тогда выгоднее последовательный расчёт
+func newChunkPool(lo, hi, minSize int) *chunkPool {
+	k := min(Threads(), (hi-lo)/minSize)
+	if k < 2 {
+		return nil
+	}
+	p := &chunkPool{
+		bounds: make([]int, k+1),
+		start:  make([]chan struct{}, k-1),
+		done:   make(chan struct{}, k-1),
+	}
+	for j := range p.bounds {
+		p.bounds[j] = lo + (hi-lo)*j/k
+	}
+	for j := range p.start {
+		p.start[j] = make(chan struct{})
+		go func(ch chan struct{}, k int) {
+			for range ch {
+				p.work(k, p.bounds[k], p.bounds[k+1])
+				p.done <- struct{}{}
+			}
+		}(p.start[j], j+1)
+	}
+	return p
+}
+
+// Число фрагментов
+func (p *chunkPool) size() int {
+	return len(p.bounds) - 1
+}
+
+// Вызов work для всех фрагментов (k — номер фрагмента) с ожиданием завершения
+func (p *chunkPool) run(work func(k, lo, hi int)) {
+	p.work = work
+	for _, ch := range p.start {
+		ch <- struct{}{}
+	}
+	work(0, p.bounds[0], p.bounds[1])
+	for range p.start {
+		<-p.done
+	}
+}
+
+func (p *chunkPool) close() {
+	for _, ch := range p.start {
+		close(ch)
+	}
+}
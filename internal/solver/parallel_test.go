@@ -0,0 +1,252 @@
+package solver
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"testing"
+)
+
+// Число потоков на время теста
+func withThreads(t testing.TB, n int) {
+	t.Helper()
+	prev := int(threads.Load())
+	SetThreads(n)
+	t.Cleanup(func() { SetThreads(prev) })
+}
+
+// Фрагменты пула покрывают диапазон без пересечений и не меньше minSize
+func TestChunkPool(t *testing.T) {
+	withThreads(t, 8)
+	if p := newChunkPool(1, 100, 64); p != nil {
+		p.close()
+		t.Error("pool for a range smaller than two chunks, want the serial path")
+	}
+	p := newChunkPool(1, 1000, 100)
+	if p == nil {
+		t.Fatal("no pool for 10 chunks")
+	}
+	defer p.close()
+	if p.size() != 8 {
+		t.Errorf("%d chunks, want one per thread", p.size())
+	}
+	hits := make([]int, 1000)
+	for range 3 {
+		p.run(func(k, lo, hi int) {
+			if hi-lo < 100 {
+				t.Errorf("chunk %d [%d, %d) smaller than 100", k, lo, hi)
+			}
+			for i := lo; i < hi; i++ {
+				hits[i]++
+			}
+		})
+	}
+	for i, h := range hits {
+		if want := 3 * min(i, 1); h != want {
+			t.Fatalf("node %d updated %d times, want %d", i, h, want)
+		}
+	}
+}
+
+// Параллельный FTCS побитно совпадает с последовательным
+func TestParallelFTCSBitIdentical(t *testing.T) {
+	const nx, nt = 1 << 16, 20
+	dx := 1.0 / nx
+	dt := 0.4 * dx * dx
+
+	withThreads(t, 1)
+	serial := SolveFTCS(nx, nt, dx, dt)
+	for _, n := range []int{2, 3, 4} {
+		t.Run(fmt.Sprintf("%d threads", n), func(t *testing.T) {
+			withThreads(t, n)
+			if p := newChunkPool(1, nx, ftcsMinChunk); p == nil {
+				t.Fatal("grid too small for the parallel path")
+			} else {
+				p.close()
+			}
+			parallel := SolveFTCS(nx, nt, dx, dt)
+			for k := range serial {
+				if !slices.Equal(parallel[k], serial[k]) {
+					t.Fatalf("level %d differs from the serial run", k)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkFTCSThreads(b *testing.B) {
+	const nx, nt = 100000, 100
+	dx := 1.0 / nx
+	u0 := SineProfile(nx, dx)
+	for _, n := range []int{1, 2, 4, 8} {
+		b.Run(fmt.Sprintf("threads=%d", n), func(b *testing.B) {
+			withThreads(b, n)
+			for b.Loop() {
+				StreamFTCSFrom(u0, nt, dx, 0.4*dx*dx, nil)
+			}
+		})
+	}
+}
+
+// Параллельный 2D FTCS (плитки по потокам) побитно совпадает с
+// последовательным
+func TestParallel2DFTCSBitIdentical(t *testing.T) {
+	const nx, ny, nt = 255, 256, 10
+	dx, dy := 1.0/nx, 1.0/ny
+	dt := 0.2 * dx * dx
+
+	withThreads(t, 1)
+	serial := Solve2DFTCS(nx, ny, nt, dx, dy, dt, 1)
+	for _, n := range []int{2, 3, 4} {
+		t.Run(fmt.Sprintf("%d threads", n), func(t *testing.T) {
+			withThreads(t, n)
+			if p := newChunkPool(0, lineBlocks(nx)*lineBlocks(ny), ftcsMinChunk/(lineTile*lineTile)); p == nil {
+				t.Fatal("grid too small for the parallel path")
+			} else {
+				p.close()
+			}
+			parallel := Solve2DFTCS(nx, ny, nt, dx, dy, dt, 1)
+			for k := range serial {
+				if !slices.Equal(parallel[k], serial[k]) {
+					t.Fatalf("level %d differs from the serial run", k)
+				}
+			}
+		})
+	}
+}
+
+// Шаги 2D FTCS на сетке 1024² по числу потоков
+func Benchmark2DFTCSThreads(b *testing.B) {
+	const n, nt = 1024, 10
+	d := 1.0 / n
+	u0 := SineProfile2D(n, n, d, d)
+	for _, threads := range []int{1, 2, 4, 8} {
+		b.Run(fmt.Sprintf("threads=%d", threads), func(b *testing.B) {
+			withThreads(b, threads)
+			for b.Loop() {
+				Stream2DFTCSFrom(u0, n, n, nt, d, d, 0.2*d*d, 1, nil)
+			}
+		})
+	}
+}
+
+// Столбцы по одному с шагом nx+1 по слою — прогонки по y до разбиения на
+// блоки; эталон для lineSolver.sweepY
+func sweepYStrided(s *lineSolver, src, dst []float64, explicit float64) {
+	nx, ny, w := s.nx, s.ny, s.nx+1
+	rhs, line := make([]float64, ny-1), make([]float64, ny-1)
+	for i := 1; i < nx; i++ {
+		for j := 1; j < ny; j++ {
+			c := j*w + i
+			rhs[j-1] = src[c] + explicit*(src[c-1]-2*src[c]+src[c+1])
+		}
+		rhs[0] += s.hy * dst[i]
+		rhs[ny-2] += s.hy * dst[ny*w+i]
+		s.ty.solve(rhs, line)
+		for j := 1; j < ny; j++ {
+			dst[j*w+i] = line[j-1]
+		}
+	}
+}
+
+// Прогонка по блокам столбцов побитно совпадает с прогонкой по одному
+// столбцу, в том числе когда nx−1 не кратно lineTile и по краям
+// заданы ненулевые значения
+func TestLineSweepYBlocked(t *testing.T) {
+	withThreads(t, 1)
+	for _, n := range [][2]int{{8, 8}, {65, 40}, {130, 129}, {200, 3}} {
+		nx, ny := n[0], n[1]
+		t.Run(fmt.Sprintf("%dx%d", nx, ny), func(t *testing.T) {
+			src := make([]float64, (nx+1)*(ny+1))
+			for c := range src {
+				src[c] = math.Sin(float64(c)*0.37) + float64(c%7)
+			}
+			want, got := slices.Clone(src), slices.Clone(src)
+			s := newLineSolver(nx, ny, 0.8, 1.3)
+			defer s.close()
+			sweepYStrided(s, src, want, 0.4)
+			s.sweepY(src, got, 0.4)
+			if !slices.Equal(got, want) {
+				t.Error("blocked sweep differs from the column-by-column one")
+			}
+		})
+	}
+}
+
+// Параллельные ADI и LOD (строки и блоки столбцов по потокам) побитно
+// совпадают с последовательными
+func TestParallelLinesBitIdentical(t *testing.T) {
+	const nx, ny, nt = 300, 257, 4
+	dx, dy := 1.0/nx, 1.0/ny
+	u0 := make([]float64, (nx+1)*(ny+1))
+	for c := range u0 {
+		u0[c] = math.Sin(float64(c) * 0.37)
+	}
+	for _, tc := range []struct {
+		name   string
+		stream Stream2DFunc
+	}{
+		{"ADI", Stream2DADIFrom},
+		{"LOD", Stream2DLODFrom},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			withThreads(t, 1)
+			serial, err := tc.stream(u0, nx, ny, nt, dx, dy, 0.01, 1, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, n := range []int{2, 3, 8} {
+				withThreads(t, n)
+				s := newLineSolver(nx, ny, 1, 1)
+				if s.rows == nil || s.blocks == nil {
+					t.Fatalf("%d threads: grid too small for the parallel path", n)
+				}
+				s.close()
+				parallel, err := tc.stream(u0, nx, ny, nt, dx, dy, 0.01, 1, nil)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !slices.Equal(parallel, serial) {
+					t.Errorf("%d threads: last level differs from the serial run", n)
+				}
+			}
+		})
+	}
+}
+
+// Шаги ADI на сетке 1024² по числу потоков
+func Benchmark2DADIThreads(b *testing.B) {
+	const n, nt = 1024, 10
+	d := 1.0 / n
+	u0 := SineProfile2D(n, n, d, d)
+	for _, threads := range []int{1, 2, 4, 8} {
+		b.Run(fmt.Sprintf("threads=%d", threads), func(b *testing.B) {
+			withThreads(b, threads)
+			for b.Loop() {
+				Stream2DADIFrom(u0, n, n, nt, d, d, 0.01, 1, nil)
+			}
+		})
+	}
+}
+
+// Прогонка по y на сетке 1024² в одном потоке: по блокам столбцов против
+// столбцов по одному
+func BenchmarkLineSweepY(b *testing.B) {
+	const n = 1024
+	d := 1.0 / n
+	src := SineProfile2D(n, n, d, d)
+	dst := make([]float64, len(src))
+	withThreads(b, 1)
+	s := newLineSolver(n, n, 5, 5)
+	defer s.close()
+	b.Run("blocked", func(b *testing.B) {
+		for b.Loop() {
+			s.sweepY(src, dst, 5)
+		}
+	})
+	b.Run("strided", func(b *testing.B) {
+		for b.Loop() {
+			sweepYStrided(s, src, dst, 5)
+		}
+	})
+}
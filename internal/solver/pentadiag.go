@@ -0,0 +1,86 @@
+package solver
+
+// LU-разложение пятидиагональной матрицы без выбора ведущего элемента,
+// вычисленное один раз, как у thomas. Диагонали: e — вторая поддиагональ
+// (e[0], e[1] не используются), a — поддиагональ, b — главная, c —
+// наддиагональ, f — вторая наддиагональ (последние элементы c и f не
+// используются). L — единичная с двумя поддиагоналями (al, be), U —
+// диагональ 1/den и наддиагонали (u1, u2). Без выбора ведущего элемента
+// достаточно симметричной положительно определённой матрицы, как у
+// широкого шаблона (I − θr·D₄)
+type penta struct {
+	al, be, u1, u2, den []float64
+	// Общий буфер из пула
+	buf []float64
+}
+
+func newPenta(n int) *penta {
+	buf := pool64.get(5 * n)
+	return &penta{
+		al: buf[:n:n], be: buf[n : 2*n : 2*n], u1: buf[2*n : 3*n : 3*n],
+		u2: buf[3*n : 4*n : 4*n], den: buf[4*n:], buf: buf,
+	}
+}
+
+// Возврат рабочих массивов в пул; после close разложение не используется
+func (p *penta) close() {
+	pool64.put(p.buf)
+	p.buf, p.al, p.be, p.u1, p.u2, p.den = nil, nil, nil, nil, nil, nil
+}
+
+func (p *penta) factor(e, a, b, c, f []float64) {
+	n := len(b)
+	for i := 0; i < n; i++ {
+		var al, be float64
+		piv := b[i]
+		if i >= 2 {
+			al = e[i] * p.den[i-2]
+			piv -= al * p.u2[i-2]
+		}
+		if i >= 1 {
+			sub := a[i]
+			if i >= 2 {
+				sub -= al * p.u1[i-2]
+			}
+			be = sub * p.den[i-1]
+			piv -= be * p.u1[i-1]
+		}
+		p.al[i], p.be[i] = al, be
+		p.den[i] = 1 / piv
+		if i+1 < n {
+			p.u1[i] = c[i]
+			if i >= 1 {
+				p.u1[i] -= be * p.u2[i-1]
+			}
+		}
+		if i+2 < n {
+			p.u2[i] = f[i]
+		}
+	}
+}
+
+// Решение с правой частью d в x (len(x) = len(d)); x не должен
+// пересекаться с d
+func (p *penta) solve(d, x []float64) {
+	n := len(d)
+	for i := 0; i < n; i++ {
+		y := d[i]
+		if i >= 1 {
+			y -= p.be[i] * x[i-1]
+		}
+		if i >= 2 {
+			y -= p.al[i] * x[i-2]
+		}
+		x[i] = y
+	}
+	for i := n - 1; i >= 0; i-- {
+		v := x[i]
+		if i+1 < n {
+			v -= p.u1[i] * x[i+1]
+		}
+		if i+2 < n {
+			v -= p.u2[i] * x[i+2]
+		}
+		x[i] = v * p.den[i]
+	}
+}
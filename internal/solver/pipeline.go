@@ -0,0 +1,108 @@
+package solver
+
+import "sync"
+
+// Конвейер между расчётом и выводом: Emit копирует слой в один из depth
+// буферов и передаёт его горутине-потребителю, пока схема считает
+// следующие слои. Так запись (форматирование, диск, сеть) идёт параллельно
+// со счётом, и общее время близко к max(счёт, запись), а не к их сумме.
+// Схема ждёт, только если все буферы ещё не записаны
+type Pipeline struct {
+	consume EmitFunc
+	free    chan []float64
+	full    chan pipelineLevel
+	done    chan struct{}
+	wg      sync.WaitGroup
+
+	mu     sync.Mutex
+	err    error
+	closed bool
+}
+
+type pipelineLevel struct {
+	n   int
+	row []float64
+}
+
+// Конвейер на depth слоёв по width значений; consume вызывается в
+// отдельной горутине для каждого слоя по порядку. Срез row в consume
+// действителен до возврата из него
+func NewPipeline(depth, width int, consume EmitFunc) *Pipeline {
+	depth = max(depth, 1)
+	p := &Pipeline{
+		consume: consume,
+		free:    make(chan []float64, depth),
+		full:    make(chan pipelineLevel, depth),
+		done:    make(chan struct{}),
+	}
+	for range depth {
+		p.free <- make([]float64, width)
+	}
+	p.wg.Add(1)
+	go p.run()
+	return p
+}
+
+func (p *Pipeline) run() {
+	defer p.wg.Done()
+	for lv := range p.full {
+		select {
+		case <-p.done:
+			// После ошибки оставшиеся слои только возвращаются в очередь
+		default:
+			if err := p.consume(lv.n, lv.row); err != nil {
+				p.fail(err)
+			}
+		}
+		p.free <- lv.row
+	}
+}
+
+// Первая ошибка любой из сторон останавливает другую; последующие
+// ошибки отбрасываются
+func (p *Pipeline) fail(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.err == nil {
+		p.err = err
+		close(p.done)
+	}
+}
+
+func (p *Pipeline) failure() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.err
+}
+
+// Передача слоя потребителю (EmitFunc для Stream). После ошибки
+// потребителя возвращает её, и расчёт прерывается
+func (p *Pipeline) Emit(n int, row []float64) error {
+	var buf []float64
+	select {
+	case buf = <-p.free:
+	case <-p.done:
+		return p.failure()
+	}
+	copy(buf, row)
+	p.full <- pipelineLevel{n: n, row: buf}
+	return nil
+}
+
+// Завершение: err — ошибка расчёта (nil, если он закончился успешно);
+// она останавливает потребителя, не дожидаясь записи оставшихся слоёв.
+// Ждёт потребителя и возвращает первую ошибку конвейера — одну на обе
+// стороны. Повторный вызов возвращает ту же ошибку
+func (p *Pipeline) Close(err error) error {
+	if err != nil {
+		p.fail(err)
+	}
+	p.mu.Lock()
+	if !p.closed {
+		p.closed = true
+		close(p.full)
+	}
+	p.mu.Unlock()
+	p.wg.Wait()
+	return p.failure()
+}
@@ -0,0 +1,155 @@
+package solver
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+// Потребитель, собирающий копии слоёв
+func collect(rows *[][]float64) EmitFunc {
+	return func(n int, row []float64) error {
+		*rows = append(*rows, slices.Clone(row))
+		return nil
+	}
+}
+
+// Через конвейер приходят те же слои в том же порядке, что и напрямую
+func TestPipelineMatchesSequential(t *testing.T) {
+	const nx, nt = 200, 300
+	dx := 1.0 / nx
+	u0 := SineProfile(nx, dx)
+	for _, depth := range []int{0, 1, 2, 8} {
+		var want, got [][]float64
+		if _, err := StreamCrankNicolsonFrom(u0, nt, dx, 0.0001, collect(&want)); err != nil {
+			t.Fatal(err)
+		}
+		p := NewPipeline(depth, nx+1, collect(&got))
+		_, err := StreamCrankNicolsonFrom(u0, nt, dx, 0.0001, p.Emit)
+		if err := p.Close(err); err != nil {
+			t.Fatalf("depth %d: %v", depth, err)
+		}
+		if !slices.EqualFunc(got, want, slices.Equal) {
+			t.Errorf("depth %d: pipelined levels differ from the direct ones", depth)
+		}
+	}
+}
+
+// Emit копирует слой: схема может сразу писать в тот же срез
+func TestPipelineCopiesLevels(t *testing.T) {
+	var got [][]float64
+	p := NewPipeline(2, 3, func(n int, row []float64) error {
+		time.Sleep(time.Millisecond)
+		return collect(&got)(n, row)
+	})
+	row := make([]float64, 3)
+	for n := range 10 {
+		for i := range row {
+			row[i] = float64(n)
+		}
+		if err := p.Emit(n, row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := p.Close(nil); err != nil {
+		t.Fatal(err)
+	}
+	for n, r := range got {
+		if !slices.Equal(r, []float64{float64(n), float64(n), float64(n)}) {
+			t.Fatalf("level %d arrived as %v", n, r)
+		}
+	}
+	if len(got) != 10 {
+		t.Errorf("%d levels consumed, want 10", len(got))
+	}
+}
+
+// Ошибка одной стороны останавливает другую и возвращается один раз
+func TestPipelineErrors(t *testing.T) {
+	errWrite := errors.New("disk full")
+	tests := []struct {
+		name string
+		// Слой, на котором потребитель отказывает; −1 — не отказывает
+		failAt int
+		// Отмена контекста расчёта до начала
+		cancel bool
+		want   error
+	}{
+		{"consumer", 5, false, errWrite},
+		{"consumer on the first level", 0, false, errWrite},
+		{"solver", -1, true, context.Canceled},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			consumed := 0
+			p := NewPipeline(4, 51, func(n int, row []float64) error {
+				if n == tc.failAt {
+					return errWrite
+				}
+				consumed++
+				return nil
+			})
+			ctx, cancel := context.WithCancel(context.Background())
+			if tc.cancel {
+				cancel()
+			}
+			defer cancel()
+			emit := func(n int, row []float64) error {
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+				return p.Emit(n, row)
+			}
+			_, err := StreamFTCSFrom(SineProfile(50, 0.02), 10000, 0.02, 0.0001, emit)
+			if err == nil {
+				t.Fatal("the solve was not interrupted")
+			}
+			if got := p.Close(err); !errors.Is(got, tc.want) {
+				t.Fatalf("Close = %v, want %v", got, tc.want)
+			}
+			if got := p.Close(nil); !errors.Is(got, tc.want) {
+				t.Errorf("second Close = %v, want the same error", got)
+			}
+			if tc.failAt >= 0 && consumed != tc.failAt {
+				t.Errorf("%d levels consumed, want %d before the failure", consumed, tc.failAt)
+			}
+		})
+	}
+}
+
+// Медленная запись (250 мкс на слой) при сравнимом по времени счёте:
+// последовательно время складывается, в конвейере — близко к большему
+func BenchmarkPipeline(b *testing.B) {
+	const nx, nt = 20000, 100
+	dx := 1.0 / nx
+	u0 := SineProfile(nx, dx)
+	write := func(int, []float64) error {
+		time.Sleep(250 * time.Microsecond)
+		return nil
+	}
+	b.Run("sequential", func(b *testing.B) {
+		for b.Loop() {
+			if _, err := StreamCrankNicolsonFrom(u0, nt, dx, 0.0001, write); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("pipelined", func(b *testing.B) {
+		for b.Loop() {
+			p := NewPipeline(8, nx+1, write)
+			_, err := StreamCrankNicolsonFrom(u0, nt, dx, 0.0001, p.Emit)
+			if err := p.Close(err); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("solve only", func(b *testing.B) {
+		for b.Loop() {
+			if _, err := StreamCrankNicolsonFrom(u0, nt, dx, 0.0001, nil); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
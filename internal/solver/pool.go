@@ -0,0 +1,90 @@
+package solver
+
+import (
+	"math/bits"
+	"sync"
+	"sync/atomic"
+	"unsafe"
+)
+
+// Пул буферов для сеток решения и рабочих массивов схем. Буферы делятся
+// на классы по ёмкости 2^k значений; сервер под нагрузкой повторяет
+// расчёты одного размера, и сетка берётся из пула вместо нового
+// выделения. Буфер очищается при выдаче, поэтому данные одного расчёта
+// не видны в другом. Буферы больше poolMaxBytes не пулируются: пул не
+// должен удерживать память редких больших расчётов
+const (
+	poolMinClass = 6
+	poolMaxBytes = 64 << 20
+)
+
+// Исходы запроса буфера для наблюдателя пула
+const (
+	PoolHit    = "hit"
+	PoolMiss   = "miss"
+	PoolBypass = "bypass"
+)
+
+var poolObserver atomic.Pointer[func(outcome string)]
+
+// Наблюдатель за пулом буферов (метрики): вызывается при каждом запросе
+// буфера с исходом PoolHit, PoolMiss или PoolBypass; nil — отключить
+func SetPoolObserver(fn func(outcome string)) {
+	if fn == nil {
+		poolObserver.Store(nil)
+		return
+	}
+	poolObserver.Store(&fn)
+}
+
+func observePool(outcome string) {
+	if fn := poolObserver.Load(); fn != nil {
+		(*fn)(outcome)
+	}
+}
+
+type bufPool[T float32 | float64] struct {
+	classes [bits.UintSize]sync.Pool
+}
+
+var (
+	pool64 bufPool[float64]
+	pool32 bufPool[float32]
+)
+
+// Класс буфера для n значений: наименьшее k с 2^k ≥ n
+func poolClass(n int) int {
+	return max(poolMinClass, bits.Len(uint(max(n, 1)-1)))
+}
+
+// Обнулённый буфер из n значений
+func (p *bufPool[T]) get(n int) []T {
+	var zero T
+	k := poolClass(n)
+	if uintptr(1)<<k*unsafe.Sizeof(zero) > poolMaxBytes {
+		observePool(PoolBypass)
+		return make([]T, n)
+	}
+	if v, ok := p.classes[k].Get().(*[]T); ok {
+		observePool(PoolHit)
+		buf := (*v)[:n]
+		clear(buf)
+		return buf
+	}
+	observePool(PoolMiss)
+	return make([]T, n, 1<<k)
+}
+
+// Возврат буфера, полученного из get; после вызова буфер не используется
+func (p *bufPool[T]) put(buf []T) {
+	c := cap(buf)
+	if c == 0 || c&(c-1) != 0 || c < 1<<poolMinClass {
+		return // выделен в обход пула
+	}
+	var zero T
+	if uintptr(c)*unsafe.Sizeof(zero) > poolMaxBytes {
+		return
+	}
+	buf = buf[:c]
+	p.classes[bits.Len(uint(c))-1].Put(&buf)
+}
@@ -0,0 +1,116 @@
+package solver
+
+import (
+	"context"
+	"runtime"
+	"runtime/debug"
+	"testing"
+
+	"heat-solver/internal/config"
+)
+
+// Исходы запросов буфера, пока тест не закончится
+func observeOutcomes(t *testing.T) map[string]int {
+	t.Helper()
+	outcomes := make(map[string]int)
+	SetPoolObserver(func(outcome string) { outcomes[outcome]++ })
+	t.Cleanup(func() { SetPoolObserver(nil) })
+	return outcomes
+}
+
+// Проверки попаданий в пул имеют смысл только без детектора гонок
+func skipRace(t *testing.T) {
+	t.Helper()
+	if raceEnabled {
+		t.Skip("sync.Pool drops buffers at random under the race detector")
+	}
+}
+
+func TestBufPool(t *testing.T) {
+	skipRace(t)
+	// Без сборки мусора sync.Pool не теряет буферы между get и put
+	defer debug.SetGCPercent(debug.SetGCPercent(-1))
+	large := int(poolMaxBytes/8) + 1
+	tests := []struct {
+		name    string
+		put     func() // буфер, возвращаемый в пул до запроса
+		n       int
+		outcome string
+	}{
+		{"same class is reused", func() { pool64.put(make([]float64, 1000, 1024)) }, 900, PoolHit},
+		{"other class misses", func() { pool64.put(make([]float64, 1000, 1024)) }, 5000, PoolMiss},
+		{"foreign buffer is not pooled", func() { pool64.put(make([]float64, 1000)) }, 1000, PoolMiss},
+		{"large buffer bypasses the pool", func() {}, large, PoolBypass},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Пул может хранить буферы прошлых тестов того же класса
+			for pool64.classes[poolClass(tc.n)].Get() != nil {
+			}
+			tc.put()
+			outcomes := observeOutcomes(t)
+			buf := pool64.get(tc.n)
+			if len(buf) != tc.n || outcomes[tc.outcome] != 1 || len(outcomes) != 1 {
+				t.Fatalf("len %d, outcomes %v; want len %d and one %s", len(buf), outcomes, tc.n, tc.outcome)
+			}
+			pool64.put(buf)
+		})
+	}
+}
+
+// Буфер из пула выдаётся обнулённым: данные прошлого расчёта не видны
+func TestBufPoolZeroesReused(t *testing.T) {
+	skipRace(t)
+	defer debug.SetGCPercent(debug.SetGCPercent(-1))
+	buf := pool32.get(100)
+	for i := range buf {
+		buf[i] = 1
+	}
+	pool32.put(buf)
+	outcomes := observeOutcomes(t)
+	reused := pool32.get(120)
+	if outcomes[PoolHit] != 1 {
+		t.Fatalf("outcomes %v, want a hit", outcomes)
+	}
+	for i, v := range reused[:cap(reused)] {
+		if v != 0 {
+			t.Fatalf("reused buffer has %v at %d", v, i)
+		}
+	}
+}
+
+// Повторные расчёты одного размера с Release берут сетку из пула: память,
+// выделяемая на расчёт, — доли от размера сетки
+func TestRunReusesBuffers(t *testing.T) {
+	skipRace(t)
+	defer debug.SetGCPercent(debug.SetGCPercent(-1))
+	p := config.Params{Method: "CN", Dx: 0.001, Dt: 0.0001, Tmax: 0.1}.Normalize()
+	nx, nt := p.Grid()
+	grid := uint64((nx + 1) * (nt + 1) * 8)
+	bytesPerRun := func(release bool) uint64 {
+		const runs = 20
+		var before, after runtime.MemStats
+		runtime.ReadMemStats(&before)
+		for range runs {
+			res, err := Run(context.Background(), p, Hooks{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if release {
+				res.Release()
+			}
+		}
+		runtime.ReadMemStats(&after)
+		return (after.TotalAlloc - before.TotalAlloc) / runs
+	}
+
+	outcomes := observeOutcomes(t)
+	pooled := bytesPerRun(true)
+	if outcomes[PoolMiss] > outcomes[PoolHit]/10 {
+		t.Errorf("pool outcomes %v, want nearly all hits", outcomes)
+	}
+	fresh := bytesPerRun(false)
+	if pooled > grid/10 || fresh < grid {
+		t.Errorf("%d bytes per run with the pool, %d without; grid is %d bytes", pooled, fresh, grid)
+	}
+}
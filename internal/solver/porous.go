@@ -0,0 +1,265 @@
+package solver
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+
+	"heat-solver/internal/mathutils"
+)
+
+// Уравнение пористой среды u_t = (u^m)_xx, m ≥ 1, с нулём на краях.
+// Шаг — BTCS с итерациями Пикара по запаздывающему множителю:
+// u^m ≈ w·u, w = (u^{(s)})^{m−1} с предыдущей итерации s, и на итерации
+// решается u_i − r·(w_{i+1}u_{i+1} − 2w_i·u_i + w_{i−1}u_{i−1}) = u_i^n.
+// В столбце j матрицы диагональ 1 + 2r·w_j, а вне её −r·w_j дважды, так
+// что это M-матрица при любых w ≥ 0, и у прогонки все множители одного
+// знака: из u^n ≥ 0 получается u^{n+1} ≥ 0 без отсечки, и нули вне
+// носителя остаются точными нулями. Носитель растёт не больше чем на узел
+// за итерацию. Масса Σu_i·dx сохраняется, пока носитель не дошёл до краёв
+
+// Пористая среда с заданным неотрицательным профилем u0; хранятся два слоя,
+// каждый слой передаётся в emit. Если итерации Пикара не сходятся, шаг
+// делится пополам (до maxPorousHalvings раз). Отрицательные начальные
+// значения, m < 1 и отсутствие сходимости — ошибка
+func StreamPorousFrom(u0 []float64, nt int, dx, dt, m, tol float64, emit EmitFunc) ([]float64, error) {
+	u := newGrid(2, len(u0))
+	if err := porous(u0, nt, dx, dt, m, tol, u, emit); err != nil {
+		return nil, err
+	}
+	return level(u, nt), nil
+}
+
+// Профиль Баренблатта b при t = 0 в узлах x_i = i·dx
+func BarenblattProfile(nx int, dx float64, b mathutils.Barenblatt) []float64 {
+	u0 := make([]float64, nx+1)
+	b.Grid(nx, dx)(u0, 0)
+	return u0
+}
+
+// Правый край носителя: давление p = u^{m−1} у решения Баренблатта
+// линейно убывает к краю, поэтому край — ноль прямой через два крайних
+// узла, где p ещё не меньше 5% максимума. Так край не зависит от того,
+// насколько далеко итерации успели протянуть исчезающе малые значения.
+// NaN, если таких узлов меньше двух или край уходит за последний узел
+func PorousFront(u []float64, dx, m float64) float64 {
+	var pmax float64
+	for _, v := range u {
+		pmax = math.Max(pmax, math.Pow(math.Max(v, 0), m-1))
+	}
+	if pmax == 0 {
+		return math.NaN()
+	}
+	for i := len(u) - 1; i > 0; i-- {
+		p := math.Pow(math.Max(u[i], 0), m-1)
+		if p < 0.05*pmax {
+			continue
+		}
+		prev := math.Pow(math.Max(u[i-1], 0), m-1)
+		if prev <= p || i == len(u)-1 {
+			return math.NaN()
+		}
+		return (float64(i) + p/(prev-p)) * dx
+	}
+	return math.NaN()
+}
+
+// Итераций Пикара на одну попытку шага пористой среды; если их не хватило,
+// шаг делится пополам
+const porousPicardIterations = 50
+
+// Наибольшее число делений шага пористой среды пополам
+const maxPorousHalvings = 20
+
+// Шаг BTCS пористой среды с итерациями Пикара и делением пополам: при
+// большом r фронт за шаг проходит много узлов, а итерация продвигает
+// носитель на узел, и сходимость становится медленной. Половинные шаги
+// те же по построению, так что неотрицательность сохраняется
+type porousStepper struct {
+	m, tol, dx     float64
+	a, b, c, d, w  []float64
+	iterate        []float64
+	tri            *thomas
+	mids           [][]float64
+	iterations     int
+	substeps, most int
+	deepest        int
+}
+
+// Итерации Пикара шага dt из cur в next; false, если за
+// porousPicardIterations поправка не опустилась до tol
+func (p *porousStepper) solve(cur, next []float64, dt float64) (bool, error) {
+	nx := len(cur) - 1
+	r := dt / (p.dx * p.dx)
+	next[0], next[nx] = 0, 0
+	copy(p.iterate, cur)
+	for s := 1; s <= porousPicardIterations; s++ {
+		for i, v := range p.iterate {
+			p.w[i] = math.Pow(v, p.m-1)
+		}
+		// Узел i — строка i−1 системы: a у w_{i−1}, c у w_{i+1}
+		for i := 1; i < nx; i++ {
+			p.a[i-1] = -r * p.w[i-1]
+			p.b[i-1] = 1 + 2*r*p.w[i]
+			p.c[i-1] = -r * p.w[i+1]
+		}
+		copy(p.d, cur[1:nx])
+		p.tri.factor(p.a, p.b, p.c)
+		p.tri.solve(p.d, next[1:nx])
+
+		var update float64
+		for i := 1; i < nx; i++ {
+			update = math.Max(update, math.Abs(next[i]-p.iterate[i]))
+		}
+		copy(p.iterate, next)
+		p.iterations++
+		if math.IsNaN(update) || math.IsInf(update, 0) {
+			return false, ErrDiverged
+		}
+		if update <= p.tol {
+			p.most = max(p.most, s)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Шаг dt из cur в next; без сходимости — два шага dt/2 через буфер
+// уровня depth
+func (p *porousStepper) advance(cur, next []float64, dt float64, depth int) error {
+	ok, err := p.solve(cur, next, dt)
+	if err != nil {
+		return err
+	}
+	if ok {
+		p.substeps++
+		p.deepest = max(p.deepest, depth)
+		return nil
+	}
+	if depth == maxPorousHalvings {
+		return fmt.Errorf("Picard iteration did not converge after %d halvings of the step (dt = %g)", depth, dt)
+	}
+	if len(p.mids) == depth {
+		p.mids = append(p.mids, make([]float64, len(cur)))
+	}
+	mid := p.mids[depth]
+	if err := p.advance(cur, mid, dt/2, depth+1); err != nil {
+		return err
+	}
+	return p.advance(mid, next, dt/2, depth+1)
+}
+
+func porous(u0 []float64, nt int, dx, dt, m, tol float64, u [][]float64, emit EmitFunc) error {
+	if !(m >= 1) || math.IsInf(m, 0) {
+		return fmt.Errorf("porous medium exponent must be finite and at least 1, not %g", m)
+	}
+	for i, v := range u0 {
+		if !(v >= 0) || math.IsInf(v, 0) {
+			return fmt.Errorf("porous medium initial value %g at node %d is not finite and non-negative", v, i)
+		}
+	}
+	nx := len(u0) - 1
+	slog.Info("Starting porous medium BTCS solver", "nx", nx, "nt", nt, "dx", dx, "dt", dt, "m", m, "picard_tol", tol)
+
+	if err := start(u, u0, emit); err != nil {
+		return err
+	}
+
+	a, b, c, d, ws := workspace(nx - 1)
+	defer pool64.put(ws)
+	buf := pool64.get(2 * (nx + 1))
+	defer pool64.put(buf)
+	// Матрица меняется на каждой итерации: Томас с новым разложением
+	tri := newThomas(nx - 1)
+	defer tri.close()
+	p := &porousStepper{m: m, tol: tol, dx: dx, a: a, b: b, c: c, d: d,
+		w: buf[: nx+1 : nx+1], iterate: buf[nx+1:], tri: tri}
+
+	for n := 0; n < nt; n++ {
+		if err := p.advance(level(u, n), level(u, n+1), dt, 0); err != nil {
+			return fmt.Errorf("step %d: %w", n+1, err)
+		}
+		if err := emitLevel(emit, n+1, level(u, n+1)); err != nil {
+			return err
+		}
+	}
+
+	if nt > 0 {
+		slog.Info("Picard iterations", "total", p.iterations, "max_per_substep", p.most, "mean_per_step", float64(p.iterations)/float64(nt))
+	}
+	if p.substeps > nt {
+		slog.Info("Steps were halved for Picard convergence", "substeps", p.substeps, "steps", nt, "smallest_dt", dt/math.Exp2(float64(p.deepest)))
+	}
+	slog.Info("Porous medium BTCS solver finished successfully")
+	return nil
+}
+
+// Проверочные случаи пористой среды; в Verify — под именем PME
+var verificationPorous = []VerifyCase{
+	porousSpreadCase(2, 0.1, 0.01, 7),
+	porousSpreadCase(2, 0.05, 0.0025, 7),
+	porousSpreadCase(2, 0.025, 0.000625, 7),
+	porousSpreadCase(3, 0.05, 0.0025, 7),
+	porousPositiveCase(2, 0.05, 0.5, 2),
+}
+
+// Рост носителя из профиля Баренблатта с R0 = 1 при T0 = 1 на [0, 8] (центр
+// 4) до t = tmax: относительная разница между численной полушириной
+// (край PorousFront минус центр) и R0·(1 + tmax)^{1/(m+1)}. При tmax = 7
+// и m = 2 носитель вырастает вдвое
+func porousSpreadCase(m, dx, dt, tmax float64) VerifyCase {
+	b := mathutils.Barenblatt{M: m, Center: 4, T0: 1, R0: 1}
+	return VerifyCase{
+		Name:     fmt.Sprintf("m=%g support growth dx=%g dt=%g", m, dx, dt),
+		Quantity: "relative radius error",
+		Compare:  VerifyAtMost,
+		Expected: 0.03,
+		Measure: func(ctx context.Context, _ Method) (float64, string, error) {
+			if err := ctx.Err(); err != nil {
+				return math.NaN(), "", err
+			}
+			nx, nt := int(math.Round(8/dx)), int(math.Round(tmax/dt))
+			u0 := BarenblattProfile(nx, dx, b)
+			last, err := StreamPorousFrom(u0, nt, dx, dt, m, DefaultPicardTol, nil)
+			if err != nil {
+				return math.NaN(), "", err
+			}
+			t := float64(nt) * dt
+			radius, exact := PorousFront(last, dx, m)-b.Center, b.Radius(t)
+			return math.Abs(radius-exact) / exact, fmt.Sprintf("radius %.4g from %.4g, Barenblatt %.4g",
+				radius, PorousFront(u0, dx, m)-b.Center, exact), nil
+		},
+	}
+}
+
+// Наименьшее значение на всех слоях из ступеньки 1 на [0.4, 0.6] при
+// большом r = dt/dx²: отрицательных значений быть не должно
+func porousPositiveCase(m, dx, dt, tmax float64) VerifyCase {
+	return VerifyCase{
+		Name:     fmt.Sprintf("m=%g step dx=%g dt=%g", m, dx, dt),
+		Quantity: "min u",
+		Compare:  VerifyAtLeast,
+		Expected: 0,
+		Measure: func(ctx context.Context, _ Method) (float64, string, error) {
+			nx, nt := int(math.Round(1/dx)), int(math.Round(tmax/dt))
+			u0 := make([]float64, nx+1)
+			for i := range u0 {
+				if x := float64(i) * dx; x >= 0.4 && x <= 0.6 {
+					u0[i] = 1
+				}
+			}
+			least := math.Inf(1)
+			emit := func(_ int, row []float64) error {
+				for _, v := range row {
+					least = math.Min(least, v)
+				}
+				return ctx.Err()
+			}
+			if _, err := StreamPorousFrom(u0, nt, dx, dt, m, DefaultPicardTol, emit); err != nil {
+				return math.NaN(), "", err
+			}
+			return least, fmt.Sprintf("r = %g, %d steps", dt/(dx*dx), nt), nil
+		},
+	}
+}
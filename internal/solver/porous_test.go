@@ -0,0 +1,170 @@
+package solver
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"heat-solver/internal/mathutils"
+)
+
+// Полуширина носителя из профиля Баренблатта с R0 = 1 при T0 = 1 растёт
+// как (1 + t)^{1/(m+1)}: при tmax = 3 и m = 2 — в 4^{1/3} раз. Ошибка
+// в пределах нескольких процентов и не растёт при измельчении
+func TestPorousSpread(t *testing.T) {
+	tests := []struct {
+		m    float64
+		dxs  []float64
+		tmax float64
+	}{
+		{2, []float64{0.1, 0.05, 0.025}, 3},
+		{3, []float64{0.1, 0.05}, 3},
+		{1.5, []float64{0.1, 0.05}, 3},
+	}
+	for _, tc := range tests {
+		t.Run(fmt.Sprintf("m=%g", tc.m), func(t *testing.T) {
+			b := mathutils.Barenblatt{M: tc.m, Center: 4, T0: 1, R0: 1}
+			prev := math.Inf(1)
+			for _, dx := range tc.dxs {
+				dt := dx * dx / 4
+				nx, nt := int(math.Round(8/dx)), int(math.Round(tc.tmax/dt))
+				u0 := BarenblattProfile(nx, dx, b)
+				last, err := StreamPorousFrom(u0, nt, dx, dt, tc.m, DefaultPicardTol, nil)
+				if err != nil {
+					t.Fatal(err)
+				}
+				radius, exact := PorousFront(last, dx, tc.m)-b.Center, b.Radius(float64(nt)*dt)
+				rel := math.Abs(radius-exact) / exact
+				if rel > 0.03 || rel > 1.5*prev {
+					t.Errorf("dx=%g: radius %.5g, Barenblatt %.5g (relative error %.3g, previous %.3g)", dx, radius, exact, rel, prev)
+				}
+				prev = rel
+				if heat, want := norm1(last)*dx, b.Heat(0); math.Abs(heat-want) > 0.02*want {
+					t.Errorf("dx=%g: mass %.6g, Barenblatt %.6g", dx, heat, want)
+				}
+			}
+		})
+	}
+}
+
+// Край по давлению на точном профиле Баренблатта — в пределах пятой доли
+// узла: давление квадратично по x, и прямая через два узла ошибается на
+// O(dx²). Без носителя или с носителем до последнего узла — NaN
+func TestPorousFront(t *testing.T) {
+	for _, m := range []float64{1.5, 2, 3} {
+		for _, dx := range []float64{0.1, 0.05, 0.013} {
+			b := mathutils.Barenblatt{M: m, Center: 4, T0: 1, R0: 1}
+			u := BarenblattProfile(int(math.Round(8/dx)), dx, b)
+			if front := PorousFront(u, dx, m); math.Abs(front-5) > 0.2*dx {
+				t.Errorf("m=%g dx=%g: front %.12g, want 5", m, dx, front)
+			}
+		}
+	}
+	if front := PorousFront(make([]float64, 11), 0.1, 2); !math.IsNaN(front) {
+		t.Errorf("zero profile: front %v, want NaN", front)
+	}
+	if front := PorousFront([]float64{0, 1, 2, 3}, 0.1, 2); !math.IsNaN(front) {
+		t.Errorf("support through the last node: front %v, want NaN", front)
+	}
+}
+
+// Неотрицательные данные при большом r дают неотрицательные слои с
+// нулями на краях
+func TestPorousPositive(t *testing.T) {
+	tests := []struct {
+		name string
+		m    float64
+		u0   func(x float64) float64
+		dt   float64
+	}{
+		{"step m=2", 2, func(x float64) float64 { return boolFloat(x >= 0.4 && x <= 0.6) }, 0.5},
+		{"step m=3", 3, func(x float64) float64 { return boolFloat(x >= 0.4 && x <= 0.6) }, 0.5},
+		{"spike m=2", 2, func(x float64) float64 { return boolFloat(math.Abs(x-0.5) < 1e-9) * 10 }, 0.05},
+		{"two bumps m=4", 4, func(x float64) float64 {
+			return math.Max(0, 0.01-(x-0.3)*(x-0.3)) + math.Max(0, 0.01-(x-0.7)*(x-0.7))
+		}, 0.2},
+		{"linear m=1", 1, func(x float64) float64 { return boolFloat(x >= 0.45 && x <= 0.55) }, 0.01},
+	}
+	const dx = 0.02
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			u0 := make([]float64, int(math.Round(1/dx))+1)
+			for i := range u0 {
+				u0[i] = tc.u0(float64(i) * dx)
+			}
+			least, first := math.Inf(1), []float64(nil)
+			emit := func(n int, row []float64) error {
+				for _, v := range row {
+					least = math.Min(least, v)
+				}
+				if n == 1 {
+					first = append([]float64(nil), row...)
+				}
+				return nil
+			}
+			if _, err := StreamPorousFrom(u0, 4, dx, tc.dt, tc.m, DefaultPicardTol, emit); err != nil {
+				t.Fatal(err)
+			}
+			if least < 0 {
+				t.Errorf("min u = %g", least)
+			}
+			if first[0] != 0 || first[len(first)-1] != 0 {
+				t.Errorf("boundary values %g, %g", first[0], first[len(first)-1])
+			}
+		})
+	}
+}
+
+// Ступенька 1 на [0.4, 0.6]: Σu_i·dx = 0.22 на каждом слое, пока
+// соседние с краями узлы нулевые
+func TestPorousMass(t *testing.T) {
+	const dx, dt = 0.02, 0.01
+	u0 := make([]float64, 51)
+	for i := 20; i <= 30; i++ {
+		u0[i] = 1
+	}
+	var bad []string
+	emit := func(n int, row []float64) error {
+		if row[1] == 0 && row[len(row)-2] == 0 {
+			if heat := norm1(row) * dx; math.Abs(heat-0.22) > 1e-8 {
+				bad = append(bad, fmt.Sprintf("step %d: mass %.12g", n, heat))
+			}
+		}
+		return nil
+	}
+	if _, err := StreamPorousFrom(u0, 20, dx, dt, 2, 1e-12, emit); err != nil {
+		t.Fatal(err)
+	}
+	if len(bad) > 0 {
+		t.Errorf("mass not conserved: %v", bad)
+	}
+}
+
+func TestPorousRejects(t *testing.T) {
+	tests := []struct {
+		name string
+		u0   []float64
+		m    float64
+	}{
+		{"negative value", []float64{0, 1, -1e-12, 0}, 2},
+		{"NaN value", []float64{0, math.NaN(), 0}, 2},
+		{"infinite value", []float64{0, math.Inf(1), 0}, 2},
+		{"m below 1", []float64{0, 1, 0}, 0.5},
+		{"NaN m", []float64{0, 1, 0}, math.NaN()},
+		{"infinite m", []float64{0, 1, 0}, math.Inf(1)},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := StreamPorousFrom(tc.u0, 1, 0.1, 0.01, tc.m, DefaultPicardTol, nil); err == nil {
+				t.Error("accepted")
+			}
+		})
+	}
+}
+
+func boolFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
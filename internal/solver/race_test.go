@@ -0,0 +1,6 @@
+//go:build race
+
+package solver
+
+// Под детектором гонок sync.Pool отбрасывает часть возвращённых буферов
+const raceEnabled = true
@@ -0,0 +1,275 @@
+package solver
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+
+	"heat-solver/internal/mathutils"
+)
+
+// Радиальные уравнения u_t = α·(u_rr + (m/r)·u_r) на r ∈ [0, 1] с условием
+// симметрии u_r = 0 в центре и значением края на r = 1: m = 1 — длинный
+// цилиндр, m = 2 — шар. Обе схемы θ-взвешенные, как SolveTheta:
+// θ = ½ — CN, θ = 1 — BTCS
+
+// J₀(λ₁r) на узлах r_i = i·dr цилиндра радиуса 1; на r = 1 — точный ноль
+func BesselProfile(nr int, dr float64) []float64 {
+	u0 := make([]float64, nr+1)
+	mathutils.BesselMode{}.Grid(nr, dr)(u0, 0)
+	u0[nr] = 0
+	return u0
+}
+
+// sin(πr)/r на узлах r_i = i·dr шара радиуса 1 (в центре — предел π); на
+// r = 1 — точный ноль
+func SphereProfile(nr int, dr float64) []float64 {
+	u0 := make([]float64, nr+1)
+	mathutils.SphereMode{}.Grid(nr, dr)(u0, 0)
+	u0[nr] = 0
+	return u0
+}
+
+// Цилиндр: u_t = α·(u_rr + u_r/r). Начальный слой — J₀(λ₁r), точное
+// решение — exp(−αλ₁²t)·J₀(λ₁r) (mathutils.BesselMode)
+func SolveCylindrical(nr, nt int, dr, dt, alpha, theta float64) [][]float64 {
+	return SolveCylindricalFrom(BesselProfile(nr, dr), nt, dr, dt, alpha, theta)
+}
+
+// Радиальная схема цилиндра с заданным профилем u0 (nr+1 значений, u0[nr] —
+// край)
+func SolveCylindricalFrom(u0 []float64, nt int, dr, dt, alpha, theta float64) [][]float64 {
+	u := newGrid(nt+1, len(u0))
+	radial(cylinderShape, u0, nt, dr, dt, alpha, theta, u, nil)
+	return u
+}
+
+// Цилиндр с хранением двух слоёв; каждый слой передаётся в emit
+func StreamCylindricalFrom(u0 []float64, nt int, dr, dt, alpha, theta float64, emit EmitFunc) ([]float64, error) {
+	u := newGrid(2, len(u0))
+	if err := radial(cylinderShape, u0, nt, dr, dt, alpha, theta, u, emit); err != nil {
+		return nil, err
+	}
+	return level(u, nt), nil
+}
+
+// Шар: u_t = α·(u_rr + 2u_r/r). Начальный слой — sin(πr)/r, точное
+// решение — exp(−π²αt)·sin(πr)/r (mathutils.SphereMode)
+func SolveSpherical(nr, nt int, dr, dt, alpha, theta float64) [][]float64 {
+	return SolveSphericalFrom(SphereProfile(nr, dr), nt, dr, dt, alpha, theta)
+}
+
+// Радиальная схема шара с заданным профилем u0
+func SolveSphericalFrom(u0 []float64, nt int, dr, dt, alpha, theta float64) [][]float64 {
+	u := newGrid(nt+1, len(u0))
+	radial(sphereShape, u0, nt, dr, dt, alpha, theta, u, nil)
+	return u
+}
+
+// Шар с хранением двух слоёв; каждый слой передаётся в emit
+func StreamSphericalFrom(u0 []float64, nt int, dr, dt, alpha, theta float64, emit EmitFunc) ([]float64, error) {
+	u := newGrid(2, len(u0))
+	if err := radial(sphereShape, u0, nt, dr, dt, alpha, theta, u, emit); err != nil {
+		return nil, err
+	}
+	return level(u, nt), nil
+}
+
+// Геометрия радиальной схемы: показатель m в весах r^m и наибольшее по
+// модулю собственное значение dr²·L (степенной метод; от nr при nr ≥ 8 не
+// зависит). У декартовой δ² это 4, здесь больше из-за центральной строки,
+// поэтому предел r при θ < ½ — (2/ρ)/(1 − 2θ): 0.413 у цилиндра и 0.314 у
+// шара вместо ½
+type radialShape struct {
+	name     string
+	m        int
+	spectral float64
+}
+
+var (
+	cylinderShape = radialShape{"cylindrical", 1, 4.842}
+	sphereShape   = radialShape{"spherical", 2, 6.367}
+)
+
+// Веса соседей и диагонали в строке i ≥ 1: из баланса тепла в ячейке
+// [r_{i−½}, r_{i+½}],
+//
+//	(L·u)_i = [r_{i+½}^m·(u_{i+1} − u_i) − r_{i−½}^m·(u_i − u_{i−1})] / (V_i·dr²),
+//
+// V_i = (r_{i+½}^{m+1} − r_{i−½}^{m+1}) / ((m+1)·dr) — объём ячейки на dr.
+// С s = 1/(2i): у цилиндра V_i = r_i и веса (1 ± s); у шара
+// V_i = r_i²·(1 + s²/3) и веса (1 ± s)²/(1 + s²/3). Если вместо V_i взять
+// r_i² (частая ошибка), у шара в строке 1 получается ошибка O(1) и второй
+// порядок у центра теряется
+func (g radialShape) weights(i int) (lo, diag, hi float64) {
+	s := 1 / (2 * float64(i))
+	if g.m == 1 {
+		return 1 - s, 2, 1 + s
+	}
+	v := 1 + s*s/3
+	return (1 - s) * (1 - s) / v, (2 + 2*s*s) / v, (1 + s) * (1 + s) / v
+}
+
+// В центре u_r/r → u_rr (Лопиталь), и с отражённым узлом u_{−1} = u_1
+// уравнение — (L·u)_0 = 2(m+1)·(u_1 − u_0)/dr²: 4 у цилиндра, 6 у шара.
+// То же даёт баланс тепла в ячейке [0, dr/2] вокруг центра, так что схема
+// консервативна и в центральной ячейке. Неизвестные — узлы 0..nr−1, край
+// r = 1 берётся из слоя. Матрица (1 − θr·L) постоянна и раскладывается
+// один раз
+func radial(g radialShape, u0 []float64, nt int, dr, dt, alpha, theta float64, u [][]float64, emit EmitFunc) error {
+	nr := len(u0) - 1
+	r := alpha * dt / (dr * dr)
+	name := g.name + " " + thetaName(theta)
+	warnUnstable(name, r, thetaMaxR(theta)*4/g.spectral)
+	slog.Info("Starting "+name+" solver", "nr", nr, "nt", nt, "dr", dr, "dt", dt, "r", r)
+
+	row := level(u, 0)
+	copy(row, u0)
+	if err := emitLevel(emit, 0, row); err != nil {
+		return err
+	}
+
+	implicit, explicit := theta*r, (1-theta)*r
+	center := float64(2 * (g.m + 1))
+	a, b, c, d, ws := workspace(nr)
+	defer pool64.put(ws)
+	b[0], c[0] = 1+center*implicit, -center*implicit
+	for i := 1; i < nr; i++ {
+		lo, diag, hi := g.weights(i)
+		a[i] = -implicit * lo
+		b[i] = 1 + diag*implicit
+		c[i] = -implicit * hi
+	}
+	tri := newThomas(nr)
+	defer tri.close()
+	tri.factor(a, b, c)
+	_, _, out := g.weights(nr - 1) // вес края в строке nr−1
+
+	for n := 0; n < nt; n++ {
+		cur, next := level(u, n), level(u, n+1)
+		next[nr] = cur[nr]
+		d[0] = cur[0] + center*explicit*(cur[1]-cur[0])
+		for i := 1; i < nr; i++ {
+			lo, diag, hi := g.weights(i)
+			d[i] = cur[i] + explicit*(hi*cur[i+1]-diag*cur[i]+lo*cur[i-1])
+		}
+		d[nr-1] += implicit * out * next[nr]
+		tri.solve(d, next[:nr])
+		if err := emitLevel(emit, n+1, next); err != nil {
+			return err
+		}
+	}
+
+	slog.Info(name + " solver finished successfully")
+	return nil
+}
+
+// Проверочные случаи радиальных схем; в Verify — под именами CYLINDRICAL и
+// SPHERICAL
+var (
+	verificationCylindrical = []VerifyCase{
+		cylinderRateCase(32, 0.001, 0.05, 0.3, 0.01),
+		radialErrorCase(StreamCylindricalFrom, BesselProfile, mathutils.BesselMode{Alpha: 1}, "Bessel mode", 32, 0.001, 0.1, 2e-4),
+		radialOrderCase(StreamCylindricalFrom, BesselProfile, mathutils.BesselMode{Alpha: 1}, "Bessel order", 16, 0.004, 0.1, 2, 0.15),
+	}
+	verificationSpherical = []VerifyCase{
+		radialErrorCase(StreamSphericalFrom, SphereProfile, mathutils.SphereMode{Alpha: 1}, "sin(πr)/r", 32, 0.001, 0.1, 1e-3),
+		radialOrderCase(StreamSphericalFrom, SphereProfile, mathutils.SphereMode{Alpha: 1}, "sin(πr)/r order", 16, 0.004, 0.1, 2, 0.15),
+		sphereCenterOrderCase(16, 0.004, 0.1, 2, 0.15),
+	}
+)
+
+// Радиальная схема с хранением двух слоёв и её начальный профиль
+type (
+	radialStream  func(u0 []float64, nt int, dr, dt, alpha, theta float64, emit EmitFunc) ([]float64, error)
+	radialProfile func(nr int, dr float64) []float64
+)
+
+// Наблюдаемая скорость затухания моды J₀ (CN на nr отрезках) по значениям в
+// центре между t1 и t2 против λ₁² (α = 1), округлённого в таблице до
+// 5.7832: отличие округления 1.4e-5 много меньше допуска
+func cylinderRateCase(nr int, dt, t1, t2, tol float64) VerifyCase {
+	rate := mathutils.BesselMode{Alpha: 1}.Rate()
+	return VerifyCase{
+		Name:      fmt.Sprintf("Bessel decay rate nr=%d dt=%g", nr, dt),
+		Quantity:  "decay rate",
+		Compare:   VerifyNear,
+		Expected:  math.Round(rate*1e4) / 1e4,
+		Tolerance: tol,
+		Measure: func(ctx context.Context, m Method) (float64, string, error) {
+			dr := 1 / float64(nr)
+			n1, n2 := int(math.Round(t1/dt)), int(math.Round(t2/dt))
+			u := SolveCylindrical(nr, n2, dr, dt, 1, 0.5)
+			observed := math.Log(u[n1][0]/u[n2][0]) / (float64(n2-n1) * dt)
+			return observed, fmt.Sprintf("λ₁² = %.6f, relative difference %.2e", rate, math.Abs(observed-rate)/rate), nil
+		},
+	}
+}
+
+// Ошибка L2 CN против точного решения в момент tmax не больше bound
+func radialErrorCase(stream radialStream, profile radialProfile, exact mathutils.Reference, name string, nr int, dt, tmax, bound float64) VerifyCase {
+	return VerifyCase{
+		Name:     fmt.Sprintf("%s nr=%d dt=%g", name, nr, dt),
+		Quantity: "L2 error",
+		Compare:  VerifyAtMost,
+		Expected: bound,
+		Measure: func(ctx context.Context, m Method) (float64, string, error) {
+			l2, _ := radialErrors(stream, profile, exact, nr, dt, tmax)
+			return l2, "", nil
+		},
+	}
+}
+
+// Наблюдаемый порядок L2 за два измельчения dr и dt вдвое (CN — второй по
+// обоим шагам)
+func radialOrderCase(stream radialStream, profile radialProfile, exact mathutils.Reference, name string, nr int, dt, tmax, order, tol float64) VerifyCase {
+	return VerifyCase{
+		Name:      fmt.Sprintf("%s from nr=%d dt=%g", name, nr, dt),
+		Quantity:  "observed order",
+		Compare:   VerifyNear,
+		Expected:  order,
+		Tolerance: tol,
+		Measure: func(ctx context.Context, m Method) (float64, string, error) {
+			var errs [3]float64
+			for k := range errs {
+				errs[k], _ = radialErrors(stream, profile, exact, nr<<k, dt/math.Exp2(float64(k)), tmax)
+			}
+			first, second := math.Log2(errs[0]/errs[1]), math.Log2(errs[1]/errs[2])
+			return second, fmt.Sprintf("errors %.3g, %.3g, %.3g; orders %.3f, %.3f", errs[0], errs[1], errs[2], first, second), nil
+		},
+	}
+}
+
+// Порядок ошибки в самом центре шара, |u_0 − π·exp(−π²t)|, за два
+// измельчения dr и dt вдвое. Центральная строка — отдельная формула, и
+// ошибку в ней L2 по всем узлам почти не видит
+func sphereCenterOrderCase(nr int, dt, tmax, order, tol float64) VerifyCase {
+	return VerifyCase{
+		Name:      fmt.Sprintf("sphere center order from nr=%d dt=%g", nr, dt),
+		Quantity:  "observed order at r=0",
+		Compare:   VerifyNear,
+		Expected:  order,
+		Tolerance: tol,
+		Measure: func(ctx context.Context, m Method) (float64, string, error) {
+			var errs [3]float64
+			for k := range errs {
+				n, step := nr<<k, dt/math.Exp2(float64(k))
+				dr := 1 / float64(n)
+				nt := int(math.Round(tmax / step))
+				last, _ := StreamSphericalFrom(SphereProfile(n, dr), nt, dr, step, 1, 0.5, nil)
+				errs[k] = math.Abs(last[0] - mathutils.SphereMode{Alpha: 1}.Eval(0, float64(nt)*step))
+			}
+			first, second := math.Log2(errs[0]/errs[1]), math.Log2(errs[1]/errs[2])
+			return second, fmt.Sprintf("errors %.3g, %.3g, %.3g; orders %.3f, %.3f", errs[0], errs[1], errs[2], first, second), nil
+		},
+	}
+}
+
+// Нормы L2 и L∞ ошибки CN на nr отрезках в момент tmax
+func radialErrors(stream radialStream, profile radialProfile, exact mathutils.Reference, nr int, dt, tmax float64) (float64, float64) {
+	dr := 1 / float64(nr)
+	nt := int(math.Round(tmax / dt))
+	last, _ := stream(profile(nr, dr), nt, dr, dt, 1, 0.5, nil)
+	return mathutils.LevelErrors(last, dr, float64(nt)*dt, exact)
+}
@@ -0,0 +1,215 @@
+package solver
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"testing"
+
+	"heat-solver/internal/mathutils"
+)
+
+// Скорость затухания J₀(λ₁r) по значениям в центре между t1 = 0.05 и
+// t2 = 0.3 против λ₁² = 5.7832: у CN отличие O(dr² + dt²), у BTCS —
+// O(dt), λ₁⁴·dt/2 относительно λ₁²
+func TestCylindricalDecayRate(t *testing.T) {
+	rate := mathutils.BesselMode{Alpha: 1}.Rate()
+	tests := []struct {
+		nr    int
+		dt    float64
+		theta float64
+		tol   float64 // относительный допуск
+	}{
+		{32, 0.001, 0.5, 1e-3},
+		{64, 0.0005, 0.5, 3e-4},
+		{64, 0.0005, 1, 3e-3},
+		{16, 0.01, 0.5, 4e-3},
+	}
+	for _, tc := range tests {
+		t.Run(fmt.Sprintf("nr=%d/dt=%g/θ=%g", tc.nr, tc.dt, tc.theta), func(t *testing.T) {
+			dr := 1 / float64(tc.nr)
+			n1, n2 := int(math.Round(0.05/tc.dt)), int(math.Round(0.3/tc.dt))
+			u := SolveCylindrical(tc.nr, n2, dr, tc.dt, 1, tc.theta)
+			observed := math.Log(u[n1][0]/u[n2][0]) / (float64(n2-n1) * tc.dt)
+			if diff := math.Abs(observed-rate) / rate; diff > tc.tol {
+				t.Errorf("decay rate %.6f, λ₁² = %.6f: relative difference %.2e", observed, rate, diff)
+			}
+			last, err := StreamCylindricalFrom(BesselProfile(tc.nr, dr), n2, dr, tc.dt, 1, tc.theta, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(last, u[n2]) {
+				t.Error("streamed run differs from SolveCylindrical")
+			}
+		})
+	}
+}
+
+// CN против exp(−λ₁²t)·J₀(λ₁r): второй порядок при измельчении dr и dt
+// вдвое вместе, в том числе по центральному узлу
+func TestCylindricalOrder(t *testing.T) {
+	exact := mathutils.BesselMode{Alpha: 1}
+	var prevL2, prevCenter float64
+	for k, nr := range []int{16, 32, 64} {
+		dr, dt := 1/float64(nr), 0.064/float64(nr)
+		nt := int(math.Round(0.1 / dt))
+		last, err := StreamCylindricalFrom(BesselProfile(nr, dr), nt, dr, dt, 1, 0.5, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		l2, _ := mathutils.LevelErrors(last, dr, float64(nt)*dt, exact)
+		center := math.Abs(last[0] - exact.Eval(0, float64(nt)*dt))
+		if k > 0 {
+			for _, e := range []struct {
+				name       string
+				prev, next float64
+			}{{"L2", prevL2, l2}, {"center", prevCenter, center}} {
+				if order := math.Log2(e.prev / e.next); math.Abs(order-2) > 0.15 {
+					t.Errorf("nr=%d: %s error %.3g → %.3g, observed order %.2f", nr, e.name, e.prev, e.next, order)
+				}
+			}
+		}
+		prevL2, prevCenter = l2, center
+	}
+}
+
+// Строки оператора, и центральная тоже, в сумме дают ноль: постоянный
+// профиль с тем же значением на краю не меняется ни при каком θ
+func TestCylindricalConstant(t *testing.T) {
+	const nr = 20
+	for _, theta := range []float64{0, 0.5, 1} {
+		u0 := make([]float64, nr+1)
+		for i := range u0 {
+			u0[i] = 3
+		}
+		last, err := StreamCylindricalFrom(u0, 50, 1.0/nr, 0.0005, 1, theta, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, v := range last {
+			if math.Abs(v-3) > 1e-13 {
+				t.Fatalf("θ=%g: node %d drifted to %v", theta, i, v)
+			}
+		}
+	}
+}
+
+// Явная схема (θ = 0) устойчива до r = 2/4.842 ≈ 0.413, а не до ½:
+// ниже предела мода J₀ затухает, выше — растёт пилообразная мода, и о
+// ней предупреждает журнал
+func TestCylindricalExplicitLimit(t *testing.T) {
+	const nr, nt = 32, 2000
+	dr := 1.0 / nr
+	tests := []struct {
+		r        float64
+		unstable bool
+	}{
+		{0.40, false},
+		{0.43, true},
+	}
+	for _, tc := range tests {
+		t.Run(fmt.Sprintf("r=%g", tc.r), func(t *testing.T) {
+			warnings := captureWarnings(t)
+			last, err := StreamCylindricalFrom(BesselProfile(nr, dr), nt, dr, tc.r*dr*dr, 1, 0, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var peak float64
+			for _, v := range last {
+				peak = max(peak, math.Abs(v))
+			}
+			if grew := !(peak <= 1); grew != tc.unstable {
+				t.Errorf("max |u| = %.3g after %d steps, unstable: %v", peak, nt, tc.unstable)
+			}
+			if warned := strings.Contains(warnings.String(), "may be unstable"); warned != tc.unstable {
+				t.Errorf("warning %v, want %v: %q", warned, tc.unstable, warnings.String())
+			}
+		})
+	}
+}
+
+// Веса строк — баланс тепла в ячейке, и на r² оператор точен: L·r² = 2(m+1)
+// (u_rr + (m/r)·u_r) в каждой строке i ≥ 1. С весом r_i² вместо объёма
+// ячейки у шара это нарушается в первых строках
+func TestRadialWeights(t *testing.T) {
+	for _, g := range []radialShape{cylinderShape, sphereShape} {
+		t.Run(g.name, func(t *testing.T) {
+			want := float64(2 * (g.m + 1))
+			for i := 1; i < 200; i++ {
+				lo, diag, hi := g.weights(i)
+				fi := float64(i)
+				if got := lo*(fi-1)*(fi-1) - diag*fi*fi + hi*(fi+1)*(fi+1); math.Abs(got-want) > 1e-9*fi*fi {
+					t.Fatalf("row %d: L·r² = %v, want %v", i, got, want)
+				}
+				if sum := lo - diag + hi; math.Abs(sum) > 1e-15 {
+					t.Fatalf("row %d: weights sum to %v", i, sum)
+				}
+			}
+		})
+	}
+}
+
+// CN против exp(−π²t)·sin(πr)/r: второй порядок при измельчении dr и dt
+// вдвое вместе и по L2, и в самом центре, где строка — отдельная формула
+func TestSphericalOrder(t *testing.T) {
+	exact := mathutils.SphereMode{Alpha: 1}
+	var prevL2, prevCenter float64
+	for k, nr := range []int{16, 32, 64, 128} {
+		dr, dt := 1/float64(nr), 0.064/float64(nr)
+		nt := int(math.Round(0.1 / dt))
+		u := SolveSpherical(nr, nt, dr, dt, 1, 0.5)
+		last := u[nt]
+		streamed, err := StreamSphericalFrom(SphereProfile(nr, dr), nt, dr, dt, 1, 0.5, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Equal(streamed, last) {
+			t.Fatalf("nr=%d: streamed run differs from SolveSpherical", nr)
+		}
+		l2, _ := mathutils.LevelErrors(last, dr, float64(nt)*dt, exact)
+		center := math.Abs(last[0] - exact.Eval(0, float64(nt)*dt))
+		if k > 0 {
+			for _, e := range []struct {
+				name       string
+				prev, next float64
+			}{{"L2", prevL2, l2}, {"center", prevCenter, center}} {
+				if order := math.Log2(e.prev / e.next); math.Abs(order-2) > 0.15 {
+					t.Errorf("nr=%d: %s error %.3g → %.3g, observed order %.2f", nr, e.name, e.prev, e.next, order)
+				}
+			}
+		}
+		prevL2, prevCenter = l2, center
+	}
+}
+
+// Предел явной схемы у шара — 2/6.367 ≈ 0.314
+func TestSphericalExplicitLimit(t *testing.T) {
+	const nr, nt = 32, 2000
+	dr := 1.0 / nr
+	for _, tc := range []struct {
+		r        float64
+		unstable bool
+	}{
+		{0.30, false},
+		{0.33, true},
+	} {
+		t.Run(fmt.Sprintf("r=%g", tc.r), func(t *testing.T) {
+			warnings := captureWarnings(t)
+			last, err := StreamSphericalFrom(SphereProfile(nr, dr), nt, dr, tc.r*dr*dr, 1, 0, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var peak float64
+			for _, v := range last {
+				peak = max(peak, math.Abs(v))
+			}
+			if grew := !(peak <= math.Pi); grew != tc.unstable {
+				t.Errorf("max |u| = %.3g after %d steps, unstable: %v", peak, nt, tc.unstable)
+			}
+			if warned := strings.Contains(warnings.String(), "may be unstable"); warned != tc.unstable {
+				t.Errorf("warning %v, want %v: %q", warned, tc.unstable, warnings.String())
+			}
+		})
+	}
+}
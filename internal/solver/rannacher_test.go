@@ -0,0 +1,128 @@
+package solver
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"heat-solver/internal/config"
+	"heat-solver/internal/mathutils"
+)
+
+// Первые два шага — по два полушага BTCS, дальше обычный CN
+func TestRannacherStartup(t *testing.T) {
+	const nx, nt = 40, 5
+	dx, dt := 1.0/nx, 0.01
+	u0 := TopHatProfile(nx)
+	u := SolveCrankNicolsonRannacherFrom(u0, nt, dx, dt)
+	half := stencilReference("BTCS", u0, 2*RannacherSteps, dx, dt/2)
+	want := [][]float64{u0}
+	for n := 1; n <= RannacherSteps; n++ {
+		want = append(want, half[2*n])
+	}
+	want = append(want, stencilReference("CN", want[RannacherSteps], nt-RannacherSteps, dx, dt)[1:]...)
+	for n := range u {
+		for i, v := range u[n] {
+			if math.Abs(v-want[n][i]) > 1e-14 {
+				t.Fatalf("level %d, node %d: %v, want %v", n, i, v, want[n][i])
+			}
+		}
+	}
+	last, err := StreamCrankNicolsonRannacherFrom(u0, nt, dx, dt, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range last {
+		if last[i] != u[nt][i] {
+			t.Fatalf("streamed node %d = %v, full run %v", i, last[i], u[nt][i])
+		}
+	}
+}
+
+// Ступенька при r от 8 до 40: колебания CN у разрывов (oscillation, полная
+// вариация сверх одного максимума) — десятые доли, со стартом Раннахера
+// они меньше хотя бы в десять раз
+func TestRannacherOscillation(t *testing.T) {
+	const nx, tmax = 40, 0.1
+	dx := 1.0 / nx
+	cn := mustLookup(t, "CN")
+	rm, err := RannacherMethod(cn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range []float64{8, 16, 40} {
+		t.Run(fmt.Sprintf("r=%g", r), func(t *testing.T) {
+			dt := r * dx * dx
+			nt := int(math.Round(tmax / dt))
+			u0 := TopHatProfile(nx)
+			plain, started := oscillation(cn.Solve(u0, nt, dx, dt)), oscillation(rm.Solve(u0, nt, dx, dt))
+			if plain < 0.1 || started > plain/10 {
+				t.Errorf("oscillation %.3g without the startup, %.3g with it", plain, started)
+			}
+		})
+	}
+}
+
+// Старт не портит второй порядок CN по времени: против точного решения
+// полудискретной задачи на sin(πx) ошибка падает вчетверо
+func TestRannacherTimeOrder(t *testing.T) {
+	const nx, tmax = 100, 0.4
+	dx := 1.0 / nx
+	exact := semiDiscreteSine(dx)
+	var prev float64
+	for k, dt := range []float64{0.01, 0.005, 0.0025} {
+		nt := int(math.Round(tmax / dt))
+		last, err := StreamCrankNicolsonRannacherFrom(SineProfile(nx, dx), nt, dx, dt, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		l2, _ := mathutils.LevelErrors(last, dx, float64(nt)*dt, exact)
+		if k > 0 {
+			if order := math.Log2(prev / l2); math.Abs(order-2) > 0.1 {
+				t.Errorf("dt=%g: error %.3g → %.3g, observed order %.2f", dt, prev, l2, order)
+			}
+		}
+		prev = l2
+	}
+}
+
+func TestRannacherMethod(t *testing.T) {
+	tests := []struct {
+		method string
+		ok     bool
+	}{
+		{"CN", true},
+		{"crank-nicolson", true},
+		{"BTCS", false},
+		{"FTCS", false},
+		{"BDF2", false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.method, func(t *testing.T) {
+			rm, err := RannacherMethod(mustLookup(t, tc.method))
+			if tc.ok != (err == nil) {
+				t.Fatalf("error %v, want ok: %v", err, tc.ok)
+			}
+			if tc.ok && rm.Startup != RannacherSteps {
+				t.Errorf("startup %d steps, want %d", rm.Startup, RannacherSteps)
+			}
+		})
+	}
+
+	byDefault := []struct {
+		p    config.Params
+		want bool
+	}{
+		{config.Params{Method: "CN", IC: config.ICTopHat}, true},
+		{config.Params{Method: "crank-nicolson", IC: "TopHat"}, true},
+		{config.Params{Method: "CN", IC: config.ICSine}, false},
+		{config.Params{Method: "CN", IC: config.ICTopHat, Adapt: true}, false},
+		{config.Params{Method: "CN", IC: config.ICTopHat, Stencil: config.StencilWide}, false},
+		{config.Params{Method: "BTCS", IC: config.ICTopHat}, false},
+	}
+	for _, tc := range byDefault {
+		if got := RannacherByDefault(tc.p); got != tc.want {
+			t.Errorf("RannacherByDefault(%+v) = %v, want %v", tc.p, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package solver
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"heat-solver/internal/config"
+	"heat-solver/internal/mathutils"
+)
+
+// Метод FTCS, BTCS или CN для u_t = u_xx − λu, как при Params.Lambda > 0.
+// У неявных схем λ·dt входит в диагональ матрицы шага с весом θ, у FTCS —
+// в явный шаг: u_i += r·δ²u_i − λdt·u_i. С sin(πx) решение остаётся
+// exp(−(π² + λ)t)·sin(πx)
+func ReactionMethod(m Method, lambda float64) (Method, error) {
+	if m.Adaptive {
+		return Method{}, &config.ValidationError{Field: "lambda", Message: "a reaction term is not available with adaptive time steps"}
+	}
+	if m.wide {
+		return Method{}, &config.ValidationError{Field: "lambda", Message: "a reaction term is not available with the wide stencil"}
+	}
+	var theta float64
+	switch m.Name {
+	case "FTCS":
+		theta = 0
+	case "BTCS":
+		theta = 1
+	case "CN":
+		theta = 0.5
+	default:
+		return Method{}, &config.ValidationError{Field: "lambda", Message: fmt.Sprintf("a reaction term is available for FTCS, BTCS and CN, not %s", m.Name)}
+	}
+	m.Reaction = lambda
+	m.Description += fmt.Sprintf("; linear reaction −λu with λ = %g", lambda)
+	if m.MaxR > 0 {
+		m.StabilityBound = fmt.Sprintf("r = dt/dx² ≤ %g − λ·dt/4", m.MaxR)
+	}
+	// Множитель перехода и матрица шага зависят от λ·dt, а не только от r
+	m.Amplification = nil
+	m.System = nil
+	m.setTheta(theta)
+	return m, nil
+}
+
+// Предел r θ-схемы с реакцией k = λ·dt. Символ старшей моды — 4r + k, и
+// при θ < ½ шаг устойчив, пока (1−2θ)(4r + k) ≤ 2, то есть
+// r ≤ 1/(2(1−2θ)) − k/4. Если k само выше предела, неустойчив любой r
+func reactionMaxR(theta, k float64) float64 {
+	maxR := thetaMaxR(theta)
+	if maxR == 0 || k == 0 {
+		return maxR
+	}
+	return math.Max(maxR-k/4, math.SmallestNonzeroFloat64)
+}
+
+// Проверочные случаи реакции; в Verify — под именем REACTION
+var verificationReaction = []VerifyCase{
+	reactionOrderCase("CN", 5, 0.1, 0.01, 0.2, 2, 2, 0.1),
+	reactionOrderCase("BTCS", 5, 0.1, 0.001, 0.2, 4, 2, 0.1),
+	reactionOrderCase("FTCS", 5, 0.1, 0.001, 0.2, 4, 2, 0.1),
+	reactionDecayCase(5, 0.02, 0.001, 0.2, 0.01),
+}
+
+// Наблюдаемый порядок метода name по dx при λ = lambda на sin(πx) против
+// exp(−(π² + λ)t)·sin(πx): dx делится пополам, dt — на dtFactor. Расчёт
+// идёт через Run с Params.Lambda, как из CLI и сервера
+func reactionOrderCase(name string, lambda, dx, dt, tmax, dtFactor, order, tol float64) VerifyCase {
+	return VerifyCase{
+		Name:      fmt.Sprintf("%s λ=%g order from dx=%g dt=%g", name, lambda, dx, dt),
+		Quantity:  "observed order",
+		Compare:   VerifyNear,
+		Expected:  order,
+		Tolerance: tol,
+		Measure: func(ctx context.Context, _ Method) (float64, string, error) {
+			var errs [3]float64
+			for k := range errs {
+				h, tau := dx/math.Exp2(float64(k)), dt/math.Pow(dtFactor, float64(k))
+				res, err := reactionRun(ctx, name, lambda, h, tau, tmax)
+				if err != nil {
+					return math.NaN(), "", err
+				}
+				errs[k], _ = mathutils.LevelErrors(res.Final, res.Dx, float64(res.Nt)*res.Dt, mathutils.Analytical{Alpha: 1, Lambda: lambda})
+				res.Release()
+			}
+			first, second := math.Log2(errs[0]/errs[1]), math.Log2(errs[1]/errs[2])
+			return second, fmt.Sprintf("errors %.3g, %.3g, %.3g; orders %.3f, %.3f", errs[0], errs[1], errs[2], first, second), nil
+		},
+	}
+}
+
+// Затухание в середине отрезка у CN с реакцией и без: скорости
+// ln(u(½, 0)/u(½, t))/t различаются на λ (у точного решения — ровно на λ)
+func reactionDecayCase(lambda, dx, dt, tmax, tol float64) VerifyCase {
+	return VerifyCase{
+		Name:      fmt.Sprintf("CN decay rate with λ=%g dx=%g dt=%g", lambda, dx, dt),
+		Quantity:  "rate difference",
+		Compare:   VerifyNear,
+		Expected:  lambda,
+		Tolerance: tol,
+		Measure: func(ctx context.Context, _ Method) (float64, string, error) {
+			var rates [2]float64
+			for k, l := range []float64{0, lambda} {
+				res, err := reactionRun(ctx, "CN", l, dx, dt, tmax)
+				if err != nil {
+					return math.NaN(), "", err
+				}
+				mid := res.Nx / 2
+				rates[k] = math.Log(res.Level(0)[mid]/res.Final[mid]) / (float64(res.Nt) * res.Dt)
+				res.Release()
+			}
+			return rates[1] - rates[0], fmt.Sprintf("rates %.5g without and %.5g with reaction, exact %.5g and %.5g",
+				rates[0], rates[1], math.Pi*math.Pi, math.Pi*math.Pi+lambda), nil
+		},
+	}
+}
+
+func reactionRun(ctx context.Context, name string, lambda, dx, dt, tmax float64) (*Result, error) {
+	p := config.Params{Method: name, Dx: dx, Dt: dt, Tmax: tmax, Lambda: lambda, Storage: config.StorageFinalOnly, Precision: config.PrecisionFloat64}
+	res, err := Run(ctx, p, Hooks{})
+	if err != nil {
+		return nil, err
+	}
+	if res.Diverged {
+		res.Release()
+		return nil, fmt.Errorf("%s solve with dx=%g dt=%g diverged", name, dx, dt)
+	}
+	return res, nil
+}
@@ -0,0 +1,112 @@
+package solver
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"heat-solver/internal/mathutils"
+)
+
+// λ = 5 на sin(πx): в середине отрезка u спадает как e^{−(π²+λ)t}, то
+// есть быстрее, чем без реакции, ровно на λ; у схем — до ошибки по dx и dt
+func TestReactionDecay(t *testing.T) {
+	const lambda, dx, tmax = 5, 0.02, 0.2
+	tests := []struct {
+		method string
+		dt     float64
+	}{
+		{"FTCS", 0.0001},
+		{"BTCS", 0.0001},
+		{"CN", 0.001},
+	}
+	for _, tc := range tests {
+		t.Run(tc.method, func(t *testing.T) {
+			var rates [2]float64
+			for k, l := range []float64{0, lambda} {
+				res, err := reactionRun(context.Background(), tc.method, l, dx, tc.dt, tmax)
+				if err != nil {
+					t.Fatal(err)
+				}
+				mid := res.Nx / 2
+				rates[k] = math.Log(res.Level(0)[mid]/res.Final[mid]) / (float64(res.Nt) * res.Dt)
+				res.Release()
+			}
+			if want := math.Pi*math.Pi + lambda; math.Abs(rates[1]-want)/want > 2e-3 {
+				t.Errorf("rate %.5f, want π²+λ = %.5f", rates[1], want)
+			}
+			if math.Abs(rates[1]-rates[0]-lambda) > 0.01 {
+				t.Errorf("rates %.5f and %.5f differ by %.5f, want λ = %g", rates[0], rates[1], rates[1]-rates[0], float64(lambda))
+			}
+		})
+	}
+}
+
+// Ошибка L2 против e^{−(π²+λ)t}·sin(πx) при λ = 5: у CN при dt ~ dx —
+// второй порядок, у FTCS и BTCS при dt ~ dx² — второй по dx
+func TestReactionOrder(t *testing.T) {
+	const lambda, tmax = 5, 0.2
+	tests := []struct {
+		method       string
+		dt, dtFactor float64
+	}{
+		{"CN", 0.01, 2},
+		{"BTCS", 0.001, 4},
+		{"FTCS", 0.001, 4},
+	}
+	exact := mathutils.Analytical{Alpha: 1, Lambda: lambda}
+	for _, tc := range tests {
+		t.Run(tc.method, func(t *testing.T) {
+			var errs [3]float64
+			for k := range errs {
+				dx, dt := 0.1/math.Exp2(float64(k)), tc.dt/math.Pow(tc.dtFactor, float64(k))
+				res, err := reactionRun(context.Background(), tc.method, lambda, dx, dt, tmax)
+				if err != nil {
+					t.Fatal(err)
+				}
+				errs[k], _ = mathutils.LevelErrors(res.Final, res.Dx, float64(res.Nt)*res.Dt, exact)
+				res.Release()
+			}
+			for k := 1; k < len(errs); k++ {
+				if order := math.Log2(errs[k-1] / errs[k]); math.Abs(order-2) > 0.1 {
+					t.Errorf("L2 errors %.3g, %.3g, %.3g: order %.3f", errs[0], errs[1], errs[2], order)
+				}
+			}
+		})
+	}
+}
+
+// Предел r с реакцией: у FTCS ½ − λdt/4, у неявных схем предела нет, а
+// ReactionMethod берёт только FTCS, BTCS и CN
+func TestReactionMethod(t *testing.T) {
+	for _, tc := range []struct {
+		theta, k, want float64
+	}{
+		{0, 0, 0.5},
+		{0, 0.4, 0.4},
+		{0.25, 0.4, 0.9},
+		{0.5, 3, 0},
+		{1, 3, 0},
+	} {
+		if got := reactionMaxR(tc.theta, tc.k); math.Abs(got-tc.want) > 1e-15 {
+			t.Errorf("θ=%g λdt=%g: max r %v, want %v", tc.theta, tc.k, got, tc.want)
+		}
+	}
+	if got := reactionMaxR(0, 3); got <= 0 || got > 1e-300 {
+		t.Errorf("λdt above the limit: max r %v, want the smallest positive", got)
+	}
+	for _, name := range []string{"FTCS", "BTCS", "CN"} {
+		m, err := ReactionMethod(mustLookup(t, name), 5)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if m.Reaction != 5 {
+			t.Errorf("%s: reaction %v", name, m.Reaction)
+		}
+	}
+	for _, name := range []string{"RK4", "BDF2", "RK45"} {
+		if _, err := ReactionMethod(mustLookup(t, name), 5); err == nil {
+			t.Errorf("%s accepted", name)
+		}
+	}
+}
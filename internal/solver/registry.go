@@ -0,0 +1,420 @@
+package solver
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+
+	"heat-solver/internal/mathutils"
+)
+
+// Функция расчёта схемы на равномерной сетке из начального профиля u0
+// (nx+1 значений); возвращает nt+1 временных слоёв
+type SolveFunc func(u0 []float64, nt int, dx, dt float64) [][]float64
+
+// Тот же расчёт с хранением только двух слоёв: каждый слой передаётся
+// в emit (может быть nil), возвращается последний
+type StreamFunc func(u0 []float64, nt int, dx, dt float64, emit EmitFunc) ([]float64, error)
+
+// Описание метода в реестре
+type Method struct {
+	Name        string
+	Aliases     []string
+	Description string
+	Explicit    bool
+	// Условная устойчивость задаётся формулой ограничения, пустая строка — безусловная
+	StabilityBound string
+	// Предел r = dt/dx² для условно устойчивых схем, 0 — без предела
+	MaxR       float64
+	OrderTime  int
+	OrderSpace int
+	Solve      SolveFunc
+	Stream     StreamFunc
+	// Множитель перехода фон Неймана; nil — не известен
+	Amplification AmplificationFunc
+	// Матрица шага неявной схемы; nil — явная схема без системы
+	System SystemFunc
+	// Случаи с известным ответом для Verify; метод без них не проверяется
+	Verification []VerifyCase
+	// Число слоёв, от которых зависит шаг: 0 и 2 — двухслойная схема,
+	// 3 — трёхслойная (продолжить расчёт с одного слоя нельзя)
+	Levels int
+	// Шаг интегрирования выбирается по оценке ошибки, dt — только шаг
+	// выдачи слоёв. Повторный расчёт от слоя выдачи не совпадает с
+	// исходным, порядка по dt нет
+	Adaptive bool
+	// Неизвестные — средние по ячейкам, узловые слои восстанавливаются по
+	// ним; продолжить расчёт с узлового слоя без потерь нельзя
+	CellCentered bool
+	// Слой — комбинация двух расчётов с разными шагами (экстраполяция
+	// Ричардсона); продолжить расчёт с него нельзя
+	Combined bool
+	// Шагов в начале расчёта по другой схеме (старт Раннахера): повтор от
+	// контрольного слоя посреди расчёта прошёл бы без них
+	Startup int
+	// Шаг зависит от номера шага с этим периодом (у HOPSCOTCH явные и
+	// неявные узлы меняются через шаг — 2); 0 и 1 — не зависит. Повтор от
+	// контрольного слоя совпадает с исходным, только если номер слоя
+	// кратен периоду
+	Period int
+	// Коэффициент линейной реакции λ в u_t = u_xx − λu (ReactionMethod);
+	// 0 — чистая теплопроводность
+	Reaction float64
+	// Объёмный источник f(x, t) в u_t = u_xx + f (SourceMethod); нулевой —
+	// источника нет
+	Source mathutils.Source
+
+	// Широкий пятиточечный шаблон (WideMethod): OrderSpace = 4 тогда не
+	// означает компактную схему
+	wide bool
+
+	// Расчёт в готовую сетку из nt+1 слоёв (встроенные схемы): Run берёт
+	// её из пула буферов. Без него Run вызывает Solve. Ошибка — только от
+	// проверки невязки в режиме ResidualStrict
+	solveInto func(u0 []float64, nt int, dx, dt float64, u [][]float64) error
+}
+
+func (m Method) Unconditional() bool {
+	return m.StabilityBound == ""
+}
+
+// Выполнено ли условие устойчивости при данном r
+func (m Method) StableAt(r float64) bool {
+	return m.MaxR == 0 || r <= m.MaxR
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   []Method
+	byName     = map[string]int{}
+)
+
+// Регистрация метода; имя и псевдонимы не зависят от регистра
+func Register(m Method) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	names := append([]string{m.Name}, m.Aliases...)
+	for _, n := range names {
+		if _, dup := byName[strings.ToUpper(n)]; dup {
+			panic(fmt.Sprintf("solver: method name %q already registered", n))
+		}
+	}
+	registry = append(registry, m)
+	for _, n := range names {
+		byName[strings.ToUpper(n)] = len(registry) - 1
+	}
+}
+
+// Поиск метода по имени или псевдониму
+func Lookup(name string) (Method, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	i, ok := byName[strings.ToUpper(strings.TrimSpace(name))]
+	if !ok {
+		return Method{}, false
+	}
+	return registry[i], true
+}
+
+// Все методы в порядке регистрации
+func Registered() []Method {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	return append([]Method(nil), registry...)
+}
+
+func init() {
+	Register(Method{
+		Name:           "FTCS",
+		Aliases:        []string{"EXPLICIT"},
+		Description:    "Forward Euler in time, central differences in space",
+		Explicit:       true,
+		StabilityBound: "r = dt/dx² ≤ 1/2",
+		MaxR:           0.5,
+		OrderTime:      1,
+		OrderSpace:     2,
+		Solve:          SolveFTCSFrom,
+		Stream:         StreamFTCSFrom,
+		Amplification:  WeightedAmplification(0),
+		Verification: []VerifyCase{
+			SineErrorCase(0.05, 0.001, 0.1, 1e-3),
+			SineOrderCase(0.05, 0.001, 0.1, 2, 0.1),
+			HeatBalanceCase(0.02, 0.0001, 0.1, 2.5e-3),
+			SteadyStateCase(0.05, 0.001, 2, 1e-8),
+			UnstableCase(0.52, 20, 1000),
+		},
+		solveInto: func(u0 []float64, nt int, dx, dt float64, u [][]float64) error {
+			return thetaScheme(u0, nt, dx, dt, 1, 0, u, nil)
+		},
+	})
+	Register(Method{
+		Name:          "BTCS",
+		Aliases:       []string{"IMPLICIT"},
+		Description:   "Backward Euler in time, central differences in space",
+		OrderTime:     1,
+		OrderSpace:    2,
+		Solve:         SolveBTCSFrom,
+		Stream:        StreamBTCSFrom,
+		Amplification: WeightedAmplification(1),
+		System: func(n int, r float64) (a, b, c []float64) {
+			return constantSystem(n, -r, 1+2*r, -r)
+		},
+		Verification: []VerifyCase{
+			SineErrorCase(0.05, 0.001, 0.1, 2.5e-3),
+			SineOrderCase(0.05, 0.001, 0.1, 2, 0.1),
+			TimeOrderCase(0.01, 0.01, 0.4, 1, 0.1),
+			HeatBalanceCase(0.02, 0.0001, 0.1, 4e-3),
+			SteadyStateCase(0.05, 0.01, 2, 1e-8),
+		},
+		solveInto: func(u0 []float64, nt int, dx, dt float64, u [][]float64) error {
+			return thetaScheme(u0, nt, dx, dt, 1, 1, u, nil)
+		},
+	})
+	Register(Method{
+		Name:          "CN",
+		Aliases:       []string{"CRANK-NICOLSON", "CRANKNICOLSON"},
+		Description:   "Crank–Nicolson: trapezoidal rule in time, central differences in space",
+		OrderTime:     2,
+		OrderSpace:    2,
+		Solve:         SolveCrankNicolsonFrom,
+		Stream:        StreamCrankNicolsonFrom,
+		Amplification: WeightedAmplification(0.5),
+		System: func(n int, r float64) (a, b, c []float64) {
+			return constantSystem(n, -r/2, 1+r, -r/2)
+		},
+		Verification: []VerifyCase{
+			SineErrorCase(0.05, 0.001, 0.1, 7e-4),
+			SineOrderCase(0.05, 0.001, 0.1, 2, 0.1),
+			TimeOrderCase(0.01, 0.01, 0.4, 2, 0.1),
+			HeatBalanceCase(0.02, 0.0001, 0.1, 3e-3),
+			SteadyStateCase(0.05, 0.01, 2, 1e-8),
+			// Ступенька при r = 16: без старта зубцы у разрывов
+			RannacherOscillationCase(0.025, 0.01, 0.1, 0.1),
+		},
+		solveInto: func(u0 []float64, nt int, dx, dt float64, u [][]float64) error {
+			return thetaScheme(u0, nt, dx, dt, 1, 0.5, u, nil)
+		},
+	})
+	Register(Method{
+		Name:          "BDF2",
+		Description:   "Second-order backward differentiation in time, central differences in space",
+		OrderTime:     2,
+		OrderSpace:    2,
+		Solve:         SolveBDF2From,
+		Stream:        StreamBDF2From,
+		Amplification: bdf2Amplification,
+		System: func(n int, r float64) (a, b, c []float64) {
+			return constantSystem(n, -2*r, 3+4*r, -2*r)
+		},
+		Verification: []VerifyCase{
+			SineErrorCase(0.05, 0.001, 0.1, 1e-3),
+			SineOrderCase(0.05, 0.001, 0.1, 2, 0.1),
+			TimeOrderCase(0.01, 0.01, 0.4, 2, 0.1),
+			HeatBalanceCase(0.02, 0.0001, 0.1, 3e-3),
+			SteadyStateCase(0.05, 0.01, 2, 1e-8),
+		},
+		Levels: 3,
+		solveInto: func(u0 []float64, nt int, dx, dt float64, u [][]float64) error {
+			return bdf2(u0, nt, dx, dt, u, nil)
+		},
+	})
+	Register(Method{
+		Name:           "RK4",
+		Description:    "Method of lines: central differences in space, classical fourth-order Runge–Kutta in time",
+		Explicit:       true,
+		StabilityBound: "r = dt/dx² ≤ 0.696",
+		MaxR:           rk4MaxR,
+		OrderTime:      4,
+		OrderSpace:     2,
+		Solve:          SolveRK4From,
+		Stream:         StreamRK4From,
+		Amplification:  rk4Amplification,
+		Verification: []VerifyCase{
+			SineErrorCase(0.05, 0.001, 0.1, 6e-4),
+			TimeOrderCase(0.05, 0.0016, 0.4, 4, 0.1),
+			SteadyStateCase(0.05, 0.001, 2, 1e-8),
+			UnstableCase(0.72, 20, 1000),
+		},
+		solveInto: func(u0 []float64, nt int, dx, dt float64, u [][]float64) error {
+			return rk4(u0, nt, dx, dt, u, nil)
+		},
+	})
+	Register(Method{
+		Name:        "RK45",
+		Aliases:     []string{"DOPRI5", "DORMAND-PRINCE"},
+		Description: "Method of lines: central differences in space, adaptive Dormand–Prince 5(4) in time",
+		Explicit:    true,
+		OrderSpace:  2,
+		Solve:       SolveRK45From,
+		Stream:      StreamRK45From,
+		Verification: []VerifyCase{
+			SineErrorCase(0.05, 0.001, 0.1, 6e-4),
+			SteadyStateCase(0.05, 0.01, 2, 1e-8),
+		},
+		Adaptive: true,
+		solveInto: func(u0 []float64, nt int, dx, dt float64, u [][]float64) error {
+			return rk45(u0, nt, dx, dt, u, nil)
+		},
+	})
+	Register(Method{
+		Name:          "RKC",
+		Aliases:       []string{"RUNGE-KUTTA-CHEBYSHEV", "STS"},
+		Description:   "Method of lines: central differences in space, second-order Runge–Kutta–Chebyshev super-steps with the stage count chosen from r",
+		Explicit:      true,
+		OrderTime:     2,
+		OrderSpace:    2,
+		Solve:         SolveRKCFrom,
+		Stream:        StreamRKCFrom,
+		Amplification: rkcAmplification,
+		Verification: []VerifyCase{
+			SineErrorCase(0.05, 0.001, 0.1, 7e-4),
+			SineOrderCase(0.05, 0.001, 0.1, 2, 0.1),
+			TimeOrderCase(0.01, 0.01, 0.4, 2, 0.1),
+			HeatBalanceCase(0.02, 0.0001, 0.1, 3e-3),
+			SteadyStateCase(0.05, 0.01, 2, 1e-8),
+			BeyondFTCSCase(20, 20, 1000),
+			rkcAgainstBTCSCase(0.01, 20, 0.1),
+		},
+		solveInto: func(u0 []float64, nt int, dx, dt float64, u [][]float64) error {
+			return rkc(u0, nt, dx, dt, u, nil)
+		},
+	})
+	Register(Method{
+		Name:        "EXPONENTIAL",
+		Aliases:     []string{"EXP", "EXACT-TIME"},
+		Description: "Exponential integrator: central differences in space, exact in time through the discrete sine modes",
+		OrderSpace:  2,
+		Solve:       SolveExponentialFrom,
+		Stream:      StreamExponentialFrom,
+		// Амплитуда моды за шаг умножается на exp(λ·dt) = exp(−4r·sin²(θ/2))
+		Amplification: func(r, theta float64) float64 {
+			s := math.Sin(theta / 2)
+			return math.Exp(-4 * r * s * s)
+		},
+		Verification: []VerifyCase{
+			SineErrorCase(0.05, 0.01, 0.1, 6e-4),
+			SemiDiscreteCase(0.05, 0.01, 0.5, 1e-13),
+			SteadyStateCase(0.05, 0.1, 2, 1e-8),
+		},
+		solveInto: func(u0 []float64, nt int, dx, dt float64, u [][]float64) error {
+			return exponential(u0, nt, dx, dt, u, nil)
+		},
+	})
+	Register(Method{
+		Name:        "SPECTRAL",
+		Aliases:     []string{"DST"},
+		Description: "Pseudo-spectral: discrete sine transform in space, exact decay of every mode in time",
+		Solve:       SolveSpectralFrom,
+		Stream:      StreamSpectralFrom,
+		// Мода θ = kπ·dx за шаг умножается на exp(−(θ/dx)²·dt) = exp(−r·θ²)
+		Amplification: func(r, theta float64) float64 {
+			return math.Exp(-r * theta * theta)
+		},
+		Verification: []VerifyCase{
+			SineErrorCase(0.0625, 0.01, 0.5, 1e-14),
+			SteadyStateCase(0.05, 0.1, 2, 1e-8),
+		},
+		solveInto: func(u0 []float64, nt int, dx, dt float64, u [][]float64) error {
+			return spectral(u0, nt, dx, dt, u, nil)
+		},
+	})
+	Register(Method{
+		Name:          "DUFORT-FRANKEL",
+		Aliases:       []string{"DUFORTFRANKEL", "DF"},
+		Description:   "DuFort–Frankel: explicit three-level leapfrog with the centre node averaged in time",
+		Explicit:      true,
+		OrderTime:     2,
+		OrderSpace:    2,
+		Solve:         SolveDuFortFrankelFrom,
+		Stream:        StreamDuFortFrankelFrom,
+		Amplification: duFortFrankelAmplification,
+		// Порядок не проверяется: при измельчении с постоянным dt/dx
+		// несогласованность не убывает
+		Verification: []VerifyCase{
+			SineErrorCase(0.05, 0.001, 0.1, 1e-3),
+			SteadyStateCase(0.05, 0.001, 2, 1e-8),
+			BoundedCase(5, 20, 1000),
+		},
+		Levels: 3,
+		solveInto: func(u0 []float64, nt int, dx, dt float64, u [][]float64) error {
+			return duFortFrankel(u0, nt, dx, dt, u, nil)
+		},
+	})
+	Register(Method{
+		Name:        "SAULYEV",
+		Aliases:     []string{"SAUL'YEV", "ADE"},
+		Description: "Saul'yev alternating direction explicit: left-to-right and right-to-left sweeps averaged each step",
+		Explicit:    true,
+		// При постоянном dx ошибка по dt второго порядка, но в ней есть
+		// член (dt/dx)², так что при измельчении нужно dt ~ dx², как у
+		// схемы первого порядка
+		OrderTime:     1,
+		OrderSpace:    2,
+		Solve:         SolveSaulyevFrom,
+		Stream:        StreamSaulyevFrom,
+		Amplification: saulyevAmplification,
+		Verification: []VerifyCase{
+			SineErrorCase(0.05, 0.001, 0.1, 2e-3),
+			SineOrderCase(0.05, 0.001, 0.1, 2, 0.1),
+			TimeOrderCase(0.05, 0.01, 0.4, 2, 0.1),
+			HeatBalanceCase(0.02, 0.0001, 0.1, 3e-3),
+			SteadyStateCase(0.05, 0.001, 2, 1e-8),
+			// Там, где FTCS расходится
+			BeyondFTCSCase(2, 20, 1000),
+		},
+		solveInto: func(u0 []float64, nt int, dx, dt float64, u [][]float64) error {
+			return saulyev(u0, nt, dx, dt, u, nil)
+		},
+	})
+	Register(Method{
+		Name:        "HOPSCOTCH",
+		Aliases:     []string{"ODD-EVEN-HOPSCOTCH"},
+		Description: "Odd–even hopscotch: explicit and implicit-by-substitution updates on alternating nodes, no tridiagonal solve",
+		Explicit:    true,
+		// Как у SAULYEV: член (dt/dx)² требует dt ~ dx²
+		OrderTime:  1,
+		OrderSpace: 2,
+		Period:     2,
+		Solve:      SolveHopscotchFrom,
+		Stream:     StreamHopscotchFrom,
+		Verification: []VerifyCase{
+			SineErrorCase(0.05, 0.001, 0.1, 1e-3),
+			// r = 1
+			SineErrorCase(0.05, 0.0025, 0.1, 7e-3),
+			SineOrderCase(0.05, 0.001, 0.1, 2, 0.1),
+			HeatBalanceCase(0.02, 0.0001, 0.1, 3e-3),
+			SteadyStateCase(0.05, 0.001, 2, 1e-8),
+			BeyondFTCSCase(2, 20, 1000),
+		},
+		solveInto: func(u0 []float64, nt int, dx, dt float64, u [][]float64) error {
+			return hopscotch(u0, nt, dx, dt, u, nil)
+		},
+	})
+	Register(Method{
+		Name:        "BTCS-RICHARDSON",
+		Aliases:     []string{"BTCS-RE", "RICHARDSON"},
+		Description: "BTCS at dt and dt/2 combined as 2·u(dt/2) − u(dt): Richardson extrapolation to second order in time",
+		OrderTime:   2,
+		OrderSpace:  2,
+		Solve:       SolveExtrapolatedBTCSFrom,
+		Stream:      StreamExtrapolatedBTCSFrom,
+		// Матрица грубого расчёта, обусловленная хуже мелкой
+		System: func(n int, r float64) (a, b, c []float64) {
+			return constantSystem(n, -r, 1+2*r, -r)
+		},
+		Verification: []VerifyCase{
+			SineErrorCase(0.05, 0.001, 0.1, 7e-4),
+			SineOrderCase(0.05, 0.001, 0.1, 2, 0.1),
+			TimeOrderCase(0.01, 0.01, 0.4, 2, 0.1),
+			HeatBalanceCase(0.02, 0.0001, 0.1, 3e-3),
+			SteadyStateCase(0.05, 0.01, 2, 1e-8),
+		},
+		Combined: true,
+		solveInto: func(u0 []float64, nt int, dx, dt float64, u [][]float64) error {
+			return extrapolatedBTCS(u0, nt, dx, dt, u, nil)
+		},
+	})
+}
@@ -0,0 +1,137 @@
+package solver
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"sync/atomic"
+)
+
+// Проверка невязки ‖A·x − d‖∞ после каждой прогонки в BTCS и CN.
+// По умолчанию выключена: проверка — ещё один проход по системе на шаге
+const (
+	ResidualOff = "off"
+	// Превышение допуска — предупреждение в логе, расчёт продолжается
+	ResidualWarn = "warn"
+	// Превышение допуска прерывает расчёт с ErrResidual
+	ResidualStrict = "strict"
+)
+
+// Невязка прогонки превысила допуск (режим ResidualStrict)
+var ErrResidual = errors.New("tridiagonal residual exceeds tolerance")
+
+// Допуск невязки: residualFactor·ε·(‖A‖∞·‖x‖∞ + ‖d‖∞). Прогонка для
+// матриц с диагональным преобладанием обратно устойчива с малой
+// константой, так что исправное решение остаётся на порядки ниже
+const residualFactor = 64
+
+var residualMode atomic.Value
+
+// Режим проверки невязки для расчётов, начатых после вызова
+func SetResidualCheck(mode string) error {
+	switch mode {
+	case ResidualOff, ResidualWarn, ResidualStrict:
+		residualMode.Store(mode)
+		return nil
+	}
+	return fmt.Errorf("unknown residual check %q, want %s, %s or %s", mode, ResidualOff, ResidualWarn, ResidualStrict)
+}
+
+func ResidualCheck() string {
+	if mode, ok := residualMode.Load().(string); ok {
+		return mode
+	}
+	return ResidualOff
+}
+
+// Прогонка с проверкой невязки каждого шага. Матрица (a, b, c) та же,
+// что разложена во вложенном решателе; d после solve не меняется, поэтому
+// копии правой части не нужны
+type checkedTridiag struct {
+	tridiagSolver
+	a, b, c []float64
+	normA   float64
+	strict  bool
+
+	steps    int
+	exceeded int
+	// Наибольшая невязка и наибольшее её отношение к допуску
+	maxResidual, maxRatio float64
+	err                   error
+}
+
+func newCheckedTridiag(t tridiagSolver, a, b, c []float64, strict bool) *checkedTridiag {
+	ct := &checkedTridiag{tridiagSolver: t, a: a, b: b, c: c, strict: strict}
+	for i := range b {
+		ct.normA = max(ct.normA, math.Abs(a[i])+math.Abs(b[i])+math.Abs(c[i]))
+	}
+	return ct
+}
+
+func (t *checkedTridiag) solve(d, x []float64) {
+	t.tridiagSolver.solve(d, x)
+	t.steps++
+
+	res, normX, normD := tridiagResidual(t.a, t.b, t.c, d, x)
+	tol := residualFactor * 0x1p-52 * (t.normA*normX + normD)
+	t.maxResidual = max(t.maxResidual, res)
+	if tol > 0 {
+		t.maxRatio = max(t.maxRatio, res/tol)
+	}
+	// NaN в невязке — тоже отказ
+	if res <= tol {
+		return
+	}
+	t.exceeded++
+	if t.exceeded == 1 {
+		slog.Warn("Tridiagonal residual exceeds tolerance", "step", t.steps, "residual", res, "tolerance", tol)
+	}
+	if t.strict && t.err == nil {
+		t.err = fmt.Errorf("%w: step %d, residual %g > %g", ErrResidual, t.steps, res, tol)
+	}
+}
+
+func (t *checkedTridiag) close() {
+	slog.Info("Tridiagonal residual check", "steps", t.steps, "max_residual", t.maxResidual,
+		"max_residual_to_tolerance", t.maxRatio, "exceeded", t.exceeded)
+	t.tridiagSolver.close()
+}
+
+// ‖A·x − d‖∞, ‖x‖∞ и ‖d‖∞ для трёхдиагональной A (a[0] и c[n−1] не
+// входят). Крайние строки считаются отдельно, внутренние — срезами одной
+// длины без проверок границ. NaN в невязке сохраняется в res
+func tridiagResidual(a, b, c, d, x []float64) (res, normX, normD float64) {
+	n := len(d)
+	if n == 1 {
+		return math.Abs(b[0]*x[0] - d[0]), math.Abs(x[0]), math.Abs(d[0])
+	}
+	res = max(math.Abs(b[0]*x[0]+c[0]*x[1]-d[0]), math.Abs(a[n-1]*x[n-2]+b[n-1]*x[n-1]-d[n-1]))
+	normX = max(math.Abs(x[0]), math.Abs(x[n-1]))
+	normD = max(math.Abs(d[0]), math.Abs(d[n-1]))
+
+	m := n - 2
+	left, mid, right := x[:m], x[1 : m+1], x[2 : m+2]
+	ai, bi, ci, di := a[1:m+1], b[1:m+1], c[1:m+1], d[1:m+1]
+	for i := range m {
+		r := math.Abs(ai[i]*left[i] + bi[i]*mid[i] + ci[i]*right[i] - di[i])
+		if r > res || r != r {
+			res = r
+		}
+		if v := math.Abs(mid[i]); v > normX {
+			normX = v
+		}
+		if v := math.Abs(di[i]); v > normD {
+			normD = v
+		}
+	}
+	return res, normX, normD
+}
+
+// Ошибка проверки невязки в режиме strict; nil для остальных решателей
+func tridiagErr(t tridiagSolver) error {
+	if ct, ok := t.(*checkedTridiag); ok {
+		return ct.err
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package solver
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"slices"
+	"testing"
+
+	"heat-solver/internal/config"
+)
+
+// Режим проверки невязки на время теста
+func withResidualCheck(t testing.TB, mode string) {
+	t.Helper()
+	prev := ResidualCheck()
+	if err := SetResidualCheck(mode); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { SetResidualCheck(prev) })
+}
+
+// Матрица шага CN при данном r на n неизвестных
+func cnMatrix(n int, r float64) (a, b, c []float64) {
+	a, b, c = make([]float64, n), make([]float64, n), make([]float64, n)
+	for i := range n {
+		a[i], b[i], c[i] = -r/2, 1+r, -r/2
+	}
+	return a, b, c
+}
+
+func TestTridiagResidual(t *testing.T) {
+	tests := []struct {
+		name              string
+		a, b, c, d, x     []float64
+		res, normX, normD float64
+	}{
+		{"one row", []float64{9}, []float64{2}, []float64{9}, []float64{3}, []float64{1}, 1, 1, 3},
+		{"two rows exact", []float64{0, 1}, []float64{2, 2}, []float64{1, 0}, []float64{5, 4}, []float64{2, 1}, 0, 2, 5},
+		{"interior row", []float64{0, 1, 1, 1}, []float64{2, 2, 2, 2}, []float64{1, 1, 1, 0},
+			[]float64{1, 1, 1.5, 1}, []float64{0.5, 0, 0.5, 0.25}, 0.25, 0.5, 1.5},
+		{"NaN", []float64{0, 1, 1}, []float64{2, 2, 2}, []float64{1, 1, 0}, []float64{0, 0, 0}, []float64{0, math.NaN(), 0}, math.NaN(), 0, 0},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			res, normX, normD := tridiagResidual(tc.a, tc.b, tc.c, tc.d, tc.x)
+			if !(res == tc.res || math.IsNaN(res) && math.IsNaN(tc.res)) || normX != tc.normX || normD != tc.normD {
+				t.Errorf("residual %v, ‖x‖ %v, ‖d‖ %v; want %v, %v, %v", res, normX, normD, tc.res, tc.normX, tc.normD)
+			}
+		})
+	}
+}
+
+// Исправная прогонка CN держит невязку на порядок ниже допуска;
+// разложение с испорченной опорой (ведущим элементом) ловится в первом
+// же шаге: в strict — ошибкой, в warn — только счётом
+func TestCheckedTridiag(t *testing.T) {
+	const n, steps = 1000, 50
+	rng := rand.New(rand.NewSource(1))
+	tests := []struct {
+		name     string
+		r        float64
+		pivot    int // испорченная строка разложения; −1 — без порчи
+		strict   bool
+		exceeded int
+	}{
+		{"healthy r=0.5", 0.5, -1, true, 0},
+		{"healthy r=1e4", 1e4, -1, true, 0},
+		{"corrupted pivot strict", 0.5, 400, true, steps},
+		{"corrupted pivot warn", 0.5, 400, false, steps},
+		{"corrupted last pivot", 100, n - 1, true, steps},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			a, b, c := cnMatrix(n, tc.r)
+			factored := slices.Clone(b)
+			if tc.pivot >= 0 {
+				factored[tc.pivot] *= 1 + 1e-6
+			}
+			inner := newThomas(n)
+			inner.factor(a, factored, c)
+			ct := newCheckedTridiag(inner, a, b, c, tc.strict)
+			d, x := make([]float64, n), make([]float64, n)
+			for range steps {
+				for i := range d {
+					d[i] = rng.NormFloat64()
+				}
+				ct.solve(d, x)
+			}
+			ct.close()
+
+			if ct.steps != steps || ct.exceeded != tc.exceeded {
+				t.Fatalf("%d steps with %d over tolerance, want %d with %d", ct.steps, ct.exceeded, steps, tc.exceeded)
+			}
+			if tc.exceeded == 0 && ct.maxRatio > 0.1 {
+				t.Errorf("healthy residual reaches %.3g of the tolerance", ct.maxRatio)
+			}
+			wantErr := tc.exceeded > 0 && tc.strict
+			if err := tridiagErr(ct); wantErr != errors.Is(err, ErrResidual) || !wantErr && err != nil {
+				t.Errorf("error %v, want ErrResidual: %v", err, wantErr)
+			}
+		})
+	}
+}
+
+// Проверка не меняет решение BTCS и CN ни при одном алгоритме прогонки
+func TestResidualCheckRun(t *testing.T) {
+	for _, algo := range []string{TridiagThomas, TridiagParallel} {
+		for _, method := range []string{"BTCS", "CN"} {
+			t.Run(algo+"/"+method, func(t *testing.T) {
+				withTridiagonal(t, algo)
+				withThreads(t, 2)
+				p := config.Params{Method: method, Dx: 1.0 / (1 << 16), Dt: 1e-6, Tmax: 2e-5, Storage: config.StorageFinalOnly}.Normalize()
+				withResidualCheck(t, ResidualOff)
+				plain, err := Run(context.Background(), p, Hooks{})
+				if err != nil {
+					t.Fatal(err)
+				}
+				defer plain.Release()
+				withResidualCheck(t, ResidualStrict)
+				checked, err := Run(context.Background(), p, Hooks{})
+				if err != nil {
+					t.Fatalf("strict check failed a healthy solve: %v", err)
+				}
+				defer checked.Release()
+				if !slices.Equal(plain.Final, checked.Final) {
+					t.Error("the residual check changed the solution")
+				}
+			})
+		}
+	}
+	if err := SetResidualCheck("sometimes"); err == nil {
+		t.Error("unknown residual mode accepted")
+	}
+}
@@ -0,0 +1,217 @@
+package solver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+
+	"heat-solver/internal/mathutils"
+)
+
+// BTCS с экстраполяцией Ричардсона по времени: два расчёта BTCS из u0, с
+// шагом dt и с шагом dt/2, идут параллельно, и слой n — это
+// 2·u_{dt/2}(n·dt) − u_dt(n·dt). Главный член ошибки O(dt) сокращается,
+// остаётся второй порядок по dt. Каждый расчёт ведёт своё состояние и
+// продолжается от него, а не от комбинации, поэтому у мод нет смены знака
+// от шага к шагу, как у CN при больших r
+func SolveExtrapolatedBTCS(nx, nt int, dx, dt float64) [][]float64 {
+	return SolveExtrapolatedBTCSFrom(SineProfile(nx, dx), nt, dx, dt)
+}
+
+// Экстраполированный BTCS с заданным начальным профилем u0 (nx+1 значений)
+func SolveExtrapolatedBTCSFrom(u0 []float64, nt int, dx, dt float64) [][]float64 {
+	u := newGrid(nt+1, len(u0))
+	extrapolatedBTCS(u0, nt, dx, dt, u, nil)
+	return u
+}
+
+// Экстраполированный BTCS с хранением двух слоёв выдачи; каждый слой
+// передаётся в emit
+func StreamExtrapolatedBTCSFrom(u0 []float64, nt int, dx, dt float64, emit EmitFunc) ([]float64, error) {
+	u := newGrid(2, len(u0))
+	if err := extrapolatedBTCS(u0, nt, dx, dt, u, emit); err != nil {
+		return nil, err
+	}
+	return level(u, nt), nil
+}
+
+// Состояния грубого (dt) и мелкого (dt/2) расчётов хранятся отдельно от
+// слоёв выдачи; на шаг выдачи — одна прогонка с r и две с r/2
+func extrapolatedBTCS(u0 []float64, nt int, dx, dt float64, u [][]float64, emit EmitFunc) error {
+	nx := len(u0) - 1
+	r := dt / (dx * dx)
+	slog.Info("Starting extrapolated BTCS solver", "nx", nx, "nt", nt, "dx", dx, "dt", dt, "r", r)
+
+	if err := start(u, u0, emit); err != nil {
+		return err
+	}
+
+	ws := pool64.get(2 * (nx + 1))
+	defer pool64.put(ws)
+	coarse, fine := ws[:nx+1:nx+1], ws[nx+1:]
+	copy(coarse, level(u, 0))
+	copy(fine, coarse)
+
+	a, b, c, d, sysCoarse := workspace(nx - 1)
+	defer pool64.put(sysCoarse)
+	ah, bh, ch, _, sysFine := workspace(nx - 1)
+	defer pool64.put(sysFine)
+	for i := range b {
+		a[i], b[i], c[i] = -r, 1+2*r, -r
+		ah[i], bh[i], ch[i] = -r/2, 1+r, -r/2
+	}
+	triCoarse := newTridiag(a, b, c)
+	defer triCoarse.close()
+	triFine := newTridiag(ah, bh, ch)
+	defer triFine.close()
+
+	// Шаг BTCS на месте: края нулевые, правая часть — внутренние узлы
+	step := func(tri tridiagSolver, y []float64) error {
+		copy(d, y[1:nx])
+		tri.solve(d, y[1:nx])
+		return tridiagErr(tri)
+	}
+	for n := 0; n < nt; n++ {
+		if err := step(triCoarse, coarse); err != nil {
+			return err
+		}
+		for range 2 {
+			if err := step(triFine, fine); err != nil {
+				return err
+			}
+		}
+		next := level(u, n+1)
+		for i := range next {
+			next[i] = 2*fine[i] - coarse[i]
+		}
+		if err := emitLevel(emit, n+1, next); err != nil {
+			return err
+		}
+	}
+
+	slog.Info("Extrapolated BTCS solver finished successfully")
+	return nil
+}
+
+// Слой экстраполяции по пространству: raw — расчёт на заданной сетке,
+// extrapolated — комбинация с расчётом на вдвое более мелкой
+type RichardsonEmitFunc func(n int, raw, extrapolated []float64) error
+
+// Экстраполяция Ричардсона по пространству: схема m считает из coarse0
+// на сетке dx (nx+1 узлов) и из fine0 на сетке dx/2 (2nx+1 узлов) с одним
+// и тем же dt, и в узлах грубой сетки слой n — (4·u_{dx/2} − u_dx)/3.
+// Главный член ошибки по пространству O(dx²) сокращается, и у схемы
+// второго порядка остаётся четвёртый, если ошибка по времени меньше.
+// Оба расчёта идут одновременно: мелкий — в своей горутине, и в грубый
+// передаются только его значения в узлах грубой сетки, поэтому в памяти
+// несколько слоёв, а не весь мелкий расчёт. Возвращает последние слои
+// грубого расчёта и экстраполяции
+func StreamSpatialRichardson(m Method, coarse0, fine0 []float64, nt int, dx, dt float64, emit RichardsonEmitFunc) (raw, extrapolated []float64, err error) {
+	nx := len(coarse0) - 1
+	if len(fine0) != 2*nx+1 {
+		return nil, nil, fmt.Errorf("fine initial profile has %d nodes, want %d", len(fine0), 2*nx+1)
+	}
+	slog.Info("Starting spatial Richardson extrapolation", "method", m.Name, "nx", nx, "nx_fine", 2*nx, "nt", nt)
+
+	levels := make(chan []float64, 4)
+	done := make(chan struct{})
+	var fineErr error
+	go func() {
+		defer close(levels)
+		_, fineErr = m.Stream(fine0, nt, dx/2, dt, func(n int, row []float64) error {
+			s := pool64.get(nx + 1)
+			for i := range s {
+				s[i] = row[2*i]
+			}
+			select {
+			case levels <- s:
+				return nil
+			case <-done:
+				pool64.put(s)
+				return errRichardsonStopped
+			}
+		})
+	}()
+	// Если грубый расчёт остановился раньше, мелкий снимается; ошибка
+	// мелкого — первопричина и важнее ошибки грубого
+	defer func() {
+		close(done)
+		for s := range levels {
+			pool64.put(s)
+		}
+		if fineErr != nil && !errors.Is(fineErr, errRichardsonStopped) {
+			err = fmt.Errorf("fine grid: %w", fineErr)
+		}
+	}()
+
+	extrapolated = make([]float64, nx+1)
+	raw, err = m.Stream(coarse0, nt, dx, dt, func(n int, row []float64) error {
+		s, ok := <-levels
+		if !ok {
+			return fmt.Errorf("fine grid stopped before level %d", n)
+		}
+		for i := range extrapolated {
+			extrapolated[i] = (4*s[i] - row[i]) / 3
+		}
+		pool64.put(s)
+		if emit == nil {
+			return nil
+		}
+		return emit(n, row, extrapolated)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	slog.Info("Spatial Richardson extrapolation finished successfully")
+	return raw, extrapolated, nil
+}
+
+var errRichardsonStopped = errors.New("coarse grid stopped")
+
+// Проверочные случаи экстраполяции по пространству; в Verify — под
+// именем SPACE-RICHARDSON
+var verificationSpatialRichardson = []VerifyCase{
+	spatialRichardsonOrderCase("CN", 0.2, 1e-5, 0.1, 4, 0.15),
+	spatialRichardsonOrderCase("RK4", 0.2, 1e-5, 0.1, 4, 0.15),
+}
+
+// Наблюдаемый порядок по dx экстраполяции из sin(πx) за два деления dx
+// пополам при постоянном маленьком dt, чтобы ошибка по времени не
+// заслоняла пространственную. В подробностях — ошибки и порядок без
+// экстраполяции (второй)
+func spatialRichardsonOrderCase(name string, dx, dt, tmax, order, tol float64) VerifyCase {
+	return VerifyCase{
+		Name:      fmt.Sprintf("%s extrapolated order from dx=%g dt=%g", name, dx, dt),
+		Quantity:  "observed order in dx",
+		Compare:   VerifyNear,
+		Expected:  order,
+		Tolerance: tol,
+		Measure: func(ctx context.Context, _ Method) (float64, string, error) {
+			m, ok := Lookup(name)
+			if !ok {
+				return math.NaN(), "", fmt.Errorf("unknown method %s", name)
+			}
+			nt := int(math.Round(tmax / dt))
+			exact := mathutils.Analytical{Alpha: 1}
+			var raw, ext [3]float64
+			for k := range raw {
+				if err := ctx.Err(); err != nil {
+					return math.NaN(), "", err
+				}
+				h := dx / math.Exp2(float64(k))
+				nx := int(math.Round(1 / h))
+				lu, le, err := StreamSpatialRichardson(m, SineProfile(nx, h), SineProfile(2*nx, h/2), nt, h, dt, nil)
+				if err != nil {
+					return math.NaN(), "", err
+				}
+				raw[k], _ = mathutils.LevelErrors(lu, h, float64(nt)*dt, exact)
+				ext[k], _ = mathutils.LevelErrors(le, h, float64(nt)*dt, exact)
+			}
+			order := math.Log2(ext[1] / ext[2])
+			return order, fmt.Sprintf("errors %.3g, %.3g, %.3g (order %.3f); raw %.3g, %.3g, %.3g (order %.3f)",
+				ext[0], ext[1], ext[2], order, raw[0], raw[1], raw[2], math.Log2(raw[1]/raw[2])), nil
+		},
+	}
+}
@@ -0,0 +1,214 @@
+package solver
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+
+	"heat-solver/internal/config"
+	"heat-solver/internal/mathutils"
+)
+
+// Слой — ровно 2·u_{dt/2}(n·dt) − u_dt(n·dt) по двум независимым расчётам
+// BTCS, а не комбинация, с которой расчёт продолжается
+func TestExtrapolatedBTCSCombination(t *testing.T) {
+	const nx, nt = 20, 15
+	dx, dt := 1.0/nx, 0.01
+	u0 := make([]float64, nx+1)
+	for i := range u0 {
+		x := float64(i) * dx
+		u0[i] = x * (1 - x) * (1 + 3*x)
+	}
+	coarse := stencilReference("BTCS", u0, nt, dx, dt)
+	fine := stencilReference("BTCS", u0, 2*nt, dx, dt/2)
+	u := SolveExtrapolatedBTCSFrom(u0, nt, dx, dt)
+	for n := range u {
+		for i, v := range u[n] {
+			if want := 2*fine[2*n][i] - coarse[n][i]; math.Abs(v-want) > 1e-14 {
+				t.Fatalf("level %d, node %d: %v, want %v", n, i, v, want)
+			}
+		}
+	}
+	last, err := StreamExtrapolatedBTCSFrom(u0, nt, dx, dt, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range last {
+		if last[i] != u[nt][i] {
+			t.Fatalf("streamed node %d = %v, full run %v", i, last[i], u[nt][i])
+		}
+	}
+}
+
+// Порядок по dt против точного решения полудискретной задачи: у BTCS —
+// первый, у экстраполированного BTCS на тех же шагах — второй
+func TestExtrapolatedBTCSTimeOrder(t *testing.T) {
+	const nx, tmax = 50, 0.4
+	dx := 1.0 / nx
+	exact := semiDiscreteSine(dx)
+	tests := []struct {
+		method string
+		order  float64
+	}{
+		{"BTCS", 1},
+		{"BTCS-RICHARDSON", 2},
+	}
+	for _, tc := range tests {
+		t.Run(tc.method, func(t *testing.T) {
+			m := mustLookup(t, tc.method)
+			var prev float64
+			for k, dt := range []float64{0.01, 0.005, 0.0025, 0.00125} {
+				nt := int(math.Round(tmax / dt))
+				last, err := m.Stream(SineProfile(nx, dx), nt, dx, dt, nil)
+				if err != nil {
+					t.Fatal(err)
+				}
+				l2, _ := mathutils.LevelErrors(last, dx, float64(nt)*dt, exact)
+				if k > 0 {
+					if order := math.Log2(prev / l2); math.Abs(order-tc.order) > 0.1 {
+						t.Errorf("dt=%g: error %.3g → %.3g, observed order %.2f", dt, prev, l2, order)
+					}
+				}
+				prev = l2
+			}
+		})
+	}
+}
+
+// Всплеск в одном узле при r = 5: у CN высокие моды меняют знак на каждом
+// шаге, и уже после первого соседние узлы уходят в минус на десятые доли;
+// экстраполированный BTCS почти не выходит за ноль
+func TestExtrapolatedBTCSSpike(t *testing.T) {
+	const nx, nt = 40, 1
+	dx := 1.0 / nx
+	dt := 5 * dx * dx
+	u0 := make([]float64, nx+1)
+	u0[nx/2] = 1
+	minimum := func(method string) float64 {
+		last, err := mustLookup(t, method).Stream(u0, nt, dx, dt, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var m float64
+		for _, v := range last {
+			m = min(m, v)
+		}
+		return m
+	}
+	if cn := minimum("CN"); cn > -0.1 {
+		t.Errorf("CN minimum %.3g, expected a visible undershoot", cn)
+	}
+	if re := minimum("BTCS-RICHARDSON"); re < -1e-4 {
+		t.Errorf("extrapolated BTCS minimum %.3g", re)
+	}
+}
+
+// Слой выдачи не хранит состояний обоих расчётов, и продолжить с него нельзя
+func TestExtrapolatedBTCSCheckpointed(t *testing.T) {
+	p := config.Params{Method: "BTCS-RE", Dx: 0.05, Dt: 0.01, Tmax: 0.1}.Normalize()
+	_, err := Checkpointed(context.Background(), p, 2, Hooks{})
+	var verr *config.ValidationError
+	if !errors.As(err, &verr) || verr.Field != "method" {
+		t.Errorf("Checkpointed returned %v, want a method validation error", err)
+	}
+}
+
+// Экстраполяция по пространству: CN и RK4 из sin(πx) при маленьком dt —
+// второй порядок по dx без экстраполяции и четвёртый с ней
+func TestSpatialRichardsonOrder(t *testing.T) {
+	const dt, tmax = 1e-5, 0.1
+	nt := int(math.Round(tmax / dt))
+	exact := mathutils.Analytical{Alpha: 1}
+	for _, name := range []string{"CN", "RK4"} {
+		t.Run(name, func(t *testing.T) {
+			m := mustLookup(t, name)
+			var raw, ext [3]float64
+			for k := range raw {
+				h := 0.2 / math.Exp2(float64(k))
+				nx := int(math.Round(1 / h))
+				lu, le, err := StreamSpatialRichardson(m, SineProfile(nx, h), SineProfile(2*nx, h/2), nt, h, dt, nil)
+				if err != nil {
+					t.Fatal(err)
+				}
+				raw[k], _ = mathutils.LevelErrors(lu, h, float64(nt)*dt, exact)
+				ext[k], _ = mathutils.LevelErrors(le, h, float64(nt)*dt, exact)
+			}
+			if order := math.Log2(raw[1] / raw[2]); math.Abs(order-2) > 0.1 {
+				t.Errorf("raw errors %.3g, %.3g, %.3g: order %.3f", raw[0], raw[1], raw[2], order)
+			}
+			if order := math.Log2(ext[1] / ext[2]); math.Abs(order-4) > 0.15 {
+				t.Errorf("extrapolated errors %.3g, %.3g, %.3g: order %.3f", ext[0], ext[1], ext[2], order)
+			}
+			if ext[0] > raw[0]/100 {
+				t.Errorf("extrapolation reduces the error only from %.3g to %.3g", raw[0], ext[0])
+			}
+		})
+	}
+}
+
+// Слой n — ровно (4·u_{dx/2} − u_dx)/3 в узлах грубой сетки по двум
+// отдельным расчётам, и сырой слой — грубый расчёт
+func TestSpatialRichardsonCombination(t *testing.T) {
+	const nx, nt, dt = 10, 12, 0.002
+	dx := 1.0 / nx
+	profile := func(n int) []float64 {
+		u := make([]float64, n+1)
+		for i := range u {
+			x := float64(i) / float64(n)
+			u[i] = x * (1 - x) * (1 + 3*x)
+		}
+		return u
+	}
+	coarse := stencilReference("CN", profile(nx), nt, dx, dt)
+	fine := stencilReference("CN", profile(2*nx), nt, dx/2, dt)
+	var levels int
+	raw, ext, err := StreamSpatialRichardson(mustLookup(t, "CN"), profile(nx), profile(2*nx), nt, dx, dt, func(n int, raw, extrapolated []float64) error {
+		levels++
+		for i := range raw {
+			want := (4*fine[n][2*i] - coarse[n][i]) / 3
+			if math.Abs(raw[i]-coarse[n][i]) > 1e-14 || math.Abs(extrapolated[i]-want) > 1e-14 {
+				t.Fatalf("level %d, node %d: raw %v, extrapolated %v; want %v, %v", n, i, raw[i], extrapolated[i], coarse[n][i], want)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if levels != nt+1 || len(raw) != nx+1 || len(ext) != nx+1 {
+		t.Errorf("%d levels, last %d and %d nodes", levels, len(raw), len(ext))
+	}
+}
+
+// Мелкая сетка не той длины отклоняется; ошибка из emit останавливает оба
+// расчёта и возвращается как есть, а ошибка мелкого расчёта — с пометкой
+// "fine grid"
+func TestSpatialRichardsonErrors(t *testing.T) {
+	cn := mustLookup(t, "CN")
+	if _, _, err := StreamSpatialRichardson(cn, SineProfile(10, 0.1), SineProfile(10, 0.1), 5, 0.1, 0.01, nil); err == nil {
+		t.Error("fine profile of the wrong length accepted")
+	}
+	stop := errors.New("stop")
+	_, _, err := StreamSpatialRichardson(cn, SineProfile(10, 0.1), SineProfile(20, 0.05), 100, 0.1, 0.01, func(n int, _, _ []float64) error {
+		if n == 3 {
+			return stop
+		}
+		return nil
+	})
+	if !errors.Is(err, stop) {
+		t.Errorf("emit error lost: %v", err)
+	}
+	failing := cn
+	failing.Stream = func(u0 []float64, nt int, dx, dt float64, emit EmitFunc) ([]float64, error) {
+		if len(u0) > 11 {
+			return nil, stop
+		}
+		return cn.Stream(u0, nt, dx, dt, emit)
+	}
+	_, _, err = StreamSpatialRichardson(failing, SineProfile(10, 0.1), SineProfile(20, 0.05), 5, 0.1, 0.01, nil)
+	if !errors.Is(err, stop) || !strings.Contains(err.Error(), "fine grid") {
+		t.Errorf("fine grid error %v", err)
+	}
+}
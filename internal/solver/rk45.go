@@ -0,0 +1,228 @@
+package solver
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"sync/atomic"
+)
+
+// Допуски RK45 по умолчанию
+const (
+	DefaultRTol = 1e-6
+	DefaultATol = 1e-9
+)
+
+// Предел шага RK45 по устойчивости, dt ≤ rk45MaxR·dx²: область
+// устойчивости Дормана–Принса на отрицательной полуоси кончается в
+// z ≈ −3.3066, спектральный радиус δ² — 4
+const rk45MaxR = 0.8266
+
+var rk45Tol atomic.Pointer[[2]float64]
+
+// Относительный и абсолютный допуск ошибки шага RK45 для расчётов,
+// начатых после вызова. Оба неотрицательны, хотя бы один положителен
+func SetRK45Tolerance(rtol, atol float64) error {
+	if !(rtol >= 0 && atol >= 0) || rtol+atol == 0 {
+		return fmt.Errorf("tolerances must be non-negative and not both zero, got rtol=%g atol=%g", rtol, atol)
+	}
+	rk45Tol.Store(&[2]float64{rtol, atol})
+	return nil
+}
+
+func RK45Tolerance() (rtol, atol float64) {
+	if t := rk45Tol.Load(); t != nil {
+		return t[0], t[1]
+	}
+	return DefaultRTol, DefaultATol
+}
+
+// Таблица Дормана–Принса 5(4): строки a — коэффициенты стадий 2–7,
+// седьмая стадия — решение пятого порядка (FSAL: её производная — первая
+// стадия следующего шага). Узлы c не нужны: система автономна
+var (
+	dpA = [7][]float64{
+		nil,
+		{1.0 / 5},
+		{3.0 / 40, 9.0 / 40},
+		{44.0 / 45, -56.0 / 15, 32.0 / 9},
+		{19372.0 / 6561, -25360.0 / 2187, 64448.0 / 6561, -212.0 / 729},
+		{9017.0 / 3168, -355.0 / 33, 46732.0 / 5247, 49.0 / 176, -5103.0 / 18656},
+		{35.0 / 384, 0, 500.0 / 1113, 125.0 / 192, -2187.0 / 6784, 11.0 / 84},
+	}
+	// Разность решений пятого и четвёртого порядка
+	dpE = [7]float64{71.0 / 57600, 0, -71.0 / 16695, 71.0 / 1920, -17253.0 / 339200, 22.0 / 525, -1.0 / 40}
+	// Плотная выдача четвёртого порядка (Hairer, contd5)
+	dpD = [7]float64{-12715105075.0 / 11282082432, 0, 87487479700.0 / 32700410799, -10690763975.0 / 1880347072,
+		701980252875.0 / 199316789632, -1453857185.0 / 822651844, 69997945.0 / 29380423}
+)
+
+// RK45 по методу прямых: дискретизация по x центральной разностью,
+// du/dt = δ²u/dx² интегрируется методом Дормана–Принса 5(4) с выбором
+// шага по оценке ошибки (RK45Tolerance). dt — шаг выдачи слоёв, а не
+// интегрирования: слои n·dt получаются плотной выдачей внутри принятых
+// шагов. Шаг ограничен устойчивостью, поэтому любое dt допустимо
+func SolveRK45(nx, nt int, dx, dt float64) [][]float64 {
+	return SolveRK45From(SineProfile(nx, dx), nt, dx, dt)
+}
+
+// RK45 с заданным начальным профилем u0 (nx+1 значений)
+func SolveRK45From(u0 []float64, nt int, dx, dt float64) [][]float64 {
+	u := newGrid(nt+1, len(u0))
+	rk45(u0, nt, dx, dt, u, nil)
+	return u
+}
+
+// RK45 с хранением двух слоёв выдачи; каждый слой передаётся в emit
+func StreamRK45From(u0 []float64, nt int, dx, dt float64, emit EmitFunc) ([]float64, error) {
+	u := newGrid(2, len(u0))
+	if err := rk45(u0, nt, dx, dt, u, emit); err != nil {
+		return nil, err
+	}
+	return level(u, nt), nil
+}
+
+// Состояние интегратора (y, ynew, стадии) хранится отдельно от слоёв
+// выдачи u. Шаг принимается, если среднеквадратичная ошибка в единицах
+// atol + rtol·|u| не больше 1; новый шаг — 0.9·err^(−1/5) от прежнего, от
+// 0.2 до 5 раз, после отказа без роста
+func rk45(u0 []float64, nt int, dx, dt float64, u [][]float64, emit EmitFunc) error {
+	nx := len(u0) - 1
+	rtol, atol := RK45Tolerance()
+	hmax := rk45MaxR * dx * dx
+	tEnd := float64(nt) * dt
+	slog.Info("Starting RK45 solver", "nx", nx, "nt", nt, "dx", dx, "dt", dt, "rtol", rtol, "atol", atol, "max_step", hmax)
+
+	if err := start(u, u0, emit); err != nil {
+		return err
+	}
+	if nt == 0 {
+		slog.Info("RK45 solver finished successfully", "accepted", 0, "rejected", 0)
+		return nil
+	}
+
+	ws := pool64.get(10 * (nx + 1))
+	defer pool64.put(ws)
+	rows := gridRows(ws, 10, nx+1)
+	y, ynew, stage := rows[0], rows[1], rows[2]
+	var k [7][]float64
+	copy(k[:], rows[3:])
+
+	copy(y, level(u, 0))
+	coef := 1 / (dx * dx)
+	laplacian(y, k[0], coef)
+
+	t, h := 0.0, min(hmax, dt, tEnd)
+	minStep := 1e-12 * tEnd
+	next := 1 // следующий слой выдачи
+	var accepted, rejected int
+	hmin, hbig := math.Inf(1), 0.0
+	for next <= nt {
+		last := t+h >= tEnd*(1-1e-14)
+		if last {
+			h = tEnd - t
+		}
+		for s := 1; s < 7; s++ {
+			dst := stage
+			if s == 6 {
+				dst = ynew
+			}
+			rkCombine(dst, y, h, dpA[s], k[:s])
+			laplacian(dst, k[s], coef)
+		}
+		err := rk45Error(y, ynew, k[:], h, rtol, atol)
+
+		if !(err <= 1) {
+			rejected++
+			fac := 0.2
+			if !math.IsNaN(err) {
+				fac = max(0.2, 0.9*math.Pow(err, -0.2))
+			}
+			h *= fac
+			if h < minStep {
+				return fmt.Errorf("RK45 step size %g fell below %g at t = %g", h, minStep, t)
+			}
+			continue
+		}
+
+		accepted++
+		hmin, hbig = min(hmin, h), max(hbig, h)
+		tNew := t + h
+		if last {
+			tNew = tEnd
+		}
+		for ; next <= nt && (float64(next)*dt <= tNew || next == nt && last); next++ {
+			row := level(u, next)
+			theta := min(1, (float64(next)*dt-t)/h)
+			rk45Dense(row, y, ynew, k[:], h, theta)
+			row[0], row[nx] = 0, 0
+			if err := emitLevel(emit, next, row); err != nil {
+				return err
+			}
+		}
+		t = tNew
+		y, ynew = ynew, y
+		k[0], k[6] = k[6], k[0]
+
+		fac := 5.0
+		if err > 0 {
+			fac = min(5, max(0.2, 0.9*math.Pow(err, -0.2)))
+		}
+		h = min(h*fac, hmax)
+	}
+
+	slog.Info("RK45 solver finished successfully", "accepted", accepted, "rejected", rejected,
+		"min_step", hmin, "max_step", hbig)
+	return nil
+}
+
+// dst = y + h·Σ a_j·k_j
+func rkCombine(dst, y []float64, h float64, a []float64, k [][]float64) {
+	copy(dst, y)
+	for j, w := range a {
+		if w != 0 {
+			rkAccumulate(dst, k[j], h*w)
+		}
+	}
+}
+
+// Среднеквадратичная оценка ошибки шага по внутренним узлам в единицах
+// atol + rtol·max(|y|, |ynew|)
+func rk45Error(y, ynew []float64, k [][]float64, h, rtol, atol float64) float64 {
+	n := len(y) - 2
+	if n < 1 {
+		return 0
+	}
+	var sum float64
+	for i := 1; i <= n; i++ {
+		var e float64
+		for j, w := range dpE {
+			if w != 0 {
+				e += w * k[j][i]
+			}
+		}
+		sc := atol + rtol*max(math.Abs(y[i]), math.Abs(ynew[i]))
+		e *= h / sc
+		sum += e * e
+	}
+	return math.Sqrt(sum / float64(n))
+}
+
+// Плотная выдача в точке t + θ·h принятого шага из y в ynew; k[0] и k[6] —
+// производные на концах шага
+func rk45Dense(dst, y, ynew []float64, k [][]float64, h, theta float64) {
+	theta1 := 1 - theta
+	for i := range dst {
+		diff := ynew[i] - y[i]
+		bspl := h*k[0][i] - diff
+		c4 := diff - h*k[6][i] - bspl
+		var c5 float64
+		for j, w := range dpD {
+			if w != 0 {
+				c5 += w * k[j][i]
+			}
+		}
+		c5 *= h
+		dst[i] = y[i] + theta*(diff+theta1*(bspl+theta*(c4+theta1*c5)))
+	}
+}
@@ -0,0 +1,136 @@
+package solver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"math"
+	"testing"
+
+	"heat-solver/internal/config"
+	"heat-solver/internal/mathutils"
+)
+
+// Допуски RK45 на время теста
+func withRK45Tolerance(t testing.TB, rtol, atol float64) {
+	t.Helper()
+	prevR, prevA := RK45Tolerance()
+	if err := SetRK45Tolerance(rtol, atol); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { SetRK45Tolerance(prevR, prevA) })
+}
+
+// Расчёт RK45 с журналом: число принятых и отвергнутых шагов из записи
+// о завершении
+func rk45Steps(t *testing.T, u0 []float64, nt int, dx, dt float64) (u [][]float64, accepted, rejected int) {
+	t.Helper()
+	var buf bytes.Buffer
+	prev := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&buf, nil)))
+	u = SolveRK45From(u0, nt, dx, dt)
+	slog.SetDefault(prev)
+	dec := json.NewDecoder(&buf)
+	for {
+		var rec struct {
+			Msg      string `json:"msg"`
+			Accepted int    `json:"accepted"`
+			Rejected int    `json:"rejected"`
+		}
+		if err := dec.Decode(&rec); err != nil {
+			t.Fatalf("no RK45 summary in the log: %v", err)
+		}
+		if rec.Msg == "RK45 solver finished successfully" {
+			return u, rec.Accepted, rec.Rejected
+		}
+	}
+}
+
+// До tmax = 1 RK45 обходится меньшим числом шагов, чем FTCS при r = ½,
+// и не уступает ему в точности; слои выдачи — на равномерной сетке dt
+func TestRK45FewerSteps(t *testing.T) {
+	withRK45Tolerance(t, DefaultRTol, DefaultATol)
+	exact := mathutils.Analytical{Alpha: 1}
+	for _, nx := range []int{20, 50} {
+		dx := 1.0 / float64(nx)
+		const dt, nt = 0.01, 100
+		u, accepted, rejected := rk45Steps(t, SineProfile(nx, dx), nt, dx, dt)
+		if len(u) != nt+1 {
+			t.Fatalf("nx=%d: %d levels, want %d", nx, len(u), nt+1)
+		}
+		errRK45, _ := mathutils.LevelErrors(u[nt], dx, 1, exact)
+
+		ftcsDt := 0.5 * dx * dx
+		ftcsSteps := int(math.Round(1 / ftcsDt))
+		ftcs := SolveFTCS(nx, ftcsSteps, dx, ftcsDt)
+		errFTCS, _ := mathutils.LevelErrors(ftcs[ftcsSteps], dx, 1, exact)
+		if accepted+rejected >= ftcsSteps || errRK45 > errFTCS {
+			t.Errorf("nx=%d: RK45 %d+%d steps with error %.3g, FTCS %d steps with %.3g",
+				nx, accepted, rejected, errRK45, ftcsSteps, errFTCS)
+		}
+		// Слой n — решение полудискретной системы в момент n·dt
+		sn := math.Sin(math.Pi * dx / 2)
+		lambda := 4 * sn * sn / (dx * dx)
+		for n := 1; n <= nt; n += 11 {
+			want := math.Exp(-lambda * float64(n) * dt)
+			if got := u[n][nx/2]; math.Abs(got-want) > 1e-6*want {
+				t.Errorf("nx=%d: u(½) = %v at level %d, want %v", nx, got, n, want)
+			}
+		}
+		last, err := StreamRK45From(SineProfile(nx, dx), nt, dx, dt, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := range last {
+			if last[i] != u[nt][i] {
+				t.Fatalf("nx=%d: streamed u[%d] = %v, full run %v", nx, i, last[i], u[nt][i])
+			}
+		}
+	}
+}
+
+// На ступеньке контроллер начинает с отказов, а ошибка против
+// полудискретного решения (RK45 с очень малым допуском) следует допуску
+func TestRK45Tolerance(t *testing.T) {
+	const nx, nt, dt = 40, 10, 0.005
+	dx := 1.0 / nx
+	u0 := make([]float64, nx+1)
+	for i := nx / 4; i <= 3*nx/4; i++ {
+		u0[i] = 1
+	}
+	withRK45Tolerance(t, 1e-12, 1e-14)
+	ref, _, _ := rk45Steps(t, u0, nt, dx, dt)
+
+	var prev float64
+	for k, tol := range []float64{1e-3, 1e-5, 1e-7} {
+		withRK45Tolerance(t, tol, tol*1e-3)
+		u, _, rejected := rk45Steps(t, u0, nt, dx, dt)
+		if rejected == 0 {
+			t.Errorf("rtol=%g: no rejected steps on a unit step", tol)
+		}
+		var e float64
+		for i := range u[nt] {
+			e = max(e, math.Abs(u[nt][i]-ref[nt][i]))
+		}
+		if e > 100*tol {
+			t.Errorf("rtol=%g: error %.3g", tol, e)
+		}
+		if k > 0 && e >= prev {
+			t.Errorf("rtol=%g: error %.3g did not fall from %.3g", tol, e, prev)
+		}
+		prev = e
+	}
+
+	for _, tc := range [][2]float64{{0, 0}, {-1, 1e-9}, {1e-6, math.NaN()}} {
+		if err := SetRK45Tolerance(tc[0], tc[1]); err == nil {
+			t.Errorf("tolerances %v accepted", tc)
+		}
+	}
+	p := config.Params{Method: "RK45", Dx: 0.1, Dt: 0.01, Tmax: 0.1}.Normalize()
+	var verr *config.ValidationError
+	if _, err := Checkpointed(context.Background(), p, 2, Hooks{}); !errors.As(err, &verr) {
+		t.Errorf("checkpointed adaptive method: %v, want a validation error", err)
+	}
+}
@@ -0,0 +1,83 @@
+package solver
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+// Ошибка по времени против решения полудискретной системы
+// sin(πx)·exp(−λt), λ = 4sin²(π·dx/2)/dx²: пространственная ошибка в неё
+// не входит, и видно O(dt⁴)
+func TestRK4TimeOrder(t *testing.T) {
+	const nx, tmax = 20, 0.4
+	dx := 1.0 / nx
+	s := math.Sin(math.Pi * dx / 2)
+	lambda := 4 * s * s / (dx * dx)
+	var prev float64
+	for k, dt := range []float64{0.0016, 0.0008, 0.0004} {
+		nt := int(math.Round(tmax / dt))
+		u := SolveRK4(nx, nt, dx, dt)
+		var sum float64
+		for i, v := range u[nt] {
+			e := v - math.Sin(math.Pi*float64(i)*dx)*math.Exp(-lambda*tmax)
+			sum += e * e
+		}
+		l2 := math.Sqrt(sum * dx)
+		if k > 0 {
+			if order := math.Log2(prev / l2); math.Abs(order-4) > 0.2 {
+				t.Errorf("dt=%g: error %.3g → %.3g, observed order %.2f, want 4", dt, prev, l2, order)
+			}
+		}
+		prev = l2
+	}
+}
+
+// Мода sin(πx) убывает по G(π·dx)ⁿ; на r чуть выше rk4MaxR высокие моды
+// растут, и об этом предупреждение; поток совпадает с полным расчётом
+func TestRK4Stability(t *testing.T) {
+	const nx, nt = 40, 2000
+	dx := 1.0 / nx
+	tests := []struct {
+		r      float64
+		stable bool
+	}{
+		{0.5, true},
+		{0.69, true},
+		{0.72, false},
+	}
+	for _, tc := range tests {
+		warnings := captureWarnings(t)
+		dt := tc.r * dx * dx
+		u := SolveRK4(nx, nt, dx, dt)
+		var peak float64
+		for _, v := range u[nt] {
+			peak = max(peak, math.Abs(v))
+		}
+		if (peak <= 1) != tc.stable {
+			t.Errorf("r=%g: peak %g after %d steps, want stable: %v", tc.r, peak, nt, tc.stable)
+		}
+		if warned := strings.Contains(warnings.String(), "RK4 may be unstable"); warned == tc.stable {
+			t.Errorf("r=%g: stability warning %v", tc.r, warned)
+		}
+		if !tc.stable {
+			continue
+		}
+		if want := math.Pow(rk4Amplification(tc.r, math.Pi*dx), nt); math.Abs(u[nt][nx/2]-want) > 1e-12 {
+			t.Errorf("r=%g: u(½) = %v, want G^n = %v", tc.r, u[nt][nx/2], want)
+		}
+		last, err := StreamRK4From(SineProfile(nx, dx), nt, dx, dt, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := range last {
+			if last[i] != u[nt][i] {
+				t.Fatalf("r=%g: streamed u[%d] = %v, full run %v", tc.r, i, last[i], u[nt][i])
+			}
+		}
+	}
+	// Граница области устойчивости: G = 1 у моды θ = π при r = rk4MaxR
+	if g := rk4Amplification(rk4MaxR, math.Pi); math.Abs(g-1) > 1e-12 {
+		t.Errorf("G(rk4MaxR, π) = %v, want 1", g)
+	}
+}
@@ -0,0 +1,205 @@
+package solver
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+)
+
+// Затухание RKC: многочлен стадий сдвинут внутрь, w0 = 1 + ε/s², чтобы
+// |R(z)| не касался единицы внутри области устойчивости
+const rkcDamping = 2.0 / 13
+
+// Рунге–Кутта–Чебышёв второго порядка (RKC, Соммейер–Шампайн–Вервер) по
+// методу прямых: шаг из s стадий, каждая — одно вычисление δ²u/dx², и
+// многочлен устойчивости R_s(z) = a_s + b_s·T_s(w0 + w1·z) из многочлена
+// Чебышёва T_s. Область устойчивости на отрицательной полуоси растёт как
+// β(s) = (1 + w0)/w1 ≈ 0.65·s², поэтому число стадий берётся наименьшим
+// с β(s) ≥ dt·ρ, где ρ = 4/dx² — спектральный радиус δ²/dx²: шаг
+// устойчив при любом r, а работа растёт как √r, а не как r у FTCS.
+// Порядок по dt — второй
+func SolveRKC(nx, nt int, dx, dt float64) [][]float64 {
+	return SolveRKCFrom(SineProfile(nx, dx), nt, dx, dt)
+}
+
+// RKC с заданным начальным профилем u0 (nx+1 значений)
+func SolveRKCFrom(u0 []float64, nt int, dx, dt float64) [][]float64 {
+	u := newGrid(nt+1, len(u0))
+	rkc(u0, nt, dx, dt, u, nil)
+	return u
+}
+
+// RKC с хранением двух слоёв; каждый слой передаётся в emit
+func StreamRKCFrom(u0 []float64, nt int, dx, dt float64, emit EmitFunc) ([]float64, error) {
+	u := newGrid(2, len(u0))
+	if err := rkc(u0, nt, dx, dt, u, emit); err != nil {
+		return nil, err
+	}
+	return level(u, nt), nil
+}
+
+// Коэффициенты шага RKC из s стадий: Y_1 = Y_0 + μ̃_1·k(Y_0),
+// Y_j = (1 − μ_j − ν_j)·Y_0 + μ_j·Y_{j−1} + ν_j·Y_{j−2} + μ̃_j·k(Y_{j−1})
+// + γ̃_j·k(Y_0), k(y) = dt·δ²y/dx²; u^{n+1} = Y_s. Индексы j от 0 до s,
+// элементы 0 и 1 у mu, nu и gamma не используются
+type rkcCoefficients struct {
+	s          int
+	w0, w1     float64
+	as, bs     float64 // R_s(z) = as + bs·T_s(w0 + w1·z)
+	mu, nu     []float64
+	muT, gamma []float64
+}
+
+func newRKCCoefficients(s int) rkcCoefficients {
+	w0 := 1 + rkcDamping/float64(s*s)
+	// T_j(w0) и две производные по рекуррентности Чебышёва
+	t, t1, t2 := make([]float64, s+1), make([]float64, s+1), make([]float64, s+1)
+	t[0], t[1], t1[1] = 1, w0, 1
+	for j := 2; j <= s; j++ {
+		t[j] = 2*w0*t[j-1] - t[j-2]
+		t1[j] = 2*t[j-1] + 2*w0*t1[j-1] - t1[j-2]
+		t2[j] = 4*t1[j-1] + 2*w0*t2[j-1] - t2[j-2]
+	}
+	w1 := t1[s] / t2[s]
+	b := make([]float64, s+1)
+	for j := 2; j <= s; j++ {
+		b[j] = t2[j] / (t1[j] * t1[j])
+	}
+	b[0], b[1] = b[2], b[2]
+	c := rkcCoefficients{s: s, w0: w0, w1: w1, as: 1 - b[s]*t[s], bs: b[s],
+		mu: make([]float64, s+1), nu: make([]float64, s+1), muT: make([]float64, s+1), gamma: make([]float64, s+1)}
+	c.muT[1] = b[1] * w1
+	for j := 2; j <= s; j++ {
+		c.mu[j] = 2 * b[j] * w0 / b[j-1]
+		c.nu[j] = -b[j] / b[j-2]
+		c.muT[j] = 2 * b[j] * w1 / b[j-1]
+		c.gamma[j] = -(1 - b[j-1]*t[j-1]) * c.muT[j]
+	}
+	return c
+}
+
+// Граница области устойчивости на отрицательной полуоси: |R_s(z)| ≤ 1 при
+// z ∈ [−β, 0], пока аргумент T_s не меньше −1
+func (c rkcCoefficients) beta() float64 {
+	return (1 + c.w0) / c.w1
+}
+
+// Наименьшее число стадий (не меньше двух), при котором z = −4r лежит в
+// области устойчивости; начальная оценка — по β(s) ≈ 0.65·s²
+func rkcStages(r float64) int {
+	s := max(2, int(math.Sqrt(1+1.54*4*r)))
+	for s > 2 && newRKCCoefficients(s-1).beta() >= 4*r {
+		s--
+	}
+	for newRKCCoefficients(s).beta() < 4*r {
+		s++
+	}
+	return s
+}
+
+// На шаге s вычислений δ²; стадии ходят по трём буферам, последняя
+// пишется прямо в следующий слой. δ²Y_{j−1} не хранится: стадия
+// считается за один проход вместе с ним
+func rkc(u0 []float64, nt int, dx, dt float64, u [][]float64, emit EmitFunc) error {
+	nx := len(u0) - 1
+	r := dt / (dx * dx)
+	c := newRKCCoefficients(rkcStages(r))
+	slog.Info("Starting RKC solver", "nx", nx, "nt", nt, "dx", dx, "dt", dt, "r", r, "stages", c.s, "stability_bound", c.beta())
+
+	if err := start(u, u0, emit); err != nil {
+		return err
+	}
+
+	ws := pool64.get(4 * (nx + 1))
+	defer pool64.put(ws)
+	k0 := ws[: nx+1 : nx+1]
+	stages := [3][]float64{ws[nx+1 : 2*(nx+1) : 2*(nx+1)], ws[2*(nx+1) : 3*(nx+1) : 3*(nx+1)], ws[3*(nx+1):]}
+
+	for n := 0; n < nt; n++ {
+		cur, next := level(u, n), level(u, n+1)
+		rkcStep(c, cur, next, k0, stages, r)
+		if err := emitLevel(emit, n+1, next); err != nil {
+			return err
+		}
+	}
+
+	slog.Info("RKC solver finished successfully", "stages", c.s, "laplacian_evaluations", nt*c.s)
+	return nil
+}
+
+// Один шаг RKC из cur в next, r = dt/dx²; Y_1 — в stages[1], дальше
+// Y_j — в stages[j mod 3], последняя стадия — в next
+func rkcStep(c rkcCoefficients, cur, next, k0 []float64, stages [3][]float64, r float64) {
+	laplacian(cur, k0, r)
+	prev2, prev := cur, stages[1]
+	rkStage(cur, k0, c.muT[1], prev)
+	for j := 2; j <= c.s; j++ {
+		dst := stages[j%3]
+		if j == c.s {
+			dst = next
+		}
+		rkcStage(c, j, r, cur, prev, prev2, k0, dst)
+		prev2, prev = prev, dst
+	}
+}
+
+// Стадия j ≥ 2 во внутренних узлах: dst = (1 − μ_j − ν_j)·Y_0 + μ_j·Y_{j−1}
+// + ν_j·Y_{j−2} + μ̃_j·r·δ²Y_{j−1} + γ̃_j·k0; края dst — края Y_0 (сумма
+// весов при Y — единица). Соседи — срезы той же длины, как в laplacian
+func rkcStage(c rkcCoefficients, j int, r float64, y0, prev, prev2, k0, dst []float64) {
+	n := len(y0)
+	dst[0], dst[n-1] = y0[0], y0[n-1]
+	mu, nu, muT, gamma := c.mu[j], c.nu[j], c.muT[j]*r, c.gamma[j]
+	keep := 1 - mu - nu
+	out := dst[1 : n-1]
+	base, left, mid, right := y0[1:len(out)+1], prev[:len(out)], prev[1:len(out)+1], prev[2:len(out)+2]
+	old, k := prev2[1:len(out)+1], k0[1:len(out)+1]
+	for i := range out {
+		out[i] = keep*base[i] + mu*mid[i] + nu*old[i] + muT*(right[i]-2*mid[i]+left[i]) + gamma*k[i]
+	}
+}
+
+// G(θ) RKC: R_s(z) при z = −4r·sin²(θ/2) с тем же числом стадий, что
+// выбирает расчёт при этом r
+func rkcAmplification(r, theta float64) float64 {
+	c := newRKCCoefficients(rkcStages(r))
+	s := math.Sin(theta / 2)
+	x := c.w0 - c.w1*4*r*s*s
+	prev, cur := 1.0, x
+	for j := 2; j <= c.s; j++ {
+		prev, cur = cur, 2*x*cur-prev
+	}
+	return c.as + c.bs*cur
+}
+
+// RKC против BTCS на sin(πx) при r = dt/dx² (r = 20 далеко за пределом
+// FTCS): ошибка L2 RKC не больше ошибки BTCS, значение — их отношение. В
+// подробностях — число стадий и вычислений δ² против прогонок BTCS и CN
+// (по одной на шаг)
+func rkcAgainstBTCSCase(dx, r, tmax float64) VerifyCase {
+	return VerifyCase{
+		Name:     fmt.Sprintf("against BTCS at r=%g dx=%g", r, dx),
+		Quantity: "L2 RKC / L2 BTCS",
+		Compare:  VerifyAtMost,
+		Expected: 1,
+		Measure: func(ctx context.Context, m Method) (float64, string, error) {
+			btcs, ok := Lookup("BTCS")
+			if !ok {
+				return math.NaN(), "", fmt.Errorf("BTCS is not registered")
+			}
+			dt := r * dx * dx
+			own, err := verifySineError(ctx, m, dx, dt, tmax)
+			if err != nil {
+				return math.NaN(), "", err
+			}
+			ref, err := verifySineError(ctx, btcs, dx, dt, tmax)
+			if err != nil {
+				return math.NaN(), "", err
+			}
+			s, nt := rkcStages(r), int(math.Round(tmax/dt))
+			return own / ref, fmt.Sprintf("L2 %.3g against %.3g; %d stages, %d δ² evaluations against %d tridiagonal solves",
+				own, ref, s, s*nt, nt), nil
+		},
+	}
+}
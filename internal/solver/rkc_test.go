@@ -0,0 +1,124 @@
+package solver
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"testing"
+
+	"heat-solver/internal/mathutils"
+)
+
+// Число стадий — наименьшее s ≥ 2 с β(s) ≥ 4r, и растёт как √r: при
+// r = 20 это около десятка стадий вместо сорока шагов FTCS
+func TestRKCStages(t *testing.T) {
+	for _, r := range []float64{0.01, 0.5, 1, 5, 20, 100, 1e4} {
+		s := rkcStages(r)
+		if s < 2 || newRKCCoefficients(s).beta() < 4*r {
+			t.Errorf("r=%g: %d stages, β = %g < 4r", r, s, newRKCCoefficients(s).beta())
+		}
+		if s > 2 && newRKCCoefficients(s-1).beta() >= 4*r {
+			t.Errorf("r=%g: %d stages, but %d suffice", r, s, s-1)
+		}
+		if b := newRKCCoefficients(s).beta(); s >= 5 && (b < 0.6*float64(s*s) || b > 0.7*float64(s*s)) {
+			t.Errorf("s=%d: β = %g, want ≈ 0.65·s²", s, b)
+		}
+	}
+	if s := rkcStages(20); s > 13 {
+		t.Errorf("r=20: %d stages", s)
+	}
+}
+
+// Многочлен устойчивости: |G| ≤ 1 на всех гармониках при любом r, G(0) = 1,
+// и один шаг умножает гармонику sin(kπx) ровно на G(kπ·dx)
+func TestRKCAmplification(t *testing.T) {
+	for _, r := range []float64{0.1, 1, 20, 1000} {
+		for k := 0; k <= 200; k++ {
+			theta := math.Pi * float64(k) / 200
+			if g := rkcAmplification(r, theta); math.Abs(g) > 1+1e-12 {
+				t.Errorf("r=%g θ=%.3f: |G| = %g", r, theta, math.Abs(g))
+			}
+		}
+		if g := rkcAmplification(r, 0); math.Abs(g-1) > 1e-12 {
+			t.Errorf("r=%g: G(0) = %v", r, g)
+		}
+		const nx = 20
+		dx := 1.0 / nx
+		for _, k := range []int{1, 7, 19} {
+			u0 := make([]float64, nx+1)
+			for i := range u0 {
+				u0[i] = math.Sin(float64(k) * math.Pi * float64(i) * dx)
+			}
+			last, err := StreamRKCFrom(u0, 1, dx, r*dx*dx, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			g := rkcAmplification(r, float64(k)*math.Pi*dx)
+			for i := 1; i < nx; i++ {
+				if math.Abs(last[i]-g*u0[i]) > 1e-11 {
+					t.Fatalf("r=%g k=%d: u[%d] = %v, G·u0 = %v", r, k, i, last[i], g*u0[i])
+				}
+			}
+		}
+	}
+}
+
+// Второй порядок по dt против точного решения полудискретной задачи, в
+// том числе при r от 5 до 40
+func TestRKCTimeOrder(t *testing.T) {
+	const nx, tmax = 50, 0.2
+	dx := 1.0 / nx
+	exact := semiDiscreteSine(dx)
+	var prev float64
+	for k, dt := range []float64{0.016, 0.008, 0.004, 0.002} {
+		nt := int(math.Round(tmax / dt))
+		last, err := StreamRKCFrom(SineProfile(nx, dx), nt, dx, dt, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		l2, _ := mathutils.LevelErrors(last, dx, float64(nt)*dt, exact)
+		if k > 0 {
+			if order := math.Log2(prev / l2); math.Abs(order-2) > 0.1 {
+				t.Errorf("dt=%g: error %.3g → %.3g, observed order %.2f", dt, prev, l2, order)
+			}
+		}
+		prev = l2
+	}
+}
+
+// При r = 20 ошибка RKC на порядок меньше ошибки BTCS (у BTCS первый
+// порядок по dt)
+func TestRKCAgainstBTCS(t *testing.T) {
+	const r, tmax = 20, 0.1
+	for _, dx := range []float64{0.02, 0.01} {
+		t.Run(fmt.Sprintf("dx=%g", dx), func(t *testing.T) {
+			dt := r * dx * dx
+			errs := make(map[string]float64)
+			for _, name := range []string{"RKC", "BTCS"} {
+				l2, err := verifySineError(context.Background(), mustLookup(t, name), dx, dt, tmax)
+				if err != nil {
+					t.Fatal(err)
+				}
+				errs[name] = l2
+			}
+			if errs["RKC"] > errs["BTCS"]/10 {
+				t.Errorf("L2 errors %v", errs)
+			}
+		})
+	}
+}
+
+// RKC против прогонок CN и BTCS при r = 20 на сетке 10⁴
+func BenchmarkRKC(b *testing.B) {
+	const nx, nt, r = 10000, 20, 20
+	dx := 1.0 / nx
+	u0 := SineProfile(nx, dx)
+	for _, method := range []string{"RKC", "CN", "BTCS"} {
+		m, _ := Lookup(method)
+		b.Run(method, func(b *testing.B) {
+			for b.Loop() {
+				m.Stream(u0, nt, dx, r*dx*dx, nil)
+			}
+		})
+	}
+}
@@ -0,0 +1,575 @@
+package solver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"slices"
+	"sync/atomic"
+	"time"
+
+	"heat-solver/internal/config"
+	"heat-solver/internal/mathutils"
+)
+
+// Решение разошлось (NaN/Inf или неограниченный рост)
+var ErrDiverged = errors.New("solution diverged")
+
+// Результат одного запуска схемы
+type Result struct {
+	Method string
+	Nx     int
+	Nt     int
+	Dx     float64
+	Dt     float64
+	// Все nt+1 слоёв или, при Storage = final-only, только начальный и
+	// последний. При Precision = float32 слои лежат в U32, а U пуст
+	U   [][]float64
+	U32 [][]float32
+	// Последний слой в рабочей точности float64: по нему считаются нормы
+	Final     []float64
+	Storage   string
+	Precision string
+	Runtime   time.Duration
+	Diverged  bool
+	// Проверка принципа максимума по всем слоям расчёта
+	MaxPrinciple MaxPrinciple
+	// Оценка κ∞ матрицы шага (StepCondition); 0 для явных схем
+	Condition float64
+	// Максимум по x каждого из nt+1 слоёв, при любом хранении
+	Peaks *PeakTrace
+	// Изменение теплосодержания против притока через границы
+	Flux FluxBalance
+	// Проверка симметрии (SetSymmetryCheck); Checked = false, если она
+	// выключена или начальный профиль несимметричен
+	Symmetry Symmetry
+	// Принятые шаги при Params.Adapt; nil при постоянном шаге
+	Steps []AdaptiveStep
+
+	// Буфер истории из пула; nil, если память выделена обычным образом
+	pooled *pooledBuffer
+	// Контрольные слои при Storage = checkpoint
+	checkpoints *checkpoints
+}
+
+// Буфер истории с подсчётом ссылок: в пул он возвращается, когда
+// освобождены все ссылки
+type pooledBuffer struct {
+	refs   atomic.Int32
+	flat   []float64
+	flat32 []float32
+}
+
+// Ещё одна ссылка на результат (например, из кэша); каждой Retain
+// соответствует свой Release
+func (r *Result) Retain() {
+	if r.pooled != nil {
+		r.pooled.refs.Add(1)
+	}
+}
+
+// Освобождение ссылки. Run возвращает результат с одной ссылкой; когда
+// освобождена последняя, слои возвращаются в пул и читать U, U32, Final
+// и срезы из Level больше нельзя. Результат, для которого Release не
+// вызван, просто собирается сборщиком мусора
+func (r *Result) Release() {
+	if r.pooled == nil || r.pooled.refs.Add(-1) != 0 {
+		return
+	}
+	if r.pooled.flat != nil {
+		pool64.put(r.pooled.flat)
+	}
+	if r.pooled.flat32 != nil {
+		pool32.put(r.pooled.flat32)
+	}
+}
+
+func newPooledBuffer(flat []float64, flat32 []float32) *pooledBuffer {
+	b := &pooledBuffer{flat: flat, flat32: flat32}
+	b.refs.Store(1)
+	return b
+}
+
+// Размер решения в байтах (для учёта памяти в кэшах)
+func (r *Result) Bytes() int64 {
+	peaks := int64(len(r.Steps)) * 32
+	if r.Peaks != nil {
+		peaks += int64(r.Peaks.Len()) * 12
+	}
+	if r.checkpoints != nil {
+		return int64(len(r.checkpoints.rows)+1)*int64(r.Nx+1)*8 + peaks
+	}
+	if r.U32 != nil {
+		return int64(len(r.U32))*int64(r.Nx+1)*4 + int64(len(r.Final))*8 + peaks
+	}
+	return int64(len(r.U))*int64(r.Nx+1)*8 + peaks
+}
+
+// Первые моменты, когда max_x u опускается ниже каждого из порогов
+// (PeakTrace.Crossings); nil, если максимумы слоёв не записаны
+func (r *Result) Crossings(thresholds ...float64) []Crossing {
+	if r.Peaks == nil {
+		return nil
+	}
+	return r.Peaks.Crossings(r.Dt, r.Dx, thresholds...)
+}
+
+// Число хранимых слоёв; при Storage = checkpoint доступны все nt+1
+func (r *Result) Levels() int {
+	if r.checkpoints != nil {
+		return r.Nt + 1
+	}
+	if r.U32 != nil {
+		return len(r.U32)
+	}
+	return len(r.U)
+}
+
+// Хранимый слой k в float64; слой из U32 или пересчитанный от
+// контрольного возвращается новой копией
+func (r *Result) Level(k int) []float64 {
+	if r.checkpoints != nil {
+		return r.checkpoints.level(r, k)
+	}
+	if r.U32 == nil {
+		return r.U[k]
+	}
+	row := make([]float64, len(r.U32[k]))
+	for i, v := range r.U32[k] {
+		row[i] = float64(v)
+	}
+	return row
+}
+
+// Все хранимые слои в float64; для U32 и checkpoint — копия
+func (r *Result) Rows() [][]float64 {
+	if r.checkpoints != nil {
+		u := newGrid(r.Nt+1, r.Nx+1)
+		r.checkpoints.each(r, func(n int, row []float64) error {
+			copy(u[n], row)
+			return nil
+		})
+		return u
+	}
+	if r.U32 == nil {
+		return r.U
+	}
+	u := newGrid(len(r.U32), r.Nx+1)
+	for k, row := range r.U32 {
+		for i, v := range row {
+			u[k][i] = float64(v)
+		}
+	}
+	return u
+}
+
+// Хранимые слои по порядку: n — номер временного шага. row действителен
+// до возврата из yield; ошибка yield прерывает обход. При Storage =
+// checkpoint слои пересчитываются одним проходом схемы
+func (r *Result) Each(yield EmitFunc) error {
+	if r.checkpoints != nil {
+		return r.checkpoints.each(r, yield)
+	}
+	step := r.LevelStep()
+	for k := range r.Levels() {
+		if err := yield(k*step, r.Level(k)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Амплитуды первых modes синус-мод (mathutils.SineTransform) хранимых
+// слоёв с номером шага, кратным stride: времена слоёв и по строке
+// коэффициентов a_1..a_modes на слой
+func (r *Result) Modes(modes, stride int) (t []float64, a [][]float64, err error) {
+	dst, err := mathutils.NewSineTransform(r.Nx, modes)
+	if err != nil {
+		return nil, nil, err
+	}
+	stride = max(1, stride)
+	err = r.Each(func(n int, row []float64) error {
+		if n%stride != 0 {
+			return nil
+		}
+		coef := make([]float64, modes)
+		dst.Transform(coef, row)
+		t = append(t, float64(n)*r.Dt)
+		a = append(a, coef)
+		return nil
+	})
+	return t, a, err
+}
+
+// Временных шагов между соседними хранимыми слоями: 1 при полном
+// хранении, Nt в режиме final-only
+func (r *Result) LevelStep() int {
+	if r.Storage == config.StorageFinalOnly {
+		return max(1, r.Nt)
+	}
+	return 1
+}
+
+// Обратные вызовы для наблюдения за расчётом (метрики, логирование).
+// Любое поле может быть nil.
+type Hooks struct {
+	OnStart  func(method string)
+	OnFinish func(res *Result)
+}
+
+// Имена доступных методов
+func Methods() []string {
+	var names []string
+	for _, m := range Registered() {
+		names = append(names, m.Name)
+	}
+	return names
+}
+
+// Запуск выбранной схемы по параметрам.
+// ctx передаётся в записи лога (например, с идентификатором запроса).
+// При p.Storage = final-only или p.Precision = float32 расчёт идёт на двух
+// рабочих слоях, а история (если нужна) сохраняется во float32.
+func Run(ctx context.Context, p config.Params, hooks Hooks) (*Result, error) {
+	if p.Storage == config.StorageFinalOnly || p.Precision == config.PrecisionFloat32 {
+		return stream(ctx, p, hooks, nil)
+	}
+	nx, nt := p.Grid()
+
+	m, ok := Lookup(p.Method)
+	if !ok {
+		return nil, &config.ValidationError{Field: "method", Message: fmt.Sprintf("unknown method %q", p.Method)}
+	}
+	var steps []AdaptiveStep
+	m, err := paramsMethod(m, p, &steps)
+	if err != nil {
+		return nil, err
+	}
+
+	slog.InfoContext(ctx, "Solve started", "method", m.Name, "nx", nx, "nt", nt)
+	if hooks.OnStart != nil {
+		hooks.OnStart(m.Name)
+	}
+	start := time.Now()
+
+	var u [][]float64
+	var pooled *pooledBuffer
+	u0 := InitialProfile(p, nx)
+	if m.solveInto != nil {
+		flat := pool64.get((nt + 1) * (nx + 1))
+		u = gridRows(flat, nt+1, nx+1)
+		if err := m.solveInto(u0, nt, p.Dx, p.Dt, u); err != nil {
+			pool64.put(flat)
+			if hooks.OnFinish != nil {
+				hooks.OnFinish(&Result{Method: m.Name, Nx: nx, Nt: nt, Dx: p.Dx, Dt: p.Dt, Runtime: time.Since(start)})
+			}
+			return nil, err
+		}
+		pooled = newPooledBuffer(flat, nil)
+	} else {
+		u = m.Solve(u0, nt, p.Dx, p.Dt)
+	}
+	principle := NewPrincipleMonitor(u0)
+	peaks := principle.TracePeaks(nt + 1)
+	audit := NewFluxAudit(p.Dx, p.Dt).WithSource(m.Source)
+	symmetry := symmetryMonitor(u0)
+	for n, row := range u {
+		principle.Check(n, row)
+		audit.Add(n, row)
+		symmetry.Check(n, row)
+	}
+
+	res := &Result{
+		Method:    m.Name,
+		Nx:        nx,
+		Nt:        nt,
+		Dx:        p.Dx,
+		Dt:        p.Dt,
+		U:         u,
+		Final:     u[nt],
+		Storage:   config.StorageFull,
+		Precision: config.PrecisionFloat64,
+		Runtime:   time.Since(start),
+		Diverged:  Diverged(u),
+		pooled:    pooled,
+
+		MaxPrinciple: principle.Report(),
+		Condition:    StepCondition(m, nx, p.Dx, p.Dt),
+		Peaks:        peaks,
+		Flux:         audit.Report(u[nt]),
+		Symmetry:     symmetry.Report(),
+		Steps:        steps,
+	}
+	if res.Diverged {
+		slog.WarnContext(ctx, "Solution diverged", "method", m.Name, "nx", nx, "nt", nt)
+	}
+	warnPrinciple(ctx, m, res.MaxPrinciple)
+	warnSymmetry(ctx, m.Name, res.Symmetry)
+	slog.InfoContext(ctx, "Solve finished", "method", m.Name, "runtime_sec", res.Runtime.Seconds())
+	if hooks.OnFinish != nil {
+		hooks.OnFinish(res)
+	}
+	return res, nil
+}
+
+// Ячеек между проверками ctx в Stream
+const streamCheckCells = 1 << 20
+
+// Расчёт с хранением двух слоёв; каждый слой n передаётся в emit по порядку
+// (emit может быть nil). Между порциями шагов проверяется ctx. В Result.U
+// (или U32 при p.Precision = float32) только начальный и последний слои,
+// Storage = final-only
+func Stream(ctx context.Context, p config.Params, hooks Hooks, emit EmitFunc) (*Result, error) {
+	p.Storage = config.StorageFinalOnly
+	return stream(ctx, p, hooks, emit)
+}
+
+// Расчёт на двух рабочих слоях float64. При Storage = full история
+// копируется во float32 по мере счёта, иначе сохраняются только начальный
+// и последний слои в точности p.Precision
+func stream(ctx context.Context, p config.Params, hooks Hooks, emit EmitFunc) (*Result, error) {
+	nx, nt := p.Grid()
+
+	m, ok := Lookup(p.Method)
+	if !ok {
+		return nil, &config.ValidationError{Field: "method", Message: fmt.Sprintf("unknown method %q", p.Method)}
+	}
+	var steps []AdaptiveStep
+	m, err := paramsMethod(m, p, &steps)
+	if err != nil {
+		return nil, err
+	}
+
+	storage, precision := config.StorageFull, config.PrecisionFloat64
+	if p.Storage == config.StorageFinalOnly {
+		storage = config.StorageFinalOnly
+	}
+	if p.Precision == config.PrecisionFloat32 {
+		precision = config.PrecisionFloat32
+	}
+
+	slog.InfoContext(ctx, "Streaming solve started", "method", m.Name, "nx", nx, "nt", nt, "storage", storage, "precision", precision)
+	if hooks.OnStart != nil {
+		hooks.OnStart(m.Name)
+	}
+	start := time.Now()
+	res := &Result{Method: m.Name, Nx: nx, Nt: nt, Dx: p.Dx, Dt: p.Dt, Storage: storage, Precision: precision}
+	// Хуки вызываются и при прерванном расчёте (метрики активных расчётов)
+	defer func() {
+		res.Runtime = time.Since(start)
+		if hooks.OnFinish != nil {
+			hooks.OnFinish(res)
+		}
+	}()
+
+	// Для полного хранения до этого доходит только float32
+	var history [][]float32
+	var flat32 []float32
+	if storage == config.StorageFull {
+		flat32 = pool32.get((nt + 1) * (nx + 1))
+		history = gridRows(flat32, nt+1, nx+1)
+	}
+	var first []float64
+	check := max(1, streamCheckCells/(nx+1))
+	u0 := InitialProfile(p, nx)
+	principle := NewPrincipleMonitor(u0)
+	peaks := principle.TracePeaks(nt + 1)
+	audit := NewFluxAudit(p.Dx, p.Dt).WithSource(m.Source)
+	symmetry := symmetryMonitor(u0)
+	last, err := m.Stream(u0, nt, p.Dx, p.Dt, func(n int, row []float64) error {
+		principle.Check(n, row)
+		audit.Add(n, row)
+		symmetry.Check(n, row)
+		if n == 0 {
+			first = slices.Clone(row)
+		} else if n%check == 0 {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+		}
+		if history != nil {
+			toFloat32(history[n], row)
+		}
+		if emit == nil {
+			return nil
+		}
+		return emit(n, row)
+	})
+	if err != nil {
+		if flat32 != nil {
+			pool32.put(flat32)
+		}
+		return nil, err
+	}
+	res.Final = last
+	switch {
+	case history != nil:
+		res.U32 = history
+		res.pooled = newPooledBuffer(nil, flat32)
+	case precision == config.PrecisionFloat32:
+		res.U32 = newGrid32(2, nx+1)
+		toFloat32(res.U32[0], first)
+		toFloat32(res.U32[1], last)
+	default:
+		res.U = [][]float64{first, last}
+	}
+
+	res.Diverged = Diverged([][]float64{first, last})
+	if res.Diverged {
+		slog.WarnContext(ctx, "Solution diverged", "method", m.Name, "nx", nx, "nt", nt)
+	}
+	res.MaxPrinciple = principle.Report()
+	res.Condition = StepCondition(m, nx, p.Dx, p.Dt)
+	res.Peaks = peaks
+	res.Flux = audit.Report(last)
+	res.Symmetry = symmetry.Report()
+	res.Steps = steps
+	warnPrinciple(ctx, m, res.MaxPrinciple)
+	warnSymmetry(ctx, m.Name, res.Symmetry)
+	slog.InfoContext(ctx, "Streaming solve finished", "method", m.Name, "runtime_sec", time.Since(start).Seconds())
+	return res, nil
+}
+
+func toFloat32(dst []float32, src []float64) {
+	for i, v := range src {
+		dst[i] = float32(v)
+	}
+}
+
+// Нарушения принципа максимума — предупреждением; с источником принцип не
+// действует (источник добавляет тепло), и предупреждения нет
+func warnPrinciple(ctx context.Context, m Method, mp MaxPrinciple) {
+	if mp.Count == 0 || !m.Source.IsZero() {
+		return
+	}
+	slog.WarnContext(ctx, "Maximum principle violated", "method", m.Name, "violations", mp.Count,
+		"first_step", mp.First.Step, "first_node", mp.First.Node,
+		"worst_step", mp.Worst.Step, "worst_node", mp.Worst.Node, "worst_magnitude", mp.Worst.Magnitude)
+}
+
+// Метод m с обёртками из параметров: адаптивный шаг (принятые шаги — в
+// *steps), старт Раннахера, реакция, источник и шаблон четвёртого порядка
+// по x (компактный или широкий)
+func paramsMethod(m Method, p config.Params, steps *[]AdaptiveStep) (Method, error) {
+	var err error
+	if p.Adapt {
+		if m, err = AdaptiveMethod(m, p.Tol, steps); err != nil {
+			return Method{}, err
+		}
+	}
+	if p.Rannacher {
+		if m, err = RannacherMethod(m); err != nil {
+			return Method{}, err
+		}
+	}
+	if p.Lambda != 0 {
+		if m, err = ReactionMethod(m, p.Lambda); err != nil {
+			return Method{}, err
+		}
+	}
+	if p.Source != "" {
+		src, err := p.ParseSource()
+		if err != nil {
+			return Method{}, &config.ValidationError{Field: "source", Message: err.Error()}
+		}
+		if m, err = SourceMethod(m, src); err != nil {
+			return Method{}, err
+		}
+	}
+	switch {
+	case p.SpatialOrder == 4 && p.Stencil == config.StencilWide:
+		if m, err = WideMethod(m); err != nil {
+			return Method{}, err
+		}
+	case p.SpatialOrder == 4:
+		if m, err = CompactMethod(m); err != nil {
+			return Method{}, err
+		}
+	}
+	return m, nil
+}
+
+// Начальный профиль на сетке: выборка из параметров или предустановка
+// (sin(πx) или ступенька)
+func InitialProfile(p config.Params, nx int) []float64 {
+	switch {
+	case len(p.ICSamples) > 0:
+		return SampledProfile(p.ICSamples, nx, p.Dx)
+	case config.DiscontinuousIC(p.IC):
+		return TopHatProfile(nx)
+	}
+	return SineProfile(nx, p.Dx)
+}
+
+// Точное решение для предустановленного начального условия, с реакцией
+// p.Lambda; для выборки — nil, эталон по ней выбирает вызывающий. С
+// источником p.Source эталон есть только у sin(πx) без реакции и
+// предустановок источника sine и manufactured, иначе nil
+func PresetReference(p config.Params) mathutils.Reference {
+	switch {
+	case len(p.ICSamples) > 0:
+		return nil
+	case p.Source != "":
+		src, err := p.ParseSource()
+		if err != nil || config.DiscontinuousIC(p.IC) || p.Lambda != 0 {
+			return nil
+		}
+		return src.SineSolution()
+	case config.DiscontinuousIC(p.IC):
+		return mathutils.NewTopHatSeries(0.25, 0.75, topHatModes).WithReaction(p.Lambda)
+	}
+	return mathutils.Analytical{Alpha: 1, Lambda: p.Lambda}
+}
+
+// Членов ряда Фурье точного решения для ступеньки: на сетках до nx = 1000
+// обрезка ряда заметна только у разрывов при t = 0
+const topHatModes = 1024
+
+// Ступенька config.ICTopHat на nx интервалах: 1 на (¼, ¾), 0 вне, ½ в
+// узлах разрыва (при nx, кратном 4). Сравнение в целых, без округления x
+func TopHatProfile(nx int) []float64 {
+	u0 := make([]float64, nx+1)
+	for i := range u0 {
+		switch q := 4 * i; {
+		case q == nx || q == 3*nx:
+			u0[i] = 0.5
+		case q > nx && q < 3*nx:
+			u0[i] = 1
+		}
+	}
+	return u0
+}
+
+// Линейная интерполяция выборки (x по возрастанию, покрывает [0, 1]) на узлы сетки
+func SampledProfile(samples []config.Sample, nx int, dx float64) []float64 {
+	u0 := make([]float64, nx+1)
+	k := 0
+	for i := range u0 {
+		x := min(float64(i)*dx, 1)
+		for k < len(samples)-2 && samples[k+1].X < x {
+			k++
+		}
+		a, b := samples[k], samples[k+1]
+		w := (x - a.X) / (b.X - a.X)
+		u0[i] = a.U + w*(b.U-a.U)
+	}
+	return u0
+}
+
+// Решение разошлось: NaN/Inf или рост намного выше начальных данных
+func Diverged(u [][]float64) bool {
+	var u0max float64
+	for _, v := range u[0] {
+		u0max = math.Max(u0max, math.Abs(v))
+	}
+	bound := 1e3 * math.Max(u0max, 1)
+
+	for _, v := range u[len(u)-1] {
+		if math.IsNaN(v) || math.IsInf(v, 0) || math.Abs(v) > bound {
+			return true
+		}
+	}
+	return false
+}
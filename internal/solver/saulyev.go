@@ -0,0 +1,82 @@
+package solver
+
+import (
+	"log/slog"
+	"math"
+)
+
+// Явная схема переменных направлений Саульева. Проход слева направо
+// берёт левого соседа уже с нового слоя:
+// (1+r)·v_i = u_i + r·(v_{i−1} − u_i + u_{i+1}),
+// проход справа налево — правого:
+// (1+r)·w_i = u_i + r·(w_{i+1} − u_i + u_{i−1}).
+// Каждый узел считается явно, по уже посчитанному соседу, без системы.
+// Оба прохода безусловно устойчивы; их среднее u^{n+1} = (v + w)/2
+// (вариант Ларкина) гасит погрешность O(dt/dx) одного прохода
+func SolveSaulyev(nx, nt int, dx, dt float64) [][]float64 {
+	return SolveSaulyevFrom(SineProfile(nx, dx), nt, dx, dt)
+}
+
+// Схема Саульева с заданным начальным профилем u0 (nx+1 значений)
+func SolveSaulyevFrom(u0 []float64, nt int, dx, dt float64) [][]float64 {
+	u := newGrid(nt+1, len(u0))
+	saulyev(u0, nt, dx, dt, u, nil)
+	return u
+}
+
+// Схема Саульева с хранением двух слоёв; каждый слой передаётся в emit
+func StreamSaulyevFrom(u0 []float64, nt int, dx, dt float64, emit EmitFunc) ([]float64, error) {
+	u := newGrid(2, len(u0))
+	if err := saulyev(u0, nt, dx, dt, u, emit); err != nil {
+		return nil, err
+	}
+	return level(u, nt), nil
+}
+
+func saulyev(u0 []float64, nt int, dx, dt float64, u [][]float64, emit EmitFunc) error {
+	nx := len(u0) - 1
+	r := dt / (dx * dx)
+	slog.Info("Starting Saul'yev solver", "nx", nx, "nt", nt, "dx", dx, "dt", dt, "r", r)
+
+	if err := start(u, u0, emit); err != nil {
+		return err
+	}
+
+	// Проход справа налево пишется в отдельный буфер
+	back := pool64.get(nx + 1)
+	defer pool64.put(back)
+	den := 1 / (1 + r)
+	for n := 0; n < nt; n++ {
+		cur, next := level(u, n), level(u, n+1)
+		next[0], next[nx] = 0, 0
+		back[0], back[nx] = 0, 0
+		for i := 1; i < nx; i++ {
+			next[i] = (cur[i] + r*(next[i-1]-cur[i]+cur[i+1])) * den
+		}
+		for i := nx - 1; i >= 1; i-- {
+			back[i] = (cur[i] + r*(back[i+1]-cur[i]+cur[i-1])) * den
+		}
+		for i := 1; i < nx; i++ {
+			next[i] = (next[i] + back[i]) / 2
+		}
+		if err := emitLevel(emit, n+1, next); err != nil {
+			return err
+		}
+	}
+
+	slog.Info("Saul'yev solver finished successfully")
+	return nil
+}
+
+// G(θ) среднего двух проходов. На бесконечной сетке проход слева
+// направо умножает моду на G₊ = (1 − r + r·e^{iθ})/(1 + r − r·e^{−iθ}),
+// справа налево — на сопряжённый G₋, так что их среднее — Re G₊.
+// |G₊|² = ((1−r)² + r² + 2r(1−r)·cosθ)/((1+r)² + r² − 2r(1+r)·cosθ) ≤ 1
+// при любом r
+func saulyevAmplification(r, theta float64) float64 {
+	c, s := math.Cos(theta), math.Sin(theta)
+	// Числитель p и знаменатель q как комплексные числа
+	pr, pi := 1-r+r*c, r*s
+	qr, qi := 1+r-r*c, r*s
+	return (pr*qr + pi*qi) / (qr*qr + qi*qi)
+}
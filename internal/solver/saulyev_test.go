@@ -0,0 +1,123 @@
+package solver
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+
+	"heat-solver/internal/config"
+	"heat-solver/internal/mathutils"
+)
+
+// Проходы Саульева в исходной записи, каждый в свой слой
+func saulyevReference(u0 []float64, nt int, dx, dt float64) [][]float64 {
+	nx := len(u0) - 1
+	r := dt / (dx * dx)
+	u := [][]float64{append([]float64(nil), u0...)}
+	u[0][0], u[0][nx] = 0, 0
+	for n := 0; n < nt; n++ {
+		cur := u[n]
+		v, w := make([]float64, nx+1), make([]float64, nx+1)
+		for i := 1; i < nx; i++ {
+			v[i] = (cur[i] + r*(v[i-1]-cur[i]+cur[i+1])) / (1 + r)
+		}
+		for i := nx - 1; i >= 1; i-- {
+			w[i] = (cur[i] + r*(w[i+1]-cur[i]+cur[i-1])) / (1 + r)
+		}
+		next := make([]float64, nx+1)
+		for i := range next {
+			next[i] = (v[i] + w[i]) / 2
+		}
+		u = append(u, next)
+	}
+	return u
+}
+
+func TestSaulyevSweeps(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	const nx, nt = 30, 20
+	dx := 1.0 / nx
+	u0 := make([]float64, nx+1)
+	for i := range u0 {
+		u0[i] = rng.Float64()
+	}
+	for _, r := range []float64{0.3, 2, 50} {
+		want := saulyevReference(u0, nt, dx, r*dx*dx)
+		got := SolveSaulyevFrom(u0, nt, dx, r*dx*dx)
+		for n := range want {
+			for i := range want[n] {
+				if math.Abs(got[n][i]-want[n][i]) > 1e-14 {
+					t.Fatalf("r=%g, level %d, node %d: %v, want %v", r, n, i, got[n][i], want[n][i])
+				}
+			}
+		}
+	}
+}
+
+// При r = 2, где FTCS расходится, схема остаётся ограниченной, и при
+// измельчении dx с тем же r (dt ~ dx²) ошибка против sin(πx)·exp(−π²t)
+// падает вчетверо: расчёт сходится к точному решению при dt → 0
+func TestSaulyevBeyondFTCS(t *testing.T) {
+	const r, tmax = 2.0, 0.2
+	for _, method := range []string{"FTCS", "SAULYEV"} {
+		nx := 20
+		dx := 1 / float64(nx)
+		p := config.Params{Method: method, Dx: dx, Dt: r * dx * dx, Tmax: 1000 * r * dx * dx, Storage: config.StorageFinalOnly}.Normalize()
+		res, err := Run(context.Background(), p, Hooks{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Diverged != (method == "FTCS") {
+			t.Errorf("%s at r=%g: diverged %v", method, r, res.Diverged)
+		}
+		res.Release()
+	}
+
+	// На грубых сетках член (dt/dx)² = r²·dx²·… ещё не вышел на асимптотику
+	var prev float64
+	for k, nx := range []int{80, 160, 320} {
+		dx := 1 / float64(nx)
+		dt := r * dx * dx
+		nt := int(math.Round(tmax / dt))
+		last, err := StreamSaulyevFrom(SineProfile(nx, dx), nt, dx, dt, func(n int, row []float64) error {
+			for i, v := range row {
+				if math.Abs(v) > 1 {
+					return fmt.Errorf("level %d, node %d: |u| = %v", n, i, math.Abs(v))
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("nx=%d: %v", nx, err)
+		}
+		l2, _ := mathutils.LevelErrors(last, dx, float64(nt)*dt, mathutils.Analytical{Alpha: 1})
+		if k > 0 {
+			if order := math.Log2(prev / l2); math.Abs(order-2) > 0.1 {
+				t.Errorf("nx=%d: error %.3g → %.3g, observed order %.2f", nx, prev, l2, order)
+			}
+		}
+		prev = l2
+	}
+}
+
+// |G| ≤ 1 при любом r и угле; гладкая мода не затухает
+func TestSaulyevAmplification(t *testing.T) {
+	for _, r := range []float64{0.1, 0.5, 1, 2, 10, 1e3} {
+		t.Run(fmt.Sprintf("r=%g", r), func(t *testing.T) {
+			if g := saulyevAmplification(r, 0); math.Abs(g-1) > 1e-15 {
+				t.Errorf("G(0) = %v, want 1", g)
+			}
+			for k := 1; k <= 100; k++ {
+				angle := math.Pi * float64(k) / 100
+				if g := saulyevAmplification(r, angle); math.Abs(g) > 1+1e-15 {
+					t.Fatalf("|G(%g)| = %v", angle, math.Abs(g))
+				}
+			}
+			if !mustLookup(t, "SAULYEV").StableAt(r) {
+				t.Error("not reported stable")
+			}
+		})
+	}
+}
@@ -5,9 +5,34 @@ import (
 	"heat-solver/internal/mathutils"
 )
 
-// FTCS (явная схема)
+// Начальный профиль sin(πx) на узлах сетки
+func SineProfile(nx int, dx float64) []float64 {
+	u0 := make([]float64, nx+1)
+	for i := range u0 {
+		u0[i] = mathutils.InitialCondition(float64(i) * dx)
+	}
+	return u0
+}
+
+// FTCS (явная схема): θ-схема при θ = 0
 func SolveFTCS(nx, nt int, dx, dt float64) [][]float64 {
-	r := dt / (dx * dx)
+	return SolveTheta(nx, nt, dx, dt, 1, 0)
+}
+
+// FTCS с заданным начальным профилем u0 (nx+1 значений)
+func SolveFTCSFrom(u0 []float64, nt int, dx, dt float64) [][]float64 {
+	return SolveThetaFrom(u0, nt, dx, dt, 1, 0)
+}
+
+// FTCS с хранением двух слоёв; каждый слой передаётся в emit
+func StreamFTCSFrom(u0 []float64, nt int, dx, dt float64, emit EmitFunc) ([]float64, error) {
+	return StreamThetaFrom(u0, nt, dx, dt, 1, 0, emit)
+}
+
+// Шаг FTCS: u_i += r·(u_{i−1} − 2u_i + u_{i+1}), r = α·dt/dx²
+func ftcs(u0 []float64, nt int, dx, dt, alpha float64, u [][]float64, emit EmitFunc) error {
+	nx := len(u0) - 1
+	r := alpha * dt / (dx * dx)
 	if r > 0.5 {
 		slog.Warn("FTCS may be unstable", "r", r)
 	} else {
@@ -16,152 +41,165 @@ func SolveFTCS(nx, nt int, dx, dt float64) [][]float64 {
 
 	slog.Info("Starting FTCS solver", "nx", nx, "nt", nt, "dx", dx, "dt", dt)
 
-	u := make([][]float64, nt+1)
-	for i := range u {
-		u[i] = make([]float64, nx+1)
-	}
-
 	// Начальное условие
-	for i := 0; i <= nx; i++ {
-		x := float64(i) * dx
-		u[0][i] = mathutils.InitialCondition(x)
+	if err := start(u, u0, emit); err != nil {
+		return err
 	}
 
-	// Граничные условия
-	for n := 0; n <= nt; n++ {
-		u[n][0] = 0.0
-		u[n][nx] = 0.0
+	// При большом nx внутренние узлы делятся между потоками; фрагменты
+	// не пересекаются, поэтому результат тот же, что и у одного потока
+	var shared [2][]float64 // слои текущего шага для потоков пула
+	update := func(_, lo, hi int) {
+		ftcsUpdate(shared[0][lo-1:hi+1], shared[1][lo-1:hi+1], r)
+	}
+	pool := newChunkPool(1, nx, ftcsMinChunk)
+	if pool != nil {
+		defer pool.close()
+		slog.Info("FTCS runs in parallel", "threads", pool.size())
 	}
 
 	// Основной цикл
 	for n := 0; n < nt; n++ {
-		for i := 1; i < nx; i++ {
-			u[n+1][i] = u[n][i] + r*(u[n][i+1]-2*u[n][i]+u[n][i-1])
+		cur, next := level(u, n), level(u, n+1)
+		// Граничные условия
+		next[0], next[nx] = 0, 0
+		if pool != nil {
+			shared = [2][]float64{cur, next}
+			pool.run(update)
+		} else {
+			ftcsUpdate(cur, next, r)
+		}
+		if err := emitLevel(emit, n+1, next); err != nil {
+			return err
 		}
 	}
 
 	slog.Info("FTCS solver finished successfully")
-	return u
+	return nil
 }
 
-// BTCS (неявная схема)
-func SolveBTCS(nx, nt int, dx, dt float64) [][]float64 {
-	r := dt / (dx * dx)
-	slog.Info("Starting BTCS solver", "nx", nx, "nt", nt, "dx", dx, "dt", dt, "r", r)
-
-	u := make([][]float64, nt+1)
-	for i := range u {
-		u[i] = make([]float64, nx+1)
-	}
-
-	for i := 0; i <= nx; i++ {
-		x := float64(i) * dx
-		u[0][i] = mathutils.InitialCondition(x)
-	}
-
-	for n := 0; n <= nt; n++ {
-		u[n][0] = 0.0
-		u[n][nx] = 0.0
+// Наименьший фрагмент внутренних узлов на поток: около десятков
+// микросекунд работы FTCS, чтобы синхронизация на шаге не съедала выигрыш
+const ftcsMinChunk = 1 << 14
+
+// Шаг FTCS для внутренних узлов среза на чистом Go; крайние узлы next не
+// меняются. Левый, центральный и правый соседи — срезы cur той же длины,
+// что и внутренняя часть next, поэтому в цикле нет проверок границ
+// (go build -gcflags=-d=ssa/check_bce). Перенос соседей между итерациями
+// в локальных переменных оказался медленнее. Преобразование float64
+// запрещает компилятору сливать умножение и сложение в FMA (GOAMD64=v3):
+// векторное ядро ftcsUpdate округляет так же, и результаты совпадают побитно
+func ftcsUpdateGeneric(cur, next []float64, r float64) {
+	if len(cur) < 3 {
+		return
+	}
+	next = next[1 : len(cur)-1]
+	left, mid, right := cur[:len(next)], cur[1:len(next)+1], cur[2:len(next)+2]
+	for i := range next {
+		next[i] = mid[i] + float64(r*(right[i]-2*mid[i]+left[i]))
 	}
+}
 
-	a := make([]float64, nx-1)
-	b := make([]float64, nx-1)
-	c := make([]float64, nx-1)
-	d := make([]float64, nx-1)
+// BTCS (неявная схема): θ-схема при θ = 1
+func SolveBTCS(nx, nt int, dx, dt float64) [][]float64 {
+	return SolveTheta(nx, nt, dx, dt, 1, 1)
+}
 
-	for i := 0; i < nx-1; i++ {
-		a[i] = -r
-		b[i] = 1 + 2*r
-		c[i] = -r
-	}
+func SolveBTCSFrom(u0 []float64, nt int, dx, dt float64) [][]float64 {
+	return SolveThetaFrom(u0, nt, dx, dt, 1, 1)
+}
 
-	for n := 0; n < nt; n++ {
-		for i := 0; i < nx-1; i++ {
-			d[i] = u[n][i+1]
-		}
+func StreamBTCSFrom(u0 []float64, nt int, dx, dt float64, emit EmitFunc) ([]float64, error) {
+	return StreamThetaFrom(u0, nt, dx, dt, 1, 1, emit)
+}
 
-		d[0] += r * u[n+1][0]
-		d[nx-2] += r * u[n+1][nx]
+// Crank–Nicolson (полуявная схема): θ-схема при θ = ½
+func SolveCrankNicolson(nx, nt int, dx, dt float64) [][]float64 {
+	return SolveTheta(nx, nt, dx, dt, 1, 0.5)
+}
 
-		solution := thomasAlgorithm(a, b, c, d)
-		for i := 0; i < nx-1; i++ {
-			u[n+1][i+1] = solution[i]
-		}
-	}
+func SolveCrankNicolsonFrom(u0 []float64, nt int, dx, dt float64) [][]float64 {
+	return SolveThetaFrom(u0, nt, dx, dt, 1, 0.5)
+}
 
-	slog.Info("BTCS solver finished successfully")
-	return u
+func StreamCrankNicolsonFrom(u0 []float64, nt int, dx, dt float64, emit EmitFunc) ([]float64, error) {
+	return StreamThetaFrom(u0, nt, dx, dt, 1, 0.5, emit)
 }
 
-// Crank–Nicolson (полуявная схема)
-func SolveCrankNicolson(nx, nt int, dx, dt float64) [][]float64 {
-	r := dt / (dx * dx)
-	slog.Info("Starting Crank–Nicolson solver", "nx", nx, "nt", nt, "dx", dx, "dt", dt, "r", r)
+// Диагонали a, b, c и правая часть d системы из n уравнений в одном
+// буфере из пула; ws возвращается в пул после расчёта
+func workspace(n int) (a, b, c, d, ws []float64) {
+	ws = pool64.get(4 * n)
+	return ws[:n:n], ws[n : 2*n : 2*n], ws[2*n : 3*n : 3*n], ws[3*n:], ws
+}
 
-	u := make([][]float64, nt+1)
-	for i := range u {
-		u[i] = make([]float64, nx+1)
-	}
+// Алгоритм Томаса (метод прогонки)
+func thomasAlgorithm(a, b, c, d []float64) []float64 {
+	n := len(d)
+	x := make([]float64, n)
+	t := newThomas(n)
+	defer t.close()
+	t.factor(a, b, c)
+	t.solve(d, x)
 
-	for i := 0; i <= nx; i++ {
-		x := float64(i) * dx
-		u[0][i] = mathutils.InitialCondition(x)
-	}
+	slog.Debug("Thomas algorithm executed", "n", n)
+	return x
+}
 
-	for n := 0; n <= nt; n++ {
-		u[n][0] = 0.0
-		u[n][nx] = 0.0
-	}
+// Прогонка с разложением, вычисленным один раз: матрица (a, b, c) у BTCS
+// и CN постоянна на всём расчёте, и на шаге остаются только прямой ход
+// по правой части и обратная подстановка. Если коэффициенты меняются
+// (другой dt или α), нужно снова вызвать factor
+type thomas struct {
+	// den — знаменатели b[i] − a[i]·cp[i−1]
+	a, cp, den []float64
+	// Общий буфер cp и den из пула
+	buf []float64
+}
 
-	a := make([]float64, nx-1)
-	b := make([]float64, nx-1)
-	c := make([]float64, nx-1)
-	d := make([]float64, nx-1)
+func newThomas(n int) *thomas {
+	buf := pool64.get(2 * n)
+	return &thomas{cp: buf[:n:n], den: buf[n:], buf: buf}
+}
 
-	for i := 0; i < nx-1; i++ {
-		a[i] = -r / 2
-		b[i] = 1 + r
-		c[i] = -r / 2
-	}
+// Возврат рабочих массивов в пул; после close прогонка не используется
+func (t *thomas) close() {
+	pool64.put(t.buf)
+	t.buf, t.cp, t.den = nil, nil, nil
+}
 
-	for n := 0; n < nt; n++ {
-		for i := 0; i < nx-1; i++ {
-			d[i] = (r/2)*u[n][i] + (1-r)*u[n][i+1] + (r/2)*u[n][i+2]
-		}
-		d[0] += (r / 2) * u[n+1][0]
-		d[nx-2] += (r / 2) * u[n+1][nx]
+// Прямой ход по матрице: cp и знаменатели. На шаге по-прежнему делится на
+// знаменатель, а не умножается на обратный: результат побитово совпадает
+// с прогонкой без разложения
+func (t *thomas) factor(a, b, c []float64) {
+	n := len(b)
+	t.a = a
+	t.cp, t.den = t.cp[:n], t.den[:n]
 
-		solution := thomasAlgorithm(a, b, c, d)
-		for i := 0; i < nx-1; i++ {
-			u[n+1][i+1] = solution[i]
-		}
+	t.den[0] = b[0]
+	t.cp[0] = c[0] / b[0]
+	for i := 1; i < n; i++ {
+		t.den[i] = b[i] - a[i]*t.cp[i-1]
+		t.cp[i] = c[i] / t.den[i]
 	}
-
-	slog.Info("Crank–Nicolson solver finished successfully")
-	return u
 }
 
-// Алгоритм Томаса (метод прогонки)
-func thomasAlgorithm(a, b, c, d []float64) []float64 {
+// Решение системы с правой частью d в x (len(x) = len(d)); x может быть
+// срезом слоя решения, но не должен пересекаться с d
+func (t *thomas) solve(d, x []float64) {
 	n := len(d)
-	cp := make([]float64, n)
-	dp := make([]float64, n)
-	x := make([]float64, n)
-
-	cp[0] = c[0] / b[0]
-	dp[0] = d[0] / b[0]
+	a, cp, den, x := t.a[:n], t.cp[:n], t.den[:n], x[:n]
 
+	// Предыдущее значение держится в локальной переменной, а не читается
+	// из x; срезы одной длины n, поэтому проверок границ в циклах нет
+	prev := d[0] / den[0]
+	x[0] = prev
 	for i := 1; i < n; i++ {
-		denom := b[i] - a[i]*cp[i-1]
-		cp[i] = c[i] / denom
-		dp[i] = (d[i] - a[i]*dp[i-1]) / denom
+		prev = (d[i] - a[i]*prev) / den[i]
+		x[i] = prev
 	}
-
-	x[n-1] = dp[n-1]
 	for i := n - 2; i >= 0; i-- {
-		x[i] = dp[i] - cp[i]*x[i+1]
+		prev = x[i] - cp[i]*prev
+		x[i] = prev
 	}
-
-	slog.Debug("Thomas algorithm executed", "n", n)
-	return x
 }
@@ -0,0 +1,217 @@
+package solver
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand"
+	"os"
+	"testing"
+)
+
+// Схемы пишут в журнал о каждом расчёте; в тестах и бенчмарках он не нужен
+func TestMain(m *testing.M) {
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	os.Exit(m.Run())
+}
+
+// Прогонка без разложения, как до thomas.factor: cp и dp на каждом решении
+func thomasReference(a, b, c, d []float64) []float64 {
+	n := len(d)
+	cp, dp, x := make([]float64, n), make([]float64, n), make([]float64, n)
+	cp[0] = c[0] / b[0]
+	dp[0] = d[0] / b[0]
+	for i := 1; i < n; i++ {
+		denom := b[i] - a[i]*cp[i-1]
+		cp[i] = c[i] / denom
+		dp[i] = (d[i] - a[i]*dp[i-1]) / denom
+	}
+	x[n-1] = dp[n-1]
+	for i := n - 2; i >= 0; i-- {
+		x[i] = dp[i] - cp[i]*x[i+1]
+	}
+	return x
+}
+
+// Разложенная прогонка побитово совпадает с прогонкой без разложения: на
+// матрицах BTCS и CN и на случайных с диагональным преобладанием, с
+// несколькими правыми частями на одно разложение
+func TestThomasFactorBitIdentical(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	tests := []struct {
+		name    string
+		n       int
+		a, b, c func(i int) float64
+	}{
+		{"BTCS r=0.4", 9, func(int) float64 { return -0.4 }, func(int) float64 { return 1.8 }, func(int) float64 { return -0.4 }},
+		{"CN r=16", 39, func(int) float64 { return -8 }, func(int) float64 { return 17 }, func(int) float64 { return -8 }},
+		{"random", 200, func(int) float64 { return rng.Float64() - 0.5 }, func(int) float64 { return 2 + rng.Float64() }, func(int) float64 { return rng.Float64() - 0.5 }},
+		{"single", 1, func(int) float64 { return 0 }, func(int) float64 { return 3 }, func(int) float64 { return 0 }},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			a, b, c := make([]float64, tc.n), make([]float64, tc.n), make([]float64, tc.n)
+			for i := range b {
+				a[i], b[i], c[i] = tc.a(i), tc.b(i), tc.c(i)
+			}
+			tri := newThomas(tc.n)
+			defer tri.close()
+			tri.factor(a, b, c)
+			for rhs := 0; rhs < 3; rhs++ {
+				d := make([]float64, tc.n)
+				for i := range d {
+					d[i] = rng.NormFloat64()
+				}
+				x := make([]float64, tc.n)
+				tri.solve(d, x)
+				want := thomasReference(a, b, c, d)
+				for i := range x {
+					if x[i] != want[i] {
+						t.Fatalf("rhs %d: x[%d] = %v, want %v (diff %g)", rhs, i, x[i], want[i], x[i]-want[i])
+					}
+				}
+				if got := thomasAlgorithm(a, b, c, d); got[tc.n-1] != want[tc.n-1] {
+					t.Fatalf("thomasAlgorithm: x[%d] = %v, want %v", tc.n-1, got[tc.n-1], want[tc.n-1])
+				}
+			}
+		})
+	}
+}
+
+// Шаг неявной схемы без выделений памяти: правая часть и решение пишутся
+// в готовые срезы, рабочие массивы прогонки взяты при разложении
+func TestThomasSolveNoAllocs(t *testing.T) {
+	const n = 999
+	a, b, c, d, ws := workspace(n)
+	defer pool64.put(ws)
+	for i := range b {
+		a[i], b[i], c[i] = -0.5, 2, -0.5
+	}
+	tri := newThomas(n)
+	defer tri.close()
+	tri.factor(a, b, c)
+	cur, next := SineProfile(n+1, 1.0/(n+1)), make([]float64, n+2)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		thetaRHS(cur, d, 0.5)
+		tri.solve(d, next[1:n+1])
+	})
+	if allocs != 0 {
+		t.Errorf("%v allocations per step, want 0", allocs)
+	}
+}
+
+// Шаг CN при nx = 10⁴: -benchmem показывает 0 allocs/op
+func BenchmarkThomasStep(b *testing.B) {
+	const n = 9999
+	a, bd, c, d, ws := workspace(n)
+	defer pool64.put(ws)
+	for i := range bd {
+		a[i], bd[i], c[i] = -0.5, 2, -0.5
+	}
+	tri := newThomas(n)
+	defer tri.close()
+	tri.factor(a, bd, c)
+	cur, next := SineProfile(n+1, 1.0/(n+1)), make([]float64, n+2)
+	b.ReportAllocs()
+	for b.Loop() {
+		thetaRHS(cur, d, 0.5)
+		tri.solve(d, next[1:n+1])
+	}
+}
+
+// Прогонка без разложения на каждом шаге — для сравнения с BenchmarkThomasStep
+func BenchmarkThomasStepUnfactored(b *testing.B) {
+	const n = 9999
+	a, bd, c := make([]float64, n), make([]float64, n), make([]float64, n)
+	for i := range bd {
+		a[i], bd[i], c[i] = -0.5, 2, -0.5
+	}
+	cur, d := SineProfile(n+1, 1.0/(n+1)), make([]float64, n)
+	b.ReportAllocs()
+	for b.Loop() {
+		thetaRHS(cur, d, 0.5)
+		thomasReference(a, bd, c, d)
+	}
+}
+
+// Схемы в исходной записи через u[n][i±1] и прогонку без разложения:
+// образец для проверки перестроенных циклов. float64(...) запрещает FMA,
+// как и в ftcsUpdateGeneric
+func stencilReference(method string, u0 []float64, nt int, dx, dt float64) [][]float64 {
+	nx := len(u0) - 1
+	r := dt / (dx * dx)
+	u := make([][]float64, nt+1)
+	u[0] = append([]float64(nil), u0...)
+	u[0][0], u[0][nx] = 0, 0
+	a, b, c := make([]float64, nx-1), make([]float64, nx-1), make([]float64, nx-1)
+	for i := range b {
+		a[i], b[i], c[i] = -r, 1+2*r, -r
+		if method == "CN" {
+			a[i], b[i], c[i] = -r/2, 1+r, -r/2
+		}
+	}
+	for n := 0; n < nt; n++ {
+		u[n+1] = make([]float64, nx+1)
+		d := make([]float64, nx-1)
+		switch method {
+		case "FTCS":
+			for i := 1; i < nx; i++ {
+				u[n+1][i] = u[n][i] + float64(r*(u[n][i+1]-2*u[n][i]+u[n][i-1]))
+			}
+			continue
+		case "BTCS":
+			for i := 0; i < nx-1; i++ {
+				d[i] = u[n][i+1]
+			}
+		case "CN":
+			for i := 0; i < nx-1; i++ {
+				d[i] = float64((r/2)*u[n][i]) + float64((1-r)*u[n][i+1]) + float64((r/2)*u[n][i+2])
+			}
+		}
+		copy(u[n+1][1:nx], thomasReference(a, b, c, d))
+	}
+	return u
+}
+
+// Перестроенные циклы FTCS, BTCS и CN (и векторное ядро FTCS) дают
+// побитно тот же результат, что исходная запись схем
+func TestStencilBitIdentical(t *testing.T) {
+	solvers := map[string]func(u0 []float64, nt int, dx, dt float64) [][]float64{
+		"FTCS": SolveFTCSFrom,
+		"BTCS": SolveBTCSFrom,
+		"CN":   SolveCrankNicolsonFrom,
+	}
+	rng := rand.New(rand.NewSource(1))
+	for name, solve := range solvers {
+		for _, nx := range []int{2, 3, 4, 5, 17, 100, 1001} {
+			t.Run(fmt.Sprintf("%s/nx=%d", name, nx), func(t *testing.T) {
+				dx := 1.0 / float64(nx)
+				dt := 0.4 * dx * dx
+				u0 := SineProfile(nx, dx)
+				for i := range u0 {
+					u0[i] += 1e-3 * rng.NormFloat64()
+				}
+				got := solve(u0, 50, dx, dt)
+				want := stencilReference(name, u0, 50, dx, dt)
+				for n := range want {
+					for i := range want[n] {
+						if got[n][i] != want[n][i] {
+							t.Fatalf("u[%d][%d] = %v, want %v", n, i, got[n][i], want[n][i])
+						}
+					}
+				}
+			})
+		}
+	}
+}
+
+// Полный расчёт FTCS на большом nx
+func BenchmarkSolveFTCS(b *testing.B) {
+	const nx, nt = 100000, 100
+	dx := 1.0 / nx
+	u0 := SineProfile(nx, dx)
+	for b.Loop() {
+		SolveFTCSFrom(u0, nt, dx, 0.4*dx*dx)
+	}
+}
@@ -0,0 +1,151 @@
+package solver
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"heat-solver/internal/config"
+	"heat-solver/internal/mathutils"
+)
+
+// Метод FTCS, BTCS или CN для u_t = u_xx + f(x, t), как при непустом
+// Params.Source. У FTCS к явному шагу добавляется dt·f^n, у неявных схем
+// правая часть получает dt·(θf^{n+1} + (1−θ)f^n): у CN источник
+// усредняется по шагу, и второй порядок по dt сохраняется
+func SourceMethod(m Method, src mathutils.Source) (Method, error) {
+	if m.Adaptive {
+		return Method{}, &config.ValidationError{Field: "source", Message: "a source term is not available with adaptive time steps"}
+	}
+	if m.wide {
+		return Method{}, &config.ValidationError{Field: "source", Message: "a source term is not available with the wide stencil"}
+	}
+	var theta float64
+	switch m.Name {
+	case "FTCS":
+		theta = 0
+	case "BTCS":
+		theta = 1
+	case "CN":
+		theta = 0.5
+	default:
+		return Method{}, &config.ValidationError{Field: "source", Message: fmt.Sprintf("a source term is available for FTCS, BTCS and CN, not %s", m.Name)}
+	}
+	m.Source = src
+	m.Description += fmt.Sprintf("; volumetric source f = %s", src.Expr)
+	m.setTheta(theta)
+	return m, nil
+}
+
+// Проверочные случаи источника; в Verify — под именем SOURCE
+var verificationSource = []VerifyCase{
+	sourceSteadyCase("CN", 0.05, 0.01, 3, 5e-4),
+	sourceSteadyCase("BTCS", 0.05, 0.01, 3, 5e-4),
+	sourceSteadyCase("FTCS", 0.05, 0.001, 3, 5e-4),
+	sourceOrderCase("CN", 0.1, 0.1, 1, 2, 2, 0.1),
+	sourceOrderCase("BTCS", 0.1, 0.01, 1, 4, 2, 0.1),
+	laserBalanceCase("CN", 0.02, 0.001, 0.1),
+	laserBalanceCase("CN", 0.01, 0.0005, 0.05),
+	laserBalanceCase("BTCS", 0.01, 0.0005, 0.05),
+	laserBalanceCase("FTCS", 0.01, 0.00005, 0.05),
+}
+
+// Пучок, который проходит отрезок и уходит с него: P = 100, v = 0.5,
+// σ = 0.05, x₀ = 0.2, центр покидает [0, 1] при t = 1.6
+var verifyLaser = mathutils.Laser{Power: 100, Speed: 0.5, Width: 0.05, Start: 0.2}
+
+// Баланс тепла каждого шага с лазером verifyLaser до t = 2: наибольшее
+// |ΔQ/dt − (приток через границы + мощность пучка)| относительно
+// наибольшего члена баланса (FluxBalance.StepImbalance и PeakRate) — не
+// больше tol.
+// Приток считается односторонними разностями второго порядка, а схема
+// теряет тепло через разность первого (u_1 − u_0)/dx; у края u_xx = −f,
+// и разница — около dx/2·f на краю. Небаланс поэтому первого порядка по
+// dx и наибольший, когда пучок проходит край
+func laserBalanceCase(name string, dx, dt, tol float64) VerifyCase {
+	return VerifyCase{
+		Name:     fmt.Sprintf("%s laser heat balance per step dx=%g dt=%g", name, dx, dt),
+		Quantity: "step imbalance / peak rate",
+		Compare:  VerifyAtMost,
+		Expected: tol,
+		Measure: func(ctx context.Context, _ Method) (float64, string, error) {
+			res, err := sourceRun(ctx, config.Params{Method: name, Dx: dx, Dt: dt, Tmax: 2, Source: mathutils.SourceLaser, Laser: verifyLaser})
+			if err != nil {
+				return math.NaN(), "", err
+			}
+			defer res.Release()
+			f := res.Flux
+			return f.StepImbalance / f.PeakRate, fmt.Sprintf("worst at step %d; over the run ΔQ %.5g, supplied %.5g, boundary %.5g, relative imbalance %.2g",
+				f.StepWorst, f.Final-f.Initial, f.Supplied, f.Inflow, f.Relative), nil
+		},
+	}
+}
+
+// Выход на стационар с -source "sin(pi*x)" из нуля: при больших t решение
+// стремится к sin(πx)/π², наибольшее отклонение в узлах — не больше tol
+// (у схемы второго порядка по dx — около π²dx²/12 от амплитуды)
+func sourceSteadyCase(name string, dx, dt, tmax, tol float64) VerifyCase {
+	return VerifyCase{
+		Name:     fmt.Sprintf("%s f=sin(πx) steady state dx=%g dt=%g", name, dx, dt),
+		Quantity: "max |u − sin(πx)/π²|",
+		Compare:  VerifyAtMost,
+		Expected: tol,
+		Measure: func(ctx context.Context, _ Method) (float64, string, error) {
+			res, err := sourceRun(ctx, config.Params{Method: name, Dx: dx, Dt: dt, Tmax: tmax,
+				ICSamples: []config.Sample{{X: 0, U: 0}, {X: 1, U: 0}}, Source: "sin(pi*x)"})
+			if err != nil {
+				return math.NaN(), "", err
+			}
+			defer res.Release()
+			var worst float64
+			for i, v := range res.Final {
+				worst = max(worst, math.Abs(v-math.Sin(math.Pi*float64(i)*res.Dx)/(math.Pi*math.Pi)))
+			}
+			mid := res.Final[res.Nx/2]
+			return worst, fmt.Sprintf("u(½) = %.6g, steady %.6g at t = %g", mid, 1/(math.Pi*math.Pi), float64(res.Nt)*res.Dt), nil
+		},
+	}
+}
+
+// Наблюдаемый порядок по dx на построенном решении cos t·sin(πx)
+// (-source manufactured из sin(πx)): dx делится пополам, dt — на
+// dtFactor. Источник зависит от t, так что проверяется и его вес по
+// времени: у CN при весе не ½ порядок упал бы до первого
+func sourceOrderCase(name string, dx, dt, tmax, dtFactor, order, tol float64) VerifyCase {
+	return VerifyCase{
+		Name:      fmt.Sprintf("%s manufactured source order from dx=%g dt=%g", name, dx, dt),
+		Quantity:  "observed order",
+		Compare:   VerifyNear,
+		Expected:  order,
+		Tolerance: tol,
+		Measure: func(ctx context.Context, _ Method) (float64, string, error) {
+			var errs [3]float64
+			for k := range errs {
+				h, tau := dx/math.Exp2(float64(k)), dt/math.Pow(dtFactor, float64(k))
+				p := config.Params{Method: name, Dx: h, Dt: tau, Tmax: tmax, Source: mathutils.SourceManufactured}
+				res, err := sourceRun(ctx, p)
+				if err != nil {
+					return math.NaN(), "", err
+				}
+				errs[k], _ = mathutils.LevelErrors(res.Final, res.Dx, float64(res.Nt)*res.Dt, PresetReference(p))
+				res.Release()
+			}
+			first, second := math.Log2(errs[0]/errs[1]), math.Log2(errs[1]/errs[2])
+			return second, fmt.Sprintf("errors %.3g, %.3g, %.3g; orders %.3f, %.3f", errs[0], errs[1], errs[2], first, second), nil
+		},
+	}
+}
+
+// Расчёт через Run, как из CLI и сервера; хранится только последний слой
+func sourceRun(ctx context.Context, p config.Params) (*Result, error) {
+	p.Storage, p.Precision = config.StorageFinalOnly, config.PrecisionFloat64
+	res, err := Run(ctx, p, Hooks{})
+	if err != nil {
+		return nil, err
+	}
+	if res.Diverged {
+		res.Release()
+		return nil, fmt.Errorf("%s solve with dx=%g dt=%g diverged", p.Method, p.Dx, p.Dt)
+	}
+	return res, nil
+}
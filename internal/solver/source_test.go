@@ -0,0 +1,148 @@
+package solver
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+
+	"heat-solver/internal/config"
+	"heat-solver/internal/mathutils"
+)
+
+// -source "sin(pi*x)" из нуля: при больших t решение выходит на
+// sin(πx)/π²; отклонение — ошибка схемы по dx, второго порядка
+func TestSourceSteady(t *testing.T) {
+	tests := []struct {
+		method string
+		dt     float64
+	}{
+		{"FTCS", 0.2},
+		{"BTCS", 100},
+		{"CN", 2},
+	}
+	for _, tc := range tests {
+		t.Run(tc.method, func(t *testing.T) {
+			var prev float64
+			for _, dx := range []float64{0.1, 0.05, 0.025} {
+				dt := tc.dt * dx * dx
+				p := config.Params{Method: tc.method, Dx: dx, Dt: dt, Tmax: 4,
+					ICSamples: []config.Sample{{X: 0, U: 0}, {X: 1, U: 0}}, Source: "sin(pi*x)"}
+				res, err := sourceRun(context.Background(), p)
+				if err != nil {
+					t.Fatal(err)
+				}
+				var worst float64
+				for i, v := range res.Final {
+					worst = max(worst, math.Abs(v-math.Sin(math.Pi*float64(i)*dx)/(math.Pi*math.Pi)))
+				}
+				res.Release()
+				// Стационар разностной задачи — sin(πx_i)/λ_h, где
+				// λ_h = 4sin²(πdx/2)/dx² ≈ π²(1 − π²dx²/12): отклонение
+				// 1/λ_h − 1/π² ≈ dx²/12
+				if worst > 0.1*dx*dx {
+					t.Errorf("dx=%g: max deviation %.3g from sin(πx)/π²", dx, worst)
+				}
+				if prev > 0 {
+					if order := math.Log2(prev / worst); math.Abs(order-2) > 0.1 {
+						t.Errorf("dx=%g: order %.3f", dx, order)
+					}
+				}
+				prev = worst
+			}
+		})
+	}
+}
+
+// Построенное решение cos t·sin(πx): источник зависит от t, и у CN вес
+// ½ по времени держит второй порядок при dt ~ dx, у BTCS — первый по dt
+func TestSourceManufactured(t *testing.T) {
+	tests := []struct {
+		method   string
+		dx, dt   float64
+		dtFactor float64
+		order    float64
+	}{
+		{"CN", 0.1, 0.1, 2, 2},
+		{"BTCS", 0.1, 0.01, 4, 2},
+		{"BTCS", 0.005, 0.05, 2, 1},
+	}
+	for _, tc := range tests {
+		t.Run(tc.method, func(t *testing.T) {
+			var errs [3]float64
+			for k := range errs {
+				p := config.Params{Method: tc.method, Dx: tc.dx / math.Exp2(float64(k)), Dt: tc.dt / math.Pow(tc.dtFactor, float64(k)),
+					Tmax: 1, Source: mathutils.SourceManufactured}
+				res, err := sourceRun(context.Background(), p)
+				if err != nil {
+					t.Fatal(err)
+				}
+				errs[k], _ = mathutils.LevelErrors(res.Final, res.Dx, float64(res.Nt)*res.Dt, PresetReference(p))
+				res.Release()
+			}
+			if order := math.Log2(errs[1] / errs[2]); math.Abs(order-tc.order) > 0.15 {
+				t.Errorf("errors %.3g, %.3g, %.3g: order %.3f, want %g", errs[0], errs[1], errs[2], order, tc.order)
+			}
+		})
+	}
+}
+
+// Нулевой источник, заданный выражением, не меняет решения неявных схем;
+// FTCS с источником считает не векторное ядро, а шаг θ-схемы, и расходится
+// с ним в пределах округления
+func TestSourceZero(t *testing.T) {
+	tests := []struct {
+		method string
+		tol    float64
+	}{
+		{"FTCS", 1e-15},
+		{"BTCS", 0},
+		{"CN", 0},
+	}
+	for _, tc := range tests {
+		t.Run(tc.method, func(t *testing.T) {
+			p := config.Params{Method: tc.method, Dx: 0.05, Dt: 0.001, Tmax: 0.1}
+			plain, err := sourceRun(context.Background(), p)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer plain.Release()
+			p.Source = "0*x"
+			zero, err := sourceRun(context.Background(), p)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer zero.Release()
+			for i, v := range zero.Final {
+				if math.Abs(v-plain.Final[i]) > tc.tol {
+					t.Fatalf("f = 0 changed u[%d] from %v to %v", i, plain.Final[i], v)
+				}
+			}
+		})
+	}
+}
+
+func TestSourceRejects(t *testing.T) {
+	tests := []struct {
+		name  string
+		p     config.Params
+		field string
+	}{
+		{"three-level", config.Params{Method: "DUFORT-FRANKEL", Source: "sine"}, "source"},
+		{"adaptive", config.Params{Method: "RK45", Source: "sine"}, "source"},
+		{"bad expression", config.Params{Method: "CN", Source: "sin(x"}, "source"},
+		{"laser parameters without laser", config.Params{Method: "CN", Source: "sine", Laser: mathutils.DefaultLaser}, "source"},
+		{"wide stencil", config.Params{Method: "CN", Source: "sine", SpatialOrder: 4, Stencil: config.StencilWide}, "stencil"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			p := tc.p
+			p.Dx, p.Dt, p.Tmax = 0.05, 0.001, 0.01
+			_, err := Run(context.Background(), p.Normalize(), Hooks{})
+			var verr *config.ValidationError
+			if !errors.As(err, &verr) || verr.Field != tc.field {
+				t.Errorf("error %v, want a validation error for %s", err, tc.field)
+			}
+		})
+	}
+}
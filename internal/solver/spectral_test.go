@@ -0,0 +1,72 @@
+package solver
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"heat-solver/internal/mathutils"
+)
+
+// Для sin(πx) спектральная схема даёт ошибку на уровне округления уже при
+// nx = 16, а FTCS при r = 0.4 и на nx = 500 остаётся на много порядков
+// хуже
+func TestSpectralMachinePrecision(t *testing.T) {
+	const tmax = 0.1
+	exact := mathutils.Analytical{Alpha: 1}
+	for _, nx := range []int{16, 32, 100, 256} {
+		t.Run(fmt.Sprintf("nx=%d", nx), func(t *testing.T) {
+			dx := 1.0 / float64(nx)
+			for _, dt := range []float64{0.1, 0.01} {
+				nt := int(math.Round(tmax / dt))
+				u := SolveSpectral(nx, nt, dx, dt)
+				if l2, linf := mathutils.LevelErrors(u[nt], dx, tmax, exact); linf > 1e-14 {
+					t.Errorf("dt=%g: L2 %.3g, L∞ %.3g; want round-off", dt, l2, linf)
+				}
+			}
+		})
+	}
+	for _, nx := range []int{16, 500} {
+		dx := 1.0 / float64(nx)
+		dt := 0.4 * dx * dx
+		nt := int(math.Round(tmax / dt))
+		u := SolveFTCS(nx, nt, dx, dt)
+		if _, linf := mathutils.LevelErrors(u[nt], dx, float64(nt)*dt, exact); linf < 1e-8 {
+			t.Errorf("FTCS at nx=%d: L∞ %.3g, expected far above round-off", nx, linf)
+		}
+	}
+}
+
+// Мода k затухает точно как у уравнения: exp(−k²π²t)
+func TestSpectralModes(t *testing.T) {
+	const nx, nt, dt = 16, 5, 0.002
+	dx := 1.0 / nx
+	dst, err := mathutils.NewSineTransform(nx, nx-1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a0 := make([]float64, nx-1)
+	for k := range a0 {
+		a0[k] = math.Pow(-0.5, float64(k))
+	}
+	u0 := make([]float64, nx+1)
+	dst.Inverse(u0, a0)
+	u := SolveSpectralFrom(u0, nt, dx, dt)
+	a := make([]float64, nx-1)
+	dst.Transform(a, u[nt])
+	for k := range a {
+		w := float64(k+1) * math.Pi
+		if want := a0[k] * math.Exp(-w*w*nt*dt); math.Abs(a[k]-want) > 1e-14 {
+			t.Errorf("mode %d: a = %v, want %v", k+1, a[k], want)
+		}
+	}
+	last, err := StreamSpectralFrom(u0, nt, dx, dt, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range last {
+		if last[i] != u[nt][i] {
+			t.Fatalf("streamed u[%d] = %v, full run %v", i, last[i], u[nt][i])
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package solver
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+
+	"heat-solver/internal/mathutils"
+)
+
+// Двумерная схема на квадрате с хранением двух слоёв, как
+// Stream2DFTCSFrom: u0 и слои — строки из (nx+1)·(ny+1) значений
+type Stream2DFunc func(u0 []float64, nx, ny, nt int, dx, dy, dt, alpha float64, emit EmitFunc) ([]float64, error)
+
+// Двумерные схемы в порядке перечисления. Реестр Method одномерный, поэтому
+// у них свой список; проверочные случаи идут в Verify после методов реестра
+var methods2D = []struct {
+	name         string
+	stream       Stream2DFunc
+	verification []VerifyCase
+}{
+	{"FTCS", Stream2DFTCSFrom, []VerifyCase{
+		squareSineCase(Stream2DFTCSFrom, 16, 1.0/2048, 0.05, 5e-4),
+	}},
+	{"ADI", Stream2DADIFrom, []VerifyCase{
+		// r = 5 на всех сетках: dt уменьшается вдвое вместе с dx
+		squareSineCase(Stream2DADIFrom, 16, 5.0/256, 0.1953125, 1e-5),
+		squareOrderCase(Stream2DADIFrom, 16, 5.0/256, 0.1953125, 2, 0.15),
+		squareReferenceCase(Stream2DADIFrom, 32, 5.0/1024, 0.1953125, 128, 400, 1e-4),
+		squareTimeOrderCase(Stream2DADIFrom, 16, 0.01, 0.2, 2, 0.1),
+	}},
+	{"LOD", Stream2DLODFrom, []VerifyCase{
+		squareSineCase(Stream2DLODFrom, 16, 1.0/1024, 0.2, 5e-4),
+		// Ошибка расщепления и BTCS по времени — первого порядка; у ADI на
+		// тех же шагах — второго
+		squareTimeOrderCase(Stream2DLODFrom, 16, 0.01, 0.2, 1, 0.1),
+	}},
+}
+
+// Двумерная схема по имени без учёта регистра
+func Lookup2D(name string) (Stream2DFunc, bool) {
+	for _, m := range methods2D {
+		if strings.EqualFold(m.name, name) {
+			return m.stream, true
+		}
+	}
+	return nil, false
+}
+
+// Имена двумерных схем
+func Methods2D() []string {
+	names := make([]string, len(methods2D))
+	for i, m := range methods2D {
+		names[i] = m.name
+	}
+	return names
+}
+
+// Ошибка L2 на sin(πx)·sin(πy) при nx = ny = n в момент tmax не больше
+// bound
+func squareSineCase(stream Stream2DFunc, n int, dt, tmax, bound float64) VerifyCase {
+	return VerifyCase{
+		Name:     fmt.Sprintf("square sine n=%d dt=%g", n, dt),
+		Quantity: "L2 error",
+		Compare:  VerifyAtMost,
+		Expected: bound,
+		Measure: func(ctx context.Context, m Method) (float64, string, error) {
+			l2, err := squareSineError(stream, n, dt, tmax)
+			return l2, "", err
+		},
+	}
+}
+
+// Наблюдаемый порядок на sin(πx)·sin(πy) за два измельчения, в которых dx,
+// dy и dt уменьшаются вдвое вместе: для схемы второго порядка по всем
+// шагам ошибка убывает вчетверо
+func squareOrderCase(stream Stream2DFunc, n int, dt, tmax, order, tol float64) VerifyCase {
+	return VerifyCase{
+		Name:      fmt.Sprintf("square order from n=%d dt=%g", n, dt),
+		Quantity:  "observed order",
+		Compare:   VerifyNear,
+		Expected:  order,
+		Tolerance: tol,
+		Measure: func(ctx context.Context, m Method) (float64, string, error) {
+			var errs [3]float64
+			for k := range errs {
+				l2, err := squareSineError(stream, n<<k, dt/math.Exp2(float64(k)), tmax)
+				if err != nil {
+					return math.NaN(), "", err
+				}
+				errs[k] = l2
+			}
+			first, second := math.Log2(errs[0]/errs[1]), math.Log2(errs[1]/errs[2])
+			return second, fmt.Sprintf("errors %.3g, %.3g, %.3g; orders %.3f, %.3f", errs[0], errs[1], errs[2], first, second), nil
+		},
+	}
+}
+
+// Наблюдаемый порядок по dt на sin(πx)·sin(πy) при nx = ny = n за два деления
+// dt пополам. Эталон — точное решение полудискретной задачи
+// (semiDiscreteSine, по x и y одно и то же), так что в ошибке остаётся
+// только вклад интегрирования по времени, у LOD — вместе с расщеплением
+func squareTimeOrderCase(stream Stream2DFunc, n int, dt, tmax, order, tol float64) VerifyCase {
+	return VerifyCase{
+		Name:      fmt.Sprintf("square time order n=%d from dt=%g", n, dt),
+		Quantity:  "observed order in dt",
+		Compare:   VerifyNear,
+		Expected:  order,
+		Tolerance: tol,
+		Measure: func(ctx context.Context, m Method) (float64, string, error) {
+			h := 1 / float64(n)
+			exact := mathutils.Analytical2D{Alpha: semiDiscreteSine(h).Alpha}
+			var errs [3]float64
+			for k := range errs {
+				step := dt / math.Exp2(float64(k))
+				nt := int(math.Round(tmax / step))
+				last, err := stream(SineProfile2D(n, n, h, h), n, n, nt, h, h, step, 1, nil)
+				if err != nil {
+					return math.NaN(), "", err
+				}
+				errs[k], _ = mathutils.LevelErrors2D(last, n, n, h, h, float64(nt)*step, exact)
+			}
+			first, second := math.Log2(errs[0]/errs[1]), math.Log2(errs[1]/errs[2])
+			return second, fmt.Sprintf("errors %.3g, %.3g, %.3g; orders %.3f, %.3f", errs[0], errs[1], errs[2], first, second), nil
+		},
+	}
+}
+
+// Отличие L2 от эталона на мелкой сетке fine×fine с fineSteps шагами до
+// tmax (fine кратно n) в общих узлах. Начальный профиль не собственный
+// вектор схемы (squarePolyProfile), так что работают все моды и края
+// промежуточного слоя
+func squareReferenceCase(stream Stream2DFunc, n int, dt, tmax float64, fine, fineSteps int, bound float64) VerifyCase {
+	return VerifyCase{
+		Name:     fmt.Sprintf("square reference n=%d dt=%g", n, dt),
+		Quantity: "L2 difference",
+		Compare:  VerifyAtMost,
+		Expected: bound,
+		Measure: func(ctx context.Context, m Method) (float64, string, error) {
+			h, hf := 1/float64(n), 1/float64(fine)
+			last, err := stream(squarePolyProfile(n), n, n, int(math.Round(tmax/dt)), h, h, dt, 1, nil)
+			if err != nil {
+				return math.NaN(), "", err
+			}
+			ref, err := stream(squarePolyProfile(fine), fine, fine, fineSteps, hf, hf, tmax/float64(fineSteps), 1, nil)
+			if err != nil {
+				return math.NaN(), "", err
+			}
+			s := fine / n
+			var sum, linf float64
+			for j := 0; j <= n; j++ {
+				for i := 0; i <= n; i++ {
+					d := math.Abs(last[j*(n+1)+i] - ref[j*s*(fine+1)+i*s])
+					sum += d * d
+					linf = max(linf, d)
+				}
+			}
+			return math.Sqrt(sum / float64(len(last))), fmt.Sprintf("reference %d×%d, %d steps; L∞ %.3g", fine, fine, fineSteps, linf), nil
+		},
+	}
+}
+
+func squareSineError(stream Stream2DFunc, n int, dt, tmax float64) (float64, error) {
+	h := 1 / float64(n)
+	nt := int(math.Round(tmax / dt))
+	last, err := stream(SineProfile2D(n, n, h, h), n, n, nt, h, h, dt, 1, nil)
+	if err != nil {
+		return math.NaN(), err
+	}
+	l2, _ := mathutils.LevelErrors2D(last, n, n, h, h, float64(nt)*dt, mathutils.Analytical2D{Alpha: 1})
+	return l2, nil
+}
+
+// 16x(1−x)·y(1−y)·(1 + x + 2y²) на сетке n×n: ноль на краях, без
+// симметрий квадрата
+func squarePolyProfile(n int) []float64 {
+	u0 := make([]float64, (n+1)*(n+1))
+	for j := 0; j <= n; j++ {
+		y := float64(j) / float64(n)
+		for i := 0; i <= n; i++ {
+			x := float64(i) / float64(n)
+			u0[j*(n+1)+i] = 16 * x * (1 - x) * y * (1 - y) * (1 + x + 2*y*y)
+		}
+	}
+	return u0
+}
@@ -0,0 +1,69 @@
+package solver
+
+import (
+	"fmt"
+	"math"
+)
+
+// Множитель перехода G(θ) фон Неймана: гармоника exp(ijθ) за шаг
+// умножается на G, θ = kπ·dx — волновое число моды, r = α·dt/dx²
+type AmplificationFunc func(r, theta float64) float64
+
+// G(θ) схемы с весом w неявной части (0 — FTCS, 1/2 — CN, 1 — BTCS):
+// (1 − 4(1−w)·r·sin²(θ/2)) / (1 + 4w·r·sin²(θ/2))
+func WeightedAmplification(w float64) AmplificationFunc {
+	return func(r, theta float64) float64 {
+		s := math.Sin(theta / 2)
+		q := 4 * r * s * s
+		return (1 - (1-w)*q) / (1 + w*q)
+	}
+}
+
+// |G| ≤ 1 с этим допуском считается устойчивостью
+const stabilityTolerance = 1e-12
+
+// Значение G в одной точке θ
+type AmplificationPoint struct {
+	Theta float64
+	G     float64
+}
+
+// Анализ фон Неймана схемы при данном r на точках θ_j = jπ/n, j = 1..n.
+// MaxTheta — самая усиливаемая мода (наибольший |G|). NegativeFrom —
+// наименьшее θ с G < 0 (моды выше осциллируют от шага к шагу), 0 — G ≥ 0
+// везде
+type StabilityAnalysis struct {
+	Method       string
+	R            float64
+	Points       []AmplificationPoint
+	MaxAbsG      float64
+	MaxTheta     float64
+	Stable       bool
+	NegativeFrom float64
+}
+
+// G(θ) метода m на n точках (0,π]; ошибка — для метода без Amplification
+func AnalyzeStability(m Method, r float64, n int) (StabilityAnalysis, error) {
+	if m.Amplification == nil {
+		return StabilityAnalysis{}, fmt.Errorf("method %s has no amplification factor", m.Name)
+	}
+	if n < 1 {
+		return StabilityAnalysis{}, fmt.Errorf("need at least one wavenumber, got %d", n)
+	}
+	a := StabilityAnalysis{Method: m.Name, R: r, Points: make([]AmplificationPoint, n), Stable: true}
+	for j := range n {
+		theta := float64(j+1) * math.Pi / float64(n)
+		g := m.Amplification(r, theta)
+		a.Points[j] = AmplificationPoint{Theta: theta, G: g}
+		if abs := math.Abs(g); abs > a.MaxAbsG {
+			a.MaxAbsG, a.MaxTheta = abs, theta
+		}
+		if math.Abs(g) > 1+stabilityTolerance {
+			a.Stable = false
+		}
+		if g < 0 && a.NegativeFrom == 0 {
+			a.NegativeFrom = theta
+		}
+	}
+	return a, nil
+}
@@ -0,0 +1,105 @@
+package solver
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func mustLookup(t testing.TB, name string) Method {
+	t.Helper()
+	m, ok := Lookup(name)
+	if !ok {
+		t.Fatalf("method %s is not registered", name)
+	}
+	return m
+}
+
+// Граница FTCS r = 1/2, безусловная устойчивость BTCS и CN. G < 0 выше
+// θ* = 2·arcsin(1/(2√r)) у FTCS и 2·arcsin(1/√(2r)) у CN
+func TestAnalyzeStability(t *testing.T) {
+	// Нечётное n: θ* = π/2 у FTCS при r = 1/2 не попадает в точку сетки
+	const n = 201
+	// Первая точка θ_j = jπ/n выше θ*
+	negativeFrom := func(sin float64) float64 {
+		return math.Floor(2*math.Asin(sin)/(math.Pi/n)+1) * math.Pi / n
+	}
+	low := math.Pow(math.Sin(math.Pi/2/n), 2)
+	tests := []struct {
+		method   string
+		r        float64
+		stable   bool
+		maxAbsG  float64
+		maxTheta float64
+		negative float64
+	}{
+		{"FTCS", 0.25, true, 1 - low, math.Pi / n, 0},
+		{"FTCS", 0.5, true, 1, math.Pi, negativeFrom(1 / math.Sqrt(2))},
+		{"FTCS", 0.5 + 1e-9, false, 1 + 4e-9, math.Pi, negativeFrom(1 / math.Sqrt(2))},
+		{"FTCS", 1, false, 3, math.Pi, negativeFrom(0.5)},
+		{"BTCS", 10, true, 1 / (1 + 40*low), math.Pi / n, 0},
+		{"CN", 10, true, (1 - 20*low) / (1 + 20*low), math.Pi / n, negativeFrom(1 / math.Sqrt(20))},
+		{"CN", 0.4, true, 0, 0, 0},
+	}
+	for _, tc := range tests {
+		t.Run(fmt.Sprintf("%s/r=%g", tc.method, tc.r), func(t *testing.T) {
+			a, err := AnalyzeStability(mustLookup(t, tc.method), tc.r, n)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(a.Points) != n || a.Points[n-1].Theta != math.Pi {
+				t.Fatalf("%d points up to %v, want %d up to π", len(a.Points), a.Points[len(a.Points)-1].Theta, n)
+			}
+			if a.Stable != tc.stable {
+				t.Errorf("stable %v with max|G| %v, want %v", a.Stable, a.MaxAbsG, tc.stable)
+			}
+			if tc.maxTheta != 0 && (math.Abs(a.MaxAbsG-tc.maxAbsG) > 1e-12 || math.Abs(a.MaxTheta-tc.maxTheta) > 1e-12) {
+				t.Errorf("max|G| %v at θ = %v, want %v at %v", a.MaxAbsG, a.MaxTheta, tc.maxAbsG, tc.maxTheta)
+			}
+			if math.Abs(a.NegativeFrom-tc.negative) > 1e-12 {
+				t.Errorf("G < 0 from θ = %v, want %v", a.NegativeFrom, tc.negative)
+			}
+			for _, p := range a.Points {
+				if tc.method == "BTCS" && !(p.G > 0 && p.G < 1) {
+					t.Fatalf("BTCS G(%v) = %v, want inside (0, 1)", p.Theta, p.G)
+				}
+			}
+		})
+	}
+
+	if _, err := AnalyzeStability(Method{Name: "NONE"}, 1, n); err == nil {
+		t.Error("method without an amplification factor accepted")
+	}
+	if _, err := AnalyzeStability(mustLookup(t, "FTCS"), 1, 0); err == nil {
+		t.Error("zero wavenumbers accepted")
+	}
+}
+
+// G(θ) совпадает с тем, во сколько раз шаг схемы умножает моду сетки
+// sin(kπx): моды — собственные векторы шага при нулевых краях
+func TestAmplificationMatchesStep(t *testing.T) {
+	const nx = 16
+	dx := 1.0 / nx
+	theta, err := ThetaMethod(0.3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	methods := []Method{mustLookup(t, "FTCS"), mustLookup(t, "BTCS"), mustLookup(t, "CN"), theta}
+	for _, m := range methods {
+		for _, r := range []float64{0.3, 0.5, 4} {
+			for k := 1; k < nx; k++ {
+				u0 := make([]float64, nx+1)
+				for i := 1; i < nx; i++ {
+					u0[i] = math.Sin(float64(k) * math.Pi * float64(i) * dx)
+				}
+				u := m.Solve(u0, 1, dx, r*dx*dx)
+				g := m.Amplification(r, float64(k)*math.Pi*dx)
+				for i := 1; i < nx; i++ {
+					if math.Abs(u[1][i]-g*u0[i]) > 1e-12 {
+						t.Fatalf("%s r=%g mode %d: node %d is %v, want G·u0 = %v", m.Name, r, k, i, u[1][i], g*u0[i])
+					}
+				}
+			}
+		}
+	}
+}
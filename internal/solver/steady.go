@@ -0,0 +1,97 @@
+package solver
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+
+	"heat-solver/internal/mathutils"
+)
+
+// Стационарное решение −α·u″ = f(x) на [0, 1] с u(0) = left, u(1) = right
+// без расчёта по времени: центральная разность даёт систему
+// (−1, 2, −1)·u = dx²·f/α по внутренним узлам, краевые значения переходят
+// в правую часть. Система собирается один раз и решается прогонкой.
+// Второй порядок по dx; для постоянной f решение — парабола, и схема
+// точна до округления
+func SolveSteady(nx int, dx, alpha float64, f func(x float64) float64, left, right float64) []float64 {
+	slog.Info("Starting steady-state solver", "nx", nx, "dx", dx, "alpha", alpha, "left", left, "right", right)
+
+	n := nx - 1
+	a, b, c, d, ws := workspace(n)
+	defer pool64.put(ws)
+	h2 := dx * dx / alpha
+	for i := range n {
+		a[i], b[i], c[i] = -1, 2, -1
+		d[i] = h2 * f(float64(i+1)*dx)
+	}
+	d[0] += left
+	d[n-1] += right
+
+	u := make([]float64, nx+1)
+	u[0], u[nx] = left, right
+	copy(u[1:nx], thomasAlgorithm(a, b, c, d))
+
+	slog.Info("Steady-state solver finished successfully")
+	return u
+}
+
+// Проверочные случаи стационарного решателя; в Verify — под именем STEADY
+var verificationSteady = []VerifyCase{
+	steadyParabolaCase(0.05, 1e-12),
+	steadyOrderCase(0.05, 2, 0.1),
+}
+
+// Постоянная f: точное решение — парабола, и трёхточечная разность на
+// ней точна, так что ошибка — только округление
+func steadyParabolaCase(dx, bound float64) VerifyCase {
+	const (
+		alpha       = 0.5
+		f           = 3.0
+		left, right = 1.0, -2.0
+	)
+	return VerifyCase{
+		Name:     fmt.Sprintf("parabola f=%g dx=%g", f, dx),
+		Quantity: "max error",
+		Compare:  VerifyAtMost,
+		Expected: bound,
+		Measure: func(ctx context.Context, m Method) (float64, string, error) {
+			nx := int(math.Round(1 / dx))
+			u := SolveSteady(nx, dx, alpha, func(float64) float64 { return f }, left, right)
+			exact := mathutils.SteadyParabola(alpha, f, left, right)
+			var e float64
+			for i, v := range u {
+				e = max(e, math.Abs(v-exact(float64(i)*dx)))
+			}
+			return e, fmt.Sprintf("peak %.6g", exact(0.5)), nil
+		},
+	}
+}
+
+// Наблюдаемый порядок по dx за два деления пополам на гладком решении
+// u = sin(πx) + 1 + x (f = π²·sin(πx), α = 1), где схема уже не точна
+func steadyOrderCase(dx, order, tol float64) VerifyCase {
+	return VerifyCase{
+		Name:      fmt.Sprintf("sine order from dx=%g", dx),
+		Quantity:  "observed order",
+		Compare:   VerifyNear,
+		Expected:  order,
+		Tolerance: tol,
+		Measure: func(ctx context.Context, m Method) (float64, string, error) {
+			f := func(x float64) float64 { return math.Pi * math.Pi * math.Sin(math.Pi*x) }
+			var errs [3]float64
+			for k := range errs {
+				h := dx / math.Exp2(float64(k))
+				nx := int(math.Round(1 / h))
+				u := SolveSteady(nx, h, 1, f, 1, 2)
+				for i, v := range u {
+					x := float64(i) * h
+					errs[k] = max(errs[k], math.Abs(v-(math.Sin(math.Pi*x)+1+x)))
+				}
+			}
+			first, second := math.Log2(errs[0]/errs[1]), math.Log2(errs[1]/errs[2])
+			return second, fmt.Sprintf("errors %.3g, %.3g, %.3g; orders %.3f, %.3f", errs[0], errs[1], errs[2], first, second), nil
+		},
+	}
+}
@@ -0,0 +1,61 @@
+package solver
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"heat-solver/internal/mathutils"
+)
+
+// Постоянная f: решение — парабола, на которой трёхточечная разность
+// точна, так что ошибка — только округление при любом nx
+func TestSolveSteadyParabola(t *testing.T) {
+	tests := []struct {
+		nx          int
+		alpha, f    float64
+		left, right float64
+	}{
+		{2, 1, 8, 0, 0},
+		{10, 0.5, 3, 1, -2},
+		{100, 2, -5, 3, 3},
+		{1000, 1, 0, -1, 4},
+	}
+	for _, tc := range tests {
+		t.Run(fmt.Sprintf("nx=%d/f=%g", tc.nx, tc.f), func(t *testing.T) {
+			dx := 1 / float64(tc.nx)
+			u := SolveSteady(tc.nx, dx, tc.alpha, func(float64) float64 { return tc.f }, tc.left, tc.right)
+			exact := mathutils.SteadyParabola(tc.alpha, tc.f, tc.left, tc.right)
+			if len(u) != tc.nx+1 || u[0] != tc.left || u[tc.nx] != tc.right {
+				t.Fatalf("%d nodes with edges %v, %v", len(u), u[0], u[len(u)-1])
+			}
+			for i, v := range u {
+				if e := math.Abs(v - exact(float64(i)*dx)); e > 1e-9 {
+					t.Fatalf("node %d: %v, exact %v", i, v, exact(float64(i)*dx))
+				}
+			}
+		})
+	}
+}
+
+// На u = sin(πx) + 1 + x (f = π²·sin(πx), α = 1) ошибка L∞ падает вчетверо
+// при каждом делении dx пополам
+func TestSolveSteadyOrder(t *testing.T) {
+	f := func(x float64) float64 { return math.Pi * math.Pi * math.Sin(math.Pi*x) }
+	var prev float64
+	for k, nx := range []int{20, 40, 80, 160} {
+		dx := 1 / float64(nx)
+		u := SolveSteady(nx, dx, 1, f, 1, 2)
+		var linf float64
+		for i, v := range u {
+			x := float64(i) * dx
+			linf = max(linf, math.Abs(v-(math.Sin(math.Pi*x)+1+x)))
+		}
+		if k > 0 {
+			if order := math.Log2(prev / linf); math.Abs(order-2) > 0.05 {
+				t.Errorf("nx=%d: error %.3g → %.3g, observed order %.2f", nx, prev, linf, order)
+			}
+		}
+		prev = linf
+	}
+}
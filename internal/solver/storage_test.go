@@ -0,0 +1,116 @@
+package solver
+
+import (
+	"context"
+	"math"
+	"slices"
+	"testing"
+
+	"heat-solver/internal/config"
+)
+
+// В режиме final-only хранятся начальный и последний слои, и последний
+// побитно совпадает с полным хранением — у всех схем, в том числе
+// трёхслойных
+func TestFinalOnlyMatchesFull(t *testing.T) {
+	for _, name := range Methods() {
+		t.Run(name, func(t *testing.T) {
+			p := config.Params{Method: name, Dx: 0.05, Dt: 0.001, Tmax: 0.05}.Normalize()
+			full, err := Run(context.Background(), p, Hooks{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer full.Release()
+			p.Storage = config.StorageFinalOnly
+			final, err := Run(context.Background(), p, Hooks{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer final.Release()
+
+			if final.Storage != config.StorageFinalOnly || final.Levels() != 2 || final.LevelStep() != full.Nt {
+				t.Fatalf("storage %s with %d levels %d steps apart, want final-only with 2 levels %d apart",
+					final.Storage, final.Levels(), final.LevelStep(), full.Nt)
+			}
+			if !slices.Equal(final.Level(0), full.Level(0)) {
+				t.Error("initial level differs from the full run")
+			}
+			if !slices.Equal(final.Level(1), full.Level(full.Nt)) || !slices.Equal(final.Final, full.Final) {
+				t.Error("final level differs from the full run")
+			}
+		})
+	}
+}
+
+// История во float32 отличается от расчёта во float64 только округлением
+// до одинарной точности, а Final и нормы считаются по рабочим float64
+func TestFloat32Storage(t *testing.T) {
+	for _, name := range Methods() {
+		t.Run(name, func(t *testing.T) {
+			p := config.Params{Method: name, Dx: 0.05, Dt: 0.001, Tmax: 0.05}.Normalize()
+			full, err := Run(context.Background(), p, Hooks{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer full.Release()
+			p.Precision = config.PrecisionFloat32
+			single, err := Run(context.Background(), p, Hooks{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer single.Release()
+
+			if single.Precision != config.PrecisionFloat32 || single.U != nil || single.Levels() != full.Levels() {
+				t.Fatalf("precision %s with %d levels (U set: %v), want float32 with %d levels in U32",
+					single.Precision, single.Levels(), single.U != nil, full.Levels())
+			}
+			for k := range full.Levels() {
+				want := full.Level(k)
+				for i, v := range single.Level(k) {
+					if math.Abs(v-want[i]) > 0x1p-24*math.Abs(want[i]) {
+						t.Fatalf("u[%d][%d] = %v, float64 run %v: more than single-precision rounding", k, i, v, want[i])
+					}
+				}
+			}
+			if !slices.Equal(single.Final, full.Final) {
+				t.Error("Final differs from the float64 run")
+			}
+		})
+	}
+}
+
+// Память под историю на большой сетке: float32 — примерно половина
+func TestFloat32StorageBytes(t *testing.T) {
+	sizes := map[string]int64{}
+	for _, precision := range []string{config.PrecisionFloat64, config.PrecisionFloat32} {
+		p := config.Params{Method: "CN", Dx: 0.001, Dt: 0.0001, Tmax: 0.1, Precision: precision}.Normalize()
+		res, err := Run(context.Background(), p, Hooks{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		sizes[precision] = res.Bytes()
+		res.Release()
+	}
+	if ratio := float64(sizes[config.PrecisionFloat32]) / float64(sizes[config.PrecisionFloat64]); ratio < 0.45 || ratio > 0.55 {
+		t.Errorf("float32 history takes %.0f%% of float64, want about half", 100*ratio)
+	}
+}
+
+// Расчёт с историей во float64 и float32 и её размер
+func BenchmarkStoragePrecision(b *testing.B) {
+	for _, precision := range []string{config.PrecisionFloat64, config.PrecisionFloat32} {
+		b.Run(precision, func(b *testing.B) {
+			p := config.Params{Method: "CN", Dx: 0.001, Dt: 0.0001, Tmax: 0.1, Precision: precision}.Normalize()
+			var bytes int64
+			for b.Loop() {
+				res, err := Run(context.Background(), p, Hooks{})
+				if err != nil {
+					b.Fatal(err)
+				}
+				bytes = res.Bytes()
+				res.Release()
+			}
+			b.ReportMetric(float64(bytes)/(1<<20), "MiB/result")
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package solver
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"heat-solver/internal/config"
+)
+
+// Шаги сетки для заданной ошибки L2 в момент tmax по модели
+// E = Cx·dx^p + Ct·dt^q, откалиброванной SplitError
+type Suggestion struct {
+	Method string
+	Target float64
+	Dx, Dt float64
+	Nx, Nt int
+	// Коэффициенты модели и порядки метода
+	Cx, Ct                float64
+	OrderSpace, OrderTime int
+	// Ошибка по модели при предложенных dx и dt с учётом знаков вкладов
+	// и её гарантированная оценка сверху: сумма вкладов, а для вкладов
+	// разного знака — больший из них
+	Predicted, Bound float64
+	// Память под решение: все слои (full) и два рабочих слоя (final-only)
+	FullBytes, FinalOnlyBytes int64
+	// Оценка времени расчёта по пробным suggestProbeSteps шагам
+	Runtime time.Duration
+	// Калибровка на сетке p.Dx, p.Dt
+	Calibration ErrorSplit
+}
+
+// Шагов пробного расчёта для оценки времени
+const suggestProbeSteps = 64
+
+// Подбор dx и dt для ошибки target. Калибровка — SplitError на грубой
+// сетке p.Dx, p.Dt (четыре коротких расчёта). По умолчанию допуск делится
+// между частями так, чтобы число узлов nx·nt было наименьшим: на dx
+// приходится q/(p+q), на dt — p/(p+q). balance делит его поровну.
+// Вклады разного знака (ErrorSplit.Opposite) друг друга не усиливают,
+// и каждому достаётся весь допуск.
+// Шаг, вклад которого при калибровке не виден, не крупнее калибровочного;
+// у FTCS dt дополнительно ограничен условием устойчивости
+func Suggest(ctx context.Context, p config.Params, target float64, balance bool) (Suggestion, error) {
+	if !(target > 0) {
+		return Suggestion{}, fmt.Errorf("target error must be positive, got %g", target)
+	}
+	cal, err := SplitError(ctx, p)
+	if err != nil {
+		return Suggestion{}, fmt.Errorf("calibration: %w", err)
+	}
+	m, _ := Lookup(cal.Method)
+
+	ps, qt := float64(cal.OrderSpace), float64(cal.OrderTime)
+	s := Suggestion{
+		Method:      cal.Method,
+		Target:      target,
+		Cx:          cal.Spatial / math.Pow(cal.Dx, ps),
+		Ct:          cal.Temporal / math.Pow(cal.Dt, qt),
+		OrderSpace:  cal.OrderSpace,
+		OrderTime:   cal.OrderTime,
+		Calibration: cal,
+	}
+	spaceShare := qt / (ps + qt)
+	if balance {
+		spaceShare = 0.5
+	}
+	timeShare := 1 - spaceShare
+	if cal.Opposite {
+		spaceShare, timeShare = 1, 1
+	}
+	dx, dt := cal.Dx, cal.Dt
+	if s.Cx > 0 {
+		dx = min(dx, math.Pow(spaceShare*target/s.Cx, 1/ps))
+	}
+	if s.Ct > 0 {
+		dt = min(dt, math.Pow(timeShare*target/s.Ct, 1/qt))
+	}
+	if m.MaxR > 0 {
+		dt = min(dt, m.MaxR*dx*dx)
+	}
+
+	// Целые nx и nt: шаги только уменьшаются
+	s.Nx = int(math.Ceil(1/dx - 1e-9))
+	s.Nt = int(math.Ceil(p.Tmax/dt - 1e-9))
+	s.Dx, s.Dt = 1/float64(s.Nx), p.Tmax/float64(s.Nt)
+	if m.MaxR > 0 && s.Dt > m.MaxR*s.Dx*s.Dx {
+		s.Nt = int(math.Ceil(p.Tmax / (m.MaxR * s.Dx * s.Dx)))
+		s.Dt = p.Tmax / float64(s.Nt)
+	}
+	spatial, temporal := s.Cx*math.Pow(s.Dx, ps), s.Ct*math.Pow(s.Dt, qt)
+	s.Predicted, s.Bound = spatial+temporal, spatial+temporal
+	if cal.Opposite {
+		s.Predicted, s.Bound = math.Abs(spatial-temporal), max(spatial, temporal)
+	}
+
+	row := int64(s.Nx+1) * 8
+	s.FullBytes = int64(s.Nt+1) * row
+	s.FinalOnlyBytes = 2 * row
+	// Скорость меряется коротким расчётом на предложенной сетке: на
+	// калибровочных сетках время уходит в основном на накладные расходы
+	probe := p
+	probe.Dx, probe.Dt = s.Dx, s.Dt
+	probeSteps := min(s.Nt, suggestProbeSteps)
+	probe.Tmax = float64(probeSteps) * s.Dt
+	probe.Storage, probe.Precision, probe.ICSamples = config.StorageFinalOnly, config.PrecisionFloat64, nil
+	res, err := Run(ctx, probe, Hooks{})
+	if err != nil {
+		return Suggestion{}, fmt.Errorf("runtime probe: %w", err)
+	}
+	s.Runtime = res.Runtime * time.Duration(s.Nt) / time.Duration(probeSteps)
+	res.Release()
+	return s, nil
+}
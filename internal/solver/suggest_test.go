@@ -0,0 +1,59 @@
+package solver
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"heat-solver/internal/config"
+)
+
+// Расчёт на предложенной сетке попадает в допуск с запасом не хуже 2×.
+// Впустую точнее в десятки раз он может быть, только если dt ограничен
+// устойчивостью (FTCS) или вклады гасят друг друга (у CN на sin(πx)):
+// тогда допуск целиком отдаётся каждому вкладу, и ошибка много меньше
+// оценки сверху Bound
+func TestSuggest(t *testing.T) {
+	tests := []struct {
+		method  string
+		dx, dt  float64
+		target  float64
+		balance bool
+	}{
+		{"CN", 0.1, 0.01, 1e-4, false},
+		{"CN", 0.1, 0.01, 1e-6, false},
+		{"CN", 0.1, 0.01, 1e-5, true},
+		{"BTCS", 0.1, 0.01, 1e-4, false},
+		{"BTCS", 0.1, 0.01, 1e-5, true},
+		{"FTCS", 0.1, 0.001, 1e-5, false},
+	}
+	for _, tc := range tests {
+		t.Run(fmt.Sprintf("%s/%g/balance=%v", tc.method, tc.target, tc.balance), func(t *testing.T) {
+			p := config.Params{Method: tc.method, Dx: tc.dx, Dt: tc.dt, Tmax: 0.5}
+			s, err := Suggest(context.Background(), p, tc.target, tc.balance)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s.Dx != 1/float64(s.Nx) || s.FullBytes != int64(s.Nt+1)*int64(s.Nx+1)*8 || s.Runtime <= 0 {
+				t.Fatalf("dx %g for nx %d, %d bytes for nt %d, runtime %v", s.Dx, s.Nx, s.FullBytes, s.Nt, s.Runtime)
+			}
+			m := mustLookup(t, tc.method)
+			if m.MaxR > 0 && s.Dt > m.MaxR*s.Dx*s.Dx {
+				t.Fatalf("dt %g breaks the stability limit at dx %g", s.Dt, s.Dx)
+			}
+			if s.Bound > tc.target*(1+1e-9) || s.Predicted > s.Bound {
+				t.Fatalf("model error %.3g with bound %.3g, want within the target %g", s.Predicted, s.Bound, tc.target)
+			}
+			p.Dx, p.Dt = s.Dx, s.Dt
+			got := runError(t, p)
+			wasteful := m.MaxR == 0 && !s.Calibration.Opposite && got < tc.target/10
+			if got > 2*tc.target || wasteful {
+				t.Errorf("error %.3g at dx=%g dt=%g (nx %d, nt %d), predicted %.3g; target %g",
+					got, s.Dx, s.Dt, s.Nx, s.Nt, s.Predicted, tc.target)
+			}
+		})
+	}
+	if _, err := Suggest(context.Background(), config.Params{Method: "CN", Dx: 0.1, Dt: 0.01, Tmax: 0.5}, 0, false); err == nil {
+		t.Error("zero target accepted")
+	}
+}
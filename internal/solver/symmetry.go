@@ -0,0 +1,107 @@
+package solver
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"sync/atomic"
+)
+
+// Допуск асимметрии относительно max|u0|: отклонения на уровне округления
+// ошибкой не считаются
+const symmetryTolerance = 1e-12
+
+var symmetryCheck atomic.Bool
+
+// Проверка симметрии относительно x = 1/2 в расчётах, начатых после
+// вызова. По умолчанию выключена: ещё один проход по каждому слою
+func SetSymmetryCheck(on bool) {
+	symmetryCheck.Store(on)
+}
+
+func SymmetryCheck() bool {
+	return symmetryCheck.Load()
+}
+
+// Итог проверки симметрии. Checked — проверка велась: она включена и
+// начальный профиль симметричен (границы у всех схем нулевые, так что
+// симметрия задачи определяется профилем). Max — наибольшая
+// |u[n][i] − u[n][nx−i]| по слоям, Step и Node — где она достигнута
+type Symmetry struct {
+	Checked   bool
+	Initial   float64
+	Max       float64
+	Step      int
+	Node      int
+	Tolerance float64
+}
+
+// Асимметрия выше допуска: ошибка индексации или сборки правой части
+func (s Symmetry) Broken() bool {
+	return s.Checked && s.Max > s.Tolerance
+}
+
+// Проверка слоёв на симметрию u_i = u_{nx−i}. Методы nil-монитора ничего
+// не делают: так в расчёте выглядит выключенная проверка
+type SymmetryMonitor struct {
+	report Symmetry
+}
+
+// Симметричность задачи определяется по u0 с тем же допуском; для
+// несимметричного профиля Check ничего не делает
+func NewSymmetryMonitor(u0 []float64) *SymmetryMonitor {
+	var scale float64
+	for _, v := range u0 {
+		scale = max(scale, math.Abs(v))
+	}
+	initial, _ := Asymmetry(u0)
+	tol := symmetryTolerance * scale
+	return &SymmetryMonitor{report: Symmetry{Checked: initial <= tol, Initial: initial, Tolerance: tol}}
+}
+
+// Монитор для расчёта или nil, если проверка выключена
+func symmetryMonitor(u0 []float64) *SymmetryMonitor {
+	if !SymmetryCheck() {
+		return nil
+	}
+	return NewSymmetryMonitor(u0)
+}
+
+// Проверка слоя n
+func (m *SymmetryMonitor) Check(n int, row []float64) {
+	if m == nil || !m.report.Checked {
+		return
+	}
+	if d, i := Asymmetry(row); d > m.report.Max {
+		m.report.Max, m.report.Step, m.report.Node = d, n, i
+	}
+}
+
+func (m *SymmetryMonitor) Report() Symmetry {
+	if m == nil {
+		return Symmetry{}
+	}
+	return m.report
+}
+
+func warnSymmetry(ctx context.Context, method string, s Symmetry) {
+	if !s.Broken() {
+		return
+	}
+	slog.WarnContext(ctx, "Symmetry broken", "method", method, "asymmetry", s.Max,
+		"tolerance", s.Tolerance, "step", s.Step, "node", s.Node)
+}
+
+// max_i |row[i] − row[len−1−i]| и узел i < len/2, где он достигнут. NaN
+// пропускаются
+func Asymmetry(row []float64) (float64, int) {
+	var worst float64
+	node := 0
+	last := len(row) - 1
+	for i := range len(row) / 2 {
+		if d := math.Abs(row[i] - row[last-i]); d > worst {
+			worst, node = d, i
+		}
+	}
+	return worst, node
+}
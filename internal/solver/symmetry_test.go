@@ -0,0 +1,126 @@
+package solver
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"heat-solver/internal/config"
+)
+
+// Проверка симметрии на время теста
+func withSymmetryCheck(t testing.TB, on bool) {
+	t.Helper()
+	prev := SymmetryCheck()
+	SetSymmetryCheck(on)
+	t.Cleanup(func() { SetSymmetryCheck(prev) })
+}
+
+func TestAsymmetry(t *testing.T) {
+	tests := []struct {
+		name  string
+		row   []float64
+		worst float64
+		node  int
+	}{
+		{"empty", nil, 0, 0},
+		{"single node", []float64{3}, 0, 0},
+		{"symmetric odd", []float64{0, 1, 2, 1, 0}, 0, 0},
+		{"symmetric even", []float64{0, 1, 1, 0}, 0, 0},
+		{"worst inside", []float64{0, 1, 0.5, 1.25, 1, 0}, 0.75, 2},
+		{"NaN skipped", []float64{0, math.NaN(), 2, 1, 0.5}, 0.5, 0},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if worst, node := Asymmetry(tc.row); worst != tc.worst || node != tc.node {
+				t.Errorf("asymmetry %v at node %d, want %v at %d", worst, node, tc.worst, tc.node)
+			}
+		})
+	}
+}
+
+// Схемы на sin(πx) остаются симметричными до округления во всех режимах
+// хранения; несимметричный профиль не проверяется
+func TestSymmetryRun(t *testing.T) {
+	withSymmetryCheck(t, true)
+	for _, method := range []string{"FTCS", "BTCS", "CN"} {
+		for _, storage := range []string{config.StorageFull, config.StorageFinalOnly, "checkpoint"} {
+			t.Run(method+"/"+storage, func(t *testing.T) {
+				p := config.Params{Method: method, Dx: 0.01, Dt: 4e-5, Tmax: 0.05}.Normalize()
+				var res *Result
+				var err error
+				if storage == "checkpoint" {
+					res, err = Checkpointed(context.Background(), p, 16, Hooks{})
+				} else {
+					p.Storage = storage
+					res, err = Run(context.Background(), p, Hooks{})
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+				defer res.Release()
+				s := res.Symmetry
+				if !s.Checked || s.Broken() || s.Max > 1e-14 || s.Tolerance != symmetryTolerance {
+					t.Errorf("symmetry %+v, want checked and within round-off", s)
+				}
+			})
+		}
+	}
+
+	skewed := []config.Sample{{X: 0, U: 0}, {X: 0.3, U: 1}, {X: 1, U: 0}}
+	res, err := Run(context.Background(), config.Params{Method: "CN", Dx: 0.1, Dt: 0.01, Tmax: 0.1, ICSamples: skewed}.Normalize(), Hooks{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Release()
+	if s := res.Symmetry; s.Checked || s.Broken() || s.Initial == 0 {
+		t.Errorf("skewed profile: symmetry %+v, want unchecked", s)
+	}
+
+	withSymmetryCheck(t, false)
+	res, err = Run(context.Background(), config.Params{Method: "CN", Dx: 0.1, Dt: 0.01, Tmax: 0.1}.Normalize(), Hooks{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Release()
+	if res.Symmetry != (Symmetry{}) {
+		t.Errorf("check off: symmetry %+v", res.Symmetry)
+	}
+}
+
+// Двойник BTCS с ошибкой сборки правой части: 1e-9 в узле 1 на каждом
+// шаге. Монитор ловит асимметрию в узле 1, исправный BTCS — нет
+func TestSymmetryMonitorCatchesPerturbation(t *testing.T) {
+	const nx, nt = 50, 20
+	dx := 1.0 / nx
+	stream := mustLookup(t, "BTCS").Stream
+	for _, perturb := range []float64{0, 1e-9} {
+		u := SineProfile(nx, dx)
+		m := NewSymmetryMonitor(u)
+		m.Check(0, u)
+		for n := 1; n <= nt; n++ {
+			next, err := stream(u, 1, dx, 1e-3, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			next[1] += perturb
+			m.Check(n, next)
+			u = next
+		}
+		s := m.Report()
+		if perturb == 0 {
+			if !s.Checked || s.Broken() {
+				t.Errorf("healthy BTCS: symmetry %+v", s)
+			}
+			continue
+		}
+		if !s.Broken() || s.Node != 1 || s.Max < perturb {
+			t.Errorf("perturbed BTCS: symmetry %+v, want broken at node 1", s)
+		}
+	}
+	var off *SymmetryMonitor
+	off.Check(0, []float64{0, 1, 0, 0})
+	if off.Report() != (Symmetry{}) {
+		t.Error("nil monitor reported a check")
+	}
+}
@@ -0,0 +1,326 @@
+package solver
+
+import (
+	"fmt"
+	"log/slog"
+
+	"heat-solver/internal/mathutils"
+)
+
+// θ-схема: (u^{n+1} − u^n)/dt = α·(θ·δ²u^{n+1} + (1−θ)·δ²u^n)/dx², δ² —
+// центральная разность по x, края нулевые. θ от 0 до 1: θ = 0 — FTCS,
+// θ = 1 — BTCS, θ = ½ — CN. При θ ≥ ½ схема устойчива при любом
+// r = α·dt/dx², при θ < ½ — при r ≤ 1/(2(1−2θ))
+func SolveTheta(nx, nt int, dx, dt, alpha, theta float64) [][]float64 {
+	return SolveThetaFrom(SineProfile(nx, dx), nt, dx, dt, alpha, theta)
+}
+
+// θ-схема с заданным начальным профилем u0 (nx+1 значений)
+func SolveThetaFrom(u0 []float64, nt int, dx, dt, alpha, theta float64) [][]float64 {
+	u := newGrid(nt+1, len(u0))
+	thetaScheme(u0, nt, dx, dt, alpha, theta, u, nil)
+	return u
+}
+
+// θ-схема с хранением двух слоёв; каждый слой передаётся в emit
+func StreamThetaFrom(u0 []float64, nt int, dx, dt, alpha, theta float64, emit EmitFunc) ([]float64, error) {
+	u := newGrid(2, len(u0))
+	if err := thetaScheme(u0, nt, dx, dt, alpha, theta, u, emit); err != nil {
+		return nil, err
+	}
+	return level(u, nt), nil
+}
+
+// При θ = 0 системы нет, и шаг считает ядро FTCS (векторное, с потоками).
+// Иначе на шаге решается система (−θr, 1+2θr, −θr) с правой частью
+// (1−θ)r·u_{i−1} + (1−2(1−θ)r)·u_i + (1−θ)r·u_{i+1}. Коэффициенты
+// собираются так, что при θ = 1 и θ = ½ совпадают побитно с прежними
+// отдельными BTCS и CN: умножение r на 1 и ½ точное
+func thetaScheme(u0 []float64, nt int, dx, dt, alpha, theta float64, u [][]float64, emit EmitFunc) error {
+	return thetaSchemeWith(u0, nt, dx, dt, alpha, theta, thetaOptions{}, u, emit)
+}
+
+// Варианты θ-схемы; нулевое значение — обычная схема
+type thetaOptions struct {
+	// Первые startup шагов заменяются двумя полушагами BTCS каждый (старт
+	// Раннахера). BTCS гасит высокие моды разрывных данных, которые у CN
+	// при больших r почти не затухают и меняют знак на каждом шаге; второй
+	// порядок CN по времени при этом сохраняется
+	startup int
+	// Компактная схема четвёртого порядка по x:
+	// (1 + δ²/12)·(u^{n+1} − u^n)/dt = α·δ²(θu^{n+1} + (1−θ)u^n)/dx².
+	// Матрица остаётся трёхдиагональной: (1/12 − θr, 5/6 + 2θr, 1/12 − θr)
+	compact bool
+	// Коэффициент линейной реакции λ: −λ(θu^{n+1} + (1−θ)u^n) в правой
+	// части шага. λ·dt входит в диагональ (у компактной схемы — с весами
+	// массы); при θ = 0 шаг явный, но ядро FTCS реакции не знает
+	reaction float64
+	// Объёмный источник f(x, t): dt·(θf^{n+1} + (1−θ)f^n) в правой части
+	// шага (у компактной схемы — с весами массы), у полушага BTCS —
+	// (dt/2)·f в конце полушага. Ядро FTCS источника не знает
+	source mathutils.Source
+}
+
+// θ-схема с вариантами opt; при θ = 0 — FTCS без вариантов
+func thetaSchemeWith(u0 []float64, nt int, dx, dt, alpha, theta float64, opt thetaOptions, u [][]float64, emit EmitFunc) error {
+	if theta == 0 && opt.reaction == 0 && opt.source.IsZero() {
+		return ftcs(u0, nt, dx, dt, alpha, u, emit)
+	}
+	nx := len(u0) - 1
+	r := alpha * dt / (dx * dx)
+	k := opt.reaction * dt
+	name := thetaName(theta)
+	warnUnstable(name, r, reactionMaxR(theta, k))
+	slog.Info("Starting "+name+" solver", "nx", nx, "nt", nt, "dx", dx, "dt", dt, "r", r)
+	if k > 0 {
+		slog.Info("Linear reaction", "lambda", opt.reaction, "lambda_dt", k)
+	}
+	startup := min(opt.startup, nt)
+	if startup > 0 {
+		slog.Info("Rannacher startup", "btcs_half_steps", 2*startup)
+	}
+	// Веса (1 + δ²/12): 1/12 у соседей и 5/6 в узле
+	var side, diag float64 = 0, 1
+	if opt.compact {
+		side, diag = 1.0/12, 5.0/6
+		slog.Info("Compact fourth-order differences in x")
+	}
+
+	if err := start(u, u0, emit); err != nil {
+		return err
+	}
+
+	// Источник в узлах: f^n, f^{n+1} и их смесь g с весом θ у нового слоя.
+	// Не зависящий от t источник считается один раз, и g = f
+	src := opt.source
+	var fCur, fNext, g []float64
+	if !src.IsZero() {
+		buf := pool64.get(3 * (nx + 1))
+		defer pool64.put(buf)
+		fCur, fNext, g = buf[:nx+1:nx+1], buf[nx+1:2*nx+2:2*nx+2], buf[2*nx+2:]
+		src.Fill(g, dx, 0)
+		slog.Info("Volumetric source", "f", src.Expr, "time_dependent", src.TimeDependent())
+	}
+
+	implicit, explicit := theta*r, (1-theta)*r
+	// Множители массы от реакции: 1 + θλdt слева, 1 − (1−θ)λdt справа;
+	// при λ = 0 — ровно 1, и коэффициенты совпадают побитно
+	massImplicit, massExplicit := 1+theta*k, 1-(1-theta)*k
+	a, b, c, d, ws := workspace(nx - 1)
+	defer pool64.put(ws)
+	// При θ = 0 (FTCS с реакцией) системы нет
+	var tri tridiagSolver
+	if implicit > 0 {
+		for i := 0; i < nx-1; i++ {
+			a[i] = side*massImplicit - implicit
+			b[i] = diag*massImplicit + 2*implicit
+			c[i] = side*massImplicit - implicit
+		}
+		tri = newTridiag(a, b, c)
+		defer tri.close()
+	}
+
+	// Матрица полушага BTCS: (−r/2, 1+r, −r/2), у компактной схемы
+	// (1/12 − r/2, 5/6 + r, 1/12 − r/2); реакция — множителем 1 + λdt/2
+	var half tridiagSolver
+	if startup > 0 {
+		ah, bh, ch, _, wsHalf := workspace(nx - 1)
+		defer pool64.put(wsHalf)
+		massHalf := 1 + k/2
+		for i := range bh {
+			ah[i], bh[i], ch[i] = side*massHalf-r/2, diag*massHalf+r, side*massHalf-r/2
+		}
+		half = newTridiag(ah, bh, ch)
+		defer half.close()
+	}
+	// Правая часть шага BTCS: u или (1 + δ²/12)·u
+	massRHS := func(cur []float64) {
+		if opt.compact {
+			weightedRHS(cur, d, side, diag)
+		} else {
+			copy(d, cur[1:nx])
+		}
+	}
+
+	// Источник полушага BTCS в момент t: (dt/2)·f(t)
+	halfSource := func(t float64) {
+		if src.IsZero() {
+			return
+		}
+		if src.TimeDependent() {
+			src.Fill(g, dx, t)
+		}
+		addSource(d, g, dt/2, side, diag)
+	}
+	for n := 0; n < startup; n++ {
+		cur, next := level(u, n), level(u, n+1)
+		next[0], next[nx] = 0, 0
+		massRHS(cur)
+		halfSource((float64(n) + 0.5) * dt)
+		half.solve(d, next[1:nx])
+		massRHS(next)
+		halfSource(float64(n+1) * dt)
+		half.solve(d, next[1:nx])
+		if err := tridiagErr(half); err != nil {
+			return err
+		}
+		if err := emitLevel(emit, n+1, next); err != nil {
+			return err
+		}
+	}
+	if src.TimeDependent() {
+		src.Fill(fCur, dx, float64(startup)*dt)
+	}
+	for n := startup; n < nt; n++ {
+		cur, next := level(u, n), level(u, n+1)
+		next[0], next[nx] = 0, 0
+		if src.TimeDependent() {
+			src.Fill(fNext, dx, float64(n+1)*dt)
+			for i := range g {
+				g[i] = theta*fNext[i] + (1-theta)*fCur[i]
+			}
+			fCur, fNext = fNext, fCur
+		}
+		if tri == nil {
+			weightedRHS(cur, next[1:nx], explicit, massExplicit-2*explicit)
+			if g != nil {
+				addSource(next[1:nx], g, dt, 0, 1)
+			}
+			if err := emitLevel(emit, n+1, next); err != nil {
+				return err
+			}
+			continue
+		}
+		switch {
+		case opt.compact:
+			weightedRHS(cur, d, side*massExplicit+explicit, diag*massExplicit-2*explicit)
+		case explicit == 0:
+			copy(d, cur[1:nx])
+		case k == 0:
+			thetaRHS(cur, d, explicit)
+		default:
+			weightedRHS(cur, d, explicit, massExplicit-2*explicit)
+		}
+		d[0] += implicit * next[0]
+		d[nx-2] += implicit * next[nx]
+		if g != nil {
+			addSource(d, g, dt, side, diag)
+		}
+
+		tri.solve(d, next[1:nx])
+		if err := tridiagErr(tri); err != nil {
+			return err
+		}
+		if err := emitLevel(emit, n+1, next); err != nil {
+			return err
+		}
+	}
+
+	slog.Info(name + " solver finished successfully")
+	return nil
+}
+
+// Явная часть правой части для внутренних узлов: d[i] по cur[i], cur[i+1],
+// cur[i+2] с весом w = (1−θ)r у соседей. Как в ftcsUpdateGeneric, соседи —
+// срезы cur длины len(d), без проверок границ в цикле, а преобразования
+// float64 запрещают FMA: каждое произведение округляется отдельно, и
+// результат не зависит от того, какое из них компилятор слил бы со суммой
+func thetaRHS(cur, d []float64, w float64) {
+	diag := 1 - 2*w
+	left, mid, right := cur[:len(d)], cur[1:len(d)+1], cur[2:len(d)+2]
+	for i := range d {
+		d[i] = float64(w*left[i]) + float64(diag*mid[i]) + float64(w*right[i])
+	}
+}
+
+// Правая часть с весами side у соседей и diag в узле; как thetaRHS, но
+// с произвольными весами (компактная схема)
+func weightedRHS(cur, d []float64, side, diag float64) {
+	left, mid, right := cur[:len(d)], cur[1:len(d)+1], cur[2:len(d)+2]
+	for i := range d {
+		d[i] = side*left[i] + diag*mid[i] + side*right[i]
+	}
+}
+
+// Источник во внутренних узлах: d[i] += scale·(side·g[i] + diag·g[i+1] +
+// side·g[i+2]), g — значения во всех узлах (len(d)+2)
+func addSource(d, g []float64, scale, side, diag float64) {
+	left, mid, right := g[:len(d)], g[1:len(d)+1], g[2:len(d)+2]
+	for i := range d {
+		d[i] += scale * (side*left[i] + diag*mid[i] + side*right[i])
+	}
+}
+
+// Предупреждение условно устойчивой схемы за пределом r ≤ maxR; при
+// maxR = 0 (безусловная устойчивость) молчит
+func warnUnstable(name string, r, maxR float64) {
+	if maxR > 0 && r > maxR {
+		slog.Warn(name+" may be unstable", "r", r, "max_r", maxR)
+	}
+}
+
+// Предел r для θ < ½; 0 — схема безусловно устойчива
+func thetaMaxR(theta float64) float64 {
+	if theta >= 0.5 {
+		return 0
+	}
+	return 1 / (2 * (1 - 2*theta))
+}
+
+// Имя схемы в логе: у частных случаев — прежние имена
+func thetaName(theta float64) string {
+	switch theta {
+	case 0:
+		return "FTCS"
+	case 0.5:
+		return "Crank–Nicolson"
+	case 1:
+		return "BTCS"
+	}
+	return fmt.Sprintf("θ-scheme (θ = %g)", theta)
+}
+
+// Метод θ-схемы для реестра под именем THETA; θ от 0 до 1. Встроенные
+// FTCS, BTCS и CN — её частные случаи и зарегистрированы отдельно, так что
+// THETA регистрирует тот, кому нужно промежуточное θ (например, CLI с
+// -method theta)
+func ThetaMethod(theta float64) (Method, error) {
+	if !(theta >= 0 && theta <= 1) {
+		return Method{}, fmt.Errorf("theta must be between 0 and 1, got %g", theta)
+	}
+	m := Method{
+		Name:        "THETA",
+		Description: fmt.Sprintf("θ-scheme with θ = %g: weighted explicit and implicit central differences", theta),
+		Explicit:    theta == 0,
+		MaxR:        thetaMaxR(theta),
+		OrderTime:   1,
+		OrderSpace:  2,
+		Solve: func(u0 []float64, nt int, dx, dt float64) [][]float64 {
+			return SolveThetaFrom(u0, nt, dx, dt, 1, theta)
+		},
+		Stream: func(u0 []float64, nt int, dx, dt float64, emit EmitFunc) ([]float64, error) {
+			return StreamThetaFrom(u0, nt, dx, dt, 1, theta, emit)
+		},
+		Amplification: WeightedAmplification(theta),
+		// Ожидания, не зависящие от θ
+		Verification: []VerifyCase{
+			SineOrderCase(0.05, 0.001, 0.1, 2, 0.1),
+			SteadyStateCase(0.05, 0.001, 2, 1e-8),
+		},
+		solveInto: func(u0 []float64, nt int, dx, dt float64, u [][]float64) error {
+			return thetaScheme(u0, nt, dx, dt, 1, theta, u, nil)
+		},
+	}
+	if theta == 0.5 {
+		m.OrderTime = 2
+	}
+	if m.MaxR > 0 {
+		m.StabilityBound = fmt.Sprintf("r = dt/dx² ≤ 1/(2(1−2θ)) = %g", m.MaxR)
+	}
+	if theta > 0 {
+		m.System = func(n int, r float64) (a, b, c []float64) {
+			return constantSystem(n, -theta*r, 1+2*theta*r, -theta*r)
+		}
+	}
+	return m, nil
+}
@@ -0,0 +1,147 @@
+package solver
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// θ-схема в исходной записи: правая часть (1−θ)r·δ²u + u и прогонка без
+// разложения; для сравнения при промежуточных θ
+func thetaReference(u0 []float64, nt int, dx, dt, theta float64) [][]float64 {
+	nx := len(u0) - 1
+	r := dt / (dx * dx)
+	u := make([][]float64, nt+1)
+	u[0] = append([]float64(nil), u0...)
+	u[0][0], u[0][nx] = 0, 0
+	a, b, c := make([]float64, nx-1), make([]float64, nx-1), make([]float64, nx-1)
+	for i := range b {
+		a[i], b[i], c[i] = -theta*r, 1+2*theta*r, -theta*r
+	}
+	for n := 0; n < nt; n++ {
+		u[n+1] = make([]float64, nx+1)
+		d := make([]float64, nx-1)
+		for i := 1; i < nx; i++ {
+			d[i-1] = u[n][i] + (1-theta)*r*(u[n][i+1]-2*u[n][i]+u[n][i-1])
+		}
+		copy(u[n+1][1:nx], thomasReference(a, b, c, d))
+	}
+	return u
+}
+
+// θ = 0, 1 и ½ побитно совпадают с исходной записью FTCS, BTCS и CN;
+// промежуточные θ — с исходной записью θ-схемы до 1e-12. Поток
+// заканчивается последним слоем полного расчёта
+func TestSolveTheta(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	tests := []struct {
+		theta float64
+		exact string // схема с побитным совпадением; "" — до 1e-12
+	}{
+		{0, "FTCS"},
+		{1, "BTCS"},
+		{0.5, "CN"},
+		{0.25, ""},
+		{0.75, ""},
+	}
+	for _, tc := range tests {
+		for _, nx := range []int{2, 5, 64} {
+			t.Run(fmt.Sprintf("theta=%g/nx=%d", tc.theta, nx), func(t *testing.T) {
+				dx := 1.0 / float64(nx)
+				dt := 0.4 * dx * dx
+				u0 := SineProfile(nx, dx)
+				for i := range u0 {
+					u0[i] += 1e-3 * rng.NormFloat64()
+				}
+				got := SolveThetaFrom(u0, 30, dx, dt, 1, tc.theta)
+				want, tol := thetaReference(u0, 30, dx, dt, tc.theta), 1e-12
+				if tc.exact != "" {
+					want, tol = stencilReference(tc.exact, u0, 30, dx, dt), 0
+				}
+				for n := range want {
+					for i := range want[n] {
+						if math.Abs(got[n][i]-want[n][i]) > tol {
+							t.Fatalf("u[%d][%d] = %v, want %v", n, i, got[n][i], want[n][i])
+						}
+					}
+				}
+				last, err := StreamThetaFrom(u0, 30, dx, dt, 1, tc.theta, nil)
+				if err != nil {
+					t.Fatal(err)
+				}
+				for i := range last {
+					if last[i] != got[30][i] {
+						t.Fatalf("streamed u[%d] = %v, full run %v", i, last[i], got[30][i])
+					}
+				}
+			})
+		}
+	}
+}
+
+// α входит только через α·dt: α = 2 с dt совпадает с α = 1 с 2dt
+func TestSolveThetaAlpha(t *testing.T) {
+	for _, theta := range []float64{0, 0.5, 0.75, 1} {
+		a := SolveTheta(40, 20, 0.025, 1e-4, 2, theta)
+		b := SolveTheta(40, 20, 0.025, 2e-4, 1, theta)
+		for i := range a[20] {
+			if a[20][i] != b[20][i] {
+				t.Errorf("theta=%g: u[%d] = %v with α = 2, %v with 2dt", theta, i, a[20][i], b[20][i])
+				break
+			}
+		}
+	}
+}
+
+// Предел устойчивости 1/(2(1−2θ)): при θ = ¼ расчёт чуть ниже r = 1
+// затухает, чуть выше — растёт; амплитуда sin(πx) убывает по G(π·dx)ⁿ
+func TestThetaMethod(t *testing.T) {
+	for _, theta := range []float64{-0.1, 1.5, math.NaN()} {
+		if _, err := ThetaMethod(theta); err == nil {
+			t.Errorf("theta %v accepted", theta)
+		}
+	}
+	tests := []struct {
+		theta     float64
+		maxR      float64
+		orderTime int
+	}{
+		{0, 0.5, 1},
+		{0.25, 1, 1},
+		{0.5, 0, 2},
+		{0.75, 0, 1},
+	}
+	for _, tc := range tests {
+		m, err := ThetaMethod(tc.theta)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if m.Name != "THETA" || m.MaxR != tc.maxR || m.OrderTime != tc.orderTime || m.Unconditional() != (tc.maxR == 0) ||
+			(m.System == nil) != (tc.theta == 0) || m.Explicit != (tc.theta == 0) {
+			t.Errorf("theta=%g: %s with max r %g, order %d, explicit %v", tc.theta, m.Name, m.MaxR, m.OrderTime, m.Explicit)
+		}
+
+		const nx, nt = 50, 1000
+		dx := 1.0 / nx
+		for _, r := range []float64{0.9 * max(tc.maxR, 1), 1.1 * tc.maxR} {
+			if r == 0 {
+				continue
+			}
+			u := m.Solve(SineProfile(nx, dx), nt, dx, r*dx*dx)
+			peak := 0.0
+			for _, v := range u[nt] {
+				peak = max(peak, math.Abs(v))
+			}
+			if stable := m.StableAt(r); stable != (peak < 1) {
+				t.Errorf("theta=%g, r=%g: peak %g after %d steps, stable by the bound: %v", tc.theta, r, peak, nt, stable)
+			}
+			if m.StableAt(r) {
+				want := math.Pow(m.Amplification(r, math.Pi*dx), nt)
+				if math.Abs(u[nt][nx/2]-want) > 1e-12 {
+					t.Errorf("theta=%g, r=%g: u(½) = %v, want G^n = %v", tc.theta, r, u[nt][nx/2], want)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package solver
+
+import "math"
+
+// Максимум каждого слоя по x и узел, где он достигается. По нему
+// отвечают на вопрос «когда пик опустится ниже T*» для любых порогов уже
+// после расчёта (в том числе для результата из кэша): 12 байт на слой
+type PeakTrace struct {
+	values []float64
+	nodes  []int32
+}
+
+// Ёмкость — ожидаемое число слоёв
+func NewPeakTrace(levels int) *PeakTrace {
+	return &PeakTrace{values: make([]float64, 0, levels), nodes: make([]int32, 0, levels)}
+}
+
+// Максимум слоя; слои передаются по порядку, начиная с нулевого. NaN
+// пропускаются, слой из одних NaN даёт NaN. В расчётах максимумы пишет
+// PrincipleMonitor (TracePeaks) тем же проходом, что и проверку
+func (t *PeakTrace) Add(row []float64) {
+	_, peak, node := extrema(row)
+	t.add(peak, node)
+}
+
+func (t *PeakTrace) add(peak float64, node int) {
+	t.values = append(t.values, peak)
+	t.nodes = append(t.nodes, int32(node))
+}
+
+func (t *PeakTrace) Len() int {
+	return len(t.values)
+}
+
+// Максимум слоя n и его узел
+func (t *PeakTrace) Peak(n int) (float64, int) {
+	return t.values[n], int(t.nodes[n])
+}
+
+// Первое опускание max_x u ниже порога. Время и положение максимума
+// линейно интерполируются между слоями Step−1 и Step. Reached = false —
+// порог не достигнут до tmax, остальные поля нулевые
+type Crossing struct {
+	Threshold float64
+	Reached   bool
+	Step      int
+	Time      float64
+	X         float64
+}
+
+// Пересечения порогов в порядке thresholds; dt и dx — шаги расчёта
+func (t *PeakTrace) Crossings(dt, dx float64, thresholds ...float64) []Crossing {
+	out := make([]Crossing, len(thresholds))
+	for k, thr := range thresholds {
+		out[k] = t.crossing(dt, dx, thr)
+	}
+	return out
+}
+
+func (t *PeakTrace) crossing(dt, dx, thr float64) Crossing {
+	c := Crossing{Threshold: thr}
+	for n, v := range t.values {
+		if !(v < thr) {
+			continue
+		}
+		c.Reached, c.Step = true, n
+		c.Time, c.X = float64(n)*dt, float64(t.nodes[n])*dx
+		if n == 0 || math.IsNaN(t.values[n-1]) {
+			return c
+		}
+		// Между слоями n−1 (≥ thr) и n (< thr)
+		prev := t.values[n-1]
+		s := (prev - thr) / (prev - v)
+		c.Time = (float64(n-1) + s) * dt
+		c.X = (float64(t.nodes[n-1]) + s*float64(t.nodes[n]-t.nodes[n-1])) * dx
+		return c
+	}
+	return c
+}
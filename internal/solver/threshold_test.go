@@ -0,0 +1,82 @@
+package solver
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"testing"
+
+	"heat-solver/internal/config"
+)
+
+// Пересечения по записанным максимумам: интерполяция времени и узла,
+// порог с самого начала, недостигнутый порог, NaN на соседнем слое
+func TestCrossings(t *testing.T) {
+	const dt, dx = 0.1, 0.25
+	trace := NewPeakTrace(6)
+	for _, row := range [][]float64{
+		{0, 1, 0.5, 0, 0},
+		{0, 0.2, 0.8, 0, 0},
+		{0, 0, 0.6, 0.2, 0},
+		{0, 0, 0, 0.4, 0},
+		{math.NaN(), math.NaN(), math.NaN(), math.NaN(), math.NaN()},
+		{0, 0.05, 0, 0, 0},
+	} {
+		trace.Add(row)
+	}
+	tests := []struct {
+		threshold float64
+		want      Crossing
+	}{
+		{2, Crossing{Threshold: 2, Reached: true, Step: 0, Time: 0, X: 0.25}},
+		{0.9, Crossing{Threshold: 0.9, Reached: true, Step: 1, Time: 0.05, X: 0.375}},
+		{0.8, Crossing{Threshold: 0.8, Reached: true, Step: 2, Time: 0.1, X: 0.5}},
+		{0.5, Crossing{Threshold: 0.5, Reached: true, Step: 3, Time: 0.25, X: 0.625}},
+		// Слой 4 из одних NaN не пересекает порог, и слой 5 не с чем
+		// интерполировать
+		{0.3, Crossing{Threshold: 0.3, Reached: true, Step: 5, Time: 0.5, X: 0.25}},
+		{-1, Crossing{Threshold: -1}},
+	}
+	var thresholds []float64
+	for _, tc := range tests {
+		thresholds = append(thresholds, tc.threshold)
+	}
+	got := trace.Crossings(dt, dx, thresholds...)
+	for k, tc := range tests {
+		c := got[k]
+		if c.Threshold != tc.want.Threshold || c.Reached != tc.want.Reached || c.Step != tc.want.Step ||
+			math.Abs(c.Time-tc.want.Time) > 1e-15 || math.Abs(c.X-tc.want.X) > 1e-15 {
+			t.Errorf("threshold %v: %+v, want %+v", tc.threshold, c, tc.want)
+		}
+	}
+}
+
+// Для sin(πx) пик в x = ½ опускается ниже T* при t = −ln T*/π²;
+// ошибка — доля dt от интерполяции и погрешность схемы
+func TestCrossingsAnalytic(t *testing.T) {
+	thresholds := []float64{0.9, 0.5, 0.1, 0.01, 1e-9}
+	for _, method := range []string{"FTCS", "BTCS", "CN"} {
+		for _, storage := range []string{config.StorageFull, config.StorageFinalOnly} {
+			t.Run(fmt.Sprintf("%s/%s", method, storage), func(t *testing.T) {
+				p := config.Params{Method: method, Dx: 0.01, Dt: 4e-5, Tmax: 0.5, Storage: storage}.Normalize()
+				res, err := Run(context.Background(), p, Hooks{})
+				if err != nil {
+					t.Fatal(err)
+				}
+				defer res.Release()
+				for _, c := range res.Crossings(thresholds...) {
+					want := -math.Log(c.Threshold) / (math.Pi * math.Pi)
+					if want > p.Tmax {
+						if c.Reached {
+							t.Errorf("threshold %g reached at t=%v after tmax", c.Threshold, c.Time)
+						}
+						continue
+					}
+					if !c.Reached || math.Abs(c.Time-want) > p.Dt+1e-3*want || math.Abs(c.X-0.5) > 1e-12 {
+						t.Errorf("threshold %g: reached %v at t=%.6f, x=%v; want t=%.6f at x=0.5", c.Threshold, c.Reached, c.Time, c.X, want)
+					}
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package solver
+
+import (
+	"fmt"
+	"log/slog"
+	"sync/atomic"
+)
+
+// Алгоритмы трёхдиагональной прогонки в BTCS и CN
+const (
+	// Последовательная прогонка Томаса
+	TridiagThomas = "thomas"
+	// Разбиение на блоки, которые прогоняются параллельно, и малая система
+	// для разделяющих строк. От Томаса отличается на уровне округления;
+	// на малых nx и одном потоке используется Томас
+	TridiagParallel = "parallel"
+)
+
+var tridiagAlgo atomic.Value
+
+// Выбор алгоритма прогонки для расчётов, начатых после вызова
+func SetTridiagonal(name string) error {
+	switch name {
+	case TridiagThomas, TridiagParallel:
+		tridiagAlgo.Store(name)
+		return nil
+	}
+	return fmt.Errorf("unknown tridiagonal solver %q, want %s or %s", name, TridiagThomas, TridiagParallel)
+}
+
+func Tridiagonal() string {
+	if name, ok := tridiagAlgo.Load().(string); ok {
+		return name
+	}
+	return TridiagThomas
+}
+
+// Наименьший блок на поток в параллельной прогонке. Блочный алгоритм
+// делает примерно вдвое больше операций, чем Томас, поэтому блоки должны
+// быть крупными
+const tridiagMinBlock = 1 << 15
+
+// Решение системы с постоянной матрицей на каждом шаге
+type tridiagSolver interface {
+	// Правая часть d, решение в x; x не должен пересекаться с d
+	solve(d, x []float64)
+	close()
+}
+
+// Разложение матрицы (a, b, c) выбранным алгоритмом; при включённой
+// проверке невязки (SetResidualCheck) — с проверкой каждого шага
+func newTridiag(a, b, c []float64) tridiagSolver {
+	t := factorTridiag(a, b, c)
+	if mode := ResidualCheck(); mode != ResidualOff {
+		return newCheckedTridiag(t, a, b, c, mode == ResidualStrict)
+	}
+	return t
+}
+
+func factorTridiag(a, b, c []float64) tridiagSolver {
+	if Tridiagonal() == TridiagParallel {
+		if pool := newChunkPool(0, len(b), tridiagMinBlock); pool != nil {
+			slog.Info("Parallel tridiagonal solver", "n", len(b), "blocks", pool.size())
+			return newBlockTridiag(pool, a, b, c)
+		}
+	}
+	t := newThomas(len(b))
+	t.factor(a, b, c)
+	return t
+}
+
+// Блочная прогонка. Последняя строка каждого фрагмента пула, кроме
+// последнего, — разделитель s_j; остальные строки блока k выражаются
+// через соседние разделители: x = y + v·s_{k−1} + w·s_k, где y — прогонка
+// блока с правой частью d, а v и w от шага не зависят. Подстановка в
+// уравнения разделителей даёт трёхдиагональную систему из P−1 неизвестных.
+// На шаге: прогонка блоков параллельно, малая система последовательно,
+// затем параллельная поправка x
+type blockTridiag struct {
+	pool    *chunkPool
+	a, b, c []float64
+	blocks  []*thomas
+	v, w    []float64
+	// Система для разделителей: разложение, правая часть и решение
+	sep        *thomas
+	sepD, sepX []float64
+
+	// Аргументы текущего шага для потоков пула
+	d, x           []float64
+	sweep, correct func(k, lo, hi int)
+}
+
+func newBlockTridiag(pool *chunkPool, a, b, c []float64) *blockTridiag {
+	n, p := len(b), pool.size()
+	t := &blockTridiag{
+		pool:   pool,
+		a:      a,
+		b:      b,
+		c:      c,
+		blocks: make([]*thomas, p),
+		v:      make([]float64, n),
+		w:      make([]float64, n),
+		sepD:   make([]float64, p-1),
+		sepX:   make([]float64, p-1),
+	}
+	t.sweep = func(k, lo, hi int) {
+		hi = t.blockEnd(k, hi)
+		t.blocks[k].solve(t.d[lo:hi], t.x[lo:hi])
+	}
+	t.correct = func(k, lo, hi int) {
+		hi = t.blockEnd(k, hi)
+		var left, right float64
+		if k > 0 {
+			left = t.x[lo-1]
+		}
+		if hi < n {
+			right = t.x[hi]
+		}
+		x, v, w := t.x[lo:hi], t.v[lo:hi], t.w[lo:hi]
+		for i := range x {
+			x[i] += v[i]*left + w[i]*right
+		}
+	}
+
+	// Разложение блоков и отклики v, w на единичные разделители
+	pool.run(func(k, lo, hi int) {
+		hi = t.blockEnd(k, hi)
+		blk := newThomas(hi - lo)
+		blk.factor(a[lo:hi], b[lo:hi], c[lo:hi])
+		t.blocks[k] = blk
+		e := make([]float64, hi-lo)
+		if k > 0 {
+			e[0] = -a[lo]
+			blk.solve(e, t.v[lo:hi])
+			e[0] = 0
+		}
+		if hi < n {
+			e[len(e)-1] = -c[hi-1]
+			blk.solve(e, t.w[lo:hi])
+		}
+	})
+
+	sa, sb, sc := make([]float64, p-1), make([]float64, p-1), make([]float64, p-1)
+	for j := range p - 1 {
+		q := t.separator(j)
+		sa[j] = a[q] * t.v[q-1]
+		sb[j] = b[q] + a[q]*t.w[q-1] + c[q]*t.v[q+1]
+		sc[j] = c[q] * t.w[q+1]
+	}
+	t.sep = newThomas(p - 1)
+	t.sep.factor(sa, sb, sc)
+	return t
+}
+
+// Строка разделителя j: последняя строка фрагмента j
+func (t *blockTridiag) separator(j int) int {
+	return t.pool.bounds[j+1] - 1
+}
+
+// Конец блока k без разделителя
+func (t *blockTridiag) blockEnd(k, hi int) int {
+	if k < t.pool.size()-1 {
+		return hi - 1
+	}
+	return hi
+}
+
+func (t *blockTridiag) solve(d, x []float64) {
+	t.d, t.x = d, x
+	t.pool.run(t.sweep)
+
+	a, c := t.a, t.c
+	for j := range t.sepD {
+		q := t.separator(j)
+		t.sepD[j] = d[q] - a[q]*x[q-1] - c[q]*x[q+1]
+	}
+	t.sep.solve(t.sepD, t.sepX)
+	for j, s := range t.sepX {
+		x[t.separator(j)] = s
+	}
+
+	t.pool.run(t.correct)
+}
+
+func (t *blockTridiag) close() {
+	t.pool.close()
+	for _, blk := range t.blocks {
+		blk.close()
+	}
+	t.sep.close()
+}
@@ -0,0 +1,113 @@
+package solver
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// Алгоритм прогонки на время теста
+func withTridiagonal(t testing.TB, name string) {
+	t.Helper()
+	prev := Tridiagonal()
+	if err := SetTridiagonal(name); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { SetTridiagonal(prev) })
+}
+
+// Блочная прогонка совпадает с Томасом до 1e-12 относительно max|x|;
+// малые системы и один поток решаются Томасом
+func TestBlockTridiag(t *testing.T) {
+	withTridiagonal(t, TridiagParallel)
+	rng := rand.New(rand.NewSource(1))
+	tests := []struct {
+		name    string
+		n       int
+		threads int
+		blocks  bool
+		b       func() float64
+	}{
+		{"small falls back to Thomas", tridiagMinBlock, 4, false, func() float64 { return 2 }},
+		{"one thread falls back to Thomas", 4 * tridiagMinBlock, 1, false, func() float64 { return 2 }},
+		{"CN r=0.5", 4 * tridiagMinBlock, 4, true, func() float64 { return 1.5 }},
+		{"weak diagonal dominance", 3*tridiagMinBlock + 17, 3, true, func() float64 { return 1.0001 }},
+		{"random", 2*tridiagMinBlock + 1, 2, true, func() float64 { return 1.2 + rng.Float64() }},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			withThreads(t, tc.threads)
+			a, b, c := make([]float64, tc.n), make([]float64, tc.n), make([]float64, tc.n)
+			for i := range b {
+				a[i], b[i], c[i] = -0.5, tc.b(), -0.5
+			}
+			tri := factorTridiag(a, b, c)
+			defer tri.close()
+			if _, ok := tri.(*blockTridiag); ok != tc.blocks {
+				t.Fatalf("block solver used: %v, want %v", ok, tc.blocks)
+			}
+			for rhs := range 2 {
+				d := make([]float64, tc.n)
+				for i := range d {
+					d[i] = rng.NormFloat64()
+				}
+				x := make([]float64, tc.n)
+				tri.solve(d, x)
+				want := thomasReference(a, b, c, d)
+				var scale, diff float64
+				for i := range want {
+					scale = max(scale, math.Abs(want[i]))
+					diff = max(diff, math.Abs(x[i]-want[i]))
+				}
+				if diff > 1e-12*scale {
+					t.Errorf("rhs %d: max difference %g from Thomas, want ≤ 1e-12·%g", rhs, diff, scale)
+				}
+			}
+		})
+	}
+}
+
+// CN с блочной прогонкой: тот же результат, что с Томасом, до округления;
+// ошибка округления накапливается не быстрее 1e-12 за шаг
+func TestParallelTridiagCN(t *testing.T) {
+	const nx, nt = 1 << 17, 5
+	dx := 1.0 / nx
+	withThreads(t, 4)
+	for _, r := range []float64{0.5, 1, 100} {
+		t.Run(fmt.Sprintf("r=%g", r), func(t *testing.T) {
+			withTridiagonal(t, TridiagThomas)
+			serial := SolveCrankNicolson(nx, nt, dx, r*dx*dx)
+			SetTridiagonal(TridiagParallel)
+			parallel := SolveCrankNicolson(nx, nt, dx, r*dx*dx)
+			for k := 1; k <= nt; k++ {
+				for i, v := range parallel[k] {
+					if math.Abs(v-serial[k][i]) > 1e-12*float64(k) {
+						t.Fatalf("step %d: u[%d] = %v, Thomas %v", k, i, v, serial[k][i])
+					}
+				}
+			}
+		})
+	}
+}
+
+// Шаг прогонки при n = 2²⁰ по числу потоков; 1 поток — Томас
+func BenchmarkTridiagonal(b *testing.B) {
+	const n = 1 << 20
+	a, bd, c, d := make([]float64, n), make([]float64, n), make([]float64, n), make([]float64, n)
+	for i := range bd {
+		a[i], bd[i], c[i], d[i] = -0.5, 2, -0.5, float64(i%7)
+	}
+	x := make([]float64, n)
+	withTridiagonal(b, TridiagParallel)
+	for _, threads := range []int{1, 2, 4, 8} {
+		b.Run(fmt.Sprintf("threads=%d", threads), func(b *testing.B) {
+			withThreads(b, threads)
+			tri := factorTridiag(a, bd, c)
+			defer tri.close()
+			for b.Loop() {
+				tri.solve(d, x)
+			}
+		})
+	}
+}
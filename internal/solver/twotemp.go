@@ -0,0 +1,319 @@
+package solver
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+
+	"heat-solver/internal/mathutils"
+)
+
+// Двухтемпературная модель (электроны и решётка, твёрдая фаза и жидкость)
+//
+//	u_t = α₁·u_xx − h·(u − v),  v_t = α₂·v_xx + h·(u − v)
+//
+// на [0, 1]: поля обмениваются теплом с коэффициентом h ≥ 0. Шаг
+// θ-взвешенный, как SolveTheta, и решает систему для обоих полей сразу,
+// без итераций между ними: с неизвестными вперемешку (u_0, v_0, u_1, v_1,
+// …) матрица блочно-трёхдиагональная с блоками 2×2, то есть
+// пятидиагональная, постоянная и раскладывается один раз (penta). Строки
+// матрицы — 1 + θ(2r_k + h·dt) на диагонали против θ(2r_k + h·dt) вне
+// её, диагональное преобладание обходится без выбора ведущего элемента.
+// Обмен неявный, поэтому жёсткость при большом h·dt схему не ломает; с
+// BTCS поля при h → ∞ сливаются в решение одного уравнения с
+// α = (α₁ + α₂)/2. Края — ноль у обоих полей или теплоизоляция
+// (Insulated): u_x = v_x = 0 через фиктивный узел u_{−1} = u_1, и тогда
+// тепло ∫(u + v) dx сохраняется точно, а энергия ½∫(u² + v²) dx при θ ≥ ½
+// не растёт (TwoTemperatureEnergy)
+
+// Параметры двухтемпературной модели
+type TwoTemperature struct {
+	Alpha1, Alpha2 float64
+	// Коэффициент обмена h
+	H float64
+	// Теплоизолированные края вместо нуля на них
+	Insulated bool
+}
+
+// Приём слоя n обоих полей, как EmitFunc
+type TwoFieldEmitFunc func(n int, u, v []float64) error
+
+// Двухтемпературная модель с профилями u0 и v0 (nx+1 значений, dx = 1/nx;
+// без Insulated края обнуляются, как у start); хранятся по два слоя
+// каждого поля, каждый слой передаётся в emit
+func StreamTwoTemperatureFrom(u0, v0 []float64, nt int, dx, dt, theta float64, m TwoTemperature, emit TwoFieldEmitFunc) ([]float64, []float64, error) {
+	if len(u0) != len(v0) {
+		return nil, nil, fmt.Errorf("initial profiles differ in length: %d and %d", len(u0), len(v0))
+	}
+	u, v := newGrid(2, len(u0)), newGrid(2, len(v0))
+	if err := twoTemperature(u0, v0, nt, dx, dt, theta, m, u, v, emit); err != nil {
+		return nil, nil, err
+	}
+	return level(u, nt), level(v, nt), nil
+}
+
+// Тепло ∫(u + v) dx и энергия ½∫(u² + v²) dx по формуле трапеций. В
+// скалярном произведении с весами трапеций разностный оператор с
+// фиктивными узлами симметричен, поэтому для них и выполняются законы
+// сохранения и убывания
+func TwoTemperatureEnergy(u, v []float64, dx float64) (heat, energy float64) {
+	for i := range u {
+		w := dx
+		if i == 0 || i == len(u)-1 {
+			w /= 2
+		}
+		heat += w * (u[i] + v[i])
+		energy += w * (u[i]*u[i] + v[i]*v[i]) / 2
+	}
+	return heat, energy
+}
+
+func twoTemperature(u0, v0 []float64, nt int, dx, dt, theta float64, m TwoTemperature, u, v [][]float64, emit TwoFieldEmitFunc) error {
+	if !(m.Alpha1 > 0) || !(m.Alpha2 > 0) || math.IsInf(m.Alpha1, 0) || math.IsInf(m.Alpha2, 0) {
+		return fmt.Errorf("diffusivities must be positive and finite, got %g and %g", m.Alpha1, m.Alpha2)
+	}
+	if !(m.H >= 0) || math.IsInf(m.H, 0) {
+		return fmt.Errorf("exchange coefficient must be non-negative and finite, got %g", m.H)
+	}
+	nx := len(u0) - 1
+	if nx < 2 {
+		return fmt.Errorf("two-temperature model needs at least 2 intervals, got %d", nx)
+	}
+	r := [2]float64{m.Alpha1 * dt / (dx * dx), m.Alpha2 * dt / (dx * dx)}
+	hdt := m.H * dt
+	name := "two-temperature " + thetaName(theta)
+	// Спектр оператора с обменом — в [−4r − 2h·dt, 0]
+	warnUnstable(name, math.Max(r[0], r[1])+hdt/2, thetaMaxR(theta))
+	slog.Info("Starting "+name+" solver", "nx", nx, "nt", nt, "dx", dx, "dt", dt, "r1", r[0], "r2", r[1], "h_dt", hdt, "insulated", m.Insulated)
+
+	cu, cv := level(u, 0), level(v, 0)
+	copy(cu, u0)
+	copy(cv, v0)
+	if !m.Insulated {
+		cu[0], cu[nx], cv[0], cv[nx] = 0, 0, 0, 0
+	}
+	if err := twoFieldLevel(emit, 0, cu, cv); err != nil {
+		return err
+	}
+
+	// Узлы с неизвестными: все при теплоизоляции, иначе внутренние
+	first, last := 1, nx-1
+	if m.Insulated {
+		first, last = 0, nx
+	}
+	nodes := last - first + 1
+	n := 2 * nodes
+	buf := pool64.get(7 * n)
+	defer pool64.put(buf)
+	e, a, b, c, f := buf[:n:n], buf[n:2*n:2*n], buf[2*n:3*n:3*n], buf[3*n:4*n:4*n], buf[4*n:5*n:5*n]
+	d, z := buf[5*n:6*n:6*n], buf[6*n:]
+	// Множители соседей узла i: 2 вместо 1 у единственного соседа узла на
+	// теплоизолированном краю (фиктивный узел — зеркало соседа)
+	left := func(i int) float64 {
+		if i == nx {
+			return 2
+		}
+		return 1
+	}
+	right := func(i int) float64 {
+		if i == 0 {
+			return 2
+		}
+		return 1
+	}
+	for i := first; i <= last; i++ {
+		for k := range 2 {
+			p := 2*(i-first) + k
+			e[p], a[p], c[p], f[p] = -theta*r[k]*left(i), 0, 0, -theta*r[k]*right(i)
+			b[p] = 1 + theta*(2*r[k]+hdt)
+		}
+		c[2*(i-first)] = -theta * hdt
+		a[2*(i-first)+1] = -theta * hdt
+	}
+	lu := newPenta(n)
+	defer lu.close()
+	lu.factor(e, a, b, c, f)
+
+	for step := 0; step < nt; step++ {
+		cur := [2][]float64{level(u, step), level(v, step)}
+		next := [2][]float64{level(u, step+1), level(v, step+1)}
+		for i := first; i <= last; i++ {
+			ex := cur[1][i] - cur[0][i]
+			for k, w := range cur {
+				lap := -2 * w[i]
+				if i > 0 {
+					lap += left(i) * w[i-1]
+				}
+				if i < nx {
+					lap += right(i) * w[i+1]
+				}
+				d[2*(i-first)+k] = w[i] + (1-theta)*(r[k]*lap+hdt*ex)
+				ex = -ex
+			}
+		}
+		lu.solve(d, z)
+		for i := first; i <= last; i++ {
+			next[0][i], next[1][i] = z[2*(i-first)], z[2*(i-first)+1]
+		}
+		if err := twoFieldLevel(emit, step+1, next[0], next[1]); err != nil {
+			return err
+		}
+	}
+
+	slog.Info(name + " solver finished successfully")
+	return nil
+}
+
+func twoFieldLevel(emit TwoFieldEmitFunc, n int, u, v []float64) error {
+	if emit == nil {
+		return nil
+	}
+	return emit(n, u, v)
+}
+
+// Проверочные случаи двухтемпературной модели; в Verify — под именем
+// TWOTEMP
+var verificationTwoTemperature = []VerifyCase{
+	twoTemperatureModeCase(0.02, 0.0005, 0.5, 1e-4),
+	twoTemperatureCollapseCase(1e6, 1e-5),
+	twoTemperatureCollapseOrderCase(1e3, 1, 0.05),
+	twoTemperatureEnergyCase(0.5, 1e-12),
+	twoTemperatureEnergyCase(1, 1e-12),
+}
+
+// Параметры проверок: быстрые «электроны» u, медленная «решётка» v,
+// нагреты вначале только электроны
+var verifyTwoTemperature = TwoTemperature{Alpha1: 1, Alpha2: 0.2, H: 5}
+
+// Мода sin(πx) против mathutils.TwoTemperatureMode (u из sin(πx), v из
+// нуля, CN): наибольшая ошибка обоих полей в момент tmax не больше tol
+func twoTemperatureModeCase(dx, dt, tmax, tol float64) VerifyCase {
+	m := verifyTwoTemperature
+	mode := mathutils.TwoTemperatureMode{Alpha1: m.Alpha1, Alpha2: m.Alpha2, H: m.H, U0: 1}
+	return VerifyCase{
+		Name:     fmt.Sprintf("sine mode h=%g α₂=%g dx=%g dt=%g", m.H, m.Alpha2, dx, dt),
+		Quantity: "L∞ error of u and v",
+		Compare:  VerifyAtMost,
+		Expected: tol,
+		Measure: func(ctx context.Context, _ Method) (float64, string, error) {
+			nx := int(math.Round(1 / dx))
+			nt := int(math.Round(tmax / dt))
+			lu, lv, err := StreamTwoTemperatureFrom(SineProfile(nx, dx), make([]float64, nx+1), nt, dx, dt, 0.5, m, nil)
+			if err != nil {
+				return math.NaN(), "", err
+			}
+			t := float64(nt) * dt
+			_, eu := mathutils.LevelErrors(lu, dx, t, mode.U())
+			_, ev := mathutils.LevelErrors(lv, dx, t, mode.V())
+			a, b := mode.Amplitudes(t)
+			return math.Max(eu, ev), fmt.Sprintf("amplitudes u %.5f, v %.5f", a, b), nil
+		},
+	}
+}
+
+// Наибольшее отличие обоих полей от одного уравнения с α = (α₁ + α₂)/2 из
+// полусуммы начальных профилей. Обе задачи считаются BTCS с тем же
+// шагом; одно уравнение — через StreamBTCSFrom с шагом α·dt, что для
+// BTCS то же самое. Из sin(πx) и нуля, dx = 0.05, dt = 0.001, t = 0.1
+func twoTemperatureCollapse(h float64) (float64, error) {
+	const dx, dt, nt = 0.05, 0.001, 100
+	nx := int(math.Round(1 / dx))
+	m := verifyTwoTemperature
+	m.H = h
+	lu, lv, err := StreamTwoTemperatureFrom(SineProfile(nx, dx), make([]float64, nx+1), nt, dx, dt, 1, m, nil)
+	if err != nil {
+		return math.NaN(), err
+	}
+	w0 := SineProfile(nx, dx)
+	for i := range w0 {
+		w0[i] /= 2
+	}
+	single, err := StreamBTCSFrom(w0, nt, dx, (m.Alpha1+m.Alpha2)/2*dt, nil)
+	if err != nil {
+		return math.NaN(), err
+	}
+	var worst float64
+	for i, w := range single {
+		worst = math.Max(worst, math.Max(math.Abs(lu[i]-w), math.Abs(lv[i]-w)))
+	}
+	return worst, nil
+}
+
+// При h = h поля совпадают с одним уравнением (twoTemperatureCollapse) с
+// точностью tol
+func twoTemperatureCollapseCase(h, tol float64) VerifyCase {
+	return VerifyCase{
+		Name:     fmt.Sprintf("h=%g collapses onto α = (α₁ + α₂)/2", h),
+		Quantity: "max |u, v − u single|",
+		Compare:  VerifyAtMost,
+		Expected: tol,
+		Measure: func(ctx context.Context, _ Method) (float64, string, error) {
+			diff, err := twoTemperatureCollapse(h)
+			return diff, "", err
+		},
+	}
+}
+
+// Отличие от одного уравнения убывает как 1/h: порядок по h между h и
+// 10h — log₁₀ отношения отличий
+func twoTemperatureCollapseOrderCase(h, order, tol float64) VerifyCase {
+	return VerifyCase{
+		Name:      fmt.Sprintf("collapse order in 1/h from h=%g", h),
+		Quantity:  "observed order in 1/h",
+		Compare:   VerifyNear,
+		Expected:  order,
+		Tolerance: tol,
+		Measure: func(ctx context.Context, _ Method) (float64, string, error) {
+			var diffs [2]float64
+			for k := range diffs {
+				var err error
+				if diffs[k], err = twoTemperatureCollapse(h * math.Pow(10, float64(k))); err != nil {
+					return math.NaN(), "", err
+				}
+			}
+			return math.Log10(diffs[0] / diffs[1]), fmt.Sprintf("differences %.3g, %.3g", diffs[0], diffs[1]), nil
+		},
+	}
+}
+
+// Теплоизолированные края, u — импульс на (0.4, 0.6), v — ноль, θ = theta:
+// энергия TwoTemperatureEnergy по слоям не растёт, а тепло сохраняется.
+// Измеряется наибольший рост энергии за шаг и наибольший уход тепла, оба
+// относительно начальных значений; уход тепла — накопленное за nt шагов
+// округление
+func twoTemperatureEnergyCase(theta, tol float64) VerifyCase {
+	return VerifyCase{
+		Name:     fmt.Sprintf("insulated energy decay %s", thetaName(theta)),
+		Quantity: "max energy rise, heat drift",
+		Compare:  VerifyAtMost,
+		Expected: tol,
+		Measure: func(ctx context.Context, _ Method) (float64, string, error) {
+			const dx, dt, nt = 0.02, 0.001, 500
+			nx := int(math.Round(1 / dx))
+			m := verifyTwoTemperature
+			m.Insulated = true
+			var heat0, energy0, prev, rise, drift float64
+			steps := 0
+			_, _, err := StreamTwoTemperatureFrom(PulseProfile(nx, dx, 0.4, 0.6), make([]float64, nx+1), nt, dx, dt, theta, m, func(n int, u, v []float64) error {
+				heat, energy := TwoTemperatureEnergy(u, v, dx)
+				if n == 0 {
+					heat0, energy0 = heat, energy
+				} else {
+					rise = math.Max(rise, (energy-prev)/energy0)
+					if energy < prev {
+						steps++
+					}
+				}
+				drift = math.Max(drift, math.Abs(heat-heat0)/heat0)
+				prev = energy
+				return nil
+			})
+			if err != nil {
+				return math.NaN(), "", err
+			}
+			return math.Max(rise, drift), fmt.Sprintf("energy %.4g → %.4g, falls in %d of %d steps; heat drift %.2g",
+				energy0, prev, steps, nt, drift), nil
+		},
+	}
+}
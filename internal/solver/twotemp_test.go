@@ -0,0 +1,165 @@
+package solver
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+
+	"heat-solver/internal/mathutils"
+)
+
+// Мода sin(πx) против TwoTemperatureMode (u из sin(πx), v из нуля): у CN
+// ошибка падает вчетверо при вдвое меньших dx и dt, у BTCS при малом dx
+// — вдвое при вдвое меньшем dt
+func TestTwoTemperatureMode(t *testing.T) {
+	m := verifyTwoTemperature
+	mode := mathutils.TwoTemperatureMode{Alpha1: m.Alpha1, Alpha2: m.Alpha2, H: m.H, U0: 1}
+	tests := []struct {
+		theta  float64
+		grids  [][2]float64 // dx, dt
+		ratio  float64
+		coarse float64 // ошибка на первой сетке не больше
+	}{
+		{0.5, [][2]float64{{0.04, 0.004}, {0.02, 0.002}, {0.01, 0.001}}, 4, 2e-3},
+		{1, [][2]float64{{0.005, 0.004}, {0.005, 0.002}, {0.005, 0.001}}, 2, 1e-2},
+	}
+	for _, tc := range tests {
+		t.Run(thetaName(tc.theta), func(t *testing.T) {
+			var prev float64
+			for k, g := range tc.grids {
+				dx, dt := g[0], g[1]
+				nx, nt := int(math.Round(1/dx)), int(math.Round(0.2/dt))
+				lu, lv, err := StreamTwoTemperatureFrom(SineProfile(nx, dx), make([]float64, nx+1), nt, dx, dt, tc.theta, m, nil)
+				if err != nil {
+					t.Fatal(err)
+				}
+				tm := float64(nt) * dt
+				_, eu := mathutils.LevelErrors(lu, dx, tm, mode.U())
+				_, ev := mathutils.LevelErrors(lv, dx, tm, mode.V())
+				e := math.Max(eu, ev)
+				if k == 0 && e > tc.coarse {
+					t.Errorf("dx=%g dt=%g: error %.3g", dx, dt, e)
+				}
+				if k > 0 {
+					if ratio := prev / e; math.Abs(ratio-tc.ratio) > 0.15*tc.ratio {
+						t.Errorf("dx=%g dt=%g: error %.3g → %.3g, ratio %.2f; want %g", dx, dt, prev, e, ratio, tc.ratio)
+					}
+				}
+				prev = e
+			}
+		})
+	}
+}
+
+// При h → ∞ оба поля сливаются в решение одного уравнения с
+// α = (α₁ + α₂)/2 из полусуммы начальных профилей; отличие убывает как 1/h
+func TestTwoTemperatureCollapse(t *testing.T) {
+	var prev float64
+	for _, h := range []float64{1e3, 1e4, 1e5, 1e6} {
+		diff, err := twoTemperatureCollapse(h)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if prev > 0 {
+			if order := math.Log10(prev / diff); math.Abs(order-1) > 0.05 {
+				t.Errorf("h=%g: difference %.3g → %.3g, order %.3f in 1/h", h, prev, diff, order)
+			}
+		}
+		prev = diff
+	}
+	if prev > 1e-5 {
+		t.Errorf("h=1e6: fields differ from the single equation by %.3g", prev)
+	}
+}
+
+// Без обмена поля независимы: каждое — BTCS со своим α (шаг α·dt)
+func TestTwoTemperatureDecoupled(t *testing.T) {
+	const nx, nt, dx, dt = 20, 50, 0.05, 0.002
+	m := TwoTemperature{Alpha1: 1, Alpha2: 0.3}
+	u0, v0 := SineProfile(nx, dx), PulseProfile(nx, dx, 0.25, 0.5)
+	lu, lv, err := StreamTwoTemperatureFrom(u0, v0, nt, dx, dt, 1, m, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for k, tc := range []struct {
+		got   []float64
+		u0    []float64
+		alpha float64
+	}{{lu, u0, m.Alpha1}, {lv, v0, m.Alpha2}} {
+		want, err := StreamBTCSFrom(tc.u0, nt, dx, tc.alpha*dt, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := range want {
+			if math.Abs(tc.got[i]-want[i]) > 1e-14 {
+				t.Errorf("field %d, u[%d] = %v, BTCS %v", k, i, tc.got[i], want[i])
+				break
+			}
+		}
+	}
+}
+
+// Теплоизолированные края: тепло ∫(u + v) сохраняется до округления, а
+// энергия ½∫(u² + v²) не растёт ни на одном шаге при θ = ½ и 1, в том
+// числе при большом r и h·dt
+func TestTwoTemperatureEnergy(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	const nx, dx = 40, 0.025
+	u0, v0 := make([]float64, nx+1), make([]float64, nx+1)
+	for i := range u0 {
+		u0[i], v0[i] = rng.Float64(), -rng.Float64()
+	}
+	for _, theta := range []float64{0.5, 1} {
+		for _, tc := range []struct {
+			dt float64
+			m  TwoTemperature
+		}{
+			{0.0005, TwoTemperature{Alpha1: 1, Alpha2: 0.2, H: 5, Insulated: true}},
+			{0.05, TwoTemperature{Alpha1: 1, Alpha2: 0.01, H: 1e3, Insulated: true}},
+		} {
+			t.Run(fmt.Sprintf("%s/dt=%g", thetaName(theta), tc.dt), func(t *testing.T) {
+				var heat0, energy0, prev float64
+				_, _, err := StreamTwoTemperatureFrom(u0, v0, 200, dx, tc.dt, theta, tc.m, func(n int, u, v []float64) error {
+					heat, energy := TwoTemperatureEnergy(u, v, dx)
+					if n == 0 {
+						heat0, energy0 = heat, energy
+					} else if energy > prev*(1+1e-15) {
+						t.Errorf("step %d: energy %.17g after %.17g", n, energy, prev)
+					}
+					if math.Abs(heat-heat0) > 1e-13*energy0 {
+						t.Errorf("step %d: heat %.17g, initially %.17g", n, heat, heat0)
+					}
+					prev = energy
+					return nil
+				})
+				if err != nil {
+					t.Fatal(err)
+				}
+			})
+		}
+	}
+}
+
+func TestTwoTemperatureRejects(t *testing.T) {
+	u0 := SineProfile(10, 0.1)
+	tests := []struct {
+		name   string
+		u0, v0 []float64
+		m      TwoTemperature
+	}{
+		{"lengths", u0, u0[:10], verifyTwoTemperature},
+		{"one interval", []float64{0, 0}, []float64{0, 0}, verifyTwoTemperature},
+		{"zero α₁", u0, u0, TwoTemperature{Alpha2: 1}},
+		{"infinite α₂", u0, u0, TwoTemperature{Alpha1: 1, Alpha2: math.Inf(1)}},
+		{"negative h", u0, u0, TwoTemperature{Alpha1: 1, Alpha2: 1, H: -1}},
+		{"NaN h", u0, u0, TwoTemperature{Alpha1: 1, Alpha2: 1, H: math.NaN()}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, _, err := StreamTwoTemperatureFrom(tc.u0, tc.v0, 1, 0.1, 0.001, 1, tc.m, nil); err == nil {
+				t.Error("accepted")
+			}
+		})
+	}
+}
@@ -0,0 +1,451 @@
+package solver
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"heat-solver/internal/config"
+	"heat-solver/internal/mathutils"
+)
+
+// Способ сравнения измеренного значения с ожидаемым
+const (
+	VerifyAtMost  = "<="
+	VerifyAtLeast = ">="
+	// |measured − Expected| ≤ Tolerance
+	VerifyNear = "~"
+)
+
+// Проверочный случай метода с известным ответом. Ожидание и допуск
+// задаются при регистрации метода рядом с его описанием: метод без
+// случаев в Verify не проверяется
+type VerifyCase struct {
+	Name string
+	// Что измеряется: ошибка L2, наблюдаемый порядок и т.п.
+	Quantity  string
+	Compare   string
+	Expected  float64
+	Tolerance float64
+	// Измерение; detail — подробности для таблицы (промежуточные значения)
+	Measure func(ctx context.Context, m Method) (value float64, detail string, err error)
+}
+
+// Итог одного случая
+type VerifyResult struct {
+	Method    string
+	Case      string
+	Quantity  string
+	Compare   string
+	Expected  float64
+	Tolerance float64
+	Measured  float64
+	Detail    string
+	Pass      bool
+	// Ошибка измерения; случай при этом не пройден
+	Err     error
+	Runtime time.Duration
+}
+
+type VerifyReport struct {
+	Results []VerifyResult
+	Failed  int
+	// Зарегистрированные методы без проверочных случаев
+	Unverified []string
+	Runtime    time.Duration
+}
+
+func (r VerifyReport) Passed() bool {
+	return r.Failed == 0
+}
+
+// Проверочные случаи всех зарегистрированных методов в порядке
+// регистрации, затем двумерных схем (под именами "2D FTCS", "2D ADI", "2D LOD").
+// Расчёты маленькие: весь набор встроенных схем занимает доли секунды.
+// Прерывается только по ctx
+func Verify(ctx context.Context) (VerifyReport, error) {
+	start := time.Now()
+	var rep VerifyReport
+	for _, m := range Registered() {
+		if len(m.Verification) == 0 {
+			rep.Unverified = append(rep.Unverified, m.Name)
+			continue
+		}
+		if err := rep.run(ctx, m, m.Verification); err != nil {
+			return rep, err
+		}
+	}
+	for _, m := range methods2D {
+		if err := rep.run(ctx, Method{Name: "2D " + m.name}, m.verification); err != nil {
+			return rep, err
+		}
+	}
+	if err := rep.run(ctx, Method{Name: "3D DOUGLAS-GUNN"}, verification3D); err != nil {
+		return rep, err
+	}
+	if err := rep.run(ctx, Method{Name: "CYLINDRICAL"}, verificationCylindrical); err != nil {
+		return rep, err
+	}
+	if err := rep.run(ctx, Method{Name: "SPHERICAL"}, verificationSpherical); err != nil {
+		return rep, err
+	}
+	if err := rep.run(ctx, Method{Name: "STRETCHED"}, verificationStretched); err != nil {
+		return rep, err
+	}
+	if err := rep.run(ctx, Method{Name: "ADAPTIVE"}, verificationAdaptive); err != nil {
+		return rep, err
+	}
+	if err := rep.run(ctx, Method{Name: "STEADY"}, verificationSteady); err != nil {
+		return rep, err
+	}
+	if err := rep.run(ctx, Method{Name: "COMPACT"}, verificationCompact); err != nil {
+		return rep, err
+	}
+	if err := rep.run(ctx, Method{Name: "WIDE"}, verificationWide); err != nil {
+		return rep, err
+	}
+	if err := rep.run(ctx, Method{Name: "ADVECTION"}, verificationAdvection); err != nil {
+		return rep, err
+	}
+	if err := rep.run(ctx, Method{Name: "REACTION"}, verificationReaction); err != nil {
+		return rep, err
+	}
+	if err := rep.run(ctx, Method{Name: "FISHER"}, verificationFisher); err != nil {
+		return rep, err
+	}
+	if err := rep.run(ctx, Method{Name: "CONDUCTIVITY"}, verificationConductivity); err != nil {
+		return rep, err
+	}
+	if err := rep.run(ctx, Method{Name: "PME"}, verificationPorous); err != nil {
+		return rep, err
+	}
+	if err := rep.run(ctx, Method{Name: "SOURCE"}, verificationSource); err != nil {
+		return rep, err
+	}
+	if err := rep.run(ctx, Method{Name: "LAYERS"}, verificationLayers); err != nil {
+		return rep, err
+	}
+	if err := rep.run(ctx, Method{Name: "CATTANEO"}, verificationCattaneo); err != nil {
+		return rep, err
+	}
+	if err := rep.run(ctx, Method{Name: "FRACTIONAL"}, verificationFractional); err != nil {
+		return rep, err
+	}
+	if err := rep.run(ctx, Method{Name: "TWOTEMP"}, verificationTwoTemperature); err != nil {
+		return rep, err
+	}
+	if err := rep.run(ctx, Method{Name: "NETWORK"}, verificationNetwork); err != nil {
+		return rep, err
+	}
+	if err := rep.run(ctx, Method{Name: "MASK"}, verificationMask); err != nil {
+		return rep, err
+	}
+	if err := rep.run(ctx, Method{Name: "SPACE-RICHARDSON"}, verificationSpatialRichardson); err != nil {
+		return rep, err
+	}
+	if err := rep.run(ctx, Method{Name: "FIT"}, verificationFit); err != nil {
+		return rep, err
+	}
+	if err := rep.run(ctx, Method{Name: "NEUMANN"}, verificationNeumann); err != nil {
+		return rep, err
+	}
+	if err := rep.run(ctx, Method{Name: "ROBIN"}, verificationRobin); err != nil {
+		return rep, err
+	}
+	if err := rep.run(ctx, Method{Name: "COMPAT"}, verificationCompat); err != nil {
+		return rep, err
+	}
+	rep.Runtime = time.Since(start)
+	return rep, nil
+}
+
+func (rep *VerifyReport) run(ctx context.Context, m Method, cases []VerifyCase) error {
+	for _, c := range cases {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		caseStart := time.Now()
+		r := VerifyResult{Method: m.Name, Case: c.Name, Quantity: c.Quantity, Compare: c.Compare, Expected: c.Expected, Tolerance: c.Tolerance}
+		r.Measured, r.Detail, r.Err = c.Measure(ctx, m)
+		r.Runtime = time.Since(caseStart)
+		r.Pass = r.Err == nil && c.holds(r.Measured)
+		if !r.Pass {
+			rep.Failed++
+		}
+		rep.Results = append(rep.Results, r)
+	}
+	return nil
+}
+
+// NaN не проходит ни одно сравнение
+func (c VerifyCase) holds(v float64) bool {
+	switch c.Compare {
+	case VerifyAtMost:
+		return v <= c.Expected
+	case VerifyAtLeast:
+		return v >= c.Expected
+	case VerifyNear:
+		return math.Abs(v-c.Expected) <= c.Tolerance
+	}
+	return false
+}
+
+// Ошибка L2 для sin(πx) с нулевыми краями в момент tmax не больше bound
+func SineErrorCase(dx, dt, tmax, bound float64) VerifyCase {
+	return VerifyCase{
+		Name:     fmt.Sprintf("sine dx=%g dt=%g", dx, dt),
+		Quantity: "L2 error",
+		Compare:  VerifyAtMost,
+		Expected: bound,
+		Measure: func(ctx context.Context, m Method) (float64, string, error) {
+			l2, err := verifySineError(ctx, m, dx, dt, tmax)
+			return l2, "", err
+		},
+	}
+}
+
+// Наблюдаемый порядок по dx на sin(πx) за два измельчения: dx делится
+// пополам, dt — на 2^(p/q) по порядкам метода, чтобы обе части ошибки
+// убывали одинаково. Значение — порядок на втором измельчении
+func SineOrderCase(dx, dt, tmax, order, tol float64) VerifyCase {
+	return VerifyCase{
+		Name:      fmt.Sprintf("sine order from dx=%g dt=%g", dx, dt),
+		Quantity:  "observed order",
+		Compare:   VerifyNear,
+		Expected:  order,
+		Tolerance: tol,
+		Measure: func(ctx context.Context, m Method) (float64, string, error) {
+			if m.OrderSpace < 1 || m.OrderTime < 1 {
+				return math.NaN(), "", fmt.Errorf("method %s has no declared order of accuracy", m.Name)
+			}
+			dtFactor := math.Exp2(float64(m.OrderSpace) / float64(m.OrderTime))
+			var errs [3]float64
+			for k := range errs {
+				l2, err := verifySineError(ctx, m, dx/math.Exp2(float64(k)), dt/math.Pow(dtFactor, float64(k)), tmax)
+				if err != nil {
+					return math.NaN(), "", err
+				}
+				errs[k] = l2
+			}
+			first, second := math.Log2(errs[0]/errs[1]), math.Log2(errs[1]/errs[2])
+			return second, fmt.Sprintf("errors %.3g, %.3g, %.3g; orders %.3f, %.3f", errs[0], errs[1], errs[2], first, second), nil
+		},
+	}
+}
+
+// Наблюдаемый порядок по dt на sin(πx) при постоянном dx за два деления
+// dt пополам. Эталон — точное решение полудискретной задачи
+// (semiDiscreteSine), так что в ошибке остаётся только вклад интегратора
+// по времени
+func TimeOrderCase(dx, dt, tmax, order, tol float64) VerifyCase {
+	return VerifyCase{
+		Name:      fmt.Sprintf("time order dx=%g from dt=%g", dx, dt),
+		Quantity:  "observed order in dt",
+		Compare:   VerifyNear,
+		Expected:  order,
+		Tolerance: tol,
+		Measure: func(ctx context.Context, m Method) (float64, string, error) {
+			exact := semiDiscreteSine(dx)
+			var errs [3]float64
+			for k := range errs {
+				res, err := verifyRun(ctx, m, dx, dt/math.Exp2(float64(k)), tmax)
+				if err != nil {
+					return math.NaN(), "", err
+				}
+				errs[k], _ = mathutils.LevelErrors(res.Final, res.Dx, float64(res.Nt)*res.Dt, exact)
+				res.Release()
+			}
+			first, second := math.Log2(errs[0]/errs[1]), math.Log2(errs[1]/errs[2])
+			return second, fmt.Sprintf("errors %.3g, %.3g, %.3g; orders %.3f, %.3f", errs[0], errs[1], errs[2], first, second), nil
+		},
+	}
+}
+
+// Ошибка по времени на sin(πx) — ошибка L2 относительно точного решения
+// полудискретной задачи — не больше bound. Для интегратора, точного по
+// времени, это уровень округления
+func SemiDiscreteCase(dx, dt, tmax, bound float64) VerifyCase {
+	return VerifyCase{
+		Name:     fmt.Sprintf("semi-discrete dx=%g dt=%g", dx, dt),
+		Quantity: "L2 error in time",
+		Compare:  VerifyAtMost,
+		Expected: bound,
+		Measure: func(ctx context.Context, m Method) (float64, string, error) {
+			res, err := verifyRun(ctx, m, dx, dt, tmax)
+			if err != nil {
+				return math.NaN(), "", err
+			}
+			defer res.Release()
+			l2, _ := mathutils.LevelErrors(res.Final, res.Dx, float64(res.Nt)*res.Dt, semiDiscreteSine(dx))
+			spatial, _ := mathutils.LevelErrors(res.Final, res.Dx, float64(res.Nt)*res.Dt, mathutils.Analytical{Alpha: 1})
+			return l2, fmt.Sprintf("error against exp(−π²t)·sin(πx) %.3g", spatial), nil
+		},
+	}
+}
+
+// Точное решение полудискретной задачи du/dt = δ²u/dx² из sin(πx):
+// exp(−λ_h·t)·sin(πx), λ_h = (4/dx²)·sin²(π·dx/2). sin(πx) на узлах —
+// собственный вектор δ²/dx², и решение то же, что у задачи с α = λ_h/π²
+func semiDiscreteSine(dx float64) mathutils.Analytical {
+	s := math.Sin(math.Pi * dx / 2)
+	return mathutils.Analytical{Alpha: 4 * s * s / (dx * dx) / (math.Pi * math.Pi)}
+}
+
+// Баланс тепла (FluxAudit) на sin(πx): относительный небаланс изменения
+// ∫u dx и притока через границы не больше bound
+func HeatBalanceCase(dx, dt, tmax, bound float64) VerifyCase {
+	return VerifyCase{
+		Name:     fmt.Sprintf("heat balance dx=%g dt=%g", dx, dt),
+		Quantity: "relative imbalance",
+		Compare:  VerifyAtMost,
+		Expected: bound,
+		Measure: func(ctx context.Context, m Method) (float64, string, error) {
+			res, err := verifyRun(ctx, m, dx, dt, tmax)
+			if err != nil {
+				return math.NaN(), "", err
+			}
+			defer res.Release()
+			f := res.Flux
+			return f.Relative, fmt.Sprintf("ΔQ %.6g, inflow %.6g", f.Final-f.Initial, f.Inflow), nil
+		},
+	}
+}
+
+// Выход на стационар: при нулевых краях u → 0, и к моменту tmax
+// max|u| не больше bound (точное решение — exp(−π²·tmax))
+func SteadyStateCase(dx, dt, tmax, bound float64) VerifyCase {
+	return VerifyCase{
+		Name:     fmt.Sprintf("steady state t=%g", tmax),
+		Quantity: "max |u|",
+		Compare:  VerifyAtMost,
+		Expected: bound,
+		Measure: func(ctx context.Context, m Method) (float64, string, error) {
+			res, err := verifyRun(ctx, m, dx, dt, tmax)
+			if err != nil {
+				return math.NaN(), "", err
+			}
+			defer res.Release()
+			var peak float64
+			for _, v := range res.Final {
+				peak = max(peak, math.Abs(v))
+			}
+			return peak, fmt.Sprintf("exact %.3g", math.Exp(-math.Pi*math.Pi*float64(res.Nt)*dt)), nil
+		},
+	}
+}
+
+// Схема выше границы устойчивости (r > MaxR) должна быть распознана
+// неустойчивой: StableAt(r) = false и max|G| > 1. Значение — рост max|u|
+// за steps шагов на сетке nx; ошибка округления в старшей моде растёт
+// как |G(π)|ⁿ, так что рост ≥ 1 — неустойчивость видна и в расчёте
+func UnstableCase(r float64, nx, steps int) VerifyCase {
+	return VerifyCase{
+		Name:     fmt.Sprintf("unstable r=%g", r),
+		Quantity: "growth of max |u|",
+		Compare:  VerifyAtLeast,
+		Expected: 1,
+		Measure: func(ctx context.Context, m Method) (float64, string, error) {
+			a, err := AnalyzeStability(m, r, nx)
+			if err != nil {
+				return math.NaN(), "", err
+			}
+			detail := fmt.Sprintf("stable_at=%v max|G|=%.4f", m.StableAt(r), a.MaxAbsG)
+			if m.StableAt(r) || a.Stable {
+				return math.NaN(), detail, fmt.Errorf("r = %g is not flagged as unstable", r)
+			}
+			dx := 1 / float64(nx)
+			dt := r * dx * dx
+			res, err := verifyRun(ctx, m, dx, dt, float64(steps)*dt)
+			if err != nil {
+				return math.NaN(), detail, err
+			}
+			defer res.Release()
+			if res.Diverged {
+				return math.Inf(1), detail + ", diverged", nil
+			}
+			var initial, final float64
+			for i, v := range res.Final {
+				initial, final = max(initial, math.Abs(res.U[0][i])), max(final, math.Abs(v))
+			}
+			return final / initial, detail, nil
+		},
+	}
+}
+
+// Безусловно устойчивая схема далеко за пределом FTCS (r ≫ ½) остаётся
+// ограниченной: значение — рост max|u| за steps шагов на сетке nx, не
+// больше 1. Расхождение — ошибка измерения
+func BoundedCase(r float64, nx, steps int) VerifyCase {
+	return VerifyCase{
+		Name:     fmt.Sprintf("bounded r=%g", r),
+		Quantity: "growth of max |u|",
+		Compare:  VerifyAtMost,
+		Expected: 1,
+		Measure: func(ctx context.Context, m Method) (float64, string, error) {
+			dx := 1 / float64(nx)
+			dt := r * dx * dx
+			res, err := verifyRun(ctx, m, dx, dt, float64(steps)*dt)
+			if err != nil {
+				return math.NaN(), "", err
+			}
+			defer res.Release()
+			if res.Diverged {
+				return math.Inf(1), "", fmt.Errorf("solve with r = %g diverged", r)
+			}
+			var initial, final float64
+			for i, v := range res.Final {
+				initial, final = max(initial, math.Abs(res.U[0][i])), max(final, math.Abs(v))
+			}
+			return final / initial, "", nil
+		},
+	}
+}
+
+// Безусловно устойчивая явная схема при r, где FTCS расходится: рост
+// max|u| за steps шагов на сетке nx не больше 1, а FTCS на тех же шагах
+// обязан разойтись (иначе случай ничего не показывает — ошибка измерения)
+func BeyondFTCSCase(r float64, nx, steps int) VerifyCase {
+	bounded := BoundedCase(r, nx, steps)
+	bounded.Name = fmt.Sprintf("bounded r=%g where FTCS diverges", r)
+	measure := bounded.Measure
+	bounded.Measure = func(ctx context.Context, m Method) (float64, string, error) {
+		ftcs, ok := Lookup("FTCS")
+		if !ok {
+			return math.NaN(), "", fmt.Errorf("FTCS is not registered")
+		}
+		dx := 1 / float64(nx)
+		dt := r * dx * dx
+		res, err := verifyRun(ctx, ftcs, dx, dt, float64(steps)*dt)
+		if err != nil {
+			return math.NaN(), "", err
+		}
+		diverged := res.Diverged
+		res.Release()
+		if !diverged {
+			return math.NaN(), "", fmt.Errorf("FTCS did not diverge at r = %g", r)
+		}
+		growth, _, err := measure(ctx, m)
+		return growth, "FTCS diverged", err
+	}
+	return bounded
+}
+
+// Расчёт в режиме final-only из sin(πx)
+func verifyRun(ctx context.Context, m Method, dx, dt, tmax float64) (*Result, error) {
+	p := config.Params{Method: m.Name, Dx: dx, Dt: dt, Tmax: tmax, Storage: config.StorageFinalOnly, Precision: config.PrecisionFloat64}
+	return Run(ctx, p, Hooks{})
+}
+
+func verifySineError(ctx context.Context, m Method, dx, dt, tmax float64) (float64, error) {
+	res, err := verifyRun(ctx, m, dx, dt, tmax)
+	if err != nil {
+		return math.NaN(), err
+	}
+	defer res.Release()
+	if res.Diverged {
+		return math.NaN(), fmt.Errorf("solve with dx=%g dt=%g diverged", dx, dt)
+	}
+	l2, _ := mathutils.LevelErrors(res.Final, res.Dx, float64(res.Nt)*res.Dt, mathutils.Analytical{Alpha: 1})
+	return l2, nil
+}
@@ -0,0 +1,36 @@
+package solver
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+// Весь набор Verify (то же, что `head verify`) под go test: каждый
+// проверочный случай — отдельный подтест с измеренным значением в
+// сообщении об ошибке
+func TestVerify(t *testing.T) {
+	if testing.Short() {
+		t.Skip("the verification suite takes a few seconds")
+	}
+	rep, err := Verify(context.Background())
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if len(rep.Results) == 0 {
+		t.Fatal("Verify ran no cases")
+	}
+	if len(rep.Unverified) > 0 {
+		t.Errorf("methods without verification cases: %v", rep.Unverified)
+	}
+	for _, r := range rep.Results {
+		t.Run(fmt.Sprintf("%s/%s", r.Method, r.Case), func(t *testing.T) {
+			switch {
+			case r.Err != nil:
+				t.Errorf("%s: %v", r.Quantity, r.Err)
+			case !r.Pass:
+				t.Errorf("%s = %g, want %s %g (tolerance %g); %s", r.Quantity, r.Measured, r.Compare, r.Expected, r.Tolerance, r.Detail)
+			}
+		})
+	}
+}
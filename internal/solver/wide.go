@@ -0,0 +1,242 @@
+package solver
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+
+	"heat-solver/internal/config"
+	"heat-solver/internal/mathutils"
+)
+
+// Широкий пятиточечный шаблон четвёртого порядка по x с θ-схемой по
+// времени: D₄u_i = (−u_{i−2} + 16u_{i−1} − 30u_i + 16u_{i+1} − u_{i+2})/12,
+// (u^{n+1} − u^n)/dt = α·D₄(θu^{n+1} + (1−θ)u^n)/dx². У узлов 1 и nx−1
+// шаблон выходит за край; значение за краем берётся нечётным отражением
+// u_{−1} = 2u_0 − u_1. На стенке с постоянным значением u_t = 0, значит
+// и u_xx = 0, и ошибка отражения O(dx⁴) — порядок сохраняется. При θ = 0
+// (FTCS) шаг явный, иначе на шаге решается пятидиагональная система
+func SolveWideFTCS(nx, nt int, dx, dt float64) [][]float64 {
+	return SolveWideThetaFrom(SineProfile(nx, dx), nt, dx, dt, 1, 0)
+}
+
+// Широкий шаблон с BTCS из sin(πx)
+func SolveWideBTCS(nx, nt int, dx, dt float64) [][]float64 {
+	return SolveWideThetaFrom(SineProfile(nx, dx), nt, dx, dt, 1, 1)
+}
+
+// Широкий шаблон с θ-схемой и заданным начальным профилем u0
+func SolveWideThetaFrom(u0 []float64, nt int, dx, dt, alpha, theta float64) [][]float64 {
+	u := newGrid(nt+1, len(u0))
+	wideScheme(u0, nt, dx, dt, alpha, theta, u, nil)
+	return u
+}
+
+// Широкий шаблон с хранением двух слоёв; каждый слой передаётся в emit
+func StreamWideThetaFrom(u0 []float64, nt int, dx, dt, alpha, theta float64, emit EmitFunc) ([]float64, error) {
+	u := newGrid(2, len(u0))
+	if err := wideScheme(u0, nt, dx, dt, alpha, theta, u, emit); err != nil {
+		return nil, err
+	}
+	return level(u, nt), nil
+}
+
+// Символ D₄ на моде θ: 4s²(1 + s²/3), s = sin(θ/2); наибольший — 16/3
+// на старшей моде, отсюда предел r ≤ 3/(8(1−2θ)) при θ < ½
+const wideSymbolMax = 16.0 / 3
+
+func wideMaxR(theta float64) float64 {
+	if theta >= 0.5 {
+		return 0
+	}
+	return 2 / ((1 - 2*theta) * wideSymbolMax)
+}
+
+func wideScheme(u0 []float64, nt int, dx, dt, alpha, theta float64, u [][]float64, emit EmitFunc) error {
+	nx := len(u0) - 1
+	r := alpha * dt / (dx * dx)
+	name := thetaName(theta) + " (wide stencil)"
+	warnUnstable(name, r, wideMaxR(theta))
+	slog.Info("Starting "+name+" solver", "nx", nx, "nt", nt, "dx", dx, "dt", dt, "r", r)
+
+	if err := start(u, u0, emit); err != nil {
+		return err
+	}
+
+	// ext — слой с отражёнными узлами за краями: ext[k] = u_{k−1}
+	ext := pool64.get(nx + 3)
+	defer pool64.put(ext)
+	lap := pool64.get(nx + 1)
+	defer pool64.put(lap)
+	explicit := (1 - theta) * r
+
+	var p *penta
+	var d []float64
+	implicit := theta * r
+	if theta > 0 {
+		n := nx - 1
+		e, a, b, c, f, ws := wideSystem(n, implicit)
+		defer pool64.put(ws)
+		p = newPenta(n)
+		defer p.close()
+		p.factor(e, a, b, c, f)
+		d = pool64.get(n)
+		defer pool64.put(d)
+	}
+
+	for n := 0; n < nt; n++ {
+		cur, next := level(u, n), level(u, n+1)
+		next[0], next[nx] = 0, 0
+		wideLaplacian(cur, ext, lap)
+		if p == nil {
+			for i := 1; i < nx; i++ {
+				next[i] = cur[i] + r*lap[i]
+			}
+		} else {
+			for i := 1; i < nx; i++ {
+				d[i-1] = cur[i] + explicit*lap[i]
+			}
+			// Краевые значения нового слоя: в строке узла 1 вес u_0 —
+			// (16 − 2)/12 с учётом отражения, в строке узла 2 — −1/12
+			d[0] += implicit * 14 / 12 * next[0]
+			d[nx-2] += implicit * 14 / 12 * next[nx]
+			if nx > 2 {
+				d[1] -= implicit / 12 * next[0]
+				d[nx-3] -= implicit / 12 * next[nx]
+			}
+			p.solve(d, next[1:nx])
+		}
+		if err := emitLevel(emit, n+1, next); err != nil {
+			return err
+		}
+	}
+
+	slog.Info(name + " solver finished successfully")
+	return nil
+}
+
+// D₄u во внутренних узлах cur в lap[1:nx] (без деления на dx²); ext —
+// рабочий буфер из nx+3 значений для отражённых узлов
+func wideLaplacian(cur, ext, lap []float64) {
+	nx := len(cur) - 1
+	copy(ext[1:nx+2], cur)
+	ext[0] = 2*cur[0] - cur[1]
+	ext[nx+2] = 2*cur[nx] - cur[nx-1]
+	for i := 1; i < nx; i++ {
+		k := i + 1
+		lap[i] = (-ext[k-2] + 16*ext[k-1] - 30*ext[k] + 16*ext[k+1] - ext[k+2]) / 12
+	}
+}
+
+// Диагонали матрицы I − w·D₄ для n внутренних узлов в одном буфере из
+// пула. Отражение за краем добавляет +w/12 к диагонали первой и
+// последней строк
+func wideSystem(n int, w float64) (e, a, b, c, f, ws []float64) {
+	ws = pool64.get(5 * n)
+	e, a, b, c, f = ws[:n:n], ws[n:2*n:2*n], ws[2*n:3*n:3*n], ws[3*n:4*n:4*n], ws[4*n:]
+	for i := range b {
+		e[i], a[i], b[i], c[i], f[i] = w/12, -16*w/12, 1+30*w/12, -16*w/12, w/12
+	}
+	b[0] -= w / 12
+	b[n-1] -= w / 12
+	return e, a, b, c, f, ws
+}
+
+// Метод FTCS, BTCS или CN с широким шаблоном по x, как при
+// Params.SpatialOrder = 4 и Params.Stencil = wide
+func WideMethod(m Method) (Method, error) {
+	if m.Adaptive {
+		return Method{}, &config.ValidationError{Field: "stencil", Message: "the wide stencil is not available with adaptive time steps"}
+	}
+	if m.Startup > 0 {
+		return Method{}, &config.ValidationError{Field: "stencil", Message: "the wide stencil is not available with the Rannacher startup"}
+	}
+	if m.Reaction != 0 {
+		return Method{}, &config.ValidationError{Field: "stencil", Message: "the wide stencil is not available with a reaction term"}
+	}
+	if !m.Source.IsZero() {
+		return Method{}, &config.ValidationError{Field: "stencil", Message: "the wide stencil is not available with a source term"}
+	}
+	var theta float64
+	switch m.Name {
+	case "FTCS":
+		theta = 0
+	case "BTCS":
+		theta = 1
+	case "CN":
+		theta = 0.5
+	default:
+		return Method{}, &config.ValidationError{Field: "stencil", Message: fmt.Sprintf("the wide stencil is available for FTCS, BTCS and CN, not %s", m.Name)}
+	}
+	m.OrderSpace = 4
+	m.Description += "; fourth-order five-point differences in x"
+	m.MaxR = wideMaxR(theta)
+	if m.MaxR > 0 {
+		m.StabilityBound = fmt.Sprintf("r = dt/dx² ≤ %g", m.MaxR)
+	}
+	m.Amplification = WideAmplification(theta)
+	// Матрица шага пятидиагональная; оценка κ и проверка невязки
+	// рассчитаны на трёхдиагональные
+	m.System = nil
+	m.wide = true
+	m.Solve = func(u0 []float64, nt int, dx, dt float64) [][]float64 {
+		return SolveWideThetaFrom(u0, nt, dx, dt, 1, theta)
+	}
+	m.Stream = func(u0 []float64, nt int, dx, dt float64, emit EmitFunc) ([]float64, error) {
+		return StreamWideThetaFrom(u0, nt, dx, dt, 1, theta, emit)
+	}
+	m.solveInto = func(u0 []float64, nt int, dx, dt float64, u [][]float64) error {
+		return wideScheme(u0, nt, dx, dt, 1, theta, u, nil)
+	}
+	return m, nil
+}
+
+// Множитель перехода θ-схемы с широким шаблоном и весом w:
+// G = (1 − (1−w)q)/(1 + wq), q = 4r·s²(1 + s²/3), s = sin(θ/2)
+func WideAmplification(w float64) AmplificationFunc {
+	return func(r, theta float64) float64 {
+		s := math.Sin(theta / 2)
+		q := 4 * r * s * s * (1 + s*s/3)
+		return (1 - (1-w)*q) / (1 + w*q)
+	}
+}
+
+// Проверочные случаи широкого шаблона; в Verify — под именем WIDE.
+// r = 0.01 на первой сетке — далеко от предела FTCS 3/8
+var verificationWide = []VerifyCase{
+	wideOrderCase("FTCS", 0, 1, 0.1, 0.0001, 0.1, 4, 0.15),
+	wideOrderCase("BTCS", 1, 1, 0.1, 0.0001, 0.1, 4, 0.15),
+	wideOrderCase("CN", 0.5, 2, 0.1, 0.001, 0.1, 4, 0.15),
+}
+
+// Наблюдаемый порядок широкого шаблона по dx на sin(πx) за два деления
+// dx пополам; dt делится на 2^(4/orderTime), чтобы ошибка по времени
+// убывала так же, как по пространству
+func wideOrderCase(name string, theta float64, orderTime int, dx, dt, tmax, order, tol float64) VerifyCase {
+	return VerifyCase{
+		Name:      fmt.Sprintf("%s sine order from dx=%g dt=%g", name, dx, dt),
+		Quantity:  "observed order",
+		Compare:   VerifyNear,
+		Expected:  order,
+		Tolerance: tol,
+		Measure: func(ctx context.Context, _ Method) (float64, string, error) {
+			dtFactor := math.Exp2(4 / float64(orderTime))
+			var errs [3]float64
+			for k := range errs {
+				if err := ctx.Err(); err != nil {
+					return math.NaN(), "", err
+				}
+				h, tau := dx/math.Exp2(float64(k)), dt/math.Pow(dtFactor, float64(k))
+				nx, nt := int(math.Round(1/h)), int(math.Round(tmax/tau))
+				u, err := StreamWideThetaFrom(SineProfile(nx, h), nt, h, tau, 1, theta, nil)
+				if err != nil {
+					return math.NaN(), "", err
+				}
+				errs[k], _ = mathutils.LevelErrors(u, h, float64(nt)*tau, mathutils.Analytical{Alpha: 1})
+			}
+			first, second := math.Log2(errs[0]/errs[1]), math.Log2(errs[1]/errs[2])
+			return second, fmt.Sprintf("errors %.3g, %.3g, %.3g; orders %.3f, %.3f", errs[0], errs[1], errs[2], first, second), nil
+		},
+	}
+}
@@ -0,0 +1,227 @@
+package solver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"heat-solver/internal/config"
+	"heat-solver/internal/mathutils"
+)
+
+// Разложение пятидиагональной матрицы против умножения обратно: невязка
+// на уровне округления для матриц с диагональным преобладанием и для
+// матрицы широкого шаблона при больших r
+func TestPenta(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	type system struct {
+		name          string
+		e, a, b, c, f []float64
+	}
+	var systems []system
+	for _, n := range []int{1, 2, 3, 4, 7, 100} {
+		e, a, b, c, f := make([]float64, n), make([]float64, n), make([]float64, n), make([]float64, n), make([]float64, n)
+		for i := range n {
+			e[i], a[i], c[i], f[i] = rng.NormFloat64(), rng.NormFloat64(), rng.NormFloat64(), rng.NormFloat64()
+			b[i] = 5 + math.Abs(e[i]) + math.Abs(a[i]) + math.Abs(c[i]) + math.Abs(f[i])
+		}
+		systems = append(systems, system{fmt.Sprintf("dominant n=%d", n), e, a, b, c, f})
+	}
+	for _, w := range []float64{0.5, 100, 1e4} {
+		e, a, b, c, f, _ := wideSystem(50, w)
+		systems = append(systems, system{fmt.Sprintf("wide w=%g", w), e, a, b, c, f})
+	}
+	for _, s := range systems {
+		t.Run(s.name, func(t *testing.T) {
+			n := len(s.b)
+			want, d := make([]float64, n), make([]float64, n)
+			for i := range want {
+				want[i] = rng.NormFloat64()
+			}
+			// d = A·want
+			for i := range n {
+				d[i] = s.b[i] * want[i]
+				if i >= 1 {
+					d[i] += s.a[i] * want[i-1]
+				}
+				if i >= 2 {
+					d[i] += s.e[i] * want[i-2]
+				}
+				if i+1 < n {
+					d[i] += s.c[i] * want[i+1]
+				}
+				if i+2 < n {
+					d[i] += s.f[i] * want[i+2]
+				}
+			}
+			p := newPenta(n)
+			defer p.close()
+			p.factor(s.e, s.a, s.b, s.c, s.f)
+			x := make([]float64, n)
+			p.solve(d, x)
+			for i := range x {
+				if math.Abs(x[i]-want[i]) > 1e-9*(1+math.Abs(want[i])) {
+					t.Fatalf("x[%d] = %v, want %v", i, x[i], want[i])
+				}
+			}
+		})
+	}
+}
+
+// Нечётное отражение за краем точно для sin(πx), так что это собственный
+// вектор шага: множитель — WideAmplification(θ) при угле π·dx
+func TestWideSineMode(t *testing.T) {
+	tests := []struct {
+		theta float64
+		nx    int
+		r     float64
+	}{
+		{0, 20, 0.3},
+		{0.5, 10, 5},
+		{1, 40, 50},
+		{1, 3, 1},
+	}
+	for _, tc := range tests {
+		t.Run(fmt.Sprintf("θ=%g/nx=%d/r=%g", tc.theta, tc.nx, tc.r), func(t *testing.T) {
+			const nt = 6
+			dx := 1 / float64(tc.nx)
+			g := WideAmplification(tc.theta)(tc.r, math.Pi*dx)
+			u := SolveWideThetaFrom(SineProfile(tc.nx, dx), nt, dx, tc.r*dx*dx, 1, tc.theta)
+			for n := range u {
+				scale := math.Pow(g, float64(n))
+				for i, v := range u[n] {
+					if math.Abs(v-scale*u[0][i]) > 1e-14 {
+						t.Fatalf("level %d, node %d: %v, want G^n·u0 = %v", n, i, v, scale*u[0][i])
+					}
+				}
+			}
+		})
+	}
+}
+
+// Через Run со Stencil = wide ошибка на sin(πx) падает в 16 раз при
+// делении dx пополам у FTCS (r = 0.01 — вдали от предела), BTCS и CN; dt
+// уменьшается так, чтобы ошибка по времени убывала не медленнее
+func TestWideSpatialOrder(t *testing.T) {
+	tests := []struct {
+		method   string
+		dt       float64
+		dtFactor float64
+	}{
+		{"FTCS", 0.0001, 16},
+		{"BTCS", 0.0001, 16},
+		{"CN", 0.001, 4},
+	}
+	for _, tc := range tests {
+		t.Run(tc.method, func(t *testing.T) {
+			var prev float64
+			dt := tc.dt
+			for k, nx := range []int{10, 20, 40} {
+				dx := 1 / float64(nx)
+				p := config.Params{Method: tc.method, Dx: dx, Dt: dt, Tmax: 0.1, SpatialOrder: 4, Stencil: config.StencilWide, Storage: config.StorageFinalOnly}.Normalize()
+				res, err := Run(context.Background(), p, Hooks{})
+				if err != nil {
+					t.Fatal(err)
+				}
+				l2, _ := mathutils.LevelErrors(res.Final, dx, float64(res.Nt)*dt, mathutils.Analytical{Alpha: 1})
+				res.Release()
+				if k > 0 {
+					if order := math.Log2(prev / l2); math.Abs(order-4) > 0.15 {
+						t.Errorf("nx=%d: error %.3g → %.3g, observed order %.2f", nx, prev, l2, order)
+					}
+				}
+				prev = l2
+				dt /= tc.dtFactor
+			}
+		})
+	}
+}
+
+// Предел явного шага с широким шаблоном — r ≤ 3/8, а не ½
+func TestWideExplicitLimit(t *testing.T) {
+	const nx, nt = 40, 4000
+	dx := 1.0 / nx
+	for _, tc := range []struct {
+		r        float64
+		unstable bool
+	}{
+		{0.37, false},
+		{0.38, true},
+	} {
+		t.Run(fmt.Sprintf("r=%g", tc.r), func(t *testing.T) {
+			warnings := captureWarnings(t)
+			u0 := SineProfile(nx, dx)
+			u0[nx/2] += 1e-6
+			last, err := StreamWideThetaFrom(u0, nt, dx, tc.r*dx*dx, 1, 0, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var peak float64
+			for _, v := range last {
+				peak = max(peak, math.Abs(v))
+			}
+			if grew := !(peak <= 1); grew != tc.unstable {
+				t.Errorf("max |u| = %.3g after %d steps, unstable: %v", peak, nt, tc.unstable)
+			}
+			if warned := strings.Contains(warnings.String(), "may be unstable"); warned != tc.unstable {
+				t.Errorf("warning %v, want %v", warned, tc.unstable)
+			}
+		})
+	}
+}
+
+func TestWideMethod(t *testing.T) {
+	rannacher, err := RannacherMethod(mustLookup(t, "CN"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sine, err := mathutils.ParseSource(mathutils.SourceSine)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sourced, err := SourceMethod(mustLookup(t, "CN"), sine)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name  string
+		m     Method
+		maxR  float64
+		field string // поле ошибки; пусто — метод принят
+	}{
+		{"FTCS", mustLookup(t, "FTCS"), 0.375, ""},
+		{"BTCS", mustLookup(t, "BTCS"), 0, ""},
+		{"CN", mustLookup(t, "CN"), 0, ""},
+		{"RK45", mustLookup(t, "RK45"), 0, "stencil"},
+		{"BDF2", mustLookup(t, "BDF2"), 0, "stencil"},
+		{"CN with Rannacher startup", rannacher, 0, "stencil"},
+		{"CN with a source", sourced, 0, "stencil"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			m, err := WideMethod(tc.m)
+			if tc.field != "" {
+				var verr *config.ValidationError
+				if !errors.As(err, &verr) || verr.Field != tc.field {
+					t.Fatalf("error %v, want a validation error on %s", err, tc.field)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if m.OrderSpace != 4 || math.Abs(m.MaxR-tc.maxR) > 1e-15 || m.System != nil {
+				t.Errorf("order %d, max r %v, system %v; want 4 and %v without a tridiagonal system", m.OrderSpace, m.MaxR, m.System != nil, tc.maxR)
+			}
+			// Компактная разность с широким шаблоном не сочетается
+			var verr *config.ValidationError
+			if _, err := CompactMethod(m); !errors.As(err, &verr) || verr.Field != "stencil" {
+				t.Errorf("CompactMethod on a wide method: %v", err)
+			}
+		})
+	}
+}
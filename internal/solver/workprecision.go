@@ -0,0 +1,136 @@
+package solver
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"heat-solver/internal/config"
+	"heat-solver/internal/mathutils"
+)
+
+// Исход ступени лестницы WorkPrecision
+const (
+	WorkOK = "ok"
+	// Шаг нарушает условие устойчивости явной схемы, расчёт не запускался
+	WorkUnstable = "unstable"
+	WorkDiverged = "diverged"
+	// Расчёт не уложился бы в оставшийся бюджет времени
+	WorkBudget = "budget"
+)
+
+// Точка диаграммы «работа–точность»: ошибка L2 в момент Nt·Dt
+// относительно точного решения для sin(πx) и время расчёта. Для
+// Status ≠ WorkOK Error и Runtime нулевые
+type WorkPoint struct {
+	Method  string
+	Level   int
+	Dx, Dt  float64
+	Nx, Nt  int
+	Error   float64
+	Runtime time.Duration
+	Status  string
+}
+
+// Время, которое набирают повторами короткие расчёты: берётся наименьшее
+// из повторов, иначе в точке одни накладные расходы и шум
+const (
+	workMinTiming  = 20 * time.Millisecond
+	workMaxRepeats = 5
+)
+
+// Лестница расчётов для каждого метода: на ступени k dt = p.Dt/2^k, а
+// число интервалов nx растёт как 2^(k·q/p) по порядкам метода, так что
+// вклады dx и dt в ошибку убывают одинаково (у CN dx делится пополам
+// вместе с dt, у BTCS и FTCS — на √2, и r у FTCS не меняется).
+// Ступени идут по очереди для всех методов. Неустойчивые сочетания
+// помечаются WorkUnstable, разошедшиеся — WorkDiverged; ступень, которая
+// по времени предыдущей (пропорционально nx·nt) не уложится в budget,
+// и все следующие ступени метода помечаются WorkBudget. budget ≤ 0 — без
+// ограничения
+func WorkPrecision(ctx context.Context, methods []string, p config.Params, levels int, budget time.Duration) ([]WorkPoint, error) {
+	if levels < 1 {
+		return nil, fmt.Errorf("levels must be positive, got %d", levels)
+	}
+	ms := make([]Method, len(methods))
+	for i, name := range methods {
+		m, ok := Lookup(name)
+		if !ok {
+			return nil, &config.ValidationError{Field: "method", Message: fmt.Sprintf("unknown method %q", name)}
+		}
+		if m.OrderSpace < 1 || m.OrderTime < 1 {
+			return nil, fmt.Errorf("method %s has no declared order of accuracy", m.Name)
+		}
+		ms[i] = m
+	}
+	nx0, _ := p.Normalize().Grid()
+	exact := mathutils.Analytical{Alpha: 1}
+
+	start := time.Now()
+	// Последний расчёт метода: время и nx·nt для оценки следующего
+	lastRuntime := make([]time.Duration, len(ms))
+	lastCells := make([]float64, len(ms))
+	outOfBudget := make([]bool, len(ms))
+
+	var points []WorkPoint
+	for k := range levels {
+		for i, m := range ms {
+			nx := int(math.Round(float64(nx0) * math.Exp2(float64(k*m.OrderTime)/float64(m.OrderSpace))))
+			q := config.Params{
+				Method:    m.Name,
+				Dx:        1 / float64(nx),
+				Dt:        p.Dt / math.Exp2(float64(k)),
+				Tmax:      p.Tmax,
+				Storage:   config.StorageFinalOnly,
+				Precision: config.PrecisionFloat64,
+			}
+			_, nt := q.Grid()
+			pt := WorkPoint{Method: m.Name, Level: k, Dx: q.Dx, Dt: q.Dt, Nx: nx, Nt: nt}
+			cells := float64(nx+1) * float64(nt)
+			if !outOfBudget[i] && budget > 0 && lastCells[i] > 0 {
+				estimate := time.Duration(float64(lastRuntime[i]) * cells / lastCells[i])
+				outOfBudget[i] = time.Since(start)+estimate > budget
+			}
+			switch {
+			case outOfBudget[i]:
+				pt.Status = WorkBudget
+			case !m.StableAt(q.Dt / (q.Dx * q.Dx)):
+				pt.Status = WorkUnstable
+			default:
+				if err := workPoint(ctx, q, exact, &pt); err != nil {
+					return points, err
+				}
+				lastRuntime[i], lastCells[i] = pt.Runtime, cells
+			}
+			points = append(points, pt)
+		}
+	}
+	return points, nil
+}
+
+// Расчёт ступени с повторами до workMinTiming; время — наименьшее
+func workPoint(ctx context.Context, q config.Params, exact mathutils.Reference, pt *WorkPoint) error {
+	var total time.Duration
+	for rep := 0; rep < workMaxRepeats && total < workMinTiming; rep++ {
+		res, err := Run(ctx, q, Hooks{})
+		if err != nil {
+			return err
+		}
+		total += res.Runtime
+		if rep == 0 || res.Runtime < pt.Runtime {
+			pt.Runtime = res.Runtime
+		}
+		if rep == 0 {
+			if res.Diverged {
+				res.Release()
+				pt.Status, pt.Runtime = WorkDiverged, 0
+				return nil
+			}
+			pt.Error, _ = mathutils.LevelErrors(res.Final, q.Dx, float64(pt.Nt)*q.Dt, exact)
+		}
+		res.Release()
+	}
+	pt.Status = WorkOK
+	return nil
+}
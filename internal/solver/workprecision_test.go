@@ -0,0 +1,99 @@
+package solver
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"heat-solver/internal/config"
+)
+
+// Лестница из четырёх ступеней: dt делится пополам, nx растёт по
+// порядкам метода, неустойчивый FTCS не запускается. CN доминирует BTCS:
+// для каждой точки BTCS есть точка CN с меньшей ошибкой и не большей
+// работой nx·nt (по времени на одном ядре сравнивать ненадёжно)
+func TestWorkPrecision(t *testing.T) {
+	const levels = 4
+	p := config.Params{Dx: 0.1, Dt: 0.01, Tmax: 0.1}
+	points, err := WorkPrecision(context.Background(), []string{"CN", "BTCS", "FTCS"}, p, levels, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(points) != 3*levels {
+		t.Fatalf("%d points, want %d", len(points), 3*levels)
+	}
+	growth := map[string]float64{"CN": 2, "BTCS": math.Sqrt2, "FTCS": math.Sqrt2}
+	byMethod := map[string][]WorkPoint{}
+	for i, pt := range points {
+		wantMethod := []string{"CN", "BTCS", "FTCS"}[i%3]
+		if pt.Method != wantMethod || pt.Level != i/3 {
+			t.Fatalf("point %d is %s level %d, want %s level %d", i, pt.Method, pt.Level, wantMethod, i/3)
+		}
+		wantNx := int(math.Round(10 * math.Pow(growth[pt.Method], float64(pt.Level))))
+		if pt.Dt != p.Dt/math.Exp2(float64(pt.Level)) || pt.Nx != wantNx || pt.Dx != 1/float64(pt.Nx) ||
+			pt.Nt != int(math.Round(p.Tmax/pt.Dt)) {
+			t.Errorf("%s level %d: dx %g (nx %d), dt %g (nt %d); want nx %d", pt.Method, pt.Level, pt.Dx, pt.Nx, pt.Dt, pt.Nt, wantNx)
+		}
+		wantStatus := WorkOK
+		if pt.Method == "FTCS" {
+			wantStatus = WorkUnstable
+		}
+		if pt.Status != wantStatus || (pt.Status == WorkOK) != (pt.Error > 0 && pt.Runtime > 0) {
+			t.Errorf("%s level %d: status %s, error %g, runtime %v; want %s", pt.Method, pt.Level, pt.Status, pt.Error, pt.Runtime, wantStatus)
+		}
+		byMethod[pt.Method] = append(byMethod[pt.Method], pt)
+	}
+	for _, b := range byMethod["BTCS"] {
+		dominated := false
+		for _, c := range byMethod["CN"] {
+			dominated = dominated || c.Error < b.Error && c.Nx*c.Nt <= b.Nx*b.Nt
+		}
+		if !dominated {
+			t.Errorf("BTCS level %d (error %.3g, work %d) is not dominated by CN", b.Level, b.Error, b.Nx*b.Nt)
+		}
+	}
+	for k := 1; k < levels; k++ {
+		if cn := byMethod["CN"]; cn[k].Error >= cn[k-1].Error/3 {
+			t.Errorf("CN error %.3g → %.3g, want second order", cn[k-1].Error, cn[k].Error)
+		}
+	}
+}
+
+// Бюджет в наносекунду исчерпан первой ступенью: остальные помечены
+// WorkBudget и не считаются
+func TestWorkPrecisionBudget(t *testing.T) {
+	p := config.Params{Dx: 0.1, Dt: 0.01, Tmax: 0.1}
+	points, err := WorkPrecision(context.Background(), []string{"BTCS"}, p, 4, time.Nanosecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, pt := range points {
+		want := WorkBudget
+		if pt.Level == 0 {
+			want = WorkOK
+		}
+		if pt.Status != want || want == WorkBudget && (pt.Error != 0 || pt.Runtime != 0) {
+			t.Errorf("level %d: status %s, error %g, runtime %v; want %s", pt.Level, pt.Status, pt.Error, pt.Runtime, want)
+		}
+	}
+}
+
+func TestWorkPrecisionRejects(t *testing.T) {
+	p := config.Params{Dx: 0.1, Dt: 0.01, Tmax: 0.1}
+	tests := []struct {
+		name    string
+		methods []string
+		levels  int
+	}{
+		{"no levels", []string{"CN"}, 0},
+		{"unknown method", []string{"CN", "NOPE"}, 2},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := WorkPrecision(context.Background(), tc.methods, p, tc.levels, 0); err == nil {
+				t.Error("accepted")
+			}
+		})
+	}
+}
@@ -12,7 +12,7 @@ def plot_results(csv_file, method_name):
         return
     
     print(f"Загрузка данных из {csv_file}...")
-    df = pd.read_csv(csv_file)
+    df = pd.read_csv(csv_file, comment='#')
 
     times = sorted(df['t'].unique())
     print(f"Найдено {len(times)} временных слоёв")
@@ -127,7 +127,7 @@ def compare_methods(csv_files, method_names):
             print(f"Предупреждение: {csv_file} не найден, пропускаем")
             continue
             
-        df = pd.read_csv(csv_file)
+        df = pd.read_csv(csv_file, comment='#')
         final_t = df['t'].max()
         final_data = df[df['t'] == final_t].sort_values('x')
         
@@ -148,7 +148,7 @@ def compare_methods(csv_files, method_names):
         axes[1, 1].bar(idx, linf_error, color=color, label=method)
     
     if csv_files:
-        df = pd.read_csv(csv_files[0])
+        df = pd.read_csv(csv_files[0], comment='#')
         final_t = df['t'].max()
         final_data = df[df['t'] == final_t].sort_values('x')
         axes[0, 0].plot(final_data['x'], final_data['u_exact'], 
@@ -0,0 +1,7 @@
+// Пакет web — статические файлы веб-интерфейса, встроенные в сервер
+package web
+
+import "embed"
+
+//go:embed index.html script.js style.css background.png
+var FS embed.FS
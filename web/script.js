@@ -1,24 +1,84 @@
-document.getElementById("run").addEventListener("click", async () => {
-  const method = document.getElementById("method").value;
-  const dx = document.getElementById("dx").value;
-  const dt = document.getElementById("dt").value;
-  const tmax = document.getElementById("tmax").value;
+const sourceSelect = document.getElementById("source");
+sourceSelect.addEventListener("change", () => {
+  document.getElementById("laserControls").hidden = sourceSelect.value !== "laser";
+});
 
-  const res = await fetch(`/simulate?method=${method}&dx=${dx}&dt=${dt}&tmax=${tmax}`);
-  const data = await res.json();
+document.getElementById("run").addEventListener("click", async () => {
+  const query = new URLSearchParams({
+    method: document.getElementById("method").value,
+    dx: document.getElementById("dx").value,
+    dt: document.getElementById("dt").value,
+    tmax: document.getElementById("tmax").value,
+  });
+  const source = sourceSelect.value;
+  if (source === "laser") {
+    query.set("source", source);
+    query.set("laser_power", document.getElementById("laserPower").value);
+    query.set("laser_speed", document.getElementById("laserSpeed").value);
+    query.set("laser_width", document.getElementById("laserWidth").value);
+    query.set("laser_start", document.getElementById("laserStart").value);
+  }
 
-  const u = data.u;
-  const dxVal = data.dx;
-  const dtVal = data.dt;
-  animateHeat(u, dxVal, dtVal);
+  const res = await fetch(`/api/v1/frames?${query}`);
+  if (!res.ok) {
+    console.error(`Simulation failed: ${await res.text()}`);
+    return;
+  }
+  const frames = parseFrames(await res.arrayBuffer());
+  animateHeat(frames.u, frames.dx, frames.dt * frames.stride, source !== "");
 });
 
-function animateHeat(u, dx, dt) {
+// Двоичные кадры /api/v1/frames: 64-байтовый заголовок, затем значения
+// float32 (u = offset + scale·v) или float64
+function parseFrames(buf) {
+  const view = new DataView(buf);
+  const magic = new TextDecoder().decode(new Uint8Array(buf, 0, 8));
+  if (magic !== "HEATFRM1") {
+    throw new Error("unexpected frames format");
+  }
+  const nx = view.getUint32(8, true);
+  const frames = view.getUint32(20, true);
+  const size = view.getUint32(24, true);
+  const offset = view.getFloat64(48, true);
+  const scale = view.getFloat64(56, true);
+  const values = size === 4
+    ? new Float32Array(buf, 64, frames * (nx + 1))
+    : new Float64Array(buf, 64, frames * (nx + 1));
+
+  const u = [];
+  for (let k = 0; k < frames; k++) {
+    const row = new Float64Array(nx + 1);
+    const src = values.subarray(k * (nx + 1), (k + 1) * (nx + 1));
+    for (let i = 0; i <= nx; i++) {
+      row[i] = offset + scale * src[i];
+    }
+    u.push(row);
+  }
+  return {
+    u,
+    stride: view.getUint32(16, true),
+    dx: view.getFloat64(32, true),
+    dt: view.getFloat64(40, true),
+  };
+}
+
+// С источником (heated) профиль масштабируется по наибольшему |u| за
+// расчёт, а анимация идёт до последнего кадра: в центре может быть
+// холодно, пока пучок ещё не дошёл
+function animateHeat(u, dx, dt, heated) {
   const canvas = document.getElementById("heatCanvas");
   const ctx = canvas.getContext("2d");
   const nx = u[0].length;
   const width = canvas.width;
   const height = canvas.height;
+  let peak = 1;
+  if (heated) {
+    for (const row of u) {
+      for (const v of row) {
+        peak = Math.max(peak, Math.abs(v));
+      }
+    }
+  }
 
   let frame = 0;
   const nt = u.length;
@@ -32,7 +92,7 @@ function animateHeat(u, dx, dt) {
     ctx.moveTo(0, height / 2);
     for (let i = 0; i < nx; i++) {
       const x = (i / nx) * width;
-      const y = height / 2 - u[frame][i] * 200;
+      const y = height / 2 - (u[frame][i] / peak) * 200;
       ctx.lineTo(x, y);
     }
     ctx.strokeStyle = "orange";
@@ -53,7 +113,7 @@ function animateHeat(u, dx, dt) {
     ctx.fillText(`🌡️ Temp(center): ${tempCenter.toFixed(4)}`, 160, height - 25);
 
     // --- Проверяем условие остановки ---
-    if (Math.abs(tempCenter) < 1e-4) {
+    if (!heated && Math.abs(tempCenter) < 1e-4) {
       cancelAnimationFrame(animationId);
       console.log(`Simulation stopped: temperature at center ≈ 0 (t = ${timeNow}s)`);
       return;